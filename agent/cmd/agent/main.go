@@ -17,6 +17,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	clustersv1alpha1 "open-cluster-management.io/api/cluster/v1alpha1"
 	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
@@ -31,6 +32,7 @@ import (
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/config"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/controllers"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/event"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/partitionstore"
 	agentscheme "github.com/stolostron/multicluster-global-hub/agent/pkg/scheme"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/jobs"
@@ -167,6 +169,8 @@ func parseFlags() *config.AgentConfig {
 		"The configuration file for the kubernetes event exporter")
 	pflag.BoolVar(&agentConfig.EnableGlobalResource, "enable-global-resource", false,
 		"Enable the global resource feature.")
+	pflag.BoolVar(&agentConfig.EnableGitOpsStatus, "enable-gitops-status", false,
+		"Report the health and sync status of the Argo CD applications.")
 	pflag.Float32Var(&agentConfig.QPS, "qps", 150,
 		"QPS for the multicluster global hub agent")
 	pflag.IntVar(&agentConfig.Burst, "burst", 300,
@@ -237,6 +241,11 @@ func createManager(ctx context.Context, restConfig *rest.Config, agentConfig *co
 	if err != nil {
 		return nil, fmt.Errorf("failed to create a new manager: %w", err)
 	}
+	// keep the partition of the hub in the shared status topic once it's chosen
+	if agentConfig.TransportConfig.KafkaConfig != nil && agentConfig.TransportConfig.KafkaConfig.ProducerConfig != nil {
+		agentConfig.TransportConfig.KafkaConfig.ProducerConfig.PartitionStore = partitionstore.NewConfigMapStore(mgr,
+			agentConfig.PodNameSpace)
+	}
 	kubeClient, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kubeclient: %w", err)
@@ -268,14 +277,15 @@ func initCache(config *rest.Config, cacheOpts cache.Options) (cache.Cache, error
 		&apiextensionsv1.CustomResourceDefinition{}: {
 			Field: fields.OneTermEqualSelector("metadata.name", "clustermanagers.operator.open-cluster-management.io"),
 		},
-		&policyv1.Policy{}:                  {},
-		&clusterv1.ManagedCluster{}:         {},
-		&clustersv1alpha1.ClusterClaim{}:    {},
-		&routev1.Route{}:                    {},
-		&placementrulev1.PlacementRule{}:    {},
-		&clusterv1beta1.Placement{}:         {},
-		&clusterv1beta1.PlacementDecision{}: {},
-		&appsv1alpha1.SubscriptionReport{}:  {},
+		&policyv1.Policy{}:                   {},
+		&clusterv1.ManagedCluster{}:          {},
+		&addonv1alpha1.ManagedClusterAddOn{}: {},
+		&clustersv1alpha1.ClusterClaim{}:     {},
+		&routev1.Route{}:                     {},
+		&placementrulev1.PlacementRule{}:     {},
+		&clusterv1beta1.Placement{}:          {},
+		&clusterv1beta1.PlacementDecision{}:  {},
+		&appsv1alpha1.SubscriptionReport{}:   {},
 		&coordinationv1.Lease{}: {
 			Field: fields.OneTermEqualSelector("metadata.namespace", constants.GHAgentNamespace),
 		},
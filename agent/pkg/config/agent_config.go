@@ -17,6 +17,7 @@ type AgentConfig struct {
 	KubeEventExporterConfigPath  string
 	MetricsAddress               string
 	EnableGlobalResource         bool
+	EnableGitOpsStatus           bool
 	QPS                          float32
 	Burst                        int
 }
@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+// Package desiredstate keeps the global resources applied from the spec bundles. it's recorded by the spec syncers
+// and read by the status syncers as the baseline to detect the drift of the objects on the managed hub.
+package desiredstate
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+// desiredObjects holds the global resources from the last received spec bundles.
+var desiredObjects = newObjectStore()
+
+type objectStore struct {
+	lock    sync.RWMutex
+	objects map[string]*unstructured.Unstructured
+	// version is increased on every change, so that the store is persisted only if it's changed
+	version int64
+}
+
+func newObjectStore() *objectStore {
+	return &objectStore{
+		objects: make(map[string]*unstructured.Unstructured),
+	}
+}
+
+// Record keeps the object which is applied from the spec bundle. only the objects with the global resource label
+// are recorded.
+func Record(obj *unstructured.Unstructured) {
+	if _, found := obj.GetLabels()[constants.GlobalHubGlobalResourceLabel]; !found {
+		return
+	}
+	desiredObjects.lock.Lock()
+	defer desiredObjects.lock.Unlock()
+	desiredObjects.objects[ObjectKey(obj)] = obj.DeepCopy()
+	desiredObjects.version++
+}
+
+// Remove stops detecting the drift of the object, it's invoked once the object is deleted by the spec bundle.
+func Remove(obj *unstructured.Unstructured) {
+	desiredObjects.lock.Lock()
+	defer desiredObjects.lock.Unlock()
+	if _, found := desiredObjects.objects[ObjectKey(obj)]; !found {
+		return
+	}
+	delete(desiredObjects.objects, ObjectKey(obj))
+	desiredObjects.version++
+}
+
+// List returns the copies of the desired objects ordered by the keys.
+func List() []*unstructured.Unstructured {
+	objects, _ := desiredObjects.snapshot()
+	return objects
+}
+
+// Reset removes all the desired objects, it's used by the tests.
+func Reset() {
+	desiredObjects.lock.Lock()
+	defer desiredObjects.lock.Unlock()
+	desiredObjects.objects = make(map[string]*unstructured.Unstructured)
+	desiredObjects.version = 0
+}
+
+// snapshot returns the copies of the objects with the version of the store.
+func (s *objectStore) snapshot() ([]*unstructured.Unstructured, int64) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	objects := make([]*unstructured.Unstructured, 0, len(keys))
+	for _, key := range keys {
+		objects = append(objects, s.objects[key].DeepCopy())
+	}
+	return objects, s.version
+}
+
+// restore adds the persisted objects which aren't recorded yet, the objects recorded from the spec bundles after
+// the start are newer than the persisted ones.
+func (s *objectStore) restore(objects []*unstructured.Unstructured) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	restored := 0
+	for _, obj := range objects {
+		key := ObjectKey(obj)
+		if _, found := s.objects[key]; found {
+			continue
+		}
+		s.objects[key] = obj
+		restored++
+	}
+	return restored
+}
+
+// ObjectKey identifies the object by the kind, the namespace and the name.
+func ObjectKey(obj *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s/%s", obj.GroupVersionKind().String(), obj.GetNamespace(), obj.GetName())
+}
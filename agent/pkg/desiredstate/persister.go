@@ -0,0 +1,193 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package desiredstate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+const (
+	persisterName = "desired-objects-persister"
+	// SecretName is the secret keeping the desired objects, so that the baseline of the drift survives the restart
+	// of the agent. it's a secret since the global resources might have the sensitive fields.
+	SecretName = "multicluster-global-hub-agent-desired-objects"
+	secretKey  = "objects.json.gz"
+	// maxSecretSize is under the 1MiB limit of the secret, the objects aren't persisted once it's exceeded
+	maxSecretSize    = 1000 * 1024
+	persistInterval  = 10 * time.Second
+	persistTimeout   = 30 * time.Second
+	loadRetryBackoff = 5 * time.Second
+)
+
+// persister restores the desired objects from the secret on the start, and saves them into the secret once they're
+// changed.
+type persister struct {
+	log       logr.Logger
+	client    client.Client
+	reader    client.Reader
+	namespace string
+	// savedVersion is the version of the store which is saved into the secret
+	savedVersion int64
+}
+
+// AddPersister persists the desired objects into the secret in the namespace of the agent.
+func AddPersister(mgr ctrl.Manager, namespace string) error {
+	return mgr.Add(&persister{
+		log:       ctrl.Log.WithName(persisterName),
+		client:    mgr.GetClient(),
+		reader:    mgr.GetAPIReader(),
+		namespace: namespace,
+	})
+}
+
+func (p *persister) Start(ctx context.Context) error {
+	go p.run(ctx)
+	return nil
+}
+
+func (p *persister) run(ctx context.Context) {
+	// save the objects only after they're restored, otherwise the persisted objects are overridden by the partial
+	// ones recorded since the start
+	for {
+		err := p.load(ctx)
+		if err == nil {
+			break
+		}
+		p.log.Error(err, "failed to restore the desired objects, retrying")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(loadRetryBackoff):
+		}
+	}
+
+	ticker := time.NewTicker(persistInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.log.Info("ctx is done, and exiting the desired objects persister!")
+			return
+		case <-ticker.C:
+			if err := p.save(ctx); err != nil {
+				p.log.Error(err, "failed to persist the desired objects")
+			}
+		}
+	}
+}
+
+func (p *persister) load(ctx context.Context) error {
+	secret := &corev1.Secret{}
+	err := p.reader.Get(ctx, types.NamespacedName{Namespace: p.namespace, Name: SecretName}, secret)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	objects, err := decode(secret.Data[secretKey])
+	if err != nil {
+		// the corrupted content is overridden by the next save
+		p.log.Error(err, "failed to decode the persisted desired objects, skipping them")
+		return nil
+	}
+	restored := desiredObjects.restore(objects)
+	p.log.Info("restored the desired objects", "persisted", len(objects), "restored", restored)
+	return nil
+}
+
+func (p *persister) save(ctx context.Context) error {
+	objects, version := desiredObjects.snapshot()
+	if version == p.savedVersion {
+		return nil
+	}
+	data, err := encode(objects)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxSecretSize {
+		return fmt.Errorf("the desired objects exceed the size of the secret: %d > %d bytes", len(data), maxSecretSize)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, persistTimeout)
+	defer cancel()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SecretName,
+			Namespace: p.namespace,
+			Labels: map[string]string{
+				constants.GlobalHubOwnerLabelKey: constants.GHAgentOwnerLabelValue,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{secretKey: data},
+	}
+	existing := &corev1.Secret{}
+	err = p.client.Get(ctx, client.ObjectKeyFromObject(secret), existing)
+	if apierrors.IsNotFound(err) {
+		err = p.client.Create(ctx, secret)
+	} else if err == nil {
+		existing.Data = secret.Data
+		err = p.client.Update(ctx, existing)
+	}
+	if err != nil {
+		return err
+	}
+	p.savedVersion = version
+	return nil
+}
+
+func encode(objects []*unstructured.Unstructured) ([]byte, error) {
+	payload, err := json.Marshal(objects)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(data []byte) ([]*unstructured.Unstructured, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	objects := []*unstructured.Unstructured{}
+	if err := json.Unmarshal(payload, &objects); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
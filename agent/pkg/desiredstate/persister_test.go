@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package desiredstate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+func newObject(name, value string, global bool) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("policy.open-cluster-management.io/v1")
+	obj.SetKind("Policy")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	if global {
+		obj.SetLabels(map[string]string{constants.GlobalHubGlobalResourceLabel: ""})
+	}
+	_ = unstructured.SetNestedField(obj.Object, value, "spec", "remediationAction")
+	return obj
+}
+
+func TestPersister(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	p := &persister{log: logr.Discard(), client: fakeClient, reader: fakeClient, namespace: "agent"}
+
+	Reset()
+	Record(newObject("policy1", "inform", true))
+	Record(newObject("policy2", "inform", true))
+	Record(newObject("local-policy", "inform", false))
+	assert.Len(t, List(), 2, "only the global resources are recorded")
+
+	require.NoError(t, p.load(ctx), "the secret doesn't exist before the first save")
+	require.NoError(t, p.save(ctx))
+	secret := &corev1.Secret{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "agent", Name: SecretName}, secret))
+	savedVersion := secret.ResourceVersion
+
+	// the unchanged objects aren't saved again
+	require.NoError(t, p.save(ctx))
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "agent", Name: SecretName}, secret))
+	assert.Equal(t, savedVersion, secret.ResourceVersion)
+
+	// restart: the object recorded from the spec bundle since the start wins over the persisted one
+	Reset()
+	Record(newObject("policy1", "enforce", true))
+	p = &persister{log: logr.Discard(), client: fakeClient, reader: fakeClient, namespace: "agent"}
+	require.NoError(t, p.load(ctx))
+
+	objects := List()
+	require.Len(t, objects, 2)
+	assert.Equal(t, "policy1", objects[0].GetName())
+	action, _, _ := unstructured.NestedString(objects[0].Object, "spec", "remediationAction")
+	assert.Equal(t, "enforce", action)
+	assert.Equal(t, "policy2", objects[1].GetName())
+
+	// the removed object is removed from the secret
+	Remove(objects[1])
+	require.NoError(t, p.save(ctx))
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "agent", Name: SecretName}, secret))
+	persisted, err := decode(secret.Data[secretKey])
+	require.NoError(t, err)
+	require.Len(t, persisted, 1)
+	assert.Equal(t, "policy1", persisted[0].GetName())
+}
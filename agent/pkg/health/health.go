@@ -0,0 +1,143 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
+	"github.com/stolostron/multicluster-global-hub/pkg/version"
+)
+
+// WorkerPool is the pool which handles the spec bundles, it reports the saturation of the pool.
+type WorkerPool interface {
+	Size() int
+	Pending() int
+}
+
+// registry collects the self health of the agent, it's reported to the manager by the heartbeat bundle.
+type registry struct {
+	lock           sync.RWMutex
+	statusSyncers  map[string]struct{}
+	specSyncers    map[string]struct{}
+	bundles        map[string]*base.BundleSendStatus
+	specWorkerPool WorkerPool
+}
+
+var agentHealth = newRegistry()
+
+func newRegistry() *registry {
+	return &registry{
+		statusSyncers: make(map[string]struct{}),
+		specSyncers:   make(map[string]struct{}),
+		bundles:       make(map[string]*base.BundleSendStatus),
+	}
+}
+
+// RegisterStatusSyncer records the status syncer of the bundle is running, the syncer is keyed by the transport
+// bundle key, the same as its sending results. The first round of the syncer is expected from now on.
+func RegisterStatusSyncer(bundleKey string) {
+	agentHealth.lock.Lock()
+	defer agentHealth.lock.Unlock()
+	agentHealth.statusSyncers[bundleKey] = struct{}{}
+	status := agentHealth.bundleStatus(bundleKey)
+	if status.LastSyncTime == nil {
+		now := time.Now()
+		status.LastSyncTime = &now
+	}
+}
+
+// RecordSync records a round of the status syncer of the bundle with the current sync interval, it's called on
+// every round whether the bundle is changed or not, so that the stuck syncers are detected.
+func RecordSync(bundleKey string, interval time.Duration) {
+	agentHealth.lock.Lock()
+	defer agentHealth.lock.Unlock()
+	status := agentHealth.bundleStatus(bundleKey)
+	now := time.Now()
+	status.LastSyncTime = &now
+	status.SyncInterval = interval
+}
+
+// RegisterSpecSyncer records the spec syncer is running.
+func RegisterSpecSyncer(name string) {
+	agentHealth.lock.Lock()
+	defer agentHealth.lock.Unlock()
+	agentHealth.specSyncers[name] = struct{}{}
+}
+
+// RegisterSpecWorkerPool sets the worker pool to report the saturation of it.
+func RegisterSpecWorkerPool(pool WorkerPool) {
+	agentHealth.lock.Lock()
+	defer agentHealth.lock.Unlock()
+	agentHealth.specWorkerPool = pool
+}
+
+// RecordSendSuccess updates the last successful send time of the bundle, and resets the consecutive errors.
+func RecordSendSuccess(bundleKey string) {
+	agentHealth.lock.Lock()
+	defer agentHealth.lock.Unlock()
+	status := agentHealth.bundleStatus(bundleKey)
+	now := time.Now()
+	status.LastSuccessTime = &now
+	status.ConsecutiveErrors = 0
+	status.LastError = ""
+}
+
+// RecordSendFailure increases the error count of the bundle.
+func RecordSendFailure(bundleKey string, err error) {
+	agentHealth.lock.Lock()
+	defer agentHealth.lock.Unlock()
+	status := agentHealth.bundleStatus(bundleKey)
+	status.ErrorCount++
+	status.ConsecutiveErrors++
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
+
+func (r *registry) bundleStatus(bundleKey string) *base.BundleSendStatus {
+	status, ok := r.bundles[bundleKey]
+	if !ok {
+		status = &base.BundleSendStatus{}
+		r.bundles[bundleKey] = status
+	}
+	return status
+}
+
+// GetAgentHealth returns a snapshot of the current agent health.
+func GetAgentHealth() *base.AgentHealth {
+	agentHealth.lock.RLock()
+	defer agentHealth.lock.RUnlock()
+
+	now := time.Now()
+	health := &base.AgentHealth{
+		AgentVersion:  version.Get(),
+		ReportTime:    &now,
+		StatusSyncers: sortedKeys(agentHealth.statusSyncers),
+		SpecSyncers:   sortedKeys(agentHealth.specSyncers),
+		Bundles:       make(map[string]*base.BundleSendStatus, len(agentHealth.bundles)),
+	}
+	for key, status := range agentHealth.bundles {
+		copied := *status
+		health.Bundles[key] = &copied
+	}
+	if agentHealth.specWorkerPool != nil {
+		health.SpecWorkerPool = &base.WorkerPoolStatus{
+			Size:    agentHealth.specWorkerPool.Size(),
+			Pending: agentHealth.specWorkerPool.Pending(),
+		}
+	}
+	return health
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeWorkerPool struct {
+	size    int
+	pending int
+}
+
+func (p *fakeWorkerPool) Size() int    { return p.size }
+func (p *fakeWorkerPool) Pending() int { return p.pending }
+
+func TestAgentHealth(t *testing.T) {
+	agentHealth = newRegistry()
+
+	RegisterStatusSyncer("hub1.ManagedClusters")
+	RegisterStatusSyncer("hub1.HubClusterHeartbeat")
+	RegisterSpecSyncer("Resync")
+	pool := &fakeWorkerPool{size: 2, pending: 0}
+	RegisterSpecWorkerPool(pool)
+
+	RecordSendFailure("hub1.ManagedClusters", errors.New("broker not available"))
+	RecordSendFailure("hub1.ManagedClusters", errors.New("broker not available"))
+	RecordSendSuccess("hub1.HubClusterInfo")
+
+	health := GetAgentHealth()
+	assert.NotEmpty(t, health.AgentVersion)
+	assert.Equal(t, []string{"hub1.HubClusterHeartbeat", "hub1.ManagedClusters"}, health.StatusSyncers)
+	assert.NotNil(t, health.ReportTime)
+	assert.NotNil(t, health.Bundles["hub1.ManagedClusters"].LastSyncTime, "the first round is expected from now on")
+	assert.Equal(t, []string{"Resync"}, health.SpecSyncers)
+	assert.Equal(t, int64(2), health.Bundles["hub1.ManagedClusters"].ErrorCount)
+	assert.Equal(t, int64(2), health.Bundles["hub1.ManagedClusters"].ConsecutiveErrors)
+	assert.Nil(t, health.Bundles["hub1.ManagedClusters"].LastSuccessTime)
+	assert.NotNil(t, health.Bundles["hub1.HubClusterInfo"].LastSuccessTime)
+	assert.Len(t, health.DegradedReasons(), 1)
+
+	// recover the bundle and saturate the worker pool
+	RecordSendSuccess("hub1.ManagedClusters")
+	pool.pending = 2
+
+	health = GetAgentHealth()
+	assert.Equal(t, int64(2), health.Bundles["hub1.ManagedClusters"].ErrorCount)
+	assert.Equal(t, int64(0), health.Bundles["hub1.ManagedClusters"].ConsecutiveErrors)
+	assert.True(t, health.SpecWorkerPool.Saturated())
+	reasons := health.DegradedReasons()
+	assert.Len(t, reasons, 1)
+	assert.Contains(t, reasons[0], "spec worker pool is saturated")
+
+	pool.pending = 1
+	assert.Empty(t, GetAgentHealth().DegradedReasons())
+}
+
+func TestStuckSyncer(t *testing.T) {
+	agentHealth = newRegistry()
+
+	RegisterStatusSyncer("hub1.ManagedClusters")
+	RegisterStatusSyncer("hub1.Policies")
+	RecordSync("hub1.ManagedClusters", 5*time.Second)
+	RecordSync("hub1.Policies", 5*time.Minute)
+	RegisterStatusSyncer("hub1.Events")
+
+	health := GetAgentHealth()
+	assert.Empty(t, health.DegradedReasons())
+
+	// the syncers are evaluated at the report time of the agent
+	reportTime := health.ReportTime.Add(2 * time.Minute)
+	health.ReportTime = &reportTime
+	reasons := health.DegradedReasons()
+	assert.Len(t, reasons, 2)
+	// the syncer which never runs after the registration is also flagged
+	assert.Contains(t, reasons[0], "syncer of bundle hub1.Events hasn't run since")
+	assert.Contains(t, reasons[1], "syncer of bundle hub1.ManagedClusters hasn't run since")
+
+	// the syncer with the long interval is flagged after the intervals
+	reportTime = reportTime.Add(time.Hour)
+	health.ReportTime = &reportTime
+	assert.Len(t, health.DegradedReasons(), 3)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package partitionstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+// ConfigMapName is the configmap keeping the partitions of the topics the agent sends to. The partition of the hub is
+// chosen by hashing the hub name the first time, then it's kept in the configmap, so that the hub isn't moved to the
+// other partition once the partitions are added to the shared topic, even after the agent restarts.
+const ConfigMapName = "multicluster-global-hub-agent-partitions"
+
+var _ transport.PartitionStore = &configMapStore{}
+
+type configMapStore struct {
+	client    client.Client
+	reader    client.Reader
+	namespace string
+}
+
+// NewConfigMapStore returns the partition store backed by the configmap in the namespace of the agent.
+func NewConfigMapStore(mgr ctrl.Manager, namespace string) transport.PartitionStore {
+	return &configMapStore{
+		client:    mgr.GetClient(),
+		reader:    mgr.GetAPIReader(),
+		namespace: namespace,
+	}
+}
+
+func (s *configMapStore) Load(ctx context.Context, topic, partitionKey string) (int32, bool, error) {
+	configMap := &corev1.ConfigMap{}
+	err := s.reader.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: ConfigMapName}, configMap)
+	if apierrors.IsNotFound(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	value, found := configMap.Data[dataKey(topic, partitionKey)]
+	if !found {
+		return 0, false, nil
+	}
+	partition, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		// the invalid partition is overridden by the chosen one
+		return 0, false, nil
+	}
+	return int32(partition), true, nil
+}
+
+func (s *configMapStore) Store(ctx context.Context, topic, partitionKey string, partition int32) error {
+	configMap := &corev1.ConfigMap{}
+	err := s.reader.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: ConfigMapName}, configMap)
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ConfigMapName,
+				Namespace: s.namespace,
+				Labels: map[string]string{
+					constants.GlobalHubOwnerLabelKey: constants.GHAgentOwnerLabelValue,
+				},
+			},
+			Data: map[string]string{dataKey(topic, partitionKey): strconv.Itoa(int(partition))},
+		}
+		return s.client.Create(ctx, configMap)
+	}
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[dataKey(topic, partitionKey)] = strconv.Itoa(int(partition))
+	return s.client.Update(ctx, configMap)
+}
+
+// dataKey is the key of the partition in the configmap, both the topic and the hub name are the valid configmap keys.
+func dataKey(topic, partitionKey string) string {
+	return fmt.Sprintf("%s.%s", topic, partitionKey)
+}
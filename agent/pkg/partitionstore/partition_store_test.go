@@ -0,0 +1,44 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package partitionstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigMapStore(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	store := &configMapStore{client: fakeClient, reader: fakeClient, namespace: "agent"}
+
+	_, found, err := store.Load(ctx, "status", "hub1")
+	require.NoError(t, err, "the configmap doesn't exist before the first store")
+	assert.False(t, found)
+
+	require.NoError(t, store.Store(ctx, "status", "hub1", 2))
+	require.NoError(t, store.Store(ctx, "status.hub2", "hub2", 0))
+
+	partition, found, err := store.Load(ctx, "status", "hub1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int32(2), partition)
+
+	partition, found, err = store.Load(ctx, "status.hub2", "hub2")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int32(0), partition)
+
+	_, found, err = store.Load(ctx, "status", "hub2")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
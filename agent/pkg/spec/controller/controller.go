@@ -4,12 +4,15 @@ import (
 	"fmt"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/health"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/spec/controller/syncers"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/spec/controller/workers"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport/consumer"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/producer"
 )
 
 func AddToManager(mgr ctrl.Manager, agentConfig *config.AgentConfig) error {
@@ -27,6 +30,7 @@ func AddToManager(mgr ctrl.Manager, agentConfig *config.AgentConfig) error {
 	if err := mgr.Add(workers); err != nil {
 		return fmt.Errorf("failed to add k8s workers pool to runtime manager: %w", err)
 	}
+	health.RegisterSpecWorkerPool(workers)
 
 	// add bundle dispatcher to manager
 	dispatcher := syncers.NewGenericDispatcher(consumer, *agentConfig)
@@ -34,15 +38,30 @@ func AddToManager(mgr ctrl.Manager, agentConfig *config.AgentConfig) error {
 		return fmt.Errorf("failed to add bundle dispatcher to runtime manager: %w", err)
 	}
 
+	// the producer requests the snapshots of the spec bundles from the manager once the delta bundles are missing,
+	// and reports the results of the migration stages
+	producer, err := producer.NewGenericProducer(agentConfig.TransportConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize transport producer: %w", err)
+	}
+
 	// register syncer to the dispatcher
 	if agentConfig.EnableGlobalResource {
 		dispatcher.RegisterSyncer(syncers.GenericMessageKey,
-			syncers.NewGenericSyncer(workers, agentConfig))
+			syncers.NewGenericSyncer(workers, agentConfig, producer))
 		dispatcher.RegisterSyncer(constants.ManagedClustersLabelsMsgKey,
 			syncers.NewManagedClusterLabelSyncer(workers))
 	}
 
 	dispatcher.RegisterSyncer(constants.ResyncMsgKey,
 		syncers.NewResyncSyncer())
+
+	// the migration stages wait for the clusters, so they're run with a direct client out of the worker pool
+	migrationClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return fmt.Errorf("failed to initialize the client of the migration syncer: %w", err)
+	}
+	dispatcher.RegisterSyncer(constants.ManagedClusterMigrationMsgKey,
+		syncers.NewMigrationSyncer(migrationClient, agentConfig, producer))
 	return nil
 }
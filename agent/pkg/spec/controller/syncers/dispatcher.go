@@ -7,6 +7,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/health"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport"
 )
 
@@ -28,6 +29,7 @@ func NewGenericDispatcher(consumer transport.Consumer, config config.AgentConfig
 
 func (d *genericDispatcher) RegisterSyncer(messageID string, syncer Syncer) {
 	d.syncers[messageID] = syncer
+	health.RegisterSpecSyncer(messageID)
 	d.log.Info("dispatch syncer is registered", "messageID", messageID)
 }
 
@@ -58,7 +60,7 @@ func (d *genericDispatcher) dispatch(ctx context.Context) {
 				d.log.V(2).Info("dispatching to the default generic syncer", "messageKey", message.Key)
 				syncer = d.syncers[GenericMessageKey]
 			}
-			if err := syncer.Sync(message.Payload); err != nil {
+			if err := syncer.Sync(message.Key, message.Payload); err != nil {
 				d.log.Error(err, "submit to syncer error", "messageKey", message.Key)
 			}
 		}
@@ -3,7 +3,10 @@ package syncers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -11,35 +14,63 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/desiredstate"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/health"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/spec/controller/rbac"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/spec/controller/workers"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
 	"github.com/stolostron/multicluster-global-hub/pkg/utils"
 	helper "github.com/stolostron/multicluster-global-hub/pkg/utils"
 )
 
+// snapshotRequestInterval is the minimal interval to request the snapshot of the same spec bundle again, in case the
+// request or the snapshot is lost.
+const snapshotRequestInterval = time.Minute
+
 // genericBundleSyncer syncs objects spec from received bundles.
 type genericBundleSyncer struct {
 	log                          logr.Logger
 	workerPool                   *workers.WorkerPool
 	bundleProcessingWaitingGroup sync.WaitGroup
 	enforceHohRbac               bool
+	leafHubName                  string
+	producer                     transport.Producer
+	// appliedGenerations is the generation of the last applied bundle by the message key
+	appliedGenerations map[string]spec.BundleGeneration
+	// snapshotRequests is the time of requesting the snapshot by the message key, it's removed once the snapshot
+	// is received
+	snapshotRequests      map[string]time.Time
+	snapshotRequestBundle *spec.SnapshotRequestBundle
 }
 
-func NewGenericSyncer(workerPool *workers.WorkerPool, config *config.AgentConfig) *genericBundleSyncer {
+func NewGenericSyncer(workerPool *workers.WorkerPool, config *config.AgentConfig,
+	producer transport.Producer,
+) *genericBundleSyncer {
 	return &genericBundleSyncer{
 		log:                          ctrl.Log.WithName("generic-bundle-syncer"),
 		workerPool:                   workerPool,
 		bundleProcessingWaitingGroup: sync.WaitGroup{},
 		enforceHohRbac:               config.SpecEnforceHohRbac,
+		leafHubName:                  config.LeafHubName,
+		producer:                     producer,
+		appliedGenerations:           make(map[string]spec.BundleGeneration),
+		snapshotRequests:             make(map[string]time.Time),
+		snapshotRequestBundle:        spec.NewAgentSnapshotRequestBundle(config.LeafHubName),
 	}
 }
 
-func (syncer *genericBundleSyncer) Sync(payload []byte) error {
+func (syncer *genericBundleSyncer) Sync(messageKey string, payload []byte) error {
 	genericBundle := &spec.GenericSpecBundle{}
 	if err := json.Unmarshal(payload, genericBundle); err != nil {
 		return err
 	}
+	if !syncer.accept(messageKey, genericBundle.BundleGeneration) {
+		syncer.log.V(2).Info("skip the stale bundle", "messageKey", messageKey,
+			"generation", genericBundle.Generation)
+		return nil
+	}
 
 	syncer.bundleProcessingWaitingGroup.Add(len(genericBundle.Objects) + len(genericBundle.DeletedObjects))
 	syncer.syncObjects(genericBundle.Objects)
@@ -48,6 +79,80 @@ func (syncer *genericBundleSyncer) Sync(payload []byte) error {
 	return nil
 }
 
+// accept returns whether the bundle should be applied. The delta bundle is applied in order on top of the applied
+// generation, the snapshot is requested once a gap is detected, and the stale bundles are skipped. The delta bundle
+// after a gap is still applied since it holds the latest objects.
+func (syncer *genericBundleSyncer) accept(messageKey string, generation spec.BundleGeneration) bool {
+	// the bundle without generation holds all the objects
+	if generation.Generation == 0 {
+		return true
+	}
+
+	applied, found := syncer.appliedGenerations[messageKey]
+	sameStream := found && applied.Stream == generation.Stream
+	if generation.Snapshot {
+		if sameStream && generation.Generation < applied.Generation {
+			return false
+		}
+		syncer.appliedGenerations[messageKey] = generation
+		delete(syncer.snapshotRequests, messageKey)
+		return true
+	}
+
+	if sameStream && generation.Generation <= applied.Generation {
+		return false
+	}
+	if !sameStream || generation.BaseGeneration > applied.Generation {
+		syncer.log.Info("missing the spec bundles, request the snapshot", "messageKey", messageKey,
+			"appliedGeneration", applied.Generation, "baseGeneration", generation.BaseGeneration)
+		syncer.requestSnapshot(messageKey)
+		return true
+	}
+	syncer.appliedGenerations[messageKey] = generation
+	return true
+}
+
+// requestSnapshot sends the message keys waiting for the snapshots to the manager.
+func (syncer *genericBundleSyncer) requestSnapshot(messageKey string) {
+	if requestedAt, found := syncer.snapshotRequests[messageKey]; found &&
+		time.Since(requestedAt) < snapshotRequestInterval {
+		return
+	}
+	syncer.snapshotRequests[messageKey] = time.Now()
+	if syncer.producer == nil {
+		return
+	}
+
+	msgKeys := make([]string, 0, len(syncer.snapshotRequests))
+	for key := range syncer.snapshotRequests {
+		msgKeys = append(msgKeys, key)
+	}
+	sort.Strings(msgKeys)
+	syncer.snapshotRequestBundle.Objects = msgKeys
+	syncer.snapshotRequestBundle.GetVersion().Incr()
+
+	transportBundleKey := fmt.Sprintf("%s.%s", syncer.leafHubName, constants.SpecSnapshotRequestMsgKey)
+	payloadBytes, err := json.Marshal(syncer.snapshotRequestBundle)
+	if err != nil {
+		syncer.log.Error(err, "marshal snapshot request bundle error", "key", transportBundleKey)
+		return
+	}
+	if err := syncer.producer.Send(context.TODO(), &transport.Message{
+		Key:         transportBundleKey,
+		Destination: syncer.leafHubName,
+		MsgType:     constants.StatusBundle,
+		Payload:     payloadBytes,
+	}); err != nil {
+		syncer.log.Error(err, "send transport message error", "key", transportBundleKey)
+		health.RecordSendFailure(transportBundleKey, err)
+		// retry once the next gap is detected
+		delete(syncer.snapshotRequests, messageKey)
+		return
+	}
+	health.RecordSendSuccess(transportBundleKey)
+	syncer.snapshotRequestBundle.GetVersion().Next()
+}
+
 func (syncer *genericBundleSyncer) syncObjects(bundleObjects []*unstructured.Unstructured) {
 	for _, bundleObject := range bundleObjects {
 		if !syncer.enforceHohRbac { // if rbac not enforced, use controller's identity.
@@ -87,12 +192,15 @@ func (syncer *genericBundleSyncer) syncObjects(bundleObjects []*unstructured.Uns
 			}
 
 			delete(unstructuredObject.Object, "status")
+			// the object is overridden by the response of the server, keep a copy as the baseline of the drift
+			desiredObject := unstructuredObject.DeepCopy()
 			err := helper.UpdateObject(ctx, k8sClient, unstructuredObject)
 			if err != nil {
 				syncer.log.Error(err, "failed to update object", "name", unstructuredObject.GetName(),
 					"namespace", unstructuredObject.GetNamespace(), "kind", unstructuredObject.GetKind())
 				return
 			}
+			desiredstate.Record(desiredObject)
 			syncer.log.V(2).Info("object updated", "name", unstructuredObject.GetName(), "namespace",
 				unstructuredObject.GetNamespace(), "kind", unstructuredObject.GetKind())
 		}))
@@ -111,6 +219,7 @@ func (syncer *genericBundleSyncer) syncDeletedObjects(deletedObjects []*unstruct
 			defer syncer.bundleProcessingWaitingGroup.Done()
 
 			unstructuredObject, _ := obj.(*unstructured.Unstructured)
+			desiredstate.Remove(unstructuredObject)
 
 			// syncer.deleteObject(ctx, k8sClient, obj.(*unstructured.Unstructured))
 			if deleted, err := helper.DeleteObject(ctx, k8sClient, unstructuredObject); err != nil {
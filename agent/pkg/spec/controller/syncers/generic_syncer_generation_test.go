@@ -0,0 +1,66 @@
+package syncers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+type fakeProducer struct {
+	messages []*transport.Message
+}
+
+func (p *fakeProducer) Send(ctx context.Context, msg *transport.Message) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func TestGenericSyncerAccept(t *testing.T) {
+	producer := &fakeProducer{}
+	syncer := NewGenericSyncer(nil, &config.AgentConfig{LeafHubName: "hub1"}, producer)
+	delta := func(stream string, base int64) spec.BundleGeneration {
+		return spec.BundleGeneration{Stream: stream, Generation: base + 1, BaseGeneration: base}
+	}
+
+	// the bundle without generation is always applied
+	assert.True(t, syncer.accept("Policies", spec.BundleGeneration{}))
+	assert.Empty(t, producer.messages)
+
+	assert.True(t, syncer.accept("Policies", spec.BundleGeneration{Stream: "a", Generation: 1, Snapshot: true}))
+	assert.True(t, syncer.accept("Policies", delta("a", 1)))
+	// the delta bundle which is already applied is skipped
+	assert.False(t, syncer.accept("Policies", delta("a", 1)))
+	assert.Empty(t, producer.messages)
+
+	// the gap is detected, the delta bundle is applied and the snapshot is requested
+	assert.True(t, syncer.accept("Policies", delta("a", 3)))
+	assert.Len(t, producer.messages, 1)
+	assert.Equal(t, "hub1."+constants.SpecSnapshotRequestMsgKey, producer.messages[0].Key)
+	request := spec.NewAgentSnapshotRequestBundle("hub1")
+	assert.Nil(t, json.Unmarshal(producer.messages[0].Payload, request))
+	assert.Equal(t, []string{"Policies"}, request.Objects)
+
+	// the snapshot isn't requested again until the interval is passed
+	assert.True(t, syncer.accept("Policies", delta("a", 4)))
+	assert.Len(t, producer.messages, 1)
+
+	// the stale snapshot is skipped, the snapshot of the current generation is applied
+	assert.False(t, syncer.accept("Policies", spec.BundleGeneration{Stream: "a", Generation: 1, Snapshot: true}))
+	assert.True(t, syncer.accept("Policies", spec.BundleGeneration{Stream: "a", Generation: 2, Snapshot: true}))
+	assert.Empty(t, syncer.snapshotRequests)
+	assert.True(t, syncer.accept("Policies", delta("a", 2)))
+
+	// the delta bundle of the new stream requests the snapshot
+	assert.True(t, syncer.accept("Policies", delta("b", 1)))
+	assert.Len(t, producer.messages, 2)
+	assert.True(t, syncer.accept("Policies", spec.BundleGeneration{Stream: "b", Generation: 1, Snapshot: true}))
+	assert.True(t, syncer.accept("Policies", delta("b", 1)))
+	assert.Len(t, producer.messages, 2)
+}
@@ -7,7 +7,9 @@ import (
 const GenericMessageKey = "Generic"
 
 type Syncer interface {
-	Sync(payload []byte) error
+	// Sync syncs the bundle of the message key, the message key is specified since the generic syncer is the default
+	// syncer of the message keys which aren't registered.
+	Sync(messageKey string, payload []byte) error
 }
 
 type Dispatcher interface {
@@ -0,0 +1,506 @@
+package syncers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/health"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+const (
+	// migrationStageTimeout is the time to wait for the import secret or the registration of the cluster on the target
+	// hub, or the bootstrap kubeconfig applied to the cluster from the source hub.
+	migrationStageTimeout = 5 * time.Minute
+	// migrationResultTTL is the time to keep the results of the stages, the manager requests the stage again if it
+	// doesn't receive the result, then the result is sent again.
+	migrationResultTTL  = time.Hour
+	migrationPollPeriod = 5 * time.Second
+
+	// migrationWorkName is the manifestwork which deploys the bootstrap kubeconfig to the cluster from the source hub
+	migrationWorkName = "global-hub-migration"
+	// the bootstrap kubeconfig of the klusterlet, the klusterlet bootstraps again once the server is changed
+	bootstrapSecretName      = "bootstrap-hub-kubeconfig"
+	bootstrapSecretNamespace = "open-cluster-management-agent"
+	bootstrapSecretKey       = "kubeconfig"
+	// the import secret is created by the import controller of the hub for each managed cluster
+	importSecretSuffix = "-import"
+	importSecretKey    = "import.yaml"
+)
+
+// migrationSyncer runs the stages of the managed cluster migration requested by the manager, and reports the
+// results to the manager. The stages wait for the hub or the clusters, so they're run out of the worker pool.
+type migrationSyncer struct {
+	log         logr.Logger
+	client      client.Client
+	leafHubName string
+	producer    transport.Producer
+
+	lock sync.Mutex
+	// running holds the stages which are running by the key of the migration, the stage and the clusters
+	running      map[string]bool
+	results      map[string]*spec.MigrationStatus
+	statusBundle *spec.MigrationStatusBundle
+}
+
+func NewMigrationSyncer(runtimeClient client.Client, config *config.AgentConfig,
+	producer transport.Producer,
+) *migrationSyncer {
+	return &migrationSyncer{
+		log:          ctrl.Log.WithName("managed-cluster-migration-syncer"),
+		client:       runtimeClient,
+		leafHubName:  config.LeafHubName,
+		producer:     producer,
+		running:      make(map[string]bool),
+		results:      make(map[string]*spec.MigrationStatus),
+		statusBundle: spec.NewAgentMigrationStatusBundle(config.LeafHubName),
+	}
+}
+
+func (syncer *migrationSyncer) Sync(messageKey string, payload []byte) error {
+	migration := &spec.MigrationSpec{}
+	if err := json.Unmarshal(payload, migration); err != nil {
+		return err
+	}
+	key := migrationKey(migration)
+
+	syncer.lock.Lock()
+	defer syncer.lock.Unlock()
+	if syncer.running[key] {
+		return nil
+	}
+	// the manager requests the stage again if it misses the result, the failed stage is run again since the
+	// manager only requests it again if it's waiting for the clusters, e.g. the clusters are registering
+	if result, found := syncer.results[key]; found && succeeded(result) {
+		syncer.report()
+		return nil
+	}
+	syncer.running[key] = true
+	syncer.log.Info("running the migration stage", "migration", migration.MigrationID, "stage", migration.Stage,
+		"clusters", len(migration.Clusters))
+
+	go func() {
+		status := syncer.run(migration)
+		syncer.lock.Lock()
+		defer syncer.lock.Unlock()
+		delete(syncer.running, key)
+		syncer.results[key] = status
+		syncer.report()
+	}()
+	return nil
+}
+
+func (syncer *migrationSyncer) run(migration *spec.MigrationSpec) *spec.MigrationStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*migrationStageTimeout)
+	defer cancel()
+
+	status := &spec.MigrationStatus{MigrationID: migration.MigrationID, Stage: migration.Stage}
+	for _, cluster := range migration.Clusters {
+		clusterStatus := &spec.MigrationClusterStatus{Name: cluster.Name}
+		var err error
+		switch migration.Stage {
+		case spec.MigrationStageExport:
+			clusterStatus.ClusterSetBindings, err = exportClusterSetBindings(ctx, syncer.client, cluster.Name)
+		case spec.MigrationStagePrepare:
+			clusterStatus.BootstrapKubeconfig, err = prepareCluster(ctx, syncer.client, migration.MigrationID,
+				cluster)
+		case spec.MigrationStageDeploy:
+			err = deployBootstrapKubeconfig(ctx, syncer.client, cluster.Name, cluster.BootstrapKubeconfig, true)
+		case spec.MigrationStageRegister:
+			err = waitForRegistration(ctx, syncer.client, cluster.Name)
+		case spec.MigrationStageDetach:
+			err = detachCluster(ctx, syncer.client, cluster.Name)
+		case spec.MigrationStageRollback:
+			if syncer.leafHubName == migration.SourceHub {
+				clusterStatus.BootstrapKubeconfig, err = restoreCluster(ctx, syncer.client, cluster.Name)
+			} else {
+				err = removePreparedCluster(ctx, syncer.client, migration.MigrationID, cluster.Name,
+					cluster.BootstrapKubeconfig)
+			}
+		default:
+			err = fmt.Errorf("unknown migration stage %s", migration.Stage)
+		}
+		if err != nil {
+			syncer.log.Error(err, "failed to run the migration stage", "migration", migration.MigrationID,
+				"stage", migration.Stage, "cluster", cluster.Name)
+			clusterStatus.Error = err.Error()
+		}
+		status.Clusters = append(status.Clusters, clusterStatus)
+	}
+	status.CompletedAt = time.Now()
+	syncer.log.Info("the migration stage is completed", "migration", migration.MigrationID,
+		"stage", migration.Stage)
+	return status
+}
+
+// migrationKey identifies the stage of the migration run for the clusters, the manager requests the stage for the
+// different clusters separately once they're ready for the stage.
+func migrationKey(migration *spec.MigrationSpec) string {
+	names := make([]string, 0, len(migration.Clusters))
+	for _, cluster := range migration.Clusters {
+		names = append(names, cluster.Name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("%s/%s/%s", migration.MigrationID, migration.Stage, strings.Join(names, ","))
+}
+
+func succeeded(status *spec.MigrationStatus) bool {
+	for _, cluster := range status.Clusters {
+		if cluster.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// report sends all the recent results to the manager, the expired results are removed. the caller holds the lock.
+func (syncer *migrationSyncer) report() {
+	keys := []string{}
+	for key, result := range syncer.results {
+		if time.Since(result.CompletedAt) > migrationResultTTL {
+			delete(syncer.results, key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	syncer.statusBundle.Objects = make([]*spec.MigrationStatus, 0, len(keys))
+	for _, key := range keys {
+		syncer.statusBundle.Objects = append(syncer.statusBundle.Objects, syncer.results[key])
+	}
+	if syncer.producer == nil {
+		return
+	}
+	syncer.statusBundle.GetVersion().Incr()
+
+	transportBundleKey := fmt.Sprintf("%s.%s", syncer.leafHubName, constants.ManagedClusterMigrationStatusMsgKey)
+	payloadBytes, err := json.Marshal(syncer.statusBundle)
+	if err != nil {
+		syncer.log.Error(err, "marshal migration status bundle error", "key", transportBundleKey)
+		return
+	}
+	if err := syncer.producer.Send(context.TODO(), &transport.Message{
+		Key:         transportBundleKey,
+		Destination: syncer.leafHubName,
+		MsgType:     constants.StatusBundle,
+		Payload:     payloadBytes,
+	}); err != nil {
+		// the result is sent again once the manager requests the stage again
+		syncer.log.Error(err, "send transport message error", "key", transportBundleKey)
+		health.RecordSendFailure(transportBundleKey, err)
+		return
+	}
+	health.RecordSendSuccess(transportBundleKey)
+	syncer.statusBundle.GetVersion().Next()
+}
+
+// exportClusterSetBindings returns the namespaces which bind the cluster set of the cluster on the source hub.
+func exportClusterSetBindings(ctx context.Context, c client.Client, clusterName string) ([]string, error) {
+	cluster := &clusterv1.ManagedCluster{}
+	if err := c.Get(ctx, types.NamespacedName{Name: clusterName}, cluster); err != nil {
+		return nil, fmt.Errorf("failed to get the cluster on the source hub: %w", err)
+	}
+	clusterSet := cluster.Labels[clusterv1beta2.ClusterSetLabel]
+	if clusterSet == "" {
+		return nil, nil
+	}
+	bindings := &clusterv1beta2.ManagedClusterSetBindingList{}
+	if err := c.List(ctx, bindings); err != nil {
+		return nil, fmt.Errorf("failed to list the cluster set bindings: %w", err)
+	}
+	namespaces := []string{}
+	for _, binding := range bindings.Items {
+		if binding.Spec.ClusterSet == clusterSet {
+			namespaces = append(namespaces, binding.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// prepareCluster creates the cluster on the target hub with the labels, which include the cluster set of the
+// cluster, and binds the cluster set to the namespaces as the source hub. It returns the bootstrap kubeconfig from
+// the import secret of the cluster.
+func prepareCluster(ctx context.Context, c client.Client, migrationID string, cluster *spec.MigrationClusterSpec,
+) ([]byte, error) {
+	existing := &clusterv1.ManagedCluster{}
+	err := c.Get(ctx, types.NamespacedName{Name: cluster.Name}, existing)
+	if err == nil && existing.Annotations[constants.ManagedClusterMigrationAnnotation] != migrationID {
+		return nil, fmt.Errorf("the cluster %s exists on the target hub", cluster.Name)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if clusterSet := cluster.Labels[clusterv1beta2.ClusterSetLabel]; clusterSet != "" {
+		err := c.Create(ctx, &clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: clusterSet}})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to create the cluster set %s: %w", clusterSet, err)
+		}
+		if err := bindClusterSet(ctx, c, clusterSet, cluster.ClusterSetBindings); err != nil {
+			return nil, err
+		}
+	}
+	if apierrors.IsNotFound(err) {
+		managedCluster := &clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        cluster.Name,
+				Labels:      cluster.Labels,
+				Annotations: map[string]string{constants.ManagedClusterMigrationAnnotation: migrationID},
+			},
+			Spec: clusterv1.ManagedClusterSpec{HubAcceptsClient: true},
+		}
+		if err := c.Create(ctx, managedCluster); err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to create the cluster on the target hub: %w", err)
+		}
+	}
+
+	var kubeconfig []byte
+	importSecret := &corev1.Secret{}
+	err = wait.PollUntilContextTimeout(ctx, migrationPollPeriod, migrationStageTimeout, true,
+		func(ctx context.Context) (bool, error) {
+			err := c.Get(ctx, types.NamespacedName{Namespace: cluster.Name, Name: cluster.Name + importSecretSuffix},
+				importSecret)
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			kubeconfig, err = bootstrapKubeconfig(importSecret)
+			return err == nil, err
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the import secret of the cluster: %w", err)
+	}
+	return kubeconfig, nil
+}
+
+// bindClusterSet binds the cluster set to the namespaces, the namespaces are created if they don't exist, so that
+// the placements in them select the cluster once they're created on the target hub.
+func bindClusterSet(ctx context.Context, c client.Client, clusterSet string, namespaces []string) error {
+	for _, namespace := range namespaces {
+		err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create the namespace %s: %w", namespace, err)
+		}
+		err = c.Create(ctx, &clusterv1beta2.ManagedClusterSetBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterSet, Namespace: namespace},
+			Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: clusterSet},
+		})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to bind the cluster set %s to the namespace %s: %w", clusterSet, namespace, err)
+		}
+	}
+	return nil
+}
+
+// bootstrapKubeconfig returns the bootstrap kubeconfig of the klusterlet in the import secret.
+func bootstrapKubeconfig(importSecret *corev1.Secret) ([]byte, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(importSecret.Data[importSecretKey]), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if obj.GetKind() != "Secret" || obj.GetName() != bootstrapSecretName {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, secret); err != nil {
+			return nil, err
+		}
+		if len(secret.Data[bootstrapSecretKey]) == 0 {
+			break
+		}
+		return secret.Data[bootstrapSecretKey], nil
+	}
+	return nil, fmt.Errorf("the bootstrap kubeconfig isn't found in the import secret %s", importSecret.Name)
+}
+
+// deployBootstrapKubeconfig replaces the bootstrap kubeconfig of the klusterlet with the manifestwork, which is
+// orphaned once it's deleted. It waits for the manifestwork to be applied if wait is true.
+func deployBootstrapKubeconfig(ctx context.Context, c client.Client, clusterName string, kubeconfig []byte,
+	waitApplied bool,
+) error {
+	if len(kubeconfig) == 0 {
+		return fmt.Errorf("the bootstrap kubeconfig of the cluster %s is empty", clusterName)
+	}
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstrapSecretName,
+			Namespace: bootstrapSecretNamespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{bootstrapSecretKey: kubeconfig},
+	}
+	work := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: migrationWorkName, Namespace: clusterName},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, work, func() error {
+		work.Spec.Workload.Manifests = []workv1.Manifest{{RawExtension: runtime.RawExtension{Object: secret}}}
+		work.Spec.DeleteOption = &workv1.DeleteOption{PropagationPolicy: workv1.DeletePropagationPolicyTypeOrphan}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to deploy the bootstrap kubeconfig: %w", err)
+	}
+	if !waitApplied {
+		return nil
+	}
+
+	err := wait.PollUntilContextTimeout(ctx, migrationPollPeriod, migrationStageTimeout, true,
+		func(ctx context.Context) (bool, error) {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(work), work); err != nil {
+				return false, err
+			}
+			applied := meta.FindStatusCondition(work.Status.Conditions, workv1.WorkApplied)
+			return applied != nil && applied.Status == metav1.ConditionTrue &&
+				applied.ObservedGeneration == work.Generation, nil
+		})
+	if err != nil {
+		return fmt.Errorf("the bootstrap kubeconfig isn't applied to the cluster: %w", err)
+	}
+	return nil
+}
+
+// waitForRegistration waits for the cluster to be available on the target hub.
+func waitForRegistration(ctx context.Context, c client.Client, clusterName string) error {
+	cluster := &clusterv1.ManagedCluster{}
+	err := wait.PollUntilContextTimeout(ctx, migrationPollPeriod, migrationStageTimeout, true,
+		func(ctx context.Context) (bool, error) {
+			if err := c.Get(ctx, types.NamespacedName{Name: clusterName}, cluster); err != nil {
+				return false, err
+			}
+			return meta.IsStatusConditionTrue(cluster.Status.Conditions, clusterv1.ManagedClusterConditionAvailable),
+				nil
+		})
+	if err != nil {
+		return fmt.Errorf("the cluster %s isn't available on the target hub: %w", clusterName, err)
+	}
+	return nil
+}
+
+// detachCluster removes the cluster from the source hub. The source hub revokes the access of the cluster first,
+// and the manifestwork is orphaned to keep the bootstrap kubeconfig of the target hub on the cluster.
+func detachCluster(ctx context.Context, c client.Client, clusterName string) error {
+	work := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: migrationWorkName, Namespace: clusterName}}
+	if err := c.Delete(ctx, work); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the manifestwork: %w", err)
+	}
+
+	cluster := &clusterv1.ManagedCluster{}
+	if err := c.Get(ctx, types.NamespacedName{Name: clusterName}, cluster); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if cluster.Spec.HubAcceptsClient {
+		patch := client.MergeFrom(cluster.DeepCopy())
+		cluster.Spec.HubAcceptsClient = false
+		if err := c.Patch(ctx, cluster, patch); err != nil {
+			return fmt.Errorf("failed to deny the cluster: %w", err)
+		}
+	}
+	if err := c.Delete(ctx, cluster); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the cluster: %w", err)
+	}
+	return nil
+}
+
+// restoreCluster deploys the bootstrap kubeconfig of the source hub back to the cluster if it's replaced, and
+// accepts the cluster on the source hub again. It returns the bootstrap kubeconfig, which is deployed by the target
+// hub if the cluster has switched to it, since the manifestwork of the source hub doesn't reach the cluster then.
+func restoreCluster(ctx context.Context, c client.Client, clusterName string) ([]byte, error) {
+	importSecret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: clusterName, Name: clusterName + importSecretSuffix},
+		importSecret); err != nil {
+		return nil, fmt.Errorf("failed to get the import secret of the cluster: %w", err)
+	}
+	kubeconfig, err := bootstrapKubeconfig(importSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	work := &workv1.ManifestWork{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: clusterName, Name: migrationWorkName}, work)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		// the cluster might not reach the source hub, so the manifestwork isn't waited
+		if err := deployBootstrapKubeconfig(ctx, c, clusterName, kubeconfig, false); err != nil {
+			return nil, err
+		}
+	}
+
+	cluster := &clusterv1.ManagedCluster{}
+	if err := c.Get(ctx, types.NamespacedName{Name: clusterName}, cluster); err != nil {
+		return nil, fmt.Errorf("failed to get the cluster on the source hub: %w", err)
+	}
+	if !cluster.Spec.HubAcceptsClient {
+		patch := client.MergeFrom(cluster.DeepCopy())
+		cluster.Spec.HubAcceptsClient = true
+		if err := c.Patch(ctx, cluster, patch); err != nil {
+			return nil, fmt.Errorf("failed to accept the cluster: %w", err)
+		}
+	}
+	return kubeconfig, nil
+}
+
+// removePreparedCluster removes the cluster created by the migration from the target hub. The cluster which has
+// joined the target hub is switched back to the source hub with its bootstrap kubeconfig before it's removed, since
+// removing it would detach the klusterlet from both the hubs.
+func removePreparedCluster(ctx context.Context, c client.Client, migrationID, clusterName string,
+	sourceKubeconfig []byte,
+) error {
+	cluster := &clusterv1.ManagedCluster{}
+	if err := c.Get(ctx, types.NamespacedName{Name: clusterName}, cluster); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if cluster.Annotations[constants.ManagedClusterMigrationAnnotation] != migrationID {
+		return nil
+	}
+	if meta.IsStatusConditionTrue(cluster.Status.Conditions, clusterv1.ManagedClusterConditionAvailable) {
+		if len(sourceKubeconfig) == 0 {
+			return fmt.Errorf("the cluster %s has joined the target hub", clusterName)
+		}
+		if err := deployBootstrapKubeconfig(ctx, c, clusterName, sourceKubeconfig, true); err != nil {
+			return err
+		}
+		return detachCluster(ctx, c, clusterName)
+	}
+	if err := c.Delete(ctx, cluster); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the cluster from the target hub: %w", err)
+	}
+	return nil
+}
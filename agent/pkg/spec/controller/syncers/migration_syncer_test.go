@@ -0,0 +1,209 @@
+package syncers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+const importYAML = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: open-cluster-management-agent
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bootstrap-hub-kubeconfig
+  namespace: open-cluster-management-agent
+type: Opaque
+data:
+  kubeconfig: a3ViZWNvbmZpZw==
+`
+
+func TestBootstrapKubeconfig(t *testing.T) {
+	importSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1-import", Namespace: "cluster1"},
+		Data:       map[string][]byte{importSecretKey: []byte(importYAML)},
+	}
+	kubeconfig, err := bootstrapKubeconfig(importSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, "kubeconfig", string(kubeconfig))
+
+	importSecret.Data[importSecretKey] = []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: foo\n")
+	_, err = bootstrapKubeconfig(importSecret)
+	assert.Error(t, err)
+}
+
+func TestMigrationDetachAndRollback(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, clusterv1.AddToScheme(scheme))
+	assert.NoError(t, workv1.AddToScheme(scheme))
+	ctx := context.Background()
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+			Spec:       clusterv1.ManagedClusterSpec{HubAcceptsClient: true},
+		},
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: migrationWorkName, Namespace: "cluster1"}},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cluster2",
+				Annotations: map[string]string{constants.ManagedClusterMigrationAnnotation: "m1"},
+			},
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cluster3",
+				Annotations: map[string]string{constants.ManagedClusterMigrationAnnotation: "m1"},
+			},
+			Status: clusterv1.ManagedClusterStatus{Conditions: []metav1.Condition{{
+				Type:   clusterv1.ManagedClusterConditionAvailable,
+				Status: metav1.ConditionTrue,
+			}}},
+		},
+	).Build()
+
+	// the source hub detaches the cluster
+	assert.NoError(t, detachCluster(ctx, c, "cluster1"))
+	err := c.Get(ctx, types.NamespacedName{Name: "cluster1"}, &clusterv1.ManagedCluster{})
+	assert.True(t, apierrors.IsNotFound(err))
+	err = c.Get(ctx, types.NamespacedName{Namespace: "cluster1", Name: migrationWorkName}, &workv1.ManifestWork{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.NoError(t, detachCluster(ctx, c, "cluster1"))
+
+	// the target hub removes the cluster which hasn't joined
+	assert.NoError(t, removePreparedCluster(ctx, c, "m2", "cluster2", nil))
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Name: "cluster2"}, &clusterv1.ManagedCluster{}))
+	assert.NoError(t, removePreparedCluster(ctx, c, "m1", "cluster2", nil))
+	err = c.Get(ctx, types.NamespacedName{Name: "cluster2"}, &clusterv1.ManagedCluster{})
+	assert.True(t, apierrors.IsNotFound(err))
+	// the joined cluster is only removed with the bootstrap kubeconfig of the source hub
+	assert.Error(t, removePreparedCluster(ctx, c, "m1", "cluster3", nil))
+}
+
+func TestMigrationRestoreCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, clusterv1.AddToScheme(scheme))
+	assert.NoError(t, workv1.AddToScheme(scheme))
+	ctx := context.Background()
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1-import", Namespace: "cluster1"},
+			Data:       map[string][]byte{importSecretKey: []byte(importYAML)},
+		},
+		&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: migrationWorkName, Namespace: "cluster1"}},
+	).Build()
+
+	// the source hub exports its bootstrap kubeconfig for the target hub
+	kubeconfig, err := restoreCluster(ctx, c, "cluster1")
+	assert.NoError(t, err)
+	assert.Equal(t, "kubeconfig", string(kubeconfig))
+	cluster := &clusterv1.ManagedCluster{}
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Name: "cluster1"}, cluster))
+	assert.True(t, cluster.Spec.HubAcceptsClient)
+	work := &workv1.ManifestWork{}
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "cluster1", Name: migrationWorkName}, work))
+	assert.Len(t, work.Spec.Workload.Manifests, 1)
+}
+
+func TestMigrationClusterSetBindings(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, clusterv1.AddToScheme(scheme))
+	assert.NoError(t, clusterv1beta2.AddToScheme(scheme))
+	ctx := context.Background()
+
+	source := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{
+			Name:   "cluster1",
+			Labels: map[string]string{clusterv1beta2.ClusterSetLabel: "set1"},
+		}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster2"}},
+		&clusterv1beta2.ManagedClusterSetBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: "ns2"},
+			Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: "set1"},
+		},
+		&clusterv1beta2.ManagedClusterSetBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: "ns1"},
+			Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: "set1"},
+		},
+		&clusterv1beta2.ManagedClusterSetBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "set2", Namespace: "ns1"},
+			Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: "set2"},
+		},
+	).Build()
+
+	namespaces, err := exportClusterSetBindings(ctx, source, "cluster1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns1", "ns2"}, namespaces)
+	namespaces, err = exportClusterSetBindings(ctx, source, "cluster2")
+	assert.NoError(t, err)
+	assert.Empty(t, namespaces)
+
+	// the target hub binds the cluster set to the same namespaces
+	target := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}},
+	).Build()
+	assert.NoError(t, bindClusterSet(ctx, target, "set1", []string{"ns1", "ns2"}))
+	assert.NoError(t, bindClusterSet(ctx, target, "set1", []string{"ns1", "ns2"}))
+	for _, namespace := range []string{"ns1", "ns2"} {
+		binding := &clusterv1beta2.ManagedClusterSetBinding{}
+		assert.NoError(t, target.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "set1"}, binding))
+		assert.Equal(t, "set1", binding.Spec.ClusterSet)
+	}
+}
+
+func TestMigrationSyncerReport(t *testing.T) {
+	producer := &fakeProducer{}
+	syncer := NewMigrationSyncer(nil, &config.AgentConfig{LeafHubName: "hub1"}, producer)
+	syncer.results["m1/Prepare/cluster1"] = &spec.MigrationStatus{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStagePrepare,
+		Clusters:    []*spec.MigrationClusterStatus{{Name: "cluster1", BootstrapKubeconfig: []byte("kubeconfig")}},
+		CompletedAt: time.Now(),
+	}
+	syncer.results["m0/Detach/cluster1"] = &spec.MigrationStatus{
+		MigrationID: "m0",
+		Stage:       spec.MigrationStageDetach,
+		CompletedAt: time.Now().Add(-2 * migrationResultTTL),
+	}
+
+	// the result of the stage which is run is reported again
+	payload, err := json.Marshal(&spec.MigrationSpec{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStagePrepare,
+		Clusters:    []*spec.MigrationClusterSpec{{Name: "cluster1"}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, syncer.Sync(constants.ManagedClusterMigrationMsgKey, payload))
+	assert.Len(t, producer.messages, 1)
+	assert.Equal(t, "hub1."+constants.ManagedClusterMigrationStatusMsgKey, producer.messages[0].Key)
+	statusBundle := &spec.MigrationStatusBundle{}
+	assert.NoError(t, json.Unmarshal(producer.messages[0].Payload, statusBundle))
+	assert.Len(t, statusBundle.Objects, 1, "the expired result is removed")
+	assert.Equal(t, "kubeconfig", string(statusBundle.Objects[0].Clusters[0].BootstrapKubeconfig))
+}
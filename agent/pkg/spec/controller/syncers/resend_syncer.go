@@ -19,7 +19,7 @@ func NewResyncSyncer() *resyncSyncer {
 	}
 }
 
-func (syncer *resyncSyncer) Sync(payload []byte) error {
+func (syncer *resyncSyncer) Sync(messageKey string, payload []byte) error {
 	syncer.log.Info("Resync bundle info")
 	bundleKeys := []string{}
 	if err := json.Unmarshal(payload, &bundleKeys); err != nil {
@@ -27,18 +27,20 @@ func (syncer *resyncSyncer) Sync(payload []byte) error {
 		return err
 	}
 
+	if cache.Cache == nil {
+		syncer.log.Info("Cache is nil, do not need to resync info")
+		return nil
+	}
 	for _, bundleKey := range bundleKeys {
-		syncer.log.Info("Resync bundle", "key", bundleKey)
-		if cache.Cache == nil {
-			syncer.log.Info("Cache is nil, do not need to resync info")
-			return nil
-		}
-		_, ok := cache.Cache[bundleKey]
+		// the bundle isn't registered if the syncer isn't enabled on the hub, e.g. the global resource syncers
+		bundle, ok := cache.Cache[bundleKey]
 		if !ok {
-			syncer.log.Info("Cache is nil, do not need to resync info", "bundle key", bundleKey)
-			return nil
+			syncer.log.Info("Bundle isn't found in the cache, do not need to resync info", "bundle key", bundleKey)
+			continue
 		}
-		cache.Cache[bundleKey].GetVersion().Incr()
+		syncer.log.Info("Resync bundle", "key", bundleKey)
+		// the bundle is resent in the next sync period once the version is increased
+		bundle.GetVersion().Incr()
 	}
 	return nil
 }
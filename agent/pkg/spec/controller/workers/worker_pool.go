@@ -70,6 +70,22 @@ func (pool *WorkerPool) Start(ctx context.Context) error {
 	return nil
 }
 
+// Size returns the number of the workers in the pool.
+func (pool *WorkerPool) Size() int {
+	return pool.poolSize
+}
+
+// Pending returns the number of the jobs waiting for the workers, it includes the jobs of the impersonation workers.
+func (pool *WorkerPool) Pending() int {
+	pending := len(pool.jobsQueue)
+	pool.impersonationWorkersLock.Lock()
+	defer pool.impersonationWorkersLock.Unlock()
+	for _, workerQueue := range pool.impersonationWorkersQueues {
+		pending += len(workerQueue)
+	}
+	return pending
+}
+
 func (pool *WorkerPool) Submit(job *Job) {
 	pool.initializationWaitingGroup.Wait() // start running jobs only after some initialization steps have finished.
 
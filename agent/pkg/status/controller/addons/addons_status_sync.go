@@ -0,0 +1,56 @@
+package addons
+
+import (
+	"context"
+	"fmt"
+
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+	clustersv1alpha1 "open-cluster-management.io/api/cluster/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/generic"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	genericbundle "github.com/stolostron/multicluster-global-hub/pkg/bundle/generic"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/utils"
+)
+
+// AddManagedClusterAddOnSyncer adds the managed cluster addons status controller to the manager.
+func AddManagedClusterAddOnSyncer(mgr ctrl.Manager, producer transport.Producer) error {
+	createObjFunction := func() bundle.Object { return &addonv1alpha1.ManagedClusterAddOn{} }
+	leafHubName := config.GetLeafHubName()
+	transportBundleKey := fmt.Sprintf("%s.%s", leafHubName, constants.ManagedClusterAddOnsMsgKey)
+
+	bundleCollection := []*generic.BundleEntry{ // single bundle for managed cluster addons
+		generic.NewBundleEntry(transportBundleKey,
+			genericbundle.NewGenericStatusBundle(leafHubName, manipulateAddOnFunc(mgr.GetClient())),
+			func() bool { return true }),
+	}
+
+	// the addons are in the namespaces of the managed clusters
+	statusFilter := &generic.StatusFilter{
+		ClusterNameFunc: func(object bundle.Object) string { return object.GetNamespace() },
+	}
+
+	return generic.NewFilteredStatusSyncer(mgr, "addons-status-sync", producer, bundleCollection,
+		createObjFunction, nil, statusFilter, config.GetManagerClusterDuration)
+}
+
+// manipulateAddOnFunc drops the managed fields, and records the ACM version of the hub which installs the addon
+func manipulateAddOnFunc(c client.Client) func(object bundle.Object) {
+	return func(object bundle.Object) {
+		object.SetManagedFields(nil)
+
+		versionClaim := &clustersv1alpha1.ClusterClaim{}
+		err := c.Get(context.Background(), client.ObjectKey{Name: constants.VersionClusterClaimName}, versionClaim)
+		if err != nil || versionClaim.Spec.Value == "" {
+			return
+		}
+		utils.AddAnnotations(object, map[string]string{
+			constants.ManagedHubVersionAnnotation: versionClaim.Spec.Value,
+		})
+	}
+}
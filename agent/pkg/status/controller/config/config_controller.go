@@ -63,9 +63,15 @@ func (c *hubOfHubsConfigController) Reconcile(ctx context.Context, request ctrl.
 	c.setSyncInterval(agentConfigMap, PolicyIntervalKey)
 	c.setSyncInterval(agentConfigMap, HubClusterInfoIntervalKey)
 	c.setSyncInterval(agentConfigMap, HubClusterHeartBeatIntervalKey)
+	c.setSyncInterval(agentConfigMap, DriftDetectionIntervalKey)
 
 	c.setAgentConfig(agentConfigMap, AgentAggregationKey)
 	c.setAgentConfig(agentConfigMap, EnableLocalPolicyKey)
+	c.setAgentConfig(agentConfigMap, DriftPolicyKey)
+
+	c.setStatusFilter(agentConfigMap, ManagedClusterFilter, ManagedClusterLabelSelectorKey, "", ManagedClusterSetsKey)
+	c.setStatusFilter(agentConfigMap, PolicyFilter, PolicyLabelSelectorKey, PolicyNamespacesKey, "")
+	c.setRedactionRules(agentConfigMap)
 
 	reqLogger.V(2).Info("Reconciliation complete.")
 	return ctrl.Result{}, nil
@@ -94,3 +100,37 @@ func (c *hubOfHubsConfigController) setAgentConfig(configMap *v1.ConfigMap, conf
 	}
 	agentConfigs[configKey] = AgentConfigValue(val)
 }
+
+func (c *hubOfHubsConfigController) setStatusFilter(configMap *v1.ConfigMap, resource StatusFilterResource,
+	labelSelectorKey, namespacesKey, clusterSetsKey AgentConfigKey,
+) {
+	labelSelector := configMap.Data[string(labelSelectorKey)]
+	namespaces := configMap.Data[string(namespacesKey)]
+	clusterSets := configMap.Data[string(clusterSetsKey)]
+
+	var filter *StatusFilter
+	if labelSelector != "" || namespaces != "" || clusterSets != "" {
+		var err error
+		if filter, err = NewStatusFilter(labelSelector, namespaces, clusterSets); err != nil {
+			// keep the existing filter, otherwise the excluded objects are reported by the invalid configuration
+			c.log.Error(err, "invalid status filter, using the existing filter", "resource", resource)
+			return
+		}
+	}
+	if SetStatusFilter(resource, filter) {
+		c.log.Info("status filter is updated", "resource", resource, "labelSelector", labelSelector,
+			"namespaces", namespaces, "clusterSets", clusterSets)
+	}
+}
+
+func (c *hubOfHubsConfigController) setRedactionRules(configMap *v1.ConfigMap) {
+	updated, err := SetRedactionRules(configMap.Data[string(RedactionRulesKey)])
+	if err != nil {
+		// keep the existing rules, otherwise the sensitive fields are reported by the invalid configuration
+		c.log.Error(err, "invalid redaction rules, using the existing rules")
+		return
+	}
+	if updated {
+		c.log.Info("redaction rules are updated")
+	}
+}
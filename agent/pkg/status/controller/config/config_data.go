@@ -11,10 +11,12 @@ var (
 		PolicyIntervalKey:              5 * time.Second,
 		HubClusterInfoIntervalKey:      60 * time.Second,
 		HubClusterHeartBeatIntervalKey: 60 * time.Second,
+		DriftDetectionIntervalKey:      60 * time.Second,
 	}
 	agentConfigs = map[AgentConfigKey]AgentConfigValue{
 		AgentAggregationKey:  AggregationFull,
 		EnableLocalPolicyKey: EnableLocalPolicyTrue,
+		DriftPolicyKey:       DriftPolicyReport,
 	}
 )
 
@@ -27,6 +29,8 @@ const (
 	HubClusterHeartBeatIntervalKey AgentConfigKey = "hubClusterHeartbeat"
 	AgentAggregationKey            AgentConfigKey = "aggregationLevel"
 	EnableLocalPolicyKey           AgentConfigKey = "enableLocalPolicies"
+	DriftDetectionIntervalKey      AgentConfigKey = "driftDetection"
+	DriftPolicyKey                 AgentConfigKey = "driftPolicy"
 )
 
 type AgentConfigValue string
@@ -36,6 +40,11 @@ const (
 	AggregationMinimal     AgentConfigValue = "minimal"
 	EnableLocalPolicyTrue  AgentConfigValue = "true"
 	EnableLocalPolicyFalse AgentConfigValue = "false"
+	// DriftPolicyReport only reports the drift of the global resources to the global hub
+	DriftPolicyReport AgentConfigValue = "report"
+	// DriftPolicyRevert reverts the drifted global resources to the spec from the global hub, and reports it. the
+	// object isn't reverted again within the drift detection interval, the drift is only reported until then
+	DriftPolicyRevert AgentConfigValue = "revert"
 )
 
 // ResolveSyncIntervalFunc is a function for resolving corresponding sync interval from SyncIntervals data structure.
@@ -60,6 +69,12 @@ func GetHeartbeatDuration() time.Duration {
 	return syncIntervals[HubClusterHeartBeatIntervalKey]
 }
 
+// GetDriftDetectionDuration returns the interval to resync the drift of the global resources, the changes of them are
+// detected once they're watched. it's also the minimum interval between the reverts of the same object.
+func GetDriftDetectionDuration() time.Duration {
+	return syncIntervals[DriftDetectionIntervalKey]
+}
+
 func GetLeafHubName() string {
 	return leafHubName
 }
@@ -71,3 +86,7 @@ func GetAggregationLevel() AgentConfigValue {
 func GetEnableLocalPolicy() AgentConfigValue {
 	return agentConfigs[EnableLocalPolicyKey]
 }
+
+func GetDriftPolicy() AgentConfigValue {
+	return agentConfigs[DriftPolicyKey]
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
+)
+
+// RedactionRulesKey redacts the sensitive fields of the reported objects, the value maps the kind to the json paths
+// of the fields, e.g. {"Policy": ["$.spec.policy-templates[*].objectDefinition.data"]}
+const RedactionRulesKey AgentConfigKey = "redactionRules"
+
+// redaction holds the rules configured in the agent config, the generation is increased once the rules are changed,
+// so that the syncers can redact the existing objects again.
+var redaction = struct {
+	sync.RWMutex
+	raw        string
+	rules      sensitive.Rules
+	generation int64
+}{}
+
+// SetRedactionRules replaces the redaction rules with the raw rules, it returns true if the rules are changed.
+func SetRedactionRules(raw string) (bool, error) {
+	redaction.Lock()
+	defer redaction.Unlock()
+
+	if raw == redaction.raw {
+		return false, nil
+	}
+	rules, err := sensitive.ParseRules(raw)
+	if err != nil {
+		return false, err
+	}
+	redaction.raw = raw
+	redaction.rules = rules
+	redaction.generation++
+	return true, nil
+}
+
+// GetRedactionGeneration returns the generation of the redaction rules.
+func GetRedactionGeneration() int64 {
+	redaction.RLock()
+	defer redaction.RUnlock()
+
+	return redaction.generation
+}
+
+// RedactObject redacts the sensitive fields of the object with the rules of the kind before it's bundled.
+func RedactObject(kind string, object runtime.Object) error {
+	redaction.RLock()
+	defer redaction.RUnlock()
+
+	_, err := redaction.rules.RedactObject(kind, object)
+	return err
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+)
+
+func TestRedactionRules(t *testing.T) {
+	defer func() {
+		_, _ = SetRedactionRules("")
+	}()
+
+	generation := GetRedactionGeneration()
+	updated, err := SetRedactionRules(`{"ManagedCluster": ["$.spec.managedClusterClientConfigs[*].caBundle"]}`)
+	require.NoError(t, err)
+	assert.True(t, updated)
+	updated, err = SetRedactionRules(`{"ManagedCluster": ["$.spec.managedClusterClientConfigs[*].caBundle"]}`)
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assert.Equal(t, generation+1, GetRedactionGeneration())
+
+	// the invalid rules are rejected, the existing rules are kept
+	_, err = SetRedactionRules(`{"ManagedCluster": ["$.spec["]}`)
+	assert.Error(t, err)
+	assert.Equal(t, generation+1, GetRedactionGeneration())
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+		Spec: clusterv1.ManagedClusterSpec{
+			ManagedClusterClientConfigs: []clusterv1.ClientConfig{{URL: "https://cluster1", CABundle: []byte("ca")}},
+		},
+	}
+	require.NoError(t, RedactObject("ManagedCluster", cluster))
+	assert.Equal(t, "https://cluster1", cluster.Spec.ManagedClusterClientConfigs[0].URL)
+	assert.NotEqual(t, []byte("ca"), cluster.Spec.ManagedClusterClientConfigs[0].CABundle)
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+)
+
+// StatusFilterResource is the resource type which can be filtered before it's reported to the global hub.
+type StatusFilterResource string
+
+const (
+	ManagedClusterFilter StatusFilterResource = "managedClusters"
+	PolicyFilter         StatusFilterResource = "policies"
+)
+
+const (
+	// ManagedClusterLabelSelectorKey only reports the managed clusters matching the label selector, e.g. "ci!=true"
+	ManagedClusterLabelSelectorKey AgentConfigKey = "managedClusters.labelSelector"
+	// ManagedClusterSetsKey only reports the managed clusters in the comma separated cluster sets
+	ManagedClusterSetsKey AgentConfigKey = "managedClusters.clusterSets"
+	// PolicyLabelSelectorKey only reports the policies matching the label selector
+	PolicyLabelSelectorKey AgentConfigKey = "policies.labelSelector"
+	// PolicyNamespacesKey only reports the policies in the comma separated namespaces
+	PolicyNamespacesKey AgentConfigKey = "policies.namespaces"
+)
+
+// StatusFilterKeys are the keys of the agent config to filter the reported resources.
+var StatusFilterKeys = []AgentConfigKey{
+	ManagedClusterLabelSelectorKey,
+	ManagedClusterSetsKey,
+	PolicyLabelSelectorKey,
+	PolicyNamespacesKey,
+}
+
+// StatusFilter decides whether an object is reported to the global hub. the empty filter includes all the objects.
+type StatusFilter struct {
+	selector    labels.Selector
+	namespaces  sets.Set[string]
+	clusterSets sets.Set[string]
+	// raw is the configuration of the filter, it's used to detect the change of the filter
+	raw string
+}
+
+// NewStatusFilter creates the filter with the label selector, and the comma separated namespaces and cluster sets.
+func NewStatusFilter(labelSelector, namespaces, clusterSets string) (*StatusFilter, error) {
+	filter := &StatusFilter{
+		namespaces:  splitValues(namespaces),
+		clusterSets: splitValues(clusterSets),
+	}
+	if strings.TrimSpace(labelSelector) != "" {
+		selector, err := labels.Parse(labelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", labelSelector, err)
+		}
+		filter.selector = selector
+	}
+	filter.raw = fmt.Sprintf("selector=%s;namespaces=%s;clusterSets=%s", labelSelector,
+		strings.Join(sets.List(filter.namespaces), ","), strings.Join(sets.List(filter.clusterSets), ","))
+	return filter, nil
+}
+
+// Matches returns true if the object is included by the filter.
+func (f *StatusFilter) Matches(object bundle.Object) bool {
+	if f == nil {
+		return true
+	}
+	if f.selector != nil && !f.selector.Matches(labels.Set(object.GetLabels())) {
+		return false
+	}
+	if f.namespaces.Len() > 0 && !f.namespaces.Has(object.GetNamespace()) {
+		return false
+	}
+	if f.clusterSets.Len() > 0 && !f.clusterSets.Has(object.GetLabels()[clusterv1beta2.ClusterSetLabel]) {
+		return false
+	}
+	return true
+}
+
+func splitValues(value string) sets.Set[string] {
+	values := sets.New[string]()
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values.Insert(item)
+		}
+	}
+	return values
+}
+
+// statusFilters holds the filters configured in the agent config, and the managed clusters excluded by the filter.
+// the generation is increased once the filters or the excluded clusters are changed, so that the syncers can
+// evaluate the existing objects again.
+var statusFilters = struct {
+	sync.RWMutex
+	filters            map[StatusFilterResource]*StatusFilter
+	filterGeneration   int64
+	excludedClusters   sets.Set[string]
+	excludedGeneration int64
+}{
+	filters:          map[StatusFilterResource]*StatusFilter{},
+	excludedClusters: sets.New[string](),
+}
+
+// SetStatusFilter replaces the filter of the resource, the nil filter includes all the objects.
+func SetStatusFilter(resource StatusFilterResource, filter *StatusFilter) bool {
+	statusFilters.Lock()
+	defer statusFilters.Unlock()
+
+	existing := statusFilters.filters[resource]
+	if (existing == nil && filter == nil) || (existing != nil && filter != nil && existing.raw == filter.raw) {
+		return false
+	}
+	if filter == nil {
+		delete(statusFilters.filters, resource)
+	} else {
+		statusFilters.filters[resource] = filter
+	}
+	statusFilters.filterGeneration++
+	return true
+}
+
+// IsStatusIncluded returns true if the object is reported to the global hub. the excluded managed clusters are
+// recorded, so that their compliance is removed from the reported policies.
+func IsStatusIncluded(resource StatusFilterResource, object bundle.Object) bool {
+	statusFilters.RLock()
+	included := statusFilters.filters[resource].Matches(object)
+	statusFilters.RUnlock()
+
+	if resource == ManagedClusterFilter {
+		setClusterExcluded(object.GetName(), !included)
+	}
+	return included
+}
+
+// ReleaseStatusObject forgets the deleted object.
+func ReleaseStatusObject(resource StatusFilterResource, object bundle.Object) {
+	if resource == ManagedClusterFilter {
+		setClusterExcluded(object.GetName(), false)
+	}
+}
+
+func setClusterExcluded(clusterName string, excluded bool) {
+	statusFilters.Lock()
+	defer statusFilters.Unlock()
+
+	if excluded == statusFilters.excludedClusters.Has(clusterName) {
+		return
+	}
+	if excluded {
+		statusFilters.excludedClusters.Insert(clusterName)
+	} else {
+		statusFilters.excludedClusters.Delete(clusterName)
+	}
+	statusFilters.excludedGeneration++
+}
+
+// GetStatusFilterGeneration returns the generation of the filter for the resource. the policies are also evaluated
+// again once the excluded clusters are changed.
+func GetStatusFilterGeneration(resource StatusFilterResource) int64 {
+	statusFilters.RLock()
+	defer statusFilters.RUnlock()
+
+	if resource == PolicyFilter {
+		return statusFilters.filterGeneration + statusFilters.excludedGeneration
+	}
+	return statusFilters.filterGeneration
+}
+
+// GetExcludedClustersGeneration returns the generation of the managed clusters excluded by the filter, the objects
+// of the managed clusters are evaluated again once it's changed.
+func GetExcludedClustersGeneration() int64 {
+	statusFilters.RLock()
+	defer statusFilters.RUnlock()
+
+	return statusFilters.excludedGeneration
+}
+
+// IsClusterExcluded returns true if the managed cluster is excluded by the filter.
+func IsClusterExcluded(clusterName string) bool {
+	statusFilters.RLock()
+	defer statusFilters.RUnlock()
+
+	return statusFilters.excludedClusters.Has(clusterName)
+}
+
+// RemoveExcludedClusters removes the compliance of the excluded clusters from the policy.
+func RemoveExcludedClusters(object bundle.Object) {
+	policy, ok := object.(*policiesv1.Policy)
+	if !ok {
+		return
+	}
+
+	statusFilters.RLock()
+	defer statusFilters.RUnlock()
+
+	if statusFilters.excludedClusters.Len() == 0 {
+		return
+	}
+	statuses := make([]*policiesv1.CompliancePerClusterStatus, 0, len(policy.Status.Status))
+	for _, status := range policy.Status.Status {
+		if status != nil && statusFilters.excludedClusters.Has(status.ClusterName) {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	policy.Status.Status = statuses
+}
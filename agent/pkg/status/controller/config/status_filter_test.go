@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+func TestStatusFilter(t *testing.T) {
+	_, err := NewStatusFilter("ci in (true", "", "")
+	assert.Error(t, err)
+
+	clusterFilter, err := NewStatusFilter("ci!=true", "", "prod, stage")
+	require.NoError(t, err)
+	newCluster := func(name string, labels map[string]string) *clusterv1.ManagedCluster {
+		return &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	assert.True(t, clusterFilter.Matches(newCluster("cluster1", map[string]string{
+		clusterv1beta2.ClusterSetLabel: "prod",
+	})))
+	assert.False(t, clusterFilter.Matches(newCluster("cluster2", map[string]string{
+		clusterv1beta2.ClusterSetLabel: "prod", "ci": "true",
+	})))
+	assert.False(t, clusterFilter.Matches(newCluster("cluster3", map[string]string{
+		clusterv1beta2.ClusterSetLabel: "sandbox",
+	})))
+
+	policyFilter, err := NewStatusFilter("", "default", "")
+	require.NoError(t, err)
+	assert.True(t, policyFilter.Matches(&policiesv1.Policy{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}}))
+	assert.False(t, policyFilter.Matches(&policiesv1.Policy{ObjectMeta: metav1.ObjectMeta{Namespace: "sandbox"}}))
+
+	var emptyFilter *StatusFilter
+	assert.True(t, emptyFilter.Matches(newCluster("cluster3", nil)))
+}
+
+func TestExcludedClusters(t *testing.T) {
+	defer func() {
+		SetStatusFilter(ManagedClusterFilter, nil)
+		ReleaseStatusObject(ManagedClusterFilter, &clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "ci-cluster"},
+		})
+	}()
+
+	filter, err := NewStatusFilter("ci!=true", "", "")
+	require.NoError(t, err)
+	generation := GetStatusFilterGeneration(ManagedClusterFilter)
+	assert.True(t, SetStatusFilter(ManagedClusterFilter, filter))
+	sameFilter, err := NewStatusFilter("ci!=true", "", "")
+	require.NoError(t, err)
+	assert.False(t, SetStatusFilter(ManagedClusterFilter, sameFilter))
+	assert.Equal(t, generation+1, GetStatusFilterGeneration(ManagedClusterFilter))
+
+	ciCluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "ci-cluster", Labels: map[string]string{"ci": "true"}},
+	}
+	policyGeneration := GetStatusFilterGeneration(PolicyFilter)
+	assert.False(t, IsStatusIncluded(ManagedClusterFilter, ciCluster))
+	assert.True(t, IsClusterExcluded("ci-cluster"))
+	// the policies are evaluated again to remove the compliance of the excluded cluster
+	assert.Equal(t, policyGeneration+1, GetStatusFilterGeneration(PolicyFilter))
+	assert.Equal(t, generation+1, GetStatusFilterGeneration(ManagedClusterFilter))
+
+	policy := &policiesv1.Policy{
+		Status: policiesv1.PolicyStatus{
+			Status: []*policiesv1.CompliancePerClusterStatus{
+				{ClusterName: "ci-cluster", ComplianceState: policiesv1.NonCompliant},
+				{ClusterName: "cluster1", ComplianceState: policiesv1.Compliant},
+			},
+		},
+	}
+	RemoveExcludedClusters(policy)
+	require.Len(t, policy.Status.Status, 1)
+	assert.Equal(t, "cluster1", policy.Status.Status[0].ClusterName)
+
+	ReleaseStatusObject(ManagedClusterFilter, ciCluster)
+	assert.False(t, IsClusterExcluded("ci-cluster"))
+	assert.Equal(t, policyGeneration+2, GetStatusFilterGeneration(PolicyFilter))
+}
@@ -10,8 +10,12 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/desiredstate"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/addons"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/apps"
 	agentstatusconfig "github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/drift"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/gitops"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/hubcluster"
 	localpolicies "github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/local_policies"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/localplacement"
@@ -49,6 +53,7 @@ func AddControllers(ctx context.Context, mgr ctrl.Manager, agentConfig *config.A
 
 	addControllerFunctions := []func(ctrl.Manager, transport.Producer) error{
 		managedclusters.AddMangedClusterSyncer,
+		addons.AddManagedClusterAddOnSyncer,
 		// apps.AddSubscriptionStatusesController,
 		localpolicies.AddLocalRootPolicySyncer,
 		localpolicies.AddLocalReplicatedPolicySyncer,
@@ -63,7 +68,17 @@ func AddControllers(ctx context.Context, mgr ctrl.Manager, agentConfig *config.A
 			placement.AddPlacementDecisionsController,
 			apps.AddSubscriptionReportsSyncer,
 			localplacement.AddLocalPlacementRulesController,
+			drift.AddDriftSyncer,
+			policies.AddComplianceDetailsSyncer,
 		)
+		// keep the baseline of the drift detection across the restarts
+		if err := desiredstate.AddPersister(mgr, agentConfig.PodNameSpace); err != nil {
+			return fmt.Errorf("failed to add the desired objects persister: %w", err)
+		}
+	}
+
+	if agentConfig.EnableGitOpsStatus {
+		addControllerFunctions = append(addControllerFunctions, gitops.AddApplicationSyncer)
 	}
 
 	for _, addControllerFunction := range addControllerFunctions {
@@ -0,0 +1,347 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/desiredstate"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/health"
+	statuscache "github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/cache"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/drift"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/utils"
+)
+
+const (
+	driftSyncerName = "global-resource-drift-syncer"
+	// eventDelay batches the changes of the watched objects, e.g. the changes made by the revert, into one detection
+	eventDelay = time.Second
+)
+
+// informerSource starts the informers of the watched kinds, it's the cache of the global resources.
+type informerSource interface {
+	GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error)
+}
+
+// driftSyncer compares the global resources on the managed hub with the last received spec bundles once they're
+// changed, and periodically as the resync. the drift is reverted or only reported to the global hub depending on the
+// drift policy of the agent config.
+type driftSyncer struct {
+	log    logr.Logger
+	client client.Client
+	// reader reads the objects from the cache of the global resources, which only has the objects with the global
+	// resource label, and informers watch the kinds of the desired objects to trigger the detection
+	reader    client.Reader
+	informers informerSource
+	// apiReader confirms the objects missing in the cache, the label of them may be removed rather than deleted
+	apiReader client.Reader
+	watched   map[schema.GroupVersionKind]bool
+	events    chan struct{}
+
+	transportBundleKey    string
+	lastSentBundleVersion metadata.BundleVersion
+	driftBundle           *drift.DriftBundle
+	// the drifts from the last round, to keep the detected time while the object remains drifted
+	reportedDrifts map[string]*drift.ObjectDrift
+	// revertedAt is the last revert of the objects, the object isn't reverted again within the interval, so the
+	// agent doesn't fight with the controller which keeps undoing the revert
+	revertedAt map[string]time.Time
+
+	producer     transport.Producer
+	intervalFunc config.ResolveSyncIntervalFunc
+}
+
+func AddDriftSyncer(mgr ctrl.Manager, producer transport.Producer) error {
+	leafHubName := config.GetLeafHubName()
+	driftBundle := drift.NewAgentDriftBundle(leafHubName)
+
+	// the global resources are cached by their own cache, so that the cache of the manager isn't changed
+	globalResource, err := labels.NewRequirement(constants.GlobalHubGlobalResourceLabel, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	globalResourceCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:               mgr.GetScheme(),
+		Mapper:               mgr.GetRESTMapper(),
+		DefaultLabelSelector: labels.NewSelector().Add(*globalResource),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the cache of the global resources: %w", err)
+	}
+	if err := mgr.Add(globalResourceCache); err != nil {
+		return err
+	}
+
+	syncer := &driftSyncer{
+		log:                   ctrl.Log.WithName(driftSyncerName),
+		client:                mgr.GetClient(),
+		reader:                globalResourceCache,
+		informers:             globalResourceCache,
+		apiReader:             mgr.GetAPIReader(),
+		watched:               make(map[schema.GroupVersionKind]bool),
+		events:                make(chan struct{}, 1),
+		transportBundleKey:    fmt.Sprintf("%s.%s", leafHubName, constants.GlobalResourceDriftMsgKey),
+		lastSentBundleVersion: *driftBundle.BundleVersion,
+		driftBundle:           driftBundle,
+		reportedDrifts:        make(map[string]*drift.ObjectDrift),
+		revertedAt:            make(map[string]time.Time),
+		producer:              producer,
+		intervalFunc:          config.GetDriftDetectionDuration,
+	}
+	health.RegisterStatusSyncer(syncer.transportBundleKey)
+	statuscache.RegistToCache(constants.GlobalResourceDriftMsgKey, driftBundle)
+	return mgr.Add(syncer)
+}
+
+func (s *driftSyncer) Start(ctx context.Context) error {
+	go s.periodicSync(ctx)
+	return nil
+}
+
+func (s *driftSyncer) periodicSync(ctx context.Context) {
+	currentSyncInterval := s.intervalFunc()
+	s.log.Info("sync interval has been set to", "interval", currentSyncInterval.String())
+
+	ticker := time.NewTicker(currentSyncInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("ctx is done, and exiting the drift detection loop!")
+			ticker.Stop()
+			return
+		case <-s.events:
+			// wait for the other changes of the batch, the event of them is merged into the pending one
+			select {
+			case <-ctx.Done():
+				continue
+			case <-time.After(eventDelay):
+			}
+			s.sync(ctx)
+		case <-ticker.C:
+			s.sync(ctx)
+			health.RecordSync(s.transportBundleKey, currentSyncInterval)
+
+			resolvedInterval := s.intervalFunc()
+			if resolvedInterval != currentSyncInterval {
+				currentSyncInterval = resolvedInterval
+				ticker.Reset(currentSyncInterval)
+				s.log.Info("sync interval has been reset to", "interval", currentSyncInterval.String())
+			}
+		}
+	}
+}
+
+// sync detects the drifts and sends the bundle if the drifts are changed.
+func (s *driftSyncer) sync(ctx context.Context) {
+	drifts := s.detect(ctx, config.GetDriftPolicy())
+	// update the bundle only if the drifts are changed
+	if !reflect.DeepEqual(drifts, s.driftBundle.Objects) {
+		s.driftBundle.Objects = drifts
+		s.driftBundle.GetVersion().Incr()
+	}
+	s.syncBundle(ctx)
+}
+
+// watch starts the informer of the kind of the desired object, the changes of the global resources of the kind
+// trigger the detection. It returns false if the kind can't be watched, e.g. the crd isn't installed yet.
+func (s *driftSyncer) watch(ctx context.Context, desired *unstructured.Unstructured) bool {
+	gvk := desired.GroupVersionKind()
+	if s.informers == nil || s.watched[gvk] {
+		return true
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	informer, err := s.informers.GetInformer(ctx, obj)
+	if err != nil {
+		s.log.Error(err, "failed to watch the global resources", "kind", gvk.String())
+		return false
+	}
+	trigger := func(interface{}) { s.trigger() }
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    trigger,
+		UpdateFunc: func(interface{}, interface{}) { s.trigger() },
+		DeleteFunc: trigger,
+	}); err != nil {
+		s.log.Error(err, "failed to handle the changes of the global resources", "kind", gvk.String())
+		return false
+	}
+	s.watched[gvk] = true
+	s.log.Info("watching the global resources", "kind", gvk.String())
+	return true
+}
+
+// trigger requests the detection, the request is dropped if there is a pending one.
+func (s *driftSyncer) trigger() {
+	select {
+	case s.events <- struct{}{}:
+	default:
+	}
+}
+
+// revertInterval is the minimum interval between the reverts of the same object.
+func (s *driftSyncer) revertInterval() time.Duration {
+	if s.intervalFunc == nil {
+		return 0
+	}
+	return s.intervalFunc()
+}
+
+// detect returns the drifts of the global resources in this round.
+func (s *driftSyncer) detect(ctx context.Context, policy config.AgentConfigValue) []*drift.ObjectDrift {
+	drifts := make([]*drift.ObjectDrift, 0)
+	reportedDrifts := make(map[string]*drift.ObjectDrift)
+	now := time.Now()
+	for key, revertedAt := range s.revertedAt {
+		if now.Sub(revertedAt) >= s.revertInterval() {
+			delete(s.revertedAt, key)
+		}
+	}
+
+	for _, desired := range desiredstate.List() {
+		if !s.watch(ctx, desired) {
+			continue
+		}
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(desired.GroupVersionKind())
+		namespacedName := types.NamespacedName{Namespace: desired.GetNamespace(), Name: desired.GetName()}
+		err := s.reader.Get(ctx, namespacedName, live)
+		if apierrors.IsNotFound(err) && s.apiReader != nil {
+			err = s.apiReader.Get(ctx, namespacedName, live)
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			s.log.Error(err, "failed to get the object", "kind", desired.GetKind(),
+				"namespace", desired.GetNamespace(), "name", desired.GetName())
+			continue
+		}
+
+		objectDrift := &drift.ObjectDrift{
+			APIVersion: desired.GetAPIVersion(),
+			Kind:       desired.GetKind(),
+			Namespace:  desired.GetNamespace(),
+			Name:       desired.GetName(),
+			Deleted:    apierrors.IsNotFound(err),
+			Action:     drift.DriftReported,
+			DetectedAt: time.Now().UTC().Truncate(time.Second),
+		}
+		if !objectDrift.Deleted {
+			if len(driftedFieldPaths(desired.Object, live.Object)) == 0 {
+				continue
+			}
+			if objectDrift.FieldPaths, err = redactedFieldPaths(desired, live); err != nil {
+				s.log.Error(err, "failed to redact the object", "kind", desired.GetKind(),
+					"namespace", desired.GetNamespace(), "name", desired.GetName())
+				continue
+			}
+		}
+		s.log.Info("detected the drift of the global resource", "kind", objectDrift.Kind, "namespace",
+			objectDrift.Namespace, "name", objectDrift.Name, "fields", objectDrift.FieldPaths, "deleted",
+			objectDrift.Deleted)
+
+		key := desiredstate.ObjectKey(desired)
+		if policy == config.DriftPolicyRevert {
+			if _, found := s.revertedAt[key]; found {
+				// the revert is undone within the interval, the drift is reported until the interval passes
+				s.log.Info("the revert is undone, the drift is reverted again after the interval", "kind",
+					objectDrift.Kind, "namespace", objectDrift.Namespace, "name", objectDrift.Name)
+			} else if err := utils.UpdateObject(ctx, s.client, desired.DeepCopy()); err != nil {
+				// the object is updated with the response of the server, so apply a copy of it
+				s.log.Error(err, "failed to revert the drift", "kind", objectDrift.Kind,
+					"namespace", objectDrift.Namespace, "name", objectDrift.Name)
+			} else {
+				objectDrift.Action = drift.DriftReverted
+				s.revertedAt[key] = now
+			}
+		}
+		if !isReported(desired) {
+			continue
+		}
+
+		// the detected time is kept while the object remains drifted, including the drift reverted repeatedly
+		if reported, ok := s.reportedDrifts[key]; ok && reported.Deleted == objectDrift.Deleted &&
+			reflect.DeepEqual(reported.FieldPaths, objectDrift.FieldPaths) {
+			objectDrift.DetectedAt = reported.DetectedAt
+		}
+		reportedDrifts[key] = objectDrift
+		drifts = append(drifts, objectDrift)
+	}
+
+	s.reportedDrifts = reportedDrifts
+	return drifts
+}
+
+// redactedFieldPaths returns the drifted fields of the redacted objects, so the paths don't tell which sensitive
+// values are changed. the drift of only the sensitive values is reported without the field paths.
+func redactedFieldPaths(desired, live *unstructured.Unstructured) ([]string, error) {
+	redactedDesired, redactedLive := desired.DeepCopy(), live.DeepCopy()
+	if err := config.RedactObject(desired.GetKind(), redactedDesired); err != nil {
+		return nil, err
+	}
+	if err := config.RedactObject(desired.GetKind(), redactedLive); err != nil {
+		return nil, err
+	}
+	return driftedFieldPaths(redactedDesired.Object, redactedLive.Object), nil
+}
+
+// isReported returns false if the object is excluded by the status filters, the drift of it is still reverted but
+// isn't reported to the global hub.
+func isReported(desired *unstructured.Unstructured) bool {
+	switch desired.GetKind() {
+	case "Policy":
+		return config.IsStatusIncluded(config.PolicyFilter, desired)
+	case "ManagedCluster":
+		return !config.IsClusterExcluded(desired.GetName())
+	}
+	return true
+}
+
+func (s *driftSyncer) syncBundle(ctx context.Context) {
+	// send to transport only if bundle has changed.
+	if !s.driftBundle.GetVersion().NewerThan(&s.lastSentBundleVersion) {
+		return
+	}
+
+	payloadBytes, err := json.Marshal(s.driftBundle)
+	if err != nil {
+		s.log.Error(err, "marshal drift bundle error", "key", s.transportBundleKey)
+		return
+	}
+
+	if err := s.producer.Send(ctx, &transport.Message{
+		Key:         s.transportBundleKey,
+		Destination: config.GetLeafHubName(),
+		MsgType:     constants.StatusBundle,
+		Payload:     payloadBytes,
+	}); err != nil {
+		s.log.Error(err, "send transport message error", "key", s.transportBundleKey)
+		health.RecordSendFailure(s.transportBundleKey, err)
+		return
+	}
+	health.RecordSendSuccess(s.transportBundleKey)
+
+	// 1. get into the next generation
+	// 2. set the lastSentBundleVersion to first version of next generation
+	s.driftBundle.GetVersion().Next()
+	s.lastSentBundleVersion = *s.driftBundle.GetVersion()
+}
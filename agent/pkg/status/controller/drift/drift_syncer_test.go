@@ -0,0 +1,225 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package drift
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/desiredstate"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/drift"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+func TestDriftedFieldPaths(t *testing.T) {
+	desired := map[string]interface{}{
+		"apiVersion": "policy.open-cluster-management.io/v1",
+		"kind":       "Policy",
+		"metadata": map[string]interface{}{
+			"name":   "policy1",
+			"labels": map[string]interface{}{"env": "prod"},
+		},
+		"spec": map[string]interface{}{
+			"disabled":          false,
+			"remediationAction": "enforce",
+			"policy-templates": []interface{}{
+				map[string]interface{}{"objectDefinition": map[string]interface{}{"kind": "ConfigurationPolicy"}},
+			},
+		},
+	}
+	live := map[string]interface{}{
+		"apiVersion": "policy.open-cluster-management.io/v1",
+		"kind":       "Policy",
+		"metadata": map[string]interface{}{
+			"name":            "policy1",
+			"resourceVersion": "100",
+			"labels":          map[string]interface{}{"env": "dev", "added": "true"},
+		},
+		"spec": map[string]interface{}{
+			"disabled":          true,
+			"remediationAction": "enforce",
+			"policy-templates": []interface{}{
+				map[string]interface{}{"objectDefinition": map[string]interface{}{
+					"kind": "ConfigurationPolicy", "defaulted": "value",
+				}},
+			},
+		},
+		"status": map[string]interface{}{"compliant": "Compliant"},
+	}
+	assert.Equal(t, []string{"metadata.labels.env", "spec.disabled"}, driftedFieldPaths(desired, live))
+
+	delete(live["spec"].(map[string]interface{}), "policy-templates")
+	assert.Equal(t, []string{"metadata.labels.env", "spec.disabled", "spec.policy-templates"},
+		driftedFieldPaths(desired, live))
+}
+
+func TestDetectDrift(t *testing.T) {
+	desiredstate.Reset()
+
+	scheme := runtime.NewScheme()
+	assert.Nil(t, policiesv1.AddToScheme(scheme))
+
+	livePolicy := &policiesv1.Policy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "policy1",
+			Namespace: "default",
+			Labels:    map[string]string{constants.GlobalHubGlobalResourceLabel: ""},
+		},
+		Spec: policiesv1.PolicySpec{
+			Disabled:          true,
+			RemediationAction: policiesv1.Enforce,
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(livePolicy).Build()
+
+	desiredPolicy := livePolicy.DeepCopy()
+	desiredPolicy.Spec.Disabled = false
+	desiredPolicy.Spec.RemediationAction = policiesv1.Inform
+	unstructuredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desiredPolicy)
+	assert.Nil(t, err)
+	desired := &unstructured.Unstructured{Object: unstructuredMap}
+	desired.SetAPIVersion(policiesv1.GroupVersion.String())
+	desired.SetKind("Policy")
+	desiredstate.Record(desired)
+
+	// the object without the global resource label isn't recorded
+	localPolicy := desired.DeepCopy()
+	localPolicy.SetName("local-policy")
+	localPolicy.SetLabels(nil)
+	desiredstate.Record(localPolicy)
+	assert.Len(t, desiredstate.List(), 1)
+
+	syncer := &driftSyncer{
+		log:            ctrl.Log.WithName(driftSyncerName),
+		client:         c,
+		reader:         c,
+		reportedDrifts: make(map[string]*drift.ObjectDrift),
+		revertedAt:     make(map[string]time.Time),
+	}
+
+	drifts := syncer.detect(context.TODO(), config.DriftPolicyReport)
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, "policy1", drifts[0].Name)
+	assert.Equal(t, drift.DriftReported, drifts[0].Action)
+	assert.False(t, drifts[0].Deleted)
+	assert.Equal(t, []string{"spec.disabled", "spec.remediationAction"}, drifts[0].FieldPaths)
+
+	// the detected time is kept while the object remains drifted
+	detectedAt := drifts[0].DetectedAt
+	drifts = syncer.detect(context.TODO(), config.DriftPolicyReport)
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, detectedAt, drifts[0].DetectedAt)
+
+	// the redacted fields aren't reported
+	_, err = config.SetRedactionRules(`{"Policy": ["$.spec.remediationAction"]}`)
+	assert.Nil(t, err)
+	drifts = syncer.detect(context.TODO(), config.DriftPolicyReport)
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, []string{"spec.disabled"}, drifts[0].FieldPaths)
+	_, err = config.SetRedactionRules("")
+	assert.Nil(t, err)
+	syncer.detect(context.TODO(), config.DriftPolicyReport)
+
+	// the object is deleted on the managed hub
+	assert.Nil(t, c.Delete(context.TODO(), livePolicy))
+	drifts = syncer.detect(context.TODO(), config.DriftPolicyReport)
+	assert.Len(t, drifts, 1)
+	assert.True(t, drifts[0].Deleted)
+
+	// the drift of the object excluded by the status filter isn't reported
+	filter, err := config.NewStatusFilter("env=prod", "", "")
+	assert.Nil(t, err)
+	config.SetStatusFilter(config.PolicyFilter, filter)
+	assert.Len(t, syncer.detect(context.TODO(), config.DriftPolicyReport), 0)
+	config.SetStatusFilter(config.PolicyFilter, nil)
+
+	// the object is deleted by the spec bundle
+	desiredstate.Remove(desired)
+	assert.Len(t, syncer.detect(context.TODO(), config.DriftPolicyReport), 0)
+}
+
+// revertClient counts the reverts, the reverts don't change the objects, like they're undone by another controller
+type revertClient struct {
+	client.Client
+	reverts int
+}
+
+func (c *revertClient) Patch(ctx context.Context, obj client.Object, patch client.Patch,
+	opts ...client.PatchOption,
+) error {
+	if patch.Type() == types.ApplyPatchType {
+		c.reverts++
+		return nil
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestRevertDrift(t *testing.T) {
+	desiredstate.Reset()
+
+	scheme := runtime.NewScheme()
+	assert.Nil(t, policiesv1.AddToScheme(scheme))
+
+	livePolicy := &policiesv1.Policy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "policy1",
+			Namespace: "default",
+			Labels:    map[string]string{constants.GlobalHubGlobalResourceLabel: ""},
+		},
+		Spec: policiesv1.PolicySpec{Disabled: true},
+	}
+	c := &revertClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(livePolicy).Build()}
+
+	desiredPolicy := livePolicy.DeepCopy()
+	desiredPolicy.Spec.Disabled = false
+	unstructuredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desiredPolicy)
+	assert.Nil(t, err)
+	desired := &unstructured.Unstructured{Object: unstructuredMap}
+	desired.SetAPIVersion(policiesv1.GroupVersion.String())
+	desired.SetKind("Policy")
+	desiredstate.Record(desired)
+
+	interval := time.Hour
+	syncer := &driftSyncer{
+		log:            ctrl.Log.WithName(driftSyncerName),
+		client:         c,
+		reader:         c,
+		reportedDrifts: make(map[string]*drift.ObjectDrift),
+		revertedAt:     make(map[string]time.Time),
+		intervalFunc:   func() time.Duration { return interval },
+	}
+
+	drifts := syncer.detect(context.TODO(), config.DriftPolicyRevert)
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, drift.DriftReverted, drifts[0].Action)
+	assert.Equal(t, 1, c.reverts)
+
+	// the revert is undone, the object isn't reverted again within the interval and the detected time is kept
+	detectedAt := drifts[0].DetectedAt
+	drifts = syncer.detect(context.TODO(), config.DriftPolicyRevert)
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, drift.DriftReported, drifts[0].Action)
+	assert.Equal(t, detectedAt, drifts[0].DetectedAt)
+	assert.Equal(t, 1, c.reverts)
+
+	// the object is reverted again after the interval, and the detected time is still kept
+	interval = 0
+	drifts = syncer.detect(context.TODO(), config.DriftPolicyRevert)
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, drift.DriftReverted, drifts[0].Action)
+	assert.Equal(t, detectedAt, drifts[0].DetectedAt)
+	assert.Equal(t, 2, c.reverts)
+}
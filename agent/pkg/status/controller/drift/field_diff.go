@@ -0,0 +1,84 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package drift
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// the top level fields are maintained by the managed hub, they aren't compared except the labels and annotations
+var ignoredFields = map[string]bool{
+	"apiVersion": true,
+	"kind":       true,
+	"metadata":   true,
+	"status":     true,
+}
+
+// driftedFieldPaths returns the paths of the fields whose value on the live object is different from the desired
+// object. only the fields set in the desired object are compared, so the defaulted fields aren't treated as drift.
+func driftedFieldPaths(desired, live map[string]interface{}) []string {
+	paths := []string{}
+	for field, desiredValue := range desired {
+		if ignoredFields[field] {
+			continue
+		}
+		liveValue, found := live[field]
+		paths = append(paths, diffValue(field, desiredValue, liveValue, found)...)
+	}
+
+	// the labels and annotations might be changed manually as well
+	for _, field := range []string{"labels", "annotations"} {
+		desiredMetadata, _ := desired["metadata"].(map[string]interface{})
+		liveMetadata, _ := live["metadata"].(map[string]interface{})
+		desiredValue, found := desiredMetadata[field]
+		if !found {
+			continue
+		}
+		liveValue, found := liveMetadata[field]
+		paths = append(paths, diffValue("metadata."+field, desiredValue, liveValue, found)...)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+func diffValue(path string, desired, live interface{}, found bool) []string {
+	if desired == nil {
+		return nil
+	}
+	if !found {
+		return []string{path}
+	}
+
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+		paths := []string{}
+		for key, value := range desiredValue {
+			liveFieldValue, found := liveValue[key]
+			paths = append(paths, diffValue(fmt.Sprintf("%s.%s", path, key), value, liveFieldValue, found)...)
+		}
+		return paths
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok || len(liveValue) != len(desiredValue) {
+			return []string{path}
+		}
+		paths := []string{}
+		for i := range desiredValue {
+			paths = append(paths, diffValue(fmt.Sprintf("%s[%d]", path, i), desiredValue[i], liveValue[i], true)...)
+		}
+		return paths
+	default:
+		if !reflect.DeepEqual(desired, live) {
+			return []string{path}
+		}
+		return nil
+	}
+}
@@ -1,6 +1,9 @@
 package generic
 
 import (
+	"strings"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/cache"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
 )
@@ -9,6 +12,7 @@ import (
 func NewBundleEntry(transportBundleKey string, bundle bundle.AgentBundle,
 	bundlePredicate func() bool,
 ) *BundleEntry {
+	registerResyncBundle(transportBundleKey, bundle)
 	return &BundleEntry{
 		transportBundleKey:    transportBundleKey,
 		bundle:                bundle,
@@ -28,6 +32,7 @@ type BundleEntry struct {
 func NewSharedBundleEntry(transportBundleKey string, baseAgentBundle bundle.BaseAgentBundle,
 	bundlePredicate func() bool,
 ) *SharedBundleEntry {
+	registerResyncBundle(transportBundleKey, baseAgentBundle)
 	return &SharedBundleEntry{
 		transportBundleKey:    transportBundleKey,
 		bundle:                baseAgentBundle,
@@ -42,3 +47,9 @@ type SharedBundleEntry struct {
 	bundlePredicate       func() bool
 	lastSentBundleVersion metadata.BundleVersion // not pointer so it does not point to the bundle's internal version
 }
+
+// registerResyncBundle makes the bundle resendable by the resync request of the manager, the transport bundle key is
+// in the format of <leaf hub>.<message key>.
+func registerResyncBundle(transportBundleKey string, baseAgentBundle bundle.BaseAgentBundle) {
+	cache.RegistToCache(transportBundleKey[strings.LastIndex(transportBundleKey, ".")+1:], baseAgentBundle)
+}
@@ -11,11 +11,16 @@ import (
 
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/health"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
@@ -28,14 +33,30 @@ const REQUEUE_PERIOD = 5 * time.Second
 // CreateObjectFunction is a function for how to create an object that is stored inside the bundle.
 type CreateObjectFunction func() bundle.Object
 
+// StatusFilter removes the objects excluded by the agent config from the bundles.
+type StatusFilter struct {
+	// Resource is the filter of the object itself, it's optional.
+	Resource config.StatusFilterResource
+	// ClusterNameFunc returns the managed cluster of the object, the objects of the managed clusters excluded by the
+	// filter aren't bundled. it's optional.
+	ClusterNameFunc func(object bundle.Object) string
+	// ManipulateObjFunc updates the included object before it's bundled, it's optional.
+	ManipulateObjFunc func(object bundle.Object)
+}
+
 type genericStatusSyncer struct {
 	log                     logr.Logger
 	client                  client.Client
+	scheme                  *runtime.Scheme
 	transport               transport.Producer
 	orderedBundleCollection []*BundleEntry
 	finalizerName           string
 	createBundleObjFunc     func() bundle.Object
+	kind                    string
 	resolveSyncIntervalFunc config.ResolveSyncIntervalFunc
+	predicate               predicate.Predicate
+	filter                  *StatusFilter
+	filterGeneration        int64
 	startOnce               sync.Once
 	lock                    sync.Mutex
 }
@@ -44,18 +65,39 @@ type genericStatusSyncer struct {
 func NewGenericStatusSyncer(mgr ctrl.Manager, logName string, producer transport.Producer,
 	orderedBundleCollection []*BundleEntry, createObjFunc CreateObjectFunction, predicate predicate.Predicate,
 	resolveSyncIntervalFunc config.ResolveSyncIntervalFunc,
+) error {
+	return NewFilteredStatusSyncer(mgr, logName, producer, orderedBundleCollection, createObjFunc, predicate, nil,
+		resolveSyncIntervalFunc)
+}
+
+// NewFilteredStatusSyncer creates the status syncer which only bundles the objects included by the status filter.
+// the existing objects are evaluated again once the filter is changed.
+func NewFilteredStatusSyncer(mgr ctrl.Manager, logName string, producer transport.Producer,
+	orderedBundleCollection []*BundleEntry, createObjFunc CreateObjectFunction, predicate predicate.Predicate,
+	filter *StatusFilter, resolveSyncIntervalFunc config.ResolveSyncIntervalFunc,
 ) error {
 	statusSyncCtrl := &genericStatusSyncer{
 		client:                  mgr.GetClient(),
+		scheme:                  mgr.GetScheme(),
 		log:                     ctrl.Log.WithName(logName),
 		transport:               producer,
 		orderedBundleCollection: orderedBundleCollection,
 		finalizerName:           constants.GlobalHubCleanupFinalizer,
 		createBundleObjFunc:     createObjFunc,
 		resolveSyncIntervalFunc: resolveSyncIntervalFunc,
+		predicate:               predicate,
+		filter:                  filter,
 		lock:                    sync.Mutex{},
 	}
+	gvk, err := apiutil.GVKForObject(createObjFunc(), mgr.GetScheme())
+	if err != nil {
+		return fmt.Errorf("failed to get the kind of the bundled object - %w", err)
+	}
+	statusSyncCtrl.kind = gvk.Kind
 	statusSyncCtrl.init()
+	for _, entry := range orderedBundleCollection {
+		health.RegisterStatusSyncer(entry.transportBundleKey)
+	}
 
 	controllerBuilder := ctrl.NewControllerManagedBy(mgr).For(createObjFunc())
 	if predicate != nil {
@@ -97,6 +139,8 @@ func (c *genericStatusSyncer) Reconcile(ctx context.Context, request ctrl.Reques
 		if err := c.deleteObjectAndFinalizer(ctx, object, reqLogger); err != nil {
 			return ctrl.Result{Requeue: true, RequeueAfter: REQUEUE_PERIOD}, err
 		}
+	} else if !c.isIncluded(object) {
+		c.deleteObjectFromBundles(object)
 	} else { // otherwise, the object was not deleted and no error occurred
 		if err := c.updateObjectAndFinalizer(ctx, object, reqLogger); err != nil {
 			return ctrl.Result{Requeue: true, RequeueAfter: REQUEUE_PERIOD}, err
@@ -124,28 +168,56 @@ func (c *genericStatusSyncer) updateObjectAndFinalizer(ctx context.Context, obje
 		}
 	}
 
-	cleanObject(object)
-
 	c.lock.Lock() // make sure bundles are not updated if we're during bundles sync
 	defer c.lock.Unlock()
 
+	c.updateObjectInBundles(object)
+	return nil
+}
+
+func (c *genericStatusSyncer) updateObjectInBundles(object bundle.Object) {
+	cleanObject(object)
+	if c.filter != nil && c.filter.ManipulateObjFunc != nil {
+		c.filter.ManipulateObjFunc(object)
+	}
+	// the object isn't bundled if it can't be redacted, otherwise the sensitive fields may be reported
+	if err := config.RedactObject(c.kind, object); err != nil {
+		c.log.Error(err, "failed to redact the object", "namespace", object.GetNamespace(), "name", object.GetName())
+		return
+	}
 	for _, entry := range c.orderedBundleCollection {
 		// update in each bundle from the collection according to their order.
 		entry.bundle.UpdateObject(object)
 	}
-	return nil
 }
 
-func (c *genericStatusSyncer) deleteObjectAndFinalizer(ctx context.Context, object bundle.Object,
-	log logr.Logger,
-) error {
+func (c *genericStatusSyncer) deleteObjectFromBundles(object bundle.Object) {
 	c.lock.Lock() // make sure bundles are not updated if we're during bundles sync
+	defer c.lock.Unlock()
 
 	for _, entry := range c.orderedBundleCollection {
-		entry.bundle.DeleteObject(object) // delete from all bundles.
+		entry.bundle.DeleteObject(object)
 	}
+}
+
+func (c *genericStatusSyncer) isIncluded(object bundle.Object) bool {
+	if c.filter == nil {
+		return true
+	}
+	if c.filter.ClusterNameFunc != nil && config.IsClusterExcluded(c.filter.ClusterNameFunc(object)) {
+		return false
+	}
+	return c.filter.Resource == "" || config.IsStatusIncluded(c.filter.Resource, object)
+}
 
-	c.lock.Unlock() // not using defer since remove finalizer may get delayed. release lock as soon as possible.
+func (c *genericStatusSyncer) deleteObjectAndFinalizer(ctx context.Context, object bundle.Object,
+	log logr.Logger,
+) error {
+	if c.filter != nil && c.filter.Resource != "" {
+		config.ReleaseStatusObject(c.filter.Resource, object)
+	}
+	// the bundles are unlocked before removing the finalizer, since remove finalizer may get delayed.
+	c.deleteObjectFromBundles(object)
 
 	return removeFinalizer(ctx, c.client, object, c.finalizerName)
 }
@@ -156,7 +228,11 @@ func (c *genericStatusSyncer) periodicSync() {
 
 	for {
 		<-ticker.C // wait for next time interval
+		c.refilterObjects()
 		c.syncBundles()
+		for _, entry := range c.orderedBundleCollection {
+			health.RecordSync(entry.transportBundleKey, currentSyncInterval)
+		}
 
 		resolvedInterval := c.resolveSyncIntervalFunc()
 
@@ -169,6 +245,77 @@ func (c *genericStatusSyncer) periodicSync() {
 	}
 }
 
+// refilterObjects evaluates the existing objects again once the status filter or the redaction rules are changed,
+// the objects excluded by the new filter are removed from the bundles, and the included objects are added to the
+// bundles.
+func (c *genericStatusSyncer) refilterObjects() {
+	// both generations only increase, so the sum is changed once any of them is changed
+	generation := config.GetRedactionGeneration()
+	if c.filter != nil && c.filter.Resource != "" {
+		generation += config.GetStatusFilterGeneration(c.filter.Resource)
+	}
+	if c.filter != nil && c.filter.ClusterNameFunc != nil {
+		generation += config.GetExcludedClustersGeneration()
+	}
+	if generation == c.filterGeneration {
+		return
+	}
+
+	objects, err := c.listObjects(context.TODO())
+	if err != nil {
+		c.log.Error(err, "failed to list the objects to apply the status filter")
+		return
+	}
+	included := 0
+	for _, object := range objects {
+		if c.predicate != nil && !c.predicate.Generic(event.GenericEvent{Object: object}) {
+			continue
+		}
+		if c.isObjectBeingDeleted(object) {
+			continue
+		}
+		if !c.isIncluded(object) {
+			c.deleteObjectFromBundles(object)
+			continue
+		}
+		c.lock.Lock()
+		c.updateObjectInBundles(object)
+		c.lock.Unlock()
+		included++
+	}
+	c.filterGeneration = generation
+	c.log.Info("status filter and redaction rules are applied", "kind", c.kind, "included", included)
+}
+
+func (c *genericStatusSyncer) listObjects(ctx context.Context) ([]bundle.Object, error) {
+	gvk, err := apiutil.GVKForObject(c.createBundleObjFunc(), c.scheme)
+	if err != nil {
+		return nil, err
+	}
+	listObj, err := c.scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return nil, err
+	}
+	objectList, ok := listObj.(client.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't an object list", gvk.Kind)
+	}
+	if err := c.client.List(ctx, objectList); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(objectList)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]bundle.Object, 0, len(items))
+	for _, item := range items {
+		if object, ok := item.(bundle.Object); ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
 func (c *genericStatusSyncer) syncBundles() {
 	c.lock.Lock() // make sure bundles are not updated if we're during bundles sync
 	defer c.lock.Unlock()
@@ -203,8 +350,10 @@ func (c *genericStatusSyncer) syncBundles() {
 				Payload:     payloadBytes,
 			}); err != nil {
 				c.log.Error(err, "send transport message error", "key", transportMessageKey)
+				health.RecordSendFailure(entry.transportBundleKey, err)
 				continue
 			}
+			health.RecordSendSuccess(entry.transportBundleKey)
 
 			// 1. get into the next generation
 			// 2. set the lastSentBundleVersion to first version of next generation
@@ -10,6 +10,7 @@ import (
 	"github.com/go-logr/logr"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/health"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
@@ -40,6 +41,7 @@ func NewGenericSharedBundleSyncer(mgr ctrl.Manager, producer transport.Producer,
 		lock:             &sync.Mutex{},
 	}
 	statusSyncCtrl.init()
+	health.RegisterStatusSyncer(bundleEntry.transportBundleKey)
 
 	for _, handler := range objectCollection {
 		err := newObjectHandler(mgr, producer, bundleEntry, handler, statusSyncCtrl.lock)
@@ -64,6 +66,7 @@ func (c *genericSharedBundleSyncer) periodicSync() {
 	for {
 		<-ticker.C // wait for next time interval
 		c.syncBundles()
+		health.RecordSync(c.bundleEntry.transportBundleKey, currentSyncInterval)
 
 		resolvedInterval := c.syncIntervalFunc()
 
@@ -107,8 +110,10 @@ func (c *genericSharedBundleSyncer) syncBundles() {
 			Payload:     payloadBytes,
 		}); err != nil {
 			c.log.Error(err, "send transport message error", "key", transportMessageKey)
+			health.RecordSendFailure(entry.transportBundleKey, err)
 			return
 		}
+		health.RecordSendSuccess(entry.transportBundleKey)
 
 		// 1. get into the next generation
 		// 2. set the lastSentBundleVersion to first version of next generation
@@ -9,7 +9,9 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport"
@@ -24,6 +26,7 @@ type objectHandler struct {
 	handler       bundle.SharedBundleObject
 	producer      transport.Producer
 	finalizerName string
+	kind          string
 	lock          *sync.Mutex
 }
 
@@ -41,6 +44,11 @@ func newObjectHandler(mgr ctrl.Manager, producer transport.Producer, bundleEntry
 		finalizerName: constants.GlobalHubCleanupFinalizer,
 		lock:          lock,
 	}
+	gvk, err := apiutil.GVKForObject(obj, mgr.GetScheme())
+	if err != nil {
+		return fmt.Errorf("failed to get the kind of the bundled object - %w", err)
+	}
+	statusSyncCtrl.kind = gvk.Kind
 
 	controllerBuilder := ctrl.NewControllerManagedBy(mgr).For(obj)
 	if handler.Predicate() != nil {
@@ -90,6 +98,10 @@ func (c *objectHandler) updateObjectAndFinalizer(ctx context.Context, object bun
 	}
 
 	cleanObject(object)
+	// the object isn't bundled if it can't be redacted, otherwise the sensitive fields may be reported
+	if err := config.RedactObject(c.kind, object); err != nil {
+		return fmt.Errorf("failed to redact the object - %w", err)
+	}
 
 	c.lock.Lock() // make sure bundles are not updated if we're during bundles sync
 	defer c.lock.Unlock()
@@ -0,0 +1,189 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/generic"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	genericbundle "github.com/stolostron/multicluster-global-hub/pkg/bundle/generic"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/gitops"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+// the fields of the application sent to the global hub, the others like the managed resources and the sync history
+// are dropped to keep the bundle small
+var applicationFields = [][]string{
+	{"spec", "project"},
+	{"spec", "destination"},
+	{"spec", "source", "repoURL"},
+	{"spec", "source", "path"},
+	{"spec", "source", "targetRevision"},
+	{"status", "health"},
+	{"status", "sync", "status"},
+	{"status", "sync", "revision"},
+	{"status", "sync", "revisions"},
+	{"status", "operationState", "phase"},
+	{"status", "reconciledAt"},
+}
+
+// the fields of each source of the multi-source application
+var sourceFields = []string{"repoURL", "path", "targetRevision", "chart", "ref"}
+
+// applicationCheckInterval is the interval to check whether argo cd is installed after the agent is started
+const applicationCheckInterval = time.Minute
+
+// AddApplicationSyncer adds the argo cd applications status controller to the manager. If argo cd isn't installed on
+// the managed hub, the syncer is added once the argo cd application is found.
+func AddApplicationSyncer(mgr ctrl.Manager, producer transport.Producer) error {
+	installed, err := applicationInstalled(mgr)
+	if err != nil {
+		return err
+	}
+	if installed {
+		return addApplicationSyncer(mgr, producer)
+	}
+	ctrl.Log.WithName("gitops-status-sync").Info("wait for the argo cd application to start the syncer",
+		"gvk", gitops.ApplicationGVK.String())
+	return mgr.Add(&applicationWatcher{mgr: mgr, producer: producer})
+}
+
+func applicationInstalled(mgr ctrl.Manager) (bool, error) {
+	_, err := mgr.GetRESTMapper().RESTMapping(gitops.ApplicationGVK.GroupKind(), gitops.ApplicationGVK.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get the rest mapping of the argo cd application: %w", err)
+	}
+	return true, nil
+}
+
+// applicationWatcher adds the syncer once argo cd is installed on the managed hub after the agent is started, the
+// controllers added to the started manager are started right away.
+type applicationWatcher struct {
+	mgr      ctrl.Manager
+	producer transport.Producer
+}
+
+func (w *applicationWatcher) Start(ctx context.Context) error {
+	log := ctrl.Log.WithName("gitops-status-sync")
+	ticker := time.NewTicker(applicationCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			installed, err := applicationInstalled(w.mgr)
+			if err != nil {
+				log.Error(err, "failed to check the argo cd application")
+				continue
+			}
+			if !installed {
+				continue
+			}
+			log.Info("the argo cd application is found, starting the syncer")
+			if err := addApplicationSyncer(w.mgr, w.producer); err != nil {
+				return fmt.Errorf("failed to add the argo cd application syncer: %w", err)
+			}
+			return nil
+		}
+	}
+}
+
+func addApplicationSyncer(mgr ctrl.Manager, producer transport.Producer) error {
+	createObjFunction := func() bundle.Object { return gitops.NewApplication() }
+	leafHubName := config.GetLeafHubName()
+	transportBundleKey := fmt.Sprintf("%s.%s", leafHubName, constants.GitOpsApplicationsMsgKey)
+
+	bundleCollection := []*generic.BundleEntry{ // single bundle for argo cd applications
+		generic.NewBundleEntry(transportBundleKey,
+			genericbundle.NewGenericStatusBundle(leafHubName, trimApplication),
+			func() bool { return true }),
+	}
+
+	statusFilter := &generic.StatusFilter{ClusterNameFunc: destinationCluster}
+
+	return generic.NewFilteredStatusSyncer(mgr, "gitops-status-sync", producer, bundleCollection,
+		createObjFunction, nil, statusFilter, config.GetManagerClusterDuration)
+}
+
+// destinationCluster returns the name of the destination cluster of the application, the managed clusters are
+// registered to argo cd with their names.
+func destinationCluster(object bundle.Object) string {
+	application, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return ""
+	}
+	name, _, _ := unstructured.NestedString(application.Object, "spec", "destination", "name")
+	return name
+}
+
+// trimApplication keeps the metadata and the status summary of the application
+func trimApplication(object bundle.Object) {
+	application, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	trimmed := map[string]interface{}{
+		"apiVersion": application.GetAPIVersion(),
+		"kind":       application.GetKind(),
+		"metadata": map[string]interface{}{
+			"name":            application.GetName(),
+			"namespace":       application.GetNamespace(),
+			"uid":             string(application.GetUID()),
+			"resourceVersion": application.GetResourceVersion(),
+			"labels":          toInterfaceMap(application.GetLabels()),
+		},
+	}
+	for _, fields := range applicationFields {
+		value, found, err := unstructured.NestedFieldCopy(application.Object, fields...)
+		if err != nil || !found {
+			continue
+		}
+		_ = unstructured.SetNestedField(trimmed, value, fields...)
+	}
+	if sources, found, err := unstructured.NestedSlice(application.Object, "spec", "sources"); err == nil && found {
+		_ = unstructured.SetNestedSlice(trimmed, trimSources(sources), "spec", "sources")
+	}
+	application.Object = trimmed
+}
+
+// trimSources keeps the location of each source of the multi-source application
+func trimSources(sources []interface{}) []interface{} {
+	trimmed := make([]interface{}, 0, len(sources))
+	for _, source := range sources {
+		sourceMap, ok := source.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		trimmedSource := map[string]interface{}{}
+		for _, field := range sourceFields {
+			if value, found := sourceMap[field]; found {
+				trimmedSource[field] = value
+			}
+		}
+		trimmed = append(trimmed, trimmedSource)
+	}
+	return trimmed
+}
+
+func toInterfaceMap(labels map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(labels))
+	for key, value := range labels {
+		result[key] = value
+	}
+	return result
+}
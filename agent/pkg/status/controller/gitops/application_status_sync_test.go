@@ -0,0 +1,135 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package gitops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestTrimApplication(t *testing.T) {
+	application := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata": map[string]interface{}{
+			"name":            "guestbook",
+			"namespace":       "openshift-gitops",
+			"uid":             "2b5e1a3c-0a4f-4e55-9d0c-0c5b1c7e2f11",
+			"resourceVersion": "10",
+			"labels":          map[string]interface{}{"team": "web"},
+			"annotations":     map[string]interface{}{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+		"spec": map[string]interface{}{
+			"project": "default",
+			"destination": map[string]interface{}{
+				"server":    "https://kubernetes.default.svc",
+				"namespace": "guestbook",
+			},
+			"source": map[string]interface{}{
+				"repoURL":        "https://github.com/argoproj/argocd-example-apps",
+				"path":           "guestbook",
+				"targetRevision": "HEAD",
+				"helm":           map[string]interface{}{"valueFiles": []interface{}{"values.yaml"}},
+			},
+		},
+		"status": map[string]interface{}{
+			"health": map[string]interface{}{"status": "Degraded"},
+			"sync": map[string]interface{}{
+				"status":     "OutOfSync",
+				"revision":   "53e28ff20cc530b9ada2173fbbd64d48338583ba",
+				"comparedTo": map[string]interface{}{},
+			},
+			"resources": []interface{}{map[string]interface{}{"kind": "Deployment", "name": "guestbook-ui"}},
+			"history":   []interface{}{map[string]interface{}{"id": int64(1)}},
+			"operationState": map[string]interface{}{
+				"phase":      "Failed",
+				"syncResult": map[string]interface{}{},
+			},
+		},
+	}}
+
+	trimApplication(application)
+
+	assert.Equal(t, "guestbook", application.GetName())
+	assert.Equal(t, "openshift-gitops", application.GetNamespace())
+	assert.Equal(t, "10", application.GetResourceVersion())
+	assert.Equal(t, "2b5e1a3c-0a4f-4e55-9d0c-0c5b1c7e2f11", string(application.GetUID()))
+	assert.Equal(t, map[string]string{"team": "web"}, application.GetLabels())
+	assert.Empty(t, application.GetAnnotations())
+
+	health, _, _ := unstructured.NestedString(application.Object, "status", "health", "status")
+	assert.Equal(t, "Degraded", health)
+	revision, _, _ := unstructured.NestedString(application.Object, "status", "sync", "revision")
+	assert.Equal(t, "53e28ff20cc530b9ada2173fbbd64d48338583ba", revision)
+	server, _, _ := unstructured.NestedString(application.Object, "spec", "destination", "server")
+	assert.Equal(t, "https://kubernetes.default.svc", server)
+	phase, _, _ := unstructured.NestedString(application.Object, "status", "operationState", "phase")
+	assert.Equal(t, "Failed", phase)
+
+	for _, dropped := range [][]string{
+		{"status", "resources"},
+		{"status", "history"},
+		{"status", "sync", "comparedTo"},
+		{"status", "operationState", "syncResult"},
+		{"spec", "source", "helm"},
+	} {
+		_, found, _ := unstructured.NestedFieldNoCopy(application.Object, dropped...)
+		assert.False(t, found, dropped)
+	}
+}
+
+func TestTrimMultiSourceApplication(t *testing.T) {
+	application := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata": map[string]interface{}{
+			"name":      "prometheus",
+			"namespace": "openshift-gitops",
+		},
+		"spec": map[string]interface{}{
+			"project": "default",
+			"sources": []interface{}{
+				map[string]interface{}{
+					"repoURL":        "https://prometheus-community.github.io/helm-charts",
+					"chart":          "prometheus",
+					"targetRevision": "15.7.1",
+					"helm":           map[string]interface{}{"valueFiles": []interface{}{"$values/values.yaml"}},
+				},
+				map[string]interface{}{
+					"repoURL":        "https://git.example.com/org/values.git",
+					"targetRevision": "dev",
+					"ref":            "values",
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"sync": map[string]interface{}{
+				"status":    "Synced",
+				"revisions": []interface{}{"15.7.1", "a6a4b2c9"},
+			},
+		},
+	}}
+
+	trimApplication(application)
+
+	sources, found, err := unstructured.NestedSlice(application.Object, "spec", "sources")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"repoURL":        "https://prometheus-community.github.io/helm-charts",
+			"chart":          "prometheus",
+			"targetRevision": "15.7.1",
+		},
+		map[string]interface{}{
+			"repoURL":        "https://git.example.com/org/values.git",
+			"targetRevision": "dev",
+			"ref":            "values",
+		},
+	}, sources)
+	revisions, _, _ := unstructured.NestedStringSlice(application.Object, "status", "sync", "revisions")
+	assert.Equal(t, []string{"15.7.1", "a6a4b2c9"}, revisions)
+}
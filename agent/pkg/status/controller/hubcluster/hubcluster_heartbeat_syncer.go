@@ -10,20 +10,23 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/go-logr/logr"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/health"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
-	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/cluster"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport"
 )
 
+const heartbeatSyncerName = "heartbeat-syncer"
+
 type heartbeatStatusSyncer struct {
 	log logr.Logger
 
 	transportBundleKey    string
 	lastSentBundleVersion metadata.BundleVersion // not pointer so it does not point to the bundle's internal version
-	heartbeatBundle       bundle.BaseAgentBundle
+	heartbeatBundle       *cluster.HubClusterHeartbeatBundle
 
 	transport    transport.Producer
 	intervalFunc config.ResolveSyncIntervalFunc
@@ -36,7 +39,7 @@ func AddHeartbeatStatusSyncer(mgr ctrl.Manager, producer transport.Producer) err
 	clusterHeartbeatBundle := cluster.NewAgentHubClusterHeartbeatBundle(leafHubName)
 
 	statusSyncCtrl := &heartbeatStatusSyncer{
-		log: ctrl.Log.WithName("heartbeat-syncer"),
+		log: ctrl.Log.WithName(heartbeatSyncerName),
 
 		transportBundleKey:    fmt.Sprintf("%s.%s", leafHubName, constants.HubClusterHeartbeatMsgKey),
 		heartbeatBundle:       clusterHeartbeatBundle,
@@ -46,6 +49,7 @@ func AddHeartbeatStatusSyncer(mgr ctrl.Manager, producer transport.Producer) err
 		intervalFunc: config.GetHeartbeatDuration,
 		lock:         sync.Mutex{},
 	}
+	health.RegisterStatusSyncer(statusSyncCtrl.transportBundleKey)
 	return mgr.Add(statusSyncCtrl)
 }
 
@@ -69,6 +73,7 @@ func (s *heartbeatStatusSyncer) periodicSync(ctx context.Context) {
 		case <-ticker.C: // wait for next time interval
 			s.heartbeatBundle.GetVersion().Incr()
 			s.syncBundle(ctx)
+			health.RecordSync(s.transportBundleKey, currentSyncInterval)
 			resolvedInterval := s.intervalFunc()
 			// reset ticker if sync interval has changed
 			if resolvedInterval != currentSyncInterval {
@@ -88,6 +93,8 @@ func (s *heartbeatStatusSyncer) syncBundle(ctx context.Context) {
 
 	// send to transport only if bundle has changed.
 	if bundleVersion.NewerThan(&s.lastSentBundleVersion) {
+		// attach the self health of the agent to the heartbeat
+		s.heartbeatBundle.Health = health.GetAgentHealth()
 
 		payloadBytes, err := json.Marshal(s.heartbeatBundle)
 		if err != nil {
@@ -102,8 +109,10 @@ func (s *heartbeatStatusSyncer) syncBundle(ctx context.Context) {
 			Payload:     payloadBytes,
 		}); err != nil {
 			s.log.Error(err, "send transport message error", "key", s.transportBundleKey)
+			health.RecordSendFailure(s.transportBundleKey, err)
 			return
 		}
+		health.RecordSendSuccess(s.transportBundleKey)
 
 		// 1. get into the next generation
 		// 2. set the lastSentBundleVersion to first version of next generation
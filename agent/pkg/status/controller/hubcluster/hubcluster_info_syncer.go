@@ -5,7 +5,6 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/cache"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
 	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/generic"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
@@ -16,7 +15,7 @@ import (
 
 // AddHubClusterInfoSyncer creates a controller and adds it to the manager.
 // this controller is responsible for syncing the hub cluster status.
-// right now, it only syncs the openshift console url.
+// right now, it syncs the cluster id, the console url, the grafana url and the status filters of the hub.
 func AddHubClusterInfoSyncer(mgr ctrl.Manager, producer transport.Producer) error {
 	leafHubName := config.GetLeafHubName()
 	clusterInfoBundle := cluster.NewAgentHubClusterInfoBundle(leafHubName)
@@ -27,9 +26,17 @@ func AddHubClusterInfoSyncer(mgr ctrl.Manager, producer transport.Producer) erro
 	objectCollection := []bundle.SharedBundleObject{
 		cluster.NewHubClusterInfoClaimObject(),
 		cluster.NewHubClusterInfoRouteObject(),
+		cluster.NewHubClusterInfoFilterObject(statusFilterKeys()),
 	}
 
-	cache.RegistToCache(constants.HubClusterInfoMsgKey, clusterInfoBundle)
 	return generic.NewGenericSharedBundleSyncer(mgr, producer, bundleEntry, objectCollection,
 		config.GetHubClusterInfoDuration)
 }
+
+func statusFilterKeys() []string {
+	keys := make([]string, 0, len(config.StatusFilterKeys))
+	for _, key := range config.StatusFilterKeys {
+		keys = append(keys, string(key))
+	}
+	return keys
+}
@@ -38,7 +38,14 @@ func AddLocalReplicatedPolicySyncer(mgr ctrl.Manager, producer transport.Produce
 			utils.HasLabelKey(object.GetLabels(), rootPolicyLabel)
 	})
 
-	return generic.NewGenericStatusSyncer(mgr, "local-replicas-policies-status-sync", producer,
-		localClusterPolicyBundleEntryCollection, createObjFunc, localClusterPolicyPredicate,
+	// the replicated policies are evaluated again once the excluded clusters are changed
+	statusFilter := &generic.StatusFilter{
+		ClusterNameFunc: func(object bundle.Object) string {
+			return object.GetLabels()[constants.PolicyEventClusterNameLabelKey]
+		},
+	}
+
+	return generic.NewFilteredStatusSyncer(mgr, "local-replicas-policies-status-sync", producer,
+		localClusterPolicyBundleEntryCollection, createObjFunc, localClusterPolicyPredicate, statusFilter,
 		config.GetPolicyDuration)
 }
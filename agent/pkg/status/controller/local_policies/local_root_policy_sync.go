@@ -32,8 +32,13 @@ func AddLocalRootPolicySyncer(mgr ctrl.Manager, producer transport.Producer) err
 			!utils.HasLabelKey(object.GetLabels(), rootPolicyLabel)
 	})
 
-	return generic.NewGenericStatusSyncer(mgr, "local-root-policies-status-sync", producer, bundleCollection,
-		createObjFunc, localPolicyPredicate, config.GetPolicyDuration)
+	statusFilter := &generic.StatusFilter{
+		Resource:          config.PolicyFilter,
+		ManipulateObjFunc: config.RemoveExcludedClusters,
+	}
+
+	return generic.NewFilteredStatusSyncer(mgr, "local-root-policies-status-sync", producer, bundleCollection,
+		createObjFunc, localPolicyPredicate, statusFilter, config.GetPolicyDuration)
 }
 
 func createBundleCollection(mgr ctrl.Manager) []*generic.BundleEntry {
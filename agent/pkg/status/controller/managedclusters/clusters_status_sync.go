@@ -35,6 +35,9 @@ func AddMangedClusterSyncer(mgr ctrl.Manager, producer transport.Producer) error
 			func() bool { return true }),
 	}
 
-	return generic.NewGenericStatusSyncer(mgr, "clusters-status-sync", producer, bundleCollection,
-		createObjFunction, nil, config.GetManagerClusterDuration)
+	// the clusters excluded by the agent config, e.g. the ephemeral CI clusters, aren't reported
+	statusFilter := &generic.StatusFilter{Resource: config.ManagedClusterFilter}
+
+	return generic.NewFilteredStatusSyncer(mgr, "clusters-status-sync", producer, bundleCollection,
+		createObjFunction, nil, statusFilter, config.GetManagerClusterDuration)
 }
@@ -0,0 +1,303 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package policies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	policiesV1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/health"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/cache"
+	"github.com/stolostron/multicluster-global-hub/agent/pkg/status/controller/config"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/grc"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/utils"
+)
+
+const complianceDetailsSyncerName = "compliance-details-syncer"
+
+// complianceDetailsSyncer reports the per-template status details of the replicated global policies periodically.
+// it isn't built on the generic status syncer, so that the replicated policies aren't added with the finalizer.
+type complianceDetailsSyncer struct {
+	log    logr.Logger
+	client client.Client
+
+	transportBundleKey    string
+	lastSentBundleVersion metadata.BundleVersion
+	detailsBundle         *grc.ComplianceDetailsBundle
+
+	producer     transport.Producer
+	intervalFunc config.ResolveSyncIntervalFunc
+}
+
+func AddComplianceDetailsSyncer(mgr ctrl.Manager, producer transport.Producer) error {
+	leafHubName := config.GetLeafHubName()
+	detailsBundle := grc.NewAgentComplianceDetailsBundle(leafHubName)
+
+	syncer := &complianceDetailsSyncer{
+		log:                   ctrl.Log.WithName(complianceDetailsSyncerName),
+		client:                mgr.GetClient(),
+		transportBundleKey:    fmt.Sprintf("%s.%s", leafHubName, constants.ComplianceDetailsMsgKey),
+		lastSentBundleVersion: *detailsBundle.BundleVersion,
+		detailsBundle:         detailsBundle,
+		producer:              producer,
+		intervalFunc:          config.GetPolicyDuration,
+	}
+	health.RegisterStatusSyncer(syncer.transportBundleKey)
+	cache.RegistToCache(constants.ComplianceDetailsMsgKey, detailsBundle)
+	return mgr.Add(syncer)
+}
+
+func (s *complianceDetailsSyncer) Start(ctx context.Context) error {
+	go s.periodicSync(ctx)
+	return nil
+}
+
+func (s *complianceDetailsSyncer) periodicSync(ctx context.Context) {
+	currentSyncInterval := s.intervalFunc()
+	s.log.Info("sync interval has been set to", "interval", currentSyncInterval.String())
+
+	ticker := time.NewTicker(currentSyncInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("ctx is done, and exiting the compliance details loop!")
+			ticker.Stop()
+			return
+		case <-ticker.C:
+			details, err := s.collect(ctx)
+			if err != nil {
+				s.log.Error(err, "failed to collect the compliance details")
+			} else {
+				details, truncated := boundDetails(details, grc.MaxComplianceDetailsBundleSize)
+				if truncated {
+					s.log.Info("the compliance details are truncated to the bundle size, the violations are kept first",
+						"reported", len(details), "size", grc.MaxComplianceDetailsBundleSize)
+				}
+				// update the bundle only if the details are changed
+				if truncated != s.detailsBundle.Truncated || !reflect.DeepEqual(details, s.detailsBundle.Objects) {
+					s.detailsBundle.Objects = details
+					s.detailsBundle.Truncated = truncated
+					s.detailsBundle.GetVersion().Incr()
+				}
+			}
+			s.syncBundle(ctx)
+			health.RecordSync(s.transportBundleKey, currentSyncInterval)
+
+			resolvedInterval := s.intervalFunc()
+			if resolvedInterval != currentSyncInterval {
+				currentSyncInterval = resolvedInterval
+				ticker.Reset(currentSyncInterval)
+				s.log.Info("sync interval has been reset to", "interval", currentSyncInterval.String())
+			}
+		}
+	}
+}
+
+// collect returns the details of the replicated policies which are propagated from the global policies.
+func (s *complianceDetailsSyncer) collect(ctx context.Context) ([]*grc.ComplianceDetails, error) {
+	policies := &policiesV1.PolicyList{}
+	if err := s.client.List(ctx, policies, client.HasLabels{rootPolicyLabel}); err != nil {
+		return nil, err
+	}
+
+	includedRoots, err := s.includedRootPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]*grc.ComplianceDetails, 0)
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if !utils.HasAnnotation(policy, constants.OriginOwnerReferenceAnnotation) ||
+			!includedRoots[policy.GetLabels()[rootPolicyLabel]] {
+			continue
+		}
+		clusterName := policy.GetLabels()[constants.PolicyEventClusterNameLabelKey]
+		if clusterName == "" {
+			// the replicated policy is in the namespace of the managed cluster
+			clusterName = policy.GetNamespace()
+		}
+		if config.IsClusterExcluded(clusterName) {
+			continue
+		}
+		// the messages of the templates might have the sensitive values
+		if err := config.RedactObject(policiesV1.Kind, policy); err != nil {
+			s.log.Error(err, "failed to redact the policy", "namespace", policy.GetNamespace(), "name", policy.GetName())
+			continue
+		}
+		policyID, _ := extractPolicyID(policy)
+		details = append(details, &grc.ComplianceDetails{
+			PolicyID:    policyID,
+			ClusterName: clusterName,
+			Templates:   extractTemplateDetails(policy),
+		})
+	}
+
+	sort.Slice(details, func(i, j int) bool {
+		if details[i].PolicyID != details[j].PolicyID {
+			return details[i].PolicyID < details[j].PolicyID
+		}
+		return details[i].ClusterName < details[j].ClusterName
+	})
+	return details, nil
+}
+
+// includedRootPolicies returns the root policies included by the policy status filter, they're keyed by the value of
+// the root policy label of the replicated policies.
+func (s *complianceDetailsSyncer) includedRootPolicies(ctx context.Context) (map[string]bool, error) {
+	policies := &policiesV1.PolicyList{}
+	if err := s.client.List(ctx, policies); err != nil {
+		return nil, err
+	}
+	included := make(map[string]bool, len(policies.Items))
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if _, found := policy.GetLabels()[rootPolicyLabel]; found ||
+			!config.IsStatusIncluded(config.PolicyFilter, policy) {
+			continue
+		}
+		included[fmt.Sprintf("%s.%s", policy.GetNamespace(), policy.GetName())] = true
+	}
+	return included, nil
+}
+
+// boundDetails keeps the details within the size, the details with the violations are kept before the others. the
+// kept details are in the order of the given ones, and it returns whether any of the details is dropped.
+func boundDetails(details []*grc.ComplianceDetails, maxSize int) ([]*grc.ComplianceDetails, bool) {
+	sizes := make([]int, len(details))
+	total := 0
+	for i, detail := range details {
+		payload, err := json.Marshal(detail)
+		if err == nil {
+			sizes[i] = len(payload)
+		}
+		total += sizes[i]
+	}
+	if total <= maxSize {
+		return details, false
+	}
+
+	kept := make([]bool, len(details))
+	size := 0
+	for _, violated := range []bool{true, false} {
+		for i, detail := range details {
+			if hasViolation(detail) != violated || size+sizes[i] > maxSize {
+				continue
+			}
+			kept[i] = true
+			size += sizes[i]
+		}
+	}
+
+	bounded := make([]*grc.ComplianceDetails, 0, len(details))
+	for i, detail := range details {
+		if kept[i] {
+			bounded = append(bounded, detail)
+		}
+	}
+	return bounded, true
+}
+
+func hasViolation(detail *grc.ComplianceDetails) bool {
+	for _, template := range detail.Templates {
+		if template.Compliance == string(policiesV1.NonCompliant) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractTemplateDetails returns the latest status of the templates of the replicated policy, the number of the
+// templates and the length of the messages are bounded to keep the bundle small.
+func extractTemplateDetails(policy *policiesV1.Policy) []*grc.TemplateDetails {
+	kinds := map[string]string{}
+	for _, template := range policy.Spec.PolicyTemplates {
+		if template == nil || template.ObjectDefinition.Raw == nil {
+			continue
+		}
+		definition := struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}{}
+		if err := json.Unmarshal(template.ObjectDefinition.Raw, &definition); err != nil {
+			continue
+		}
+		kinds[definition.Metadata.Name] = definition.Kind
+	}
+
+	templates := make([]*grc.TemplateDetails, 0)
+	for _, detail := range policy.Status.Details {
+		if detail == nil {
+			continue
+		}
+		if len(templates) == grc.MaxComplianceDetailsTemplates {
+			break
+		}
+		template := &grc.TemplateDetails{
+			Name:       detail.TemplateMeta.Name,
+			Kind:       kinds[detail.TemplateMeta.Name],
+			Compliance: string(detail.ComplianceState),
+		}
+		// the history is ordered from the newest to the oldest
+		if len(detail.History) > 0 {
+			template.Message = truncateMessage(detail.History[0].Message)
+			template.LastTimestamp = detail.History[0].LastTimestamp.UTC()
+		}
+		templates = append(templates, template)
+	}
+	return templates
+}
+
+func truncateMessage(message string) string {
+	runes := []rune(message)
+	if len(runes) <= grc.MaxComplianceDetailsMessageLength {
+		return message
+	}
+	return string(runes[:grc.MaxComplianceDetailsMessageLength-3]) + "..."
+}
+
+func (s *complianceDetailsSyncer) syncBundle(ctx context.Context) {
+	// send to transport only if bundle has changed.
+	if !s.detailsBundle.GetVersion().NewerThan(&s.lastSentBundleVersion) {
+		return
+	}
+
+	payloadBytes, err := json.Marshal(s.detailsBundle)
+	if err != nil {
+		s.log.Error(err, "marshal compliance details bundle error", "key", s.transportBundleKey)
+		return
+	}
+
+	if err := s.producer.Send(ctx, &transport.Message{
+		Key:         s.transportBundleKey,
+		Destination: config.GetLeafHubName(),
+		MsgType:     constants.StatusBundle,
+		Payload:     payloadBytes,
+	}); err != nil {
+		s.log.Error(err, "send transport message error", "key", s.transportBundleKey)
+		health.RecordSendFailure(s.transportBundleKey, err)
+		return
+	}
+	health.RecordSendSuccess(s.transportBundleKey)
+
+	// 1. get into the next generation
+	// 2. set the lastSentBundleVersion to first version of next generation
+	s.detailsBundle.GetVersion().Next()
+	s.lastSentBundleVersion = *s.detailsBundle.GetVersion()
+}
@@ -0,0 +1,106 @@
+package policies
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	policiesV1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/grc"
+)
+
+func TestExtractTemplateDetails(t *testing.T) {
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	policy := &policiesV1.Policy{
+		Spec: policiesV1.PolicySpec{
+			PolicyTemplates: []*policiesV1.PolicyTemplate{
+				{ObjectDefinition: runtime.RawExtension{
+					Raw: []byte(`{"kind":"ConfigurationPolicy","metadata":{"name":"check-namespace"}}`),
+				}},
+			},
+		},
+		Status: policiesV1.PolicyStatus{
+			Details: []*policiesV1.DetailsPerTemplate{
+				{
+					TemplateMeta:    metav1.ObjectMeta{Name: "check-namespace"},
+					ComplianceState: policiesV1.NonCompliant,
+					History: []policiesV1.ComplianceHistory{
+						{LastTimestamp: now, Message: strings.Repeat("x", 2*grc.MaxComplianceDetailsMessageLength)},
+						{LastTimestamp: metav1.NewTime(now.Add(-time.Minute)), Message: "Compliant"},
+					},
+				},
+			},
+		},
+	}
+	for i := 0; i < grc.MaxComplianceDetailsTemplates; i++ {
+		policy.Status.Details = append(policy.Status.Details, &policiesV1.DetailsPerTemplate{
+			TemplateMeta:    metav1.ObjectMeta{Name: "pending"},
+			ComplianceState: policiesV1.Pending,
+		})
+	}
+
+	templates := extractTemplateDetails(policy)
+	if len(templates) != grc.MaxComplianceDetailsTemplates {
+		t.Fatalf("expected %d templates, got %d", grc.MaxComplianceDetailsTemplates, len(templates))
+	}
+
+	first := templates[0]
+	if first.Name != "check-namespace" || first.Kind != "ConfigurationPolicy" ||
+		first.Compliance != string(policiesV1.NonCompliant) {
+		t.Errorf("unexpected template details: %+v", first)
+	}
+	if len(first.Message) != grc.MaxComplianceDetailsMessageLength || !strings.HasSuffix(first.Message, "...") {
+		t.Errorf("expected the message to be truncated, got length %d", len(first.Message))
+	}
+	if !first.LastTimestamp.Equal(now.Time) {
+		t.Errorf("expected the latest timestamp %v, got %v", now.Time, first.LastTimestamp)
+	}
+
+	if templates[1].Kind != "" || templates[1].Message != "" {
+		t.Errorf("unexpected template details: %+v", templates[1])
+	}
+}
+
+func TestBoundDetails(t *testing.T) {
+	newDetails := func(cluster string, compliance policiesV1.ComplianceState) *grc.ComplianceDetails {
+		return &grc.ComplianceDetails{
+			PolicyID:    "a7e0c7e8-8e6b-4c3c-a8b5-6c9f7b5d2a10",
+			ClusterName: cluster,
+			Templates: []*grc.TemplateDetails{
+				{Name: "check-namespace", Compliance: string(compliance), Message: strings.Repeat("x", 100)},
+			},
+		}
+	}
+	details := []*grc.ComplianceDetails{
+		newDetails("cluster1", policiesV1.Compliant),
+		newDetails("cluster2", policiesV1.NonCompliant),
+		newDetails("cluster3", policiesV1.Compliant),
+		newDetails("cluster4", policiesV1.NonCompliant),
+	}
+
+	bounded, truncated := boundDetails(details, grc.MaxComplianceDetailsBundleSize)
+	if truncated || len(bounded) != len(details) {
+		t.Fatalf("expected all the details within the size, got %d, truncated %v", len(bounded), truncated)
+	}
+
+	// the size of 3 details at most: the violations are kept first, then the compliant ones in order
+	payload, err := json.Marshal(details[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	bounded, truncated = boundDetails(details, 3*len(payload)+1)
+	if !truncated {
+		t.Fatal("expected the details to be truncated")
+	}
+	clusters := []string{}
+	for _, detail := range bounded {
+		clusters = append(clusters, detail.ClusterName)
+	}
+	if strings.Join(clusters, ",") != "cluster1,cluster2,cluster4" {
+		t.Errorf("unexpected bounded details: %v", clusters)
+	}
+}
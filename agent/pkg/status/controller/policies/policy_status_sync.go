@@ -42,9 +42,15 @@ func AddPolicyStatusSyncer(mgr ctrl.Manager, producer transport.Producer) (*gene
 
 	createObjFunction := func() bundle.Object { return &policiesV1.Policy{} }
 
+	statusFilter := &generic.StatusFilter{
+		Resource:          config.PolicyFilter,
+		ManipulateObjFunc: config.RemoveExcludedClusters,
+	}
+
 	// initialize policy status controller (contains multiple bundles)
-	if err := generic.NewGenericStatusSyncer(mgr, policiesStatusSyncLog, producer, bundleCollection, createObjFunction,
-		predicate.And(rootPolicyPredicate, ownerRefAnnotationPredicate), config.GetPolicyDuration); err != nil {
+	if err := generic.NewFilteredStatusSyncer(mgr, policiesStatusSyncLog, producer, bundleCollection,
+		createObjFunction, predicate.And(rootPolicyPredicate, ownerRefAnnotationPredicate), statusFilter,
+		config.GetPolicyDuration); err != nil {
 		return hybridSyncManager, fmt.Errorf("failed to add policies controller to the manager - %w", err)
 	}
 	return hybridSyncManager, nil
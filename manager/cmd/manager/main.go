@@ -34,11 +34,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/backup"
 	managerconfig "github.com/stolostron/multicluster-global-hub/manager/pkg/config"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/cronjob"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/eventcollector"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/monitoring"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/notification"
 	managerscheme "github.com/stolostron/multicluster-global-hub/manager/pkg/scheme"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer"
 	statussyncer "github.com/stolostron/multicluster-global-hub/manager/pkg/statussyncer"
@@ -46,6 +48,7 @@ import (
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 	commonobjects "github.com/stolostron/multicluster-global-hub/pkg/objects"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
 	"github.com/stolostron/multicluster-global-hub/pkg/statistics"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport/producer"
@@ -108,12 +111,22 @@ func parseFlags() *managerconfig.ManagerConfig {
 			"can be 'month', 'week', 'day', 'hour', 'minute' or 'second', default value is 'day'.")
 	pflag.DurationVar(&managerConfig.SyncerConfig.SpecSyncInterval, "spec-sync-interval", 5*time.Second,
 		"The synchronization interval of resources in spec.")
+	pflag.DurationVar(&managerConfig.SyncerConfig.SpecSnapshotInterval, "spec-snapshot-interval", time.Hour,
+		"The interval to broadcast all the resources in spec, only the changed resources are sent in between.")
 	pflag.DurationVar(&managerConfig.SyncerConfig.StatusSyncInterval, "status-sync-interval", 5*time.Second,
 		"The synchronization interval of resources in status.")
 	pflag.DurationVar(&managerConfig.SyncerConfig.DeletedLabelsTrimmingInterval, "deleted-labels-trimming-interval",
 		5*time.Second, "The trimming interval of deleted labels.")
 	pflag.IntVar(&managerConfig.DatabaseConfig.MaxOpenConns, "database-pool-size", 10,
 		"The size of database connection pool for the process user.")
+	pflag.IntVar(&managerConfig.DatabaseConfig.JobPoolSize, "database-job-pool-size", 2,
+		"The size of database connection pool for the background jobs, 0 shares the pool of the process user.")
+	pflag.IntVar(&managerConfig.DatabaseConfig.APIPoolSize, "database-api-pool-size", 5,
+		"The size of database connection pool for the API reads, 0 shares the pool of the process user.")
+	pflag.StringVar(&managerConfig.DatabaseConfig.ReadReplicaURL, "read-replica-database-url", "",
+		"The URL of the read replica database server which serves the API reads.")
+	pflag.DurationVar(&managerConfig.DatabaseConfig.MaxReplicaLag, "max-replica-lag", 30*time.Second,
+		"The API reads fall back to the primary database once the replica lags behind more than it, 0 ignores the lag.")
 	pflag.StringVar(&managerConfig.DatabaseConfig.ProcessDatabaseURL, "process-database-url", "",
 		"The URL of database server for the process user.")
 	pflag.StringVar(&managerConfig.DatabaseConfig.TransportBridgeDatabaseURL,
@@ -158,8 +171,14 @@ func parseFlags() *managerconfig.ManagerConfig {
 	pflag.IntVar(&managerConfig.ElectionConfig.RetryPeriod, "retry-period", 26, "controller leader retry period")
 	pflag.IntVar(&managerConfig.DatabaseConfig.DataRetention, "data-retention", 18,
 		"data retention indicates how many months the expired data will kept in the database")
+	pflag.StringVar(&managerConfig.DatabaseConfig.EncryptionKeyPath, "payload-encryption-key-path", "",
+		"The path of the AES key to encrypt the sensitive fields of the payloads stored in the database.")
+	pflag.StringVar(&managerConfig.DatabaseConfig.EncryptionRulesPath, "payload-encryption-rules-path", "",
+		"The path of the rules which map the table to the json paths of the fields to encrypt.")
 	pflag.BoolVar(&managerConfig.EnableGlobalResource, "enable-global-resource", false,
 		"enable the global resource feature.")
+	pflag.BoolVar(&managerConfig.EnableStatusSharding, "enable-status-sharding", false,
+		"process the status on all the replicas, the managed hubs are sharded by the kafka partitions.")
 
 	pflag.Parse()
 	// set zap logger
@@ -191,6 +210,35 @@ func completeConfig(managerConfig *managerconfig.ManagerConfig) error {
 	return nil
 }
 
+// initFieldCipher encrypts the sensitive fields of the payloads before they're stored, they're only decrypted when
+// the payloads are sent to the managed hubs or returned by the authenticated API.
+func initFieldCipher(databaseConfig *managerconfig.DatabaseConfig) error {
+	if databaseConfig.EncryptionKeyPath == "" {
+		return nil
+	}
+	key, err := sensitive.LoadKey(databaseConfig.EncryptionKeyPath)
+	if err != nil {
+		return err
+	}
+	rules := sensitive.Rules{}
+	if databaseConfig.EncryptionRulesPath != "" {
+		content, err := os.ReadFile(databaseConfig.EncryptionRulesPath)
+		if err != nil {
+			return fmt.Errorf("failed to read the encryption rules: %w", err)
+		}
+		if rules, err = sensitive.ParseRules(string(content)); err != nil {
+			return err
+		}
+	}
+	fieldCipher, err := sensitive.NewFieldCipher(key, rules)
+	if err != nil {
+		return err
+	}
+	sensitive.SetFieldCipher(fieldCipher)
+	setupLog.Info("payload encryption is enabled", "tables", len(rules))
+	return nil
+}
+
 func createManager(ctx context.Context, restConfig *rest.Config, managerConfig *managerconfig.ManagerConfig,
 ) (ctrl.Manager, error) {
 	leaseDuration := time.Duration(managerConfig.ElectionConfig.LeaseDuration) * time.Second
@@ -263,6 +311,10 @@ func createManager(ctx context.Context, restConfig *rest.Config, managerConfig *
 		return nil, fmt.Errorf("failed to add transport-to-db syncers: %w", err)
 	}
 
+	if err := notification.AddNotifier(mgr, managerConfig.ManagerNamespace); err != nil {
+		return nil, fmt.Errorf("failed to add notifier: %w", err)
+	}
+
 	if err := cronjob.AddSchedulerToManager(ctx, mgr, managerConfig, enableSimulation); err != nil {
 		return nil, fmt.Errorf("failed to add scheduler to manager: %w", err)
 	}
@@ -284,11 +336,20 @@ func doMain(ctx context.Context, restConfig *rest.Config) int {
 		return 1
 	}
 	utils.PrintVersion(setupLog)
+	if err := initFieldCipher(managerConfig.DatabaseConfig); err != nil {
+		setupLog.Error(err, "failed to initialize the payload encryption")
+		return 1
+	}
 	err := database.InitGormInstance(&database.DatabaseConfig{
 		URL:        managerConfig.DatabaseConfig.ProcessDatabaseURL,
 		Dialect:    database.PostgresDialect,
 		CaCertPath: managerConfig.DatabaseConfig.CACertPath,
 		PoolSize:   managerConfig.DatabaseConfig.MaxOpenConns,
+		// the expensive API and job queries use their own pools, so they can't starve the status ingestion
+		JobPoolSize:   managerConfig.DatabaseConfig.JobPoolSize,
+		APIPoolSize:   managerConfig.DatabaseConfig.APIPoolSize,
+		ReplicaURL:    managerConfig.DatabaseConfig.ReadReplicaURL,
+		MaxReplicaLag: managerConfig.DatabaseConfig.MaxReplicaLag,
 	})
 	if err != nil {
 		setupLog.Error(err, "failed to initialize GORM instance")
@@ -308,6 +369,9 @@ func doMain(ctx context.Context, restConfig *rest.Config) int {
 		hookServer.Register("/mutating", &webhook.Admission{
 			Handler: mgrwebhook.NewAdmissionHandler(mgr.GetClient(), mgr.GetScheme()),
 		})
+		hookServer.Register("/audit", &webhook.Admission{
+			Handler: mgrwebhook.NewAuditHandler(),
+		})
 	}
 
 	setupLog.Info("Starting the Manager")
@@ -320,6 +384,16 @@ func doMain(ctx context.Context, restConfig *rest.Config) int {
 }
 
 func main() {
+	// the backup and restore commands run in the jobs rendered by the operator, they only connect to the database and
+	// the backup storage
+	if backup.IsCommand(os.Args[1:]) {
+		ctrl.SetLogger(zap.New())
+		if err := backup.Run(ctrl.SetupSignalHandler(), os.Args[1:]); err != nil {
+			setupLog.Error(err, "failed to run the command", "command", os.Args[1])
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	os.Exit(doMain(ctrl.SetupSignalHandler(), ctrl.GetConfigOrDie()))
 }
 
@@ -0,0 +1,135 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/simulator"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/utils"
+)
+
+var setupLog = ctrl.Log.WithName("setup")
+
+type databaseConfig struct {
+	url        string
+	caCertPath string
+	poolSize   int
+}
+
+func parseFlags() (*simulator.SimulatorConfig, *databaseConfig) {
+	simulatorConfig := &simulator.SimulatorConfig{
+		TransportConfig: &transport.TransportConfig{
+			KafkaConfig: &transport.KafkaConfig{
+				EnableTLS:      true,
+				ProducerConfig: &transport.KafkaProducerConfig{},
+				ConsumerConfig: &transport.KafkaConsumerConfig{},
+			},
+		},
+	}
+	dbConfig := &databaseConfig{}
+
+	// add zap flags
+	opts := utils.CtrlZapOptions()
+	defaultFlags := flag.CommandLine
+	opts.BindFlags(defaultFlags)
+	pflag.CommandLine.AddGoFlagSet(defaultFlags)
+
+	pflag.IntVar(&simulatorConfig.Hubs, "hubs", 5, "The number of the simulated managed hubs.")
+	pflag.IntVar(&simulatorConfig.ClustersPerHub, "clusters-per-hub", 300,
+		"The number of the managed clusters on each hub.")
+	pflag.IntVar(&simulatorConfig.PoliciesPerHub, "policies-per-hub", 10,
+		"The number of the local policies on each hub, every policy is applied to all the clusters of the hub.")
+	pflag.Float64Var(&simulatorConfig.ClusterChurn, "cluster-churn", 0.01,
+		"The fraction of the clusters whose available status is changed in each interval.")
+	pflag.Float64Var(&simulatorConfig.ComplianceChurn, "compliance-churn", 0.05,
+		"The fraction of the compliance states changed in each interval.")
+	pflag.DurationVar(&simulatorConfig.Interval, "interval", 10*time.Second,
+		"The interval to churn the hubs and send the changed bundles.")
+	pflag.DurationVar(&simulatorConfig.HeartbeatInterval, "heartbeat-interval", time.Minute,
+		"The interval of the hub heartbeats.")
+	pflag.DurationVar(&simulatorConfig.ReportInterval, "report-interval", 30*time.Second,
+		"The interval to report the latency, the database throughput and the statistics.")
+	pflag.DurationVar(&simulatorConfig.Duration, "duration", 10*time.Minute,
+		"How long the simulation runs, 0 means until it's interrupted.")
+	pflag.BoolVar(&simulatorConfig.EmbeddedManager, "embedded-manager", true,
+		"Store the bundles by the status path of the manager in this process, disable it to load the deployed "+
+			"manager over kafka.")
+	pflag.StringVar(&dbConfig.url, "database-url", "", "The URL of database server for the process user.")
+	pflag.StringVar(&dbConfig.caCertPath, "postgres-ca-path", "", "The path of CA certificate for database server.")
+	pflag.IntVar(&dbConfig.poolSize, "database-pool-size", 10,
+		"The size of database connection pool, it's also the size of db worker pool of the embedded manager.")
+	pflag.StringVar(&simulatorConfig.TransportConfig.TransportType, "transport-type", "chan",
+		"The transport type, 'chan' or 'kafka'.")
+	pflag.StringVar(&simulatorConfig.TransportConfig.KafkaConfig.BootstrapServer, "kafka-bootstrap-server",
+		"kafka-kafka-bootstrap.kafka.svc:9092", "The bootstrap server for kafka.")
+	pflag.StringVar(&simulatorConfig.TransportConfig.KafkaConfig.CaCertPath, "kafka-ca-cert-path", "",
+		"The path of CA certificate for kafka bootstrap server.")
+	pflag.StringVar(&simulatorConfig.TransportConfig.KafkaConfig.ClientCertPath, "kafka-client-cert-path", "",
+		"The path of client certificate for kafka bootstrap server.")
+	pflag.StringVar(&simulatorConfig.TransportConfig.KafkaConfig.ClientKeyPath, "kafka-client-key-path", "",
+		"The path of client key for kafka bootstrap server.")
+	pflag.StringVar(&simulatorConfig.TransportConfig.KafkaConfig.ProducerConfig.ProducerID, "kafka-producer-id",
+		"multicluster-global-hub-simulator", "ID for the kafka producer.")
+	pflag.StringVar(&simulatorConfig.TransportConfig.KafkaConfig.ProducerConfig.ProducerTopic,
+		"kafka-producer-topic", "status", "Topic for the kafka producer, it's the status topic of the manager.")
+	pflag.IntVar(&simulatorConfig.TransportConfig.KafkaConfig.ProducerConfig.MessageSizeLimitKB,
+		"kafka-message-size-limit", 940, "The limit for kafka message size in KB.")
+	pflag.StringVar(&simulatorConfig.TransportConfig.KafkaConfig.ConsumerConfig.ConsumerID,
+		"kafka-consumer-id", "multicluster-global-hub-simulator", "ID for the kafka consumer of the embedded manager.")
+	pflag.StringVar(&simulatorConfig.TransportConfig.KafkaConfig.ConsumerConfig.ConsumerTopic,
+		"kafka-consumer-topic", "status", "Topic for the kafka consumer of the embedded manager.")
+
+	pflag.Parse()
+	// set zap logger
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	return simulatorConfig, dbConfig
+}
+
+func doMain(ctx context.Context) int {
+	simulatorConfig, dbConfig := parseFlags()
+	if dbConfig.url == "" {
+		setupLog.Info("the flag database-url is required")
+		return 1
+	}
+
+	err := database.InitGormInstance(&database.DatabaseConfig{
+		URL:        dbConfig.url,
+		Dialect:    database.PostgresDialect,
+		CaCertPath: dbConfig.caCertPath,
+		PoolSize:   dbConfig.poolSize,
+	})
+	if err != nil {
+		setupLog.Error(err, "failed to initialize GORM instance")
+		return 1
+	}
+	defer database.CloseGorm()
+
+	fleetSimulator, err := simulator.NewSimulator(simulatorConfig)
+	if err != nil {
+		setupLog.Error(err, "invalid simulator configuration")
+		return 1
+	}
+
+	setupLog.Info("starting the fleet simulator", "transport", simulatorConfig.TransportConfig.TransportType,
+		"embeddedManager", simulatorConfig.EmbeddedManager)
+	if err := fleetSimulator.Start(ctx); err != nil {
+		setupLog.Error(err, "simulator exited non-zero")
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(doMain(ctrl.SetupSignalHandler()))
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
+)
+
+// the sources of the mutations, the actor of the api is the authenticated user, and the actor of the kubernetes
+// request is the user in the admission request
+const (
+	SourceAPI        = "api"
+	SourceKubernetes = "kubernetes"
+)
+
+// the actions recorded by the audit log
+const (
+	ActionCreate      = "create"
+	ActionUpdate      = "update"
+	ActionDelete      = "delete"
+	ActionPatchLabels = "patch-labels"
+	ActionAdopt       = "adopt"
+)
+
+// SpecTables are the spec tables of the global resources keyed by their kinds, the changes of them are recorded by
+// the spec to db controllers once they're persisted, with the actors recorded by the audit webhook. The tables key
+// the sensitive rules of the audited diffs.
+var SpecTables = map[string]string{
+	"Policy":                   "spec.policies",
+	"PlacementBinding":         "spec.placementbindings",
+	"PlacementRule":            "spec.placementrules",
+	"Placement":                "spec.placements",
+	"Subscription":             "spec.subscriptions",
+	"Channel":                  "spec.channels",
+	"Application":              "spec.applications",
+	"ManagedClusterSet":        "spec.managedclustersets",
+	"ManagedClusterSetBinding": "spec.managedclustersetbindings",
+}
+
+// SpecKind returns the kind of the global resources synced into the spec table, e.g. Policy of spec.policies
+func SpecKind(table string) (string, bool) {
+	for kind, specTable := range SpecTables {
+		if specTable == table {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// Entry is the mutation to record, the Before and the After are any objects which can be marshaled into json, the
+// nil means the target doesn't exist before or after the mutation.
+type Entry struct {
+	Actor     string
+	Groups    []string
+	Source    string
+	Action    string
+	Kind      string
+	Namespace string
+	Name      string
+	ID        string
+	// Table keys the sensitive rules which encrypt the fields of the diff, e.g. spec.policies
+	Table  string
+	Before interface{}
+	After  interface{}
+}
+
+// diff is the stored diff, the before and the after only have the changed fields with their paths in the target
+type diff struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Record appends the entry to the audit log. It returns false without recording the entry if the target isn't
+// changed, e.g. the update only changes the fields which aren't audited.
+func Record(db *gorm.DB, entry *Entry) (bool, error) {
+	before, err := toDocument(entry.Before)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode the target before the %s: %w", entry.Action, err)
+	}
+	after, err := toDocument(entry.After)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode the target after the %s: %w", entry.Action, err)
+	}
+	changedBefore, changedAfter, changed := Diff(before, after)
+	if !changed {
+		return false, nil
+	}
+
+	stored := diff{}
+	if stored.Before, err = encrypt(entry.Table, changedBefore); err != nil {
+		return false, err
+	}
+	if stored.After, err = encrypt(entry.Table, changedAfter); err != nil {
+		return false, err
+	}
+	encodedDiff, err := json.Marshal(stored)
+	if err != nil {
+		return false, err
+	}
+	groups, err := json.Marshal(entry.Groups)
+	if err != nil {
+		return false, err
+	}
+
+	return true, db.Create(&models.AuditLog{
+		ID:              uuid.New().String(),
+		Actor:           entry.Actor,
+		ActorGroups:     groups,
+		Source:          entry.Source,
+		Action:          entry.Action,
+		TargetKind:      entry.Kind,
+		TargetNamespace: entry.Namespace,
+		TargetName:      entry.Name,
+		TargetID:        entry.ID,
+		Diff:            encodedDiff,
+	}).Error
+}
+
+// SetActor records the user of the admitted change of the global resource, the change is recorded with the latest
+// user once it's persisted.
+func SetActor(db *gorm.DB, kind, namespace, name, actor string, groups []string) error {
+	encodedGroups, err := json.Marshal(groups)
+	if err != nil {
+		return err
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "kind"}, {Name: "namespace"}, {Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"actor": actor, "actor_groups": encodedGroups, "updated_at": gorm.Expr("now()"),
+		}),
+	}).Create(&models.AuditActor{
+		Kind:        kind,
+		Namespace:   namespace,
+		Name:        name,
+		Actor:       actor,
+		ActorGroups: encodedGroups,
+	}).Error
+}
+
+// GetActor returns the user of the latest admitted change of the global resource, the user is empty if the change
+// isn't admitted by the audit webhook, e.g. it's made before the webhook is registered.
+func GetActor(db *gorm.DB, kind, namespace, name string) (string, []string, error) {
+	actors := []models.AuditActor{}
+	if err := db.Where("kind = ? AND namespace = ? AND name = ?", kind, namespace, name).Limit(1).
+		Find(&actors).Error; err != nil {
+		return "", nil, err
+	}
+	if len(actors) == 0 {
+		return "", nil, nil
+	}
+	groups := []string{}
+	if len(actors[0].ActorGroups) > 0 {
+		if err := json.Unmarshal(actors[0].ActorGroups, &groups); err != nil {
+			return "", nil, err
+		}
+	}
+	return actors[0].Actor, groups, nil
+}
+
+// ClearActor removes the user of the deleted global resource once its deletion is recorded.
+func ClearActor(db *gorm.DB, kind, namespace, name string) error {
+	return db.Where("kind = ? AND namespace = ? AND name = ?", kind, namespace, name).
+		Delete(&models.AuditActor{}).Error
+}
+
+// Diff returns the changed fields of the documents decoded from json, the changed fields of the objects are kept
+// with their paths, and the other values, e.g. the arrays, are compared as a whole. The fields removed by the
+// mutation are only in the before, and the added ones are only in the after.
+func Diff(before, after interface{}) (interface{}, interface{}, bool) {
+	if reflect.DeepEqual(before, after) {
+		return nil, nil, false
+	}
+	beforeObject, beforeIsObject := before.(map[string]interface{})
+	afterObject, afterIsObject := after.(map[string]interface{})
+	if !beforeIsObject || !afterIsObject {
+		return before, after, true
+	}
+
+	changedBefore, changedAfter := map[string]interface{}{}, map[string]interface{}{}
+	for key, beforeValue := range beforeObject {
+		afterValue, found := afterObject[key]
+		if !found {
+			changedBefore[key] = beforeValue
+			continue
+		}
+		if beforeChild, afterChild, changed := Diff(beforeValue, afterValue); changed {
+			changedBefore[key] = beforeChild
+			changedAfter[key] = afterChild
+		}
+	}
+	for key, afterValue := range afterObject {
+		if _, found := beforeObject[key]; !found {
+			changedAfter[key] = afterValue
+		}
+	}
+	return changedBefore, changedAfter, true
+}
+
+func toDocument(object interface{}) (interface{}, error) {
+	if object == nil || reflect.ValueOf(object).Kind() == reflect.Ptr && reflect.ValueOf(object).IsNil() {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// encrypt encrypts the sensitive fields of the changed fields by the rules of the table, the paths of the changed
+// fields are the same as the target, so the rules still locate the fields
+func encrypt(table string, doc interface{}) (json.RawMessage, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if table == "" {
+		return encoded, nil
+	}
+	if encoded, err = sensitive.Encrypt(table, encoded); err != nil {
+		return nil, fmt.Errorf("failed to encrypt the diff of %s: %w", table, err)
+	}
+	return encoded, nil
+}
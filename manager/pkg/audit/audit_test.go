@@ -0,0 +1,104 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		name    string
+		before  string
+		after   string
+		changed bool
+		want    string
+	}{
+		{
+			name:    "the unchanged document",
+			before:  `{"metadata":{"name":"p1","labels":{"env":"dev"}},"spec":{"disabled":false}}`,
+			after:   `{"metadata":{"name":"p1","labels":{"env":"dev"}},"spec":{"disabled":false}}`,
+			changed: false,
+		},
+		{
+			name:    "only the changed fields are kept with their paths",
+			before:  `{"metadata":{"name":"p1","labels":{"env":"dev","team":"a"}},"spec":{"disabled":false}}`,
+			after:   `{"metadata":{"name":"p1","labels":{"env":"prod","team":"a"}},"spec":{"disabled":false}}`,
+			changed: true,
+			want:    `{"before":{"metadata":{"labels":{"env":"dev"}}},"after":{"metadata":{"labels":{"env":"prod"}}}}`,
+		},
+		{
+			name:    "the added and the removed fields",
+			before:  `{"metadata":{"labels":{"env":"dev"}}}`,
+			after:   `{"metadata":{"labels":{"team":"a"}}}`,
+			changed: true,
+			want:    `{"before":{"metadata":{"labels":{"env":"dev"}}},"after":{"metadata":{"labels":{"team":"a"}}}}`,
+		},
+		{
+			name:    "the arrays are compared as a whole",
+			before:  `{"spec":{"remediationAction":"inform","policy-templates":[{"a":1},{"b":2}]}}`,
+			after:   `{"spec":{"remediationAction":"inform","policy-templates":[{"a":1},{"b":3}]}}`,
+			changed: true,
+			want: `{"before":{"spec":{"policy-templates":[{"a":1},{"b":2}]}},` +
+				`"after":{"spec":{"policy-templates":[{"a":1},{"b":3}]}}}`,
+		},
+		{
+			name:    "the created object",
+			before:  `null`,
+			after:   `{"metadata":{"name":"p1"}}`,
+			changed: true,
+			want:    `{"before":null,"after":{"metadata":{"name":"p1"}}}`,
+		},
+		{
+			name:    "the deleted object",
+			before:  `{"metadata":{"name":"p1"}}`,
+			after:   `null`,
+			changed: true,
+			want:    `{"before":{"metadata":{"name":"p1"}},"after":null}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var before, after interface{}
+			require.NoError(t, json.Unmarshal([]byte(c.before), &before))
+			require.NoError(t, json.Unmarshal([]byte(c.after), &after))
+
+			changedBefore, changedAfter, changed := Diff(before, after)
+			assert.Equal(t, c.changed, changed)
+			if !c.changed {
+				return
+			}
+			got, err := json.Marshal(map[string]interface{}{"before": changedBefore, "after": changedAfter})
+			require.NoError(t, err)
+			assert.JSONEq(t, c.want, string(got))
+		})
+	}
+}
+
+func TestToDocument(t *testing.T) {
+	var labels *map[string]string
+	doc, err := toDocument(labels)
+	require.NoError(t, err)
+	assert.Nil(t, doc, "the nil pointer means the target doesn't exist")
+
+	doc, err = toDocument(struct {
+		Name string `json:"name"`
+	}{Name: "hub1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "hub1"}, doc)
+}
+
+func TestSpecKind(t *testing.T) {
+	for kind, table := range SpecTables {
+		specKind, found := SpecKind(table)
+		require.True(t, found, "the kind of %s", table)
+		assert.Equal(t, kind, specKind)
+	}
+	_, found := SpecKind("spec.managed_clusters_labels")
+	assert.False(t, found, "the labels aren't changed by the kubernetes requests")
+}
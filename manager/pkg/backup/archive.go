@@ -0,0 +1,355 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
+)
+
+const (
+	manifestFileName = "manifest.json"
+	tableFileSuffix  = ".copy"
+	// archiveVersion is increased when the layout of the archive is changed incompatibly
+	archiveVersion = 1
+)
+
+// Schemas are the schemas of the global hub database which are included in the backups. the schemas and the
+// functions are created by the operator, so only the data is dumped.
+var Schemas = []string{"spec", "status", "event", "history", "local_spec", "local_status"}
+
+// Manifest describes the content of a backup archive, it's the first entry of the archive.
+type Manifest struct {
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"createdAt"`
+	ServerVersion string    `json:"serverVersion"`
+	Tables        []Table   `json:"tables"`
+	// TransportPositions are the positions committed in status.transport when the snapshot is taken, the manager
+	// resumes consuming from them once the backup is restored.
+	TransportPositions []metadata.TransportPosition `json:"transportPositions"`
+}
+
+// Table is a table dumped into the archive with the COPY text format.
+type Table struct {
+	Schema  string   `json:"schema"`
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Rows    int64    `json:"rows"`
+	// Parent and PartitionBound are set for the partitions, the partition is created before its data is restored
+	// since the partitions are created and dropped by the data retention job.
+	Parent         string `json:"parent,omitempty"`
+	PartitionBound string `json:"partitionBound,omitempty"`
+}
+
+func (t Table) identifier() string {
+	return pgx.Identifier{t.Schema, t.Name}.Sanitize()
+}
+
+func (t Table) fileName() string {
+	return t.Schema + "." + t.Name + tableFileSuffix
+}
+
+func (t Table) columnList() string {
+	columns := make([]string, 0, len(t.Columns))
+	for _, column := range t.Columns {
+		columns = append(columns, pgx.Identifier{column}.Sanitize())
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Dump writes a logical backup of the database into w as a gzipped tar archive. all the tables are read in one
+// repeatable read transaction, so the data and the transport positions are taken from the same snapshot. the tables
+// are staged in the workDir, which should be able to hold the data of the database.
+func Dump(ctx context.Context, conn *pgx.Conn, w io.Writer, workDir string) (*Manifest, error) {
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin the snapshot transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	manifest := &Manifest{Version: archiveVersion, CreatedAt: time.Now().UTC()}
+	if err := tx.QueryRow(ctx, "SHOW server_version").Scan(&manifest.ServerVersion); err != nil {
+		return nil, fmt.Errorf("failed to get the server version: %w", err)
+	}
+	if manifest.Tables, err = listTables(ctx, tx); err != nil {
+		return nil, err
+	}
+	if manifest.TransportPositions, err = transportPositions(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	// the size of the tar entries must be known before they are written, so the tables are copied into the temporary
+	// files first
+	dir, err := os.MkdirTemp(workDir, "globalhub-backup")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	for i := range manifest.Tables {
+		table := &manifest.Tables[i]
+		file, err := os.Create(filepath.Join(dir, table.fileName()))
+		if err != nil {
+			return nil, err
+		}
+		tag, err := tx.Conn().PgConn().CopyTo(ctx, file,
+			fmt.Sprintf("COPY %s (%s) TO STDOUT", table.identifier(), table.columnList()))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy the table %s: %w", table.identifier(), err)
+		}
+		table.Rows = tag.RowsAffected()
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	payload, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTarEntry(tarWriter, manifestFileName, int64(len(payload)), bytes.NewReader(payload),
+		manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, table := range manifest.Tables {
+		if err := writeTarFile(tarWriter, filepath.Join(dir, table.fileName()), manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, tx.Commit(ctx)
+}
+
+// Restore replaces the data of the database with the backup archive read from r. it runs in one transaction with the
+// triggers and the foreign keys disabled, so the database is either restored entirely or left untouched. the user
+// of the connection must be a superuser to disable the triggers.
+func Restore(ctx context.Context, conn *pgx.Conn, r io.Reader) (*Manifest, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the backup archive: %w", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	manifest, err := readManifest(tarReader)
+	if err != nil {
+		return nil, err
+	}
+	tables := map[string]Table{}
+	for _, table := range manifest.Tables {
+		tables[table.fileName()] = table
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin the restore transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, "SET LOCAL session_replication_role = replica"); err != nil {
+		return nil, fmt.Errorf("failed to disable the triggers: %w", err)
+	}
+	// the tables which don't exist in the backup are truncated too, so that the database is restored to the point of
+	// the backup rather than merged with it
+	existing, err := listTables(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		identifiers := make([]string, 0, len(existing))
+		for _, table := range existing {
+			identifiers = append(identifiers, table.identifier())
+		}
+		if _, err := tx.Exec(ctx, "TRUNCATE "+strings.Join(identifiers, ", ")); err != nil {
+			return nil, fmt.Errorf("failed to truncate the tables: %w", err)
+		}
+	}
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the backup archive: %w", err)
+		}
+		table, ok := tables[header.Name]
+		if !ok {
+			return nil, fmt.Errorf("unexpected entry %s in the backup archive", header.Name)
+		}
+		if table.Parent != "" {
+			if _, err := tx.Exec(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s %s",
+				table.identifier(), table.Parent, table.PartitionBound)); err != nil {
+				return nil, fmt.Errorf("failed to create the partition %s: %w", table.identifier(), err)
+			}
+		}
+		if _, err := tx.Conn().PgConn().CopyFrom(ctx, tarReader,
+			fmt.Sprintf("COPY %s (%s) FROM STDIN", table.identifier(), table.columnList())); err != nil {
+			return nil, fmt.Errorf("failed to restore the table %s: %w", table.identifier(), err)
+		}
+	}
+	return manifest, tx.Commit(ctx)
+}
+
+// ReadManifest reads the manifest of the backup archive without restoring it.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the backup archive: %w", err)
+	}
+	return readManifest(tar.NewReader(gzipReader))
+}
+
+func readManifest(tarReader *tar.Reader) (*Manifest, error) {
+	header, err := tarReader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the backup archive: %w", err)
+	}
+	if header.Name != manifestFileName {
+		return nil, fmt.Errorf("the first entry of the backup archive is %s, expected %s", header.Name,
+			manifestFileName)
+	}
+	manifest := &Manifest{}
+	if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode the manifest: %w", err)
+	}
+	if manifest.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported backup archive version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+// listTables lists the tables with data in the global hub schemas, the partitioned tables are skipped since their
+// rows are dumped from the partitions.
+func listTables(ctx context.Context, tx pgx.Tx) ([]Table, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT n.nspname, c.relname,
+			ARRAY(SELECT a.attname FROM pg_attribute a
+				WHERE a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped AND a.attgenerated = ''
+				ORDER BY a.attnum)::text[],
+			COALESCE(quote_ident(pn.nspname) || '.' || quote_ident(p.relname), ''),
+			COALESCE(pg_get_expr(c.relpartbound, c.oid), '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_inherits i ON c.relispartition AND i.inhrelid = c.oid
+		LEFT JOIN pg_class p ON p.oid = i.inhparent
+		LEFT JOIN pg_namespace pn ON pn.oid = p.relnamespace
+		WHERE c.relkind = 'r' AND n.nspname = ANY($1)
+		ORDER BY n.nspname, c.relname`, Schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []Table{}
+	for rows.Next() {
+		table := Table{}
+		if err := rows.Scan(&table.Schema, &table.Name, &table.Columns, &table.Parent,
+			&table.PartitionBound); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+func transportPositions(ctx context.Context, tx pgx.Tx) ([]metadata.TransportPosition, error) {
+	rows, err := queryTransportPositions(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]metadata.TransportPosition, 0, len(rows))
+	for _, row := range rows {
+		positions = append(positions, row.position)
+	}
+	return positions, nil
+}
+
+// transportPositionRow is the position with the name of the row in status.transport
+type transportPositionRow struct {
+	name     string
+	position metadata.TransportPosition
+}
+
+func queryTransportPositions(ctx context.Context, tx pgx.Tx) ([]transportPositionRow, error) {
+	rows, err := tx.Query(ctx, "SELECT name, payload FROM status.transport ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the transport positions: %w", err)
+	}
+	defer rows.Close()
+
+	positions := []transportPositionRow{}
+	for rows.Next() {
+		var name string
+		var payload []byte
+		if err := rows.Scan(&name, &payload); err != nil {
+			return nil, err
+		}
+		position := metadata.TransportPosition{}
+		if err := json.Unmarshal(payload, &position); err != nil {
+			return nil, fmt.Errorf("failed to decode the transport position of %s: %w", name, err)
+		}
+		position.Topic = positionTopic(name)
+		positions = append(positions, transportPositionRow{name: name, position: position})
+	}
+	return positions, rows.Err()
+}
+
+// positionTopic returns the topic of the position, the positions are named with topic@partition, and the previous
+// versions named the position with the topic.
+func positionTopic(name string) string {
+	topic, _, _ := strings.Cut(name, conflator.KafkaPartitionDelimiter)
+	return topic
+}
+
+func writeTarFile(tarWriter *tar.Writer, path string, modTime time.Time) error {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return writeTarEntry(tarWriter, filepath.Base(path), info.Size(), file, modTime)
+}
+
+func writeTarEntry(tarWriter *tar.Writer, name string, size int64, r io.Reader, modTime time.Time) error {
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    size,
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(tarWriter, r)
+	return err
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/resync"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+const (
+	// BackupCommand takes a backup of the database, it runs in the backup cronjob rendered by the operator
+	BackupCommand = "backup"
+	// RestoreCommand restores the database from a backup, it runs in the restore job rendered by the operator
+	RestoreCommand = "restore"
+)
+
+var log = ctrl.Log.WithName("database-backup")
+
+type options struct {
+	databaseURL    string
+	postgresCAPath string
+	backupDir      string
+	s3             S3Config
+	// backup options
+	retention int
+	workDir   string
+	// restore options
+	backup      string
+	pointInTime string
+	waitTimeout time.Duration
+	kafkaConfig transport.KafkaConfig
+}
+
+// IsCommand returns true if the arguments of the manager are a backup or restore command.
+func IsCommand(args []string) bool {
+	return len(args) > 0 && (args[0] == BackupCommand || args[0] == RestoreCommand)
+}
+
+// Run runs the backup or restore command, the first argument is the command.
+func Run(ctx context.Context, args []string) error {
+	command := args[0]
+	opts := &options{}
+	flags := pflag.NewFlagSet(command, pflag.ContinueOnError)
+	flags.StringVar(&opts.databaseURL, "database-url", "", "the URL of the database, the user must be a superuser")
+	flags.StringVar(&opts.postgresCAPath, "postgres-ca-path", "/postgres-credential/ca.crt",
+		"the path of the CA certificate of the database")
+	flags.StringVar(&opts.backupDir, "backup-dir", "", "the directory to keep the backups, e.g. the mount path of "+
+		"the backup volume")
+	flags.StringVar(&opts.s3.Bucket, "s3-bucket", "", "the S3 bucket to keep the backups")
+	flags.StringVar(&opts.s3.Prefix, "s3-prefix", "", "the prefix of the backups in the S3 bucket")
+	flags.StringVar(&opts.s3.Endpoint, "s3-endpoint", "", "the endpoint of the S3 compatible storage")
+	flags.StringVar(&opts.s3.Region, "s3-region", "", "the region of the S3 bucket")
+	flags.BoolVar(&opts.s3.ForcePathStyle, "s3-force-path-style", false,
+		"use the path style addressing of the S3 bucket, it's required by most S3 compatible storages")
+	switch command {
+	case BackupCommand:
+		flags.IntVar(&opts.retention, "retention", 7, "the number of the backups to keep")
+		flags.StringVar(&opts.workDir, "work-dir", os.TempDir(), "the directory to stage the tables before they're "+
+			"archived, it should be able to hold the data of the database")
+	case RestoreCommand:
+		flags.StringVar(&opts.backup, "backup", "", "the name of the backup to restore")
+		flags.StringVar(&opts.pointInTime, "point-in-time", "", "restore the latest backup taken at or before "+
+			"the time(RFC3339), it's ignored if the backup is specified")
+		flags.DurationVar(&opts.waitTimeout, "wait-timeout", 5*time.Minute,
+			"the timeout to wait for the other sessions of the database user, e.g. the manager, to be closed")
+		flags.StringVar(&opts.kafkaConfig.BootstrapServer, "kafka-bootstrap-server", "",
+			"the kafka bootstrap server to reconcile the transport positions")
+		flags.StringVar(&opts.kafkaConfig.CaCertPath, "kafka-ca-cert-path", "", "the path of the kafka CA certificate")
+		flags.StringVar(&opts.kafkaConfig.ClientCertPath, "kafka-client-cert-path", "",
+			"the path of the kafka client certificate")
+		flags.StringVar(&opts.kafkaConfig.ClientKeyPath, "kafka-client-key-path", "", "the path of the kafka client key")
+		flags.StringVar(&opts.kafkaConfig.ConsumerConfig.ConsumerID, "kafka-consumer-id",
+			"multicluster-global-hub-manager", "the consumer id of the manager")
+	default:
+		return fmt.Errorf("unknown command %s", command)
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if opts.databaseURL == "" {
+		return fmt.Errorf("the database url isn't specified")
+	}
+
+	storage, err := newStorage(opts)
+	if err != nil {
+		return err
+	}
+	caCert, err := os.ReadFile(filepath.Clean(opts.postgresCAPath))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read the database CA certificate: %w", err)
+	}
+	conn, err := database.PostgresConnection(ctx, opts.databaseURL, caCert)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(context.Background()); err != nil {
+			log.Error(err, "failed to close the database connection")
+		}
+	}()
+
+	if command == BackupCommand {
+		return runBackup(ctx, conn, storage, opts.retention, opts.workDir)
+	}
+	return runRestore(ctx, conn, storage, opts)
+}
+
+func newStorage(opts *options) (Storage, error) {
+	if opts.s3.Bucket != "" {
+		return NewS3Storage(&opts.s3)
+	}
+	if opts.backupDir != "" {
+		return NewFileStorage(opts.backupDir)
+	}
+	return nil, fmt.Errorf("neither the backup directory nor the S3 bucket is specified")
+}
+
+func runBackup(ctx context.Context, conn *pgx.Conn, storage Storage, retention int, workDir string) error {
+	name := BackupName(time.Now())
+	reader, writer := io.Pipe()
+	manifestChan := make(chan *Manifest, 1)
+	go func() {
+		manifest, err := Dump(ctx, conn, writer, workDir)
+		manifestChan <- manifest
+		_ = writer.CloseWithError(err)
+	}()
+	// the storage reads the archive until the dump is done, the error of the dump is returned by the reader
+	if err := storage.Put(ctx, name, reader); err != nil {
+		_ = reader.CloseWithError(err)
+		<-manifestChan
+		return fmt.Errorf("failed to take the backup %s: %w", name, err)
+	}
+	manifest := <-manifestChan
+
+	rows := int64(0)
+	for _, table := range manifest.Tables {
+		rows += table.Rows
+	}
+	log.Info("the backup is taken", "name", name, "tables", len(manifest.Tables), "rows", rows,
+		"transportPositions", manifest.TransportPositions)
+
+	deleted, err := Prune(ctx, storage, retention)
+	if err != nil {
+		return err
+	}
+	if len(deleted) > 0 {
+		log.Info("deleted the expired backups", "backups", deleted)
+	}
+	return nil
+}
+
+func runRestore(ctx context.Context, conn *pgx.Conn, storage Storage, opts *options) error {
+	var pointInTime *time.Time
+	if opts.backup == "" && opts.pointInTime != "" {
+		t, err := time.Parse(time.RFC3339, opts.pointInTime)
+		if err != nil {
+			return fmt.Errorf("failed to parse the point in time: %w", err)
+		}
+		pointInTime = &t
+	}
+	backups, err := ListBackups(ctx, storage)
+	if err != nil {
+		return err
+	}
+	name, err := SelectBackup(backups, opts.backup, pointInTime)
+	if err != nil {
+		return err
+	}
+
+	// the manager is stopped by the operator during the restore, wait for its sessions to be closed so that the
+	// restored data isn't overwritten
+	if err := waitForOtherSessions(ctx, conn, opts.waitTimeout); err != nil {
+		return fmt.Errorf("failed to wait for the other sessions of the database to be closed: %w", err)
+	}
+
+	reader, err := storage.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to get the backup %s: %w", name, err)
+	}
+	defer reader.Close()
+	log.Info("restoring the database", "backup", name)
+	manifest, err := Restore(ctx, conn, reader)
+	if err != nil {
+		return fmt.Errorf("failed to restore the backup %s: %w", name, err)
+	}
+	log.Info("the database is restored", "backup", name, "createdAt", manifest.CreatedAt,
+		"transportPositions", manifest.TransportPositions)
+
+	if err := requestResync(ctx, conn); err != nil {
+		return err
+	}
+
+	if opts.kafkaConfig.BootstrapServer == "" {
+		log.Info("skip reconciling the transport positions since the kafka bootstrap server isn't specified")
+		return nil
+	}
+	opts.kafkaConfig.EnableTLS = true
+	querier, err := NewKafkaWatermarkQuerier(&opts.kafkaConfig)
+	if err != nil {
+		return err
+	}
+	reconciled, err := ReconcilePositions(ctx, conn, querier)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile the transport positions: %w", err)
+	}
+	log.Info("the transport positions are reconciled", "positions", reconciled)
+	return nil
+}
+
+// waitForOtherSessions waits until no other session of the current user is connected to the database.
+func waitForOtherSessions(ctx context.Context, conn *pgx.Conn, timeout time.Duration) error {
+	return wait.PollUntilContextTimeout(ctx, 5*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		sessions := 0
+		err := conn.QueryRow(ctx, `SELECT count(*) FROM pg_stat_activity WHERE datname = current_database()
+			AND usename = current_user AND pid <> pg_backend_pid() AND backend_type = 'client backend'`).Scan(&sessions)
+		if err != nil {
+			return false, fmt.Errorf("failed to query the sessions of the database: %w", err)
+		}
+		if sessions > 0 {
+			log.Info("waiting for the other sessions of the database to be closed", "sessions", sessions)
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+// requestResync requests the managed hubs to resend the bundles changed after the backup is taken, the resync is
+// started once the manager is running again. the unfinished resyncs kept in the backup are abandoned.
+func requestResync(ctx context.Context, conn *pgx.Conn) error {
+	_, err := conn.Exec(ctx, `UPDATE status.resyncs SET phase = $1, message = $2, completed_at = now()
+		WHERE phase IN ($3, $4)`, resync.PhaseFailed, "the database is restored", resync.PhasePending,
+		resync.PhaseRunning)
+	if err != nil {
+		return fmt.Errorf("failed to abandon the unfinished resyncs: %w", err)
+	}
+	bundleKeys, err := json.Marshal(resync.DefaultBundleKeys)
+	if err != nil {
+		return err
+	}
+	id := uuid.New().String()
+	_, err = conn.Exec(ctx, `INSERT INTO status.resyncs (id, leaf_hubs, bundle_keys, trigger, timeout_seconds, phase)
+		VALUES ($1, '[]', $2, $3, $4, $5)`, id, string(bundleKeys), resync.TriggerDatabaseRestore,
+		int(resync.DefaultTimeout.Seconds()), resync.PhasePending)
+	if err != nil {
+		return fmt.Errorf("failed to request the resync of the managed hubs: %w", err)
+	}
+	log.Info("requested the managed hubs to resync the bundles", "resync", id)
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/config"
+)
+
+const watermarkTimeoutMs = 5000
+
+// WatermarkQuerier queries the low and high watermarks of the partition.
+type WatermarkQuerier interface {
+	Watermarks(topic string, partition int32) (low int64, high int64, err error)
+}
+
+type kafkaWatermarkQuerier struct {
+	consumer *kafka.Consumer
+}
+
+// NewKafkaWatermarkQuerier creates a kafka client to query the watermarks, it doesn't subscribe to any topic, so it
+// won't join the consumer group of the manager.
+func NewKafkaWatermarkQuerier(kafkaConfig *transport.KafkaConfig) (WatermarkQuerier, error) {
+	configMap, err := config.GetConfluentConfigMap(kafkaConfig, false)
+	if err != nil {
+		return nil, err
+	}
+	_ = configMap.SetKey("client.id", fmt.Sprintf("%s-restore", kafkaConfig.ConsumerConfig.ConsumerID))
+	_ = configMap.SetKey("enable.auto.commit", "false")
+
+	consumer, err := kafka.NewConsumer(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the kafka client to query watermarks: %w", err)
+	}
+	return &kafkaWatermarkQuerier{consumer: consumer}, nil
+}
+
+func (q *kafkaWatermarkQuerier) Watermarks(topic string, partition int32) (int64, int64, error) {
+	low, high, err := q.consumer.QueryWatermarkOffsets(topic, partition, watermarkTimeoutMs)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query the watermarks of %s@%d: %w", topic, partition, err)
+	}
+	return low, high, nil
+}
+
+// ReconcilePositions moves the restored transport positions into the range still kept by kafka, so that the manager
+// replays the messages received after the backup was taken. the positions whose messages were already deleted by
+// the kafka retention, or which are beyond the end of a recreated topic, are moved to the earliest offset. the
+// missing status is recovered by the resync requested to the managed hubs after the restore.
+func ReconcilePositions(ctx context.Context, conn *pgx.Conn, querier WatermarkQuerier,
+) ([]metadata.TransportPosition, error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rows, err := queryTransportPositions(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	reconciled := []metadata.TransportPosition{}
+	for _, row := range rows {
+		position := row.position
+		low, high, err := querier.Watermarks(position.Topic, position.Partition)
+		if err != nil {
+			return nil, err
+		}
+		offset := reconcileOffset(position.Offset, low, high)
+		if offset == position.Offset {
+			continue
+		}
+		position.Offset = offset
+		payload, err := json.Marshal(position)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, "UPDATE status.transport SET payload = $1, updated_at = now() WHERE name = $2",
+			payload, row.name); err != nil {
+			return nil, fmt.Errorf("failed to update the transport position of %s: %w", row.name, err)
+		}
+		reconciled = append(reconciled, position)
+	}
+	return reconciled, tx.Commit(ctx)
+}
+
+func reconcileOffset(offset, low, high int64) int64 {
+	if offset < low || offset > high {
+		return low
+	}
+	return offset
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileOffset(t *testing.T) {
+	cases := []struct {
+		name      string
+		offset    int64
+		low, high int64
+		expected  int64
+	}{
+		{name: "kept by kafka", offset: 120, low: 100, high: 200, expected: 120},
+		{name: "at the end of the partition", offset: 200, low: 100, high: 200, expected: 200},
+		{name: "deleted by the retention", offset: 50, low: 100, high: 200, expected: 100},
+		{name: "topic is recreated", offset: 500, low: 0, high: 20, expected: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, reconcileOffset(tc.offset, tc.low, tc.high))
+		})
+	}
+}
+
+func TestPositionTopic(t *testing.T) {
+	assert.Equal(t, "status.hub1", positionTopic("status.hub1@2"))
+	assert.Equal(t, "status", positionTopic("status"))
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+const (
+	backupNamePrefix = "globalhub-"
+	backupNameSuffix = ".tar.gz"
+	backupTimeFormat = "20060102T150405Z"
+	defaultS3Region  = "us-east-1"
+)
+
+// Storage keeps the backup archives, the backups are identified by their names.
+type Storage interface {
+	Put(ctx context.Context, name string, r io.Reader) error
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	List(ctx context.Context) ([]string, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// BackupName returns the name of the backup taken at the time, the names are sorted by the time.
+func BackupName(t time.Time) string {
+	return backupNamePrefix + t.UTC().Format(backupTimeFormat) + backupNameSuffix
+}
+
+// BackupTime parses the time when the backup was taken from its name.
+func BackupTime(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, backupNamePrefix) || !strings.HasSuffix(name, backupNameSuffix) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(backupTimeFormat,
+		strings.TrimSuffix(strings.TrimPrefix(name, backupNamePrefix), backupNameSuffix))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// ListBackups lists the backups in the storage from the oldest to the latest, the other objects are ignored.
+func ListBackups(ctx context.Context, storage Storage) ([]string, error) {
+	names, err := storage.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the backups: %w", err)
+	}
+	backups := []string{}
+	for _, name := range names {
+		if _, ok := BackupTime(name); ok {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// SelectBackup selects the backup to restore. the named backup is selected if the name is specified, otherwise the
+// latest backup taken at or before the point in time, or the latest backup if the point in time is nil.
+func SelectBackup(backups []string, name string, pointInTime *time.Time) (string, error) {
+	if name != "" {
+		for _, backup := range backups {
+			if backup == name {
+				return backup, nil
+			}
+		}
+		return "", fmt.Errorf("the backup %s isn't found", name)
+	}
+	for i := len(backups) - 1; i >= 0; i-- {
+		backupTime, _ := BackupTime(backups[i])
+		if pointInTime == nil || !backupTime.After(*pointInTime) {
+			return backups[i], nil
+		}
+	}
+	if pointInTime != nil {
+		return "", fmt.Errorf("no backup is taken at or before %s", pointInTime.UTC().Format(time.RFC3339))
+	}
+	return "", fmt.Errorf("no backup is found")
+}
+
+// Prune deletes the oldest backups so that at most retention backups are kept, it returns the deleted backups.
+func Prune(ctx context.Context, storage Storage, retention int) ([]string, error) {
+	backups, err := ListBackups(ctx, storage)
+	if err != nil {
+		return nil, err
+	}
+	if retention < 1 || len(backups) <= retention {
+		return nil, nil
+	}
+	expired := backups[:len(backups)-retention]
+	for _, name := range expired {
+		if err := storage.Delete(ctx, name); err != nil {
+			return nil, fmt.Errorf("failed to delete the expired backup %s: %w", name, err)
+		}
+	}
+	return expired, nil
+}
+
+type fileStorage struct {
+	dir string
+}
+
+// NewFileStorage keeps the backups in the directory, it's the mount path of the backup volume.
+func NewFileStorage(dir string) (Storage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the backup directory %s: %w", dir, err)
+	}
+	return &fileStorage{dir: dir}, nil
+}
+
+func (s *fileStorage) Put(ctx context.Context, name string, r io.Reader) error {
+	// the backup is written into a hidden file first, so an interrupted backup is never listed
+	tmp, err := os.CreateTemp(s.dir, "."+name+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
+
+func (s *fileStorage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.dir, filepath.Base(name)))
+}
+
+func (s *fileStorage) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (s *fileStorage) Delete(ctx context.Context, name string) error {
+	return os.Remove(filepath.Join(s.dir, filepath.Base(name)))
+}
+
+// S3Config is the S3 compatible bucket to keep the backups. the credential is read from the AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY environment variables.
+type S3Config struct {
+	Bucket         string
+	Prefix         string
+	Endpoint       string
+	Region         string
+	ForcePathStyle bool
+}
+
+type s3Storage struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+// NewS3Storage keeps the backups in the S3 compatible bucket.
+func NewS3Storage(config *S3Config) (Storage, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("the bucket of the backup storage isn't specified")
+	}
+	awsConfig := aws.NewConfig().WithS3ForcePathStyle(config.ForcePathStyle)
+	if config.Region != "" {
+		awsConfig = awsConfig.WithRegion(config.Region)
+	} else {
+		awsConfig = awsConfig.WithRegion(defaultS3Region)
+	}
+	if config.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(config.Endpoint)
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the S3 session: %w", err)
+	}
+	prefix := strings.Trim(config.Prefix, "/")
+	return &s3Storage{
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+		bucket:   config.Bucket,
+		prefix:   prefix,
+	}, nil
+}
+
+func (s *s3Storage) key(name string) string {
+	return path.Join(s.prefix, name)
+}
+
+func (s *s3Storage) Put(ctx context.Context, name string, r io.Reader) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+		Body:   r,
+	})
+	return err
+}
+
+func (s *s3Storage) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	output, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func (s *s3Storage) List(ctx context.Context) ([]string, error) {
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(s.bucket)}
+	if s.prefix != "" {
+		input.Prefix = aws.String(s.prefix + "/")
+	}
+	names := []string{}
+	err := s.client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(aws.StringValue(object.Key), aws.StringValue(input.Prefix))
+			// skip the objects in the sub directories
+			if !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+		return true
+	})
+	return names, err
+}
+
+func (s *s3Storage) Delete(ctx context.Context, name string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	return err
+}
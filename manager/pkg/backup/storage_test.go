@@ -0,0 +1,127 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package backup
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupName(t *testing.T) {
+	taken := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	name := BackupName(taken)
+	assert.Equal(t, "globalhub-20240102T030405Z.tar.gz", name)
+
+	parsed, ok := BackupTime(name)
+	assert.True(t, ok)
+	assert.True(t, parsed.Equal(taken))
+
+	_, ok = BackupTime("globalhub-latest.tar.gz")
+	assert.False(t, ok)
+	_, ok = BackupTime(".globalhub-20240102T030405Z.tar.gz-123")
+	assert.False(t, ok)
+}
+
+func TestFileStorage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage, err := NewFileStorage(filepath.Join(dir, "backups"))
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, storage.Put(ctx, BackupName(start.AddDate(0, 0, i)), strings.NewReader("backup")))
+	}
+	// the other files in the volume aren't regarded as backups
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "backups", "lost+found"), []byte{}, 0o600))
+
+	backups, err := ListBackups(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"globalhub-20240101T020000Z.tar.gz",
+		"globalhub-20240102T020000Z.tar.gz",
+		"globalhub-20240103T020000Z.tar.gz",
+		"globalhub-20240104T020000Z.tar.gz",
+		"globalhub-20240105T020000Z.tar.gz",
+	}, backups)
+
+	reader, err := storage.Get(ctx, backups[0])
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, "backup", string(content))
+
+	deleted, err := Prune(ctx, storage, 3)
+	require.NoError(t, err)
+	assert.Equal(t, backups[:2], deleted)
+	backups, err = ListBackups(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"globalhub-20240103T020000Z.tar.gz",
+		"globalhub-20240104T020000Z.tar.gz",
+		"globalhub-20240105T020000Z.tar.gz",
+	}, backups)
+
+	deleted, err = Prune(ctx, storage, 3)
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+}
+
+func TestSelectBackup(t *testing.T) {
+	backups := []string{
+		"globalhub-20240103T020000Z.tar.gz",
+		"globalhub-20240104T020000Z.tar.gz",
+		"globalhub-20240105T020000Z.tar.gz",
+	}
+	pointInTime := func(value string) *time.Time {
+		t, _ := time.Parse(time.RFC3339, value)
+		return &t
+	}
+
+	cases := []struct {
+		name        string
+		backup      string
+		pointInTime *time.Time
+		expected    string
+		expectErr   bool
+	}{
+		{name: "latest", expected: "globalhub-20240105T020000Z.tar.gz"},
+		{name: "named", backup: "globalhub-20240103T020000Z.tar.gz", expected: "globalhub-20240103T020000Z.tar.gz"},
+		{name: "named not found", backup: "globalhub-20240101T020000Z.tar.gz", expectErr: true},
+		{
+			name:        "point in time",
+			pointInTime: pointInTime("2024-01-04T12:00:00Z"),
+			expected:    "globalhub-20240104T020000Z.tar.gz",
+		},
+		{
+			name:        "point in time equals to the backup",
+			pointInTime: pointInTime("2024-01-04T02:00:00Z"),
+			expected:    "globalhub-20240104T020000Z.tar.gz",
+		},
+		{name: "point in time before all the backups", pointInTime: pointInTime("2024-01-01T00:00:00Z"), expectErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, err := SelectBackup(backups, tc.backup, tc.pointInTime)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, selected)
+		})
+	}
+
+	_, err := SelectBackup(nil, "", nil)
+	assert.Error(t, err)
+}
@@ -24,11 +24,17 @@ type ManagerConfig struct {
 	NonK8sAPIServerConfig *nonk8sapi.NonK8sAPIServerConfig
 	ElectionConfig        *commonobjects.LeaderElectionConfig
 	EnableGlobalResource  bool
-	LaunchJobNames        string
+	// EnableStatusSharding processes the status bundles on every replica, the managed hubs are split between the
+	// replicas by the kafka partitions assigned to them
+	EnableStatusSharding bool
+	LaunchJobNames       string
 }
 
 type SyncerConfig struct {
-	SpecSyncInterval              time.Duration
+	SpecSyncInterval time.Duration
+	// SpecSnapshotInterval is the interval to broadcast the snapshots of the spec bundles, the delta bundles of the
+	// changed objects are sent in between. zero disables the periodic snapshots.
+	SpecSnapshotInterval          time.Duration
 	StatusSyncInterval            time.Duration
 	DeletedLabelsTrimmingInterval time.Duration
 }
@@ -39,4 +45,14 @@ type DatabaseConfig struct {
 	CACertPath                 string
 	MaxOpenConns               int
 	DataRetention              int
+	// JobPoolSize and APIPoolSize are the separate connection pools of the background jobs and the API reads
+	JobPoolSize int
+	APIPoolSize int
+	// ReadReplicaURL serves the API reads, they fall back to the primary once the replica lags behind MaxReplicaLag
+	ReadReplicaURL string
+	MaxReplicaLag  time.Duration
+	// EncryptionKeyPath is the AES key to encrypt the fields of the stored payloads, the fields are located by the
+	// rules in EncryptionRulesPath which map the table to the json paths. empty disables the encryption.
+	EncryptionKeyPath   string
+	EncryptionRulesPath string
 }
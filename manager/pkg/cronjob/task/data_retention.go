@@ -10,6 +10,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/monitoring"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/notification"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/statussyncer/hubmanagement"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
@@ -39,6 +40,13 @@ var (
 		"event.local_policies",
 		"event.local_root_policies",
 		"history.local_compliance",
+		"history.audit_log",
+	}
+	// the following history tables aren't partitioned, the records are deleted once the time column is expired. the
+	// tables are optional, e.g. only created if the global resource is enabled, so they're skipped if not existing.
+	historyTables = map[string]string{
+		"history.global_resource_drifts":    "resolved_at",
+		"history.gitops_application_health": "transitioned_at",
 	}
 	retentionLog = ctrl.Log.WithName(RetentionTaskName)
 )
@@ -51,6 +59,9 @@ func DataRetention(ctx context.Context, retentionMonth int, job gocron.Job) {
 	defer func() {
 		if err != nil {
 			monitoring.GlobalHubCronJobGaugeVec.WithLabelValues(RetentionTaskName).Set(1)
+			notification.Emit(notification.NewEvent(notification.CronJobFailed,
+				fmt.Sprintf("the job %s failed: %v", RetentionTaskName, err),
+				map[string]string{notification.DataJobName: RetentionTaskName}))
 		} else {
 			monitoring.GlobalHubCronJobGaugeVec.WithLabelValues(RetentionTaskName).Set(0)
 		}
@@ -81,8 +92,15 @@ func DataRetention(ctx context.Context, retentionMonth int, job gocron.Job) {
 			return
 		}
 	}
+	for tableName, timeColumn := range historyTables {
+		err = deleteExpiredHistory(tableName, timeColumn, minTime)
+		if err != nil {
+			retentionLog.Error(err, "failed to delete the expired history records")
+			return
+		}
+	}
 	// delete the inactive heartbeat records
-	db := database.GetGorm()
+	db := database.GetJobGorm()
 	err = db.Where("last_timestamp < ? AND status = ?", minTime, hubmanagement.HubInactive).
 		Delete(&models.LeafHubHeartbeat{}).Error
 	if err != nil {
@@ -93,7 +111,7 @@ func DataRetention(ctx context.Context, retentionMonth int, job gocron.Job) {
 }
 
 func updatePartitionTables(tableName string, createTime, deleteTime time.Time) error {
-	db := database.GetGorm()
+	db := database.GetJobGorm()
 
 	// create the partition tables for the next month
 	startTime := time.Date(createTime.Year(), createTime.Month(), 1, 0, 0, 0, 0, createTime.Location())
@@ -121,7 +139,7 @@ func updatePartitionTables(tableName string, createTime, deleteTime time.Time) e
 func deleteExpiredRecords(tableName string, minDate time.Time) error {
 
 	sql := fmt.Sprintf("DELETE FROM %s WHERE deleted_at < '%s'", tableName, minDate.Format(dateFormat))
-	db := database.GetGorm()
+	db := database.GetJobGorm()
 	if result := db.Exec(sql); result.Error != nil {
 		return fmt.Errorf("failed to delete records before %s from %s: %w",
 			minDate.Format(dateFormat), tableName, result.Error)
@@ -130,8 +148,26 @@ func deleteExpiredRecords(tableName string, minDate time.Time) error {
 	return nil
 }
 
+func deleteExpiredHistory(tableName, timeColumn string, minDate time.Time) error {
+	db := database.GetJobGorm()
+	var exists bool
+	if err := db.Raw("SELECT to_regclass(?) IS NOT NULL", tableName).Scan(&exists).Error; err != nil {
+		return fmt.Errorf("failed to check the table %s: %w", tableName, err)
+	}
+	if !exists {
+		return nil
+	}
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s < ?", tableName, timeColumn)
+	if result := db.Exec(sql, minDate); result.Error != nil {
+		return fmt.Errorf("failed to delete records before %s from %s: %w",
+			minDate.Format(dateFormat), tableName, result.Error)
+	}
+	retentionLog.Info("delete records", "table", tableName, "before", minDate.Format(dateFormat))
+	return nil
+}
+
 func traceDataRetentionLog(tableName string, startTime time.Time, err error, partition bool) error {
-	db := database.GetGorm()
+	db := database.GetJobGorm()
 	dataRetentionLog := &models.DataRetentionJobLog{
 		Name:    tableName,
 		StartAt: startTime,
@@ -158,7 +194,7 @@ func traceDataRetentionLog(tableName string, startTime time.Time, err error, par
 }
 
 func getMinMaxPartitions(tableName string) (string, string, error) {
-	db := database.GetGorm()
+	db := database.GetJobGorm()
 	schemaTable := strings.Split(tableName, ".")
 	if len(schemaTable) != 2 {
 		return "", "", fmt.Errorf("invalid table name: %s", tableName)
@@ -193,7 +229,7 @@ func getMinMaxPartitions(tableName string) (string, string, error) {
 }
 
 func getMinDeletionTime(tableName string) (time.Time, error) {
-	db := database.GetGorm()
+	db := database.GetJobGorm()
 	minDeletion := &models.Time{}
 	result := db.Raw(fmt.Sprintf("SELECT MIN(deleted_at) as time FROM %s", tableName)).Find(minDeletion)
 	if result.Error != nil {
@@ -12,6 +12,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/monitoring"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/notification"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
 )
@@ -54,6 +55,9 @@ func SyncLocalCompliance(ctx context.Context, enableSimulation bool, job gocron.
 	defer func() {
 		if err != nil {
 			monitoring.GlobalHubCronJobGaugeVec.WithLabelValues(LocalComplianceTaskName).Set(1)
+			notification.Emit(notification.NewEvent(notification.CronJobFailed,
+				fmt.Sprintf("the job %s failed: %v", LocalComplianceTaskName, err),
+				map[string]string{notification.DataJobName: LocalComplianceTaskName}))
 		} else {
 			monitoring.GlobalHubCronJobGaugeVec.WithLabelValues(LocalComplianceTaskName).Set(0)
 		}
@@ -104,7 +108,7 @@ func syncToLocalComplianceHistoryByLocalStatus(ctx context.Context, batchSize in
 		CREATE INDEX IF NOT EXISTS idx_local_compliance_view ON %s (policy_id, cluster_id);
 	`
 
-	db := database.GetGorm()
+	db := database.GetJobGorm()
 	err = db.Exec(fmt.Sprintf(createViewTemplate, viewName, viewName)).Error
 	if err != nil {
 		return totalCount, insertedCount, err
@@ -179,7 +183,7 @@ func insertToLocalComplianceHistoryByLocalStatus(ctx context.Context, tableName
 				$$;
 			`
 			}
-			db := database.GetGorm()
+			db := database.GetJobGorm()
 			selectInsertSQL := fmt.Sprintf(selectInsertSQLTemplate, interval, tableName, batchSize, offset)
 			result := db.Exec(selectInsertSQL)
 			if result.Error != nil {
@@ -204,7 +208,7 @@ func syncToLocalComplianceHistoryByPolicyEvent(ctx context.Context, batchSize in
 	`
 	totalCountStatement := fmt.Sprintf(totalCountSQLTemplate, dateInterval, dateInterval-1)
 
-	db := database.GetGorm()
+	db := database.GetJobGorm()
 	if err := db.Raw(totalCountStatement).Scan(&totalCount).Error; err != nil {
 		return totalCount, insertedCount, err
 	}
@@ -268,7 +272,7 @@ func insertToLocalComplianceHistoryByPolicyEvent(ctx context.Context, totalCount
 			selectInsertStatement := fmt.Sprintf(selectInsertSQLTemplate, dateInterval, dateInterval-1,
 				dateInterval, dateInterval, dateInterval-1)
 
-			db := database.GetGorm()
+			db := database.GetJobGorm()
 			result := db.Exec(selectInsertStatement, batchSize, offset)
 			insertError = result.Error
 			if insertError != nil {
@@ -285,7 +289,7 @@ func insertToLocalComplianceHistoryByPolicyEvent(ctx context.Context, totalCount
 func traceComplianceHistoryLog(ctx context.Context, name string, total, offset, inserted int64,
 	start time.Time, err error,
 ) error {
-	db := database.GetGorm()
+	db := database.GetJobGorm()
 	localComplianceJobLog := &models.LocalComplianceJobLog{
 		Name:     name,
 		StartAt:  start,
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+// Package ghctl implements the ghctl command, which lists the resources of the global hub and patches the labels
+// of the managed clusters with the global hub API.
+package ghctl
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/client"
+)
+
+const (
+	serverEnv = "GLOBAL_HUB_API_SERVER"
+	tokenEnv  = "GLOBAL_HUB_TOKEN"
+)
+
+// options are the global flags to connect to the global hub API.
+type options struct {
+	server   string
+	token    string
+	caFile   string
+	insecure bool
+	timeout  time.Duration
+}
+
+// NewCommand creates the ghctl command.
+func NewCommand() *cobra.Command {
+	o := &options{}
+	cmd := &cobra.Command{
+		Use:   "ghctl",
+		Short: "ghctl lists the resources of the multicluster global hub and labels the managed clusters",
+		Long: "ghctl lists the managed clusters, policies, compliance and subscriptions of the multicluster " +
+			"global hub, and patches the labels of the managed clusters with the global hub API.\n\n" +
+			"The server and token are read from the " + serverEnv + " and " + tokenEnv +
+			" environment variables if the flags are not set.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.server, "server", os.Getenv(serverEnv),
+		"The URL of the global hub API, e.g. https://multicluster-global-hub-manager.apps.example.com")
+	flags.StringVar(&o.token, "token", os.Getenv(tokenEnv), "The bearer token to access the global hub API")
+	flags.StringVar(&o.caFile, "certificate-authority", "", "The path of the CA bundle to verify the server")
+	flags.BoolVar(&o.insecure, "insecure-skip-tls-verify", false,
+		"Skip the verification of the server certificate, it makes the connection insecure")
+	flags.DurationVar(&o.timeout, "request-timeout", 30*time.Second, "The timeout of a request to the server")
+
+	cmd.AddCommand(newGetCommand(o), newLabelCommand(o))
+	return cmd
+}
+
+func (o *options) client() (*client.Client, error) {
+	if o.server == "" {
+		return nil, fmt.Errorf("the server is required, set it by --server or the %s environment variable",
+			serverEnv)
+	}
+	config := &client.Config{
+		Host:        o.server,
+		BearerToken: o.token,
+		Insecure:    o.insecure,
+		Timeout:     o.timeout,
+	}
+	if o.caFile != "" {
+		caData, err := os.ReadFile(o.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the certificate authority: %w", err)
+		}
+		config.CAData = caData
+	}
+	return client.NewClient(config)
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package ghctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	appsv1 "open-cluster-management.io/multicloud-operators-subscription/pkg/apis/apps/v1"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/client"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+// resource describes how a resource type is requested and printed.
+type resource struct {
+	names []string
+	// kind is printed with the names of the objects, e.g. managedcluster/cluster1
+	kind    string
+	headers []string
+	// rows converts an object into the table rows, the compliance of a policy has a row per cluster
+	rows  func(object runtime.Object) [][]string
+	list  func(ctx context.Context, c *client.Client, options client.ListOptions) ([]runtime.Object, error)
+	watch func(ctx context.Context, c *client.Client, options client.ListOptions) (watch.Interface, error)
+}
+
+var resources = []*resource{
+	{
+		names:   []string{"clusters", "cluster", "managedclusters", "managedcluster", "mcl"},
+		kind:    "managedcluster",
+		headers: []string{"NAME", "HUB", "AVAILABLE", "ID"},
+		rows: func(object runtime.Object) [][]string {
+			cluster := object.(*clusterv1.ManagedCluster)
+			return [][]string{{
+				cluster.Name,
+				cluster.Annotations[constants.ManagedClusterManagedByAnnotation],
+				clusterAvailable(cluster),
+				string(cluster.UID),
+			}}
+		},
+		list: func(ctx context.Context, c *client.Client, options client.ListOptions) ([]runtime.Object, error) {
+			items, err := c.ManagedClusters(options).All(ctx)
+			return toObjects(items, err)
+		},
+		watch: func(ctx context.Context, c *client.Client, options client.ListOptions) (watch.Interface, error) {
+			return c.WatchManagedClusters(ctx, options)
+		},
+	},
+	{
+		names:   []string{"policies", "policy", "plc"},
+		kind:    "policy",
+		headers: []string{"NAMESPACE", "NAME", "REMEDIATION", "COMPLIANCE", "COMPLIANT", "NONCOMPLIANT"},
+		rows: func(object runtime.Object) [][]string {
+			policy := object.(*policyv1.Policy)
+			compliant, nonCompliant := 0, 0
+			for _, status := range policy.Status.Status {
+				switch status.ComplianceState {
+				case policyv1.Compliant:
+					compliant++
+				case policyv1.NonCompliant:
+					nonCompliant++
+				}
+			}
+			return [][]string{{
+				policy.Namespace,
+				policy.Name,
+				string(policy.Spec.RemediationAction),
+				string(policy.Status.ComplianceState),
+				fmt.Sprint(compliant),
+				fmt.Sprint(nonCompliant),
+			}}
+		},
+		list: listPolicies,
+		watch: func(ctx context.Context, c *client.Client, options client.ListOptions) (watch.Interface, error) {
+			return c.WatchPolicies(ctx, options)
+		},
+	},
+	{
+		names:   []string{"compliance"},
+		kind:    "policy",
+		headers: []string{"NAMESPACE", "POLICY", "CLUSTER", "COMPLIANCE"},
+		rows: func(object runtime.Object) [][]string {
+			policy := object.(*policyv1.Policy)
+			rows := [][]string{}
+			for _, status := range policy.Status.Status {
+				rows = append(rows, []string{
+					policy.Namespace,
+					policy.Name,
+					status.ClusterName,
+					string(status.ComplianceState),
+				})
+			}
+			return rows
+		},
+		list: listPolicies,
+		watch: func(ctx context.Context, c *client.Client, options client.ListOptions) (watch.Interface, error) {
+			return c.WatchPolicies(ctx, options)
+		},
+	},
+	{
+		names:   []string{"subscriptions", "subscription", "appsub"},
+		kind:    "subscription",
+		headers: []string{"NAMESPACE", "NAME", "STATE"},
+		rows: func(object runtime.Object) [][]string {
+			subscription := object.(*appsv1.Subscription)
+			return [][]string{{subscription.Namespace, subscription.Name, string(subscription.Status.Phase)}}
+		},
+		list: func(ctx context.Context, c *client.Client, options client.ListOptions) ([]runtime.Object, error) {
+			items, err := c.Subscriptions(options).All(ctx)
+			return toObjects(items, err)
+		},
+		watch: func(ctx context.Context, c *client.Client, options client.ListOptions) (watch.Interface, error) {
+			return c.WatchSubscriptions(ctx, options)
+		},
+	},
+}
+
+func listPolicies(ctx context.Context, c *client.Client, options client.ListOptions) ([]runtime.Object, error) {
+	items, err := c.Policies(options).All(ctx)
+	return toObjects(items, err)
+}
+
+// toObjects converts the items of the iterator to the objects, T is the struct type of the object.
+func toObjects[T any, P interface {
+	*T
+	runtime.Object
+}](items []T, err error,
+) ([]runtime.Object, error) {
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]runtime.Object, 0, len(items))
+	for i := range items {
+		objects = append(objects, P(&items[i]))
+	}
+	return objects, nil
+}
+
+func findResource(name string) (*resource, error) {
+	supported := []string{}
+	for _, r := range resources {
+		for _, alias := range r.names {
+			if strings.EqualFold(alias, name) {
+				return r, nil
+			}
+		}
+		supported = append(supported, r.names[0])
+	}
+	return nil, fmt.Errorf("unsupported resource %s, the supported resources: %s", name,
+		strings.Join(supported, ", "))
+}
+
+func clusterAvailable(cluster *clusterv1.ManagedCluster) string {
+	condition := meta.FindStatusCondition(cluster.Status.Conditions, clusterv1.ManagedClusterConditionAvailable)
+	if condition == nil {
+		return "Unknown"
+	}
+	return string(condition.Status)
+}
+
+type getOptions struct {
+	*options
+	labelSelector string
+	fieldSelector string
+	chunkSize     int
+	output        string
+	watch         bool
+}
+
+func newGetCommand(o *options) *cobra.Command {
+	g := &getOptions{options: o}
+	cmd := &cobra.Command{
+		Use:   "get (clusters | policies | compliance | subscriptions)",
+		Short: "List the resources of the global hub",
+		Example: `  # list the available managed clusters of the hub1
+  ghctl get clusters --field-selector status.available=True,leafHubName=hub1
+
+  # list the compliance of the policies in the default namespace
+  ghctl get compliance --field-selector metadata.namespace=default
+
+  # watch the policies with the label env=production
+  ghctl get policies -l env=production -w`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := findResource(args[0])
+			if err != nil {
+				return err
+			}
+			return g.run(cmd.Context(), cmd.OutOrStdout(), r)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&g.labelSelector, "selector", "l", "",
+		"The label selector, e.g. 'env in (dev,qa),!deprecated'")
+	flags.StringVar(&g.fieldSelector, "field-selector", "",
+		"The field selector, e.g. metadata.namespace=default")
+	flags.IntVar(&g.chunkSize, "chunk-size", client.DefaultPageSize,
+		"The number of the resources requested in a page")
+	flags.StringVarP(&g.output, "output", "o", outputTable,
+		fmt.Sprintf("The output format, one of %s", strings.Join(outputFormats, ", ")))
+	flags.BoolVarP(&g.watch, "watch", "w", false, "Watch the changes of the resources")
+	return cmd
+}
+
+func (g *getOptions) run(ctx context.Context, out io.Writer, r *resource) error {
+	p, err := newPrinter(g.output, r, out)
+	if err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	listOptions := client.ListOptions{
+		LabelSelector: g.labelSelector,
+		FieldSelector: g.fieldSelector,
+		Limit:         g.chunkSize,
+	}
+	if !g.watch {
+		objects, err := r.list(ctx, c, listOptions)
+		if err != nil {
+			return err
+		}
+		return p.printList(objects)
+	}
+
+	// the server sends all the matched resources in an interval, so the page isn't requested by the watch
+	listOptions.Limit = 0
+	watcher, err := r.watch(ctx, c, listOptions)
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			if event.Type == watch.Error {
+				return fmt.Errorf("failed to watch the %s: %v", r.names[0], event.Object)
+			}
+			if err := p.printEvent(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package ghctl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/ghctl"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/test/pkg/testapiserver"
+)
+
+// run executes the ghctl command with the args, and returns the output
+func run(args ...string) (string, error) {
+	cmd := ghctl.NewCommand()
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs(append(args, "--server", testAPIServer.URL, "--token", testapiserver.Token))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+var _ = Describe("ghctl", Ordered, func() {
+	cluster1ID := "2aa5547c-c172-47ed-b70b-db468c84d327"
+	cluster2ID := "18c9e13c-4488-4dcd-a5ac-1196093abbc0"
+	policyID := "d9347b09-bb46-4e2b-91ea-513e83ab9ea7"
+
+	BeforeAll(func() {
+		db := database.GetGorm()
+		for _, cluster := range []struct{ id, name, hub string }{
+			{cluster1ID, "cluster1", "hub1"},
+			{cluster2ID, "cluster1", "hub2"},
+		} {
+			err := db.Exec(`INSERT INTO status.managed_clusters (cluster_id,leaf_hub_name,payload,error)
+				VALUES (?, ?, ?, 'none')`, cluster.id, cluster.hub, fmt.Sprintf(`{
+					"kind": "ManagedCluster",
+					"apiVersion": "cluster.open-cluster-management.io/v1",
+					"metadata": {
+						"uid": "%s",
+						"name": "%s",
+						"annotations": {"global-hub.open-cluster-management.io/managed-by": "%s"}
+					},
+					"status": {"conditions": [{"type": "ManagedClusterConditionAvailable", "status": "True",
+						"reason": "", "message": "", "lastTransitionTime": "2024-01-01T00:00:00Z"}]}
+				}`, cluster.id, cluster.name, cluster.hub)).Error
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		err := db.Create(&models.SpecPolicy{
+			ID: policyID,
+			Payload: []byte(`{
+				"apiVersion": "policy.open-cluster-management.io/v1",
+				"kind": "Policy",
+				"metadata": {"name": "policy1", "namespace": "default", "uid": "` + policyID + `"},
+				"spec": {"disabled": false, "remediationAction": "inform", "policy-templates": []}
+			}`),
+		}).Error
+		Expect(err).NotTo(HaveOccurred())
+		err = db.Exec(`INSERT INTO status.compliance (policy_id,cluster_name,leaf_hub_name,error,compliance)
+			VALUES (?,'cluster1','hub1','none','non_compliant'), (?,'cluster1','hub2','none','compliant')`,
+			policyID, policyID).Error
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should list the managed clusters", func() {
+		out, err := run("get", "clusters", "--field-selector", "leafHubName=hub2")
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(strings.Fields(lines[0])).To(Equal([]string{"NAME", "HUB", "AVAILABLE", "ID"}))
+		Expect(strings.Fields(lines[1])).To(Equal([]string{"cluster1", "hub2", "True", cluster2ID}))
+
+		out, err = run("get", "mcl", "-o", "name", "--chunk-size", "1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("managedcluster/cluster1\nmanagedcluster/cluster1\n"))
+
+		_, err = run("get", "clusters", "-l", "env in (dev")
+		Expect(err).To(MatchError(ContainSubstring("400 Bad Request")))
+	})
+
+	It("should list the policies and the compliance", func() {
+		out, err := run("get", "policies")
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(strings.Fields(lines[1])).To(Equal([]string{"default", "policy1", "inform", "NonCompliant", "1", "1"}))
+
+		out, err = run("get", "compliance", "--field-selector", "metadata.namespace=default")
+		Expect(err).NotTo(HaveOccurred())
+		lines = strings.Split(strings.TrimSpace(out), "\n")
+		Expect(lines).To(HaveLen(3))
+		Expect(strings.Fields(lines[0])).To(Equal([]string{"NAMESPACE", "POLICY", "CLUSTER", "COMPLIANCE"}))
+		Expect(strings.Fields(lines[1])).To(Equal([]string{"default", "policy1", "cluster1", "NonCompliant"}))
+		Expect(strings.Fields(lines[2])).To(Equal([]string{"default", "policy1", "cluster1", "Compliant"}))
+
+		out, err = run("get", "policies", "-o", "json")
+		Expect(err).NotTo(HaveOccurred())
+		list := map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(out), &list)).To(Succeed())
+		Expect(list["kind"]).To(Equal("List"))
+		Expect(list["items"]).To(HaveLen(1))
+	})
+
+	It("should label the managed cluster", func() {
+		_, err := run("label", "cluster", "cluster1", "env=production")
+		Expect(err).To(MatchError(ContainSubstring("specify the hub by --hub")))
+
+		_, err = run("label", "cluster", "cluster1", "-env=production")
+		Expect(err).To(HaveOccurred())
+
+		out, err := run("label", "cluster", "cluster1", "env=production", "canary-", "--hub", "hub1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("managedcluster/cluster1 labeled\n"))
+
+		label := &models.ManagedClusterLabel{}
+		err = database.GetGorm().Where(&models.ManagedClusterLabel{ID: cluster1ID}).First(label).Error
+		Expect(err).NotTo(HaveOccurred())
+		Expect(label.LeafHubName).To(Equal("hub1"))
+		Expect(string(label.Labels)).To(MatchJSON(`{"env": "production"}`))
+		Expect(string(label.DeletedLabelKeys)).To(MatchJSON(`["canary"]`))
+
+		By("label the cluster by the ID")
+		out, err = run("label", "cluster", cluster2ID, "env=staging")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(fmt.Sprintf("managedcluster/%s labeled\n", cluster2ID)))
+	})
+})
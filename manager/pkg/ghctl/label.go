@@ -0,0 +1,132 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package ghctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/client"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+type labelOptions struct {
+	*options
+	hub string
+}
+
+func newLabelCommand(o *options) *cobra.Command {
+	l := &labelOptions{options: o}
+	cmd := &cobra.Command{
+		Use:   "label cluster (NAME | ID) KEY_1=VAL_1 ... KEY_N=VAL_N",
+		Short: "Update the labels of a managed cluster",
+		Long: "Update the labels of a managed cluster, the key ending with a dash removes the label. The labels " +
+			"are propagated to the managed cluster on its managed hub.",
+		Example: `  # add the label env=production and remove the label canary of the cluster1 managed by the hub1
+  ghctl label cluster cluster1 env=production canary- --hub hub1`,
+		Args: cobra.MinimumNArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if r, err := findResource(args[0]); err != nil || r != resources[0] {
+				return fmt.Errorf("only the labels of the managed clusters can be updated")
+			}
+			patch, err := parseLabels(args[2:])
+			if err != nil {
+				return err
+			}
+			return l.run(cmd.Context(), cmd.OutOrStdout(), args[1], patch)
+		},
+	}
+	cmd.Flags().StringVar(&l.hub, "hub", "", "The managed hub of the cluster if the name is used by multiple hubs")
+	return cmd
+}
+
+func (l *labelOptions) run(ctx context.Context, out io.Writer, nameOrID string, patch client.LabelPatch) error {
+	c, err := l.client()
+	if err != nil {
+		return err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cluster, err := l.findCluster(ctx, c, nameOrID)
+	if err != nil {
+		return err
+	}
+	if err := c.PatchManagedClusterLabels(ctx, string(cluster.UID), patch); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "managedcluster/%s labeled\n", cluster.Name)
+	return err
+}
+
+// findCluster finds the managed cluster by the name and the hub, or by the ID
+func (l *labelOptions) findCluster(ctx context.Context, c *client.Client, nameOrID string,
+) (*clusterv1.ManagedCluster, error) {
+	selector := fields.Set{"metadata.name": nameOrID}
+	if l.hub != "" {
+		selector["leafHubName"] = l.hub
+	}
+	clusters, err := c.ManagedClusters(client.ListOptions{
+		FieldSelector: selector.AsSelector().String(),
+	}).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(clusters) == 0 {
+		// patch the cluster by the ID directly, the server responds with not found if it doesn't exist
+		if _, err := uuid.Parse(nameOrID); err == nil {
+			return &clusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: nameOrID, UID: types.UID(nameOrID)},
+			}, nil
+		}
+		return nil, fmt.Errorf("managed cluster %s not found", nameOrID)
+	}
+	if len(clusters) > 1 {
+		hubs := []string{}
+		for _, cluster := range clusters {
+			hubs = append(hubs, cluster.Annotations[constants.ManagedClusterManagedByAnnotation])
+		}
+		return nil, fmt.Errorf("managed cluster %s is found on the hubs %s, specify the hub by --hub", nameOrID,
+			strings.Join(hubs, ", "))
+	}
+	return &clusters[0], nil
+}
+
+// parseLabels parses the KEY=VALUE to set the label and the KEY- to remove the label
+func parseLabels(args []string) (client.LabelPatch, error) {
+	patch := client.LabelPatch{Set: map[string]string{}}
+	for _, arg := range args {
+		if key, ok := strings.CutSuffix(arg, "-"); ok && !strings.Contains(arg, "=") {
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				return patch, fmt.Errorf("invalid label key %s: %s", key, strings.Join(errs, "; "))
+			}
+			patch.Remove = append(patch.Remove, key)
+			continue
+		}
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return patch, fmt.Errorf("invalid label %s, it should be KEY=VALUE or KEY-", arg)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return patch, fmt.Errorf("invalid label key %s: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return patch, fmt.Errorf("invalid label value %s: %s", value, strings.Join(errs, "; "))
+		}
+		patch.Set[key] = value
+	}
+	return patch, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package ghctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputName  = "name"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML, outputName}
+
+// printer writes the resources in the output format.
+type printer struct {
+	format   string
+	resource *resource
+	out      io.Writer
+	// eventHeaders is true once the headers of the watch events are printed
+	eventHeaders bool
+}
+
+func newPrinter(format string, r *resource, out io.Writer) (*printer, error) {
+	for _, supported := range outputFormats {
+		if format == supported {
+			return &printer{format: format, resource: r, out: out}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported output format %s, the supported formats: %s", format,
+		strings.Join(outputFormats, ", "))
+}
+
+func (p *printer) printList(objects []runtime.Object) error {
+	switch p.format {
+	case outputJSON, outputYAML:
+		return p.printObject(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      objects,
+		})
+	case outputName:
+		for _, object := range objects {
+			if _, err := fmt.Fprintln(p.out, p.name(object)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rows := [][]string{}
+	for _, object := range objects {
+		rows = append(rows, p.resource.rows(object)...)
+	}
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(p.out, "No resources found")
+		return err
+	}
+	return p.printTable(p.resource.headers, rows)
+}
+
+func (p *printer) printEvent(event watch.Event) error {
+	switch p.format {
+	case outputJSON:
+		data, err := json.Marshal(map[string]interface{}{"type": event.Type, "object": event.Object})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.out, string(data))
+		return err
+	case outputYAML:
+		if _, err := fmt.Fprintln(p.out, "---"); err != nil {
+			return err
+		}
+		return p.printObject(map[string]interface{}{"type": event.Type, "object": event.Object})
+	case outputName:
+		_, err := fmt.Fprintf(p.out, "%s %s\n", event.Type, p.name(event.Object))
+		return err
+	}
+
+	rows := [][]string{}
+	for _, row := range p.resource.rows(event.Object) {
+		rows = append(rows, append([]string{string(event.Type)}, row...))
+	}
+	var headers []string
+	if !p.eventHeaders {
+		headers = append([]string{"EVENT"}, p.resource.headers...)
+		p.eventHeaders = true
+	}
+	return p.printTable(headers, rows)
+}
+
+func (p *printer) printObject(object interface{}) error {
+	var data []byte
+	var err error
+	if p.format == outputYAML {
+		data, err = yaml.Marshal(object)
+	} else {
+		data, err = json.MarshalIndent(object, "", "    ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	_, err = p.out.Write(data)
+	return err
+}
+
+func (p *printer) printTable(headers []string, rows [][]string) error {
+	writer := tabwriter.NewWriter(p.out, 0, 8, 3, ' ', 0)
+	if len(headers) > 0 {
+		fmt.Fprintln(writer, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// name returns the kind/namespace/name of the object
+func (p *printer) name(object runtime.Object) string {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return p.resource.kind
+	}
+	if accessor.GetNamespace() == "" {
+		return p.resource.kind + "/" + accessor.GetName()
+	}
+	return p.resource.kind + "/" + accessor.GetNamespace() + "/" + accessor.GetName()
+}
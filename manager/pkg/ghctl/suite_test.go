@@ -0,0 +1,36 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package ghctl_test
+
+import (
+	"testing"
+
+	_ "github.com/lib/pq"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/stolostron/multicluster-global-hub/test/pkg/testapiserver"
+)
+
+var testAPIServer *testapiserver.TestAPIServer
+
+func TestGhctl(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ghctl Suite")
+}
+
+var _ = BeforeSuite(func() {
+	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+
+	var err error
+	testAPIServer, err = testapiserver.NewTestAPIServer()
+	Expect(err).NotTo(HaveOccurred())
+})
+
+var _ = AfterSuite(func() {
+	By("tearing down the test environment")
+	Expect(testAPIServer.Stop()).To(Succeed())
+})
@@ -0,0 +1,153 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+const (
+	syncInterval = 5 * time.Second
+	// resendInterval is the time to wait for the result of the stage before requesting it again
+	resendInterval = time.Minute
+)
+
+// migrationController starts the migrations, requests the stages of the clusters from the source and the target hubs,
+// and rolls back the clusters once the migration is timed out. The results of the stages are applied by the status
+// syncer of the migration.
+type migrationController struct {
+	log            logr.Logger
+	producer       transport.Producer
+	interval       time.Duration
+	resendInterval time.Duration
+}
+
+func AddMigrationController(mgr ctrl.Manager, producer transport.Producer) error {
+	return mgr.Add(&migrationController{
+		log:            ctrl.Log.WithName("migration-controller"),
+		producer:       producer,
+		interval:       syncInterval,
+		resendInterval: resendInterval,
+	})
+}
+
+func (c *migrationController) Start(ctx context.Context) error {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.reconcile(ctx); err != nil {
+					c.log.Error(err, "failed to reconcile the migrations")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *migrationController) reconcile(ctx context.Context) error {
+	db := database.GetGorm()
+	var migrations []models.ManagedClusterMigration
+	if err := db.Select("id").Where("phase IN ?", []string{PhasePending, PhaseRunning}).Order("created_at").
+		Find(&migrations).Error; err != nil {
+		return err
+	}
+	for _, migration := range migrations {
+		err := Update(db, migration.ID, func(migration *models.ManagedClusterMigration, progress []ClusterProgress,
+		) ([]ClusterProgress, error) {
+			if migration.Phase == PhasePending {
+				return c.start(migration)
+			}
+			return progress, c.progress(ctx, migration, progress)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update the migration %s: %w", migration.ID, err)
+		}
+	}
+	return nil
+}
+
+// start records the labels of the clusters on the source hub, the migration fails if the clusters are removed from
+// the source hub after it's created.
+func (c *migrationController) start(migration *models.ManagedClusterMigration) ([]ClusterProgress, error) {
+	clusters := []string{}
+	if err := json.Unmarshal(migration.Clusters, &clusters); err != nil {
+		return nil, err
+	}
+	labels, err := clusterLabels(database.GetGorm(), migration.SourceHub, clusters)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, cluster := range clusters {
+		if _, ok := labels[cluster]; !ok {
+			migration.Phase, migration.CompletedAt = PhaseFailed, &now
+			migration.Message = fmt.Sprintf("the cluster %s isn't managed by the hub %s", cluster, migration.SourceHub)
+			return nil, nil
+		}
+	}
+	migration.Phase, migration.StartedAt = PhaseRunning, &now
+	migration.Message = fmt.Sprintf("0 of %d clusters are migrated", len(clusters))
+	c.log.Info("migration is started", "id", migration.ID, "source", migration.SourceHub,
+		"target", migration.TargetHub, "clusters", clusters)
+	return NewProgress(clusters, labels), nil
+}
+
+// progress rolls back the timed out clusters, and requests the stages the clusters wait for.
+func (c *migrationController) progress(ctx context.Context, migration *models.ManagedClusterMigration,
+	progress []ClusterProgress,
+) error {
+	now := time.Now()
+	startedAt := now
+	if migration.StartedAt != nil {
+		startedAt = *migration.StartedAt
+	}
+	phase, message := Evaluate(progress, migration.SourceHub, migration.TargetHub, startedAt,
+		time.Duration(migration.TimeoutSeconds)*time.Second, now)
+
+	for _, request := range Requests(migration.ID, progress, migration.SourceHub, migration.TargetHub, now,
+		c.resendInterval) {
+		if err := Send(ctx, c.producer, request); err != nil {
+			// the stage is requested again after the resend interval
+			c.log.Error(err, "failed to request the migration stage", "id", migration.ID,
+				"hub", request.LeafHubName, "stage", request.Migration.Stage)
+		}
+	}
+
+	migration.Phase, migration.Message = phase, message
+	if phase != PhaseRunning {
+		migration.CompletedAt = &now
+		c.log.Info("migration is finished", "id", migration.ID, "phase", phase, "message", message)
+	}
+	return nil
+}
+
+// Send requests the stage of the migration to the hub.
+func Send(ctx context.Context, producer transport.Producer, request Request) error {
+	payload, err := json.Marshal(request.Migration)
+	if err != nil {
+		return err
+	}
+	return producer.Send(ctx, &transport.Message{
+		Key:         constants.ManagedClusterMigrationMsgKey,
+		Destination: request.LeafHubName,
+		MsgType:     constants.SpecBundle,
+		Payload:     payload,
+	})
+}
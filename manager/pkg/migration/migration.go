@@ -0,0 +1,596 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package migration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
+)
+
+// the phases of the migration
+const (
+	PhasePending    = "Pending"
+	PhaseRunning    = "Running"
+	PhaseCompleted  = "Completed"
+	PhaseRolledBack = "RolledBack"
+	PhaseFailed     = "Failed"
+)
+
+// the phases of the cluster, each of the running phases waits for the result of a stage from the source or the
+// target hub: Exporting -> Preparing -> Deploying -> Registering -> Detaching -> Completed. The cluster is rolled back
+// once a stage fails or the migration is timed out before the cluster is detached from the source hub.
+const (
+	ClusterPhaseExporting   = "Exporting"
+	ClusterPhasePreparing   = "Preparing"
+	ClusterPhaseDeploying   = "Deploying"
+	ClusterPhaseRegistering = "Registering"
+	ClusterPhaseDetaching   = "Detaching"
+	ClusterPhaseCompleted   = "Completed"
+	ClusterPhaseRollingBack = "RollingBack"
+	ClusterPhaseRolledBack  = "RolledBack"
+	ClusterPhaseFailed      = "Failed"
+)
+
+const (
+	DefaultTimeout = 30 * time.Minute
+	// the hub status is maintained by the hub management
+	hubInactive = "inactive"
+)
+
+var (
+	// ErrInvalidMigration means the migration can't be started with the hubs or the clusters
+	ErrInvalidMigration = errors.New("invalid migration")
+	// ErrConflictingMigration means the clusters are being migrated by another migration
+	ErrConflictingMigration = errors.New("conflicting migration")
+)
+
+// ClusterProgress is the migration progress of a cluster.
+type ClusterProgress struct {
+	Name    string `json:"name"`
+	Phase   string `json:"phase"`
+	Message string `json:"message,omitempty"`
+	// Labels are the labels on the source hub, they're set on the target hub with the cluster set label
+	Labels map[string]string `json:"labels,omitempty"`
+	// ClusterSetBindings are the namespaces which bind the cluster set of the cluster on the source hub, they're
+	// bound on the target hub too
+	ClusterSetBindings []string `json:"clusterSetBindings,omitempty"`
+	// Deployed means the bootstrap kubeconfig of the target hub is forwarded to the source hub, so the cluster might
+	// have switched to the target hub, then it's rolled back through the target hub
+	Deployed bool `json:"deployed,omitempty"`
+	// RollbackHubs are the hubs which haven't rolled back the cluster
+	RollbackHubs []string `json:"rollbackHubs,omitempty"`
+	// RequestedAt is the time the stage of the phase is requested, nil means it isn't requested
+	RequestedAt *time.Time `json:"requestedAt,omitempty"`
+}
+
+// Create requests to migrate the clusters from the source hub to the target hub, the migration is validated before
+// it's created, and it's started by the migration controller.
+func Create(db *gorm.DB, sourceHub, targetHub string, clusters []string, timeout time.Duration,
+) (*models.ManagedClusterMigration, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if err := Validate(db, sourceHub, targetHub, clusters); err != nil {
+		return nil, err
+	}
+	sorted := append([]string{}, clusters...)
+	sort.Strings(sorted)
+	payload, err := json.Marshal(sorted)
+	if err != nil {
+		return nil, err
+	}
+	migration := &models.ManagedClusterMigration{
+		ID:             uuid.New().String(),
+		SourceHub:      sourceHub,
+		TargetHub:      targetHub,
+		Clusters:       payload,
+		TimeoutSeconds: int(timeout.Seconds()),
+		Phase:          PhasePending,
+	}
+	if err := db.Create(migration).Error; err != nil {
+		return nil, fmt.Errorf("failed to create the migration: %w", err)
+	}
+	return migration, nil
+}
+
+// Validate verifies the hubs are active, the clusters are managed by the source hub, and they aren't migrated by the
+// other migrations.
+func Validate(db *gorm.DB, sourceHub, targetHub string, clusters []string) error {
+	if sourceHub == "" || targetHub == "" || sourceHub == targetHub {
+		return fmt.Errorf("%w: the source hub and the target hub must be different hubs", ErrInvalidMigration)
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("%w: the clusters are empty", ErrInvalidMigration)
+	}
+	for _, hub := range []string{sourceHub, targetHub} {
+		heartbeat := &models.LeafHubHeartbeat{}
+		err := db.Where("leaf_hub_name = ?", hub).First(heartbeat).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && heartbeat.Status == hubInactive) {
+			return fmt.Errorf("%w: the hub %s isn't active", ErrInvalidMigration, hub)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	labels, err := clusterLabels(db, sourceHub, clusters)
+	if err != nil {
+		return err
+	}
+	for _, cluster := range clusters {
+		if _, ok := labels[cluster]; !ok {
+			return fmt.Errorf("%w: the cluster %s isn't managed by the hub %s", ErrInvalidMigration, cluster,
+				sourceHub)
+		}
+	}
+
+	var migrations []models.ManagedClusterMigration
+	if err := db.Where("phase IN ?", []string{PhasePending, PhaseRunning}).Find(&migrations).Error; err != nil {
+		return err
+	}
+	requested := map[string]bool{}
+	for _, cluster := range clusters {
+		requested[cluster] = true
+	}
+	for _, migration := range migrations {
+		migrating := []string{}
+		if err := json.Unmarshal(migration.Clusters, &migrating); err != nil {
+			return err
+		}
+		for _, cluster := range migrating {
+			if requested[cluster] {
+				return fmt.Errorf("%w: the cluster %s is being migrated by %s", ErrConflictingMigration, cluster,
+					migration.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// clusterLabels returns the labels of the clusters managed by the hub, the labels maintained by the hub aren't
+// returned.
+func clusterLabels(db *gorm.DB, leafHubName string, clusters []string) (map[string]map[string]string, error) {
+	var managedClusters []models.ManagedCluster
+	if err := db.Where("leaf_hub_name = ? AND cluster_name IN ?", leafHubName, clusters).
+		Find(&managedClusters).Error; err != nil {
+		return nil, err
+	}
+	labels := map[string]map[string]string{}
+	for _, managedCluster := range managedClusters {
+		payload, err := sensitive.Decrypt(managedCluster.Payload)
+		if err != nil {
+			return nil, err
+		}
+		object := &struct {
+			metav1.ObjectMeta `json:"metadata"`
+		}{}
+		if err := json.Unmarshal(payload, object); err != nil {
+			return nil, err
+		}
+		clusterLabels := map[string]string{}
+		for key, value := range object.Labels {
+			if strings.HasPrefix(key, "feature.open-cluster-management.io/") || key == "local-cluster" {
+				continue
+			}
+			clusterLabels[key] = value
+		}
+		labels[object.Name] = clusterLabels
+	}
+	return labels, nil
+}
+
+// NewProgress starts the migration of the clusters with the labels on the source hub.
+func NewProgress(clusters []string, labels map[string]map[string]string) []ClusterProgress {
+	progress := make([]ClusterProgress, 0, len(clusters))
+	for _, cluster := range clusters {
+		progress = append(progress, ClusterProgress{
+			Name:   cluster,
+			Phase:  ClusterPhaseExporting,
+			Labels: labels[cluster],
+		})
+	}
+	sort.Slice(progress, func(i, j int) bool { return progress[i].Name < progress[j].Name })
+	return progress
+}
+
+// ApplyStatus updates the progress with the result of the stage from the hub, the results which the clusters don't
+// wait for are ignored, e.g. the results resent by the agent. it returns true if the progress is changed, and the
+// stages to forward to the hubs with the bootstrap kubeconfigs, which are never kept in the progress.
+func ApplyStatus(progress []ClusterProgress, leafHubName, sourceHub, targetHub string,
+	status *spec.MigrationStatus, now time.Time,
+) (bool, []Request) {
+	results := map[string]*spec.MigrationClusterStatus{}
+	for _, result := range status.Clusters {
+		results[result.Name] = result
+	}
+	forwards := newRequestSet(status.MigrationID, sourceHub, targetHub)
+	forward := func(cluster *ClusterProgress, hub, stage string, kubeconfig []byte) {
+		forwards.add(hub, stage, &spec.MigrationClusterSpec{Name: cluster.Name, BootstrapKubeconfig: kubeconfig})
+		requestedAt := now
+		cluster.RequestedAt = &requestedAt
+	}
+	changed := false
+	for i := range progress {
+		cluster := &progress[i]
+		result, ok := results[cluster.Name]
+		if !ok {
+			continue
+		}
+		// the resent bootstrap kubeconfig of the target hub is forwarded to the source hub again
+		if cluster.Phase == ClusterPhaseDeploying && status.Stage == spec.MigrationStagePrepare &&
+			leafHubName == targetHub && result.Error == "" {
+			forward(cluster, sourceHub, spec.MigrationStageDeploy, result.BootstrapKubeconfig)
+			changed = true
+			continue
+		}
+		if status.Stage != stageOf(cluster) {
+			continue
+		}
+		switch cluster.Phase {
+		case ClusterPhaseExporting:
+			if leafHubName != sourceHub {
+				continue
+			}
+			if result.Error != "" {
+				// nothing is changed on the hubs yet
+				next(cluster, ClusterPhaseRolledBack, fmt.Sprintf("failed to export the cluster: %s", result.Error))
+			} else {
+				cluster.ClusterSetBindings = result.ClusterSetBindings
+				next(cluster, ClusterPhasePreparing, "the cluster is exported from the source hub")
+			}
+		case ClusterPhasePreparing:
+			if leafHubName != targetHub {
+				continue
+			}
+			if result.Error != "" {
+				rollback(cluster, fmt.Sprintf("failed to prepare the cluster: %s", result.Error), targetHub)
+			} else {
+				next(cluster, ClusterPhaseDeploying, "the cluster is prepared on the target hub")
+				cluster.Deployed = true
+				forward(cluster, sourceHub, spec.MigrationStageDeploy, result.BootstrapKubeconfig)
+			}
+		case ClusterPhaseDeploying:
+			if leafHubName != sourceHub {
+				continue
+			}
+			if result.Error != "" {
+				rollback(cluster, fmt.Sprintf("failed to deploy the bootstrap kubeconfig: %s", result.Error),
+					sourceHub, targetHub)
+			} else {
+				next(cluster, ClusterPhaseRegistering, "the bootstrap kubeconfig is deployed to the cluster")
+			}
+		case ClusterPhaseRegistering:
+			if leafHubName != targetHub {
+				continue
+			}
+			// the registration is requested again until the migration is timed out
+			if result.Error != "" {
+				cluster.Message = result.Error
+			} else {
+				next(cluster, ClusterPhaseDetaching, "the cluster joins the target hub")
+			}
+		case ClusterPhaseDetaching:
+			if leafHubName != sourceHub {
+				continue
+			}
+			if result.Error != "" {
+				// the cluster has joined the target hub, so it isn't rolled back
+				next(cluster, ClusterPhaseFailed, fmt.Sprintf("failed to detach the cluster from the source hub: %s",
+					result.Error))
+			} else {
+				next(cluster, ClusterPhaseCompleted, "the cluster is migrated")
+			}
+		case ClusterPhaseRollingBack:
+			forwarded := false
+			if leafHubName == sourceHub && result.Error == "" && restoredByTarget(cluster, targetHub) {
+				// the cluster might only reach the target hub, so the target hub deploys the bootstrap kubeconfig
+				// of the source hub to it
+				forward(cluster, targetHub, spec.MigrationStageRollback, result.BootstrapKubeconfig)
+				forwarded = true
+			}
+			remaining := []string{}
+			for _, hub := range cluster.RollbackHubs {
+				if hub != leafHubName {
+					remaining = append(remaining, hub)
+				}
+			}
+			if len(remaining) == len(cluster.RollbackHubs) {
+				if forwarded {
+					changed = true
+				}
+				continue
+			}
+			if result.Error != "" {
+				next(cluster, ClusterPhaseFailed, fmt.Sprintf("failed to roll back the cluster on the hub %s: %s",
+					leafHubName, result.Error))
+			} else if cluster.RollbackHubs = remaining; len(remaining) == 0 {
+				next(cluster, ClusterPhaseRolledBack, fmt.Sprintf("the cluster is rolled back, %s", cluster.Message))
+			}
+		default:
+			continue
+		}
+		changed = true
+	}
+	return changed, forwards.list()
+}
+
+// restoredByTarget returns true if the target hub waits for the bootstrap kubeconfig of the source hub to roll back
+// the cluster, which might have switched to the target hub.
+func restoredByTarget(cluster *ClusterProgress, targetHub string) bool {
+	if !cluster.Deployed {
+		return false
+	}
+	for _, hub := range cluster.RollbackHubs {
+		if hub == targetHub {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate rolls back the clusters which aren't detached from the source hub once the timeout is exceeded, and
+// fails the clusters which aren't detached or rolled back within twice the timeout. it returns the phase and the
+// message of the migration.
+func Evaluate(progress []ClusterProgress, sourceHub, targetHub string, startedAt time.Time, timeout time.Duration,
+	now time.Time,
+) (string, string) {
+	deadlineExceeded := now.After(startedAt.Add(timeout))
+	failureDeadlineExceeded := now.After(startedAt.Add(2 * timeout))
+	counts := map[string]int{}
+	for i := range progress {
+		cluster := &progress[i]
+		switch cluster.Phase {
+		case ClusterPhaseExporting:
+			if deadlineExceeded {
+				next(cluster, ClusterPhaseRolledBack, "the cluster isn't exported within the timeout")
+			}
+		case ClusterPhasePreparing:
+			if deadlineExceeded {
+				rollback(cluster, "the cluster isn't prepared within the timeout", targetHub)
+			}
+		case ClusterPhaseDeploying, ClusterPhaseRegistering:
+			if deadlineExceeded {
+				rollback(cluster, "the cluster doesn't join the target hub within the timeout", sourceHub, targetHub)
+			}
+		case ClusterPhaseDetaching:
+			if failureDeadlineExceeded {
+				next(cluster, ClusterPhaseFailed, "the cluster isn't detached from the source hub within twice the timeout")
+			}
+		case ClusterPhaseRollingBack:
+			if failureDeadlineExceeded {
+				next(cluster, ClusterPhaseFailed, "the cluster isn't rolled back within twice the timeout")
+			}
+		}
+		counts[cluster.Phase]++
+	}
+
+	total := len(progress)
+	finished := counts[ClusterPhaseCompleted] + counts[ClusterPhaseRolledBack] + counts[ClusterPhaseFailed]
+	if finished < total {
+		return PhaseRunning, fmt.Sprintf("%d of %d clusters are migrated", counts[ClusterPhaseCompleted], total)
+	}
+	message := fmt.Sprintf("%d of %d clusters are migrated, %d are rolled back and %d are failed",
+		counts[ClusterPhaseCompleted], total, counts[ClusterPhaseRolledBack], counts[ClusterPhaseFailed])
+	switch {
+	case counts[ClusterPhaseFailed] > 0:
+		return PhaseFailed, message
+	case counts[ClusterPhaseRolledBack] > 0:
+		return PhaseRolledBack, message
+	default:
+		return PhaseCompleted, message
+	}
+}
+
+// Request is the stage requested to the hub for the clusters.
+type Request struct {
+	LeafHubName string
+	Migration   *spec.MigrationSpec
+}
+
+// Requests returns the stages to request for the clusters, which aren't requested or whose requests aren't
+// answered within the resend interval. the requested clusters are marked with the time.
+func Requests(migrationID string, progress []ClusterProgress, sourceHub, targetHub string, now time.Time,
+	resendInterval time.Duration,
+) []Request {
+	requests := newRequestSet(migrationID, sourceHub, targetHub)
+	for i := range progress {
+		cluster := &progress[i]
+		stage := stageOf(cluster)
+		if stage == "" || (cluster.RequestedAt != nil && now.Sub(*cluster.RequestedAt) < resendInterval) {
+			continue
+		}
+		hubs := []string{targetHub}
+		clusterSpec := &spec.MigrationClusterSpec{Name: cluster.Name}
+		switch cluster.Phase {
+		case ClusterPhaseExporting:
+			hubs = []string{sourceHub}
+		case ClusterPhasePreparing:
+			clusterSpec.Labels, clusterSpec.ClusterSetBindings = cluster.Labels, cluster.ClusterSetBindings
+		case ClusterPhaseDeploying:
+			// the bootstrap kubeconfig isn't kept, so it's exported by the target hub again, and the result is
+			// forwarded to the source hub
+			stage = spec.MigrationStagePrepare
+			clusterSpec.Labels, clusterSpec.ClusterSetBindings = cluster.Labels, cluster.ClusterSetBindings
+		case ClusterPhaseDetaching:
+			hubs = []string{sourceHub}
+		case ClusterPhaseRollingBack:
+			hubs = cluster.RollbackHubs
+			if restoredByTarget(cluster, targetHub) {
+				// the source hub exports its bootstrap kubeconfig, and the result is forwarded to the target hub
+				hubs = []string{sourceHub}
+			}
+		}
+		for _, hub := range hubs {
+			requests.add(hub, stage, clusterSpec)
+		}
+		requestedAt := now
+		cluster.RequestedAt = &requestedAt
+	}
+	return requests.list()
+}
+
+// requestSet groups the clusters of the requests by the hub and the stage.
+type requestSet struct {
+	migrationID string
+	sourceHub   string
+	targetHub   string
+	requests    map[string]*Request
+	keys        []string
+}
+
+func newRequestSet(migrationID, sourceHub, targetHub string) *requestSet {
+	return &requestSet{
+		migrationID: migrationID,
+		sourceHub:   sourceHub,
+		targetHub:   targetHub,
+		requests:    map[string]*Request{},
+	}
+}
+
+func (s *requestSet) add(hub, stage string, cluster *spec.MigrationClusterSpec) {
+	key := hub + "/" + stage
+	if _, ok := s.requests[key]; !ok {
+		s.requests[key] = &Request{
+			LeafHubName: hub,
+			Migration: &spec.MigrationSpec{
+				MigrationID: s.migrationID,
+				Stage:       stage,
+				SourceHub:   s.sourceHub,
+				TargetHub:   s.targetHub,
+			},
+		}
+		s.keys = append(s.keys, key)
+	}
+	s.requests[key].Migration.Clusters = append(s.requests[key].Migration.Clusters, cluster)
+}
+
+func (s *requestSet) list() []Request {
+	sort.Strings(s.keys)
+	result := make([]Request, 0, len(s.keys))
+	for _, key := range s.keys {
+		result = append(result, *s.requests[key])
+	}
+	return result
+}
+
+// stageOf returns the stage the cluster waits for, or empty if the cluster is finished.
+func stageOf(cluster *ClusterProgress) string {
+	switch cluster.Phase {
+	case ClusterPhaseExporting:
+		return spec.MigrationStageExport
+	case ClusterPhasePreparing:
+		return spec.MigrationStagePrepare
+	case ClusterPhaseDeploying:
+		return spec.MigrationStageDeploy
+	case ClusterPhaseRegistering:
+		return spec.MigrationStageRegister
+	case ClusterPhaseDetaching:
+		return spec.MigrationStageDetach
+	case ClusterPhaseRollingBack:
+		return spec.MigrationStageRollback
+	}
+	return ""
+}
+
+// next moves the cluster to the phase.
+func next(cluster *ClusterProgress, phase, message string) {
+	cluster.Phase, cluster.Message, cluster.RequestedAt = phase, message, nil
+}
+
+func rollback(cluster *ClusterProgress, message string, hubs ...string) {
+	next(cluster, ClusterPhaseRollingBack, message)
+	cluster.RollbackHubs = hubs
+}
+
+// Update locks the running migration and updates it with the progress, it's skipped if the migration isn't running.
+func Update(db *gorm.DB, migrationID string,
+	update func(migration *models.ManagedClusterMigration, progress []ClusterProgress) ([]ClusterProgress, error),
+) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		migration := &models.ManagedClusterMigration{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND phase IN ?", migrationID, []string{PhasePending, PhaseRunning}).
+			First(migration).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		progress, err := DecodeProgress(migration)
+		if err != nil {
+			return err
+		}
+		if progress, err = update(migration, progress); err != nil {
+			return err
+		}
+		if migration.Progress, err = encodeProgress(progress); err != nil {
+			return err
+		}
+		return tx.Save(migration).Error
+	})
+}
+
+// DecodeProgress returns the progress of the clusters, the encrypted fields are decrypted.
+func DecodeProgress(migration *models.ManagedClusterMigration) ([]ClusterProgress, error) {
+	progress := []ClusterProgress{}
+	if len(migration.Progress) == 0 {
+		return progress, nil
+	}
+	payload, err := sensitive.Decrypt(migration.Progress)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, &progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+func encodeProgress(progress []ClusterProgress) ([]byte, error) {
+	if progress == nil {
+		return nil, nil
+	}
+	payload, err := json.Marshal(progress)
+	if err != nil {
+		return nil, err
+	}
+	return sensitive.Encrypt(models.ManagedClusterMigration{}.TableName(), payload)
+}
+
+// HandleStatus applies the results of the stages reported by the hub to the running migrations. It returns the
+// stages to forward to the hubs once the results are applied.
+func HandleStatus(db *gorm.DB, leafHubName string, statuses []*spec.MigrationStatus) ([]Request, error) {
+	forwards := []Request{}
+	for _, status := range statuses {
+		var requests []Request
+		err := Update(db, status.MigrationID, func(migration *models.ManagedClusterMigration,
+			progress []ClusterProgress,
+		) ([]ClusterProgress, error) {
+			if migration.Phase == PhaseRunning {
+				_, requests = ApplyStatus(progress, leafHubName, migration.SourceHub, migration.TargetHub, status,
+					time.Now())
+			}
+			return progress, nil
+		})
+		if err != nil {
+			return forwards, fmt.Errorf("failed to update the migration %s: %w", status.MigrationID, err)
+		}
+		forwards = append(forwards, requests...)
+	}
+	return forwards, nil
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package migration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+)
+
+func TestMigrationProgress(t *testing.T) {
+	startedAt := time.Now()
+	timeout := 30 * time.Minute
+	progress := NewProgress([]string{"cluster2", "cluster1"}, map[string]map[string]string{
+		"cluster1": {"env": "dev", "cluster.open-cluster-management.io/clusterset": "set1"},
+		"cluster2": {"env": "prod"},
+	})
+	require.Len(t, progress, 2)
+	assert.Equal(t, "cluster1", progress[0].Name)
+	assert.Equal(t, ClusterPhaseExporting, progress[0].Phase)
+
+	// export the cluster set bindings from the source hub
+	requests := Requests("m1", progress, "hub1", "hub2", startedAt, time.Minute)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "hub1", requests[0].LeafHubName)
+	assert.Equal(t, spec.MigrationStageExport, requests[0].Migration.Stage)
+	changed, forwards := ApplyStatus(progress, "hub1", "hub1", "hub2", &spec.MigrationStatus{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStageExport,
+		Clusters: []*spec.MigrationClusterStatus{
+			{Name: "cluster1", ClusterSetBindings: []string{"ns1"}},
+			{Name: "cluster2"},
+		},
+	}, startedAt)
+	assert.True(t, changed)
+	assert.Empty(t, forwards)
+	assert.Equal(t, ClusterPhasePreparing, progress[0].Phase)
+	assert.Equal(t, []string{"ns1"}, progress[0].ClusterSetBindings)
+
+	// prepare the clusters on the target hub
+	requests = Requests("m1", progress, "hub1", "hub2", startedAt, time.Minute)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "hub2", requests[0].LeafHubName)
+	assert.Equal(t, spec.MigrationStagePrepare, requests[0].Migration.Stage)
+	require.Len(t, requests[0].Migration.Clusters, 2)
+	assert.Equal(t, "set1", requests[0].Migration.Clusters[0].Labels["cluster.open-cluster-management.io/clusterset"])
+	assert.Equal(t, []string{"ns1"}, requests[0].Migration.Clusters[0].ClusterSetBindings)
+	assert.Empty(t, Requests("m1", progress, "hub1", "hub2", startedAt.Add(30*time.Second), time.Minute))
+	assert.Len(t, Requests("m1", progress, "hub1", "hub2", startedAt.Add(time.Minute), time.Minute), 1,
+		"the stage is requested again after the resend interval")
+
+	// the result from the other hub is ignored, and the bootstrap kubeconfig is forwarded to the source hub
+	prepared := &spec.MigrationStatus{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStagePrepare,
+		Clusters: []*spec.MigrationClusterStatus{
+			{Name: "cluster1", BootstrapKubeconfig: []byte("kubeconfig")},
+			{Name: "cluster2", Error: "the cluster cluster2 exists on the target hub"},
+		},
+	}
+	changed, _ = ApplyStatus(progress, "hub1", "hub1", "hub2", prepared, startedAt.Add(time.Minute))
+	assert.False(t, changed)
+	changed, forwards = ApplyStatus(progress, "hub2", "hub1", "hub2", prepared, startedAt.Add(time.Minute))
+	assert.True(t, changed)
+	require.Len(t, forwards, 1)
+	assert.Equal(t, "hub1", forwards[0].LeafHubName)
+	assert.Equal(t, spec.MigrationStageDeploy, forwards[0].Migration.Stage)
+	assert.Equal(t, "kubeconfig", string(forwards[0].Migration.Clusters[0].BootstrapKubeconfig))
+	assert.Equal(t, ClusterPhaseDeploying, progress[0].Phase)
+	assert.True(t, progress[0].Deployed)
+	assert.Equal(t, ClusterPhaseRollingBack, progress[1].Phase)
+	assert.Equal(t, []string{"hub2"}, progress[1].RollbackHubs)
+	encoded, err := encodeProgress(progress)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "kubeconfig", "the bootstrap kubeconfig isn't kept")
+
+	// roll back the failed cluster on the target hub, and export the bootstrap kubeconfig again after the resend
+	// interval since it isn't kept
+	requests = Requests("m1", progress, "hub1", "hub2", startedAt.Add(time.Minute), time.Minute)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "hub2", requests[0].LeafHubName)
+	assert.Equal(t, spec.MigrationStageRollback, requests[0].Migration.Stage)
+	requests = Requests("m1", progress, "hub1", "hub2", startedAt.Add(2*time.Minute), time.Minute)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "hub2", requests[0].LeafHubName)
+	assert.Equal(t, spec.MigrationStagePrepare, requests[0].Migration.Stage)
+	assert.Equal(t, "cluster1", requests[0].Migration.Clusters[0].Name)
+	changed, forwards = ApplyStatus(progress, "hub2", "hub1", "hub2", prepared, startedAt.Add(2*time.Minute))
+	assert.True(t, changed)
+	require.Len(t, forwards, 1, "the resent bootstrap kubeconfig is forwarded again")
+	assert.Equal(t, spec.MigrationStageDeploy, forwards[0].Migration.Stage)
+
+	ApplyStatus(progress, "hub1", "hub1", "hub2", &spec.MigrationStatus{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStageDeploy,
+		Clusters:    []*spec.MigrationClusterStatus{{Name: "cluster1"}},
+	}, startedAt.Add(2*time.Minute))
+	assert.Equal(t, ClusterPhaseRegistering, progress[0].Phase)
+	ApplyStatus(progress, "hub2", "hub1", "hub2", &spec.MigrationStatus{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStageRollback,
+		Clusters:    []*spec.MigrationClusterStatus{{Name: "cluster2"}},
+	}, startedAt.Add(2*time.Minute))
+	assert.Equal(t, ClusterPhaseRolledBack, progress[1].Phase)
+
+	phase, message := Evaluate(progress, "hub1", "hub2", startedAt, timeout, startedAt.Add(3*time.Minute))
+	assert.Equal(t, PhaseRunning, phase)
+	assert.Equal(t, "0 of 2 clusters are migrated", message)
+
+	// the registration is requested again until the cluster joins the target hub
+	registering := &spec.MigrationStatus{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStageRegister,
+		Clusters:    []*spec.MigrationClusterStatus{{Name: "cluster1", Error: "the cluster isn't available"}},
+	}
+	ApplyStatus(progress, "hub2", "hub1", "hub2", registering, startedAt.Add(3*time.Minute))
+	assert.Equal(t, ClusterPhaseRegistering, progress[0].Phase)
+	registering.Clusters[0].Error = ""
+	ApplyStatus(progress, "hub2", "hub1", "hub2", registering, startedAt.Add(3*time.Minute))
+	assert.Equal(t, ClusterPhaseDetaching, progress[0].Phase)
+	ApplyStatus(progress, "hub1", "hub1", "hub2", &spec.MigrationStatus{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStageDetach,
+		Clusters:    []*spec.MigrationClusterStatus{{Name: "cluster1"}},
+	}, startedAt.Add(4*time.Minute))
+	assert.Equal(t, ClusterPhaseCompleted, progress[0].Phase)
+
+	phase, message = Evaluate(progress, "hub1", "hub2", startedAt, timeout, startedAt.Add(5*time.Minute))
+	assert.Equal(t, PhaseRolledBack, phase)
+	assert.Equal(t, "1 of 2 clusters are migrated, 1 are rolled back and 0 are failed", message)
+}
+
+func TestMigrationTimeout(t *testing.T) {
+	startedAt := time.Now()
+	timeout := 30 * time.Minute
+	progress := []ClusterProgress{
+		{Name: "cluster1", Phase: ClusterPhasePreparing},
+		{Name: "cluster2", Phase: ClusterPhaseRegistering, Deployed: true},
+		{Name: "cluster3", Phase: ClusterPhaseDetaching, Deployed: true},
+		{Name: "cluster4", Phase: ClusterPhaseExporting},
+	}
+
+	now := startedAt.Add(31 * time.Minute)
+	phase, _ := Evaluate(progress, "hub1", "hub2", startedAt, timeout, now)
+	assert.Equal(t, PhaseRunning, phase)
+	assert.Equal(t, ClusterPhaseRollingBack, progress[0].Phase)
+	assert.Equal(t, []string{"hub2"}, progress[0].RollbackHubs)
+	assert.Equal(t, ClusterPhaseRollingBack, progress[1].Phase)
+	assert.Equal(t, []string{"hub1", "hub2"}, progress[1].RollbackHubs)
+	assert.Equal(t, ClusterPhaseDetaching, progress[2].Phase, "the joined cluster isn't rolled back")
+	assert.Equal(t, ClusterPhaseRolledBack, progress[3].Phase, "the exported cluster isn't changed on the hubs")
+
+	// the deployed cluster is restored by the source hub first, then by the target hub with the bootstrap
+	// kubeconfig of the source hub, since the cluster might have switched to the target hub
+	requests := Requests("m1", progress, "hub1", "hub2", now, time.Minute)
+	require.Len(t, requests, 3)
+	assert.Equal(t, "hub1", requests[0].LeafHubName)
+	assert.Equal(t, spec.MigrationStageDetach, requests[0].Migration.Stage)
+	assert.Equal(t, "hub1", requests[1].LeafHubName)
+	assert.Equal(t, spec.MigrationStageRollback, requests[1].Migration.Stage)
+	assert.Equal(t, "cluster2", requests[1].Migration.Clusters[0].Name)
+	assert.Equal(t, "hub2", requests[2].LeafHubName)
+	assert.Equal(t, "cluster1", requests[2].Migration.Clusters[0].Name)
+
+	restored := &spec.MigrationStatus{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStageRollback,
+		Clusters:    []*spec.MigrationClusterStatus{{Name: "cluster2", BootstrapKubeconfig: []byte("source")}},
+	}
+	_, forwards := ApplyStatus(progress, "hub1", "hub1", "hub2", restored, now)
+	require.Len(t, forwards, 1)
+	assert.Equal(t, "hub2", forwards[0].LeafHubName)
+	assert.Equal(t, spec.MigrationStageRollback, forwards[0].Migration.Stage)
+	assert.Equal(t, "source", string(forwards[0].Migration.Clusters[0].BootstrapKubeconfig))
+	assert.Equal(t, ClusterPhaseRollingBack, progress[1].Phase)
+	assert.Equal(t, []string{"hub2"}, progress[1].RollbackHubs)
+
+	// the source hub is requested again until the target hub rolls back the cluster
+	requests = Requests("m1", progress, "hub1", "hub2", now.Add(time.Minute), time.Minute)
+	require.Len(t, requests, 3)
+	assert.Equal(t, "hub1", requests[1].LeafHubName)
+	assert.Equal(t, spec.MigrationStageRollback, requests[1].Migration.Stage)
+	changed, forwards := ApplyStatus(progress, "hub1", "hub1", "hub2", restored, now.Add(time.Minute))
+	assert.True(t, changed)
+	assert.Len(t, forwards, 1, "the resent result of the source hub is forwarded again")
+	ApplyStatus(progress, "hub2", "hub1", "hub2", &spec.MigrationStatus{
+		MigrationID: "m1",
+		Stage:       spec.MigrationStageRollback,
+		Clusters:    []*spec.MigrationClusterStatus{{Name: "cluster2"}},
+	}, now.Add(time.Minute))
+	assert.Equal(t, ClusterPhaseRolledBack, progress[1].Phase)
+
+	phase, message := Evaluate(progress, "hub1", "hub2", startedAt, timeout, startedAt.Add(61*time.Minute))
+	assert.Equal(t, PhaseFailed, phase)
+	assert.Equal(t, "0 of 4 clusters are migrated, 2 are rolled back and 2 are failed", message)
+	assert.Equal(t, ClusterPhaseFailed, progress[0].Phase)
+	assert.Equal(t, ClusterPhaseFailed, progress[2].Phase)
+}
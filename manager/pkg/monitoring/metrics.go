@@ -15,7 +15,64 @@ var GlobalHubCronJobGaugeVec = prometheus.NewGaugeVec(
 	},
 )
 
+var TransportConsumerLagGaugeVec = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "multicluster_global_hub_transport_consumer_lag",
+		Help: "The number of messages between the committed position of the manager and the high-water mark.",
+	},
+	[]string{
+		"topic",
+		"partition",
+	},
+)
+
+var TransportHubLastReceivedGaugeVec = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "multicluster_global_hub_transport_hub_last_received_timestamp_seconds",
+		Help: "The unix time the manager received the last message from the managed hub.",
+	},
+	[]string{
+		"hub",
+	},
+)
+
+var TransportHubDelayGaugeVec = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "multicluster_global_hub_transport_hub_delay_seconds",
+		Help: "The duration between the managed hub sent the last received message and the manager received it.",
+	},
+	[]string{
+		"hub",
+	},
+)
+
+var NotificationDeliveriesCounterVec = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "multicluster_global_hub_notification_deliveries_total",
+		Help: "The number of the events delivered to the webhook endpoints.",
+	},
+	[]string{
+		"endpoint",
+		"result", // success or failure
+	},
+)
+
+var NotificationDroppedEventsCounterVec = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "multicluster_global_hub_notification_dropped_events_total",
+		Help: "The number of the events dropped since the notification queue is full.",
+	},
+	[]string{
+		"endpoint", // empty if the event is dropped before it's dispatched to the endpoints
+	},
+)
+
 // RegisterMetrics will register metrics with the global prometheus registry
 func RegisterMetrics() {
 	metrics.Registry.MustRegister(GlobalHubCronJobGaugeVec)
+	metrics.Registry.MustRegister(TransportConsumerLagGaugeVec)
+	metrics.Registry.MustRegister(TransportHubLastReceivedGaugeVec)
+	metrics.Registry.MustRegister(TransportHubDelayGaugeVec)
+	metrics.Registry.MustRegister(NotificationDeliveriesCounterVec)
+	metrics.Registry.MustRegister(NotificationDroppedEventsCounterVec)
 }
@@ -0,0 +1,173 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package auditlogs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
+)
+
+const (
+	serverInternalErrorMsg = "internal error"
+	defaultListLimit       = 100
+	maxListLimit           = 1000
+)
+
+// AuditLogList is a page of the audit logs, the latest first
+type AuditLogList struct {
+	Items    []AuditLog `json:"items"`
+	Continue string     `json:"continue,omitempty"`
+}
+
+// AuditLog is a mutation of the fleet configuration made through the global hub
+type AuditLog struct {
+	ID          string          `json:"id"`
+	Actor       string          `json:"actor"`
+	ActorGroups json.RawMessage `json:"actorGroups,omitempty"`
+	// api or kubernetes
+	Source          string `json:"source"`
+	Action          string `json:"action"`
+	TargetKind      string `json:"targetKind"`
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	TargetName      string `json:"targetName"`
+	TargetID        string `json:"targetID,omitempty"`
+	// the changed fields of the target before and after the mutation
+	Diff      json.RawMessage `json:"diff,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// ListAuditLogs godoc
+// @summary list audit logs
+// @description list the audit logs of the mutations made through the API and of the global resources, the latest
+// @description first
+// @accept json
+// @produce json
+// @param        actor        query    string    false    "The user who made the mutations"
+// @param        action       query    string    false    "The action, e.g. create, update, delete, patch-labels or adopt"
+// @param        kind         query    string    false    "The kind of the target, e.g. Policy or ManagedCluster"
+// @param        namespace    query    string    false    "The namespace of the target"
+// @param        name         query    string    false    "The name of the target"
+// @param        since        query    string    false    "The RFC 3339 time after which the mutations are made"
+// @param        until        query    string    false    "The RFC 3339 time before which the mutations are made"
+// @param        limit        query    int       false    "Maximum number of audit logs, default 100 and at most 1000"
+// @param        continue     query    string    false    "Continue token to request the next page"
+// @success      200  {object}  AuditLogList
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /auditlogs [get]
+func ListAuditLogs() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		limit := defaultListLimit
+		if value := ginCtx.Query("limit"); value != "" {
+			var err error
+			if limit, err = strconv.Atoi(value); err != nil || limit <= 0 || limit > maxListLimit {
+				ginCtx.String(http.StatusBadRequest, "invalid limit: %s, the limit must be in 1-%d", value,
+					maxListLimit)
+				return
+			}
+		}
+
+		db := database.GetReadGorm().Model(&models.AuditLog{})
+		for param, column := range map[string]string{
+			"actor":     "actor",
+			"action":    "action",
+			"kind":      "target_kind",
+			"namespace": "target_namespace",
+			"name":      "target_name",
+		} {
+			if value := ginCtx.Query(param); value != "" {
+				db = db.Where(column+" = ?", value)
+			}
+		}
+		for param, operator := range map[string]string{"since": ">=", "until": "<"} {
+			value := ginCtx.Query(param)
+			if value == "" {
+				continue
+			}
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				ginCtx.String(http.StatusBadRequest, "invalid %s: %s, the time must be in RFC 3339", param, value)
+				return
+			}
+			db = db.Where("created_at "+operator+" ?", parsed.UTC().Format(time.RFC3339Nano))
+		}
+		if value := ginCtx.Query("continue"); value != "" {
+			lastCreatedAt, lastID, err := util.DecodeContinue(value)
+			if err == nil {
+				_, err = uuid.Parse(lastID)
+			}
+			if err == nil {
+				_, err = time.Parse(time.RFC3339Nano, lastCreatedAt)
+			}
+			if err != nil {
+				ginCtx.String(http.StatusBadRequest, "invalid continue token")
+				return
+			}
+			db = db.Where("(created_at, id) < (?::timestamp, ?)", lastCreatedAt, lastID)
+		}
+
+		// one more audit log is queried to know whether there is a next page
+		var auditLogs []models.AuditLog
+		if err := db.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&auditLogs).Error; err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in querying audit logs: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+
+		result := &AuditLogList{Items: make([]AuditLog, 0, len(auditLogs))}
+		if len(auditLogs) > limit {
+			auditLogs = auditLogs[:limit]
+			last := auditLogs[limit-1]
+			var err error
+			if result.Continue, err = util.EncodeContinue(last.CreatedAt.Format(time.RFC3339Nano),
+				last.ID); err != nil {
+				fmt.Fprintf(gin.DefaultWriter, "error in encoding the continue token: %v\n", err)
+				ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+				return
+			}
+		}
+		for i := range auditLogs {
+			// the encrypted fields are restored since the API is only served to the authenticated users
+			diff, err := sensitive.Decrypt(auditLogs[i].Diff)
+			if err != nil {
+				fmt.Fprintf(gin.DefaultWriter, "error in decrypting the audit log %s: %v\n", auditLogs[i].ID, err)
+				ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+				return
+			}
+			result.Items = append(result.Items, toAuditLog(&auditLogs[i], diff))
+		}
+		ginCtx.JSON(http.StatusOK, result)
+	}
+}
+
+func toAuditLog(auditLog *models.AuditLog, diff []byte) AuditLog {
+	return AuditLog{
+		ID:              auditLog.ID,
+		Actor:           auditLog.Actor,
+		ActorGroups:     json.RawMessage(auditLog.ActorGroups),
+		Source:          auditLog.Source,
+		Action:          auditLog.Action,
+		TargetKind:      auditLog.TargetKind,
+		TargetNamespace: auditLog.TargetNamespace,
+		TargetName:      auditLog.TargetName,
+		TargetID:        auditLog.TargetID,
+		Diff:            diff,
+		CreatedAt:       auditLog.CreatedAt,
+	}
+}
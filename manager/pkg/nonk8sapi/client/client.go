@@ -0,0 +1,266 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+// Package client is the Go client of the multicluster global hub REST API served by the manager, see the
+// manager/pkg/nonk8sapi/swagger.yaml for the API description.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	appsv1 "open-cluster-management.io/multicloud-operators-subscription/pkg/apis/apps/v1"
+)
+
+// DefaultBasePath is the base path of the API, it's appended to the host without a path.
+const DefaultBasePath = "/global-hub-api/v1"
+
+const defaultTimeout = 30 * time.Second
+
+// Config holds the settings to connect to the global hub API server.
+type Config struct {
+	// Host is the URL of the API server, e.g. https://multicluster-global-hub-manager-open-cluster-management.apps.x.
+	// The DefaultBasePath is used if the URL has no path.
+	Host string
+	// BearerToken is the access token of the user, it's sent in the Authorization header.
+	BearerToken string
+	// CAData is the PEM encoded bundle to verify the certificate of the server.
+	CAData []byte
+	// Insecure skips the verification of the certificate of the server.
+	Insecure bool
+	// Timeout is the timeout of the requests except the watch requests, the default is 30 seconds.
+	Timeout time.Duration
+	// HTTPClient replaces the client built from the settings above, e.g. to use a custom transport.
+	HTTPClient *http.Client
+}
+
+// Client calls the global hub API.
+type Client struct {
+	baseURL    *url.URL
+	token      string
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+// ListOptions are the query parameters of the list and watch requests.
+type ListOptions struct {
+	// LabelSelector selects the resources by the labels, e.g. "env in (dev,qa),!deprecated".
+	LabelSelector string
+	// FieldSelector selects the resources by the supported fields, e.g. "metadata.namespace=default".
+	FieldSelector string
+	// Limit is the maximum number of the resources in a page, the server returns all of them if it's 0.
+	Limit int
+	// Continue is the token returned by the previous page to get the next page.
+	Continue string
+}
+
+// LabelPatch adds the labels of Set and removes the labels of Remove.
+type LabelPatch struct {
+	Set    map[string]string
+	Remove []string
+}
+
+// NewClient creates the client with the config.
+func NewClient(config *Config) (*Client, error) {
+	if config.Host == "" {
+		return nil, errors.New("the host of the global hub API is required")
+	}
+	baseURL, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %s: %w", config.Host, err)
+	}
+	if baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid host %s: the scheme and host are required", config.Host)
+	}
+	if strings.TrimSuffix(baseURL.Path, "/") == "" {
+		baseURL.Path = DefaultBasePath
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		// #nosec G402 -- the verification is skipped only if it's required explicitly
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: config.Insecure, // nolint:gosec
+		}
+		if len(config.CAData) > 0 {
+			rootCAs := x509.NewCertPool()
+			if ok := rootCAs.AppendCertsFromPEM(config.CAData); !ok {
+				return nil, errors.New("failed to append the CA bundle")
+			}
+			transport.TLSClientConfig.RootCAs = rootCAs
+		}
+		// the timeout is set on the requests rather than the client, so that it doesn't break the watch requests
+		httpClient = &http.Client{Transport: transport}
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		token:      config.BearerToken,
+		timeout:    timeout,
+		httpClient: httpClient,
+	}, nil
+}
+
+// ListManagedClusters lists a page of the managed clusters.
+func (c *Client) ListManagedClusters(ctx context.Context, options ListOptions,
+) (*clusterv1.ManagedClusterList, error) {
+	list := &clusterv1.ManagedClusterList{}
+	if err := c.get(ctx, "/managedclusters", options.query(), list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListPolicies lists a page of the policies, the compliance of the clusters is in the status of the policies.
+func (c *Client) ListPolicies(ctx context.Context, options ListOptions) (*policyv1.PolicyList, error) {
+	list := &policyv1.PolicyList{}
+	if err := c.get(ctx, "/policies", options.query(), list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListSubscriptions lists a page of the application subscriptions.
+func (c *Client) ListSubscriptions(ctx context.Context, options ListOptions) (*appsv1.SubscriptionList, error) {
+	list := &appsv1.SubscriptionList{}
+	if err := c.get(ctx, "/subscriptions", options.query(), list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetPolicyStatus gets the policy with the compliance of the clusters by the policy ID.
+func (c *Client) GetPolicyStatus(ctx context.Context, policyID string) (*policyv1.Policy, error) {
+	policy := &policyv1.Policy{}
+	if err := c.get(ctx, "/policy/"+url.PathEscape(policyID)+"/status", nil, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// PatchManagedClusterLabels updates the labels of the managed cluster by the cluster ID, the labels are
+// propagated to the managed cluster on the managed hub.
+func (c *Client) PatchManagedClusterLabels(ctx context.Context, clusterID string, patch LabelPatch) error {
+	type jsonPatch struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value string `json:"value,omitempty"`
+	}
+	patches := []jsonPatch{}
+	for key, value := range patch.Set {
+		patches = append(patches, jsonPatch{Op: "add", Path: labelPath(key), Value: value})
+	}
+	for _, key := range patch.Remove {
+		patches = append(patches, jsonPatch{Op: "remove", Path: labelPath(key)})
+	}
+	if len(patches) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(patches)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	resp, err := c.do(ctx, http.MethodPatch, "/managedcluster/"+url.PathEscape(clusterID), nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// labelPath escapes the label key as a JSON pointer, see https://datatracker.ietf.org/doc/html/rfc6901
+func labelPath(key string) string {
+	return "/metadata/labels/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func (o ListOptions) query() url.Values {
+	query := url.Values{}
+	if o.LabelSelector != "" {
+		query.Set("labelSelector", o.LabelSelector)
+	}
+	if o.FieldSelector != "" {
+		query.Set("fieldSelector", o.FieldSelector)
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Continue != "" {
+		query.Set("continue", o.Continue)
+	}
+	return query
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode the response of %s: %w", path, err)
+	}
+	return nil
+}
+
+// do sends the request, and returns the StatusError if the response isn't successful. the caller closes the body
+// of the returned response.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body []byte,
+) (*http.Response, error) {
+	requestURL := *c.baseURL
+	requestURL.Path = strings.TrimSuffix(c.baseURL.Path, "/") + path
+	requestURL.RawQuery = query.Encode()
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		return nil, newStatusError(method, path, resp)
+	}
+	return resp, nil
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/watch"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/client"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/test/pkg/testapiserver"
+)
+
+const clusterFormat = `{
+	"kind": "ManagedCluster",
+	"apiVersion": "cluster.open-cluster-management.io/v1",
+	"metadata": {
+		"uid": "%s",
+		"name": "%s",
+		"labels": {"env": "%s"},
+		"annotations": {"global-hub.open-cluster-management.io/managed-by": "%s"}
+	},
+	"spec": {"hubAcceptsClient": true}
+}`
+
+var _ = Describe("Global hub API client", Ordered, func() {
+	var c *client.Client
+	var ctx context.Context
+	var cancel context.CancelFunc
+	clusterIDs := []string{
+		"2aa5547c-c172-47ed-b70b-db468c84d327",
+		"18c9e13c-4488-4dcd-a5ac-1196093abbc0",
+		"5e2ae8b8-ea5c-4c43-a1d6-55b2e5b3f3a1",
+	}
+	policyID := "d9347b09-bb46-4e2b-91ea-513e83ab9ea7"
+
+	BeforeAll(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+
+		var err error
+		c, err = client.NewClient(&client.Config{Host: testAPIServer.URL, BearerToken: testapiserver.Token})
+		Expect(err).NotTo(HaveOccurred())
+
+		db := database.GetGorm()
+		for i, clusterID := range clusterIDs {
+			env := "dev"
+			if i == 0 {
+				env = "production"
+			}
+			err := db.Exec(`INSERT INTO status.managed_clusters (cluster_id,leaf_hub_name,payload,error)
+				VALUES (?, 'hub1', ?, 'none')`, clusterID,
+				fmt.Sprintf(clusterFormat, clusterID, fmt.Sprintf("mc%d", i+1), env, "hub1")).Error
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		err = db.Create(&models.SpecPolicy{
+			ID: policyID,
+			Payload: []byte(`{
+				"apiVersion": "policy.open-cluster-management.io/v1",
+				"kind": "Policy",
+				"metadata": {"name": "policy1", "namespace": "default", "uid": "` + policyID + `"},
+				"spec": {"disabled": false, "remediationAction": "inform", "policy-templates": []}
+			}`),
+		}).Error
+		Expect(err).NotTo(HaveOccurred())
+		err = db.Exec(`INSERT INTO status.compliance (policy_id,cluster_name,leaf_hub_name,error,compliance)
+			VALUES (?,'mc1','hub1','none','non_compliant'), (?,'mc2','hub1','none','compliant')`,
+			policyID, policyID).Error
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterAll(func() {
+		cancel()
+	})
+
+	It("should list the managed clusters page by page", func() {
+		list, err := c.ListManagedClusters(ctx, client.ListOptions{Limit: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(2))
+		Expect(list.Continue).NotTo(BeEmpty())
+
+		list, err = c.ListManagedClusters(ctx, client.ListOptions{Limit: 2, Continue: list.Continue})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Name).To(Equal("mc3"))
+		Expect(list.Continue).To(BeEmpty())
+
+		clusters, err := c.ManagedClusters(client.ListOptions{Limit: 1}).All(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		names := []string{}
+		for _, cluster := range clusters {
+			names = append(names, cluster.Name)
+		}
+		Expect(names).To(Equal([]string{"mc1", "mc2", "mc3"}))
+	})
+
+	It("should list the managed clusters with the selectors", func() {
+		iterator := c.ManagedClusters(client.ListOptions{
+			LabelSelector: "env notin (production)",
+			FieldSelector: "leafHubName=hub1",
+		})
+		names := []string{}
+		for iterator.Next(ctx) {
+			names = append(names, iterator.Item().Name)
+		}
+		Expect(iterator.Err()).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"mc2", "mc3"}))
+	})
+
+	It("should return the typed errors", func() {
+		_, err := c.ListManagedClusters(ctx, client.ListOptions{FieldSelector: "spec.hubAcceptsClient=true"})
+		Expect(client.IsBadRequest(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		unauthorized, err := client.NewClient(&client.Config{Host: testAPIServer.URL, BearerToken: "invalid"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = unauthorized.ListPolicies(ctx, client.ListOptions{})
+		Expect(client.IsUnauthorized(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		_, err = c.GetPolicyStatus(ctx, "4e8a3e63-5e12-44fc-b1aa-3ac5d3a1e5d1")
+		Expect(client.IsNotFound(err)).To(BeTrue(), "unexpected error: %v", err)
+
+		err = c.PatchManagedClusterLabels(ctx, "4e8a3e63-5e12-44fc-b1aa-3ac5d3a1e5d1",
+			client.LabelPatch{Set: map[string]string{"foo": "bar"}})
+		Expect(client.IsNotFound(err)).To(BeTrue(), "unexpected error: %v", err)
+	})
+
+	It("should list the policies with the compliance", func() {
+		policies, err := c.Policies(client.ListOptions{FieldSelector: "metadata.namespace=default"}).All(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policies).To(HaveLen(1))
+		Expect(policies[0].Status.ComplianceState).To(Equal(policyv1.NonCompliant))
+		Expect(policies[0].Status.Status).To(HaveLen(2))
+
+		policy, err := c.GetPolicyStatus(ctx, policyID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.Status.Status[0].ClusterName).To(Equal("mc1"))
+		Expect(policy.Status.Status[0].ComplianceState).To(Equal(policyv1.NonCompliant))
+	})
+
+	It("should patch the labels of the managed cluster", func() {
+		err := c.PatchManagedClusterLabels(ctx, clusterIDs[0], client.LabelPatch{
+			Set:    map[string]string{"app.kubernetes.io/name": "foo"},
+			Remove: []string{"env"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		label := &models.ManagedClusterLabel{}
+		err = database.GetGorm().Where(&models.ManagedClusterLabel{ID: clusterIDs[0]}).First(label).Error
+		Expect(err).NotTo(HaveOccurred())
+		labels := map[string]string{}
+		Expect(json.Unmarshal(label.Labels, &labels)).To(Succeed())
+		Expect(labels).To(Equal(map[string]string{"app.kubernetes.io/name": "foo"}))
+		removed := []string{}
+		Expect(json.Unmarshal(label.DeletedLabelKeys, &removed)).To(Succeed())
+		Expect(removed).To(Equal([]string{"env"}))
+	})
+
+	It("should watch the managed clusters", func() {
+		watcher, err := c.WatchManagedClusters(ctx, client.ListOptions{LabelSelector: "env=dev"})
+		Expect(err).NotTo(HaveOccurred())
+		defer watcher.Stop()
+
+		added := map[string]bool{}
+		Eventually(func() map[string]bool {
+			select {
+			case event := <-watcher.ResultChan():
+				Expect(event.Type).To(Equal(watch.Added))
+				added[event.Object.(*clusterv1.ManagedCluster).Name] = true
+			default:
+			}
+			return added
+		}, 20*time.Second, 100*time.Millisecond).Should(Equal(map[string]bool{"mc2": true, "mc3": true}))
+
+		By("delete the cluster mc3")
+		err = database.GetGorm().Exec(`UPDATE status.managed_clusters SET deleted_at = now()
+			WHERE cluster_id = ?`, clusterIDs[2]).Error
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() bool {
+			select {
+			case event := <-watcher.ResultChan():
+				return event.Type == watch.Deleted && event.Object.(*clusterv1.ManagedCluster).Name == "mc3"
+			default:
+				return false
+			}
+		}, 20*time.Second, 100*time.Millisecond).Should(BeTrue())
+	})
+})
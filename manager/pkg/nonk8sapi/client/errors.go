@@ -0,0 +1,71 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorMessageLength limits the length of the response body kept in the StatusError
+const maxErrorMessageLength = 1024
+
+// StatusError is returned when the server responds with an unsuccessful status code.
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// Message is the body of the response, e.g. the reason of the bad request.
+	Message string
+}
+
+func newStatusError(method, path string, resp *http.Response) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorMessageLength))
+	return &StatusError{
+		Method:     method,
+		Path:       path,
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+}
+
+func (e *StatusError) Error() string {
+	message := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	return message
+}
+
+// StatusCode returns the status code of the StatusError, or 0 if the error isn't a StatusError.
+func StatusCode(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
+
+// IsBadRequest returns true if the request is rejected, e.g. the selector is invalid.
+func IsBadRequest(err error) bool {
+	return StatusCode(err) == http.StatusBadRequest
+}
+
+// IsUnauthorized returns true if the token is missing or invalid.
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden returns true if the user isn't allowed to access the resource.
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+// IsNotFound returns true if the resource doesn't exist.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
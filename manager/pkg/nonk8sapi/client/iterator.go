@@ -0,0 +1,128 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	appsv1 "open-cluster-management.io/multicloud-operators-subscription/pkg/apis/apps/v1"
+)
+
+// DefaultPageSize is the number of the resources requested in a page by the iterators if the limit isn't set.
+const DefaultPageSize = 100
+
+// listPageFunc lists a page of the resources, and returns the continue token of the next page.
+type listPageFunc[T any] func(ctx context.Context, options ListOptions) ([]T, string, error)
+
+// Iterator walks through all the resources page by page with the continue token, e.g.
+//
+//	iterator := c.ManagedClusters(client.ListOptions{LabelSelector: "env=dev"})
+//	for iterator.Next(ctx) {
+//		cluster := iterator.Item()
+//	}
+//	if err := iterator.Err(); err != nil {
+//	}
+type Iterator[T any] struct {
+	listPage listPageFunc[T]
+	options  ListOptions
+	items    []T
+	index    int
+	lastPage bool
+	err      error
+}
+
+func newIterator[T any](options ListOptions, listPage listPageFunc[T]) *Iterator[T] {
+	if options.Limit <= 0 {
+		options.Limit = DefaultPageSize
+	}
+	return &Iterator[T]{listPage: listPage, options: options, index: -1}
+}
+
+// Next advances to the next resource, the next page is requested once the current page is consumed. it returns
+// false when all the resources are visited or an error occurs.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	it.index++
+	for it.index >= len(it.items) {
+		if it.lastPage {
+			return false
+		}
+		items, continueToken, err := it.listPage(ctx, it.options)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		// the same token means the server doesn't move forward, stop here rather than requesting it forever
+		if continueToken != "" && continueToken == it.options.Continue {
+			it.err = fmt.Errorf("the server returned the same continue token %s", continueToken)
+			return false
+		}
+		it.items, it.index = items, 0
+		it.options.Continue = continueToken
+		it.lastPage = continueToken == ""
+	}
+	return true
+}
+
+// Item returns the current resource, it's valid after Next returns true.
+func (it *Iterator[T]) Item() *T {
+	if it.index < 0 || it.index >= len(it.items) {
+		return nil
+	}
+	return &it.items[it.index]
+}
+
+// Err returns the error which stops the iteration.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All visits the remaining resources and returns them.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	items := []T{}
+	for it.Next(ctx) {
+		items = append(items, *it.Item())
+	}
+	return items, it.Err()
+}
+
+// ManagedClusters iterates all the managed clusters matching the options.
+func (c *Client) ManagedClusters(options ListOptions) *Iterator[clusterv1.ManagedCluster] {
+	return newIterator(options, func(ctx context.Context, options ListOptions,
+	) ([]clusterv1.ManagedCluster, string, error) {
+		list, err := c.ListManagedClusters(ctx, options)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+}
+
+// Policies iterates all the policies matching the options.
+func (c *Client) Policies(options ListOptions) *Iterator[policyv1.Policy] {
+	return newIterator(options, func(ctx context.Context, options ListOptions) ([]policyv1.Policy, string, error) {
+		list, err := c.ListPolicies(ctx, options)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+}
+
+// Subscriptions iterates all the application subscriptions matching the options.
+func (c *Client) Subscriptions(options ListOptions) *Iterator[appsv1.Subscription] {
+	return newIterator(options, func(ctx context.Context, options ListOptions,
+	) ([]appsv1.Subscription, string, error) {
+		list, err := c.ListSubscriptions(ctx, options)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	appsv1 "open-cluster-management.io/multicloud-operators-subscription/pkg/apis/apps/v1"
+)
+
+// WatchManagedClusters watches the managed clusters. the server sends the ADDED events of all the matched clusters
+// periodically and the DELETED events of the removed ones, the objects of the events are *clusterv1.ManagedCluster.
+func (c *Client) WatchManagedClusters(ctx context.Context, options ListOptions) (watch.Interface, error) {
+	return c.watch(ctx, "/managedclusters", options, func() runtime.Object { return &clusterv1.ManagedCluster{} })
+}
+
+// WatchPolicies watches the policies, the objects of the events are *policyv1.Policy.
+func (c *Client) WatchPolicies(ctx context.Context, options ListOptions) (watch.Interface, error) {
+	return c.watch(ctx, "/policies", options, func() runtime.Object { return &policyv1.Policy{} })
+}
+
+// WatchSubscriptions watches the application subscriptions, the objects of the events are *appsv1.Subscription.
+func (c *Client) WatchSubscriptions(ctx context.Context, options ListOptions) (watch.Interface, error) {
+	return c.watch(ctx, "/subscriptions", options, func() runtime.Object { return &appsv1.Subscription{} })
+}
+
+// watch requests the resources with the watch parameter, the stream is closed once the watcher is stopped or
+// the context is done.
+func (c *Client) watch(ctx context.Context, path string, options ListOptions, newObject func() runtime.Object,
+) (watch.Interface, error) {
+	query := options.query()
+	query.Set("watch", "")
+	resp, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return watch.NewStreamWatcher(&watchDecoder{
+		body:      resp.Body,
+		decoder:   json.NewDecoder(resp.Body),
+		newObject: newObject,
+	}, &errorReporter{}), nil
+}
+
+// watchDecoder decodes the stream of the metav1.WatchEvent written by the server.
+type watchDecoder struct {
+	body      io.ReadCloser
+	decoder   *json.Decoder
+	newObject func() runtime.Object
+}
+
+func (d *watchDecoder) Decode() (watch.EventType, runtime.Object, error) {
+	event := &metav1.WatchEvent{}
+	if err := d.decoder.Decode(event); err != nil {
+		return "", nil, err
+	}
+	object := d.newObject()
+	if err := json.Unmarshal(event.Object.Raw, object); err != nil {
+		return "", nil, fmt.Errorf("failed to decode the object of the %s event: %w", event.Type, err)
+	}
+	return watch.EventType(event.Type), object, nil
+}
+
+func (d *watchDecoder) Close() {
+	_ = d.body.Close()
+}
+
+// errorReporter reports the decoding error as the status of the ERROR event.
+type errorReporter struct{}
+
+func (r *errorReporter) AsObject(err error) runtime.Object {
+	return &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Message: err.Error(),
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package gitopsapplications
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+const (
+	serverInternalErrorMsg = "internal error"
+	defaultListLimit       = 100
+	maxListLimit           = 1000
+)
+
+// GitOpsApplicationList is a page of the applications ordered by the hub, the namespace and the name
+type GitOpsApplicationList struct {
+	Items    []GitOpsApplication `json:"items"`
+	Continue string              `json:"continue,omitempty"`
+}
+
+// GitOpsApplication is the status summary of an argo cd application on a managed hub
+type GitOpsApplication struct {
+	LeafHubName          string    `json:"leafHubName"`
+	Namespace            string    `json:"namespace"`
+	Name                 string    `json:"name"`
+	Project              string    `json:"project"`
+	Health               string    `json:"health"`
+	SyncStatus           string    `json:"syncStatus"`
+	Revision             string    `json:"revision"`
+	DestinationServer    string    `json:"destinationServer,omitempty"`
+	DestinationName      string    `json:"destinationName,omitempty"`
+	DestinationNamespace string    `json:"destinationNamespace,omitempty"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+}
+
+// ListGitOpsApplications godoc
+// @summary list argo cd applications
+// @description list the argo cd applications of all the managed hubs
+// @accept json
+// @produce json
+// @param        hub         query     string  false  "list the applications of the managed hub"
+// @param        namespace   query     string  false  "list the applications in the namespace"
+// @param        health      query     string  false  "list the applications with the health status, e.g. Degraded"
+// @param        sync        query     string  false  "list the applications with the sync status, e.g. OutOfSync"
+// @param        limit       query     int     false  "maximum number of applications, default 100 and at most 1000"
+// @param        continue    query     string  false  "continue token to request the next page"
+// @success      200  {object}    GitOpsApplicationList
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /gitopsapplications [get]
+func ListGitOpsApplications() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		limit := defaultListLimit
+		if value := ginCtx.Query("limit"); value != "" {
+			var err error
+			if limit, err = strconv.Atoi(value); err != nil || limit <= 0 || limit > maxListLimit {
+				ginCtx.String(http.StatusBadRequest, "invalid limit: %s, the limit must be in 1-%d", value,
+					maxListLimit)
+				return
+			}
+		}
+
+		db := database.GetReadGorm().Model(&models.GitOpsApplication{})
+
+		if hub := ginCtx.Query("hub"); hub != "" {
+			db = db.Where("leaf_hub_name = ?", hub)
+		}
+		if namespace := ginCtx.Query("namespace"); namespace != "" {
+			db = db.Where("namespace = ?", namespace)
+		}
+		if health := ginCtx.Query("health"); health != "" {
+			db = db.Where("health = ?", health)
+		}
+		if syncStatus := ginCtx.Query("sync"); syncStatus != "" {
+			db = db.Where("sync_status = ?", syncStatus)
+		}
+
+		if value := ginCtx.Query("continue"); value != "" {
+			// the hub and the namespace names don't contain the slash, so they're encoded as the last name
+			lastName, lastApplication, err := util.DecodeContinue(value)
+			lastHub, lastNamespace, found := strings.Cut(lastName, "/")
+			if err != nil || !found {
+				ginCtx.String(http.StatusBadRequest, "invalid continue token")
+				return
+			}
+			db = db.Where("(leaf_hub_name, namespace, name) > (?, ?, ?)", lastHub, lastNamespace, lastApplication)
+		}
+
+		// one more application is queried to know whether there is a next page
+		var applications []models.GitOpsApplication
+		err := db.Order("leaf_hub_name, namespace, name").Limit(limit + 1).Find(&applications).Error
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in quering argo cd applications: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+
+		result := &GitOpsApplicationList{Items: make([]GitOpsApplication, 0, len(applications))}
+		if len(applications) > limit {
+			applications = applications[:limit]
+			last := applications[limit-1]
+			if result.Continue, err = util.EncodeContinue(last.LeafHubName+"/"+last.Namespace,
+				last.Name); err != nil {
+				fmt.Fprintf(gin.DefaultWriter, "error in encoding the continue token: %v\n", err)
+				ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+				return
+			}
+		}
+		for _, application := range applications {
+			result.Items = append(result.Items, GitOpsApplication{
+				LeafHubName:          application.LeafHubName,
+				Namespace:            application.Namespace,
+				Name:                 application.Name,
+				Project:              application.Project,
+				Health:               application.Health,
+				SyncStatus:           application.SyncStatus,
+				Revision:             application.Revision,
+				DestinationServer:    application.DestinationServer,
+				DestinationName:      application.DestinationName,
+				DestinationNamespace: application.DestinationNamespace,
+				UpdatedAt:            application.UpdatedAt,
+			})
+		}
+		ginCtx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package managedclusteraddons
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+const (
+	serverInternalErrorMsg = "internal error"
+	defaultListLimit       = 100
+	maxListLimit           = 1000
+)
+
+// ManagedClusterAddOnList is a page of the addons ordered by the hub, the cluster and the addon name
+type ManagedClusterAddOnList struct {
+	Items    []ManagedClusterAddOn `json:"items"`
+	Continue string                `json:"continue,omitempty"`
+}
+
+// ManagedClusterAddOn is the inventory entry of an addon installed on a managed cluster
+type ManagedClusterAddOn struct {
+	LeafHubName      string `json:"leafHubName"`
+	ClusterName      string `json:"clusterName"`
+	AddOnName        string `json:"addonName"`
+	InstallNamespace string `json:"installNamespace"`
+	// HubVersion is the ACM version of the managed hub which installs the addon
+	HubVersion string          `json:"hubVersion"`
+	Available  string          `json:"available"`
+	Degraded   string          `json:"degraded"`
+	Conditions json.RawMessage `json:"conditions"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+// ListManagedClusterAddOns godoc
+// @summary list managed cluster addons
+// @description list the addons installed on the managed clusters of all the managed hubs
+// @accept json
+// @produce json
+// @param        hub         query     string  false  "list the addons of the managed hub"
+// @param        cluster     query     string  false  "list the addons of the managed cluster"
+// @param        addon       query     string  false  "list the addons with the name"
+// @param        degraded    query     bool    false  "list the addons which are not available or degraded"
+// @param        limit       query     int     false  "maximum number of addons, default 100 and at most 1000"
+// @param        continue    query     string  false  "continue token to request the next page"
+// @success      200  {object}    ManagedClusterAddOnList
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /managedclusteraddons [get]
+func ListManagedClusterAddOns() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		limit := defaultListLimit
+		if value := ginCtx.Query("limit"); value != "" {
+			var err error
+			if limit, err = strconv.Atoi(value); err != nil || limit <= 0 || limit > maxListLimit {
+				ginCtx.String(http.StatusBadRequest, "invalid limit: %s, the limit must be in 1-%d", value,
+					maxListLimit)
+				return
+			}
+		}
+
+		db := database.GetReadGorm().Model(&models.ManagedClusterAddOn{})
+
+		if hub := ginCtx.Query("hub"); hub != "" {
+			db = db.Where("leaf_hub_name = ?", hub)
+		}
+		if cluster := ginCtx.Query("cluster"); cluster != "" {
+			db = db.Where("cluster_name = ?", cluster)
+		}
+		if addOn := ginCtx.Query("addon"); addOn != "" {
+			db = db.Where("addon_name = ?", addOn)
+		}
+		if degradedQuery := ginCtx.Query("degraded"); degradedQuery != "" {
+			degraded, err := strconv.ParseBool(degradedQuery)
+			if err != nil {
+				ginCtx.String(http.StatusBadRequest, "invalid degraded: %s", degradedQuery)
+				return
+			}
+			if degraded {
+				db = db.Where("available <> ? OR degraded = ?", metav1.ConditionTrue, metav1.ConditionTrue)
+			} else {
+				db = db.Where("available = ? AND degraded <> ?", metav1.ConditionTrue, metav1.ConditionTrue)
+			}
+		}
+
+		if value := ginCtx.Query("continue"); value != "" {
+			// the hub and the cluster names don't contain the slash, so they're encoded as the last name
+			lastName, lastAddOn, err := util.DecodeContinue(value)
+			lastHub, lastCluster, found := strings.Cut(lastName, "/")
+			if err != nil || !found {
+				ginCtx.String(http.StatusBadRequest, "invalid continue token")
+				return
+			}
+			db = db.Where("(leaf_hub_name, cluster_name, addon_name) > (?, ?, ?)", lastHub, lastCluster, lastAddOn)
+		}
+
+		// one more addon is queried to know whether there is a next page
+		var addOns []models.ManagedClusterAddOn
+		err := db.Order("leaf_hub_name, cluster_name, addon_name").Limit(limit + 1).Find(&addOns).Error
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in quering managed cluster addons: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+
+		result := &ManagedClusterAddOnList{Items: make([]ManagedClusterAddOn, 0, len(addOns))}
+		if len(addOns) > limit {
+			addOns = addOns[:limit]
+			last := addOns[limit-1]
+			if result.Continue, err = util.EncodeContinue(last.LeafHubName+"/"+last.ClusterName,
+				last.AddOnName); err != nil {
+				fmt.Fprintf(gin.DefaultWriter, "error in encoding the continue token: %v\n", err)
+				ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+				return
+			}
+		}
+		for _, addOn := range addOns {
+			result.Items = append(result.Items, ManagedClusterAddOn{
+				LeafHubName:      addOn.LeafHubName,
+				ClusterName:      addOn.ClusterName,
+				AddOnName:        addOn.AddOnName,
+				InstallNamespace: addOn.InstallNamespace,
+				HubVersion:       addOn.HubVersion,
+				Available:        addOn.Available,
+				Degraded:         addOn.Degraded,
+				Conditions:       json.RawMessage(addOn.Conditions),
+				UpdatedAt:        addOn.UpdatedAt,
+			})
+		}
+		ginCtx.JSON(http.StatusOK, result)
+	}
+}
@@ -38,12 +38,23 @@ const (
 	crdName                                     = "managedclusters.cluster.open-cluster-management.io"
 )
 
+// fieldColumns are the fields supported by the field selector of the managed clusters
+var fieldColumns = util.FieldColumns{
+	"metadata.name": "cluster_name",
+	"leafHubName":   "leaf_hub_name",
+	// the status of the available condition, True, False or Unknown
+	"status.available": "COALESCE((SELECT c ->> 'status' " +
+		"FROM jsonb_array_elements(payload -> 'status' -> 'conditions') c " +
+		"WHERE c ->> 'type' = 'ManagedClusterConditionAvailable' LIMIT 1), 'Unknown')",
+}
+
 // ListManagedClusters godoc
 // @summary list managed clusters
 // @description list managed clusters
 // @accept json
 // @produce json
 // @param        labelSelector    query     string  false  "list managed clusters by label selector"
+// @param        fieldSelector    query     string  false  "list managed clusters by field selector, e.g. status.available=True"
 // @param        limit            query     int     false  "maximum managed cluster number to receive"
 // @param        continue         query     string  false  "continue token to request next request"
 // @success      200  {object}    clusterv1.ManagedClusterList
@@ -60,68 +71,45 @@ func ListManagedClusters() gin.HandlerFunc {
 		clusterv1.GroupVersion.Version)
 
 	return func(ginCtx *gin.Context) {
-		labelSelector := ginCtx.Query("labelSelector")
-
-		selectorInSql := ""
-		var err error
-
-		if labelSelector != "" {
-			selectorInSql, err = util.ParseLabelSelector(labelSelector)
-			if err != nil {
-				fmt.Fprintf(gin.DefaultWriter, "failed to parse label selector: %s\n", err.Error())
-				return
-			}
+		listOptions, err := util.ParseListOptions(ginCtx, fieldColumns)
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to parse the list options: %s\n", err.Error())
+			ginCtx.String(http.StatusBadRequest, err.Error())
+			return
 		}
 
-		fmt.Fprintf(gin.DefaultWriter, "parsed selector: %s\n", selectorInSql)
-
-		limit := ginCtx.Query("limit")
-		fmt.Fprintf(gin.DefaultWriter, "limit: %v\n", limit)
-
-		lastManagedClusterName := ""
 		lastManagedClusterUID := uuid.MustParse("00000000-0000-0000-0000-000000000000")
-
-		continueToken := ginCtx.Query("continue")
-		if continueToken != "" {
-			var lastManagedClusterUIDStr string
-			lastManagedClusterName, lastManagedClusterUIDStr, err = util.DecodeContinue(continueToken)
-			if err != nil {
-				fmt.Fprintf(gin.DefaultWriter, "failed to decode continue token: %s\n", err.Error())
-				return
-			}
-			lastManagedClusterUID, err = uuid.Parse(lastManagedClusterUIDStr)
+		if listOptions.LastUID != "" {
+			lastManagedClusterUID, err = uuid.Parse(listOptions.LastUID)
 			if err != nil {
-				fmt.Println("Error parsing UUID:", err)
+				fmt.Fprintf(gin.DefaultWriter, "failed to parse the continue token: %s\n", err.Error())
+				ginCtx.String(http.StatusBadRequest, "invalid continue token")
 				return
 			}
 		}
 
 		fmt.Fprintf(gin.DefaultWriter,
 			"last returned managed cluster name: %s, last returned managed cluster UID: %s\n",
-			lastManagedClusterName,
+			listOptions.LastName,
 			lastManagedClusterUID)
 
-		// build query condition for paging
-		LastResourceCompareCondition := fmt.Sprintf(
-			"(payload -> 'metadata' ->> 'name', cluster_id) > ('%s', '%s') ",
-			lastManagedClusterName,
-			lastManagedClusterUID)
-
-		// managed cluster list query order by name and uid with limit if set
+		// managed cluster list query order by name and uid, the paging condition is compared with the last one
 		managedClusterListQuery := "SELECT payload FROM status.managed_clusters WHERE deleted_at is NULL AND " +
-			LastResourceCompareCondition +
-			selectorInSql +
+			"(payload -> 'metadata' ->> 'name', cluster_id) > (?, ?)" +
+			listOptions.Conditions +
 			" ORDER BY (payload -> 'metadata' ->> 'name', cluster_id)"
+		args := append([]interface{}{listOptions.LastName, lastManagedClusterUID.String()}, listOptions.Args...)
 
 		// add limit
-		if limit != "" {
-			managedClusterListQuery += fmt.Sprintf(" LIMIT %s", limit)
+		if listOptions.Limit > 0 {
+			managedClusterListQuery += " LIMIT ?"
+			args = append(args, listOptions.Limit)
 		}
 
-		fmt.Fprintf(gin.DefaultWriter, "managedcluster list query: %v\n", managedClusterListQuery)
+		fmt.Fprintf(gin.DefaultWriter, "managedcluster list query: %v, args: %v\n", managedClusterListQuery, args)
 
 		if _, watch := ginCtx.GetQuery("watch"); watch {
-			handleRowsForWatch(ginCtx, managedClusterListQuery)
+			handleRowsForWatch(ginCtx, managedClusterListQuery, args)
 			return
 		}
 
@@ -129,12 +117,12 @@ func ListManagedClusters() gin.HandlerFunc {
 		lastManagedClusterQuery := "SELECT payload FROM status.managed_clusters WHERE deleted_at is NULL " +
 			"ORDER BY (payload -> 'metadata' ->> 'name', cluster_id) DESC LIMIT 1"
 
-		handleRows(ginCtx, managedClusterListQuery, lastManagedClusterQuery,
+		handleRows(ginCtx, managedClusterListQuery, args, lastManagedClusterQuery,
 			customResourceColumnDefinitions)
 	}
 }
 
-func handleRowsForWatch(ginCtx *gin.Context, managedClusterListQuery string) {
+func handleRowsForWatch(ginCtx *gin.Context, managedClusterListQuery string, args []interface{}) {
 	writer := ginCtx.Writer
 	header := writer.Header()
 	header.Set("Transfer-Encoding", "chunked")
@@ -166,19 +154,21 @@ func handleRowsForWatch(ginCtx *gin.Context, managedClusterListQuery string) {
 				return
 			}
 
-			doHandleRowsForWatch(ctx, writer, managedClusterListQuery, preAddedManagedClusterNames)
+			doHandleRowsForWatch(ctx, writer, managedClusterListQuery, args, preAddedManagedClusterNames)
 		}
 	}
 }
 
 func doHandleRowsForWatch(ctx context.Context, writer io.Writer, managedClusterListQuery string,
-	preAddedManagedClusterNames set.Set,
+	args []interface{}, preAddedManagedClusterNames set.Set,
 ) {
-	db := database.GetGorm()
-	rows, err := db.Raw(managedClusterListQuery).Rows()
+	db := database.GetReadGorm()
+	rows, err := db.Raw(managedClusterListQuery, args...).Rows()
 	if err != nil {
 		fmt.Fprintf(gin.DefaultWriter, "error in quering managed cluster list: %v\n", err)
+		return
 	}
+	defer rows.Close()
 
 	addedManagedClusterNames := set.NewSet()
 	for rows.Next() {
@@ -239,10 +229,11 @@ func doHandleRowsForWatch(ctx context.Context, writer io.Writer, managedClusterL
 	writer.(http.Flusher).Flush()
 }
 
-func handleRows(ginCtx *gin.Context, managedClusterListQuery, lastManagedClusterQuery string,
+func handleRows(ginCtx *gin.Context, managedClusterListQuery string, args []interface{},
+	lastManagedClusterQuery string,
 	customResourceColumnDefinitions []apiextensionsv1.CustomResourceColumnDefinition,
 ) {
-	db := database.GetGorm()
+	db := database.GetReadGorm()
 
 	// load the lastManaged cluster
 	lastManagedCluster := &clusterv1.ManagedCluster{}
@@ -255,7 +246,7 @@ func handleRows(ginCtx *gin.Context, managedClusterListQuery, lastManagedCluster
 		return
 	}
 	if err == nil {
-		if err := json.Unmarshal(payload, lastManagedCluster); err != nil {
+		if err := util.UnmarshalPayload(payload, lastManagedCluster); err != nil {
 			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
 			fmt.Fprintf(gin.DefaultWriter, "error to unmarshal payload to lastManagedCluster: %v\n", err)
 			return
@@ -263,10 +254,11 @@ func handleRows(ginCtx *gin.Context, managedClusterListQuery, lastManagedCluster
 	}
 
 	// get hte managed cluster list
-	rows, err := db.Raw(managedClusterListQuery).Rows()
+	rows, err := db.Raw(managedClusterListQuery, args...).Rows()
 	if err != nil {
 		ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
 		fmt.Fprintf(gin.DefaultWriter, "error in querying managed clusters: %v\n", err)
+		return
 	}
 	defer rows.Close()
 
@@ -287,7 +279,7 @@ func handleRows(ginCtx *gin.Context, managedClusterListQuery, lastManagedCluster
 			fmt.Fprintf(gin.DefaultWriter, "error in scanning a managed cluster: %v\n", err)
 			continue
 		}
-		err = json.Unmarshal(payloadCluster, &managedCluster)
+		err = util.UnmarshalPayload(payloadCluster, &managedCluster)
 		if err != nil {
 			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
 			fmt.Fprintf(gin.DefaultWriter, "error to unmarshal payload to managedCluster: %v\n", err)
@@ -4,6 +4,7 @@
 package managedclusters
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,8 +12,11 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/audit"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
 )
@@ -50,12 +54,23 @@ func PatchManagedCluster() gin.HandlerFunc {
 		clusterID := ginCtx.Param("clusterID")
 
 		fmt.Fprintf(gin.DefaultWriter, "patch for cluster with ID: %s\n", clusterID)
+		if _, err := uuid.Parse(clusterID); err != nil {
+			ginCtx.String(http.StatusBadRequest, "invalid cluster ID %s", clusterID)
+			return
+		}
 
 		db := database.GetGorm()
-		var leafHubName, managedClusterName string
-		if err := db.Raw(`SELECT leaf_hub_name, payload->'metadata'->>'name' FROM status.managed_clusters 
-			WHERE cluster_id = ?`, clusterID).Row().Scan(&leafHubName, &managedClusterName); err != nil {
+		var leafHubName, managedClusterName, currentLabels string
+		if err := db.Raw(`SELECT leaf_hub_name, payload->'metadata'->>'name', 
+			COALESCE(payload->'metadata'->'labels', '{}'::jsonb) FROM status.managed_clusters 
+			WHERE cluster_id = ?`, clusterID).Row().Scan(&leafHubName, &managedClusterName,
+			&currentLabels); err != nil {
 			fmt.Fprintf(gin.DefaultWriter, "failed to get leaf hub and manged cluster name: %s\n", err.Error())
+			if errors.Is(err, sql.ErrNoRows) {
+				ginCtx.String(http.StatusNotFound, "managed cluster %s not found", clusterID)
+				return
+			}
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
 			return
 		}
 
@@ -79,11 +94,26 @@ func PatchManagedCluster() gin.HandlerFunc {
 		fmt.Fprintf(gin.DefaultWriter, "labels to add: %v\n", labelsToAdd)
 		fmt.Fprintf(gin.DefaultWriter, "labels to remove: %v\n", labelsToRemove)
 
+		auditEntry, err := labelsPatchAudit(clusterID, managedClusterName, currentLabels, labelsToAdd,
+			labelsToRemove)
+		if err != nil {
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			fmt.Fprintf(gin.DefaultWriter, "error in auditing managed cluster labels: %v\n", err)
+			return
+		}
+
 		retryAttempts := optimisticConcurrencyRetryAttempts
 
 		for retryAttempts > 0 {
-			err = updateLabels(clusterID, leafHubName, managedClusterName, labelsToAdd,
-				labelsToRemove)
+			// the labels are updated with the audit log in a transaction, the update is rolled back if the audit log
+			// can't be recorded
+			err = db.WithContext(ginCtx.Request.Context()).Transaction(func(tx *gorm.DB) error {
+				if err := updateLabels(tx, clusterID, leafHubName, managedClusterName, labelsToAdd,
+					labelsToRemove); err != nil {
+					return err
+				}
+				return util.RecordAudit(ginCtx, tx, auditEntry)
+			})
 			if err == nil {
 				break
 			}
@@ -92,21 +122,49 @@ func PatchManagedCluster() gin.HandlerFunc {
 		}
 
 		if err != nil {
-			ginCtx.String(http.StatusInternalServerError, "internal error")
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
 			fmt.Fprintf(gin.DefaultWriter, "error in updating managed cluster labels: %v\n", err)
+			return
 		}
 
 		ginCtx.String(http.StatusOK, "managed cluster label patched")
 	}
 }
 
-func updateLabels(clusterID, leafHubName, managedClusterName string, labelsToAdd map[string]string,
+// labelsPatchAudit returns the audit log entry with the labels of the managed cluster before and after the patch, the
+// patch which doesn't change the labels isn't recorded.
+func labelsPatchAudit(clusterID, managedClusterName, currentLabels string,
+	labelsToAdd map[string]string, labelsToRemove map[string]struct{},
+) (*audit.Entry, error) {
+	before := map[string]string{}
+	if err := json.Unmarshal([]byte(currentLabels), &before); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the labels of managed cluster %s: %w", clusterID, err)
+	}
+	after := make(map[string]string, len(before)+len(labelsToAdd))
+	for key, value := range before {
+		if _, keyToBeRemoved := labelsToRemove[key]; !keyToBeRemoved {
+			after[key] = value
+		}
+	}
+	for key, value := range labelsToAdd {
+		after[key] = value
+	}
+	return &audit.Entry{
+		Action: audit.ActionPatchLabels,
+		Kind:   "ManagedCluster",
+		Name:   managedClusterName,
+		ID:     clusterID,
+		Before: map[string]interface{}{"metadata": map[string]interface{}{"labels": before}},
+		After:  map[string]interface{}{"metadata": map[string]interface{}{"labels": after}},
+	}, nil
+}
+
+func updateLabels(db *gorm.DB, clusterID, leafHubName, managedClusterName string, labelsToAdd map[string]string,
 	labelsToRemove map[string]struct{},
 ) error {
 	if len(labelsToAdd) == 0 && len(labelsToRemove) == 0 {
 		return nil
 	}
-	db := database.GetGorm()
 
 	managedClusterLabels := []models.ManagedClusterLabel{}
 	err := db.Where(models.ManagedClusterLabel{ID: clusterID}).Find(&managedClusterLabels).Error
@@ -147,7 +205,7 @@ func updateLabels(clusterID, leafHubName, managedClusterName string, labelsToAdd
 	}
 	existVersion := managedClusterLabels[0].Version
 
-	err = updateRow(clusterID, labelsToAdd, existLabels, labelsToRemove,
+	err = updateRow(db, clusterID, labelsToAdd, existLabels, labelsToRemove,
 		getMap(existLabelsToRemoveSlice), existVersion)
 	if err != nil {
 		return fmt.Errorf("failed to update managed_clusters_labels table: %w", err)
@@ -161,7 +219,7 @@ func updateLabels(clusterID, leafHubName, managedClusterName string, labelsToAdd
 	return nil
 }
 
-func updateRow(clusterID string, labelsToAdd, existLabelsToAdd map[string]string,
+func updateRow(db *gorm.DB, clusterID string, labelsToAdd, existLabelsToAdd map[string]string,
 	labelsToRemove, existLabelsToRemove map[string]struct{}, existVersion int,
 ) error {
 	newLabelsToAdd := make(map[string]string)
@@ -187,7 +245,6 @@ func updateRow(clusterID string, labelsToAdd, existLabelsToAdd map[string]string
 		newLabelsToAdd[key] = value
 	}
 
-	db := database.GetGorm()
 	newLabelsToAddPayload, err := json.Marshal(newLabelsToAdd)
 	if err != nil {
 		return err
@@ -0,0 +1,229 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package migrations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/audit"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/migration"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+const (
+	serverInternalErrorMsg = "internal error"
+	defaultListLimit       = 20
+)
+
+// MigrationRequest requests to migrate the managed clusters from the source hub to the target hub
+type MigrationRequest struct {
+	SourceHub string `json:"sourceHub"`
+	TargetHub string `json:"targetHub"`
+	// the names of the managed clusters on the source hub
+	Clusters []string `json:"clusters"`
+	// the duration to wait for the clusters to join the target hub before rolling them back, e.g. 30m
+	Timeout string `json:"timeout"`
+}
+
+// Migration is the migration and its progress on each managed cluster
+type Migration struct {
+	ID          string                      `json:"id"`
+	SourceHub   string                      `json:"sourceHub"`
+	TargetHub   string                      `json:"targetHub"`
+	Clusters    json.RawMessage             `json:"clusters"`
+	Timeout     string                      `json:"timeout"`
+	Phase       string                      `json:"phase"`
+	Message     string                      `json:"message,omitempty"`
+	Progress    []migration.ClusterProgress `json:"progress,omitempty"`
+	CreatedAt   time.Time                   `json:"createdAt"`
+	StartedAt   *time.Time                  `json:"startedAt,omitempty"`
+	CompletedAt *time.Time                  `json:"completedAt,omitempty"`
+}
+
+// CreateMigration godoc
+// @summary create migration
+// @description migrate the managed clusters from the source hub to the target hub, the labels and the cluster set of
+// @description the clusters are kept, and the clusters are rolled back if they don't join the target hub in time
+// @accept json
+// @produce json
+// @param        migration    body    MigrationRequest    true    "The hubs and the clusters to migrate"
+// @success      201  {object}  Migration
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      409
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /migrations [post]
+func CreateMigration() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		request := &MigrationRequest{}
+		if err := ginCtx.BindJSON(request); err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to bind: %s\n", err.Error())
+			return
+		}
+		timeout := migration.DefaultTimeout
+		if request.Timeout != "" {
+			var err error
+			if timeout, err = time.ParseDuration(request.Timeout); err != nil || timeout <= 0 {
+				ginCtx.String(http.StatusBadRequest, "invalid timeout: %s", request.Timeout)
+				return
+			}
+		}
+
+		var created *models.ManagedClusterMigration
+		err := database.GetGorm().WithContext(ginCtx.Request.Context()).Transaction(func(tx *gorm.DB) error {
+			var err error
+			if created, err = migration.Create(tx, request.SourceHub, request.TargetHub, request.Clusters,
+				timeout); err != nil {
+				return err
+			}
+			return util.RecordAudit(ginCtx, tx, &audit.Entry{
+				Action: audit.ActionCreate,
+				Kind:   "Migration",
+				Name:   created.ID,
+				ID:     created.ID,
+				After:  request,
+			})
+		})
+		if errors.Is(err, migration.ErrInvalidMigration) {
+			ginCtx.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, migration.ErrConflictingMigration) {
+			ginCtx.String(http.StatusConflict, err.Error())
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in creating migration: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+		fmt.Fprintf(gin.DefaultWriter, "created migration: %s\n", created.ID)
+		result, err := toMigration(created)
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in decoding migration: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+		ginCtx.JSON(http.StatusCreated, result)
+	}
+}
+
+// ListMigrations godoc
+// @summary list migrations
+// @description list the latest migrations
+// @accept json
+// @produce json
+// @param        limit    query    int    false    "Maximum number of migrations to receive, default 20"
+// @success      200  {array}  Migration
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /migrations [get]
+func ListMigrations() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		limit := defaultListLimit
+		if value := ginCtx.Query("limit"); value != "" {
+			var err error
+			if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+				ginCtx.String(http.StatusBadRequest, "invalid limit: %s", value)
+				return
+			}
+		}
+
+		var migrations []models.ManagedClusterMigration
+		if err := database.GetReadGorm().Order("created_at DESC").Limit(limit).Find(&migrations).Error; err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in querying migrations: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+		result := make([]Migration, 0, len(migrations))
+		for i := range migrations {
+			found, err := toMigration(&migrations[i])
+			if err != nil {
+				fmt.Fprintf(gin.DefaultWriter, "error in decoding migration: %v\n", err)
+				ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+				return
+			}
+			result = append(result, found)
+		}
+		ginCtx.JSON(http.StatusOK, result)
+	}
+}
+
+// GetMigration godoc
+// @summary get migration
+// @description get the progress of the migration on each managed cluster
+// @accept json
+// @produce json
+// @param        migrationID    path    string    true    "Migration ID"
+// @success      200  {object}  Migration
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      404
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /migration/{migrationID} [get]
+func GetMigration() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		migrationID := ginCtx.Param("migrationID")
+
+		found := &models.ManagedClusterMigration{}
+		err := database.GetGorm().Where("id = ?", migrationID).First(found).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ginCtx.String(http.StatusNotFound, "migration not found: %s", migrationID)
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in querying migration: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+		result, err := toMigration(found)
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in decoding migration: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+		ginCtx.JSON(http.StatusOK, result)
+	}
+}
+
+// toMigration returns the migration with the decoded progress of the clusters
+func toMigration(found *models.ManagedClusterMigration) (Migration, error) {
+	progress, err := migration.DecodeProgress(found)
+	if err != nil {
+		return Migration{}, err
+	}
+	return Migration{
+		ID:          found.ID,
+		SourceHub:   found.SourceHub,
+		TargetHub:   found.TargetHub,
+		Clusters:    json.RawMessage(found.Clusters),
+		Timeout:     (time.Duration(found.TimeoutSeconds) * time.Second).String(),
+		Phase:       found.Phase,
+		Message:     found.Message,
+		Progress:    progress,
+		CreatedAt:   found.CreatedAt,
+		StartedAt:   found.StartedAt,
+		CompletedAt: found.CompletedAt,
+	}, nil
+}
@@ -14,10 +14,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-logr/logr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/auditlogs"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/authentication"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/gitopsapplications"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/managedclusteraddons"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/managedclusters"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/migrations"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/policies"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/resyncs"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/search"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/subscriptions"
 )
 
@@ -60,7 +67,7 @@ func readCertificateAuthority(nonK8sAPIServerConfig *NonK8sAPIServerConfig) ([]b
 
 // AddNonK8sApiServer adds the non-k8s-api-server to the Manager.
 func AddNonK8sApiServer(mgr ctrl.Manager, nonK8sAPIServerConfig *NonK8sAPIServerConfig) error {
-	router, err := SetupRouter(nonK8sAPIServerConfig)
+	router, err := SetupRouter(nonK8sAPIServerConfig, mgr.GetClient())
 	if err != nil {
 		return err
 	}
@@ -97,7 +104,7 @@ func AddNonK8sApiServer(mgr ctrl.Manager, nonK8sAPIServerConfig *NonK8sAPIServer
 // @in                          header
 // @name                        Authorization
 // @description					Authorization with user access token
-func SetupRouter(nonK8sAPIServerConfig *NonK8sAPIServerConfig) (*gin.Engine, error) {
+func SetupRouter(nonK8sAPIServerConfig *NonK8sAPIServerConfig, runtimeClient client.Client) (*gin.Engine, error) {
 	router := gin.Default()
 	// add aythentication eith openshift oauth
 	// skip authentication middleware if ClusterAPIURL is empty for testing
@@ -113,10 +120,22 @@ func SetupRouter(nonK8sAPIServerConfig *NonK8sAPIServerConfig) (*gin.Engine, err
 	routerGroup.GET("/managedclusters", managedclusters.ListManagedClusters())
 	routerGroup.PATCH("/managedcluster/:clusterID",
 		managedclusters.PatchManagedCluster())
+	routerGroup.GET("/managedclusteraddons", managedclusteraddons.ListManagedClusterAddOns())
 	routerGroup.GET("/policies", policies.ListPolicies())
 	routerGroup.GET("/policy/:policyID/status", policies.GetPolicyStatus())
+	routerGroup.GET("/policy/:policyID/rollout", policies.GetPolicyRollout())
+	routerGroup.POST("/localpolicy/:policyID/adopt", policies.AdoptLocalPolicy(runtimeClient))
 	routerGroup.GET("/subscriptions", subscriptions.ListSubscriptions())
+	routerGroup.GET("/gitopsapplications", gitopsapplications.ListGitOpsApplications())
 	routerGroup.GET("/subscriptionreport/:subscriptionID", subscriptions.GetSubscriptionReport())
+	routerGroup.POST("/resyncs", resyncs.CreateResync())
+	routerGroup.GET("/resyncs", resyncs.ListResyncs())
+	routerGroup.GET("/resync/:resyncID", resyncs.GetResync())
+	routerGroup.POST("/migrations", migrations.CreateMigration())
+	routerGroup.GET("/migrations", migrations.ListMigrations())
+	routerGroup.GET("/migration/:migrationID", migrations.GetMigration())
+	routerGroup.POST("/search", search.Search())
+	routerGroup.GET("/auditlogs", auditlogs.ListAuditLogs())
 
 	return router, nil
 }
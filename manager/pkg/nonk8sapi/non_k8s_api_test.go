@@ -17,9 +17,21 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gorm.io/gorm"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/policies"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
 )
@@ -47,6 +59,7 @@ func CreateTestResponseRecorder() *TestResponseRecorder {
 var _ = Describe("Nonk8s API Server", Ordered, func() {
 	var db *gorm.DB
 	var router *gin.Engine
+	var runtimeClient client.Client
 	var plc1ID string
 	var sub1ID string
 	var sub2ID string
@@ -64,11 +77,27 @@ var _ = Describe("Nonk8s API Server", Ordered, func() {
 		Expect(err).NotTo(HaveOccurred())
 		db = database.GetGorm()
 
+		By("Create the client of the global hub which allows the access of the user")
+		scheme := runtime.NewScheme()
+		Expect(policyv1.AddToScheme(scheme)).To(Succeed())
+		Expect(clusterv1beta1.Install(scheme)).To(Succeed())
+		Expect(clusterv1beta2.Install(scheme)).To(Succeed())
+		Expect(authorizationv1.AddToScheme(scheme)).To(Succeed())
+		runtimeClient = fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+					review.Status.Allowed = review.Spec.User == "kube:admin"
+					return nil
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+
 		By("Set up nonk8s-api server router")
 		router, err = nonk8sapi.SetupRouter(&nonk8sapi.NonK8sAPIServerConfig{
 			ServerBasePath: "/global-hub-api/v1",
 			ClusterAPIURL:  testAuthServer.URL,
-		})
+		}, runtimeClient)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -197,6 +226,32 @@ var _ = Describe("Nonk8s API Server", Ordered, func() {
 		Expect(w2.Body.String()).Should(MatchJSON(
 			fmt.Sprintf(managedClusterListFormatStr, mc1, mc2)))
 
+		By("Check the managedclusters can be listed with set based labelSelector and fieldSelector")
+		w22 := httptest.NewRecorder()
+		req22, err := http.NewRequest("GET",
+			"/global-hub-api/v1/managedclusters?"+
+				"labelSelector=cloud%20in%20(Other%2CAmazon)%2Cvendor%20notin%20(Openshift)&"+
+				"fieldSelector=leafHubName%3Dhub1",
+			nil)
+		Expect(err).ToNot(HaveOccurred())
+		router.ServeHTTP(w22, req22)
+		Expect(w22.Code).To(Equal(200))
+		Expect(w22.Body.String()).Should(MatchJSON(
+			fmt.Sprintf(managedClusterListFormatStr, mc1, mc2)))
+
+		By("Check the managedclusters can't be listed with invalid selector")
+		for _, query := range []string{
+			"labelSelector=cloud%3DOther'%3B--",
+			"fieldSelector=spec.hubAcceptsClient%3Dtrue",
+			"limit=1%3BDROP",
+		} {
+			w23 := httptest.NewRecorder()
+			req23, err := http.NewRequest("GET", "/global-hub-api/v1/managedclusters?"+query, nil)
+			Expect(err).ToNot(HaveOccurred())
+			router.ServeHTTP(w23, req23)
+			Expect(w23.Code).To(Equal(400))
+		}
+
 		By("Check the managedcclusters can be listed as table")
 		// mclTable := `
 		// {
@@ -1442,6 +1497,160 @@ var _ = Describe("Nonk8s API Server", Ordered, func() {
 		Expect(w1.Body.String()).Should(MatchJSON(subscriptionReportStr))
 	})
 
+	It("Should be able to adopt a local policy", func() {
+		localPolicy := func(uid, hub, namespace, remediation string) string {
+			return fmt.Sprintf(`{
+				"apiVersion": "policy.open-cluster-management.io/v1",
+				"kind": "Policy",
+				"metadata": {
+					"name": "local-policy",
+					"namespace": "%s",
+					"uid": "%s",
+					"labels": {"env": "%s"},
+					"annotations": {
+						"policy.open-cluster-management.io/standards": "NIST SP 800-53",
+						"kubectl.kubernetes.io/last-applied-configuration": "{}"
+					}
+				},
+				"spec": {
+					"disabled": false,
+					"remediationAction": "%s",
+					"policy-templates": [{"objectDefinition": {
+						"apiVersion": "policy.open-cluster-management.io/v1",
+						"kind": "ConfigurationPolicy",
+						"metadata": {"name": "local-policy-namespace"},
+						"spec": {"object-templates": [{"complianceType": "musthave",
+							"objectDefinition": {"kind": "Namespace", "apiVersion": "v1", "metadata": {"name": "prod"}}}]}
+					}}]
+				}
+			}`, namespace, uid, hub, remediation)
+		}
+		hub1PolicyID := uuid.New().String()
+		hub2PolicyID := uuid.New().String()
+		hub3PolicyID := uuid.New().String()
+		for _, policy := range []struct{ id, hub, namespace, remediation string }{
+			{hub1PolicyID, "hub1", "default", "inform"},
+			{hub2PolicyID, "hub2", "default", "inform"},
+			{hub3PolicyID, "hub3", "default", "enforce"},
+		} {
+			err := db.Exec(`INSERT INTO local_spec.policies (policy_id,leaf_hub_name,payload) VALUES(?, ?, ?)`,
+				policy.id, policy.hub, localPolicy(policy.id, policy.hub, policy.namespace, policy.remediation)).Error
+			Expect(err).ToNot(HaveOccurred())
+		}
+		for _, compliance := range []struct{ id, hub, cluster string }{
+			{hub1PolicyID, "hub1", "mc1"},
+			{hub1PolicyID, "hub1", "mc2"},
+			{hub2PolicyID, "hub2", "mc3"},
+		} {
+			err := db.Exec(`INSERT INTO local_status.compliance (policy_id,cluster_name,leaf_hub_name,error,compliance)
+				VALUES(?, ?, ?, 'none', 'compliant')`, compliance.id, compliance.cluster, compliance.hub).Error
+			Expect(err).ToNot(HaveOccurred())
+		}
+		adopt := func(policyID, body string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", fmt.Sprintf("/global-hub-api/v1/localpolicy/%s/adopt", policyID),
+				bytes.NewBufferString(body))
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer token")
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		By("Check the unknown local policy isn't adopted")
+		Expect(adopt(uuid.New().String(), "").Code).To(Equal(http.StatusNotFound))
+		Expect(adopt("invalid", "").Code).To(Equal(http.StatusBadRequest))
+
+		By("Check the global policy can't override the local policies with the same name and a different spec")
+		w := adopt(hub1PolicyID, "")
+		Expect(w.Code).To(Equal(http.StatusConflict))
+		Expect(w.Body.String()).To(ContainSubstring("with a different spec exists on hub hub3"))
+		Expect(w.Body.String()).NotTo(ContainSubstring("hub1"))
+		Expect(w.Body.String()).NotTo(ContainSubstring("hub2"))
+
+		By("Check the copies of the local policy are replaced in the dry run")
+		w = adopt(hub1PolicyID, `{"name": "replacing-policy", "replaceLocal": true, "dryRun": true}`)
+		Expect(w.Code).To(Equal(http.StatusOK), w.Body.String())
+		adoption := &policies.PolicyAdoption{}
+		Expect(json.Unmarshal(w.Body.Bytes(), adoption)).To(Succeed())
+		Expect(adoption.ReplacedPolicies).To(ConsistOf(
+			policies.LocalPolicyReference{PolicyID: hub1PolicyID, LeafHubName: "hub1"},
+			policies.LocalPolicyReference{PolicyID: hub2PolicyID, LeafHubName: "hub2"}))
+		Expect(adoption.Placement.Spec.Predicates[0].RequiredClusterSelector.LabelSelector.MatchExpressions[0].
+			Values).To(Equal([]string{"mc1", "mc2", "mc3"}))
+		replacements := []models.LocalPolicyReplacement{}
+		Expect(db.Find(&replacements).Error).To(Succeed())
+		Expect(replacements).To(BeEmpty())
+
+		By("Check the adoption with a new name in the dry run")
+		w = adopt(hub1PolicyID, `{"name": "global-policy", "dryRun": true}`)
+		Expect(w.Code).To(Equal(http.StatusOK), w.Body.String())
+		adoption = &policies.PolicyAdoption{}
+		Expect(json.Unmarshal(w.Body.Bytes(), adoption)).To(Succeed())
+		Expect(adoption.DryRun).To(BeTrue())
+		Expect(adoption.ReplacedPolicies).To(BeEmpty())
+		Expect(adoption.Placement.Spec.Predicates[0].RequiredClusterSelector.LabelSelector.MatchExpressions[0].
+			Values).To(Equal([]string{"mc1", "mc2"}))
+		Expect(adoption.ClusterSetBinding).NotTo(BeNil())
+		Expect(adoption.ClusterSetBinding.Spec.ClusterSet).To(Equal("global"))
+		Expect(runtimeClient.Get(ctx, client.ObjectKeyFromObject(adoption.Policy), &policyv1.Policy{})).ShouldNot(
+			Succeed())
+
+		By("Check the cluster set binding which isn't synced to the managed hubs")
+		Expect(runtimeClient.Create(ctx, &clusterv1beta2.ManagedClusterSetBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "global", Namespace: "unsynced"},
+			Spec:       clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: "global"},
+		})).To(Succeed())
+		w = adopt(hub1PolicyID, `{"name": "global-policy", "namespace": "unsynced", "dryRun": true}`)
+		Expect(w.Code).To(Equal(http.StatusConflict))
+		Expect(w.Body.String()).To(ContainSubstring("ManagedClusterSetBinding unsynced/global isn't synced"))
+
+		By("Check the local policy is adopted with a new name")
+		w = adopt(hub1PolicyID, `{"name": "global-policy", "clusterSelector": {"matchLabels": {"env": "prod"}}}`)
+		Expect(w.Code).To(Equal(http.StatusCreated), w.Body.String())
+		policy := &policyv1.Policy{}
+		Expect(runtimeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "global-policy"},
+			policy)).To(Succeed())
+		Expect(policy.Labels).To(HaveKey(constants.GlobalHubGlobalResourceLabel))
+		Expect(policy.Labels).To(HaveKeyWithValue("env", "hub1"))
+		Expect(policy.Annotations).NotTo(HaveKey("kubectl.kubernetes.io/last-applied-configuration"))
+		Expect(policy.Spec.RemediationAction).To(Equal(policyv1.RemediationAction("inform")))
+		placement := &clusterv1beta1.Placement{}
+		Expect(runtimeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "global-policy-global"},
+			placement)).To(Succeed())
+		Expect(placement.Labels).To(HaveKey(constants.GlobalHubGlobalResourceLabel))
+		Expect(placement.Spec.Predicates[0].RequiredClusterSelector.LabelSelector.MatchLabels).To(
+			HaveKeyWithValue("env", "prod"))
+		binding := &policyv1.PlacementBinding{}
+		Expect(runtimeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "global-policy-global"},
+			binding)).To(Succeed())
+		Expect(binding.Subjects[0].Name).To(Equal("global-policy"))
+		Expect(binding.PlacementRef.Name).To(Equal("global-policy-global"))
+		clusterSetBinding := &clusterv1beta2.ManagedClusterSetBinding{}
+		Expect(runtimeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "global"},
+			clusterSetBinding)).To(Succeed())
+		Expect(clusterSetBinding.Labels).To(HaveKey(constants.GlobalHubGlobalResourceLabel))
+
+		By("Check the global policy can't be adopted twice")
+		w = adopt(hub1PolicyID, `{"name": "global-policy", "clusterSelector": {"matchLabels": {"env": "prod"}}}`)
+		Expect(w.Code).To(Equal(http.StatusConflict))
+		Expect(w.Body.String()).To(ContainSubstring("Policy default/global-policy exists on the global hub"))
+
+		By("Check the copies of the local policy are recorded to be deleted by the replacing policy")
+		w = adopt(hub1PolicyID, `{"name": "replacing-policy", "replaceLocal": true}`)
+		Expect(w.Code).To(Equal(http.StatusCreated), w.Body.String())
+		Expect(db.Order("leaf_hub_name").Find(&replacements).Error).To(Succeed())
+		Expect(replacements).To(HaveLen(2))
+		for i, hub := range []string{"hub1", "hub2"} {
+			Expect(replacements[i].LeafHubName).To(Equal(hub))
+			Expect(replacements[i].LocalNamespace).To(Equal("default"))
+			Expect(replacements[i].LocalName).To(Equal("local-policy"))
+			Expect(replacements[i].PolicyNamespace).To(Equal("default"))
+			Expect(replacements[i].PolicyName).To(Equal("replacing-policy"))
+			Expect(replacements[i].Replaced).To(BeFalse())
+		}
+	})
+
 	AfterAll(func() {
 		database.CloseGorm()
 	})
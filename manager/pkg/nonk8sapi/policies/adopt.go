@@ -0,0 +1,561 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package policies
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/audit"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/authentication"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
+)
+
+const (
+	// adoptedPlacementSuffix names the placement and the placement binding of the adopted policy, the suffix keeps
+	// them apart from the local placements which are usually named after the policy.
+	adoptedPlacementSuffix = "-global"
+	// clusterNameLabel is the label with the name of the managed cluster, it selects the clusters of the local policy
+	clusterNameLabel = "name"
+	// lastAppliedAnnotation is dropped from the adopted policy since it describes the local policy
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+	// globalClusterSet includes all the managed clusters of the hub, the placement only selects the clusters of the
+	// cluster sets bound to its namespace
+	globalClusterSet = "global"
+)
+
+// PolicyAdoptionRequest is the request to adopt the local policy into a global policy.
+type PolicyAdoptionRequest struct {
+	// Name of the global policy, the default is the name of the local policy.
+	Name string `json:"name,omitempty"`
+	// Namespace of the global policy, the default is the namespace of the local policy. The namespace must exist
+	// on the global hub.
+	Namespace string `json:"namespace,omitempty"`
+	// ClusterSelector selects the managed clusters of the global placement, the default selects the clusters which
+	// report the compliance of the local policy.
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+	// ReplaceLocal replaces the copies of the local policy on the managed hubs with the global policy, the copies are
+	// the local policies with the same namespace, name and spec. The copies are deleted on the hubs once the global
+	// policy reports the compliance from them, unless the global policy keeps their name and takes them over.
+	ReplaceLocal bool `json:"replaceLocal,omitempty"`
+	// DryRun validates the adoption and returns the objects without creating them.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// LocalPolicyReference is the local policy on the managed hub.
+type LocalPolicyReference struct {
+	PolicyID    string `json:"policyID"`
+	LeafHubName string `json:"leafHubName"`
+}
+
+// PolicyAdoption is the global policy with the placement and the binding created from the local policy.
+type PolicyAdoption struct {
+	Policy           *policyv1.Policy           `json:"policy"`
+	Placement        *clusterv1beta1.Placement  `json:"placement"`
+	PlacementBinding *policyv1.PlacementBinding `json:"placementBinding"`
+	// ClusterSetBinding binds the global cluster set to the namespace, it's created only if the namespace has no
+	// binding of the global cluster set.
+	ClusterSetBinding *clusterv1beta2.ManagedClusterSetBinding `json:"clusterSetBinding,omitempty"`
+	// ReplacedPolicies are the local policies which are replaced by the global policy on the managed hubs.
+	ReplacedPolicies []LocalPolicyReference `json:"replacedPolicies,omitempty"`
+	DryRun           bool                   `json:"dryRun,omitempty"`
+}
+
+// AdoptLocalPolicy godoc
+// @summary adopt local policy
+// @description adopt the local policy of a managed hub into a global policy with the placement and the binding
+// @accept json
+// @produce json
+// @param        policyID    path    string                   true    "Local Policy ID"
+// @param        adoption    body    PolicyAdoptionRequest    false   "Settings of the global policy"
+// @success      200  {object}  PolicyAdoption
+// @success      201  {object}  PolicyAdoption
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      404
+// @failure      409
+// @failure      422
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /localpolicy/{policyID}/adopt [post]
+func AdoptLocalPolicy(runtimeClient client.Client) gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		policyID := ginCtx.Param("policyID")
+		fmt.Fprintf(gin.DefaultWriter, "adopting local policy: %s\n", policyID)
+		if _, err := uuid.Parse(policyID); err != nil {
+			ginCtx.String(http.StatusBadRequest, "invalid policy ID %s", policyID)
+			return
+		}
+
+		request := &PolicyAdoptionRequest{}
+		if ginCtx.Request.ContentLength != 0 {
+			if err := ginCtx.ShouldBindJSON(request); err != nil {
+				ginCtx.String(http.StatusBadRequest, "invalid adoption request: %s", err.Error())
+				return
+			}
+		}
+
+		// the adoption is followed by writes, so the local policies are read from the primary database
+		db := database.GetGorm()
+		localPolicy := &models.LocalSpecPolicy{}
+		err := db.Where("policy_id = ?", policyID).First(localPolicy).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ginCtx.String(http.StatusNotFound, "local policy %s not found", policyID)
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, QueryPolicyFailureFormatMsg, err)
+			ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			return
+		}
+		source := &policyv1.Policy{}
+		if err := util.UnmarshalPayload(localPolicy.Payload, source); err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to decode local policy %s: %v\n", policyID, err)
+			ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			return
+		}
+		// the fields redacted by the agent can't be restored, adopting them would overwrite the real values
+		if isRedacted(source.Spec) {
+			ginCtx.String(http.StatusUnprocessableEntity,
+				"local policy %s has the fields redacted by the agent of %s, create the global policy from the source",
+				policyID, localPolicy.LeafHubName)
+			return
+		}
+
+		name, namespace := request.Name, request.Namespace
+		if name == "" {
+			name = source.Name
+		}
+		if namespace == "" {
+			namespace = source.Namespace
+		}
+
+		// the copies of the source policy on the hubs, including the source policy itself
+		sourceNamed := []models.LocalSpecPolicy{}
+		if err := db.Where("policy_name = ? AND payload -> 'metadata' ->> 'namespace' = ?", source.Name,
+			source.Namespace).Find(&sourceNamed).Error; err != nil {
+			fmt.Fprintf(gin.DefaultWriter, QueryPoliciesFailureFormatMsg, err)
+			ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			return
+		}
+		copies, err := matchLocalPolicies(source, sourceNamed)
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, QueryPoliciesFailureFormatMsg, err)
+			ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			return
+		}
+
+		// the global policy is applied to all the managed hubs, so it overrides the local policies with the same name,
+		// except the copies of the source policy since the global policy has the same spec
+		named := []models.LocalSpecPolicy{}
+		if err := db.Where("policy_name = ? AND payload -> 'metadata' ->> 'namespace' = ?", name, namespace).
+			Find(&named).Error; err != nil {
+			fmt.Fprintf(gin.DefaultWriter, QueryPoliciesFailureFormatMsg, err)
+			ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			return
+		}
+		conflicts := []string{}
+		for _, localPolicy := range named {
+			if isCopy(copies, localPolicy.PolicyID) {
+				continue
+			}
+			conflicts = append(conflicts, fmt.Sprintf("local policy %s (%s) with a different spec exists on hub %s, "+
+				"set another name", localPolicy.PolicyName, localPolicy.PolicyID, localPolicy.LeafHubName))
+		}
+
+		clusterSelector := request.ClusterSelector
+		if clusterSelector == nil {
+			// the replaced copies are propagated to the clusters of the global policy
+			policyIDs := []string{policyID}
+			if request.ReplaceLocal {
+				policyIDs = []string{}
+				for _, ref := range copies {
+					policyIDs = append(policyIDs, ref.PolicyID)
+				}
+			}
+			clusters := []string{}
+			if err := db.Model(&models.LocalStatusCompliance{}).Where("policy_id IN ?", policyIDs).
+				Distinct().Order("cluster_name").Pluck("cluster_name", &clusters).Error; err != nil {
+				fmt.Fprintf(gin.DefaultWriter, QueryPolicyComplianceFailureFormatMsg, policyID)
+				ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+				return
+			}
+			if len(clusters) == 0 {
+				ginCtx.String(http.StatusBadRequest,
+					"local policy %s isn't propagated to any cluster, the clusterSelector is required", policyID)
+				return
+			}
+			clusterSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      clusterNameLabel,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   clusters,
+			}}}
+		}
+		adoption := newPolicyAdoption(source, name, namespace, clusterSelector)
+		if request.ReplaceLocal {
+			adoption.ReplacedPolicies = copies
+		}
+		adoption.DryRun = request.DryRun
+
+		ctx := ginCtx.Request.Context()
+		// the placement selects nothing on the managed hubs unless the namespace binds the global cluster set
+		clusterSetBinding := &clusterv1beta2.ManagedClusterSetBinding{}
+		err = runtimeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: globalClusterSet},
+			clusterSetBinding)
+		switch {
+		case apierrors.IsNotFound(err):
+			adoption.ClusterSetBinding = newClusterSetBinding(namespace)
+		case err != nil:
+			fmt.Fprintf(gin.DefaultWriter, "failed to get the cluster set binding: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			return
+		default:
+			if _, found := clusterSetBinding.Labels[constants.GlobalHubGlobalResourceLabel]; !found {
+				conflicts = append(conflicts, fmt.Sprintf("ManagedClusterSetBinding %s/%s isn't synced to the "+
+					"managed hubs, add the label %s to it", namespace, globalClusterSet,
+					constants.GlobalHubGlobalResourceLabel))
+			}
+		}
+
+		if allowed, err := canAdopt(ctx, ginCtx, runtimeClient, adoption); err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to review the access of the user: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			return
+		} else if !allowed {
+			ginCtx.String(http.StatusForbidden, "the user can't create the policies, placements, "+
+				"placementbindings and managedclustersetbindings in the namespace %s", namespace)
+			return
+		}
+
+		existing, err := existingObjects(ctx, runtimeClient, adoption)
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to get the global objects: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			return
+		}
+		if conflicts = append(existing, conflicts...); len(conflicts) > 0 {
+			ginCtx.String(http.StatusConflict, "failed to adopt local policy %s: %s", policyID,
+				strings.Join(conflicts, "; "))
+			return
+		}
+
+		if request.DryRun {
+			ginCtx.JSON(http.StatusOK, adoption)
+			return
+		}
+		// the global objects are created with the identity of the manager, so the adoption is recorded with the
+		// authenticated user, and the content of the objects is recorded by the spec to db controllers. The audit
+		// log is committed once the objects are created, so the adoption isn't made if it can't be recorded.
+		adopted := map[string]interface{}{
+			"leafHubName":      localPolicy.LeafHubName,
+			"policy":           types.NamespacedName{Namespace: namespace, Name: name}.String(),
+			"placement":        adoption.Placement.Name,
+			"placementBinding": adoption.PlacementBinding.Name,
+		}
+		if adoption.ClusterSetBinding != nil {
+			adopted["clusterSetBinding"] = adoption.ClusterSetBinding.Name
+		}
+		if len(adoption.ReplacedPolicies) > 0 {
+			adopted["replacedPolicies"] = adoption.ReplacedPolicies
+		}
+		err = database.GetGorm().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := util.RecordAudit(ginCtx, tx, &audit.Entry{
+				Action:    audit.ActionAdopt,
+				Kind:      policyv1.Kind,
+				Namespace: source.Namespace,
+				Name:      source.Name,
+				ID:        policyID,
+				After:     adopted,
+			}); err != nil {
+				return err
+			}
+			// the copies with another name are deleted on the hubs once the global policy lands on them, the ones
+			// with the same name are taken over by the global policy
+			if len(adoption.ReplacedPolicies) > 0 && (name != source.Name || namespace != source.Namespace) {
+				if err := recordReplacements(tx, source, adoption); err != nil {
+					return err
+				}
+			}
+			return createAdoptedObjects(ctx, runtimeClient, adoption)
+		})
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to adopt local policy %s: %v\n", policyID, err)
+			switch {
+			case apierrors.IsAlreadyExists(err):
+				ginCtx.String(http.StatusConflict, err.Error())
+			case apierrors.IsNotFound(err):
+				ginCtx.String(http.StatusBadRequest, "namespace %s not found on the global hub", namespace)
+			case apierrors.IsInvalid(err):
+				ginCtx.String(http.StatusBadRequest, err.Error())
+			default:
+				ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			}
+			return
+		}
+		fmt.Fprintf(gin.DefaultWriter, "adopted local policy %s into global policy %s/%s\n", policyID,
+			namespace, name)
+		ginCtx.JSON(http.StatusCreated, adoption)
+	}
+}
+
+// matchLocalPolicies returns the copies of the source policy in the local policies with the same namespace and name,
+// they're the local policies with the same spec.
+func matchLocalPolicies(source *policyv1.Policy, localPolicies []models.LocalSpecPolicy,
+) ([]LocalPolicyReference, error) {
+	copies := []LocalPolicyReference{}
+	for _, localPolicy := range localPolicies {
+		policy := &policyv1.Policy{}
+		if err := util.UnmarshalPayload(localPolicy.Payload, policy); err != nil {
+			return nil, fmt.Errorf("failed to decode local policy %s: %w", localPolicy.PolicyID, err)
+		}
+		if !apiequality.Semantic.DeepEqual(source.Spec, policy.Spec) {
+			continue
+		}
+		copies = append(copies, LocalPolicyReference{
+			PolicyID:    localPolicy.PolicyID,
+			LeafHubName: localPolicy.LeafHubName,
+		})
+	}
+	return copies, nil
+}
+
+func isCopy(copies []LocalPolicyReference, policyID string) bool {
+	for _, ref := range copies {
+		if ref.PolicyID == policyID {
+			return true
+		}
+	}
+	return false
+}
+
+// recordReplacements records the local policies to be deleted on the hubs once the global policy lands on them.
+func recordReplacements(tx *gorm.DB, source *policyv1.Policy, adoption *PolicyAdoption) error {
+	replacements := []models.LocalPolicyReplacement{}
+	for _, ref := range adoption.ReplacedPolicies {
+		replacements = append(replacements, models.LocalPolicyReplacement{
+			LocalPolicyID:   ref.PolicyID,
+			LeafHubName:     ref.LeafHubName,
+			LocalNamespace:  source.Namespace,
+			LocalName:       source.Name,
+			PolicyNamespace: adoption.Policy.Namespace,
+			PolicyName:      adoption.Policy.Name,
+		})
+	}
+	return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&replacements).Error
+}
+
+// isRedacted returns true if any field of the spec is redacted by the agent.
+func isRedacted(spec policyv1.PolicySpec) bool {
+	payload, err := json.Marshal(spec)
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(payload, []byte(fmt.Sprintf("%q", sensitive.RedactedValue)))
+}
+
+// newPolicyAdoption copies the spec of the local policy into the global policy, and binds it to the placement of
+// the selected clusters. All of them have the global resource label to be synced to the managed hubs.
+func newPolicyAdoption(source *policyv1.Policy, name, namespace string, clusterSelector *metav1.LabelSelector,
+) *PolicyAdoption {
+	labels := map[string]string{}
+	for key, value := range source.Labels {
+		labels[key] = value
+	}
+	labels[constants.GlobalHubGlobalResourceLabel] = ""
+	annotations := map[string]string{}
+	for key, value := range source.Annotations {
+		if key != lastAppliedAnnotation {
+			annotations[key] = value
+		}
+	}
+	placementName := name + adoptedPlacementSuffix
+
+	return &PolicyAdoption{
+		Policy: &policyv1.Policy{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: policyv1.GroupVersion.String(),
+				Kind:       policyv1.Kind,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Spec: *source.Spec.DeepCopy(),
+		},
+		Placement: &clusterv1beta1.Placement{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: clusterv1beta1.GroupVersion.String(),
+				Kind:       "Placement",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      placementName,
+				Namespace: namespace,
+				Labels:    map[string]string{constants.GlobalHubGlobalResourceLabel: ""},
+			},
+			Spec: clusterv1beta1.PlacementSpec{
+				Predicates: []clusterv1beta1.ClusterPredicate{{
+					RequiredClusterSelector: clusterv1beta1.ClusterSelector{LabelSelector: *clusterSelector},
+				}},
+			},
+		},
+		PlacementBinding: &policyv1.PlacementBinding{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: policyv1.GroupVersion.String(),
+				Kind:       "PlacementBinding",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      placementName,
+				Namespace: namespace,
+				Labels:    map[string]string{constants.GlobalHubGlobalResourceLabel: ""},
+			},
+			PlacementRef: policyv1.PlacementSubject{
+				APIGroup: clusterv1beta1.GroupName,
+				Kind:     "Placement",
+				Name:     placementName,
+			},
+			Subjects: []policyv1.Subject{{
+				APIGroup: policyv1.GroupVersion.Group,
+				Kind:     policyv1.Kind,
+				Name:     name,
+			}},
+		},
+	}
+}
+
+// newClusterSetBinding binds the global cluster set to the namespace, it has the global resource label to be synced
+// to the managed hubs with the placement.
+func newClusterSetBinding(namespace string) *clusterv1beta2.ManagedClusterSetBinding {
+	return &clusterv1beta2.ManagedClusterSetBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1beta2.GroupVersion.String(),
+			Kind:       "ManagedClusterSetBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      globalClusterSet,
+			Namespace: namespace,
+			Labels:    map[string]string{constants.GlobalHubGlobalResourceLabel: ""},
+		},
+		Spec: clusterv1beta2.ManagedClusterSetBindingSpec{ClusterSet: globalClusterSet},
+	}
+}
+
+// canAdopt reviews whether the authenticated user can create the global objects in the namespace, since they're
+// created with the identity of the manager. The review is skipped if the authentication is disabled.
+func canAdopt(ctx context.Context, ginCtx *gin.Context, runtimeClient client.Client, adoption *PolicyAdoption,
+) (bool, error) {
+	user := ginCtx.GetString(authentication.UserKey)
+	if user == "" {
+		return true, nil
+	}
+	groups := ginCtx.GetStringSlice(authentication.GroupsKey)
+
+	namespace := adoption.Policy.Namespace
+	resources := []authorizationv1.ResourceAttributes{
+		{Group: policyv1.GroupVersion.Group, Resource: "policies", Namespace: namespace},
+		{Group: policyv1.GroupVersion.Group, Resource: "placementbindings", Namespace: namespace},
+		{Group: clusterv1beta1.GroupName, Resource: "placements", Namespace: namespace},
+	}
+	if adoption.ClusterSetBinding != nil {
+		// binding the cluster set requires the virtual bind subresource of the cluster set
+		resources = append(resources,
+			authorizationv1.ResourceAttributes{
+				Group: clusterv1beta2.GroupName, Resource: "managedclustersetbindings", Namespace: namespace,
+			},
+			authorizationv1.ResourceAttributes{
+				Group: clusterv1beta2.GroupName, Resource: "managedclustersets", Subresource: "bind",
+				Name: globalClusterSet,
+			})
+	}
+	for i := range resources {
+		resources[i].Verb = "create"
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				ResourceAttributes: &resources[i],
+				User:               user,
+				Groups:             groups,
+			},
+		}
+		if err := runtimeClient.Create(ctx, review); err != nil {
+			return false, err
+		}
+		if !review.Status.Allowed {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// existingObjects returns the conflicts of the global objects which already exist on the global hub.
+func existingObjects(ctx context.Context, runtimeClient client.Client, adoption *PolicyAdoption) ([]string, error) {
+	conflicts := []string{}
+	for _, obj := range []client.Object{adoption.Policy, adoption.Placement, adoption.PlacementBinding} {
+		existing := obj.DeepCopyObject().(client.Object)
+		err := runtimeClient.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, fmt.Sprintf("%s %s exists on the global hub",
+			obj.GetObjectKind().GroupVersionKind().Kind, types.NamespacedName{
+				Namespace: obj.GetNamespace(), Name: obj.GetName(),
+			}))
+	}
+	return conflicts, nil
+}
+
+// createAdoptedObjects creates the cluster set binding, the global policy, placement and binding, the created ones are
+// removed if any of them fails, so that the adoption can be retried.
+func createAdoptedObjects(ctx context.Context, runtimeClient client.Client, adoption *PolicyAdoption) error {
+	objects := []client.Object{adoption.Policy, adoption.Placement, adoption.PlacementBinding}
+	if adoption.ClusterSetBinding != nil {
+		objects = append([]client.Object{adoption.ClusterSetBinding}, objects...)
+	}
+	created := []client.Object{}
+	for _, obj := range objects {
+		// the response of the server drops the type meta of the typed object
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		if err := runtimeClient.Create(ctx, obj); err != nil {
+			for _, createdObj := range created {
+				if deleteErr := runtimeClient.Delete(ctx, createdObj); deleteErr != nil &&
+					!apierrors.IsNotFound(deleteErr) {
+					fmt.Fprintf(gin.DefaultWriter, "failed to remove %s %s/%s: %v\n", createdObj.GetObjectKind().
+						GroupVersionKind().Kind, createdObj.GetNamespace(), createdObj.GetName(), deleteErr)
+				}
+			}
+			return err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		created = append(created, obj)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ const (
 	QueryPoliciesFailureFormatMsg         = "error in querying policies: %v\n"
 	QueryPolicyComplianceFailureFormatMsg = "error in querying compliance status of a policy with UID: %v\n"
 	QueryPolicyMappingFailureFormatMsg    = "error in querying policy&placementbinding&placementrule mapping: %v\n"
+	QueryPolicyRolloutFailureFormatMsg    = "error in querying rollout of a policy: %v\n"
 )
 
 const (
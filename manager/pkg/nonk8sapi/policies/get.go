@@ -5,7 +5,9 @@ package policies
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -20,9 +22,18 @@ import (
 	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
 
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/grc"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
 )
 
+// clusterComplianceDetails is the latest status of the policy templates on the managed cluster.
+type clusterComplianceDetails struct {
+	ClusterName string                 `json:"clusterName"`
+	LeafHubName string                 `json:"leafHubName"`
+	Templates   []*grc.TemplateDetails `json:"templates"`
+}
+
 // GetPolicyStatus godoc
 // @summary get policy status
 // @description get status with a given policy
@@ -140,8 +151,13 @@ func handlePolicy(ginCtx *gin.Context, policyID, policyQuery, policyMappingQuery
 ) {
 	unstrPolicy, err := queryPolicyStatus(policyID,
 		policyQuery, policyMappingQuery, policyComplianceQuery)
+	if errors.Is(err, sql.ErrNoRows) {
+		ginCtx.String(http.StatusNotFound, "policy %s not found", policyID)
+		return
+	}
 	if err != nil {
 		ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+		return
 	}
 
 	// no need to return unstrPolicy spec
@@ -184,14 +200,14 @@ func queryPolicyStatus(policyID, policyQuery, policyMappingQuery,
 		return &unstructured.Unstructured{}, err
 	}
 
-	db := database.GetGorm()
+	db := database.GetReadGorm()
 	var payload []byte
 	err = db.Raw(policyQuery, policyID).Row().Scan(&payload)
 	if err != nil {
 		fmt.Fprintf(gin.DefaultWriter, QueryPolicyFailureFormatMsg, err)
 		return &unstructured.Unstructured{}, err
 	}
-	err = json.Unmarshal(payload, policy)
+	err = util.UnmarshalPayload(payload, policy)
 	if err != nil {
 		return &unstructured.Unstructured{}, err
 	}
@@ -205,6 +221,43 @@ func queryPolicyStatus(policyID, policyQuery, policyMappingQuery,
 
 	unstrPolicy, err := assemblePolicyStatus(policy, policyMatches,
 		compliancePerClusterStatuses, hasNonCompliantClusters)
+	if err != nil {
+		return &unstrPolicy, err
+	}
 
-	return &unstrPolicy, err
+	clusterDetails, err := getComplianceDetails(policyID)
+	if err != nil {
+		fmt.Fprintf(gin.DefaultWriter, QueryPolicyComplianceFailureFormatMsg, err)
+		return &unstrPolicy, err
+	}
+	if len(clusterDetails) > 0 {
+		unstrPolicy.Object["status"].(map[string]interface{})["clusterDetails"] = clusterDetails
+	}
+
+	return &unstrPolicy, nil
+}
+
+// getComplianceDetails returns the per-template status details of the policy on each managed cluster.
+func getComplianceDetails(policyID string) ([]clusterComplianceDetails, error) {
+	var complianceDetails []models.StatusComplianceDetails
+	err := database.GetReadGorm().Where(&models.StatusComplianceDetails{
+		PolicyID: policyID,
+	}).Order("leaf_hub_name asc").Order("cluster_name").Find(&complianceDetails).Error
+	if err != nil {
+		return nil, fmt.Errorf("error in querying policy compliance details: - %w", err)
+	}
+
+	clusterDetails := make([]clusterComplianceDetails, 0, len(complianceDetails))
+	for _, details := range complianceDetails {
+		templates := []*grc.TemplateDetails{}
+		if err := json.Unmarshal(details.Templates, &templates); err != nil {
+			return nil, err
+		}
+		clusterDetails = append(clusterDetails, clusterComplianceDetails{
+			ClusterName: details.ClusterName,
+			LeafHubName: details.LeafHubName,
+			Templates:   templates,
+		})
+	}
+	return clusterDetails, nil
 }
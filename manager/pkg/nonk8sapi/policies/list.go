@@ -46,6 +46,11 @@ type policyMatch struct {
 }
 
 var (
+	// fieldColumns are the fields supported by the field selector of the policies
+	fieldColumns = util.FieldColumns{
+		"metadata.name":      "payload -> 'metadata' ->> 'name'",
+		"metadata.namespace": "payload -> 'metadata' ->> 'namespace'",
+	}
 	policyMatches                   = []*policyMatch{}
 	customResourceColumnDefinitions = util.GetCustomResourceColumnDefinitions(crdName, policyv1.GroupVersion.Version)
 )
@@ -56,6 +61,7 @@ var (
 // @accept json
 // @produce json
 // @param        labelSelector    query     string  false  "list policies by label selector"
+// @param        fieldSelector    query     string  false  "list policies by field selector, e.g. metadata.namespace=default"
 // @param        limit            query     int     false  "maximum policy number to receive"
 // @param        continue         query     string  false  "continue token to request next request"
 // @success      200  {object}    policyv1.PolicyList
@@ -69,57 +75,29 @@ var (
 // @router /policies [get]
 func ListPolicies() gin.HandlerFunc {
 	return func(ginCtx *gin.Context) {
-		labelSelector := ginCtx.Query("labelSelector")
-
-		selectorInSql := ""
-		if labelSelector != "" {
-			var err error
-			selectorInSql, err = util.ParseLabelSelector(labelSelector)
-			if err != nil {
-				fmt.Fprintf(gin.DefaultWriter, "failed to parse label selector: %s\n", err.Error())
-				return
-			}
-		}
-
-		fmt.Fprintf(gin.DefaultWriter, "parsed selector: %s\n", selectorInSql)
-
-		limit := ginCtx.Query("limit")
-		fmt.Fprintf(gin.DefaultWriter, "limit: %v\n", limit)
-
-		continueToken := ginCtx.Query("continue")
-
-		lastPolicyName, lastPolicyUID := "", ""
-		if continueToken != "" {
-			fmt.Fprintf(gin.DefaultWriter, "continue: %v\n", continueToken)
-
-			var err error
-			lastPolicyName, lastPolicyUID, err = util.DecodeContinue(continueToken)
-			if err != nil {
-				fmt.Fprintf(gin.DefaultWriter, "failed to decode continue token: %s\n", err.Error())
-				return
-			}
+		listOptions, err := util.ParseListOptions(ginCtx, fieldColumns)
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to parse the list options: %s\n", err.Error())
+			ginCtx.String(http.StatusBadRequest, err.Error())
+			return
 		}
 
 		fmt.Fprintf(gin.DefaultWriter,
-			"last returned policy name: %s, last returned policy] UID: %s\n",
-			lastPolicyName,
-			lastPolicyUID)
+			"last returned policy name: %s, last returned policy UID: %s\n",
+			listOptions.LastName,
+			listOptions.LastUID)
 
-		// build query condition for paging
-		LastResourceCompareCondition := fmt.Sprintf(
-			"(payload -> 'metadata' ->> 'name', payload -> 'metadata' ->> 'uid') > ('%s', '%s') ",
-			lastPolicyName,
-			lastPolicyUID)
-
-		// policy list query order by name and uid
+		// policy list query order by name and uid, the paging condition is compared with the last one
 		policyListQuery := "SELECT id, payload FROM spec.policies WHERE deleted = FALSE AND " +
-			LastResourceCompareCondition +
-			selectorInSql +
+			"(payload -> 'metadata' ->> 'name', payload -> 'metadata' ->> 'uid') > (?, ?)" +
+			listOptions.Conditions +
 			" ORDER BY (payload -> 'metadata' ->> 'name', payload -> 'metadata' ->> 'uid')"
+		args := append([]interface{}{listOptions.LastName, listOptions.LastUID}, listOptions.Args...)
 
 		// add limit
-		if limit != "" {
-			policyListQuery += fmt.Sprintf(" LIMIT %s", limit)
+		if listOptions.Limit > 0 {
+			policyListQuery += " LIMIT ?"
+			args = append(args, listOptions.Limit)
 		}
 
 		// last policy order by name and uid query
@@ -127,22 +105,22 @@ func ListPolicies() gin.HandlerFunc {
 			"ORDER BY (payload -> 'metadata' ->> 'name', payload -> 'metadata' ->> 'uid') DESC LIMIT 1"
 
 		fmt.Fprintf(gin.DefaultWriter, "last policy query: %v\n", lastPolicyQuery)
-		fmt.Fprintf(gin.DefaultWriter, "policy list query: %v\n", policyListQuery)
+		fmt.Fprintf(gin.DefaultWriter, "policy list query: %v, args: %v\n", policyListQuery, args)
 		fmt.Fprintf(gin.DefaultWriter, "policy compliance query with policy ID: %v\n", policyComplianceQuery)
 		fmt.Fprintf(gin.DefaultWriter, "policy&placementbinding&placementrule mapping query: %v\n", policyMappingQuery)
 
 		if _, watch := ginCtx.GetQuery("watch"); watch {
-			handlePoliciesForWatch(ginCtx, policyListQuery, policyMappingQuery, policyComplianceQuery)
+			handlePoliciesForWatch(ginCtx, policyListQuery, args, policyMappingQuery, policyComplianceQuery)
 			return
 		}
 
-		handlePolicies(ginCtx, policyListQuery, lastPolicyQuery, policyMappingQuery,
+		handlePolicies(ginCtx, policyListQuery, args, lastPolicyQuery, policyMappingQuery,
 			policyComplianceQuery, customResourceColumnDefinitions)
 	}
 }
 
-func handlePoliciesForWatch(ginCtx *gin.Context, policyListQuery, policyMappingQuery,
-	policyComplianceQuery string,
+func handlePoliciesForWatch(ginCtx *gin.Context, policyListQuery string, args []interface{},
+	policyMappingQuery, policyComplianceQuery string,
 ) {
 	writer := ginCtx.Writer
 	header := writer.Header()
@@ -172,24 +150,25 @@ func handlePoliciesForWatch(ginCtx *gin.Context, policyListQuery, policyMappingQ
 				return
 			}
 
-			doHandlePoliciesForWatch(ctx, writer, policyListQuery, policyMappingQuery,
+			doHandlePoliciesForWatch(ctx, writer, policyListQuery, args, policyMappingQuery,
 				policyComplianceQuery, preAddedPolicies)
 		}
 	}
 }
 
-func doHandlePoliciesForWatch(ctx context.Context, writer gin.ResponseWriter,
-	policyListQuery, policyMappingQuery, policyComplianceQuery string, preAddedPolicies set.Set,
+func doHandlePoliciesForWatch(ctx context.Context, writer gin.ResponseWriter, policyListQuery string,
+	args []interface{}, policyMappingQuery, policyComplianceQuery string, preAddedPolicies set.Set,
 ) {
 	var err error
 	policyMatches, err = getPolicyMatches(policyMappingQuery)
 	if err != nil {
 		fmt.Fprintf(gin.DefaultWriter, QueryPolicyMappingFailureFormatMsg, err)
 	}
-	db := database.GetGorm()
-	policyRows, err := db.Raw(policyListQuery).Rows()
+	db := database.GetReadGorm()
+	policyRows, err := db.Raw(policyListQuery, args...).Rows()
 	if err != nil {
 		fmt.Fprintf(gin.DefaultWriter, QueryPoliciesFailureFormatMsg, err)
+		return
 	}
 
 	defer policyRows.Close()
@@ -203,7 +182,7 @@ func doHandlePoliciesForWatch(ctx context.Context, writer gin.ResponseWriter,
 			continue
 		}
 
-		if err := json.Unmarshal(payload, policy); err != nil {
+		if err := util.UnmarshalPayload(payload, policy); err != nil {
 			fmt.Fprintf(gin.DefaultWriter, "error in scanning a policyPayload: %v\n", err)
 			continue
 		}
@@ -292,11 +271,11 @@ func sendPolicyWatchEvent(writer io.Writer, policy *policyv1.Policy, eventType,
 	}, writer)
 }
 
-func handlePolicies(ginCtx *gin.Context, policyListQuery, lastPolicyQuery,
+func handlePolicies(ginCtx *gin.Context, policyListQuery string, args []interface{}, lastPolicyQuery,
 	policyMappingQuery, policyComplianceQuery string,
 	customResourceColumnDefinitions []apiextensionsv1.CustomResourceColumnDefinition,
 ) {
-	db := database.GetGorm()
+	db := database.GetReadGorm()
 	lastPolicy := &policyv1.Policy{}
 	lastPolicyID := ""
 	var lastPolicyPayload []byte
@@ -308,7 +287,7 @@ func handlePolicies(ginCtx *gin.Context, policyListQuery, lastPolicyQuery,
 	}
 
 	if err == nil {
-		if e := json.Unmarshal(lastPolicyPayload, lastPolicy); e != nil {
+		if e := util.UnmarshalPayload(lastPolicyPayload, lastPolicy); e != nil {
 			fmt.Fprintf(gin.DefaultWriter, "error in querying last policy payload: %v\n", e)
 			return
 		}
@@ -320,10 +299,11 @@ func handlePolicies(ginCtx *gin.Context, policyListQuery, lastPolicyQuery,
 		fmt.Fprintf(gin.DefaultWriter, QueryPolicyMappingFailureFormatMsg, err)
 	}
 
-	policyRows, err := db.Raw(policyListQuery).Rows()
+	policyRows, err := db.Raw(policyListQuery, args...).Rows()
 	if err != nil {
 		ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
 		fmt.Fprintf(gin.DefaultWriter, QueryPoliciesFailureFormatMsg, err)
+		return
 	}
 	defer policyRows.Close()
 
@@ -342,7 +322,7 @@ func handlePolicies(ginCtx *gin.Context, policyListQuery, lastPolicyQuery,
 			continue
 		}
 		policy := &policyv1.Policy{}
-		if err := json.Unmarshal(policyPayload, policy); err != nil {
+		if err := util.UnmarshalPayload(policyPayload, policy); err != nil {
 			fmt.Fprintf(gin.DefaultWriter, "error in Unmarshal a policyPayload : %v\n", err)
 			continue
 		}
@@ -414,7 +394,7 @@ func handlePolicies(ginCtx *gin.Context, policyListQuery, lastPolicyQuery,
 func getPolicyMatches(policyMappingQuery string) ([]*policyMatch, error) {
 	policyMatches := []*policyMatch{}
 
-	db := database.GetGorm()
+	db := database.GetReadGorm()
 	policyMatchRows, err := db.Raw(policyMappingQuery).Rows()
 	if err != nil {
 		return policyMatches,
@@ -444,7 +424,7 @@ func getComplianceStatus(policyComplianceQuery, policyID string,
 	compliancePerClusterStatuses := []*policyv1.CompliancePerClusterStatus{}
 	hasNonCompliantClusters := false
 
-	db := database.GetGorm()
+	db := database.GetReadGorm()
 	var statusCompliances []models.StatusCompliance
 	err := db.Where(&models.StatusCompliance{
 		PolicyID: policyID,
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package policies
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+// PolicyRollout is the rollout state of the global policy
+type PolicyRollout struct {
+	PolicyID    string          `json:"policyID"`
+	Generation  int64           `json:"generation"`
+	Strategy    json.RawMessage `json:"strategy"`
+	Phase       string          `json:"phase"`
+	CurrentWave int             `json:"currentWave"`
+	Waves       json.RawMessage `json:"waves"`
+	Message     string          `json:"message,omitempty"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// GetPolicyRollout godoc
+// @summary get policy rollout
+// @description get the wave rollout state with a given policy
+// @accept json
+// @produce json
+// @param        policyID    path    string    true    "Policy ID"
+// @success      200  {object}  PolicyRollout
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      404
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /policy/{policyID}/rollout [get]
+func GetPolicyRollout() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		policyID := ginCtx.Param("policyID")
+		fmt.Fprintf(gin.DefaultWriter, "getting rollout for policy: %s\n", policyID)
+
+		policyRollout := &models.PolicyRollout{}
+		err := database.GetReadGorm().Where("policy_id = ?", policyID).First(policyRollout).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ginCtx.String(http.StatusNotFound, "no rollout for policy: %s", policyID)
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, QueryPolicyRolloutFailureFormatMsg, err)
+			ginCtx.String(http.StatusInternalServerError, ServerInternalErrorMsg)
+			return
+		}
+
+		ginCtx.JSON(http.StatusOK, PolicyRollout{
+			PolicyID:    policyRollout.PolicyID,
+			Generation:  policyRollout.Generation,
+			Strategy:    json.RawMessage(policyRollout.Strategy),
+			Phase:       policyRollout.Phase,
+			CurrentWave: policyRollout.CurrentWave,
+			Waves:       json.RawMessage(policyRollout.Waves),
+			Message:     policyRollout.Message,
+			UpdatedAt:   policyRollout.UpdatedAt,
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package resyncs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/audit"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/resync"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+const (
+	serverInternalErrorMsg = "internal error"
+	defaultListLimit       = 20
+)
+
+// ResyncRequest requests the managed hubs to resend the status bundles
+type ResyncRequest struct {
+	// the managed hubs to resync, empty means all the active hubs
+	LeafHubs []string `json:"leafHubs"`
+	// the bundle keys to resync, e.g. ManagedClusters, empty means the default bundles and "*" means all the bundles
+	BundleKeys []string `json:"bundleKeys"`
+	// the duration to wait for the bundles, e.g. 10m
+	Timeout string `json:"timeout"`
+}
+
+// Resync is the resync and its progress on each managed hub
+type Resync struct {
+	ID          string          `json:"id"`
+	LeafHubs    json.RawMessage `json:"leafHubs"`
+	BundleKeys  json.RawMessage `json:"bundleKeys"`
+	Trigger     string          `json:"trigger"`
+	Timeout     string          `json:"timeout"`
+	Phase       string          `json:"phase"`
+	Message     string          `json:"message,omitempty"`
+	Progress    json.RawMessage `json:"progress,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	StartedAt   *time.Time      `json:"startedAt,omitempty"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty"`
+}
+
+// CreateResync godoc
+// @summary create resync
+// @description request the managed hubs to resend the status bundles, the progress is tracked by the resync
+// @accept json
+// @produce json
+// @param        resync    body    ResyncRequest    true    "The hubs and the bundles to resync"
+// @success      201  {object}  Resync
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /resyncs [post]
+func CreateResync() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		request := &ResyncRequest{}
+		if err := ginCtx.BindJSON(request); err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to bind: %s\n", err.Error())
+			return
+		}
+		timeout := resync.DefaultTimeout
+		if request.Timeout != "" {
+			var err error
+			if timeout, err = time.ParseDuration(request.Timeout); err != nil || timeout <= 0 {
+				ginCtx.String(http.StatusBadRequest, "invalid timeout: %s", request.Timeout)
+				return
+			}
+		}
+		for _, key := range request.BundleKeys {
+			if key == "" {
+				ginCtx.String(http.StatusBadRequest, "the bundle key is empty")
+				return
+			}
+		}
+
+		var created *models.Resync
+		err := database.GetGorm().WithContext(ginCtx.Request.Context()).Transaction(func(tx *gorm.DB) error {
+			var err error
+			if created, err = resync.Create(tx, request.LeafHubs, request.BundleKeys, resync.TriggerAPI,
+				timeout); err != nil {
+				return err
+			}
+			return util.RecordAudit(ginCtx, tx, &audit.Entry{
+				Action: audit.ActionCreate,
+				Kind:   "Resync",
+				Name:   created.ID,
+				ID:     created.ID,
+				After:  request,
+			})
+		})
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in creating resync: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+		fmt.Fprintf(gin.DefaultWriter, "created resync: %s\n", created.ID)
+		ginCtx.JSON(http.StatusCreated, toResync(created))
+	}
+}
+
+// ListResyncs godoc
+// @summary list resyncs
+// @description list the latest resyncs
+// @accept json
+// @produce json
+// @param        limit    query    int    false    "Maximum number of resyncs to receive, default 20"
+// @success      200  {array}  Resync
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /resyncs [get]
+func ListResyncs() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		limit := defaultListLimit
+		if value := ginCtx.Query("limit"); value != "" {
+			var err error
+			if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+				ginCtx.String(http.StatusBadRequest, "invalid limit: %s", value)
+				return
+			}
+		}
+
+		var resyncs []models.Resync
+		if err := database.GetReadGorm().Order("created_at DESC").Limit(limit).Find(&resyncs).Error; err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in querying resyncs: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+		result := make([]Resync, 0, len(resyncs))
+		for i := range resyncs {
+			result = append(result, toResync(&resyncs[i]))
+		}
+		ginCtx.JSON(http.StatusOK, result)
+	}
+}
+
+// GetResync godoc
+// @summary get resync
+// @description get the progress of the resync on each managed hub
+// @accept json
+// @produce json
+// @param        resyncID    path    string    true    "Resync ID"
+// @success      200  {object}  Resync
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      404
+// @failure      500
+// @failure      503
+// @security     ApiKeyAuth
+// @router /resync/{resyncID} [get]
+func GetResync() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		resyncID := ginCtx.Param("resyncID")
+
+		found := &models.Resync{}
+		err := database.GetGorm().Where("id = ?", resyncID).First(found).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ginCtx.String(http.StatusNotFound, "resync not found: %s", resyncID)
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in querying resync: %v\n", err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+		ginCtx.JSON(http.StatusOK, toResync(found))
+	}
+}
+
+func toResync(resync *models.Resync) Resync {
+	return Resync{
+		ID:          resync.ID,
+		LeafHubs:    json.RawMessage(resync.LeafHubs),
+		BundleKeys:  json.RawMessage(resync.BundleKeys),
+		Trigger:     resync.Trigger,
+		Timeout:     (time.Duration(resync.TimeoutSeconds) * time.Second).String(),
+		Phase:       resync.Phase,
+		Message:     resync.Message,
+		Progress:    json.RawMessage(resync.Progress),
+		CreatedAt:   resync.CreatedAt,
+		StartedAt:   resync.StartedAt,
+		CompletedAt: resync.CompletedAt,
+	}
+}
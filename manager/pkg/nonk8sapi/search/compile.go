@@ -0,0 +1,412 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// payloadRoots are the top level fields of the stored payloads which can be searched
+var payloadRoots = map[string]bool{
+	"apiVersion": true,
+	"kind":       true,
+	"metadata":   true,
+	"spec":       true,
+	"status":     true,
+}
+
+const (
+	// payloadColumn is the document of the resource, the predicate of exists() is compiled over the elements instead
+	payloadColumn = "payload"
+	// leafHubsField selects the joined fields of a managed hub, e.g. leafHubs["hub1"].nonCompliantClusters
+	leafHubsField = "leafHubs"
+	// versionPattern matches the numeric parts of the version, e.g. 4.14 of v4.14.1-rc.1, the parts are limited to 9
+	// digits to be cast into integers
+	versionPattern = `^v?([0-9]{1,9}(?:\.[0-9]{1,9})*)`
+)
+
+// joinedField is a field which isn't in the payload, e.g. the managed hub of the resource or the status joined from
+// the other tables, the args are bound to the placeholders of the column
+type joinedField struct {
+	column  string
+	numeric bool
+	args    []interface{}
+}
+
+// kind describes how the searchable resources are stored
+type kind struct {
+	table         string
+	idColumn      string
+	nameColumn    string
+	leafHubColumn string
+	// conditions selects the resources which aren't deleted
+	conditions string
+	fields     map[string]joinedField
+	// hubFields are the joined fields of the managed hub bound to the first placeholder of the column
+	hubFields map[string]joinedField
+}
+
+// kinds are the searchable resources, the compliance counts are joined from the compliance status of the policies
+var kinds = map[string]*kind{
+	"managedclusters": {
+		table:         "status.managed_clusters",
+		idColumn:      "cluster_id",
+		nameColumn:    "cluster_name",
+		leafHubColumn: "leaf_hub_name",
+		conditions:    "deleted_at IS NULL",
+		fields: map[string]joinedField{
+			"leafHubName": {column: "leaf_hub_name"},
+			// the status of the available condition, True, False or Unknown
+			"available": {column: "COALESCE((SELECT c ->> 'status' " +
+				"FROM jsonb_array_elements(payload -> 'status' -> 'conditions') c " +
+				"WHERE c ->> 'type' = 'ManagedClusterConditionAvailable' LIMIT 1), 'Unknown')"},
+		},
+	},
+	"localpolicies": {
+		table:         "local_spec.policies",
+		idColumn:      "policy_id",
+		nameColumn:    "policy_name",
+		leafHubColumn: "leaf_hub_name",
+		conditions:    "deleted_at IS NULL",
+		fields: complianceFields("local_status.compliance", "local_spec.policies.policy_id",
+			"", map[string]joinedField{"leafHubName": {column: "leaf_hub_name"}}),
+	},
+	"policies": {
+		table:         "spec.policies",
+		idColumn:      "id",
+		nameColumn:    "payload -> 'metadata' ->> 'name'",
+		leafHubColumn: "''",
+		conditions:    "deleted = FALSE",
+		fields:        complianceFields("status.compliance", "spec.policies.id", "", map[string]joinedField{}),
+		hubFields: complianceFields("status.compliance", "spec.policies.id", " AND c.leaf_hub_name = ?",
+			map[string]joinedField{}),
+	},
+}
+
+// complianceFields adds the numbers of the compliant, non compliant and unknown clusters of the policy to the fields,
+// the filter narrows the compliance, e.g. to the clusters of a managed hub
+func complianceFields(table, policyColumn, filter string, fields map[string]joinedField) map[string]joinedField {
+	for field, compliance := range map[string]string{
+		"compliantClusters":    "compliant",
+		"nonCompliantClusters": "non_compliant",
+		"unknownClusters":      "unknown",
+	} {
+		fields[field] = joinedField{
+			column: fmt.Sprintf("(SELECT count(*) FROM %s c WHERE c.policy_id = %s AND c.compliance = '%s'%s)",
+				table, policyColumn, compliance, filter),
+			numeric: true,
+		}
+	}
+	return fields
+}
+
+func kindNames() []string {
+	names := make([]string, 0, len(kinds))
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// compile translates the search expression into the parameterized sql condition, none of the literals or the keys
+// of the expression are interpolated into the condition. The equality on the payload is compiled into the jsonb
+// containment, so that it's served by the gin index of the payload.
+func (k *kind) compile(expr expression) (string, []interface{}, error) {
+	return k.compileIn(expr, payloadColumn, 0)
+}
+
+// compileIn compiles the expression over the document, which is the payload or the element of the array selected by
+// exists(), the depth is the nesting of exists() which names the elements. The joined fields are only supported
+// over the payload.
+func (k *kind) compileIn(expr expression, doc string, depth int) (string, []interface{}, error) {
+	switch e := expr.(type) {
+	case *logicalExpression:
+		left, leftArgs, err := k.compileIn(e.left, doc, depth)
+		if err != nil {
+			return "", nil, err
+		}
+		right, rightArgs, err := k.compileIn(e.right, doc, depth)
+		if err != nil {
+			return "", nil, err
+		}
+		operator := "OR"
+		if e.and {
+			operator = "AND"
+		}
+		return fmt.Sprintf("(%s %s %s)", left, operator, right), append(leftArgs, rightArgs...), nil
+	case *notExpression:
+		operand, args, err := k.compileIn(e.operand, doc, depth)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("NOT (%s)", operand), args, nil
+	case *compareExpression:
+		if field, ok := k.joinedField(e.field, depth); ok {
+			if e.version {
+				return "", nil, fmt.Errorf("version() isn't supported by the field %s", e.field)
+			}
+			return compileJoinedComparison(e, field)
+		}
+		if err := k.validatePath(e.field, depth); err != nil {
+			return "", nil, err
+		}
+		if e.version {
+			return compileVersionComparison(e, doc)
+		}
+		return compilePayloadComparison(e, doc)
+	case *callExpression:
+		if field, ok := k.joinedField(e.field, depth); ok {
+			if e.function == functionHas || field.numeric {
+				return "", nil, fmt.Errorf("%s isn't supported by the field %s", e.function, e.field)
+			}
+			return fmt.Sprintf("%s LIKE ?", field.column), append(append([]interface{}{}, field.args...),
+				likePattern(e)), nil
+		}
+		if err := k.validatePath(e.field, depth); err != nil {
+			return "", nil, err
+		}
+		path, pathArgs := payloadPath(e.field)
+		if e.function == functionHas {
+			return fmt.Sprintf("%s #> %s IS NOT NULL", doc, path), pathArgs, nil
+		}
+		return fmt.Sprintf("COALESCE(%s #>> %s LIKE ?, FALSE)", doc, path), append(pathArgs, likePattern(e)), nil
+	case *existsExpression:
+		if _, ok := k.joinedField(e.field, depth); ok {
+			return "", nil, fmt.Errorf("exists isn't supported by the field %s", e.field)
+		}
+		if err := k.validatePath(e.field, depth); err != nil {
+			return "", nil, err
+		}
+		element := fmt.Sprintf("e%d", depth+1)
+		predicate, predicateArgs, err := k.compileIn(e.predicate, element+".value", depth+1)
+		if err != nil {
+			return "", nil, err
+		}
+		// the fields which aren't arrays have no elements, so that the query doesn't fail on them
+		path, pathArgs := payloadPath(e.field)
+		args := append(append(append([]interface{}{}, pathArgs...), pathArgs...), predicateArgs...)
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(%s #> %s) = 'array' "+
+			"THEN %s #> %s ELSE '[]'::jsonb END) AS %s(value) WHERE %s)", doc, path, doc, path, element,
+			predicate), args, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported expression %T", expr)
+	}
+}
+
+// joinedField returns the joined field of the payload, e.g. leafHubName, or the field of a managed hub, e.g.
+// leafHubs["hub1"].nonCompliantClusters, the fields of the elements in exists() are never joined.
+func (k *kind) joinedField(field *fieldPath, depth int) (joinedField, bool) {
+	if depth > 0 {
+		return joinedField{}, false
+	}
+	if joined, ok := k.fields[field.String()]; ok {
+		return joined, true
+	}
+	segments := field.segments
+	if len(segments) != 3 || segments[0].key != leafHubsField || segments[1].isIndex || segments[2].isIndex {
+		return joinedField{}, false
+	}
+	joined, ok := k.hubFields[segments[2].key]
+	if !ok {
+		return joinedField{}, false
+	}
+	joined.args = []interface{}{segments[1].key}
+	return joined, true
+}
+
+// validatePath verifies the field of the payload is under the top level fields, the fields of the elements in
+// exists() are relative to the elements, so they aren't verified
+func (k *kind) validatePath(field *fieldPath, depth int) error {
+	if depth > 0 || payloadRoots[field.segments[0].key] {
+		return nil
+	}
+	names := make([]string, 0, len(k.fields)+len(k.hubFields))
+	for name := range k.fields {
+		names = append(names, name)
+	}
+	for name := range k.hubFields {
+		names = append(names, fmt.Sprintf("%s[\"<hub>\"].%s", leafHubsField, name))
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unsupported field %s, the field must be in apiVersion, kind, metadata, spec or status, "+
+		"or one of: %s", field, strings.Join(names, ", "))
+}
+
+func compileJoinedComparison(e *compareExpression, field joinedField) (string, []interface{}, error) {
+	for _, value := range e.values {
+		switch value.value.(type) {
+		case string:
+			if !field.numeric {
+				continue
+			}
+		case json.Number:
+			if field.numeric {
+				continue
+			}
+		}
+		if field.numeric {
+			return "", nil, fmt.Errorf("the field %s must be compared with a number", e.field)
+		}
+		return "", nil, fmt.Errorf("the field %s must be compared with a string", e.field)
+	}
+
+	column, placeholder := field.column, "?"
+	if field.numeric {
+		placeholder = "?::numeric"
+	}
+	// the args of the column are bound before the literal in every condition
+	argsOf := func(value literal) []interface{} {
+		return append(append([]interface{}{}, field.args...), literalArg(value))
+	}
+	switch e.operator {
+	case operatorIn:
+		conditions, args := make([]string, 0, len(e.values)), make([]interface{}, 0, len(e.values))
+		for _, value := range e.values {
+			conditions = append(conditions, fmt.Sprintf("%s = %s", column, placeholder))
+			args = append(args, argsOf(value)...)
+		}
+		return "(" + strings.Join(conditions, " OR ") + ")", args, nil
+	case operatorEqual:
+		return fmt.Sprintf("%s = %s", column, placeholder), argsOf(e.values[0]), nil
+	case operatorNotEqual:
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", column, placeholder), argsOf(e.values[0]), nil
+	default:
+		return fmt.Sprintf("%s %s %s", column, e.operator, placeholder), argsOf(e.values[0]), nil
+	}
+}
+
+// compileVersionComparison compares the numeric parts of the versions as the integer arrays, e.g. "4.9" < "4.14",
+// the values which aren't versions aren't selected, so that the query doesn't fail on the casts
+func compileVersionComparison(e *compareExpression, doc string) (string, []interface{}, error) {
+	value := e.values[0].value.(string)
+	parts := strings.Split(strings.TrimPrefix(value, "v"), ".")
+	for _, part := range parts {
+		if len(part) == 0 || len(part) > 9 || strings.Trim(part, "0123456789") != "" {
+			return "", nil, fmt.Errorf("invalid version %q of the field %s, the version must be the numbers "+
+				"separated by dots, e.g. 4.14", value, e.field)
+		}
+	}
+	operator := e.operator
+	switch operator {
+	case operatorEqual:
+		operator = "="
+	case operatorNotEqual:
+		operator = "<>"
+	}
+	path, pathArgs := payloadPath(e.field)
+	args := append(append([]interface{}{}, pathArgs...), versionPattern)
+	args = append(append(args, pathArgs...), versionPattern, "{"+strings.Join(parts, ",")+"}")
+	return fmt.Sprintf("(CASE WHEN %s #>> %s ~ ? THEN string_to_array(substring(%s #>> %s from ?), '.')::int[] %s "+
+		"?::int[] ELSE FALSE END)", doc, path, doc, path, operator), args, nil
+}
+
+func compilePayloadComparison(e *compareExpression, doc string) (string, []interface{}, error) {
+	switch e.operator {
+	case operatorIn:
+		conditions, args := make([]string, 0, len(e.values)), []interface{}{}
+		for _, value := range e.values {
+			condition, conditionArgs, err := payloadEqual(e.field, value, doc)
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, condition)
+			args = append(args, conditionArgs...)
+		}
+		return "(" + strings.Join(conditions, " OR ") + ")", args, nil
+	case operatorEqual:
+		return payloadEqual(e.field, e.values[0], doc)
+	case operatorNotEqual:
+		// the resources without the field are also selected
+		condition, args, err := payloadEqual(e.field, e.values[0], doc)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT " + condition, args, nil
+	}
+
+	// the values of the other types aren't selected, so that the query doesn't fail on the casts
+	path, pathArgs := payloadPath(e.field)
+	switch value := e.values[0].value.(type) {
+	case json.Number:
+		return fmt.Sprintf("(CASE WHEN jsonb_typeof(%s #> %s) = 'number' THEN (%s #>> %s)::numeric %s "+
+				"?::numeric ELSE FALSE END)", doc, path, doc, path, e.operator),
+			append(append(pathArgs, pathArgs...), value.String()), nil
+	case string:
+		return fmt.Sprintf("(CASE WHEN jsonb_typeof(%s #> %s) = 'string' THEN %s #>> %s %s ? "+
+				"ELSE FALSE END)", doc, path, doc, path, e.operator),
+			append(append(pathArgs, pathArgs...), value), nil
+	default:
+		return "", nil, fmt.Errorf("the operator %s of the field %s only supports numbers and strings",
+			e.operator, e.field)
+	}
+}
+
+// payloadEqual compares the field of the document with the literal, the null literal selects the resources without
+// the field. The fields without the array indexes are compared by the containment.
+func payloadEqual(field *fieldPath, value literal, doc string) (string, []interface{}, error) {
+	path, pathArgs := payloadPath(field)
+	if value.value == nil {
+		return fmt.Sprintf("COALESCE(%s #> %s, 'null'::jsonb) = 'null'::jsonb", doc, path), pathArgs, nil
+	}
+	for _, segment := range field.segments {
+		if segment.isIndex {
+			encoded, err := json.Marshal(value.value)
+			if err != nil {
+				return "", nil, err
+			}
+			return fmt.Sprintf("COALESCE(%s #> %s = ?::jsonb, FALSE)", doc, path),
+				append(pathArgs, string(encoded)), nil
+		}
+	}
+
+	var contained interface{} = value.value
+	for i := len(field.segments) - 1; i >= 0; i-- {
+		contained = map[string]interface{}{field.segments[i].key: contained}
+	}
+	encoded, err := json.Marshal(contained)
+	if err != nil {
+		return "", nil, err
+	}
+	return doc + " @> ?::jsonb", []interface{}{string(encoded)}, nil
+}
+
+// payloadPath returns the text array of the keys and the indexes of the field, e.g. ARRAY[?::text, ?::text]
+func payloadPath(field *fieldPath) (string, []interface{}) {
+	placeholders := make([]string, 0, len(field.segments))
+	args := make([]interface{}, 0, len(field.segments))
+	for _, segment := range field.segments {
+		placeholders = append(placeholders, "?::text")
+		if segment.isIndex {
+			args = append(args, strconv.Itoa(segment.index))
+		} else {
+			args = append(args, segment.key)
+		}
+	}
+	return "ARRAY[" + strings.Join(placeholders, ", ") + "]", args
+}
+
+// likePattern escapes the wildcards of the argument of the string methods
+func likePattern(e *callExpression) string {
+	value := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(e.argument.value.(string))
+	switch e.function {
+	case functionStartsWith:
+		return value + "%"
+	case functionEndsWith:
+		return "%" + value
+	default:
+		return "%" + value + "%"
+	}
+}
+
+func literalArg(value literal) interface{} {
+	if number, ok := value.value.(json.Number); ok {
+		return number.String()
+	}
+	return value.value
+}
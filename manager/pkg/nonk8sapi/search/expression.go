@@ -0,0 +1,604 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxQueryLength is the maximum length of the search expression
+	maxQueryLength = 4096
+	// maxTerms is the maximum number of the operators, fields and literals of the search expression
+	maxTerms = 256
+	// maxDepth is the maximum nesting of the parentheses and negations of the search expression
+	maxDepth = 16
+)
+
+// the functions supported by the search expression, has(field), version(field) and exists(field, predicate) are the
+// global functions and the others are the methods of the string fields, e.g. metadata.name.startsWith("prod-")
+const (
+	functionHas        = "has"
+	functionVersion    = "version"
+	functionExists     = "exists"
+	functionStartsWith = "startsWith"
+	functionEndsWith   = "endsWith"
+	functionContains   = "contains"
+)
+
+// the operators of the comparison expression
+const (
+	operatorEqual        = "=="
+	operatorNotEqual     = "!="
+	operatorLess         = "<"
+	operatorLessEqual    = "<="
+	operatorGreater      = ">"
+	operatorGreaterEqual = ">="
+	operatorIn           = "in"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	typ   tokenType
+	value string
+	pos   int
+}
+
+// expression is a node of the parsed search expression
+type expression interface{}
+
+// logicalExpression is "left && right" or "left || right"
+type logicalExpression struct {
+	and         bool
+	left, right expression
+}
+
+// notExpression is "!operand"
+type notExpression struct {
+	operand expression
+}
+
+// compareExpression compares the field with the literal, the "in" operator has one or more literals
+type compareExpression struct {
+	field    *fieldPath
+	operator string
+	values   []literal
+	// version compares the numeric parts of the version in the field, e.g. version(field) < "4.14"
+	version bool
+}
+
+// existsExpression is exists(field, predicate), which selects the resources with any element of the array field
+// matching the predicate, the fields of the predicate are relative to the element
+type existsExpression struct {
+	field     *fieldPath
+	predicate expression
+}
+
+// callExpression is has(field) or the string method of the field, e.g. field.contains("value")
+type callExpression struct {
+	function string
+	field    *fieldPath
+	argument *literal
+}
+
+// fieldPath is a path into the stored payload, e.g. metadata.labels["env"], or the name of a joined field,
+// e.g. leafHubName
+type fieldPath struct {
+	segments []pathSegment
+}
+
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// literal is a string, a json.Number, a bool or nil
+type literal struct {
+	value interface{}
+}
+
+// parseExpression parses the search expression, e.g.
+// metadata.labels["env"] == "prod" && (available != "True" || spec.hubAcceptsClient == false)
+func parseExpression(query string) (expression, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("the query is empty")
+	}
+	if len(query) > maxQueryLength {
+		return nil, fmt.Errorf("the query exceeds the maximum length of %d", maxQueryLength)
+	}
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.typ != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", next.value, next.pos)
+	}
+	return expr, nil
+}
+
+func tokenize(query string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			start := i
+			for i < len(query) && (isIdentStart(query[i]) || isDigit(query[i])) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenIdent, value: query[start:i], pos: start})
+		case isDigit(c) || (c == '-' && i+1 < len(query) && isDigit(query[i+1])):
+			start := i
+			i++
+			for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+				i++
+			}
+			if _, err := strconv.ParseFloat(query[start:i], 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", query[start:i], start)
+			}
+			tokens = append(tokens, token{typ: tokenNumber, value: query[start:i], pos: start})
+		case c == '"' || c == '\'':
+			value, end, err := readString(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{typ: tokenString, value: value, pos: i})
+			i = end
+		default:
+			punct := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]",
+				",", "."} {
+				if strings.HasPrefix(query[i:], candidate) {
+					punct = candidate
+					break
+				}
+			}
+			if punct == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{typ: tokenPunct, value: punct, pos: i})
+			i += len(punct)
+		}
+	}
+	return append(tokens, token{typ: tokenEOF, pos: len(query)}), nil
+}
+
+// readString reads the quoted string starting at the position, it returns the unquoted value and the position
+// after the closing quote
+func readString(query string, start int) (string, int, error) {
+	quote := query[start]
+	var value strings.Builder
+	for i := start + 1; i < len(query); i++ {
+		switch c := query[i]; c {
+		case quote:
+			return value.String(), i + 1, nil
+		case '\\':
+			if i+1 == len(query) {
+				return "", 0, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			switch escaped := query[i]; escaped {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case '\\', '"', '\'':
+				value.WriteByte(escaped)
+			default:
+				return "", 0, fmt.Errorf("invalid escape \\%c at position %d", escaped, i-1)
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string at position %d", start)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parser is a recursive descent parser of the search expression:
+//
+//	or      := and ("||" and)*
+//	and     := unary ("&&" unary)*
+//	unary   := "!" unary | primary
+//	primary := "(" or ")" | "has" "(" field ")" | "exists" "(" field "," or ")" |
+//	           "version" "(" field ")" operator string | field "." method "(" string ")" |
+//	           field operator literal | field "in" "[" literal ("," literal)* "]"
+//	field   := ident ("." ident | "[" string "]" | "[" integer "]")*
+type parser struct {
+	tokens   []token
+	position int
+	terms    int
+	depth    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.position]
+	if t.typ != tokenEOF {
+		p.position++
+	}
+	return t
+}
+
+func (p *parser) expect(punct string) error {
+	if t := p.next(); t.typ != tokenPunct || t.value != punct {
+		return unexpected(t, punct)
+	}
+	return nil
+}
+
+// count limits the size of the expression, so that the compiled query stays cheap to plan
+func (p *parser) count() error {
+	p.terms++
+	if p.terms > maxTerms {
+		return fmt.Errorf("the query exceeds the maximum of %d terms", maxTerms)
+	}
+	return nil
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return fmt.Errorf("the query exceeds the maximum nesting of %d", maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.typ == tokenPunct && t.value == "||"; t = p.peek() {
+		p.next()
+		if err := p.count(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpression{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.typ == tokenPunct && t.value == "&&"; t = p.peek() {
+		p.next()
+		if err := p.count(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpression{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	if t := p.peek(); t.typ == tokenPunct && t.value == "!" {
+		p.next()
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		if err := p.count(); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		p.depth--
+		return &notExpression{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expression, error) {
+	t := p.peek()
+	if t.typ == tokenPunct && t.value == "(" {
+		p.next()
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		p.depth--
+		return expr, nil
+	}
+	if t.typ != tokenIdent {
+		return nil, unexpected(t, "a field")
+	}
+	if err := p.count(); err != nil {
+		return nil, err
+	}
+
+	if t.value == functionHas && p.tokens[p.position+1].value == "(" {
+		p.next()
+		p.next()
+		field, method, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		if method != "" {
+			return nil, fmt.Errorf("unexpected method %s in has() at position %d", method, t.pos)
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &callExpression{function: functionHas, field: field}, nil
+	}
+	if t.value == functionVersion && p.tokens[p.position+1].value == "(" {
+		return p.parseVersion()
+	}
+	if t.value == functionExists && p.tokens[p.position+1].value == "(" {
+		return p.parseExists()
+	}
+
+	field, method, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+	if method != "" {
+		return p.parseMethod(field, method)
+	}
+
+	operator := p.next()
+	switch {
+	case operator.typ == tokenIdent && operator.value == operatorIn:
+		return p.parseIn(field)
+	case operator.typ == tokenPunct && isComparison(operator.value):
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return &compareExpression{field: field, operator: operator.value, values: []literal{value}}, nil
+	default:
+		return nil, unexpected(operator, "an operator")
+	}
+}
+
+// parseField parses the field path, it stops at the method call and returns the name of the method
+func (p *parser) parseField() (*fieldPath, string, error) {
+	t := p.next()
+	if t.typ != tokenIdent {
+		return nil, "", unexpected(t, "a field")
+	}
+	field := &fieldPath{segments: []pathSegment{{key: t.value}}}
+	for {
+		t = p.peek()
+		if t.typ != tokenPunct {
+			return field, "", nil
+		}
+		switch t.value {
+		case ".":
+			p.next()
+			key := p.next()
+			if key.typ != tokenIdent {
+				return nil, "", unexpected(key, "a field")
+			}
+			if next := p.peek(); next.typ == tokenPunct && next.value == "(" {
+				return field, key.value, nil
+			}
+			field.segments = append(field.segments, pathSegment{key: key.value})
+		case "[":
+			p.next()
+			key := p.next()
+			switch key.typ {
+			case tokenString:
+				field.segments = append(field.segments, pathSegment{key: key.value})
+			case tokenNumber:
+				index, err := strconv.Atoi(key.value)
+				if err != nil || index < 0 {
+					return nil, "", fmt.Errorf("invalid index %s at position %d", key.value, key.pos)
+				}
+				field.segments = append(field.segments, pathSegment{index: index, isIndex: true})
+			default:
+				return nil, "", unexpected(key, "a key or an index")
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, "", err
+			}
+		default:
+			return field, "", nil
+		}
+	}
+}
+
+// parseVersion parses version(field) operator "version", the version literal is validated by the compiler
+func (p *parser) parseVersion() (expression, error) {
+	start := p.next()
+	p.next()
+	field, method, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+	if method != "" {
+		return nil, fmt.Errorf("unexpected method %s in version() at position %d", method, start.pos)
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	operator := p.next()
+	if operator.typ != tokenPunct || !isComparison(operator.value) {
+		return nil, unexpected(operator, "a comparison operator")
+	}
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.value.(string); !ok {
+		return nil, fmt.Errorf("version() must be compared with a string at position %d", start.pos)
+	}
+	return &compareExpression{field: field, operator: operator.value, values: []literal{value}, version: true}, nil
+}
+
+// parseExists parses exists(field, predicate), the predicate is nested like the parentheses
+func (p *parser) parseExists() (expression, error) {
+	start := p.next()
+	p.next()
+	field, method, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+	if method != "" {
+		return nil, fmt.Errorf("unexpected method %s in exists() at position %d", method, start.pos)
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	predicate, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	p.depth--
+	return &existsExpression{field: field, predicate: predicate}, nil
+}
+
+func (p *parser) parseMethod(field *fieldPath, method string) (expression, error) {
+	switch method {
+	case functionStartsWith, functionEndsWith, functionContains:
+	default:
+		return nil, fmt.Errorf("unsupported method %s, the supported methods: %s, %s, %s", method,
+			functionStartsWith, functionEndsWith, functionContains)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	argument, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := argument.value.(string); !ok {
+		return nil, fmt.Errorf("the argument of %s must be a string", method)
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return &callExpression{function: method, field: field, argument: &argument}, nil
+}
+
+func (p *parser) parseIn(field *fieldPath) (expression, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	values := []literal{}
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		t := p.next()
+		if t.typ == tokenPunct && t.value == "]" {
+			break
+		}
+		if t.typ != tokenPunct || t.value != "," {
+			return nil, unexpected(t, "',' or ']'")
+		}
+	}
+	return &compareExpression{field: field, operator: operatorIn, values: values}, nil
+}
+
+func (p *parser) parseLiteral() (literal, error) {
+	if err := p.count(); err != nil {
+		return literal{}, err
+	}
+	t := p.next()
+	switch t.typ {
+	case tokenString:
+		return literal{value: t.value}, nil
+	case tokenNumber:
+		return literal{value: json.Number(t.value)}, nil
+	case tokenIdent:
+		switch t.value {
+		case "true":
+			return literal{value: true}, nil
+		case "false":
+			return literal{value: false}, nil
+		case "null":
+			return literal{value: nil}, nil
+		}
+	}
+	return literal{}, unexpected(t, "a literal")
+}
+
+func isComparison(operator string) bool {
+	switch operator {
+	case operatorEqual, operatorNotEqual, operatorLess, operatorLessEqual, operatorGreater, operatorGreaterEqual:
+		return true
+	}
+	return false
+}
+
+func unexpected(t token, expected string) error {
+	if t.typ == tokenEOF {
+		return fmt.Errorf("unexpected end of the query, expected %s", expected)
+	}
+	return fmt.Errorf("unexpected %q at position %d, expected %s", t.value, t.pos, expected)
+}
+
+// String returns the field in the form of the expression, e.g. metadata.labels["env"]
+func (f *fieldPath) String() string {
+	var builder strings.Builder
+	for i, segment := range f.segments {
+		switch {
+		case segment.isIndex:
+			fmt.Fprintf(&builder, "[%d]", segment.index)
+		case i == 0:
+			builder.WriteString(segment.key)
+		default:
+			fmt.Fprintf(&builder, "[%q]", segment.key)
+		}
+	}
+	return builder.String()
+}
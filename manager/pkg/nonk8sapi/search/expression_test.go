@@ -0,0 +1,204 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileExpression(t *testing.T) {
+	cases := []struct {
+		name      string
+		kind      string
+		query     string
+		condition string
+		args      []interface{}
+		wantErr   string
+	}{
+		{
+			name:      "equality on the payload is compiled into the containment",
+			kind:      "managedclusters",
+			query:     `metadata.labels["cloud"] == "Amazon" && spec.hubAcceptsClient == true`,
+			condition: "(payload @> ?::jsonb AND payload @> ?::jsonb)",
+			args: []interface{}{
+				`{"metadata":{"labels":{"cloud":"Amazon"}}}`, `{"spec":{"hubAcceptsClient":true}}`,
+			},
+		},
+		{
+			name:      "the resources without the field are selected by not equal",
+			kind:      "managedclusters",
+			query:     `metadata.labels.env != 'prod' || !has(metadata.labels["vendor"])`,
+			condition: "(NOT payload @> ?::jsonb OR NOT (payload #> ARRAY[?::text, ?::text, ?::text] IS NOT NULL))",
+			args:      []interface{}{`{"metadata":{"labels":{"env":"prod"}}}`, "metadata", "labels", "vendor"},
+		},
+		{
+			name:  "the array index is compared by the value",
+			kind:  "managedclusters",
+			query: `status.conditions[0].type in ["HubAcceptedManagedCluster", null]`,
+			condition: "(COALESCE(payload #> ARRAY[?::text, ?::text, ?::text, ?::text] = ?::jsonb, FALSE) OR " +
+				"COALESCE(payload #> ARRAY[?::text, ?::text, ?::text, ?::text], 'null'::jsonb) = 'null'::jsonb)",
+			args: []interface{}{
+				"status", "conditions", "0", "type", `"HubAcceptedManagedCluster"`,
+				"status", "conditions", "0", "type",
+			},
+		},
+		{
+			name:  "the numbers are compared only with the numbers",
+			kind:  "managedclusters",
+			query: `status.capacity.cpu >= 8`,
+			condition: "(CASE WHEN jsonb_typeof(payload #> ARRAY[?::text, ?::text, ?::text]) = 'number' THEN " +
+				"(payload #>> ARRAY[?::text, ?::text, ?::text])::numeric >= ?::numeric ELSE FALSE END)",
+			args: []interface{}{"status", "capacity", "cpu", "status", "capacity", "cpu", "8"},
+		},
+		{
+			name:      "the wildcards of the string methods are escaped",
+			kind:      "managedclusters",
+			query:     `metadata.name.startsWith("prod_1%") && leafHubName.contains("hub")`,
+			condition: "(COALESCE(payload #>> ARRAY[?::text, ?::text] LIKE ?, FALSE) AND leaf_hub_name LIKE ?)",
+			args:      []interface{}{"metadata", "name", `prod\_1\%%`, "%hub%"},
+		},
+		{
+			name:  "the joined status",
+			kind:  "localpolicies",
+			query: `nonCompliantClusters > 0 && leafHubName in ["hub1", "hub2"]`,
+			condition: "((SELECT count(*) FROM local_status.compliance c WHERE c.policy_id = " +
+				"local_spec.policies.policy_id AND c.compliance = 'non_compliant') > ?::numeric AND " +
+				"(leaf_hub_name = ? OR leaf_hub_name = ?))",
+			args: []interface{}{"0", "hub1", "hub2"},
+		},
+		{
+			name:  "the compliance of a managed hub",
+			kind:  "policies",
+			query: `leafHubs["hub1"].nonCompliantClusters > 0`,
+			condition: "(SELECT count(*) FROM status.compliance c WHERE c.policy_id = spec.policies.id AND " +
+				"c.compliance = 'non_compliant' AND c.leaf_hub_name = ?) > ?::numeric",
+			args: []interface{}{"hub1", "0"},
+		},
+		{
+			name:  "the versions are compared by the numeric parts",
+			kind:  "managedclusters",
+			query: `version(metadata.labels.openshiftVersion) >= "v4.14"`,
+			condition: "(CASE WHEN payload #>> ARRAY[?::text, ?::text, ?::text] ~ ? THEN " +
+				"string_to_array(substring(payload #>> ARRAY[?::text, ?::text, ?::text] from ?), '.')::int[] >= " +
+				"?::int[] ELSE FALSE END)",
+			args: []interface{}{
+				"metadata", "labels", "openshiftVersion", versionPattern,
+				"metadata", "labels", "openshiftVersion", versionPattern, "{4,14}",
+			},
+		},
+		{
+			name: "the elements of the array are matched by the predicate",
+			kind: "managedclusters",
+			query: `exists(status.clusterClaims, name == "version.openshift.io" && ` +
+				`version(value) < "4.15")`,
+			condition: "EXISTS (SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(payload #> " +
+				"ARRAY[?::text, ?::text]) = 'array' THEN payload #> ARRAY[?::text, ?::text] ELSE '[]'::jsonb END) " +
+				"AS e1(value) WHERE (e1.value @> ?::jsonb AND (CASE WHEN e1.value #>> ARRAY[?::text] ~ ? THEN " +
+				"string_to_array(substring(e1.value #>> ARRAY[?::text] from ?), '.')::int[] < ?::int[] " +
+				"ELSE FALSE END)))",
+			args: []interface{}{
+				"status", "clusterClaims", "status", "clusterClaims", `{"name":"version.openshift.io"}`,
+				"value", versionPattern, "value", versionPattern, "{4,15}",
+			},
+		},
+		{
+			name:    "the version isn't compared with the literal of the other type",
+			kind:    "managedclusters",
+			query:   `version(metadata.labels.openshiftVersion) > 4`,
+			wantErr: "version() must be compared with a string",
+		},
+		{
+			name:    "invalid version",
+			kind:    "managedclusters",
+			query:   `version(metadata.labels.openshiftVersion) > "4.x"`,
+			wantErr: "invalid version",
+		},
+		{
+			name:    "the compliance of the managed hubs isn't joined to the other kinds",
+			kind:    "managedclusters",
+			query:   `leafHubs["hub1"].nonCompliantClusters > 0`,
+			wantErr: "unsupported field leafHubs",
+		},
+		{
+			name:    "the joined field isn't compared with the literal of the other type",
+			kind:    "localpolicies",
+			query:   `nonCompliantClusters > "0"`,
+			wantErr: "must be compared with a number",
+		},
+		{
+			name:    "the field of the other kind",
+			kind:    "policies",
+			query:   `leafHubName == "hub1"`,
+			wantErr: "unsupported field leafHubName",
+		},
+		{
+			name:    "the booleans aren't ordered",
+			kind:    "managedclusters",
+			query:   `spec.hubAcceptsClient > true`,
+			wantErr: "only supports numbers and strings",
+		},
+		{
+			name:    "the sql isn't accepted",
+			kind:    "managedclusters",
+			query:   `metadata.name == "a" OR true`,
+			wantErr: `unexpected "OR"`,
+		},
+		{
+			name:    "unterminated string",
+			kind:    "managedclusters",
+			query:   `metadata.name == "a`,
+			wantErr: "unterminated string",
+		},
+		{
+			name:    "unsupported method",
+			kind:    "managedclusters",
+			query:   `metadata.name.matches(".*")`,
+			wantErr: "unsupported method matches",
+		},
+		{
+			name:    "too deep",
+			kind:    "managedclusters",
+			query:   strings.Repeat("!", maxDepth+1) + `has(spec)`,
+			wantErr: "maximum nesting",
+		},
+		{
+			name:    "too many terms",
+			kind:    "managedclusters",
+			query:   `metadata.name in [` + strings.Repeat(`"a", `, maxTerms) + `"a"]`,
+			wantErr: "maximum of 256 terms",
+		},
+		{
+			name:    "too long",
+			kind:    "managedclusters",
+			query:   `metadata.name == "` + strings.Repeat("a", maxQueryLength) + `"`,
+			wantErr: "maximum length",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			condition, args, err := compileQuery(c.kind, c.query)
+			if c.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.condition, condition)
+			assert.Equal(t, c.args, args)
+			assert.Equal(t, strings.Count(condition, "?"), len(args), "every placeholder has an argument")
+		})
+	}
+}
+
+func compileQuery(kindName, query string) (string, []interface{}, error) {
+	expr, err := parseExpression(query)
+	if err != nil {
+		return "", nil, err
+	}
+	return kinds[kindName].compile(expr)
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/util"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
+)
+
+const (
+	serverInternalErrorMsg = "internal error"
+	defaultSearchLimit     = 100
+	maxSearchLimit         = 1000
+	// searchTimeout bounds the search on the database, the expensive queries are canceled instead of loading the
+	// read replica
+	searchTimeout = 10 * time.Second
+)
+
+// SearchRequest searches the resources of the kind by the expression over the stored payloads and the joined status
+type SearchRequest struct {
+	// the kind of the resources: managedclusters, localpolicies or policies
+	Kind string `json:"kind"`
+	// the search expression, e.g. metadata.labels["env"] == "prod" && available != "True"
+	Query string `json:"query"`
+	// maximum number of the resources to receive, default 100 and at most 1000
+	Limit int `json:"limit"`
+	// continue token to request the next page
+	Continue string `json:"continue"`
+}
+
+// SearchResult is a page of the resources which match the search expression
+type SearchResult struct {
+	Kind     string       `json:"kind"`
+	Items    []SearchItem `json:"items"`
+	Continue string       `json:"continue,omitempty"`
+}
+
+// SearchItem is the resource and the managed hub it's from
+type SearchItem struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	LeafHubName string          `json:"leafHubName,omitempty"`
+	Object      json.RawMessage `json:"object"`
+}
+
+// Search godoc
+// @summary search resources
+// @description search the managed clusters, the local policies or the policies by the expression over the stored
+// @description payloads and the joined status, e.g. metadata.labels["env"] == "prod" && available != "True"
+// @accept json
+// @produce json
+// @param        search    body    SearchRequest    true    "The kind, the expression and the paging of the search"
+// @success      200  {object}  SearchResult
+// @failure      400
+// @failure      401
+// @failure      403
+// @failure      500
+// @failure      503
+// @failure      504
+// @security     ApiKeyAuth
+// @router /search [post]
+func Search() gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		request := &SearchRequest{}
+		if err := ginCtx.BindJSON(request); err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to bind: %s\n", err.Error())
+			return
+		}
+		searchKind, ok := kinds[request.Kind]
+		if !ok {
+			ginCtx.String(http.StatusBadRequest, "invalid kind: %s, the supported kinds: %s", request.Kind,
+				strings.Join(kindNames(), ", "))
+			return
+		}
+		limit := defaultSearchLimit
+		if request.Limit != 0 {
+			if request.Limit < 0 || request.Limit > maxSearchLimit {
+				ginCtx.String(http.StatusBadRequest, "invalid limit: %d, the limit must be in 1-%d",
+					request.Limit, maxSearchLimit)
+				return
+			}
+			limit = request.Limit
+		}
+		lastName, lastID := "", uuid.Nil.String()
+		if request.Continue != "" {
+			var err error
+			lastName, lastID, err = util.DecodeContinue(request.Continue)
+			if err == nil {
+				_, err = uuid.Parse(lastID)
+			}
+			if err != nil {
+				ginCtx.String(http.StatusBadRequest, "invalid continue token")
+				return
+			}
+		}
+
+		expr, err := parseExpression(request.Query)
+		if err != nil {
+			ginCtx.String(http.StatusBadRequest, "invalid query: %s", err.Error())
+			return
+		}
+		condition, conditionArgs, err := searchKind.compile(expr)
+		if err != nil {
+			ginCtx.String(http.StatusBadRequest, "invalid query: %s", err.Error())
+			return
+		}
+
+		// one more resource is queried to know whether there is a next page
+		query := fmt.Sprintf("SELECT %s::text, %s, %s, payload FROM %s WHERE %s AND (%s, %s) > (?, ?::uuid) AND %s "+
+			"ORDER BY %s, %s LIMIT ?", searchKind.idColumn, searchKind.nameColumn, searchKind.leafHubColumn,
+			searchKind.table, searchKind.conditions, searchKind.nameColumn, searchKind.idColumn, condition,
+			searchKind.nameColumn, searchKind.idColumn)
+		args := append([]interface{}{lastName, lastID}, conditionArgs...)
+		args = append(args, limit+1)
+
+		ctx, cancel := context.WithTimeout(ginCtx.Request.Context(), searchTimeout)
+		defer cancel()
+		items, err := searchItems(ctx, query, args)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			ginCtx.String(http.StatusGatewayTimeout, "the search exceeds the time limit of %s, narrow the query",
+				searchTimeout)
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "error in searching %s: %v\n", request.Kind, err)
+			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+			return
+		}
+
+		result := &SearchResult{Kind: request.Kind, Items: items}
+		if len(items) > limit {
+			result.Items = items[:limit]
+			last := result.Items[limit-1]
+			if result.Continue, err = util.EncodeContinue(last.Name, last.ID); err != nil {
+				fmt.Fprintf(gin.DefaultWriter, "error in encoding the continue token: %v\n", err)
+				ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
+				return
+			}
+		}
+		ginCtx.JSON(http.StatusOK, result)
+	}
+}
+
+// searchItems runs the search on the read database, the statement timeout also cancels the query on the database
+// if the connection is lost
+func searchItems(ctx context.Context, query string, args []interface{}) ([]SearchItem, error) {
+	items := []SearchItem{}
+	err := database.GetReadGorm().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", searchTimeout.Milliseconds())).
+			Error; err != nil {
+			return err
+		}
+		rows, err := tx.Raw(query, args...).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			item := SearchItem{}
+			var payload []byte
+			if err := rows.Scan(&item.ID, &item.Name, &item.LeafHubName, &payload); err != nil {
+				return err
+			}
+			// the encrypted fields are restored since the API is only served to the authenticated users
+			if payload, err = sensitive.Decrypt(payload); err != nil {
+				return err
+			}
+			item.Object = payload
+			items = append(items, item)
+		}
+		return rows.Err()
+	})
+	return items, err
+}
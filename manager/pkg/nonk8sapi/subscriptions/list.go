@@ -36,12 +36,19 @@ const (
 var customResourceColumnDefinitions = util.GetCustomResourceColumnDefinitions(crdName,
 	appsv1.SchemeGroupVersion.Version)
 
+// fieldColumns are the fields supported by the field selector of the subscriptions
+var fieldColumns = util.FieldColumns{
+	"metadata.name":      "payload -> 'metadata' ->> 'name'",
+	"metadata.namespace": "payload -> 'metadata' ->> 'namespace'",
+}
+
 // ListSubscriptions godoc
 // @summary list application subscriptions
 // @description list application subscriptions
 // @accept json
 // @produce json
 // @param        labelSelector    query     string  false  "list application subscriptions by label selector"
+// @param        fieldSelector    query     string  false  "list application subscriptions by field selector, e.g. metadata.namespace=default"
 // @param        limit            query     int     false  "maximum application subscription number to receive"
 // @param        continue         query     string  false  "continue token to request next request"
 // @success      200  {object}    appsv1.SubscriptionList
@@ -55,48 +62,17 @@ var customResourceColumnDefinitions = util.GetCustomResourceColumnDefinitions(cr
 // @router /subscriptions [get]
 func ListSubscriptions() gin.HandlerFunc {
 	return func(ginCtx *gin.Context) {
-		labelSelector := ginCtx.Query("labelSelector")
-
-		selectorInSql := ""
-
-		if labelSelector != "" {
-			var err error
-			selectorInSql, err = util.ParseLabelSelector(labelSelector)
-			if err != nil {
-				fmt.Fprintf(gin.DefaultWriter, "failed to parse label selector: %s\n", err.Error())
-				return
-			}
-		}
-
-		fmt.Fprintf(gin.DefaultWriter, "parsed selector: %s\n", selectorInSql)
-
-		limit := ginCtx.Query("limit")
-		fmt.Fprintf(gin.DefaultWriter, "limit: %v\n", limit)
-
-		lastSubscriptionName, lastSubscriptionUID := "", ""
-
-		continueToken := ginCtx.Query("continue")
-		if continueToken != "" {
-			fmt.Fprintf(gin.DefaultWriter, "continue: %v\n", continueToken)
-
-			var err error
-			lastSubscriptionName, lastSubscriptionUID, err = util.DecodeContinue(continueToken)
-			if err != nil {
-				fmt.Fprintf(gin.DefaultWriter, "failed to decode continue token: %s\n", err.Error())
-				return
-			}
+		listOptions, err := util.ParseListOptions(ginCtx, fieldColumns)
+		if err != nil {
+			fmt.Fprintf(gin.DefaultWriter, "failed to parse the list options: %s\n", err.Error())
+			ginCtx.String(http.StatusBadRequest, err.Error())
+			return
 		}
 
 		fmt.Fprintf(gin.DefaultWriter,
 			"last returned subscription name: %s, last returned subscription UID: %s\n",
-			lastSubscriptionName,
-			lastSubscriptionUID)
-
-		// build query condition for paging
-		LastResourceCompareCondition := fmt.Sprintf(
-			"(payload -> 'metadata' ->> 'name', payload -> 'metadata' ->> 'uid') > ('%s', '%s') ",
-			lastSubscriptionName,
-			lastSubscriptionUID)
+			listOptions.LastName,
+			listOptions.LastUID)
 
 		// the last subscription query order by subscription name and uid
 		lastSubscriptionQuery := "SELECT payload FROM spec.subscriptions WHERE deleted = FALSE " +
@@ -104,27 +80,29 @@ func ListSubscriptions() gin.HandlerFunc {
 
 		// subscrition list query
 		subscriptionListQuery := "SELECT payload FROM spec.subscriptions WHERE deleted = FALSE AND " +
-			LastResourceCompareCondition +
-			selectorInSql +
+			"(payload -> 'metadata' ->> 'name', payload -> 'metadata' ->> 'uid') > (?, ?)" +
+			listOptions.Conditions +
 			" ORDER BY (payload -> 'metadata' ->> 'name', payload -> 'metadata' ->> 'uid')"
+		args := append([]interface{}{listOptions.LastName, listOptions.LastUID}, listOptions.Args...)
 
 		// add limit
-		if limit != "" {
-			subscriptionListQuery += fmt.Sprintf(" LIMIT %s", limit)
+		if listOptions.Limit > 0 {
+			subscriptionListQuery += " LIMIT ?"
+			args = append(args, listOptions.Limit)
 		}
 
-		fmt.Fprintf(gin.DefaultWriter, "subscription list query: %v\n", subscriptionListQuery)
+		fmt.Fprintf(gin.DefaultWriter, "subscription list query: %v, args: %v\n", subscriptionListQuery, args)
 
 		if _, watch := ginCtx.GetQuery("watch"); watch {
-			handleSubscriptionListForWatch(ginCtx, subscriptionListQuery)
+			handleSubscriptionListForWatch(ginCtx, subscriptionListQuery, args)
 			return
 		}
 
-		handleRows(ginCtx, subscriptionListQuery, lastSubscriptionQuery, customResourceColumnDefinitions)
+		handleRows(ginCtx, subscriptionListQuery, args, lastSubscriptionQuery, customResourceColumnDefinitions)
 	}
 }
 
-func handleSubscriptionListForWatch(ginCtx *gin.Context, subscriptionListQuery string) {
+func handleSubscriptionListForWatch(ginCtx *gin.Context, subscriptionListQuery string, args []interface{}) {
 	writer := ginCtx.Writer
 	header := writer.Header()
 
@@ -154,19 +132,21 @@ func handleSubscriptionListForWatch(ginCtx *gin.Context, subscriptionListQuery s
 				return
 			}
 
-			doHandleRowsForWatch(ctx, writer, subscriptionListQuery, preAddedSubscriptions)
+			doHandleRowsForWatch(ctx, writer, subscriptionListQuery, args, preAddedSubscriptions)
 		}
 	}
 }
 
 func doHandleRowsForWatch(ctx context.Context, writer io.Writer, subscriptionListQuery string,
-	preAddedSubscriptions set.Set,
+	args []interface{}, preAddedSubscriptions set.Set,
 ) {
-	db := database.GetGorm()
-	rows, err := db.Raw(subscriptionListQuery).Rows()
+	db := database.GetReadGorm()
+	rows, err := db.Raw(subscriptionListQuery, args...).Rows()
 	if err != nil {
 		fmt.Fprintf(gin.DefaultWriter, "error in quering subscription list: %v\n", err)
+		return
 	}
+	defer rows.Close()
 
 	addedSubscriptions := set.NewSet()
 	for rows.Next() {
@@ -226,10 +206,10 @@ func doHandleRowsForWatch(ctx context.Context, writer io.Writer, subscriptionLis
 	writer.(http.Flusher).Flush()
 }
 
-func handleRows(ginCtx *gin.Context, subscriptionListQuery, lastSubscriptionQuery string,
+func handleRows(ginCtx *gin.Context, subscriptionListQuery string, args []interface{}, lastSubscriptionQuery string,
 	customResourceColumnDefinitions []apiextensionsv1.CustomResourceColumnDefinition,
 ) {
-	db := database.GetGorm()
+	db := database.GetReadGorm()
 	lastSubscription := &appsv1.Subscription{}
 	var payload []byte
 	err := db.Raw(lastSubscriptionQuery).Row().Scan(&payload)
@@ -240,7 +220,7 @@ func handleRows(ginCtx *gin.Context, subscriptionListQuery, lastSubscriptionQuer
 	}
 
 	if err == nil {
-		err = json.Unmarshal(payload, lastSubscription)
+		err = util.UnmarshalPayload(payload, lastSubscription)
 		if err != nil {
 			ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
 			fmt.Fprintf(gin.DefaultWriter, "error in querying last subscription payload: %v\n", err)
@@ -248,11 +228,13 @@ func handleRows(ginCtx *gin.Context, subscriptionListQuery, lastSubscriptionQuer
 		}
 	}
 
-	rows, err := db.Raw(subscriptionListQuery).Rows()
+	rows, err := db.Raw(subscriptionListQuery, args...).Rows()
 	if err != nil {
 		ginCtx.String(http.StatusInternalServerError, serverInternalErrorMsg)
 		fmt.Fprintf(gin.DefaultWriter, "error in querying subscriptions: %v\n", err)
+		return
 	}
+	defer rows.Close()
 
 	subscriptionList := &appsv1.SubscriptionList{
 		TypeMeta: metav1.TypeMeta{
@@ -270,7 +252,7 @@ func handleRows(ginCtx *gin.Context, subscriptionListQuery, lastSubscriptionQuer
 			fmt.Fprintf(gin.DefaultWriter, "error in scanning a subscription: %v\n", err)
 			continue
 		}
-		err = json.Unmarshal(payload, &subscription)
+		err = util.UnmarshalPayload(payload, &subscription)
 		if err != nil {
 			fmt.Fprintf(gin.DefaultWriter, "error in scanning a subscription payload: %v\n", err)
 			continue
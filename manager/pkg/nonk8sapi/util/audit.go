@@ -0,0 +1,27 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/audit"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi/authentication"
+)
+
+// RecordAudit records the mutation made through the API with the user authenticated by the middleware, the user is
+// empty if the authentication is disabled. The tx is the transaction of the mutation, so the mutation is rolled back
+// if it can't be recorded.
+func RecordAudit(ginCtx *gin.Context, tx *gorm.DB, entry *audit.Entry) error {
+	entry.Actor = ginCtx.GetString(authentication.UserKey)
+	entry.Groups = ginCtx.GetStringSlice(authentication.GroupsKey)
+	entry.Source = audit.SourceAPI
+	if _, err := audit.Record(tx, entry); err != nil {
+		return fmt.Errorf("failed to record the audit log of %s %s/%s: %w", entry.Action, entry.Kind, entry.Name, err)
+	}
+	return nil
+}
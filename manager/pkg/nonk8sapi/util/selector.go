@@ -0,0 +1,164 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// labelsColumn is the labels of the resource stored in the payload
+const labelsColumn = "payload -> 'metadata' -> 'labels'"
+
+// FieldColumns maps the fields supported by the field selector to the sql expressions.
+type FieldColumns map[string]string
+
+// ListOptions are the selectors and paging parameters of the list request, the selectors are compiled into the
+// parameterized sql conditions, so that none of the request values are interpolated into the query.
+type ListOptions struct {
+	// Conditions is appended to the where clause, it starts with " AND " if it isn't empty
+	Conditions string
+	// Args are the arguments of the placeholders in the Conditions
+	Args     []interface{}
+	Limit    int
+	LastName string
+	LastUID  string
+}
+
+// ParseListOptions parses the labelSelector, fieldSelector, limit and continue query parameters of the request.
+func ParseListOptions(ginCtx *gin.Context, fieldColumns FieldColumns) (*ListOptions, error) {
+	options := &ListOptions{}
+
+	labelConditions, labelArgs, err := ParseLabelSelector(ginCtx.Query("labelSelector"))
+	if err != nil {
+		return nil, err
+	}
+	fieldConditions, fieldArgs, err := ParseFieldSelector(ginCtx.Query("fieldSelector"), fieldColumns)
+	if err != nil {
+		return nil, err
+	}
+	options.Conditions = labelConditions + fieldConditions
+	options.Args = append(labelArgs, fieldArgs...)
+
+	if limit := ginCtx.Query("limit"); limit != "" {
+		options.Limit, err = strconv.Atoi(limit)
+		if err != nil || options.Limit <= 0 {
+			return nil, fmt.Errorf("invalid limit: %s", limit)
+		}
+	}
+
+	if continueToken := ginCtx.Query("continue"); continueToken != "" {
+		options.LastName, options.LastUID, err = DecodeContinue(continueToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid continue token: %w", err)
+		}
+	}
+	return options, nil
+}
+
+// ParseLabelSelector compiles the label selector into the parameterized sql conditions on the labels of the payload,
+// it supports all the operators of the kubernetes label selector, e.g. "env in (dev,qa),!canary,tier!=frontend".
+func ParseLabelSelector(labelSelector string) (string, []interface{}, error) {
+	if strings.TrimSpace(labelSelector) == "" {
+		return "", nil, nil
+	}
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+	requirements, _ := selector.Requirements()
+
+	conditions, args := "", []interface{}{}
+	for _, requirement := range requirements {
+		key := requirement.Key()
+		label := labelsColumn + " ->> ?::text"
+		values := requirement.Values().List()
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals:
+			conditions += fmt.Sprintf(" AND %s = ?", label)
+			args = append(args, key, values[0])
+		case selection.NotEquals:
+			// the resources without the label are also selected
+			conditions += fmt.Sprintf(" AND %s IS DISTINCT FROM ?", label)
+			args = append(args, key, values[0])
+		case selection.In:
+			conditions += fmt.Sprintf(" AND %s IN ?", label)
+			args = append(args, key, values)
+		case selection.NotIn:
+			conditions += fmt.Sprintf(" AND (%s IS NULL OR %s NOT IN ?)", label, label)
+			args = append(args, key, key, values)
+		case selection.Exists:
+			conditions += fmt.Sprintf(" AND %s IS NOT NULL", label)
+			args = append(args, key)
+		case selection.DoesNotExist:
+			conditions += fmt.Sprintf(" AND %s IS NULL", label)
+			args = append(args, key)
+		case selection.GreaterThan, selection.LessThan:
+			operator := ">"
+			if requirement.Operator() == selection.LessThan {
+				operator = "<"
+			}
+			value, err := strconv.ParseInt(values[0], 10, 64)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid label selector: %s isn't an integer", values[0])
+			}
+			// the labels which aren't integers aren't selected, the "?" isn't used in the pattern since it's the
+			// placeholder of the arguments
+			conditions += fmt.Sprintf(
+				" AND (CASE WHEN %s ~ '^-{0,1}[0-9]{1,18}$' THEN (%s)::bigint %s ? ELSE FALSE END)",
+				label, label, operator)
+			args = append(args, key, key, value)
+		default:
+			return "", nil, fmt.Errorf("invalid label selector: unsupported operator %s", requirement.Operator())
+		}
+	}
+	return conditions, args, nil
+}
+
+// ParseFieldSelector compiles the field selector into the parameterized sql conditions, the fields are limited to
+// the fieldColumns, e.g. "metadata.namespace=default,leafHubName!=hub1".
+func ParseFieldSelector(fieldSelector string, fieldColumns FieldColumns) (string, []interface{}, error) {
+	if strings.TrimSpace(fieldSelector) == "" {
+		return "", nil, nil
+	}
+	selector, err := fields.ParseSelector(fieldSelector)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid field selector: %w", err)
+	}
+
+	conditions, args := "", []interface{}{}
+	for _, requirement := range selector.Requirements() {
+		column, ok := fieldColumns[requirement.Field]
+		if !ok {
+			return "", nil, fmt.Errorf("invalid field selector: unsupported field %s, the supported fields: %s",
+				requirement.Field, strings.Join(fieldColumns.names(), ", "))
+		}
+		switch requirement.Operator {
+		case selection.Equals, selection.DoubleEquals:
+			conditions += fmt.Sprintf(" AND %s = ?", column)
+		case selection.NotEquals:
+			conditions += fmt.Sprintf(" AND %s IS DISTINCT FROM ?", column)
+		default:
+			return "", nil, fmt.Errorf("invalid field selector: unsupported operator %s", requirement.Operator)
+		}
+		args = append(args, requirement.Value)
+	}
+	return conditions, args, nil
+}
+
+func (f FieldColumns) names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
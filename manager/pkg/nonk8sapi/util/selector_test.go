@@ -0,0 +1,113 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	labels := "payload -> 'metadata' -> 'labels' ->> ?::text"
+	cases := []struct {
+		name       string
+		selector   string
+		conditions string
+		args       []interface{}
+		wantErr    bool
+	}{
+		{name: "empty selector"},
+		{
+			name:       "equality based",
+			selector:   "cloud=Amazon,vendor!=OpenShift",
+			conditions: " AND " + labels + " = ? AND " + labels + " IS DISTINCT FROM ?",
+			args:       []interface{}{"cloud", "Amazon", "vendor", "OpenShift"},
+		},
+		{
+			name:     "set based",
+			selector: "env in (qa, dev),tier notin (frontend),canary,!test",
+			// the requirements are sorted by the key
+			conditions: " AND " + labels + " IS NOT NULL" +
+				" AND " + labels + " IN ?" +
+				" AND " + labels + " IS NULL" +
+				" AND (" + labels + " IS NULL OR " + labels + " NOT IN ?)",
+			args: []interface{}{
+				"canary", "env", []string{"dev", "qa"}, "test", "tier", "tier", []string{"frontend"},
+			},
+		},
+		{
+			name:     "greater than",
+			selector: "replicas>2",
+			conditions: " AND (CASE WHEN " + labels + " ~ '^-{0,1}[0-9]{1,18}$' THEN (" + labels +
+				")::bigint > ? ELSE FALSE END)",
+			args: []interface{}{"replicas", "replicas", int64(2)},
+		},
+		{
+			name:     "the value isn't interpolated",
+			selector: "name=a'b",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid set",
+			selector: "env in (qa",
+			wantErr:  true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conditions, args, err := ParseLabelSelector(tc.selector)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.conditions, conditions)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestParseFieldSelector(t *testing.T) {
+	fieldColumns := FieldColumns{
+		"metadata.namespace": "payload -> 'metadata' ->> 'namespace'",
+		"leafHubName":        "leaf_hub_name",
+	}
+
+	conditions, args, err := ParseFieldSelector("metadata.namespace=default,leafHubName!=hub1", fieldColumns)
+	require.NoError(t, err)
+	// the requirements are sorted by the field
+	assert.Equal(t, " AND leaf_hub_name IS DISTINCT FROM ? AND payload -> 'metadata' ->> 'namespace' = ?",
+		conditions)
+	assert.Equal(t, []interface{}{"hub1", "default"}, args)
+
+	_, _, err = ParseFieldSelector("metadata.name=foo", fieldColumns)
+	assert.EqualError(t, err, "invalid field selector: unsupported field metadata.name, "+
+		"the supported fields: leafHubName, metadata.namespace")
+}
+
+func TestParseListOptions(t *testing.T) {
+	continueToken, err := EncodeContinue("cluster1", "uid1")
+	require.NoError(t, err)
+
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest("GET",
+		"/managedclusters?labelSelector=env%3Ddev&fieldSelector=leafHubName%3Dhub1&limit=2&continue="+continueToken, nil)
+	options, err := ParseListOptions(ginCtx, FieldColumns{"leafHubName": "leaf_hub_name"})
+	require.NoError(t, err)
+	assert.Equal(t, " AND payload -> 'metadata' -> 'labels' ->> ?::text = ? AND leaf_hub_name = ?",
+		options.Conditions)
+	assert.Equal(t, []interface{}{"env", "dev", "hub1"}, options.Args)
+	assert.Equal(t, 2, options.Limit)
+	assert.Equal(t, "cluster1", options.LastName)
+	assert.Equal(t, "uid1", options.LastUID)
+
+	// the limit is passed as an argument rather than interpolated into the query
+	ginCtx.Request = httptest.NewRequest("GET", "/managedclusters?limit=1;DROP%20TABLE", nil)
+	_, err = ParseListOptions(ginCtx, FieldColumns{})
+	assert.Error(t, err)
+}
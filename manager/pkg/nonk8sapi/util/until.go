@@ -11,6 +11,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
 )
 
 func SendWatchEvent(watchEvent *metav1.WatchEvent, writer io.Writer) error {
@@ -64,3 +66,13 @@ func ShouldReturnAsTable(ginCtx *gin.Context) bool {
 
 	return false
 }
+
+// UnmarshalPayload decodes the payload stored in the database into the object, the encrypted fields are restored
+// since the API is only served to the authenticated users.
+func UnmarshalPayload(payload []byte, object interface{}) error {
+	payload, err := sensitive.Decrypt(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, object)
+}
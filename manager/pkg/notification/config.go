@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package notification
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultTimeout    = 10 * time.Second
+)
+
+// Config is the configuration of the webhook endpoints, it's loaded from the notification secret.
+//
+//	endpoints:
+//	- name: incident
+//	  url: https://incident.example.com/hooks/global-hub
+//	  secret: <the key to sign the payload>
+//	  eventTypes: [HubInactive, ClusterUnavailable]
+//	  leafHubs: [hub1]
+type Config struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// Endpoint is a webhook endpoint which the events are delivered to.
+type Endpoint struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Secret is the key to sign the payload with HMAC-SHA256, the signature isn't set if it's empty
+	Secret string `json:"secret,omitempty"`
+	// EventTypes are the types of the events delivered to the endpoint, all the events are delivered if it's empty
+	EventTypes []string `json:"eventTypes,omitempty"`
+	// LeafHubs are the managed hubs of the events delivered to the endpoint, the events of all the hubs are delivered
+	// if it's empty. the events without the managed hub, like the cron job failure, are always delivered
+	LeafHubs []string `json:"leafHubs,omitempty"`
+	// MaxRetries is the number of the retries after the delivery failed, default 3
+	MaxRetries *int `json:"maxRetries,omitempty"`
+	// Timeout of each delivery, default 10s
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ParseConfig parses the webhook endpoints and validates them.
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	for i, endpoint := range config.Endpoints {
+		if endpoint.Name == "" {
+			return nil, fmt.Errorf("the name of the endpoint %d is empty", i)
+		}
+		u, err := url.Parse(endpoint.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("the url of the endpoint %s is invalid: %q", endpoint.Name, endpoint.URL)
+		}
+		if endpoint.Timeout != "" {
+			if _, err := time.ParseDuration(endpoint.Timeout); err != nil {
+				return nil, fmt.Errorf("the timeout of the endpoint %s is invalid: %w", endpoint.Name, err)
+			}
+		}
+	}
+	return config, nil
+}
+
+// Matches returns true if the event should be delivered to the endpoint.
+func (e *Endpoint) Matches(event *Event) bool {
+	if len(e.EventTypes) > 0 && !contains(e.EventTypes, event.Type) {
+		return false
+	}
+	leafHubName, ok := event.Data[DataLeafHubName]
+	if len(e.LeafHubs) > 0 && ok && !contains(e.LeafHubs, leafHubName) {
+		return false
+	}
+	return true
+}
+
+func (e *Endpoint) maxRetries() int {
+	if e.MaxRetries == nil || *e.MaxRetries < 0 {
+		return defaultMaxRetries
+	}
+	return *e.MaxRetries
+}
+
+func (e *Endpoint) timeout() time.Duration {
+	timeout, err := time.ParseDuration(e.Timeout)
+	if err != nil || timeout <= 0 {
+		return defaultTimeout
+	}
+	return timeout
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
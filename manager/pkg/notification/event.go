@@ -0,0 +1,54 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package notification
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// the types of the events emitted by the manager
+const (
+	// PolicyComplianceChanged means the compliance of the policy on the managed cluster is changed
+	PolicyComplianceChanged = "PolicyComplianceChanged"
+	// HubInactive means the managed hub stops sending the heartbeat, and its resources are cleaned up
+	HubInactive = "HubInactive"
+	// HubActive means the inactive managed hub sends the heartbeat again
+	HubActive = "HubActive"
+	// ClusterUnavailable means the available condition of the managed cluster isn't true any more
+	ClusterUnavailable = "ClusterUnavailable"
+	// CronJobFailed means the cron job of the manager failed
+	CronJobFailed = "CronJobFailed"
+)
+
+// the keys of the event data
+const (
+	DataLeafHubName = "leafHubName"
+	DataClusterName = "clusterName"
+	DataClusterID   = "clusterId"
+	DataPolicyID    = "policyId"
+	DataCompliance  = "compliance"
+	DataJobName     = "jobName"
+)
+
+// Event is the payload delivered to the webhook endpoints.
+type Event struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	Timestamp time.Time         `json:"timestamp"`
+	Message   string            `json:"message"`
+	Data      map[string]string `json:"data,omitempty"`
+}
+
+// NewEvent creates a new event with an unique id.
+func NewEvent(eventType, message string, data map[string]string) *Event {
+	return &Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Message:   message,
+		Data:      data,
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/monitoring"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+const (
+	// SignatureHeader is the hex encoded HMAC-SHA256 of the payload with the secret of the endpoint, e.g. sha256=...
+	SignatureHeader = "X-Global-Hub-Signature-256"
+	EventTypeHeader = "X-Global-Hub-Event"
+	DeliveryHeader  = "X-Global-Hub-Delivery"
+
+	eventQueueSize       = 1000
+	endpointQueueSize    = 1000
+	defaultRetryInterval = 2 * time.Second
+)
+
+var (
+	queueLock sync.RWMutex
+	// queue is only set while the notifier is running on the leader manager
+	queue chan *Event
+	log   = ctrl.Log.WithName("notification")
+	// droppedEvents is the number of the emitted events dropped since the queue is full
+	droppedEvents atomic.Int64
+)
+
+// Emit queues the event to be delivered to the webhook endpoints, it never blocks the caller. the event is dropped if
+// the notifier isn't running, e.g. the manager isn't the leader, or the queue is full.
+func Emit(event *Event) {
+	queueLock.RLock()
+	defer queueLock.RUnlock()
+	if queue == nil {
+		return
+	}
+	select {
+	case queue <- event:
+	default:
+		dropped := droppedEvents.Add(1)
+		monitoring.NotificationDroppedEventsCounterVec.WithLabelValues("").Inc()
+		log.Info("the notification queue is full, dropping the event", "type", event.Type, "id", event.ID,
+			"dropped", dropped)
+	}
+}
+
+// AddNotifier adds the notifier which delivers the emitted events to the webhook endpoints configured in the
+// notification secret of the manager namespace.
+func AddNotifier(mgr ctrl.Manager, namespace string) error {
+	return mgr.Add(&notifier{
+		log:           log,
+		client:        mgr.GetClient(),
+		namespace:     namespace,
+		httpClient:    &http.Client{},
+		retryInterval: defaultRetryInterval,
+	})
+}
+
+type notifier struct {
+	log           logr.Logger
+	client        client.Client
+	namespace     string
+	httpClient    *http.Client
+	retryInterval time.Duration
+	// workers deliver the events to the endpoints, each endpoint has its own queue so that a slow or unavailable
+	// endpoint doesn't delay the deliveries to the others
+	workers map[string]*endpointWorker
+}
+
+// delivery is an event to be delivered to the endpoint.
+type delivery struct {
+	endpoint *Endpoint
+	event    *Event
+	payload  []byte
+}
+
+// endpointWorker delivers the events to an endpoint in the order they're emitted.
+type endpointWorker struct {
+	deliveries chan *delivery
+	cancel     context.CancelFunc
+}
+
+func (n *notifier) Start(ctx context.Context) error {
+	events := make(chan *Event, eventQueueSize)
+	queueLock.Lock()
+	queue = events
+	queueLock.Unlock()
+
+	defer func() {
+		queueLock.Lock()
+		queue = nil
+		queueLock.Unlock()
+	}()
+
+	n.workers = map[string]*endpointWorker{}
+	defer func() {
+		for name := range n.workers {
+			n.stopWorker(name)
+		}
+	}()
+
+	n.log.Info("start the notifier")
+	for {
+		select {
+		case <-ctx.Done():
+			n.log.Info("context canceled, exiting the notifier...")
+			return nil
+		case event := <-events:
+			n.dispatch(ctx, event)
+		}
+	}
+}
+
+// dispatch queues the event to the workers of the matched endpoints, the endpoints are loaded for each event so that
+// the changes of the secret take effect immediately. the workers of the removed endpoints are stopped.
+func (n *notifier) dispatch(ctx context.Context, event *Event) {
+	endpoints, err := n.loadEndpoints(ctx)
+	if err != nil {
+		n.log.Error(err, "failed to load the webhook endpoints", "type", event.Type, "id", event.ID)
+		return
+	}
+	names := make(map[string]bool, len(endpoints))
+	for i := range endpoints {
+		names[endpoints[i].Name] = true
+	}
+	for name := range n.workers {
+		if !names[name] {
+			n.stopWorker(name)
+		}
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		n.log.Error(err, "failed to marshal the event", "type", event.Type, "id", event.ID)
+		return
+	}
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if !endpoint.Matches(event) {
+			continue
+		}
+		worker, found := n.workers[endpoint.Name]
+		if !found {
+			worker = n.startWorker(ctx)
+			n.workers[endpoint.Name] = worker
+		}
+		select {
+		case worker.deliveries <- &delivery{endpoint: endpoint, event: event, payload: payload}:
+		default:
+			monitoring.NotificationDroppedEventsCounterVec.WithLabelValues(endpoint.Name).Inc()
+			n.log.Info("the queue of the endpoint is full, dropping the event", "endpoint", endpoint.Name,
+				"type", event.Type, "id", event.ID)
+		}
+	}
+}
+
+func (n *notifier) startWorker(ctx context.Context) *endpointWorker {
+	ctx, cancel := context.WithCancel(ctx)
+	worker := &endpointWorker{
+		deliveries: make(chan *delivery, endpointQueueSize),
+		cancel:     cancel,
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case d := <-worker.deliveries:
+				n.deliverAndRecord(ctx, d)
+			}
+		}
+	}()
+	return worker
+}
+
+func (n *notifier) stopWorker(name string) {
+	n.workers[name].cancel()
+	delete(n.workers, name)
+}
+
+func (n *notifier) deliverAndRecord(ctx context.Context, d *delivery) {
+	if err := n.deliver(ctx, d.endpoint, d.event, d.payload); err != nil {
+		n.log.Error(err, "failed to deliver the event", "endpoint", d.endpoint.Name, "type", d.event.Type,
+			"id", d.event.ID)
+		monitoring.NotificationDeliveriesCounterVec.WithLabelValues(d.endpoint.Name, "failure").Inc()
+		return
+	}
+	monitoring.NotificationDeliveriesCounterVec.WithLabelValues(d.endpoint.Name, "success").Inc()
+}
+
+func (n *notifier) loadEndpoints(ctx context.Context) ([]Endpoint, error) {
+	secret := &corev1.Secret{}
+	err := n.client.Get(ctx, types.NamespacedName{
+		Namespace: n.namespace,
+		Name:      constants.GHNotificationSecretName,
+	}, secret)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	config, err := ParseConfig(secret.Data[constants.GHNotificationConfigKey])
+	if err != nil {
+		return nil, err
+	}
+	return config.Endpoints, nil
+}
+
+// deliver posts the event to the endpoint, and retries with the exponential backoff if the failure is transient.
+func (n *notifier) deliver(ctx context.Context, endpoint *Endpoint, event *Event, payload []byte) error {
+	var err error
+	interval := n.retryInterval
+	for attempt := 0; attempt <= endpoint.maxRetries(); attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+			interval *= 2
+		}
+
+		var retryable bool
+		retryable, err = n.post(ctx, endpoint, event, payload)
+		if err == nil || !retryable {
+			return err
+		}
+		n.log.V(2).Info("failed to deliver the event, retrying", "endpoint", endpoint.Name, "id", event.ID,
+			"attempt", attempt+1, "error", err.Error())
+	}
+	return err
+}
+
+// post returns whether the delivery can be retried if it failed.
+func (n *notifier) post(ctx context.Context, endpoint *Endpoint, event *Event, payload []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, endpoint.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(payload, endpoint.Secret))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusRequestTimeout
+	return retryable, fmt.Errorf("the endpoint responded with %s", resp.Status)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload, the receiver verifies the payload with it.
+func Sign(payload []byte, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
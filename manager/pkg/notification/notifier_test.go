@@ -0,0 +1,243 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+endpoints:
+- name: incident
+  url: https://incident.example.com/hooks
+  secret: key
+  eventTypes: [HubInactive]
+  leafHubs: [hub1]
+  maxRetries: 0
+  timeout: 5s
+`))
+	if err != nil {
+		t.Fatalf("failed to parse the config: %v", err)
+	}
+	if len(config.Endpoints) != 1 {
+		t.Fatalf("expected 1 endpoint, got %d", len(config.Endpoints))
+	}
+	endpoint := config.Endpoints[0]
+	if endpoint.maxRetries() != 0 || endpoint.timeout() != 5*time.Second {
+		t.Errorf("unexpected retries %d or timeout %v", endpoint.maxRetries(), endpoint.timeout())
+	}
+
+	for _, invalid := range []string{
+		"endpoints:\n- url: https://incident.example.com",
+		"endpoints:\n- name: incident\n  url: incident.example.com",
+		"endpoints:\n- name: incident\n  url: https://incident.example.com\n  timeout: 5",
+	} {
+		if _, err := ParseConfig([]byte(invalid)); err == nil {
+			t.Errorf("expected the config to be invalid: %s", invalid)
+		}
+	}
+}
+
+func TestEndpointMatches(t *testing.T) {
+	endpoint := &Endpoint{EventTypes: []string{HubInactive, CronJobFailed}, LeafHubs: []string{"hub1"}}
+
+	cases := []struct {
+		event   *Event
+		matched bool
+	}{
+		{NewEvent(HubInactive, "", map[string]string{DataLeafHubName: "hub1"}), true},
+		{NewEvent(HubInactive, "", map[string]string{DataLeafHubName: "hub2"}), false},
+		{NewEvent(HubActive, "", map[string]string{DataLeafHubName: "hub1"}), false},
+		// the event without the managed hub isn't filtered by the hubs
+		{NewEvent(CronJobFailed, "", map[string]string{DataJobName: "data-retention"}), true},
+	}
+	for _, c := range cases {
+		if matched := endpoint.Matches(c.event); matched != c.matched {
+			t.Errorf("expected the event %s %v to be matched: %v", c.event.Type, c.event.Data, c.matched)
+		}
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != "sha256="+Sign(body, "key") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// fail the first delivery
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := &notifier{log: log, httpClient: server.Client(), retryInterval: time.Millisecond}
+	event := NewEvent(HubInactive, "the hub hub1 is inactive", map[string]string{DataLeafHubName: "hub1"})
+	payload, _ := json.Marshal(event)
+
+	err := n.deliver(context.Background(), &Endpoint{Name: "test", URL: server.URL, Secret: "key"}, event, payload)
+	if err != nil {
+		t.Fatalf("failed to deliver the event: %v", err)
+	}
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("expected 2 attempts, got %d", atomic.LoadInt32(&attempts))
+	}
+
+	// the client error isn't retried
+	atomic.StoreInt32(&attempts, 0)
+	err = n.deliver(context.Background(), &Endpoint{Name: "test", URL: server.URL, Secret: "wrong"}, event, payload)
+	if err == nil {
+		t.Fatalf("expected the delivery to fail with the wrong secret")
+	}
+	if atomic.LoadInt32(&attempts) != 0 {
+		t.Errorf("expected no retries, got %d attempts", atomic.LoadInt32(&attempts))
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	var lock sync.Mutex
+	received := []*Event{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := &Event{}
+		if err := json.NewDecoder(r.Body).Decode(event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		received = append(received, event)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	namespace := "multicluster-global-hub"
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.GHNotificationSecretName, Namespace: namespace},
+		Data: map[string][]byte{
+			constants.GHNotificationConfigKey: []byte("endpoints:\n- name: test\n  url: " + server.URL +
+				"\n  eventTypes: [HubInactive]\n"),
+		},
+	}
+	n := &notifier{
+		log:           ctrl.Log.WithName("notification-test"),
+		client:        fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build(),
+		namespace:     namespace,
+		httpClient:    server.Client(),
+		retryInterval: time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = n.Start(ctx) }()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		Emit(NewEvent(HubActive, "the hub hub1 is active again", map[string]string{DataLeafHubName: "hub1"}))
+		Emit(NewEvent(HubInactive, "the hub hub1 is inactive", map[string]string{DataLeafHubName: "hub1"}))
+
+		lock.Lock()
+		count := len(received)
+		lock.Unlock()
+		if count > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the event isn't delivered")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	for _, event := range received {
+		if event.Type != HubInactive {
+			t.Errorf("the event %s shouldn't be delivered", event.Type)
+		}
+	}
+}
+
+func TestNotifierSlowEndpoint(t *testing.T) {
+	release := make(chan struct{})
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slowServer.Close()
+	defer close(release)
+
+	var delivered atomic.Int32
+	fastServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+	}))
+	defer fastServer.Close()
+
+	namespace := "multicluster-global-hub"
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.GHNotificationSecretName, Namespace: namespace},
+		Data: map[string][]byte{
+			constants.GHNotificationConfigKey: []byte("endpoints:\n- name: slow\n  url: " + slowServer.URL +
+				"\n  timeout: 1m\n- name: fast\n  url: " + fastServer.URL + "\n"),
+		},
+	}
+	n := &notifier{
+		log:           ctrl.Log.WithName("notification-test"),
+		client:        fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build(),
+		namespace:     namespace,
+		httpClient:    &http.Client{},
+		retryInterval: time.Millisecond,
+		workers:       map[string]*endpointWorker{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		n.dispatch(ctx, NewEvent(HubInactive, "the hub hub1 is inactive", map[string]string{DataLeafHubName: "hub1"}))
+	}
+
+	// the slow endpoint doesn't block the deliveries to the fast one
+	deadline := time.Now().Add(10 * time.Second)
+	for delivered.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 events delivered to the fast endpoint, got %d", delivered.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(n.workers) != 2 {
+		t.Errorf("expected the workers of 2 endpoints, got %d", len(n.workers))
+	}
+}
+
+func TestEmitDropsEvents(t *testing.T) {
+	queueLock.Lock()
+	queue = make(chan *Event, 1)
+	queueLock.Unlock()
+	defer func() {
+		queueLock.Lock()
+		queue = nil
+		queueLock.Unlock()
+	}()
+
+	dropped := droppedEvents.Load()
+	Emit(NewEvent(HubInactive, "the hub hub1 is inactive", nil))
+	Emit(NewEvent(HubInactive, "the hub hub2 is inactive", nil))
+	if droppedEvents.Load() != dropped+1 {
+		t.Errorf("expected 1 event dropped, got %d", droppedEvents.Load()-dropped)
+	}
+}
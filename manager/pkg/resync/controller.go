@@ -0,0 +1,239 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package resync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+const (
+	syncInterval = 5 * time.Second
+	// the hub status is maintained by the hub management
+	hubInactive = "inactive"
+)
+
+// resyncController sends the resync requests to the managed hubs, then tracks the progress with the bundle versions
+// recorded in status.leaf_hub_bundles. the bundles are processed by the replica owning the partition of the hub, so
+// the versions are read from the database instead of the conflation manager of the leader.
+type resyncController struct {
+	log      logr.Logger
+	producer transport.Producer
+	// bundleTypes maps the message keys registered in the manager to the bundle types
+	bundleTypes map[string]string
+	interval    time.Duration
+}
+
+func AddResyncController(mgr ctrl.Manager, producer transport.Producer, bundleTypes map[string]string) error {
+	return mgr.Add(&resyncController{
+		log:         ctrl.Log.WithName("resync-controller"),
+		producer:    producer,
+		bundleTypes: bundleTypes,
+		interval:    syncInterval,
+	})
+}
+
+func (c *resyncController) Start(ctx context.Context) error {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.reconcile(ctx); err != nil {
+					c.log.Error(err, "failed to reconcile the resyncs")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *resyncController) reconcile(ctx context.Context) error {
+	db := database.GetGorm()
+	var resyncs []models.Resync
+	if err := db.Where("phase IN ?", []string{PhasePending, PhaseRunning}).Order("created_at").
+		Find(&resyncs).Error; err != nil {
+		return err
+	}
+	for i := range resyncs {
+		resync := &resyncs[i]
+		var err error
+		if resync.Phase == PhasePending {
+			err = c.start(ctx, resync)
+		} else {
+			err = c.evaluate(resync)
+		}
+		if err != nil {
+			c.log.Error(err, "failed to reconcile the resync", "id", resync.ID)
+			resync.Message = err.Error()
+		}
+		if err := db.Save(resync).Error; err != nil {
+			return fmt.Errorf("failed to update the resync %s: %w", resync.ID, err)
+		}
+	}
+	return nil
+}
+
+// start sends the resync request to the hubs, the baseline versions are recorded before sending the request, so that
+// the bundles resent for the request are always newer than the baseline.
+func (c *resyncController) start(ctx context.Context, resync *models.Resync) error {
+	leafHubs, bundleKeys := []string{}, []string{}
+	if err := json.Unmarshal(resync.LeafHubs, &leafHubs); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(resync.BundleKeys, &bundleKeys); err != nil {
+		return err
+	}
+	bundleKeys, err := c.resolveBundleKeys(bundleKeys)
+	if err != nil {
+		now := time.Now()
+		resync.Phase, resync.CompletedAt = PhaseFailed, &now
+		return err
+	}
+
+	var heartbeats []models.LeafHubHeartbeat
+	if err := database.GetGorm().Find(&heartbeats).Error; err != nil {
+		return err
+	}
+	activeHubs := map[string]bool{}
+	for _, heartbeat := range heartbeats {
+		if heartbeat.Status != hubInactive {
+			activeHubs[heartbeat.Name] = true
+		}
+	}
+	if len(leafHubs) == 0 {
+		for hub := range activeHubs {
+			leafHubs = append(leafHubs, hub)
+		}
+	}
+
+	versions, err := recordedVersions(leafHubs)
+	if err != nil {
+		return fmt.Errorf("failed to get the bundles reported by the hubs: %w", err)
+	}
+	progress := NewProgress(leafHubs, activeHubs, bundleKeys, c.reportedBundleKeys(versions), c.versionFunc(versions))
+	payload, err := json.Marshal(bundleKeys)
+	if err != nil {
+		return err
+	}
+	for _, hub := range progress {
+		if hub.Phase == PhaseSkipped {
+			continue
+		}
+		if err := c.producer.Send(ctx, &transport.Message{
+			Key:         constants.ResyncMsgKey,
+			Destination: hub.Name,
+			MsgType:     constants.SpecBundle,
+			Payload:     payload,
+		}); err != nil {
+			// retry in the next round with the new baseline
+			return fmt.Errorf("failed to send the resync request to the hub %s: %w", hub.Name, err)
+		}
+	}
+
+	now := time.Now()
+	resync.Phase, resync.StartedAt = PhaseRunning, &now
+	resync.Message = fmt.Sprintf("the resync is requested to %d hubs", len(progress))
+	resync.Progress, err = json.Marshal(progress)
+	c.log.Info("resync is started", "id", resync.ID, "trigger", resync.Trigger, "hubs", len(progress),
+		"bundles", bundleKeys)
+	return err
+}
+
+func (c *resyncController) evaluate(resync *models.Resync) error {
+	progress := []HubProgress{}
+	if err := json.Unmarshal(resync.Progress, &progress); err != nil {
+		return err
+	}
+	leafHubs := make([]string, 0, len(progress))
+	for _, hub := range progress {
+		leafHubs = append(leafHubs, hub.Name)
+	}
+	versions, err := recordedVersions(leafHubs)
+	if err != nil {
+		return fmt.Errorf("failed to get the bundles processed for the hubs: %w", err)
+	}
+	now := time.Now()
+	deadlineExceeded := resync.StartedAt != nil &&
+		now.After(resync.StartedAt.Add(time.Duration(resync.TimeoutSeconds)*time.Second))
+
+	phase, message := Evaluate(progress, c.versionFunc(versions), now, deadlineExceeded)
+	payload, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	resync.Phase, resync.Message, resync.Progress = phase, message, payload
+	if phase != PhaseRunning {
+		resync.CompletedAt = &now
+		c.log.Info("resync is finished", "id", resync.ID, "phase", phase, "message", message)
+	}
+	return nil
+}
+
+// resolveBundleKeys expands the AllBundleKeys, and verifies the bundle keys are registered in the manager
+func (c *resyncController) resolveBundleKeys(bundleKeys []string) ([]string, error) {
+	resolved := []string{}
+	for _, key := range bundleKeys {
+		if key != AllBundleKeys {
+			if _, ok := c.bundleTypes[key]; !ok {
+				return nil, fmt.Errorf("the bundle %s isn't supported by the manager", key)
+			}
+			resolved = append(resolved, key)
+			continue
+		}
+		for registered := range c.bundleTypes {
+			// the heartbeat is sent periodically, and the snapshot request and the migration status are only sent on
+			// demand, they needn't to be resynced
+			if registered != constants.HubClusterHeartbeatMsgKey && registered != constants.SpecSnapshotRequestMsgKey &&
+				registered != constants.ManagedClusterMigrationStatusMsgKey {
+				resolved = append(resolved, registered)
+			}
+		}
+	}
+	sort.Strings(resolved)
+	deduplicated := []string{}
+	for i, key := range resolved {
+		if i == 0 || key != resolved[i-1] {
+			deduplicated = append(deduplicated, key)
+		}
+	}
+	return deduplicated, nil
+}
+
+// reportedBundleKeys maps the hubs to the keys of the bundles they have reported.
+func (c *resyncController) reportedBundleKeys(versions map[string]map[string]*metadata.BundleVersion,
+) map[string]map[string]bool {
+	reportedKeys := map[string]map[string]bool{}
+	for hub, bundleVersions := range versions {
+		reportedKeys[hub] = map[string]bool{}
+		for key, bundleType := range c.bundleTypes {
+			if _, ok := bundleVersions[bundleType]; ok {
+				reportedKeys[hub][key] = true
+			}
+		}
+	}
+	return reportedKeys
+}
+
+// versionFunc returns the recorded versions of the bundles by the keys.
+func (c *resyncController) versionFunc(versions map[string]map[string]*metadata.BundleVersion) VersionFunc {
+	return func(leafHubName, bundleKey string) *metadata.BundleVersion {
+		return versions[leafHubName][c.bundleTypes[bundleKey]]
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package resync
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gorm.io/gorm/clause"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+// ProcessedVersionsFunc returns the versions of the bundles processed by the manager replica, it maps the leaf hub to
+// the bundle types.
+type ProcessedVersionsFunc func() map[string]map[string]metadata.BundleVersion
+
+// versionRecorder records the bundle versions processed by the manager replica in status.leaf_hub_bundles, so that
+// the resync controller knows the bundles reported by each hub. only the changed versions are written.
+type versionRecorder struct {
+	log               logr.Logger
+	processedVersions ProcessedVersionsFunc
+	recorded          map[string]map[string]metadata.BundleVersion
+	interval          time.Duration
+}
+
+// NewVersionRecorder returns the runnable recording the processed versions, it should run on every replica which
+// processes the status bundles.
+func NewVersionRecorder(processedVersions ProcessedVersionsFunc) manager.Runnable {
+	return &versionRecorder{
+		log:               ctrl.Log.WithName("resync-version-recorder"),
+		processedVersions: processedVersions,
+		recorded:          map[string]map[string]metadata.BundleVersion{},
+		interval:          syncInterval,
+	}
+}
+
+func (r *versionRecorder) Start(ctx context.Context) error {
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.record(ctx); err != nil {
+					r.log.Error(err, "failed to record the processed bundle versions")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *versionRecorder) record(ctx context.Context) error {
+	versions := r.processedVersions()
+	changed := []models.LeafHubBundle{}
+	for hub, bundles := range versions {
+		for bundleType, version := range bundles {
+			if recorded, ok := r.recorded[hub][bundleType]; ok && recorded.Equals(&version) {
+				continue
+			}
+			changed = append(changed, models.LeafHubBundle{
+				LeafHubName: hub,
+				BundleType:  bundleType,
+				Generation:  int64(version.Generation),
+				Value:       int64(version.Value),
+			})
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	err := database.GetGorm().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "leaf_hub_name"}, {Name: "bundle_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"generation", "value", "updated_at"}),
+	}).Create(&changed).Error
+	if err != nil {
+		return err
+	}
+	// the hubs which are handed over to the other replicas are recorded by them
+	r.recorded = versions
+	return nil
+}
+
+// recordedVersions returns the bundle versions recorded by all the manager replicas for the hubs, it maps the hubs to
+// the bundle types.
+func recordedVersions(leafHubs []string) (map[string]map[string]*metadata.BundleVersion, error) {
+	var bundles []models.LeafHubBundle
+	if err := database.GetGorm().Where("leaf_hub_name IN ?", leafHubs).Find(&bundles).Error; err != nil {
+		return nil, err
+	}
+	versions := map[string]map[string]*metadata.BundleVersion{}
+	for _, bundle := range bundles {
+		if versions[bundle.LeafHubName] == nil {
+			versions[bundle.LeafHubName] = map[string]*metadata.BundleVersion{}
+		}
+		versions[bundle.LeafHubName][bundle.BundleType] = &metadata.BundleVersion{
+			Generation: uint64(bundle.Generation),
+			Value:      uint64(bundle.Value),
+		}
+	}
+	return versions, nil
+}
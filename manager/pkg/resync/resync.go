@@ -0,0 +1,196 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package resync
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+const (
+	PhasePending   = "Pending"
+	PhaseRunning   = "Running"
+	PhaseCompleted = "Completed"
+	PhaseTimedOut  = "TimedOut"
+	PhaseFailed    = "Failed"
+	// PhaseSkipped means the hub isn't active when the resync starts, so it isn't requested
+	PhaseSkipped = "Skipped"
+
+	// AllBundleKeys requests all the bundles registered in the manager
+	AllBundleKeys = "*"
+
+	TriggerAPI             = "api"
+	TriggerHubReactivated  = "hub-reactivated"
+	TriggerDatabaseRestore = "database-restore"
+
+	DefaultTimeout = 10 * time.Minute
+)
+
+// DefaultBundleKeys are the bundles resynced if the bundle keys aren't specified. the hub only waits for the ones it
+// reports, e.g. the gitops applications are only reported by the hub with Argo CD.
+var DefaultBundleKeys = []string{
+	constants.HubClusterInfoMsgKey,
+	constants.ManagedClustersMsgKey,
+	constants.ManagedClusterAddOnsMsgKey,
+	constants.GitOpsApplicationsMsgKey,
+	constants.LocalPolicySpecMsgKey,
+	constants.LocalComplianceMsgKey,
+}
+
+// HubProgress is the resync progress of a managed hub.
+type HubProgress struct {
+	Name    string           `json:"name"`
+	Phase   string           `json:"phase"`
+	Bundles []BundleProgress `json:"bundles,omitempty"`
+}
+
+// BundleProgress is the resync progress of a bundle, the resync is completed once a bundle with a version other
+// than the baseline is processed by the manager.
+type BundleProgress struct {
+	Key   string `json:"key"`
+	Phase string `json:"phase"`
+	// Baseline is the version processed before the resync is requested
+	Baseline    *metadata.BundleVersion `json:"baseline,omitempty"`
+	Processed   *metadata.BundleVersion `json:"processed,omitempty"`
+	CompletedAt *time.Time              `json:"completedAt,omitempty"`
+}
+
+// VersionFunc returns the last processed version of the bundle from the managed hub, or nil if it isn't received.
+type VersionFunc func(leafHubName, bundleKey string) *metadata.BundleVersion
+
+// Create requests to resync the bundles from the managed hubs. the empty hubs means all the active hubs, and the
+// empty bundle keys means the DefaultBundleKeys.
+func Create(db *gorm.DB, leafHubs, bundleKeys []string, trigger string, timeout time.Duration) (*models.Resync, error) {
+	if leafHubs == nil {
+		leafHubs = []string{}
+	}
+	if len(bundleKeys) == 0 {
+		bundleKeys = DefaultBundleKeys
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	hubsPayload, err := json.Marshal(leafHubs)
+	if err != nil {
+		return nil, err
+	}
+	keysPayload, err := json.Marshal(bundleKeys)
+	if err != nil {
+		return nil, err
+	}
+	resync := &models.Resync{
+		ID:             uuid.New().String(),
+		LeafHubs:       hubsPayload,
+		BundleKeys:     keysPayload,
+		Trigger:        trigger,
+		TimeoutSeconds: int(timeout.Seconds()),
+		Phase:          PhasePending,
+	}
+	if err := db.Create(resync).Error; err != nil {
+		return nil, fmt.Errorf("failed to create the resync: %w", err)
+	}
+	return resync, nil
+}
+
+// NewProgress records the baseline versions of the bundles on the hubs, the inactive hubs are skipped. the reportedKeys
+// maps the hub to the bundle keys it has reported, the hub only waits for them since the bundles of the disabled
+// syncers are never sent. all the bundles are waited for if the hub hasn't reported any yet.
+func NewProgress(leafHubs []string, activeHubs map[string]bool, bundleKeys []string,
+	reportedKeys map[string]map[string]bool, versionFunc VersionFunc,
+) []HubProgress {
+	progress := make([]HubProgress, 0, len(leafHubs))
+	for _, hub := range leafHubs {
+		if !activeHubs[hub] {
+			progress = append(progress, HubProgress{Name: hub, Phase: PhaseSkipped})
+			continue
+		}
+		bundles := make([]BundleProgress, 0, len(bundleKeys))
+		reported, found := reportedKeys[hub]
+		for _, key := range bundleKeys {
+			if found && !reported[key] {
+				continue
+			}
+			bundles = append(bundles, BundleProgress{
+				Key:      key,
+				Phase:    PhaseRunning,
+				Baseline: versionFunc(hub, key),
+			})
+		}
+		progress = append(progress, HubProgress{Name: hub, Phase: PhaseRunning, Bundles: bundles})
+	}
+	sort.Slice(progress, func(i, j int) bool { return progress[i].Name < progress[j].Name })
+	return progress
+}
+
+// Evaluate updates the progress with the processed versions, the running bundles are timed out once the deadline is
+// exceeded. it returns the phase and the message of the resync.
+func Evaluate(progress []HubProgress, versionFunc VersionFunc, now time.Time, deadlineExceeded bool,
+) (string, string) {
+	completedHubs, timedOutBundles := 0, []string{}
+	for i := range progress {
+		hub := &progress[i]
+		if hub.Phase == PhaseSkipped {
+			continue
+		}
+		hubPhase := PhaseCompleted
+		for j := range hub.Bundles {
+			bundle := &hub.Bundles[j]
+			if bundle.Phase == PhaseRunning {
+				if processed := versionFunc(hub.Name, bundle.Key); isResynced(bundle.Baseline, processed) {
+					completedAt := now
+					bundle.Phase, bundle.Processed, bundle.CompletedAt = PhaseCompleted, processed, &completedAt
+				} else if deadlineExceeded {
+					bundle.Phase = PhaseTimedOut
+				}
+			}
+			switch bundle.Phase {
+			case PhaseRunning:
+				hubPhase = PhaseRunning
+			case PhaseTimedOut:
+				timedOutBundles = append(timedOutBundles, fmt.Sprintf("%s/%s", hub.Name, bundle.Key))
+				if hubPhase != PhaseRunning {
+					hubPhase = PhaseTimedOut
+				}
+			}
+		}
+		hub.Phase = hubPhase
+		if hubPhase == PhaseCompleted {
+			completedHubs++
+		}
+	}
+
+	if len(timedOutBundles) > 0 {
+		return PhaseTimedOut, fmt.Sprintf("the bundles aren't resynced within the timeout: %s",
+			strings.Join(timedOutBundles, ", "))
+	}
+	total := 0
+	for _, hub := range progress {
+		if hub.Phase != PhaseSkipped {
+			total++
+		}
+	}
+	if completedHubs == total {
+		return PhaseCompleted, fmt.Sprintf("the bundles are resynced from %d hubs", total)
+	}
+	return PhaseRunning, fmt.Sprintf("the bundles are resynced from %d of %d hubs", completedHubs, total)
+}
+
+// isResynced returns true if a bundle other than the baseline is processed. the version might be reset by the
+// restarted agent, so any version different from the baseline means the bundle is resent.
+func isResynced(baseline, processed *metadata.BundleVersion) bool {
+	if processed == nil || processed.Equals(metadata.NewBundleVersion()) {
+		return false
+	}
+	return baseline == nil || !processed.Equals(baseline)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package resync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+func TestResyncProgress(t *testing.T) {
+	versions := map[string]*metadata.BundleVersion{
+		"hub1/" + constants.ManagedClustersMsgKey: {Generation: 3, Value: 2},
+	}
+	versionFunc := func(leafHubName, bundleKey string) *metadata.BundleVersion {
+		version, ok := versions[leafHubName+"/"+bundleKey]
+		if !ok {
+			return nil
+		}
+		copied := *version
+		return &copied
+	}
+
+	bundleKeys := []string{
+		constants.ManagedClustersMsgKey, constants.LocalComplianceMsgKey, constants.GitOpsApplicationsMsgKey,
+	}
+	// hub1 doesn't report the gitops applications, and hub2 hasn't reported any bundle yet
+	reportedKeys := map[string]map[string]bool{
+		"hub1": {constants.ManagedClustersMsgKey: true, constants.LocalComplianceMsgKey: true},
+	}
+	progress := NewProgress([]string{"hub2", "hub1", "hub3"}, map[string]bool{"hub1": true, "hub2": true},
+		bundleKeys, reportedKeys, versionFunc)
+	require.Len(t, progress, 3)
+	assert.Len(t, progress[0].Bundles, 2)
+	assert.Len(t, progress[1].Bundles, 3)
+	assert.Equal(t, "hub1", progress[0].Name)
+	assert.Equal(t, &metadata.BundleVersion{Generation: 3, Value: 2}, progress[0].Bundles[0].Baseline)
+	assert.Nil(t, progress[0].Bundles[1].Baseline)
+	assert.Equal(t, PhaseSkipped, progress[2].Phase)
+	assert.Empty(t, progress[2].Bundles)
+
+	now := time.Now()
+	phase, message := Evaluate(progress, versionFunc, now, false)
+	assert.Equal(t, PhaseRunning, phase)
+	assert.Equal(t, "the bundles are resynced from 0 of 2 hubs", message)
+
+	// hub1 resends the bundles, the local compliance and the applications of hub2 are resent by the restarted agent
+	versions["hub1/"+constants.ManagedClustersMsgKey] = &metadata.BundleVersion{Generation: 3, Value: 3}
+	versions["hub1/"+constants.LocalComplianceMsgKey] = &metadata.BundleVersion{Generation: 0, Value: 1}
+	versions["hub2/"+constants.LocalComplianceMsgKey] = &metadata.BundleVersion{Generation: 0, Value: 1}
+	versions["hub2/"+constants.GitOpsApplicationsMsgKey] = &metadata.BundleVersion{Generation: 0, Value: 1}
+	phase, message = Evaluate(progress, versionFunc, now, false)
+	assert.Equal(t, PhaseRunning, phase)
+	assert.Equal(t, "the bundles are resynced from 1 of 2 hubs", message)
+	assert.Equal(t, PhaseCompleted, progress[0].Phase)
+	assert.Equal(t, &metadata.BundleVersion{Generation: 3, Value: 3}, progress[0].Bundles[0].Processed)
+	assert.Equal(t, PhaseRunning, progress[1].Phase)
+	assert.Equal(t, PhaseRunning, progress[1].Bundles[0].Phase)
+	assert.Equal(t, PhaseCompleted, progress[1].Bundles[1].Phase)
+
+	// the managed clusters of hub2 aren't resent before the deadline
+	phase, message = Evaluate(progress, versionFunc, now.Add(time.Minute), true)
+	assert.Equal(t, PhaseTimedOut, phase)
+	assert.Equal(t, "the bundles aren't resynced within the timeout: hub2/ManagedClusters", message)
+	assert.Equal(t, PhaseCompleted, progress[0].Phase)
+	assert.Equal(t, PhaseTimedOut, progress[1].Phase)
+	assert.Equal(t, PhaseTimedOut, progress[1].Bundles[0].Phase)
+}
+
+func TestIsResynced(t *testing.T) {
+	cases := []struct {
+		name      string
+		baseline  *metadata.BundleVersion
+		processed *metadata.BundleVersion
+		expected  bool
+	}{
+		{name: "not received", expected: false},
+		{name: "reset by the agent", processed: metadata.NewBundleVersion(), expected: false},
+		{name: "first received", processed: &metadata.BundleVersion{Value: 1}, expected: true},
+		{
+			name:      "not resent",
+			baseline:  &metadata.BundleVersion{Generation: 1, Value: 1},
+			processed: &metadata.BundleVersion{Generation: 1, Value: 1},
+			expected:  false,
+		},
+		{
+			name:      "resent",
+			baseline:  &metadata.BundleVersion{Generation: 1, Value: 1},
+			processed: &metadata.BundleVersion{Generation: 1, Value: 2},
+			expected:  true,
+		},
+		{
+			name:      "resent by the restarted agent",
+			baseline:  &metadata.BundleVersion{Generation: 5, Value: 1},
+			processed: &metadata.BundleVersion{Generation: 0, Value: 1},
+			expected:  true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isResynced(tc.baseline, tc.processed))
+		})
+	}
+}
+
+func TestResolveBundleKeys(t *testing.T) {
+	c := &resyncController{bundleTypes: map[string]string{
+		constants.HubClusterHeartbeatMsgKey: "HubClusterHeartbeatBundle",
+		constants.ManagedClustersMsgKey:     "ManagedClusterBundle",
+		constants.LocalComplianceMsgKey:     "LocalComplianceBundle",
+	}}
+
+	keys, err := c.resolveBundleKeys([]string{constants.ManagedClustersMsgKey})
+	require.NoError(t, err)
+	assert.Equal(t, []string{constants.ManagedClustersMsgKey}, keys)
+
+	keys, err = c.resolveBundleKeys([]string{AllBundleKeys, constants.ManagedClustersMsgKey})
+	require.NoError(t, err)
+	assert.Equal(t, []string{constants.LocalComplianceMsgKey, constants.ManagedClustersMsgKey}, keys)
+
+	_, err = c.resolveBundleKeys([]string{constants.PlacementMsgKey})
+	assert.Error(t, err)
+}
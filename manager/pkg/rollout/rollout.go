@@ -0,0 +1,142 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package rollout
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	PhaseProgressing = "Progressing"
+	PhasePaused      = "Paused"
+	PhaseRolledBack  = "RolledBack"
+	PhaseCompleted   = "Completed"
+)
+
+// WaveStatus is the progress of the policy version on the hubs of the wave.
+type WaveStatus struct {
+	Name        string     `json:"name"`
+	Hubs        []string   `json:"hubs"`
+	Percentage  int        `json:"percentage"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// State is the rollout state of a policy version.
+type State struct {
+	Phase       string       `json:"phase"`
+	CurrentWave int          `json:"currentWave"`
+	Waves       []WaveStatus `json:"waves"`
+	Message     string       `json:"message,omitempty"`
+}
+
+// PercentageFunc returns the percentage of the threshold type for the given hubs. only the status reported for the
+// rolled out version is counted, so the wave is evaluated as soon as it's started.
+type PercentageFunc func(hubs []string) (int, error)
+
+// NewState starts rolling out a new policy version from the first wave.
+func NewState() *State {
+	return &State{
+		Phase:       PhaseProgressing,
+		CurrentWave: 0,
+		Waves:       []WaveStatus{},
+	}
+}
+
+// RefreshWaves updates the hubs of the waves since the hub labels might be changed, the progress of the waves with
+// the same name is kept.
+func (s *State) RefreshWaves(waves []WaveStatus) {
+	previous := map[string]WaveStatus{}
+	for _, wave := range s.Waves {
+		previous[wave.Name] = wave
+	}
+	for i := range waves {
+		if wave, ok := previous[waves[i].Name]; ok {
+			waves[i].Percentage = wave.Percentage
+			waves[i].StartedAt = wave.StartedAt
+			waves[i].CompletedAt = wave.CompletedAt
+		}
+	}
+	s.Waves = waves
+}
+
+// Progress evaluates the current wave, then advances the rollout to the next wave if the threshold is reached.
+// if the wave doesn't reach the threshold within the timeout, the rollout is paused or rolled back.
+func (s *State) Progress(strategy *Strategy, percentageFunc PercentageFunc, now time.Time) error {
+	for s.Phase == PhaseProgressing {
+		if s.CurrentWave >= len(s.Waves) {
+			s.Phase = PhaseCompleted
+			s.Message = "the policy is rolled out to all the hubs"
+			return nil
+		}
+
+		wave := &s.Waves[s.CurrentWave]
+		if wave.StartedAt == nil {
+			startedAt := now
+			wave.StartedAt = &startedAt
+			s.Message = fmt.Sprintf("rolling out to the wave %s", wave.Name)
+		}
+
+		// skip the empty wave
+		if len(wave.Hubs) == 0 {
+			wave.Percentage = 100
+		} else {
+			percentage, err := percentageFunc(wave.Hubs)
+			if err != nil {
+				return err
+			}
+			wave.Percentage = percentage
+		}
+
+		if wave.Percentage >= strategy.ThresholdPercentage {
+			completedAt := now
+			wave.CompletedAt = &completedAt
+			s.CurrentWave++
+			s.Message = fmt.Sprintf("the wave %s reached the threshold", wave.Name)
+			continue
+		}
+
+		if now.Sub(*wave.StartedAt) > strategy.WaveTimeout.Duration {
+			s.Message = fmt.Sprintf("the wave %s didn't reach the %s threshold %d%% within %s, got %d%%", wave.Name,
+				strategy.ThresholdType, strategy.ThresholdPercentage, strategy.WaveTimeout.Duration.String(),
+				wave.Percentage)
+			s.Phase = PhasePaused
+			if strategy.OnFailure == FailureRollback {
+				s.Phase = PhaseRolledBack
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// ReceivesTarget returns true if the hub should run the policy version of the rollout, otherwise the hub runs the
+// stable version which has been rolled out completely before.
+func (s *State) ReceivesTarget(hub string) bool {
+	switch s.Phase {
+	case PhaseCompleted:
+		return true
+	case PhaseRolledBack:
+		return false
+	}
+	for i := 0; i <= s.CurrentWave && i < len(s.Waves); i++ {
+		for _, waveHub := range s.Waves[i].Hubs {
+			if waveHub == hub {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortedHubs(hubLabels map[string]map[string]string) []string {
+	hubs := make([]string, 0, len(hubLabels))
+	for hub := range hubLabels {
+		hubs = append(hubs, hub)
+	}
+	sort.Strings(hubs)
+	return hubs
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package rollout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStrategy(t *testing.T) {
+	strategy, err := ParseStrategy(`{"waves":[{"name":"canary","hubSelector":{"matchLabels":{"env":"dev"}}}]}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ThresholdApplied, strategy.ThresholdType)
+	assert.Equal(t, 100, strategy.ThresholdPercentage)
+	assert.Equal(t, 30*time.Minute, strategy.WaveTimeout.Duration)
+	assert.Equal(t, FailurePause, strategy.OnFailure)
+
+	strategy, err = ParseStrategy(`{"waves":[],"thresholdType":"Compliant","thresholdPercentage":80,
+		"waveTimeout":"1h","onFailure":"Rollback"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, ThresholdCompliant, strategy.ThresholdType)
+	assert.Equal(t, 80, strategy.ThresholdPercentage)
+	assert.Equal(t, time.Hour, strategy.WaveTimeout.Duration)
+	assert.Equal(t, FailureRollback, strategy.OnFailure)
+
+	_, err = ParseStrategy(`{"waves":[{"name":""}]}`)
+	assert.NotNil(t, err)
+	_, err = ParseStrategy(`{"thresholdType":"Unknown"}`)
+	assert.NotNil(t, err)
+	_, err = ParseStrategy(`{"thresholdPercentage":120}`)
+	assert.NotNil(t, err)
+	_, err = ParseStrategy(`invalid`)
+	assert.NotNil(t, err)
+}
+
+func TestAssignHubs(t *testing.T) {
+	strategy, err := ParseStrategy(`{"waves":[
+		{"name":"canary","hubSelector":{"matchLabels":{"env":"dev"}}},
+		{"name":"east","hubSelector":{"matchExpressions":[{"key":"region","operator":"In","values":["east"]}]}}]}`)
+	assert.Nil(t, err)
+
+	waves := strategy.AssignHubs(map[string]map[string]string{
+		"hub1": {"env": "dev", "region": "east"},
+		"hub2": {"env": "prod", "region": "east"},
+		"hub3": {"env": "prod", "region": "west"},
+		"hub4": {},
+	})
+	assert.Len(t, waves, 3)
+	assert.Equal(t, []string{"hub1"}, waves[0].Hubs)
+	assert.Equal(t, []string{"hub2"}, waves[1].Hubs)
+	assert.Equal(t, DefaultWaveName, waves[2].Name)
+	assert.Equal(t, []string{"hub3", "hub4"}, waves[2].Hubs)
+}
+
+func TestProgress(t *testing.T) {
+	strategy, err := ParseStrategy(`{"waves":[{"name":"canary"},{"name":"prod"}],"thresholdPercentage":50,
+		"waveTimeout":"10m"}`)
+	assert.Nil(t, err)
+
+	percentages := map[string]int{}
+	percentageFunc := func(hubs []string) (int, error) { return percentages[hubs[0]], nil }
+
+	state := NewState()
+	state.RefreshWaves([]WaveStatus{
+		{Name: "canary", Hubs: []string{"hub1"}},
+		{Name: "prod", Hubs: []string{}},
+		{Name: DefaultWaveName, Hubs: []string{"hub2"}},
+	})
+
+	now := time.Now()
+	// start the first wave
+	assert.Nil(t, state.Progress(strategy, percentageFunc, now))
+	assert.Equal(t, PhaseProgressing, state.Phase)
+	assert.Equal(t, 0, state.CurrentWave)
+	assert.True(t, state.ReceivesTarget("hub1"))
+	assert.False(t, state.ReceivesTarget("hub2"))
+
+	// the first wave reaches the threshold, skip the empty wave and start the default wave
+	percentages["hub1"] = 60
+	assert.Nil(t, state.Progress(strategy, percentageFunc, now.Add(time.Minute)))
+	assert.Equal(t, PhaseProgressing, state.Phase)
+	assert.Equal(t, 2, state.CurrentWave)
+	assert.True(t, state.ReceivesTarget("hub2"))
+
+	// the hub labels are changed, the progress is kept
+	state.RefreshWaves([]WaveStatus{
+		{Name: "canary", Hubs: []string{"hub1"}},
+		{Name: "prod", Hubs: []string{}},
+		{Name: DefaultWaveName, Hubs: []string{"hub2", "hub3"}},
+	})
+	assert.NotNil(t, state.Waves[2].StartedAt)
+
+	// the default wave doesn't reach the threshold in time
+	percentages["hub2"] = 10
+	assert.Nil(t, state.Progress(strategy, percentageFunc, now.Add(5*time.Minute)))
+	assert.Equal(t, PhaseProgressing, state.Phase)
+	assert.Nil(t, state.Progress(strategy, percentageFunc, now.Add(20*time.Minute)))
+	assert.Equal(t, PhasePaused, state.Phase)
+	assert.Equal(t, 10, state.Waves[2].Percentage)
+
+	// roll back on failure
+	strategy.OnFailure = FailureRollback
+	state.Phase = PhaseProgressing
+	assert.Nil(t, state.Progress(strategy, percentageFunc, now.Add(20*time.Minute)))
+	assert.Equal(t, PhaseRolledBack, state.Phase)
+	assert.False(t, state.ReceivesTarget("hub1"))
+
+	// complete the rollout
+	state.Phase = PhaseProgressing
+	percentages["hub2"] = 100
+	assert.Nil(t, state.Progress(strategy, percentageFunc, now.Add(21*time.Minute)))
+	assert.Equal(t, PhaseCompleted, state.Phase)
+	assert.True(t, state.ReceivesTarget("hub3"))
+}
+
+func TestProgressWithArrivedCompliance(t *testing.T) {
+	strategy, err := ParseStrategy(`{"waves":[{"name":"canary"}]}`)
+	assert.Nil(t, err)
+
+	state := NewState()
+	state.RefreshWaves([]WaveStatus{{Name: "canary", Hubs: []string{"hub1"}}, {Name: DefaultWaveName}})
+
+	// the compliance of the rolled out version is counted in the round which starts the wave
+	assert.Nil(t, state.Progress(strategy, func(hubs []string) (int, error) { return 100, nil }, time.Now()))
+	assert.Equal(t, PhaseCompleted, state.Phase)
+	assert.NotNil(t, state.Waves[0].CompletedAt)
+}
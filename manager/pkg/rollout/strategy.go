@@ -0,0 +1,117 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package rollout
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// ThresholdApplied is the percentage of the hubs in the wave which report the status of the policy
+	ThresholdApplied = "Applied"
+	// ThresholdCompliant is the percentage of the compliant clusters from the hubs in the wave
+	ThresholdCompliant = "Compliant"
+
+	FailurePause    = "Pause"
+	FailureRollback = "Rollback"
+
+	// DefaultWaveName is the last wave of the rollout, it includes the hubs which aren't selected by any wave
+	DefaultWaveName = "default"
+
+	defaultThresholdPercentage = 100
+	defaultWaveTimeout         = 30 * time.Minute
+)
+
+// Strategy is the rollout strategy declared by the annotation of the global policy. the waves are evaluated by the
+// compliance stored in the database every spec sync interval, so a wave takes at least one interval, e.g.
+//
+//	{"waves":[{"name":"canary","hubSelector":{"matchLabels":{"env":"dev"}}}],
+//	 "thresholdType":"Compliant","thresholdPercentage":90,"waveTimeout":"1h","onFailure":"Rollback"}
+type Strategy struct {
+	Waves               []Wave          `json:"waves"`
+	ThresholdType       string          `json:"thresholdType,omitempty"`
+	ThresholdPercentage int             `json:"thresholdPercentage,omitempty"`
+	WaveTimeout         metav1.Duration `json:"waveTimeout,omitempty"`
+	OnFailure           string          `json:"onFailure,omitempty"`
+}
+
+// Wave selects the managed hubs by the labels of the hub cluster.
+type Wave struct {
+	Name        string                `json:"name"`
+	HubSelector *metav1.LabelSelector `json:"hubSelector,omitempty"`
+}
+
+// ParseStrategy parses the strategy from the annotation value and sets the default values.
+func ParseStrategy(value string) (*Strategy, error) {
+	strategy := &Strategy{}
+	if err := json.Unmarshal([]byte(value), strategy); err != nil {
+		return nil, fmt.Errorf("invalid rollout strategy: %w", err)
+	}
+
+	if strategy.ThresholdType == "" {
+		strategy.ThresholdType = ThresholdApplied
+	}
+	if strategy.ThresholdType != ThresholdApplied && strategy.ThresholdType != ThresholdCompliant {
+		return nil, fmt.Errorf("invalid rollout threshold type: %s", strategy.ThresholdType)
+	}
+	if strategy.ThresholdPercentage == 0 {
+		strategy.ThresholdPercentage = defaultThresholdPercentage
+	}
+	if strategy.ThresholdPercentage < 0 || strategy.ThresholdPercentage > 100 {
+		return nil, fmt.Errorf("invalid rollout threshold percentage: %d", strategy.ThresholdPercentage)
+	}
+	if strategy.WaveTimeout.Duration == 0 {
+		strategy.WaveTimeout.Duration = defaultWaveTimeout
+	}
+	if strategy.OnFailure == "" {
+		strategy.OnFailure = FailurePause
+	}
+	if strategy.OnFailure != FailurePause && strategy.OnFailure != FailureRollback {
+		return nil, fmt.Errorf("invalid rollout failure action: %s", strategy.OnFailure)
+	}
+
+	for i, wave := range strategy.Waves {
+		if wave.Name == "" {
+			return nil, fmt.Errorf("the name of the wave %d is empty", i)
+		}
+		if _, err := metav1.LabelSelectorAsSelector(wave.HubSelector); err != nil {
+			return nil, fmt.Errorf("invalid hub selector of the wave %s: %w", wave.Name, err)
+		}
+	}
+	return strategy, nil
+}
+
+// AssignHubs groups the hubs into the waves by the hub labels. the hub is in the first wave it matches, and the
+// hubs which aren't selected by any wave are in the last default wave.
+func (s *Strategy) AssignHubs(hubLabels map[string]map[string]string) []WaveStatus {
+	waves := make([]WaveStatus, 0, len(s.Waves)+1)
+	selectors := make([]labels.Selector, 0, len(s.Waves))
+	for _, wave := range s.Waves {
+		// the selector is validated when parsing the strategy
+		selector, _ := metav1.LabelSelectorAsSelector(wave.HubSelector)
+		selectors = append(selectors, selector)
+		waves = append(waves, WaveStatus{Name: wave.Name, Hubs: []string{}})
+	}
+	waves = append(waves, WaveStatus{Name: DefaultWaveName, Hubs: []string{}})
+
+	for _, hub := range sortedHubs(hubLabels) {
+		assigned := false
+		for i, selector := range selectors {
+			if selector.Matches(labels.Set(hubLabels[hub])) {
+				waves[i].Hubs = append(waves[i].Hubs, hub)
+				assigned = true
+				break
+			}
+		}
+		if !assigned {
+			waves[len(waves)-1].Hubs = append(waves[len(waves)-1].Hubs, hub)
+		}
+	}
+	return waves
+}
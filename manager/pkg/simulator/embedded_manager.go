@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/statussyncer/dispatcher"
+	dbsyncer "github.com/stolostron/multicluster-global-hub/manager/pkg/statussyncer/syncers"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/cluster"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/grc"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator/workerpool"
+	"github.com/stolostron/multicluster-global-hub/pkg/statistics"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/consumer"
+)
+
+// embeddedManager runs the status path of the global hub manager in the simulator process: transport consumer ->
+// transport dispatcher -> conflation manager -> conflation dispatcher -> db worker pool. It doesn't need a cluster,
+// so the bundles can be stored into the database without deploying the manager.
+type embeddedManager struct {
+	stats     *statistics.Statistics
+	runnables []manager.Runnable
+}
+
+func newEmbeddedManager(transportConfig *transport.TransportConfig) (*embeddedManager, error) {
+	// the statistics are reported by the simulator instead of the periodic log
+	stats := statistics.NewStatistics(&statistics.StatisticsConfig{LogInterval: "0s"}, []string{
+		bundle.GetBundleType(&cluster.HubClusterHeartbeatBundle{}),
+		bundle.GetBundleType(&cluster.ManagedClusterBundle{}),
+		bundle.GetBundleType(&grc.LocalPolicyBundle{}),
+		bundle.GetBundleType(&grc.LocalComplianceBundle{}),
+		bundle.GetBundleType(&grc.LocalCompleteComplianceBundle{}),
+	})
+
+	conflationReadyQueue := conflator.NewConflationReadyQueue(stats)
+	conflationManager := conflator.NewConflationManager(conflationReadyQueue, stats)
+
+	dbWorkerPool, err := workerpool.NewDBWorkerPool(stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize DBWorkerPool: %w", err)
+	}
+
+	transportConsumer, err := consumer.NewGenericConsumer(transportConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize transport consumer: %w", err)
+	}
+	transportDispatcher := dispatcher.NewTransportDispatcher(
+		ctrl.Log.WithName("transport-dispatcher"), transportConsumer, conflationManager, stats)
+
+	// register all the syncers of the manager without the global resources, since the priorities of the conflation
+	// unit must be consecutive
+	dbSyncers := []dbsyncer.Syncer{
+		dbsyncer.NewHubClusterHeartbeatSyncer(ctrl.Log.WithName("hub-heartbeat-syncer")),
+		dbsyncer.NewHubClusterInfoDBSyncer(ctrl.Log.WithName("hub-info-syncer")),
+		dbsyncer.NewManagedClustersDBSyncer(ctrl.Log.WithName("managed-cluster-syncer")),
+		dbsyncer.NewCompliancesDBSyncer(ctrl.Log.WithName("compliances-syncer")),
+		dbsyncer.NewLocalPolicySpecSyncer(ctrl.Log.WithName("local-policy-spec-syncer")),
+		dbsyncer.NewLocalPolicyEventSyncer(ctrl.Log.WithName("local-policy-event-syncer")),
+		dbsyncer.NewManagedClusterAddOnsDBSyncer(ctrl.Log.WithName("managed-cluster-addons-syncer")),
+		dbsyncer.NewGitOpsApplicationsDBSyncer(ctrl.Log.WithName("gitops-applications-syncer")),
+	}
+	for _, dbsyncerObj := range dbSyncers {
+		dbsyncerObj.RegisterCreateBundleFunctions(transportDispatcher)
+		dbsyncerObj.RegisterBundleHandlerFunctions(conflationManager)
+	}
+
+	return &embeddedManager{
+		stats: stats,
+		runnables: []manager.Runnable{
+			stats,
+			dbWorkerPool,
+			transportConsumer,
+			transportDispatcher,
+			dispatcher.NewConflationDispatcher(ctrl.Log.WithName("conflation-dispatcher"),
+				conflationReadyQueue, dbWorkerPool),
+		},
+	}, nil
+}
+
+// Start runs the components until the context is done, or any of them fails.
+func (m *embeddedManager) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errChan := make(chan error, len(m.runnables))
+	var wg sync.WaitGroup
+	for _, runnable := range m.runnables {
+		wg.Add(1)
+		go func(runnable manager.Runnable) {
+			defer wg.Done()
+			if err := runnable.Start(ctx); err != nil {
+				errChan <- err
+				cancel()
+			}
+		}(runnable)
+	}
+	wg.Wait()
+	close(errChan)
+	return <-errChan
+}
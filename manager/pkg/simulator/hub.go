@@ -0,0 +1,366 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/cluster"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/grc"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+const (
+	// SentTimeAnnotation is stamped on the probe cluster of each simulated hub with the time the bundle is sent, the
+	// end-to-end latency is measured once the annotation is observed in the database.
+	SentTimeAnnotation = "global-hub.open-cluster-management.io/simulator-sent-time"
+
+	clusterIDClaim        = "id.k8s.io"
+	simulatedPolicyPrefix = "simulated-policy"
+)
+
+// the configuration policy wrapped by the simulated local policies, to keep the payload in a realistic size
+const configurationPolicyTemplate = `{"apiVersion":"policy.open-cluster-management.io/v1",` +
+	`"kind":"ConfigurationPolicy","metadata":{"name":"%s"},"spec":{"remediationAction":"inform",` +
+	`"severity":"low","namespaceSelector":{"include":["default"]},"object-templates":[{"complianceType":` +
+	`"musthave","objectDefinition":{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"%s"}}}]}}`
+
+// hubSimulator emulates the status syncers of the agent on a managed hub. It holds the managed clusters, the local
+// policies and their compliance in memory, and sends the bundles which are changed since the last sync.
+type hubSimulator struct {
+	name   string
+	random *rand.Rand
+
+	clusters []*clusterv1.ManagedCluster
+	policies []*policyv1.Policy
+	// compliances[i][j] is the compliance state of the policy i on the cluster j
+	compliances [][]policyv1.ComplianceState
+
+	clusterVersion            *metadata.BundleVersion
+	policyVersion             *metadata.BundleVersion
+	complianceVersion         *metadata.BundleVersion
+	completeComplianceVersion *metadata.BundleVersion
+	heartbeatVersion          *metadata.BundleVersion
+
+	// the versions of the bundles have been sent
+	sentVersions map[string]metadata.BundleVersion
+}
+
+func newHubSimulator(name string, clusterCount, policyCount int, seed int64) *hubSimulator {
+	hub := &hubSimulator{
+		name:                      name,
+		random:                    rand.New(rand.NewSource(seed)), // #nosec G404 -- only used to pick the churn
+		clusters:                  make([]*clusterv1.ManagedCluster, 0, clusterCount),
+		policies:                  make([]*policyv1.Policy, 0, policyCount),
+		compliances:               make([][]policyv1.ComplianceState, policyCount),
+		clusterVersion:            metadata.NewBundleVersion(),
+		policyVersion:             metadata.NewBundleVersion(),
+		complianceVersion:         metadata.NewBundleVersion(),
+		completeComplianceVersion: metadata.NewBundleVersion(),
+		heartbeatVersion:          metadata.NewBundleVersion(),
+		sentVersions:              map[string]metadata.BundleVersion{},
+	}
+
+	now := metav1.Now()
+	for i := 0; i < clusterCount; i++ {
+		hub.clusters = append(hub.clusters, newManagedCluster(name, fmt.Sprintf("%s-cluster-%d", name, i), now))
+	}
+	for i := 0; i < policyCount; i++ {
+		hub.policies = append(hub.policies, newLocalPolicy(name, fmt.Sprintf("%s-%d", simulatedPolicyPrefix, i)))
+		hub.compliances[i] = make([]policyv1.ComplianceState, clusterCount)
+		for j := range hub.compliances[i] {
+			hub.compliances[i][j] = policyv1.Compliant
+		}
+	}
+
+	// the initial state of all the bundles
+	hub.clusterVersion.Incr()
+	hub.policyVersion.Incr()
+	hub.complianceVersion.Incr()
+	hub.completeComplianceVersion.Incr()
+	hub.heartbeatVersion.Incr()
+	return hub
+}
+
+func newManagedCluster(hubName, name string, now metav1.Time) *clusterv1.ManagedCluster {
+	return &clusterv1.ManagedCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "ManagedCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			ResourceVersion: "1",
+			Labels: map[string]string{
+				"cloud":  "Amazon",
+				"vendor": "OpenShift",
+				"name":   name,
+			},
+			Annotations: map[string]string{
+				constants.ManagedClusterManagedByAnnotation: hubName,
+			},
+		},
+		Spec: clusterv1.ManagedClusterSpec{
+			HubAcceptsClient:     true,
+			LeaseDurationSeconds: 60,
+		},
+		Status: clusterv1.ManagedClusterStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:               clusterv1.ManagedClusterConditionAvailable,
+					Status:             metav1.ConditionTrue,
+					Reason:             "ManagedClusterAvailable",
+					Message:            "Managed cluster is available",
+					LastTransitionTime: now,
+				},
+			},
+			ClusterClaims: []clusterv1.ManagedClusterClaim{
+				{
+					Name:  clusterIDClaim,
+					Value: uuid.NewSHA1(uuid.NameSpaceOID, []byte(hubName+"/"+name)).String(),
+				},
+			},
+			Version: clusterv1.ManagedClusterVersion{Kubernetes: "v1.26.0"},
+		},
+	}
+}
+
+func newLocalPolicy(hubName, name string) *policyv1.Policy {
+	return &policyv1.Policy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: policyv1.GroupVersion.String(),
+			Kind:       "Policy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			UID:             types.UID(uuid.NewSHA1(uuid.NameSpaceOID, []byte(hubName+"/default/"+name)).String()),
+			ResourceVersion: "1",
+			Generation:      1,
+		},
+		Spec: policyv1.PolicySpec{
+			RemediationAction: policyv1.Inform,
+			PolicyTemplates: []*policyv1.PolicyTemplate{
+				{
+					ObjectDefinition: runtime.RawExtension{
+						Raw: []byte(fmt.Sprintf(configurationPolicyTemplate, name, name)),
+					},
+				},
+			},
+		},
+		Status: policyv1.PolicyStatus{
+			ComplianceState: policyv1.Compliant,
+		},
+	}
+}
+
+// churn changes the available status of the clusters and the compliance states by the given rates, which are the
+// fractions of the clusters and the (policy, cluster) pairs changed in one interval.
+func (h *hubSimulator) churn(clusterRate, complianceRate float64) {
+	if count := churnCount(clusterRate, len(h.clusters)); count > 0 {
+		for _, i := range h.random.Perm(len(h.clusters))[:count] {
+			h.flipAvailable(h.clusters[i])
+		}
+		h.clusterVersion.Incr()
+	}
+
+	total := len(h.policies) * len(h.clusters)
+	if count := churnCount(complianceRate, total); count > 0 {
+		for _, k := range h.random.Perm(total)[:count] {
+			i, j := k/len(h.clusters), k%len(h.clusters)
+			if h.compliances[i][j] == policyv1.Compliant {
+				h.compliances[i][j] = policyv1.NonCompliant
+			} else {
+				h.compliances[i][j] = policyv1.Compliant
+			}
+		}
+		// the compliance of each cluster is changed, the cluster list of the policies keep the same
+		h.completeComplianceVersion.Incr()
+		h.refreshPolicyStatus()
+	}
+}
+
+func churnCount(rate float64, total int) int {
+	count := int(math.Round(rate * float64(total)))
+	if count > total {
+		count = total
+	}
+	return count
+}
+
+func (h *hubSimulator) flipAvailable(managedCluster *clusterv1.ManagedCluster) {
+	condition := &managedCluster.Status.Conditions[0]
+	if condition.Status == metav1.ConditionTrue {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "ManagedClusterLeaseUpdateStopped"
+		condition.Message = "Registration agent stopped updating its lease."
+	} else {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ManagedClusterAvailable"
+		condition.Message = "Managed cluster is available"
+	}
+	condition.LastTransitionTime = metav1.Now()
+	bumpResourceVersion(&managedCluster.ObjectMeta)
+}
+
+// refreshPolicyStatus updates the aggregated compliance state of the local policies, the local policy bundle is
+// only changed when the aggregated state of any policy is changed.
+func (h *hubSimulator) refreshPolicyStatus() {
+	changed := false
+	for i, policy := range h.policies {
+		state := policyv1.Compliant
+		for _, compliance := range h.compliances[i] {
+			if compliance == policyv1.NonCompliant {
+				state = policyv1.NonCompliant
+				break
+			}
+		}
+		if policy.Status.ComplianceState != state {
+			policy.Status.ComplianceState = state
+			bumpResourceVersion(&policy.ObjectMeta)
+			changed = true
+		}
+	}
+	if changed {
+		h.policyVersion.Incr()
+	}
+}
+
+func bumpResourceVersion(meta *metav1.ObjectMeta) {
+	version, _ := strconv.Atoi(meta.ResourceVersion)
+	meta.ResourceVersion = strconv.Itoa(version + 1)
+}
+
+// probe stamps the sent time on the first cluster, then the cluster bundle is sent in this sync.
+func (h *hubSimulator) probe(sentTime time.Time) {
+	if len(h.clusters) == 0 {
+		return
+	}
+	h.clusters[0].Annotations[SentTimeAnnotation] = strconv.FormatInt(sentTime.UnixNano(), 10)
+	bumpResourceVersion(&h.clusters[0].ObjectMeta)
+	h.clusterVersion.Incr()
+}
+
+func (h *hubSimulator) heartbeat() {
+	h.heartbeatVersion.Incr()
+}
+
+// bundles returns the bundles by the message key, the local compliance goes before the complete compliance since
+// the complete compliance depends on it.
+func (h *hubSimulator) bundles() []keyedBundle {
+	clusterBundle := &cluster.ManagedClusterBundle{
+		Objects: h.clusters,
+		BaseManagerBundle: base.BaseManagerBundle{
+			LeafHubName:   h.name,
+			BundleVersion: h.clusterVersion,
+		},
+	}
+	policyBundle := &grc.LocalPolicyBundle{
+		Objects: h.policies,
+		BaseManagerBundle: base.BaseManagerBundle{
+			LeafHubName:   h.name,
+			BundleVersion: h.policyVersion,
+		},
+	}
+
+	complianceBundle := &base.BaseComplianceBundle{
+		Objects:       make([]*base.GenericCompliance, 0, len(h.policies)),
+		LeafHubName:   h.name,
+		BundleVersion: h.complianceVersion,
+	}
+	completeComplianceBundle := &base.BaseCompleteComplianceBundle{
+		Objects:           make([]*base.GenericCompleteCompliance, 0, len(h.policies)),
+		LeafHubName:       h.name,
+		BaseBundleVersion: h.complianceVersion,
+		BundleVersion:     h.completeComplianceVersion,
+	}
+	for i, policy := range h.policies {
+		compliant, nonCompliant := []string{}, []string{}
+		for j, compliance := range h.compliances[i] {
+			if compliance == policyv1.Compliant {
+				compliant = append(compliant, h.clusters[j].Name)
+			} else {
+				nonCompliant = append(nonCompliant, h.clusters[j].Name)
+			}
+		}
+		// like the agent, the local compliance bundle is only resent when the clusters of the policy are changed,
+		// the compliance changes are delivered by the complete compliance bundle
+		complianceBundle.Objects = append(complianceBundle.Objects, &base.GenericCompliance{
+			PolicyID:                  string(policy.UID),
+			CompliantClusters:         compliant,
+			NonCompliantClusters:      nonCompliant,
+			UnknownComplianceClusters: []string{},
+		})
+		if len(nonCompliant) > 0 {
+			completeComplianceBundle.Objects = append(completeComplianceBundle.Objects,
+				&base.GenericCompleteCompliance{
+					PolicyID:                  string(policy.UID),
+					NonCompliantClusters:      nonCompliant,
+					UnknownComplianceClusters: []string{},
+				})
+		}
+	}
+
+	heartbeatBundle := &cluster.HubClusterHeartbeatBundle{
+		BaseManagerBundle: base.BaseManagerBundle{
+			LeafHubName:   h.name,
+			BundleVersion: h.heartbeatVersion,
+		},
+	}
+
+	return []keyedBundle{
+		{constants.HubClusterHeartbeatMsgKey, h.heartbeatVersion, heartbeatBundle},
+		{constants.ManagedClustersMsgKey, h.clusterVersion, clusterBundle},
+		{constants.LocalPolicySpecMsgKey, h.policyVersion, policyBundle},
+		{constants.LocalComplianceMsgKey, h.complianceVersion, complianceBundle},
+		{constants.LocalCompleteComplianceMsgKey, h.completeComplianceVersion, completeComplianceBundle},
+	}
+}
+
+type keyedBundle struct {
+	msgKey  string
+	version *metadata.BundleVersion
+	bundle  interface{}
+}
+
+// sync sends the bundles which are changed since the last sync, and returns the number of the sent bundles.
+func (h *hubSimulator) sync(ctx context.Context, producer transport.Producer) (int, error) {
+	sent := 0
+	for _, keyed := range h.bundles() {
+		if lastVersion, found := h.sentVersions[keyed.msgKey]; found && !keyed.version.NewerThan(&lastVersion) {
+			continue
+		}
+		payload, err := json.Marshal(keyed.bundle)
+		if err != nil {
+			return sent, fmt.Errorf("failed to marshal the %s bundle of hub %s - %w", keyed.msgKey, h.name, err)
+		}
+		if err := producer.Send(ctx, &transport.Message{
+			Key:         fmt.Sprintf("%s.%s", h.name, keyed.msgKey),
+			Destination: h.name,
+			MsgType:     constants.StatusBundle,
+			Payload:     payload,
+		}); err != nil {
+			return sent, fmt.Errorf("failed to send the %s bundle of hub %s - %w", keyed.msgKey, h.name, err)
+		}
+		h.sentVersions[keyed.msgKey] = *keyed.version
+		sent++
+	}
+	return sent, nil
+}
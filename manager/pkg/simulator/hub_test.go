@@ -0,0 +1,163 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/cluster"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+type fakeProducer struct {
+	messages []*transport.Message
+}
+
+func (p *fakeProducer) Send(ctx context.Context, msg *transport.Message) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *fakeProducer) keys() []string {
+	keys := []string{}
+	for _, msg := range p.messages {
+		keys = append(keys, msg.Key)
+	}
+	return keys
+}
+
+func TestHubSimulatorSync(t *testing.T) {
+	ctx := context.Background()
+	hub := newHubSimulator("hub1", 10, 2, 0)
+	producer := &fakeProducer{}
+
+	// the initial state is sent completely
+	sent, err := hub.sync(ctx, producer)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, sent)
+	assert.Equal(t, []string{
+		"hub1." + constants.HubClusterHeartbeatMsgKey,
+		"hub1." + constants.ManagedClustersMsgKey,
+		"hub1." + constants.LocalPolicySpecMsgKey,
+		"hub1." + constants.LocalComplianceMsgKey,
+		"hub1." + constants.LocalCompleteComplianceMsgKey,
+	}, producer.keys())
+	for _, msg := range producer.messages {
+		assert.Equal(t, "hub1", msg.Destination)
+		assert.Equal(t, constants.StatusBundle, msg.MsgType)
+	}
+
+	clusterBundle := &cluster.ManagedClusterBundle{}
+	assert.Nil(t, json.Unmarshal(producer.messages[1].Payload, clusterBundle))
+	assert.Len(t, clusterBundle.Objects, 10)
+	assert.Equal(t, "hub1", clusterBundle.Objects[0].Annotations[constants.ManagedClusterManagedByAnnotation])
+	assert.NotEmpty(t, clusterBundle.Objects[0].Status.ClusterClaims[0].Value)
+
+	// nothing is changed
+	producer.messages = nil
+	sent, err = hub.sync(ctx, producer)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, sent)
+
+	// only the probed clusters and the heartbeat are sent
+	sentTime := time.Now()
+	hub.probe(sentTime)
+	hub.heartbeat()
+	_, err = hub.sync(ctx, producer)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"hub1." + constants.HubClusterHeartbeatMsgKey,
+		"hub1." + constants.ManagedClustersMsgKey,
+	}, producer.keys())
+	assert.Nil(t, json.Unmarshal(producer.messages[1].Payload, clusterBundle))
+	assert.Equal(t, "2", clusterBundle.Objects[0].ResourceVersion)
+	assert.NotEmpty(t, clusterBundle.Objects[0].Annotations[SentTimeAnnotation])
+}
+
+func TestHubSimulatorChurn(t *testing.T) {
+	ctx := context.Background()
+	hub := newHubSimulator("hub1", 100, 4, 0)
+	producer := &fakeProducer{}
+	_, err := hub.sync(ctx, producer)
+	assert.Nil(t, err)
+
+	producer.messages = nil
+	hub.churn(0.1, 0.05)
+	_, err = hub.sync(ctx, producer)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"hub1." + constants.ManagedClustersMsgKey,
+		"hub1." + constants.LocalPolicySpecMsgKey,
+		"hub1." + constants.LocalCompleteComplianceMsgKey,
+	}, producer.keys())
+
+	unavailable := 0
+	for _, managedCluster := range hub.clusters {
+		if managedCluster.Status.Conditions[0].Status == metav1.ConditionUnknown {
+			unavailable++
+		}
+	}
+	assert.Equal(t, 10, unavailable)
+
+	completeBundle := &base.BaseCompleteComplianceBundle{}
+	assert.Nil(t, json.Unmarshal(producer.messages[2].Payload, completeBundle))
+	nonCompliant := 0
+	for _, compliance := range completeBundle.Objects {
+		nonCompliant += len(compliance.NonCompliantClusters)
+	}
+	assert.Equal(t, 20, nonCompliant)
+	// the complete compliance depends on the local compliance which has been sent
+	assert.Equal(t, hub.complianceVersion.String(), completeBundle.BaseBundleVersion.String())
+	for _, policy := range hub.policies {
+		assert.Equal(t, policyv1.NonCompliant, policy.Status.ComplianceState)
+	}
+
+	// no churn
+	producer.messages = nil
+	hub.churn(0, 0)
+	sent, err := hub.sync(ctx, producer)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestLatencyRecorder(t *testing.T) {
+	recorder := &latencyRecorder{}
+	assert.Equal(t, latencySummary{}, recorder.summary())
+
+	for i := 100; i >= 1; i-- {
+		recorder.record(time.Duration(i) * time.Millisecond)
+	}
+	summary := recorder.summary()
+	assert.Equal(t, 100, summary.Count)
+	assert.Equal(t, 50500*time.Microsecond, summary.Avg)
+	assert.Equal(t, 95*time.Millisecond, summary.P95)
+	assert.Equal(t, 100*time.Millisecond, summary.Max)
+}
+
+func TestValidateConfig(t *testing.T) {
+	config := &SimulatorConfig{
+		Hubs:              1,
+		ClustersPerHub:    1,
+		Interval:          time.Second,
+		HeartbeatInterval: time.Second,
+		ReportInterval:    time.Second,
+		TransportConfig:   &transport.TransportConfig{TransportType: string(transport.Chan)},
+	}
+	assert.NotNil(t, config.validate())
+
+	config.EmbeddedManager = true
+	assert.Nil(t, config.validate())
+
+	config.ComplianceChurn = 1.5
+	assert.NotNil(t, config.validate())
+}
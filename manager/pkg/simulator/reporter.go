@@ -0,0 +1,231 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/statistics"
+)
+
+const probeInterval = 500 * time.Millisecond
+
+// latencyRecorder collects the end-to-end latency samples, from the bundle is sent by the simulated hub to it's
+// stored in the database.
+type latencyRecorder struct {
+	mutex   sync.Mutex
+	samples []time.Duration
+}
+
+type latencySummary struct {
+	Count int
+	Avg   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+func (r *latencyRecorder) record(latency time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.samples = append(r.samples, latency)
+}
+
+func (r *latencyRecorder) summary() latencySummary {
+	r.mutex.Lock()
+	samples := append([]time.Duration{}, r.samples...)
+	r.mutex.Unlock()
+
+	summary := latencySummary{Count: len(samples)}
+	if len(samples) == 0 {
+		return summary
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample
+	}
+	summary.Avg = total / time.Duration(len(samples))
+	summary.P95 = samples[(len(samples)*95+99)/100-1]
+	summary.Max = samples[len(samples)-1]
+	return summary
+}
+
+// reporter measures the end-to-end latency by the probe clusters, the database throughput by the row changes of the
+// status tables, and reports them with the statistics of the embedded manager.
+type reporter struct {
+	log   logr.Logger
+	stats *statistics.Statistics // nil if the bundles are stored by a deployed manager
+	// the probe cluster name of each hub
+	probeClusters map[string]string
+	latency       *latencyRecorder
+	// the last observed sent time of the probe cluster of each hub
+	lastObserved map[string]int64
+
+	startTime     time.Time
+	startRows     int64
+	lastTime      time.Time
+	lastRows      int64
+	lastBundles   int64
+	bundlesSentFn func() int64
+}
+
+func newReporter(log logr.Logger, stats *statistics.Statistics, hubs []*hubSimulator,
+	bundlesSentFn func() int64,
+) *reporter {
+	probeClusters := map[string]string{}
+	for _, hub := range hubs {
+		if len(hub.clusters) > 0 {
+			probeClusters[hub.name] = hub.clusters[0].Name
+		}
+	}
+	return &reporter{
+		log:           log,
+		stats:         stats,
+		probeClusters: probeClusters,
+		latency:       &latencyRecorder{},
+		lastObserved:  map[string]int64{},
+		bundlesSentFn: bundlesSentFn,
+	}
+}
+
+// start records the baseline of the database throughput.
+func (r *reporter) start() error {
+	rows, err := statusRowChanges()
+	if err != nil {
+		return err
+	}
+	r.startTime, r.lastTime = time.Now(), time.Now()
+	r.startRows, r.lastRows = rows, rows
+	return nil
+}
+
+// probe polls the sent time of the probe clusters from the database until the context is done.
+func (r *reporter) probe(ctx context.Context) {
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.observe(time.Now()); err != nil {
+				r.log.Error(err, "failed to observe the probe clusters")
+			}
+		}
+	}
+}
+
+func (r *reporter) observe(now time.Time) error {
+	if len(r.probeClusters) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(r.probeClusters))
+	for _, name := range r.probeClusters {
+		names = append(names, name)
+	}
+
+	rows, err := database.GetGorm().Raw(fmt.Sprintf(`SELECT leaf_hub_name, payload->'metadata'->'annotations'->>'%s'
+		FROM %s.%s WHERE payload->'metadata'->>'name' IN ?`, SentTimeAnnotation, database.StatusSchema,
+		database.ManagedClustersTableName), names).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hubName string
+		var sentTime *string
+		if err := rows.Scan(&hubName, &sentTime); err != nil {
+			return err
+		}
+		if sentTime == nil {
+			continue
+		}
+		sentNano, err := strconv.ParseInt(*sentTime, 10, 64)
+		if err != nil || sentNano <= r.lastObserved[hubName] {
+			continue
+		}
+		r.lastObserved[hubName] = sentNano
+		r.latency.record(now.Sub(time.Unix(0, sentNano)))
+	}
+	return rows.Err()
+}
+
+// statusRowChanges returns the total inserted, updated and deleted rows of the status tables.
+func statusRowChanges() (int64, error) {
+	var rows int64
+	err := database.GetGorm().Raw(`SELECT COALESCE(SUM(n_tup_ins + n_tup_upd + n_tup_del), 0)
+		FROM pg_stat_user_tables WHERE schemaname = ?`, database.StatusSchema).Scan(&rows).Error
+	return rows, err
+}
+
+// report logs the latency, the throughput since the last report and the whole simulation, and the statistics.
+func (r *reporter) report(final bool) {
+	now := time.Now()
+	rows, err := statusRowChanges()
+	if err != nil {
+		r.log.Error(err, "failed to query the database throughput")
+		rows = r.lastRows
+	}
+	bundles := r.bundlesSentFn()
+
+	interval := now.Sub(r.lastTime).Seconds()
+	elapsed := now.Sub(r.startTime).Seconds()
+	latency := r.latency.summary()
+	message := "simulation progress"
+	if final {
+		message = "simulation summary"
+	}
+	r.log.Info(message,
+		"elapsed", now.Sub(r.startTime).Round(time.Second).String(),
+		"sentBundles", bundles,
+		"bundlesPerSecond", fmt.Sprintf("%.1f", perSecond(bundles-r.lastBundles, interval)),
+		"latencySamples", latency.Count,
+		"latencyAvg", latency.Avg.Round(time.Millisecond).String(),
+		"latencyP95", latency.P95.Round(time.Millisecond).String(),
+		"latencyMax", latency.Max.Round(time.Millisecond).String(),
+		"dbRowsPerSecond", fmt.Sprintf("%.1f", perSecond(rows-r.lastRows, interval)),
+		"dbRowsPerSecondAvg", fmt.Sprintf("%.1f", perSecond(rows-r.startRows, elapsed)),
+	)
+	r.lastTime, r.lastRows, r.lastBundles = now, rows, bundles
+
+	if r.stats == nil {
+		return
+	}
+	snapshot := r.stats.Snapshot()
+	r.log.Info("manager statistics",
+		"conflationUnits", snapshot.ConflationUnits,
+		"conflationReadyQueue", snapshot.ConflationReadyQueueSize,
+		"idleDBWorkers", snapshot.AvailableDBWorkers)
+	bundleTypes := make([]string, 0, len(snapshot.Bundles))
+	for bundleType := range snapshot.Bundles {
+		bundleTypes = append(bundleTypes, bundleType)
+	}
+	sort.Strings(bundleTypes)
+	for _, bundleType := range bundleTypes {
+		bundleSnapshot := snapshot.Bundles[bundleType]
+		r.log.Info("bundle statistics", "type", bundleType,
+			"received", bundleSnapshot.Received,
+			"conflationAvg", bundleSnapshot.Conflation.Avg.String(),
+			"conflationMax", bundleSnapshot.Conflation.Max.String(),
+			"stored", bundleSnapshot.Database.Successes,
+			"storeFailures", bundleSnapshot.Database.Failures,
+			"storeAvg", bundleSnapshot.Database.Avg.String(),
+			"storeMax", bundleSnapshot.Database.Max.String())
+	}
+}
+
+func perSecond(count int64, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return float64(count) / seconds
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/statistics"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/producer"
+)
+
+// SimulatorConfig is the configuration of the fleet load simulator.
+type SimulatorConfig struct {
+	Hubs           int
+	ClustersPerHub int
+	PoliciesPerHub int
+	// ClusterChurn is the fraction of the clusters whose available status is changed in each interval
+	ClusterChurn float64
+	// ComplianceChurn is the fraction of the (policy, cluster) pairs whose compliance is changed in each interval
+	ComplianceChurn   float64
+	Interval          time.Duration
+	HeartbeatInterval time.Duration
+	ReportInterval    time.Duration
+	// Duration is how long the simulation runs after the initial bundles are sent, 0 means until it's interrupted
+	Duration time.Duration
+	// EmbeddedManager runs the status path of the manager in the simulator process, it's required by the chan
+	// transport, otherwise the bundles are consumed by the deployed manager
+	EmbeddedManager bool
+	TransportConfig *transport.TransportConfig
+}
+
+func (c *SimulatorConfig) validate() error {
+	if c.Hubs <= 0 || c.ClustersPerHub < 0 || c.PoliciesPerHub < 0 {
+		return errors.New("the number of hubs must be positive, and the number of clusters and policies per hub " +
+			"must not be negative")
+	}
+	if c.ClusterChurn < 0 || c.ClusterChurn > 1 || c.ComplianceChurn < 0 || c.ComplianceChurn > 1 {
+		return errors.New("the churn rates must be between 0 and 1")
+	}
+	if c.Interval <= 0 || c.HeartbeatInterval <= 0 || c.ReportInterval <= 0 {
+		return errors.New("the intervals must be positive")
+	}
+	if c.TransportConfig.TransportType == string(transport.Chan) && !c.EmbeddedManager {
+		return errors.New("the chan transport requires the embedded manager")
+	}
+	return nil
+}
+
+// Simulator emulates the agents of the managed hubs in one process, and reports how the manager and the database
+// keep up with the load.
+type Simulator struct {
+	log         logr.Logger
+	config      *SimulatorConfig
+	hubs        []*hubSimulator
+	bundlesSent int64
+}
+
+func NewSimulator(config *SimulatorConfig) (*Simulator, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	hubs := make([]*hubSimulator, 0, config.Hubs)
+	for i := 0; i < config.Hubs; i++ {
+		hubs = append(hubs, newHubSimulator(fmt.Sprintf("hub%d", i+1), config.ClustersPerHub,
+			config.PoliciesPerHub, int64(i)))
+	}
+	return &Simulator{
+		log:    ctrl.Log.WithName("simulator"),
+		config: config,
+		hubs:   hubs,
+	}, nil
+}
+
+// Start sends the initial state of the hubs, then churns and syncs them every interval until the duration is over
+// or the context is done. The database must be initialized before starting the simulator.
+func (s *Simulator) Start(ctx context.Context) error {
+	// the producer is created first, so the consumer shares the same go chan with it for the chan transport
+	genericProducer, err := producer.NewGenericProducer(s.config.TransportConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create the producer: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var stats *statistics.Statistics
+	managerErrChan := make(chan error, 1)
+	if s.config.EmbeddedManager {
+		embedded, err := newEmbeddedManager(s.config.TransportConfig)
+		if err != nil {
+			return err
+		}
+		stats = embedded.stats
+		go func() {
+			managerErrChan <- embedded.Start(ctx)
+		}()
+	}
+
+	r := newReporter(s.log, stats, s.hubs, func() int64 { return atomic.LoadInt64(&s.bundlesSent) })
+	if err := r.start(); err != nil {
+		return fmt.Errorf("failed to query the database throughput: %w", err)
+	}
+	go r.probe(ctx)
+
+	s.log.Info("sending the initial bundles", "hubs", s.config.Hubs, "clustersPerHub", s.config.ClustersPerHub,
+		"policiesPerHub", s.config.PoliciesPerHub)
+	if err := s.sync(ctx, genericProducer); err != nil {
+		return err
+	}
+
+	var done <-chan time.Time
+	if s.config.Duration > 0 {
+		timer := time.NewTimer(s.config.Duration)
+		defer timer.Stop()
+		done = timer.C
+	}
+	syncTicker := time.NewTicker(s.config.Interval)
+	defer syncTicker.Stop()
+	heartbeatTicker := time.NewTicker(s.config.HeartbeatInterval)
+	defer heartbeatTicker.Stop()
+	reportTicker := time.NewTicker(s.config.ReportInterval)
+	defer reportTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.report(true)
+			return nil
+		case <-done:
+			r.report(true)
+			return nil
+		case err := <-managerErrChan:
+			if err != nil {
+				return fmt.Errorf("the embedded manager exited: %w", err)
+			}
+			r.report(true)
+			return nil
+		case <-heartbeatTicker.C:
+			// the heartbeats are sent in the next sync
+			for _, hub := range s.hubs {
+				hub.heartbeat()
+			}
+		case <-syncTicker.C:
+			for _, hub := range s.hubs {
+				hub.churn(s.config.ClusterChurn, s.config.ComplianceChurn)
+			}
+			if err := s.sync(ctx, genericProducer); err != nil {
+				return err
+			}
+		case <-reportTicker.C:
+			r.report(false)
+		}
+	}
+}
+
+func (s *Simulator) sync(ctx context.Context, producer transport.Producer) error {
+	for _, hub := range s.hubs {
+		hub.probe(time.Now())
+		sent, err := hub.sync(ctx, producer)
+		atomic.AddInt64(&s.bundlesSent, int64(sent))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package bundle
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewFilteredObjectsBundleFunc returns a create bundle function, the objects which aren't accepted by the filter are
+// skipped from the bundle. the deleted objects are always kept.
+func NewFilteredObjectsBundleFunc(createBundleFunc CreateBundleFunction,
+	filter func(metav1.Object) bool,
+) CreateBundleFunction {
+	return func() ObjectsBundle {
+		return &filteredObjectsBundle{
+			ObjectsBundle: createBundleFunc(),
+			filter:        filter,
+		}
+	}
+}
+
+type filteredObjectsBundle struct {
+	ObjectsBundle
+	filter func(metav1.Object) bool
+}
+
+// AddObject adds an object to the bundle if it's accepted by the filter.
+func (b *filteredObjectsBundle) AddObject(object metav1.Object, objectUID string) {
+	if !b.filter(object) {
+		return
+	}
+	b.ObjectsBundle.AddObject(object, objectUID)
+}
+
+// MarshalJSON marshals the underlying bundle.
+func (b *filteredObjectsBundle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.ObjectsBundle)
+}
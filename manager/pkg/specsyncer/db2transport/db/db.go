@@ -7,6 +7,7 @@ import (
 	"context"
 	"time"
 
+	"gorm.io/gorm"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/bundle"
@@ -16,6 +17,9 @@ import (
 type SpecDB interface {
 	// GetLastUpdateTimestamp returns the last update timestamp of a specific table.
 	GetLastUpdateTimestamp(ctx context.Context, tableName string, filterLocalResources bool) (*time.Time, error)
+	// GetUpdateTimestamps returns the update timestamps of the global objects updated after the timestamp, keyed by
+	// the object ids.
+	GetUpdateTimestamps(ctx context.Context, tableName string, since time.Time) (map[string]time.Time, error)
 	ObjectsSpecDB
 }
 
@@ -30,4 +34,14 @@ type ObjectsSpecDB interface {
 	// GetObjectsBundle returns a bundle of objects from a specific table.
 	GetObjectsBundle(ctx context.Context, tableName string, createObjFunc bundle.CreateObjectFunction,
 		intoBundle bundle.ObjectsBundle) (*time.Time, error)
+	// GetUpdatedObjectsBundle returns a bundle of objects updated after the timestamp from a specific table.
+	GetUpdatedObjectsBundle(ctx context.Context, tableName string, createObjFunc bundle.CreateObjectFunction,
+		intoBundle bundle.ObjectsBundle, timestamp *time.Time) (*time.Time, error)
+}
+
+// TransactionalSpecDB writes the spec objects in a transaction, e.g. with the audit log of the changes.
+type TransactionalSpecDB interface {
+	// Transaction runs the function with the spec db and the gorm db of the transaction, the transaction is rolled
+	// back if the function returns an error.
+	Transaction(ctx context.Context, fn func(specDB ObjectsSpecDB, tx *gorm.DB) error) error
 }
@@ -8,20 +8,41 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v4"
+	"gorm.io/gorm"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/bundle"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/db"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
 )
 
 var errQueryTableFailedTemplate = "failed to query table spec.%s - %w"
 
-type gormSpecDB struct{}
+// gormSpecDB reads and writes the spec objects by the gorm db, the tx is the transaction of the spec db created by
+// the Transaction, the spec db created by NewGormSpecDB uses the shared gorm db.
+type gormSpecDB struct {
+	tx *gorm.DB
+}
 
 func NewGormSpecDB() *gormSpecDB {
 	return &gormSpecDB{}
 }
 
+// Transaction runs the function with the spec db writing in the transaction.
+func (p *gormSpecDB) Transaction(ctx context.Context, fn func(specDB db.ObjectsSpecDB, tx *gorm.DB) error) error {
+	return p.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&gormSpecDB{tx: tx}, tx)
+	})
+}
+
+func (p *gormSpecDB) conn(ctx context.Context) *gorm.DB {
+	if p.tx != nil {
+		return p.tx
+	}
+	return database.GetGorm().WithContext(ctx)
+}
+
 // GetLastUpdateTimestamp returns the last update timestamp of a specific table.
 func (p *gormSpecDB) GetLastUpdateTimestamp(ctx context.Context, tableName string,
 	filterLocalResources bool,
@@ -46,23 +67,53 @@ func (p *gormSpecDB) GetLastUpdateTimestamp(ctx context.Context, tableName strin
 	return &lastTimestamp, nil
 }
 
+// GetUpdateTimestamps returns the update timestamps of the global objects updated after the timestamp, keyed by the
+// object ids.
+func (p *gormSpecDB) GetUpdateTimestamps(ctx context.Context, tableName string, since time.Time,
+) (map[string]time.Time, error) {
+	query := fmt.Sprintf(`SELECT id,updated_at FROM spec.%s WHERE
+		payload->'metadata'->'labels'->'global-hub.open-cluster-management.io/global-resource' IS NOT NULL AND
+		updated_at > ?::timestamp`, tableName)
+	rows, err := database.GetGorm().WithContext(ctx).Raw(query, since.Format(time.RFC3339Nano)).Rows()
+	if err != nil {
+		return nil, fmt.Errorf(errQueryTableFailedTemplate, tableName, err)
+	}
+	defer rows.Close()
+
+	updates := map[string]time.Time{}
+	for rows.Next() {
+		var (
+			objID     string
+			updatedAt time.Time
+		)
+		if err := rows.Scan(&objID, &updatedAt); err != nil {
+			return nil, fmt.Errorf("error reading from table spec.%s - %w", tableName, err)
+		}
+		updates[objID] = updatedAt
+	}
+	return updates, rows.Err()
+}
+
 // QuerySpecObject gets object from given table with object UID
 func (p *gormSpecDB) QuerySpecObject(ctx context.Context, tableName, objUID string, object *client.Object) error {
-	db := database.GetGorm()
+	db := p.conn(ctx)
 	var payload []byte
 	query := fmt.Sprintf("SELECT payload FROM spec.%s WHERE id = ?", tableName)
 	err := db.Raw(query, objUID).Row().Scan(&payload)
 	if err != nil {
 		return err
 	}
+	if payload, err = sensitive.Decrypt(payload); err != nil {
+		return err
+	}
 	return json.Unmarshal(payload, &object)
 }
 
 // InsertSpecObject insets new object to given table with object UID and payload
 func (p *gormSpecDB) InsertSpecObject(ctx context.Context, tableName, objUID string, object *client.Object) error {
-	db := database.GetGorm()
+	db := p.conn(ctx)
 	query := fmt.Sprintf("INSERT INTO spec.%s (id, payload) values(?, ?)", tableName)
-	payload, err := json.Marshal(object)
+	payload, err := encryptPayload(tableName, object)
 	if err != nil {
 		return err
 	}
@@ -71,8 +122,8 @@ func (p *gormSpecDB) InsertSpecObject(ctx context.Context, tableName, objUID str
 
 // UpdateSpecObject updates object payload in given table with object UID
 func (p *gormSpecDB) UpdateSpecObject(ctx context.Context, tableName, objUID string, object *client.Object) error {
-	db := database.GetGorm()
-	payload, err := json.Marshal(object)
+	db := p.conn(ctx)
+	payload, err := encryptPayload(tableName, object)
 	if err != nil {
 		return err
 	}
@@ -80,9 +131,18 @@ func (p *gormSpecDB) UpdateSpecObject(ctx context.Context, tableName, objUID str
 	return db.Exec(query, payload, objUID).Error
 }
 
+// encryptPayload marshals the object and encrypts its sensitive fields by the rules of the table.
+func encryptPayload(tableName string, object *client.Object) ([]byte, error) {
+	payload, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	return sensitive.Encrypt("spec."+tableName, payload)
+}
+
 // DeleteSpecObject deletes object with name and namespace from given table
 func (p *gormSpecDB) DeleteSpecObject(ctx context.Context, tableName, name, namespace string) error {
-	db := database.GetGorm()
+	db := p.conn(ctx)
 	updateTemplate := fmt.Sprintf(`UPDATE spec.%s SET deleted = true WHERE payload -> 'metadata' ->> 'name' = '%s' AND
 	deleted = false`, tableName, name)
 	namespaceCondition := " AND payload -> 'metadata' ->> 'namespace' IS NULL"
@@ -95,6 +155,21 @@ func (p *gormSpecDB) DeleteSpecObject(ctx context.Context, tableName, name, name
 // GetObjectsBundle returns a bundle of objects from a specific table.
 func (p *gormSpecDB) GetObjectsBundle(ctx context.Context, tableName string, createObjFunc bundle.CreateObjectFunction,
 	intoBundle bundle.ObjectsBundle,
+) (*time.Time, error) {
+	return p.getObjectsBundle(ctx, tableName, createObjFunc, intoBundle, nil)
+}
+
+// GetUpdatedObjectsBundle returns a bundle of objects updated after the timestamp from a specific table.
+func (p *gormSpecDB) GetUpdatedObjectsBundle(ctx context.Context, tableName string,
+	createObjFunc bundle.CreateObjectFunction, intoBundle bundle.ObjectsBundle, timestamp *time.Time,
+) (*time.Time, error) {
+	return p.getObjectsBundle(ctx, tableName, createObjFunc, intoBundle, timestamp)
+}
+
+// getObjectsBundle adds the objects of a specific table into the bundle, only the objects updated after the
+// timestamp are added if it isn't nil. it returns the last update timestamp of the table.
+func (p *gormSpecDB) getObjectsBundle(ctx context.Context, tableName string,
+	createObjFunc bundle.CreateObjectFunction, intoBundle bundle.ObjectsBundle, since *time.Time,
 ) (*time.Time, error) {
 	timestamp, err := p.GetLastUpdateTimestamp(ctx, tableName, true)
 	if err != nil {
@@ -103,9 +178,15 @@ func (p *gormSpecDB) GetObjectsBundle(ctx context.Context, tableName string, cre
 
 	db := database.GetGorm()
 
-	rows, err := db.Raw(fmt.Sprintf(`SELECT id,payload,deleted FROM spec.%s WHERE
+	query := fmt.Sprintf(`SELECT id,payload,deleted FROM spec.%s WHERE
 		payload->'metadata'->'labels'->'global-hub.open-cluster-management.io/global-resource' IS NOT NULL`,
-		tableName)).Rows()
+		tableName)
+	args := []interface{}{}
+	if since != nil {
+		query += " AND updated_at > ?::timestamp"
+		args = append(args, since.Format(time.RFC3339Nano))
+	}
+	rows, err := db.Raw(query, args...).Rows()
 	if err != nil {
 		return nil, fmt.Errorf(errQueryTableFailedTemplate, tableName, err)
 	}
@@ -123,6 +204,10 @@ func (p *gormSpecDB) GetObjectsBundle(ctx context.Context, tableName string, cre
 		if err := rows.Scan(&objID, &payload, &deleted); err != nil {
 			return nil, fmt.Errorf("error reading from table spec.%s - %w", tableName, err)
 		}
+		// the managed hubs apply the objects, so the encrypted fields are restored before they're sent
+		if payload, err = sensitive.Decrypt(payload); err != nil {
+			return nil, fmt.Errorf("error decrypting payload from table spec.%s - %w", tableName, err)
+		}
 		if err := json.Unmarshal(payload, &object); err != nil {
 			return nil, fmt.Errorf("error reading unmarshal payload from table spec.%s - %w", tableName, err)
 		}
@@ -0,0 +1,62 @@
+package snapshot
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+// Requests keeps the managed hubs which request the snapshots of the spec bundles. The requests are handled by the
+// manager replica owning the partition of the hub, while the db to transport syncers run on the leader, so they're
+// kept in the database.
+type Requests interface {
+	// Request records the managed hub requests the snapshots of the spec bundles, they're sent by the db to transport
+	// syncers in the next sync period.
+	Request(ctx context.Context, leafHubName string, msgKeys ...string) error
+	// Pending returns the requests for the snapshot of the spec bundle, ordered by the managed hubs.
+	Pending(ctx context.Context, msgKey string) ([]models.SpecSnapshotRequest, error)
+	// Complete removes the served requests, the requests renewed after they're returned by Pending are kept.
+	Complete(ctx context.Context, requests []models.SpecSnapshotRequest) error
+}
+
+type dbRequests struct{}
+
+func NewRequests() Requests {
+	return &dbRequests{}
+}
+
+func (r *dbRequests) Request(ctx context.Context, leafHubName string, msgKeys ...string) error {
+	if len(msgKeys) == 0 {
+		return nil
+	}
+	requests := make([]models.SpecSnapshotRequest, 0, len(msgKeys))
+	for _, msgKey := range msgKeys {
+		requests = append(requests, models.SpecSnapshotRequest{LeafHubName: leafHubName, BundleKey: msgKey})
+	}
+	return database.GetGorm().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "leaf_hub_name"}, {Name: "bundle_key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"requested_at": gorm.Expr("now()")}),
+	}).Create(&requests).Error
+}
+
+func (r *dbRequests) Pending(ctx context.Context, msgKey string) ([]models.SpecSnapshotRequest, error) {
+	var requests []models.SpecSnapshotRequest
+	err := database.GetGorm().WithContext(ctx).Where("bundle_key = ?", msgKey).Order("leaf_hub_name").
+		Find(&requests).Error
+	return requests, err
+}
+
+func (r *dbRequests) Complete(ctx context.Context, requests []models.SpecSnapshotRequest) error {
+	db := database.GetGorm().WithContext(ctx)
+	for _, request := range requests {
+		if err := db.Where("leaf_hub_name = ? AND bundle_key = ? AND requested_at = ?", request.LeafHubName,
+			request.BundleKey, request.RequestedAt).Delete(&models.SpecSnapshotRequest{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
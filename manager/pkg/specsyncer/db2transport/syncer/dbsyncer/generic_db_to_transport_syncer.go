@@ -7,10 +7,13 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/google/uuid"
 
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/bundle"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/db"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/intervalpolicy"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/snapshot"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport"
 )
@@ -46,34 +49,100 @@ func (syncer *genericDBToTransportSyncer) periodicSync(ctx context.Context) {
 			return
 
 		case <-ticker.C:
-			// define timeout of max sync interval on the sync function
-			ctxWithTimeout, cancelFunc := context.WithTimeout(ctx, syncer.intervalPolicy.GetMaxInterval())
+			syncer.sync(ctx, ticker)
+		}
+	}
+}
 
-			synced, err := syncer.syncBundleFunc(ctxWithTimeout)
-			if err != nil {
-				syncer.log.Error(err, "failed to sync bundle")
-			}
+func (syncer *genericDBToTransportSyncer) sync(ctx context.Context, ticker *time.Ticker) {
+	// define timeout of max sync interval on the sync function
+	ctxWithTimeout, cancelFunc := context.WithTimeout(ctx, syncer.intervalPolicy.GetMaxInterval())
+
+	synced, err := syncer.syncBundleFunc(ctxWithTimeout)
+	if err != nil {
+		syncer.log.Error(err, "failed to sync bundle")
+	}
 
-			cancelFunc() // cancel child ctx and is used to cleanup resources once context expires or sync is done.
+	cancelFunc() // cancel child ctx and is used to cleanup resources once context expires or sync is done.
 
-			// get current sync interval
-			currentInterval := syncer.intervalPolicy.GetInterval()
+	// get current sync interval
+	currentInterval := syncer.intervalPolicy.GetInterval()
 
-			// notify policy whether sync was actually performed or skipped
-			if synced {
-				syncer.intervalPolicy.Evaluate()
-			} else {
-				syncer.intervalPolicy.Reset()
-			}
+	// notify policy whether sync was actually performed or skipped
+	if synced {
+		syncer.intervalPolicy.Evaluate()
+	} else {
+		syncer.intervalPolicy.Reset()
+	}
 
-			// get reevaluated sync interval
-			reevaluatedInterval := syncer.intervalPolicy.GetInterval()
+	// get reevaluated sync interval
+	reevaluatedInterval := syncer.intervalPolicy.GetInterval()
 
-			// reset ticker if needed
-			if currentInterval != reevaluatedInterval {
-				ticker.Reset(reevaluatedInterval)
-				syncer.log.Info(fmt.Sprintf("sync interval has been reset to %s", reevaluatedInterval.String()))
-			}
+	// reset ticker if needed
+	if currentInterval != reevaluatedInterval {
+		ticker.Reset(reevaluatedInterval)
+		syncer.log.Info(fmt.Sprintf("sync interval has been reset to %s", reevaluatedInterval.String()))
+	}
+}
+
+// lateCommitWindow is the overlap of the delta bundles. The update timestamp of the object is set when the
+// transaction starts, so the transaction committed after the last sync might have the timestamp before it. The objects
+// updated within the window before the last sync are queried again, and the delta bundle is sent once any of them
+// isn't sent yet.
+const lateCommitWindow = time.Minute
+
+// objectsBundleStream is the stream of the spec bundles sent for a message key. Once the objects are changed, the
+// delta bundle of the changed objects is broadcast with the next generation. The snapshot bundle of all the objects is
+// broadcast when the stream is started and periodically, or sent to the managed hubs which request it.
+type objectsBundleStream struct {
+	id                    string
+	generation            int64
+	lastSyncTimestamp     time.Time
+	lastSnapshotTimestamp time.Time
+	snapshotInterval      time.Duration
+	// sentUpdates are the update timestamps of the objects sent within the late commit window
+	sentUpdates map[string]time.Time
+	requests    snapshot.Requests
+}
+
+func newObjectsBundleStream(snapshotInterval time.Duration) *objectsBundleStream {
+	return &objectsBundleStream{
+		id:               uuid.New().String(),
+		snapshotInterval: snapshotInterval,
+		requests:         snapshot.NewRequests(),
+	}
+}
+
+// snapshotDue returns true if the snapshot should be broadcast, the periodic snapshot is disabled by zero interval.
+func (s *objectsBundleStream) snapshotDue(now time.Time) bool {
+	return s.generation == 0 || (s.snapshotInterval > 0 && now.Sub(s.lastSnapshotTimestamp) >= s.snapshotInterval)
+}
+
+// windowStart returns the start of the late commit window of the last sync.
+func (s *objectsBundleStream) windowStart() time.Time {
+	if s.lastSyncTimestamp.IsZero() {
+		return s.lastSyncTimestamp
+	}
+	return s.lastSyncTimestamp.Add(-lateCommitWindow)
+}
+
+// unsent returns true if any of the updates isn't sent by the stream.
+func (s *objectsBundleStream) unsent(updates map[string]time.Time) bool {
+	for id, updatedAt := range updates {
+		if sentAt, ok := s.sentUpdates[id]; !ok || !sentAt.Equal(updatedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// markSent records the updates which are sent, only the ones within the late commit window are kept.
+func (s *objectsBundleStream) markSent(updates map[string]time.Time) {
+	windowStart := s.windowStart()
+	s.sentUpdates = make(map[string]time.Time)
+	for id, updatedAt := range updates {
+		if updatedAt.After(windowStart) {
+			s.sentUpdates[id] = updatedAt
 		}
 	}
 }
@@ -82,42 +151,114 @@ func (syncer *genericDBToTransportSyncer) periodicSync(ctx context.Context) {
 // otherwise false.
 func syncObjectsBundle(ctx context.Context, producer transport.Producer, transportBundleKey string,
 	specDB db.SpecDB, dbTableName string, createObjFunc bundle.CreateObjectFunction,
-	createBundleFunc bundle.CreateBundleFunction, lastSyncTimestampPtr *time.Time,
+	createBundleFunc bundle.CreateBundleFunction, stream *objectsBundleStream,
 ) (bool, error) {
-	lastUpdateTimestamp, err := specDB.GetLastUpdateTimestamp(ctx, dbTableName, true) // filter local resources
+	// the updates are read before the objects, so the objects committed in between are sent again at most
+	updates, err := specDB.GetUpdateTimestamps(ctx, dbTableName, stream.windowStart())
 	if err != nil {
 		return false, fmt.Errorf("unable to sync bundle - %w", err)
 	}
+	requests, err := stream.requests.Pending(ctx, transportBundleKey)
+	if err != nil {
+		return false, fmt.Errorf("unable to get the snapshot requests - %w", err)
+	}
 
-	if !lastUpdateTimestamp.After(*lastSyncTimestampPtr) { // sync only if something has changed
+	now := time.Now()
+	broadcastSnapshot := stream.snapshotDue(now)
+	changed := stream.unsent(updates)
+
+	if !changed && !broadcastSnapshot && len(requests) == 0 { // sync only if something has changed
 		return false, nil
 	}
 
-	// if we got here, then the last update timestamp from db is after what we have in memory.
-	// this means something has changed in db, syncing all the objects to transport.
+	if changed || broadcastSnapshot {
+		if err := syncBroadcastBundle(ctx, producer, transportBundleKey, specDB, dbTableName, createObjFunc,
+			createBundleFunc, stream, broadcastSnapshot, now); err != nil {
+			return false, err
+		}
+		stream.markSent(updates)
+		// the hubs requesting the snapshot get the broadcast one
+		if broadcastSnapshot || len(requests) == 0 {
+			return true, stream.requests.Complete(ctx, requests)
+		}
+	}
+
+	// the snapshot is sent with the current generation, so that the hub applies the following delta bundles
 	bundleResult := createBundleFunc()
-	lastUpdateTimestamp, err = specDB.GetObjectsBundle(ctx, dbTableName, createObjFunc, bundleResult)
+	if _, err := specDB.GetObjectsBundle(ctx, dbTableName, createObjFunc, bundleResult); err != nil {
+		return changed, fmt.Errorf("unable to sync bundle - %w", err)
+	}
+	bundleResult.SetGeneration(spec.BundleGeneration{
+		Stream:     stream.id,
+		Generation: stream.generation,
+		Snapshot:   true,
+	})
+	for i, request := range requests {
+		if err := sendObjectsBundle(ctx, producer, transportBundleKey, dbTableName, request.LeafHubName,
+			bundleResult); err != nil {
+			// the requests of the remaining hubs are served in the next round
+			if completeErr := stream.requests.Complete(ctx, requests[:i]); completeErr != nil {
+				return true, completeErr
+			}
+			return changed || i > 0, err
+		}
+	}
+	return true, stream.requests.Complete(ctx, requests)
+}
 
+// syncBroadcastBundle broadcasts the snapshot bundle, or the delta bundle of the objects changed since the last sync.
+func syncBroadcastBundle(ctx context.Context, producer transport.Producer, transportBundleKey string,
+	specDB db.SpecDB, dbTableName string, createObjFunc bundle.CreateObjectFunction,
+	createBundleFunc bundle.CreateBundleFunction, stream *objectsBundleStream, isSnapshot bool, now time.Time,
+) error {
+	var err error
+	var lastUpdateTimestamp *time.Time
+	bundleResult := createBundleFunc()
+	if isSnapshot {
+		lastUpdateTimestamp, err = specDB.GetObjectsBundle(ctx, dbTableName, createObjFunc, bundleResult)
+	} else {
+		windowStart := stream.windowStart()
+		lastUpdateTimestamp, err = specDB.GetUpdatedObjectsBundle(ctx, dbTableName, createObjFunc, bundleResult,
+			&windowStart)
+	}
 	if err != nil {
-		return false, fmt.Errorf("unable to sync bundle - %w", err)
+		return fmt.Errorf("unable to sync bundle - %w", err)
+	}
+
+	bundleResult.SetGeneration(spec.BundleGeneration{
+		Stream:         stream.id,
+		Generation:     stream.generation + 1,
+		BaseGeneration: stream.generation,
+		Snapshot:       isSnapshot,
+	})
+	if err := sendObjectsBundle(ctx, producer, transportBundleKey, dbTableName, transport.Broadcast,
+		bundleResult); err != nil {
+		return err
 	}
 
-	// send message to transport
-	payloadBytes, err := json.Marshal(bundleResult)
+	stream.generation++
+	stream.lastSyncTimestamp = *lastUpdateTimestamp
+	if isSnapshot {
+		stream.lastSnapshotTimestamp = now
+	}
+	return nil
+}
+
+func sendObjectsBundle(ctx context.Context, producer transport.Producer, transportBundleKey string,
+	dbTableName string, destination string, objectsBundle bundle.ObjectsBundle,
+) error {
+	payloadBytes, err := json.Marshal(objectsBundle)
 	if err != nil {
-		return false, fmt.Errorf("failed to sync marshal bundle(%s)", transportBundleKey)
+		return fmt.Errorf("failed to sync marshal bundle(%s)", transportBundleKey)
 	}
 	if err := producer.Send(ctx, &transport.Message{
-		Destination: transport.Broadcast,
+		Destination: destination,
 		Key:         transportBundleKey,
 		MsgType:     constants.SpecBundle,
 		Payload:     payloadBytes,
 	}); err != nil {
-		return false, fmt.Errorf("failed to sync message(%s) from table(%s) to destination(%s) - %w",
-			transportBundleKey, dbTableName, transport.Broadcast, err)
+		return fmt.Errorf("failed to sync message(%s) from table(%s) to destination(%s) - %w",
+			transportBundleKey, dbTableName, destination, err)
 	}
-
-	// updating value to retain same ptr between calls
-	*lastSyncTimestampPtr = *lastUpdateTimestamp
-	return true, nil
+	return nil
 }
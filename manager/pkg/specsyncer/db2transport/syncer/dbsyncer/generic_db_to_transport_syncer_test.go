@@ -0,0 +1,252 @@
+package dbsyncer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+type fakeSpecObject struct {
+	name      string
+	deleted   bool
+	updatedAt time.Time
+}
+
+// fakeSpecDB holds the objects of a table in memory.
+type fakeSpecDB struct {
+	objects []fakeSpecObject
+}
+
+func (f *fakeSpecDB) GetLastUpdateTimestamp(ctx context.Context, tableName string,
+	filterLocalResources bool,
+) (*time.Time, error) {
+	last := time.Time{}
+	for _, obj := range f.objects {
+		if obj.updatedAt.After(last) {
+			last = obj.updatedAt
+		}
+	}
+	return &last, nil
+}
+
+func (f *fakeSpecDB) GetUpdateTimestamps(ctx context.Context, tableName string, since time.Time,
+) (map[string]time.Time, error) {
+	updates := map[string]time.Time{}
+	for _, obj := range f.objects {
+		if obj.updatedAt.After(since) {
+			updates[obj.name] = obj.updatedAt
+		}
+	}
+	return updates, nil
+}
+
+func (f *fakeSpecDB) GetObjectsBundle(ctx context.Context, tableName string,
+	createObjFunc bundle.CreateObjectFunction, intoBundle bundle.ObjectsBundle,
+) (*time.Time, error) {
+	return f.GetUpdatedObjectsBundle(ctx, tableName, createObjFunc, intoBundle, &time.Time{})
+}
+
+func (f *fakeSpecDB) GetUpdatedObjectsBundle(ctx context.Context, tableName string,
+	createObjFunc bundle.CreateObjectFunction, intoBundle bundle.ObjectsBundle, timestamp *time.Time,
+) (*time.Time, error) {
+	for _, obj := range f.objects {
+		if !obj.updatedAt.After(*timestamp) {
+			continue
+		}
+		object := createObjFunc()
+		object.SetName(obj.name)
+		if obj.deleted {
+			intoBundle.AddDeletedObject(object)
+		} else {
+			intoBundle.AddObject(object, obj.name)
+		}
+	}
+	return f.GetLastUpdateTimestamp(ctx, tableName, true)
+}
+
+func (f *fakeSpecDB) QuerySpecObject(ctx context.Context, tableName, objUID string, object *client.Object) error {
+	return nil
+}
+
+func (f *fakeSpecDB) InsertSpecObject(ctx context.Context, tableName, objUID string, object *client.Object) error {
+	return nil
+}
+
+func (f *fakeSpecDB) UpdateSpecObject(ctx context.Context, tableName, objUID string, object *client.Object) error {
+	return nil
+}
+
+func (f *fakeSpecDB) DeleteSpecObject(ctx context.Context, tableName, name, namespace string) error {
+	return nil
+}
+
+// fakeRequests keeps the snapshot requests in memory.
+type fakeRequests struct {
+	requests []models.SpecSnapshotRequest
+}
+
+func (r *fakeRequests) Request(ctx context.Context, leafHubName string, msgKeys ...string) error {
+	for _, msgKey := range msgKeys {
+		r.requests = append(r.requests, models.SpecSnapshotRequest{LeafHubName: leafHubName, BundleKey: msgKey})
+	}
+	return nil
+}
+
+func (r *fakeRequests) Pending(ctx context.Context, msgKey string) ([]models.SpecSnapshotRequest, error) {
+	pending := []models.SpecSnapshotRequest{}
+	for _, request := range r.requests {
+		if request.BundleKey == msgKey {
+			pending = append(pending, request)
+		}
+	}
+	return pending, nil
+}
+
+func (r *fakeRequests) Complete(ctx context.Context, requests []models.SpecSnapshotRequest) error {
+	kept := []models.SpecSnapshotRequest{}
+	for _, request := range r.requests {
+		completed := false
+		for _, c := range requests {
+			completed = completed || c == request
+		}
+		if !completed {
+			kept = append(kept, request)
+		}
+	}
+	r.requests = kept
+	return nil
+}
+
+type fakeProducer struct {
+	messages []*transport.Message
+}
+
+func (p *fakeProducer) Send(ctx context.Context, msg *transport.Message) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+type receivedBundle struct {
+	destination string
+	spec.BundleGeneration
+	objects []string
+	deleted []string
+}
+
+func (p *fakeProducer) pop(t *testing.T) []receivedBundle {
+	received := []receivedBundle{}
+	for _, msg := range p.messages {
+		genericBundle := &spec.GenericSpecBundle{}
+		assert.Nil(t, json.Unmarshal(msg.Payload, genericBundle))
+		b := receivedBundle{
+			destination:      msg.Destination,
+			BundleGeneration: genericBundle.BundleGeneration,
+			objects:          []string{},
+			deleted:          []string{},
+		}
+		for _, obj := range genericBundle.Objects {
+			b.objects = append(b.objects, obj.GetName())
+		}
+		for _, obj := range genericBundle.DeletedObjects {
+			b.deleted = append(b.deleted, obj.GetName())
+		}
+		received = append(received, b)
+	}
+	p.messages = nil
+	return received
+}
+
+func TestSyncObjectsBundle(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	specDB := &fakeSpecDB{objects: []fakeSpecObject{
+		{name: "policy1", updatedAt: start},
+		{name: "policy2", updatedAt: start.Add(lateCommitWindow)},
+	}}
+	producer := &fakeProducer{}
+	requests := &fakeRequests{}
+	stream := newObjectsBundleStream(time.Hour)
+	stream.requests = requests
+	sync := func() bool {
+		synced, err := syncObjectsBundle(ctx, producer, "Policies", specDB, "policies",
+			func() metav1.Object {
+				return &policyv1.Policy{TypeMeta: metav1.TypeMeta{Kind: "Policy", APIVersion: "policy/v1"}}
+			}, bundle.NewBaseObjectsBundle, stream)
+		assert.Nil(t, err)
+		return synced
+	}
+
+	// the stream is started with the snapshot
+	assert.True(t, sync())
+	assert.Equal(t, []receivedBundle{{
+		destination:      transport.Broadcast,
+		BundleGeneration: spec.BundleGeneration{Stream: stream.id, Generation: 1, Snapshot: true},
+		objects:          []string{"policy1", "policy2"},
+		deleted:          []string{},
+	}}, producer.pop(t))
+
+	// nothing is changed
+	assert.False(t, sync())
+	assert.Empty(t, producer.pop(t))
+
+	// only the changed objects are sent
+	specDB.objects[1] = fakeSpecObject{name: "policy2", deleted: true, updatedAt: start.Add(2 * lateCommitWindow)}
+	assert.True(t, sync())
+	assert.Equal(t, []receivedBundle{{
+		destination:      transport.Broadcast,
+		BundleGeneration: spec.BundleGeneration{Stream: stream.id, Generation: 2, BaseGeneration: 1},
+		objects:          []string{},
+		deleted:          []string{"policy2"},
+	}}, producer.pop(t))
+
+	// the object committed after the last sync with an earlier update timestamp is sent with the objects updated
+	// within the late commit window
+	specDB.objects = append(specDB.objects, fakeSpecObject{name: "policy3",
+		updatedAt: start.Add(lateCommitWindow + time.Second)})
+	assert.True(t, sync())
+	assert.Equal(t, []receivedBundle{{
+		destination:      transport.Broadcast,
+		BundleGeneration: spec.BundleGeneration{Stream: stream.id, Generation: 3, BaseGeneration: 2},
+		objects:          []string{"policy3"},
+		deleted:          []string{"policy2"},
+	}}, producer.pop(t))
+	assert.False(t, sync())
+
+	// the requested snapshot is sent to the hub with the current generation
+	assert.Nil(t, requests.Request(ctx, "hub1", "Policies", "Placements"))
+	assert.True(t, sync())
+	assert.Equal(t, []receivedBundle{{
+		destination:      "hub1",
+		BundleGeneration: spec.BundleGeneration{Stream: stream.id, Generation: 3, Snapshot: true},
+		objects:          []string{"policy1", "policy3"},
+		deleted:          []string{"policy2"},
+	}}, producer.pop(t))
+	pending, _ := requests.Pending(ctx, "Placements")
+	assert.Len(t, pending, 1)
+	pending, _ = requests.Pending(ctx, "Policies")
+	assert.Empty(t, pending)
+
+	// the periodic snapshot is broadcast, it serves the requests too
+	stream.lastSnapshotTimestamp = start.Add(-time.Hour)
+	assert.Nil(t, requests.Request(ctx, "hub2", "Policies"))
+	assert.True(t, sync())
+	assert.Equal(t, []receivedBundle{{
+		destination:      transport.Broadcast,
+		BundleGeneration: spec.BundleGeneration{Stream: stream.id, Generation: 4, BaseGeneration: 3, Snapshot: true},
+		objects:          []string{"policy1", "policy3"},
+		deleted:          []string{"policy2"},
+	}}, producer.pop(t))
+	pending, _ = requests.Pending(ctx, "Policies")
+	assert.Empty(t, pending)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package dbsyncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/bundle"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/db"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/intervalpolicy"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+// AddLocalPolicyReplacementDBToTransportSyncer adds the syncer which deletes the local policies replaced by the
+// adopted global policies. the local policy is deleted on its hub once the global policy lands on the hub, which is
+// when the compliance of the global policy is reported from the hub, so the clusters are never left without policy.
+func AddLocalPolicyReplacementDBToTransportSyncer(mgr ctrl.Manager, specDB db.SpecDB, producer transport.Producer,
+	syncerConfig *config.SyncerConfig,
+) error {
+	syncer := &localPolicyReplacementSyncer{
+		log:      ctrl.Log.WithName("db-to-transport-syncer-local-policy-replacement"),
+		producer: producer,
+	}
+	if err := mgr.Add(&genericDBToTransportSyncer{
+		log:            syncer.log,
+		intervalPolicy: intervalpolicy.NewExponentialBackoffPolicy(syncerConfig.SpecSyncInterval),
+		syncBundleFunc: syncer.sync,
+	}); err != nil {
+		return fmt.Errorf("failed to add local policy replacement db to transport syncer - %w", err)
+	}
+	return nil
+}
+
+type localPolicyReplacementSyncer struct {
+	log      logr.Logger
+	producer transport.Producer
+}
+
+func (s *localPolicyReplacementSyncer) sync(ctx context.Context) (bool, error) {
+	db := database.GetGorm().WithContext(ctx)
+	replacements := []models.LocalPolicyReplacement{}
+	if err := db.Where("replaced = ?", false).Order("leaf_hub_name").Find(&replacements).Error; err != nil {
+		return false, fmt.Errorf("failed to query the local policy replacements - %w", err)
+	}
+
+	hubReplacements := map[string][]models.LocalPolicyReplacement{}
+	for _, replacement := range replacements {
+		landed, err := s.landed(ctx, replacement)
+		if err != nil {
+			return false, err
+		}
+		if landed {
+			hubReplacements[replacement.LeafHubName] = append(hubReplacements[replacement.LeafHubName], replacement)
+		}
+	}
+
+	sent := false
+	for hub, replaced := range hubReplacements {
+		hubBundle := bundle.NewBaseObjectsBundle()
+		localPolicyIDs := []string{}
+		for _, replacement := range replaced {
+			hubBundle.AddDeletedObject(&policyv1.Policy{
+				TypeMeta: metav1.TypeMeta{APIVersion: policyv1.GroupVersion.String(), Kind: "Policy"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      replacement.LocalName,
+					Namespace: replacement.LocalNamespace,
+				},
+			})
+			localPolicyIDs = append(localPolicyIDs, replacement.LocalPolicyID)
+		}
+		payloadBytes, err := json.Marshal(hubBundle)
+		if err != nil {
+			return sent, fmt.Errorf("failed to marshal the local policy replacement bundle - %w", err)
+		}
+		if err := s.producer.Send(ctx, &transport.Message{
+			Destination: hub,
+			Key:         constants.LocalPolicyReplacementMsgKey,
+			MsgType:     constants.SpecBundle,
+			Payload:     payloadBytes,
+		}); err != nil {
+			return sent, fmt.Errorf("failed to send the local policy replacement bundle to hub %s - %w", hub, err)
+		}
+		if err := db.Model(&models.LocalPolicyReplacement{}).Where("local_policy_id IN ?", localPolicyIDs).
+			Update("replaced", true).Error; err != nil {
+			return true, fmt.Errorf("failed to mark the local policies replaced on hub %s - %w", hub, err)
+		}
+		s.log.Info("local policies replaced", "hub", hub, "policies", localPolicyIDs)
+		sent = true
+	}
+	return sent, nil
+}
+
+// landed returns true if the global policy replacing the local policy reports the compliance from the hub.
+func (s *localPolicyReplacementSyncer) landed(ctx context.Context, replacement models.LocalPolicyReplacement,
+) (bool, error) {
+	db := database.GetGorm().WithContext(ctx)
+	policyIDs := []string{}
+	if err := db.Model(&models.SpecPolicy{}).Where(`deleted = ? AND payload -> 'metadata' ->> 'name' = ? AND
+		payload -> 'metadata' ->> 'namespace' = ?`, false, replacement.PolicyName, replacement.PolicyNamespace).
+		Pluck("id", &policyIDs).Error; err != nil {
+		return false, fmt.Errorf("failed to query the global policy %s/%s - %w", replacement.PolicyNamespace,
+			replacement.PolicyName, err)
+	}
+	if len(policyIDs) == 0 {
+		return false, nil
+	}
+	var count int64
+	if err := db.Model(&models.StatusCompliance{}).Where("policy_id IN ? AND leaf_hub_name = ?", policyIDs,
+		replacement.LeafHubName).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to query the compliance of the global policy %s/%s - %w",
+			replacement.PolicyNamespace, replacement.PolicyName, err)
+	}
+	return count > 0, nil
+}
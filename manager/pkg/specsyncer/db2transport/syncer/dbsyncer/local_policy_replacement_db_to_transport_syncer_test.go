@@ -0,0 +1,85 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package dbsyncer_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+var _ = Describe("Local policy replacement syncer", Ordered, func() {
+	localPolicyID := uuid.New().String()
+	globalPolicyID := uuid.New().String()
+	hub := "replacement-hub"
+
+	replaced := func() (bool, error) {
+		replacement := &models.LocalPolicyReplacement{}
+		if err := database.GetGorm().Where("local_policy_id = ?", localPolicyID).First(replacement).Error; err != nil {
+			return false, err
+		}
+		return replacement.Replaced, nil
+	}
+
+	It("deletes the local policy on the hub once the global policy lands on it", func() {
+		db := database.GetGorm()
+		Expect(db.Exec("INSERT INTO spec.policies (id,payload) VALUES(?, ?)", globalPolicyID,
+			`{"apiVersion":"policy.open-cluster-management.io/v1","kind":"Policy",`+
+				`"metadata":{"name":"replacing-policy","namespace":"default"},"spec":{"disabled":false}}`).
+			Error).To(Succeed())
+		Expect(db.Create(&models.LocalPolicyReplacement{
+			LocalPolicyID:   localPolicyID,
+			LeafHubName:     hub,
+			LocalNamespace:  "default",
+			LocalName:       "local-policy",
+			PolicyNamespace: "default",
+			PolicyName:      "replacing-policy",
+		}).Error).To(Succeed())
+
+		By("the local policy is kept until the global policy reports the compliance from the hub")
+		Consistently(func() (bool, error) {
+			return replaced()
+		}, 3*time.Second, 1*time.Second).Should(BeFalse())
+
+		By("the local policy is deleted through the agent once the global policy lands on the hub")
+		Expect(db.Create(&models.StatusCompliance{
+			PolicyID:    globalPolicyID,
+			ClusterName: "cluster1",
+			LeafHubName: hub,
+			Error:       database.ErrorNone,
+			Compliance:  database.Compliant,
+		}).Error).To(Succeed())
+		Eventually(func() error {
+			message := waitForChannel(genericConsumer.MessageChan())
+			if message == nil || message.Key != constants.LocalPolicyReplacementMsgKey {
+				return fmt.Errorf("the local policy replacement bundle isn't received")
+			}
+			if message.Destination != hub {
+				return fmt.Errorf("the bundle is sent to %s", message.Destination)
+			}
+			hubBundle := &spec.GenericSpecBundle{}
+			if err := json.Unmarshal(message.Payload, hubBundle); err != nil {
+				return err
+			}
+			if len(hubBundle.Objects) != 0 || len(hubBundle.DeletedObjects) != 1 ||
+				hubBundle.DeletedObjects[0].GetName() != "local-policy" ||
+				hubBundle.DeletedObjects[0].GetKind() != "Policy" {
+				return fmt.Errorf("unexpected bundle: %s", string(message.Payload))
+			}
+			return nil
+		}, 15*time.Second, 1*time.Second).Should(Succeed())
+		Eventually(func() (bool, error) {
+			return replaced()
+		}, 10*time.Second, 1*time.Second).Should(BeTrue())
+	})
+})
@@ -3,12 +3,12 @@ package dbsyncer
 import (
 	"context"
 	"fmt"
-	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/config"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/bundle"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/db"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/intervalpolicy"
@@ -23,19 +23,19 @@ const (
 // AddManagedClusterSetBindingsDBToTransportSyncer adds managed-cluster-set-bindings db to transport syncer to the
 // manager.
 func AddManagedClusterSetBindingsDBToTransportSyncer(mgr ctrl.Manager, specDB db.SpecDB,
-	producer transport.Producer, specSyncInterval time.Duration,
+	producer transport.Producer, syncerConfig *config.SyncerConfig,
 ) error {
 	createObjFunc := func() metav1.Object {
 		return &clusterv1beta2.ManagedClusterSetBinding{}
 	}
-	lastSyncTimestampPtr := &time.Time{}
+	stream := newObjectsBundleStream(syncerConfig.SpecSnapshotInterval)
 
 	if err := mgr.Add(&genericDBToTransportSyncer{
 		log:            ctrl.Log.WithName("db-to-transport-syncer-managedclustersetbinding"),
-		intervalPolicy: intervalpolicy.NewExponentialBackoffPolicy(specSyncInterval),
+		intervalPolicy: intervalpolicy.NewExponentialBackoffPolicy(syncerConfig.SpecSyncInterval),
 		syncBundleFunc: func(ctx context.Context) (bool, error) {
 			return syncObjectsBundle(ctx, producer, managedClusterSetBindingsMsgKey, specDB,
-				managedClusterSetBindingsTableName, createObjFunc, bundle.NewBaseObjectsBundle, lastSyncTimestampPtr)
+				managedClusterSetBindingsTableName, createObjFunc, bundle.NewBaseObjectsBundle, stream)
 		},
 	}); err != nil {
 		return fmt.Errorf("failed to add managed-cluster-set-bindings db to transport syncer - %w", err)
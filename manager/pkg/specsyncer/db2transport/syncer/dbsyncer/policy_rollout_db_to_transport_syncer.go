@@ -0,0 +1,310 @@
+package dbsyncer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/api/equality"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/rollout"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/bundle"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/db"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/intervalpolicy"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+// AddPolicyRolloutDBToTransportSyncer adds the syncer which rolls out the global policies with the rollout strategy
+// annotation to the managed hubs wave by wave. the policies are sent to each hub individually instead of broadcast.
+// the waves are evaluated by the compliance stored in the database every sync interval, the syncer runs on the leader
+// only while the compliance is handled by all the replicas.
+func AddPolicyRolloutDBToTransportSyncer(mgr ctrl.Manager, specDB db.SpecDB, producer transport.Producer,
+	syncerConfig *config.SyncerConfig,
+) error {
+	syncer := &policyRolloutSyncer{
+		log:      ctrl.Log.WithName("db-to-transport-syncer-policy-rollout"),
+		client:   mgr.GetClient(),
+		producer: producer,
+		lastSent: map[string][]byte{},
+	}
+	if err := mgr.Add(&genericDBToTransportSyncer{
+		log:            syncer.log,
+		intervalPolicy: intervalpolicy.NewExponentialBackoffPolicy(syncerConfig.SpecSyncInterval),
+		syncBundleFunc: syncer.sync,
+	}); err != nil {
+		return fmt.Errorf("failed to add policy rollout db to transport syncer - %w", err)
+	}
+	return nil
+}
+
+type policyRolloutSyncer struct {
+	log      logr.Logger
+	client   client.Client
+	producer transport.Producer
+	// lastSent is the last bundle sent to the hub
+	lastSent map[string][]byte
+}
+
+func (s *policyRolloutSyncer) sync(ctx context.Context) (bool, error) {
+	hubLabels, err := s.listHubLabels(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	policies := []models.SpecPolicy{}
+	if err := database.GetGorm().Where(`payload->'metadata'->'labels'->? IS NOT NULL AND
+		payload->'metadata'->'annotations'->? IS NOT NULL`, constants.GlobalHubGlobalResourceLabel,
+		constants.PolicyRolloutStrategyAnnotation).Find(&policies).Error; err != nil {
+		return false, fmt.Errorf("failed to query the policies with rollout strategy - %w", err)
+	}
+
+	rollouts := []*models.PolicyRollout{}
+	policyIDs := []string{}
+	for i := range policies {
+		if policies[i].Deleted {
+			continue
+		}
+		policyRollout, err := s.progress(&policies[i], hubLabels)
+		if err != nil {
+			s.log.Error(err, "failed to progress the policy rollout", "policyID", policies[i].ID)
+			continue
+		}
+		rollouts = append(rollouts, policyRollout)
+		policyIDs = append(policyIDs, policies[i].ID)
+	}
+
+	// the policy is deleted or the rollout strategy is removed from it
+	query := database.GetGorm()
+	if len(policyIDs) > 0 {
+		query = query.Where("policy_id NOT IN ?", policyIDs)
+	} else {
+		query = query.Where("1 = 1")
+	}
+	if err := query.Delete(&models.PolicyRollout{}).Error; err != nil {
+		return false, fmt.Errorf("failed to delete the stale policy rollouts - %w", err)
+	}
+
+	return s.send(ctx, rollouts, hubLabels)
+}
+
+func (s *policyRolloutSyncer) listHubLabels(ctx context.Context) (map[string]map[string]string, error) {
+	clusters := &clusterv1.ManagedClusterList{}
+	if err := s.client.List(ctx, clusters); err != nil {
+		return nil, fmt.Errorf("failed to list the managed hubs - %w", err)
+	}
+	hubLabels := map[string]map[string]string{}
+	for _, cluster := range clusters.Items {
+		hubLabels[cluster.Name] = cluster.Labels
+	}
+	return hubLabels, nil
+}
+
+// progress loads the rollout state of the policy, starts a new rollout if the policy is changed, then moves the
+// rollout forward by the status reported from the hubs. the generation of the stored policy is always 0, so the
+// rollout keeps its own generation, which is increased once the spec of the stored policy is changed.
+func (s *policyRolloutSyncer) progress(policy *models.SpecPolicy, hubLabels map[string]map[string]string,
+) (*models.PolicyRollout, error) {
+	object, err := decodePolicy(policy.Payload)
+	if err != nil {
+		return nil, err
+	}
+	strategyValue := object.GetAnnotations()[constants.PolicyRolloutStrategyAnnotation]
+
+	db := database.GetGorm()
+	policyRollout := &models.PolicyRollout{}
+	err = db.Where("policy_id = ?", policy.ID).First(policyRollout).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	state := rollout.NewState()
+	changed := true
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policyRollout = &models.PolicyRollout{PolicyID: policy.ID}
+	} else if changed, err = specChanged(policyRollout.Payload, object); err != nil {
+		return nil, err
+	}
+	if changed {
+		// the previous version becomes the stable version once it's rolled out completely
+		if policyRollout.Phase == rollout.PhaseCompleted {
+			policyRollout.StablePayload = policyRollout.Payload
+			policyRollout.StableGeneration = policyRollout.Generation
+		}
+		policyRollout.Generation++
+	} else {
+		state.Phase = policyRollout.Phase
+		state.CurrentWave = policyRollout.CurrentWave
+		state.Message = policyRollout.Message
+		if err := json.Unmarshal(policyRollout.Waves, &state.Waves); err != nil {
+			return nil, err
+		}
+	}
+	policyRollout.Payload = policy.Payload
+	policyRollout.Strategy = []byte(strategyValue)
+
+	strategy, err := rollout.ParseStrategy(strategyValue)
+	if err != nil {
+		// keep the invalid strategy as a string since it might not be a json
+		policyRollout.Strategy, _ = json.Marshal(strategyValue)
+		state.Phase = rollout.PhasePaused
+		state.Message = err.Error()
+	} else {
+		state.RefreshWaves(strategy.AssignHubs(hubLabels))
+		if err := state.Progress(strategy, func(hubs []string) (int, error) {
+			return percentage(policy.ID, policyRollout.Generation, hubs, strategy.ThresholdType)
+		}, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	policyRollout.Phase = state.Phase
+	policyRollout.CurrentWave = state.CurrentWave
+	policyRollout.Message = state.Message
+	if policyRollout.Waves, err = json.Marshal(state.Waves); err != nil {
+		return nil, err
+	}
+	if err := db.Save(policyRollout).Error; err != nil {
+		return nil, err
+	}
+	return policyRollout, nil
+}
+
+// specChanged returns true if the spec of the policy is different from the rolled out version.
+func specChanged(rolledOutPayload []byte, object *policyv1.Policy) (bool, error) {
+	rolledOut, err := decodePolicy(rolledOutPayload)
+	if err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepEqual(rolledOut.Spec, object.Spec), nil
+}
+
+// percentage returns the percentage of the hubs which report the status of the policy for the applied threshold,
+// or the percentage of the compliant clusters from the hubs for the compliant threshold. only the status reported for
+// the rolled out generation is counted, the rows of the previous version don't pass the threshold.
+func percentage(policyID string, generation int64, hubs []string, thresholdType string) (int, error) {
+	db := database.GetGorm()
+	var matched, total int64
+	if thresholdType == rollout.ThresholdCompliant {
+		if err := db.Model(&models.StatusCompliance{}).Where(
+			"policy_id = ? AND policy_generation = ? AND leaf_hub_name IN ?",
+			policyID, generation, hubs).Count(&total).Error; err != nil {
+			return 0, err
+		}
+		if err := db.Model(&models.StatusCompliance{}).Where(
+			"policy_id = ? AND policy_generation = ? AND leaf_hub_name IN ? AND compliance = ?",
+			policyID, generation, hubs, database.Compliant).Count(&matched).Error; err != nil {
+			return 0, err
+		}
+	} else {
+		total = int64(len(hubs))
+		if err := db.Model(&models.StatusCompliance{}).Where(
+			"policy_id = ? AND policy_generation = ? AND leaf_hub_name IN ?",
+			policyID, generation, hubs).Distinct("leaf_hub_name").Count(&matched).Error; err != nil {
+			return 0, err
+		}
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	return int(matched * 100 / total), nil
+}
+
+// send delivers the target version of the policies to the hubs of the rolled out waves, and the stable version to the
+// others. the bundle is only sent when it's changed for the hub.
+func (s *policyRolloutSyncer) send(ctx context.Context, rollouts []*models.PolicyRollout,
+	hubLabels map[string]map[string]string,
+) (bool, error) {
+	sent := false
+	for hub := range hubLabels {
+		hubBundle := bundle.NewBaseObjectsBundle()
+		for _, policyRollout := range rollouts {
+			state := &rollout.State{
+				Phase:       policyRollout.Phase,
+				CurrentWave: policyRollout.CurrentWave,
+				Waves:       wavesOf(policyRollout),
+			}
+			payload, generation := policyRollout.StablePayload, policyRollout.StableGeneration
+			if state.ReceivesTarget(hub) {
+				payload, generation = policyRollout.Payload, policyRollout.Generation
+			}
+			if len(payload) == 0 {
+				// there is no stable version to roll back to, remove the policy from the hub
+				if state.Phase == rollout.PhaseRolledBack {
+					object, err := decodePolicy(policyRollout.Payload)
+					if err != nil {
+						return sent, err
+					}
+					hubBundle.AddDeletedObject(object)
+				}
+				continue
+			}
+			object, err := decodePolicy(payload)
+			if err != nil {
+				return sent, err
+			}
+			object.SetUID("") // cleanup UID to avoid apply conflict in managed hub
+			// the hub reports the compliance with the delivered generation
+			annotations := object.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[constants.PolicyRolloutGenerationAnnotation] = strconv.FormatInt(generation, 10)
+			object.SetAnnotations(annotations)
+			hubBundle.AddObject(object, policyRollout.PolicyID)
+		}
+
+		payloadBytes, err := json.Marshal(hubBundle)
+		if err != nil {
+			return sent, fmt.Errorf("failed to marshal the policy rollout bundle - %w", err)
+		}
+		if lastPayload, found := s.lastSent[hub]; (found && bytes.Equal(lastPayload, payloadBytes)) ||
+			(!found && len(rollouts) == 0) {
+			continue
+		}
+		if err := s.producer.Send(ctx, &transport.Message{
+			Destination: hub,
+			Key:         constants.PolicyRolloutMsgKey,
+			MsgType:     constants.SpecBundle,
+			Payload:     payloadBytes,
+		}); err != nil {
+			return sent, fmt.Errorf("failed to send the policy rollout bundle to hub %s - %w", hub, err)
+		}
+		s.lastSent[hub] = payloadBytes
+		sent = true
+	}
+	return sent, nil
+}
+
+func wavesOf(policyRollout *models.PolicyRollout) []rollout.WaveStatus {
+	waves := []rollout.WaveStatus{}
+	_ = json.Unmarshal(policyRollout.Waves, &waves)
+	return waves
+}
+
+// decodePolicy decodes the stored policy, the encrypted fields are restored since the policy is sent to the hubs.
+func decodePolicy(payload []byte) (*policyv1.Policy, error) {
+	payload, err := sensitive.Decrypt(payload)
+	if err != nil {
+		return nil, err
+	}
+	object := &policyv1.Policy{}
+	if err := json.Unmarshal(payload, object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
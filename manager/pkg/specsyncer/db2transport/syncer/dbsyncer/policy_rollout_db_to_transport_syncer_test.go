@@ -0,0 +1,155 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package dbsyncer_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/rollout"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+)
+
+var _ = Describe("Policy rollout syncer", Ordered, func() {
+	policyID := uuid.New().String()
+	canaryHub := "rollout-canary-hub"
+
+	policyPayload := func(disabled bool) []byte {
+		payload, err := json.Marshal(&policyv1.Policy{
+			TypeMeta: metav1.TypeMeta{APIVersion: policyv1.GroupVersion.String(), Kind: "Policy"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rollout-policy",
+				Namespace: "default",
+				Labels:    map[string]string{constants.GlobalHubGlobalResourceLabel: ""},
+				Annotations: map[string]string{constants.PolicyRolloutStrategyAnnotation: `{"waves":[{"name":"canary",` +
+					`"hubSelector":{"matchLabels":{"rollout":"canary"}}}],"waveTimeout":"1h"}`},
+			},
+			Spec: policyv1.PolicySpec{Disabled: disabled, RemediationAction: policyv1.Inform},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return payload
+	}
+
+	currentRollout := func() (*models.PolicyRollout, []rollout.WaveStatus, error) {
+		drainMessages()
+		policyRollout := &models.PolicyRollout{}
+		if err := database.GetGorm().Where("policy_id = ?", policyID).First(policyRollout).Error; err != nil {
+			return nil, nil, err
+		}
+		waves := []rollout.WaveStatus{}
+		if err := json.Unmarshal(policyRollout.Waves, &waves); err != nil {
+			return nil, nil, err
+		}
+		return policyRollout, waves, nil
+	}
+
+	BeforeAll(func() {
+		Expect(kubeClient.Create(ctx, &clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   canaryHub,
+				Labels: map[string]string{"rollout": "canary"},
+			},
+			Spec: clusterv1.ManagedClusterSpec{HubAcceptsClient: true},
+		})).To(Succeed())
+	})
+
+	It("restarts the waves once the policy is edited and gates them on the compliance of the new version", func() {
+		db := database.GetGorm()
+
+		By("roll out the first version to the canary wave")
+		Expect(db.Exec("INSERT INTO spec.policies (id,payload) VALUES(?, ?)", policyID, policyPayload(false)).
+			Error).To(Succeed())
+		Eventually(func() error {
+			policyRollout, waves, err := currentRollout()
+			if err != nil {
+				return err
+			}
+			if policyRollout.Generation != 1 || policyRollout.CurrentWave != 0 || len(waves) == 0 ||
+				waves[0].Name != "canary" {
+				return fmt.Errorf("the first version isn't rolling out to the canary wave: %d/%d",
+					policyRollout.Generation, policyRollout.CurrentWave)
+			}
+			return nil
+		}, 10*time.Second, 1*time.Second).Should(Succeed())
+
+		By("the canary wave is completed by the compliance of the first version")
+		Expect(db.Create(&models.StatusCompliance{
+			PolicyID:         policyID,
+			ClusterName:      "cluster1",
+			LeafHubName:      canaryHub,
+			Error:            database.ErrorNone,
+			Compliance:       database.Compliant,
+			PolicyGeneration: 1,
+		}).Error).To(Succeed())
+		Eventually(func() error {
+			policyRollout, _, err := currentRollout()
+			if err != nil {
+				return err
+			}
+			if policyRollout.CurrentWave == 0 {
+				return fmt.Errorf("the canary wave isn't completed")
+			}
+			return nil
+		}, 10*time.Second, 1*time.Second).Should(Succeed())
+
+		By("edit the policy, the waves restart with the new generation")
+		Expect(db.Exec("UPDATE spec.policies SET payload = ? WHERE id = ?", policyPayload(true), policyID).
+			Error).To(Succeed())
+		Eventually(func() error {
+			policyRollout, waves, err := currentRollout()
+			if err != nil {
+				return err
+			}
+			if policyRollout.Generation != 2 || policyRollout.CurrentWave != 0 || len(waves) == 0 ||
+				waves[0].CompletedAt != nil {
+				return fmt.Errorf("the waves aren't restarted: %d/%d", policyRollout.Generation,
+					policyRollout.CurrentWave)
+			}
+			return nil
+		}, 10*time.Second, 1*time.Second).Should(Succeed())
+
+		By("the compliance of the first version doesn't complete the canary wave")
+		Consistently(func() int {
+			policyRollout, _, err := currentRollout()
+			Expect(err).NotTo(HaveOccurred())
+			return policyRollout.CurrentWave
+		}, 3*time.Second, 1*time.Second).Should(Equal(0))
+
+		By("the canary wave is completed by the compliance of the new version")
+		Expect(db.Model(&models.StatusCompliance{}).Where("policy_id = ? AND leaf_hub_name = ?", policyID,
+			canaryHub).Update("policy_generation", 2).Error).To(Succeed())
+		Eventually(func() error {
+			policyRollout, _, err := currentRollout()
+			if err != nil {
+				return err
+			}
+			if policyRollout.CurrentWave == 0 {
+				return fmt.Errorf("the canary wave isn't completed by the new version")
+			}
+			return nil
+		}, 10*time.Second, 1*time.Second).Should(Succeed())
+	})
+})
+
+// drainMessages reads the pending messages of the consumer, so that the syncers aren't blocked by the unbuffered
+// channel transport.
+func drainMessages() {
+	for {
+		select {
+		case <-genericConsumer.MessageChan():
+		default:
+			return
+		}
+	}
+}
@@ -15,11 +15,11 @@ import (
 
 // AddDB2TransportSyncers adds the controllers that send info from DB to transport layer to the Manager.
 func AddDB2TransportSyncers(mgr ctrl.Manager, managerConfig *config.ManagerConfig, producer transport.Producer) error {
-	specSyncInterval := managerConfig.SyncerConfig.SpecSyncInterval
-
-	addDBSyncerFunctions := []func(ctrl.Manager, db.SpecDB, transport.Producer, time.Duration) error{
+	addDBSyncerFunctions := []func(ctrl.Manager, db.SpecDB, transport.Producer, *config.SyncerConfig) error{
 		// dbsyncer.AddHoHConfigDBToTransportSyncer,
 		dbsyncer.AddPoliciesDBToTransportSyncer,
+		dbsyncer.AddPolicyRolloutDBToTransportSyncer,
+		dbsyncer.AddLocalPolicyReplacementDBToTransportSyncer,
 		dbsyncer.AddPlacementRulesDBToTransportSyncer,
 		dbsyncer.AddPlacementBindingsDBToTransportSyncer,
 		dbsyncer.AddApplicationsDBToTransportSyncer,
@@ -32,7 +32,7 @@ func AddDB2TransportSyncers(mgr ctrl.Manager, managerConfig *config.ManagerConfi
 	}
 	specDB := gorm.NewGormSpecDB()
 	for _, addDBSyncerFunction := range addDBSyncerFunctions {
-		if err := addDBSyncerFunction(mgr, specDB, producer, specSyncInterval); err != nil {
+		if err := addDBSyncerFunction(mgr, specDB, producer, managerConfig.SyncerConfig); err != nil {
 			return fmt.Errorf("failed to add DB Syncer: %w", err)
 		}
 	}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"gorm.io/gorm"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -18,6 +19,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/audit"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/db"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 )
@@ -70,7 +72,10 @@ func (r *genericSpecToDBReconciler) Reconcile(ctx context.Context, request ctrl.
 	if !r.areEqual(instance, instanceInTheDatabase) {
 		reqLogger.Info("Mismatch between hub and the database, updating the database")
 
-		if err := r.specDB.UpdateSpecObject(ctx, r.tableName, instanceUID, &instance); err != nil {
+		if err := r.writeSpecObject(ctx, audit.ActionUpdate, instance.GetNamespace(), instance.GetName(), instanceUID,
+			instanceInTheDatabase, instance, func(specDB db.ObjectsSpecDB, tx *gorm.DB) (bool, error) {
+				return true, specDB.UpdateSpecObject(ctx, r.tableName, instanceUID, &instance)
+			}); err != nil {
 			reqLogger.Error(err, "Reconciliation failed")
 
 			return ctrl.Result{}, err
@@ -88,7 +93,7 @@ func (r *genericSpecToDBReconciler) processCR(ctx context.Context, request ctrl.
 	err := r.client.Get(ctx, request.NamespacedName, instance)
 	if apierrors.IsNotFound(err) {
 		// the instance on hub was deleted, update all the matching instances in the database as deleted
-		return "", nil, r.deleteFromTheDatabase(ctx, request.Name, request.Namespace, log)
+		return "", nil, r.deleteFromTheDatabase(ctx, request.Name, request.Namespace, "", nil, log)
 	}
 
 	if err != nil {
@@ -118,7 +123,15 @@ func (r *genericSpecToDBReconciler) removeFinalizerAndDelete(ctx context.Context
 	log.Info("Removing an instance from the database")
 
 	// the policy is being deleted, update all the matching policies in the database as deleted
-	if err := r.deleteFromTheDatabase(ctx, instance.GetName(), instance.GetNamespace(), log); err != nil {
+	instanceInTheDatabase := r.createInstance()
+	err := r.specDB.QuerySpecObject(ctx, r.tableName, string(instance.GetUID()), &instanceInTheDatabase)
+	if errors.Is(err, sql.ErrNoRows) {
+		instanceInTheDatabase = nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get the instance in the database: %w", err)
+	}
+	if err := r.deleteFromTheDatabase(ctx, instance.GetName(), instance.GetNamespace(), string(instance.GetUID()),
+		instanceInTheDatabase, log); err != nil {
 		return fmt.Errorf("failed to delete an instance from the database: %w", err)
 	}
 
@@ -156,7 +169,10 @@ func (r *genericSpecToDBReconciler) processInstanceInTheDatabase(ctx context.Con
 	if errors.Is(err, sql.ErrNoRows) {
 		log.V(2).Info("The instance with the current UID does not exist in the database, inserting...")
 
-		if err := r.specDB.InsertSpecObject(ctx, r.tableName, instanceUID, &instance); err != nil {
+		if err := r.writeSpecObject(ctx, audit.ActionCreate, instance.GetNamespace(), instance.GetName(), instanceUID,
+			nil, instance, func(specDB db.ObjectsSpecDB, tx *gorm.DB) (bool, error) {
+				return true, specDB.InsertSpecObject(ctx, r.tableName, instanceUID, &instance)
+			}); err != nil {
 			return nil, err
 		}
 
@@ -188,12 +204,33 @@ func (r *genericSpecToDBReconciler) cleanInstance(instance client.Object) client
 	return instance
 }
 
-func (r *genericSpecToDBReconciler) deleteFromTheDatabase(ctx context.Context, name, namespace string,
-	log logr.Logger,
+// deleteFromTheDatabase marks the instance as deleted in the database, the instanceInTheDatabase is the deleted
+// instance recorded into the audit log, it's nil if the instance is already removed from the hub.
+func (r *genericSpecToDBReconciler) deleteFromTheDatabase(ctx context.Context, name, namespace, instanceUID string,
+	instanceInTheDatabase client.Object, log logr.Logger,
 ) error {
 	log.V(2).Info("Instance was deleted, update the deleted field in the database")
 
-	if err := r.specDB.DeleteSpecObject(ctx, r.tableName, name, namespace); err != nil {
+	var before interface{} = map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+	}
+	if instanceInTheDatabase != nil {
+		before = instanceInTheDatabase
+	}
+	if err := r.writeSpecObject(ctx, audit.ActionDelete, namespace, name, instanceUID, before, nil,
+		func(specDB db.ObjectsSpecDB, tx *gorm.DB) (bool, error) {
+			// the deletion is recorded once, the instance already marked as deleted isn't recorded again
+			if tx == nil {
+				return false, specDB.DeleteSpecObject(ctx, r.tableName, name, namespace)
+			}
+			var found bool
+			if err := tx.Raw(fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM spec.%s WHERE
+				payload -> 'metadata' ->> 'name' = ? AND COALESCE(payload -> 'metadata' ->> 'namespace', '') = ? AND
+				deleted = false)`, r.tableName), name, namespace).Row().Scan(&found); err != nil {
+				return false, err
+			}
+			return found, specDB.DeleteSpecObject(ctx, r.tableName, name, namespace)
+		}); err != nil {
 		return err
 	}
 
@@ -201,3 +238,57 @@ func (r *genericSpecToDBReconciler) deleteFromTheDatabase(ctx context.Context, n
 
 	return nil
 }
+
+// writeSpecObject writes the change of the instance into the database, and records the change with the user of the
+// latest admitted change into the audit log in the same transaction, so the change is recorded once it's persisted.
+// The write returns whether the change is recorded, the tx is nil if the spec db doesn't support the transactions, and
+// the change isn't audited then.
+func (r *genericSpecToDBReconciler) writeSpecObject(ctx context.Context, action, namespace, name, instanceUID string,
+	before, after interface{}, write func(specDB db.ObjectsSpecDB, tx *gorm.DB) (bool, error),
+) error {
+	transactional, ok := r.specDB.(db.TransactionalSpecDB)
+	if !ok {
+		_, err := write(r.specDB, nil)
+		return err
+	}
+	return transactional.Transaction(ctx, func(specDB db.ObjectsSpecDB, tx *gorm.DB) error {
+		audited, err := write(specDB, tx)
+		if err != nil || !audited {
+			return err
+		}
+		return r.recordAudit(tx, action, namespace, name, instanceUID, before, after)
+	})
+}
+
+func (r *genericSpecToDBReconciler) recordAudit(tx *gorm.DB, action, namespace, name, instanceUID string,
+	before, after interface{},
+) error {
+	table := "spec." + r.tableName
+	kind, found := audit.SpecKind(table)
+	if !found {
+		return nil
+	}
+	actor, groups, err := audit.GetActor(tx, kind, namespace, name)
+	if err != nil {
+		return fmt.Errorf("failed to get the user of the change: %w", err)
+	}
+	if _, err := audit.Record(tx, &audit.Entry{
+		Actor:     actor,
+		Groups:    groups,
+		Source:    audit.SourceKubernetes,
+		Action:    action,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		ID:        instanceUID,
+		Table:     table,
+		Before:    before,
+		After:     after,
+	}); err != nil {
+		return fmt.Errorf("failed to record the audit log: %w", err)
+	}
+	if action == audit.ActionDelete {
+		return audit.ClearActor(tx, kind, namespace, name)
+	}
+	return nil
+}
@@ -57,6 +57,7 @@ func (dispatcher *ConflationDispatcher) dispatch(ctx context.Context) {
 			bundle, bundleMetadata, handlerFunction, err := conflationUnit.GetNext()
 			if err != nil {
 				dispatcher.log.Info(err.Error()) // don't need to throw the error when bundle is not ready
+				dispatcher.dbWorkerPool.Release(dbWorker)
 				continue
 			}
 
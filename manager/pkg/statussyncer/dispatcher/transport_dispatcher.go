@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/transporthealth"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
 	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
 	"github.com/stolostron/multicluster-global-hub/pkg/statistics"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport"
@@ -39,6 +42,16 @@ func (d *TransportDispatcher) BundleRegister(registration *registration.BundleRe
 	d.bundleRegistrations[registration.MsgID] = registration
 }
 
+// BundleTypes returns the bundle types of the registered message keys, the message key is the key of the bundle
+// on the managed hubs.
+func (d *TransportDispatcher) BundleTypes() map[string]string {
+	bundleTypes := make(map[string]string, len(d.bundleRegistrations))
+	for msgID, registration := range d.bundleRegistrations {
+		bundleTypes[msgID] = bundle.GetBundleType(registration.CreateBundleFunc())
+	}
+	return bundleTypes
+}
+
 // Start function starts bundles status syncer.
 func (d *TransportDispatcher) Start(ctx context.Context) error {
 	d.log.Info("transport dispatcher starts dispatching received bundles...")
@@ -65,6 +78,8 @@ func (d *TransportDispatcher) dispatch(ctx context.Context) {
 				continue
 			}
 
+			transporthealth.RecordReceived(msgIDTokens[0], message.Time, time.Now())
+
 			msgID := msgIDTokens[1]
 			if _, found := d.bundleRegistrations[msgID]; !found {
 				// no one registered for this msg id
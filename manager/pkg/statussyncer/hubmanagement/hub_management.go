@@ -14,15 +14,18 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/notification"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/resync"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
-	"github.com/stolostron/multicluster-global-hub/pkg/transport"
 )
 
 const (
 	HubActive   = "active"
 	HubInactive = "inactive"
+	// HubDegraded means the hub is still alive, but the agent reports it isn't working well
+	HubDegraded = "degraded"
 
 	// heartbeatInterval = 1 * time.Minute
 	ActiveTimeout = 5 * time.Minute // if heartbeat < (now - ActiveTimeout), then status = inactive, vice versa
@@ -32,15 +35,13 @@ const (
 // manage the leaf hub lifecycle based on the heartbeat
 type hubManagement struct {
 	log           logr.Logger
-	producer      transport.Producer
 	probeDuration time.Duration
 	activeTimeout time.Duration
 }
 
-func AddHubManagement(mgr ctrl.Manager, producer transport.Producer) error {
+func AddHubManagement(mgr ctrl.Manager) error {
 	return mgr.Add(&hubManagement{
 		log:           ctrl.Log.WithName("hub-management"),
-		producer:      producer,
 		probeDuration: ProbeDuration,
 		activeTimeout: ActiveTimeout,
 	})
@@ -70,7 +71,7 @@ func (h *hubManagement) update(ctx context.Context) error {
 	db := database.GetGorm()
 
 	var expiredHubs []models.LeafHubHeartbeat
-	if err := db.Where("last_timestamp < ? AND status = ?", thresholdTime, HubActive).
+	if err := db.Where("last_timestamp < ? AND status IN ?", thresholdTime, []string{HubActive, HubDegraded}).
 		Find(&expiredHubs).Error; err != nil {
 		return err
 	}
@@ -86,6 +87,46 @@ func (h *hubManagement) update(ctx context.Context) error {
 	if err := h.reactive(ctx, reactiveHubs, thresholdTime); err != nil {
 		return fmt.Errorf("failed to reactive hubs %v", err)
 	}
+
+	var aliveHubs []models.LeafHubHeartbeat
+	if err := db.Where("last_timestamp > ? AND status IN ?", thresholdTime, []string{HubActive, HubDegraded}).
+		Find(&aliveHubs).Error; err != nil {
+		return err
+	}
+	if err := h.degrade(aliveHubs); err != nil {
+		return fmt.Errorf("failed to update the degraded hubs %v", err)
+	}
+	return nil
+}
+
+// degrade switches the alive hubs between active and degraded based on the agent health in the heartbeat
+func (h *hubManagement) degrade(hubs []models.LeafHubHeartbeat) error {
+	db := database.GetGorm()
+	for _, hub := range hubs {
+		reasons := []string{}
+		if len(hub.Health) > 0 {
+			health := &base.AgentHealth{}
+			if err := json.Unmarshal(hub.Health, health); err != nil {
+				h.log.Error(err, "failed to unmarshal the agent health", "name", hub.Name)
+				continue
+			}
+			reasons = health.DegradedReasons()
+		}
+
+		status := HubActive
+		if len(reasons) > 0 {
+			status = HubDegraded
+		}
+		if status == hub.Status {
+			continue
+		}
+
+		h.log.Info("update the hub status", "name", hub.Name, "status", status, "reasons", reasons)
+		err := db.Model(&models.LeafHubHeartbeat{}).Where("leaf_hub_name = ?", hub.Name).Update("status", status).Error
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -102,6 +143,10 @@ func (h *hubManagement) inactive(ctx context.Context, hubs []models.LeafHubHeart
 		if err != nil {
 			return err
 		}
+		notification.Emit(notification.NewEvent(notification.HubInactive,
+			fmt.Sprintf("the hub %s is inactive, the last heartbeat is at %s", hub.Name,
+				hub.LastUpdateAt.UTC().Format(time.RFC3339)),
+			map[string]string{notification.DataLeafHubName: hub.Name}))
 	}
 	return nil
 }
@@ -117,6 +162,22 @@ func (h *hubManagement) cleanup(hubName string) error {
 			return e
 		}
 
+		// delete the addons of the clusters
+		e = tx.Where(&models.ManagedClusterAddOn{
+			LeafHubName: hubName,
+		}).Delete(&models.ManagedClusterAddOn{}).Error
+		if e != nil {
+			return e
+		}
+
+		// delete the argo cd applications
+		e = tx.Where(&models.GitOpsApplication{
+			LeafHubName: hubName,
+		}).Delete(&models.GitOpsApplication{}).Error
+		if e != nil {
+			return e
+		}
+
 		// soft delete the hub info
 		e = tx.Where(&models.LeafHub{
 			LeafHubName: hubName,
@@ -165,26 +226,17 @@ func (h *hubManagement) reactive(ctx context.Context, hubs []models.LeafHubHeart
 		if err != nil {
 			return err
 		}
+		notification.Emit(notification.NewEvent(notification.HubActive,
+			fmt.Sprintf("the hub %s is active again", hub.Name),
+			map[string]string{notification.DataLeafHubName: hub.Name}))
 	}
 	return nil
 }
 
+// resync requests the reactivated hub to resend the bundles which are cleaned up while the hub is inactive, the
+// progress is tracked by the resync controller.
 func (h *hubManagement) resync(ctx context.Context, hubName string) error {
-	resyncResources := []string{
-		constants.ManagedClustersMsgKey,
-		constants.HubClusterInfoMsgKey,
-		constants.LocalPolicySpecMsgKey,
-		constants.LocalComplianceMsgKey,
-	}
-	payloadBytes, err := json.Marshal(resyncResources)
-	if err != nil {
-		return err
-	}
-
-	return h.producer.Send(ctx, &transport.Message{
-		Key:         constants.ResyncMsgKey,
-		Destination: hubName,
-		MsgType:     constants.SpecBundle,
-		Payload:     payloadBytes,
-	})
+	_, err := resync.Create(database.GetGorm().WithContext(ctx), []string{hubName}, resync.DefaultBundleKeys,
+		resync.TriggerHubReactivated, resync.DefaultTimeout)
+	return err
 }
@@ -5,6 +5,7 @@ package hubmanagement
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -13,9 +14,10 @@ import (
 	"gorm.io/gorm/clause"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/resync"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
-	"github.com/stolostron/multicluster-global-hub/pkg/transport"
 	"github.com/stolostron/multicluster-global-hub/test/pkg/testpostgres"
 )
 
@@ -29,6 +31,12 @@ func TestHubManagement(t *testing.T) {
 
 	// prepare data
 	now := time.Now()
+	degradedHealth, err := json.Marshal(&base.AgentHealth{
+		Bundles: map[string]*base.BundleSendStatus{
+			"heartbeat-hub05.ManagedClusters": {ErrorCount: 5, ConsecutiveErrors: 2, LastError: "timeout"},
+		},
+	})
+	assert.Nil(t, err)
 	hubs := []models.LeafHubHeartbeat{
 		{
 			Name:         "heartbeat-hub01",
@@ -49,6 +57,12 @@ func TestHubManagement(t *testing.T) {
 			LastUpdateAt: now.Add(-180 * time.Second),
 			Status:       HubInactive,
 		},
+		{
+			Name:         "heartbeat-hub05",
+			LastUpdateAt: now.Add(-10 * time.Second),
+			Status:       HubActive,
+			Health:       degradedHealth,
+		},
 	}
 	db := database.GetGorm()
 	err = db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&hubs).Error
@@ -88,14 +102,14 @@ func TestHubManagement(t *testing.T) {
 	// update
 	hubManagement := &hubManagement{
 		log:           ctrl.Log.WithName("hub-management"),
-		producer:      &tmpProducer{},
 		probeDuration: 1 * time.Second,
 		activeTimeout: 90 * time.Second,
 	}
 	assert.Nil(t, hubManagement.Start(ctx))
 	time.Sleep(3 * time.Second)
 
-	fmt.Println(">> hub management[90s]: heartbeat-hub02 -> inactive, heartbeat-hub04 -> active")
+	fmt.Println(">> hub management[90s]: heartbeat-hub02 -> inactive, heartbeat-hub04 -> active, " +
+		"heartbeat-hub05 -> degraded")
 	var updatedHubs []models.LeafHubHeartbeat
 	err = db.Find(&updatedHubs).Error
 	assert.Nil(t, err)
@@ -106,17 +120,23 @@ func TestHubManagement(t *testing.T) {
 			assert.Equal(t, HubInactive, updatedHub.Status)
 			continue
 		}
+		if updatedHub.Name == "heartbeat-hub05" {
+			assert.Equal(t, HubDegraded, updatedHub.Status)
+			continue
+		}
 		assert.Equal(t, HubActive, updatedHub.Status)
 	}
 
+	// the reactivated hub is requested to resync the bundles
+	var resyncs []models.Resync
+	err = db.Where("trigger = ?", resync.TriggerHubReactivated).Find(&resyncs).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resyncs))
+	assert.JSONEq(t, `["heartbeat-hub04"]`, string(resyncs[0].LeafHubs))
+	assert.Equal(t, resync.PhasePending, resyncs[0].Phase)
+
 	// close
 	cancel()
 	err = testPostgres.Stop()
 	assert.Nil(t, err)
 }
-
-type tmpProducer struct{}
-
-func (*tmpProducer) Send(ctx context.Context, msg *transport.Message) error {
-	return nil
-}
@@ -0,0 +1,52 @@
+package statussyncer
+
+import (
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
+)
+
+// shardedRunnable runs on every manager replica instead of the leader only, the replicas split the managed hubs by
+// the kafka partitions assigned to them in the consumer group.
+type shardedRunnable struct {
+	manager.Runnable
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface.
+func (shardedRunnable) NeedLeaderElection() bool {
+	return false
+}
+
+// addStatusRunnable adds the runnable which processes the status bundles, it runs on every replica if the status
+// processing is sharded.
+func addStatusRunnable(mgr ctrl.Manager, managerConfig *config.ManagerConfig, runnable manager.Runnable) error {
+	if managerConfig.EnableStatusSharding {
+		return mgr.Add(shardedRunnable{Runnable: runnable})
+	}
+	return mgr.Add(runnable)
+}
+
+// partitionRebalancer hands over the conflation units of the managed hubs when the partitions are rebalanced between
+// the manager replicas.
+type partitionRebalancer struct {
+	log               logr.Logger
+	conflationManager *conflator.ConflationManager
+	committer         *conflator.ConflationCommitter
+}
+
+func (r *partitionRebalancer) PartitionsAssigned(partitions []metadata.TransportPosition) {
+	r.conflationManager.AssignPartitions(partitions)
+}
+
+func (r *partitionRebalancer) PartitionsRevoked(partitions []metadata.TransportPosition) {
+	r.conflationManager.SuspendPartitions(partitions)
+	// the next owner of the partitions continues from the positions committed here
+	if err := r.committer.Commit(); err != nil {
+		r.log.Error(err, "failed to commit the positions of the revoked partitions", "partitions", partitions)
+	}
+	r.conflationManager.RevokePartitions(partitions)
+}
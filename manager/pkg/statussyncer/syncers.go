@@ -6,11 +6,15 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/migration"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/resync"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/statussyncer/dispatcher"
 	"github.com/stolostron/multicluster-global-hub/manager/pkg/statussyncer/hubmanagement"
 	dbsyncer "github.com/stolostron/multicluster-global-hub/manager/pkg/statussyncer/syncers"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/transporthealth"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/cluster"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/gitops"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/grc"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/placement"
 	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
@@ -34,7 +38,7 @@ func AddStatusSyncers(mgr ctrl.Manager, managerConfig *config.ManagerConfig,
 	}
 
 	// add hub management
-	if err := hubmanagement.AddHubManagement(mgr, producer); err != nil {
+	if err := hubmanagement.AddHubManagement(mgr); err != nil {
 		return nil, fmt.Errorf("failed to add hubmanagement to manager - %w", err)
 	}
 
@@ -45,26 +49,36 @@ func AddStatusSyncers(mgr ctrl.Manager, managerConfig *config.ManagerConfig,
 
 	// add kafka offset to the database periodically
 	committer := conflator.NewKafkaConflationCommitter(conflationManager.GetTransportMetadatas)
-	if err := mgr.Add(committer); err != nil {
+	if err := addStatusRunnable(mgr, managerConfig, committer); err != nil {
 		return nil, fmt.Errorf("failed to add DB worker pool: %w", err)
 	}
 
+	// report the consumer lag of the transport periodically
+	if err := transporthealth.AddTransportHealthReporter(mgr, managerConfig); err != nil {
+		return nil, fmt.Errorf("failed to add transport health reporter: %w", err)
+	}
+
 	// database layer initialization - worker pool + connection pool
 	dbWorkerPool, err := workerpool.NewDBWorkerPool(stats)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize DBWorkerPool: %w", err)
 	}
-	if err := mgr.Add(dbWorkerPool); err != nil {
+	if err := addStatusRunnable(mgr, managerConfig, dbWorkerPool); err != nil {
 		return nil, fmt.Errorf("failed to add DB worker pool: %w", err)
 	}
 
-	transportDispatcher, err := getTransportDispatcher(mgr, conflationManager, managerConfig, stats)
+	rebalancer := &partitionRebalancer{
+		log:               ctrl.Log.WithName("partition-rebalancer"),
+		conflationManager: conflationManager,
+		committer:         committer,
+	}
+	transportDispatcher, err := getTransportDispatcher(mgr, conflationManager, rebalancer, managerConfig, stats)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transport dispatcher: %w", err)
 	}
 
 	// add ConflationDispatcher to the runtime manager
-	if err := mgr.Add(dispatcher.NewConflationDispatcher(
+	if err := addStatusRunnable(mgr, managerConfig, dispatcher.NewConflationDispatcher(
 		ctrl.Log.WithName("conflation-dispatcher"),
 		conflationReadyQueue, dbWorkerPool)); err != nil {
 		return nil, fmt.Errorf("failed to add conflation dispatcher to runtime manager: %w", err)
@@ -77,6 +91,10 @@ func AddStatusSyncers(mgr ctrl.Manager, managerConfig *config.ManagerConfig,
 		dbsyncer.NewCompliancesDBSyncer(ctrl.Log.WithName("compliances-syncer")),
 		dbsyncer.NewLocalPolicySpecSyncer(ctrl.Log.WithName("local-policy-spec-syncer")),
 		dbsyncer.NewLocalPolicyEventSyncer(ctrl.Log.WithName("local-policy-event-syncer")),
+		dbsyncer.NewManagedClusterAddOnsDBSyncer(ctrl.Log.WithName("managed-cluster-addons-syncer")),
+		dbsyncer.NewGitOpsApplicationsDBSyncer(ctrl.Log.WithName("gitops-applications-syncer")),
+		dbsyncer.NewManagedClusterMigrationSyncer(ctrl.Log.WithName("managed-cluster-migration-syncer"),
+			producer),
 	}
 
 	if managerConfig.EnableGlobalResource {
@@ -90,6 +108,9 @@ func AddStatusSyncers(mgr ctrl.Manager, managerConfig *config.ManagerConfig,
 			dbsyncer.NewSubscriptionReportsDBSyncer(
 				ctrl.Log.WithName("subscription-reports-db-syncer")),
 			dbsyncer.NewLocalSpecPlacementruleSyncer(ctrl.Log.WithName("local-spec-placementrule-syncer")),
+			dbsyncer.NewGlobalResourceDriftSyncer(ctrl.Log.WithName("global-resource-drift-syncer")),
+			dbsyncer.NewComplianceDetailsSyncer(ctrl.Log.WithName("compliance-details-syncer")),
+			dbsyncer.NewSpecSnapshotRequestSyncer(ctrl.Log.WithName("spec-snapshot-request-syncer")),
 		)
 	}
 
@@ -98,26 +119,42 @@ func AddStatusSyncers(mgr ctrl.Manager, managerConfig *config.ManagerConfig,
 		dbsyncerObj.RegisterBundleHandlerFunctions(conflationManager)
 	}
 
+	// resync the bundles from the managed hubs on request, the progress is tracked by the bundle versions recorded
+	// by every replica
+	if err := addStatusRunnable(mgr, managerConfig,
+		resync.NewVersionRecorder(conflationManager.GetProcessedVersions)); err != nil {
+		return nil, fmt.Errorf("failed to add resync version recorder: %w", err)
+	}
+	if err := resync.AddResyncController(mgr, producer, transportDispatcher.BundleTypes()); err != nil {
+		return nil, fmt.Errorf("failed to add resync controller: %w", err)
+	}
+
+	// migrate the managed clusters between the managed hubs on request, the results are applied by the db syncer
+	if err := migration.AddMigrationController(mgr, producer); err != nil {
+		return nil, fmt.Errorf("failed to add migration controller: %w", err)
+	}
+
 	return transportDispatcher, nil
 }
 
 // the transport dispatcher implement the BundleRegister() method, which can dispatch message to syncers
 // both kafkaConsumer and Cloudevents transport dispatcher will forward message to conflation manager
 func getTransportDispatcher(mgr ctrl.Manager, conflationManager *conflator.ConflationManager,
-	managerConfig *config.ManagerConfig, stats *statistics.Statistics,
-) (dbsyncer.BundleRegisterable, error) {
-	consumer, err := consumer.NewGenericConsumer(managerConfig.TransportConfig, consumer.WithDatabasePosition(true))
+	listener consumer.PartitionListener, managerConfig *config.ManagerConfig, stats *statistics.Statistics,
+) (*dispatcher.TransportDispatcher, error) {
+	consumer, err := consumer.NewGenericConsumer(managerConfig.TransportConfig, consumer.WithDatabasePosition(true),
+		consumer.WithPartitionListener(listener))
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize transport consumer: %w", err)
 	}
-	if err := mgr.Add(consumer); err != nil {
+	if err := addStatusRunnable(mgr, managerConfig, consumer); err != nil {
 		return nil, fmt.Errorf("failed to add transport consumer to manager: %w", err)
 	}
 	// consume message from consumer and dispatcher it to conflation manager
 	transportDispatcher := dispatcher.NewTransportDispatcher(
 		ctrl.Log.WithName("transport-dispatcher"), consumer,
 		conflationManager, stats)
-	if err := mgr.Add(transportDispatcher); err != nil {
+	if err := addStatusRunnable(mgr, managerConfig, transportDispatcher); err != nil {
 		return nil, fmt.Errorf("failed to add transport dispatcher to runtime manager: %w", err)
 	}
 	return transportDispatcher, nil
@@ -132,6 +169,8 @@ func addStatisticController(mgr ctrl.Manager, managerConfig *config.ManagerConfi
 		bundle.GetBundleType(&grc.LocalComplianceBundle{}),
 		bundle.GetBundleType(&grc.LocalCompleteComplianceBundle{}),
 		bundle.GetBundleType(&grc.LocalReplicatedPolicyEventBundle{}),
+		bundle.GetBundleType(&cluster.ManagedClusterAddOnBundle{}),
+		bundle.GetBundleType(&gitops.ApplicationBundle{}),
 		// bundle.GetBundleType(&placement.LocalPlacementRulesBundle{}),
 	}
 
@@ -141,12 +180,13 @@ func addStatisticController(mgr ctrl.Manager, managerConfig *config.ManagerConfi
 			bundle.GetBundleType(&placement.PlacementDecisionsBundle{}),
 			bundle.GetBundleType(&grc.ComplianceBundle{}),
 			bundle.GetBundleType(&grc.CompleteComplianceBundle{}),
+			bundle.GetBundleType(&grc.ComplianceDetailsBundle{}),
 		)
 	}
 	// create statistics
 	stats := statistics.NewStatistics(managerConfig.StatisticsConfig, bundleTypes)
 
-	if err := mgr.Add(stats); err != nil {
+	if err := addStatusRunnable(mgr, managerConfig, stats); err != nil {
 		return nil, fmt.Errorf("failed to add statistics to manager - %w", err)
 	}
 	return stats, nil
@@ -0,0 +1,163 @@
+package dbsyncer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/grc"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/registration"
+)
+
+// complianceDetailsSyncer stores the per-template status details of the global policies reported by the managed hubs.
+type complianceDetailsSyncer struct {
+	log               logr.Logger
+	detailsBundleFunc CreateBundleFunction
+}
+
+func NewComplianceDetailsSyncer(log logr.Logger) Syncer {
+	return &complianceDetailsSyncer{
+		log:               log,
+		detailsBundleFunc: grc.NewManagerComplianceDetailsBundle,
+	}
+}
+
+// RegisterCreateBundleFunctions registers create bundle functions within the transport instance.
+func (syncer *complianceDetailsSyncer) RegisterCreateBundleFunctions(transportDispatcher BundleRegisterable) {
+	transportDispatcher.BundleRegister(&registration.BundleRegistration{
+		MsgID:            constants.ComplianceDetailsMsgKey,
+		CreateBundleFunc: syncer.detailsBundleFunc,
+		Predicate:        func() bool { return true }, // always get compliance details bundles
+	})
+}
+
+// RegisterBundleHandlerFunctions registers bundle handler functions within the conflation manager.
+// the bundle holds the details of all the replicated global policies on the managed hub, so the details of the hub
+// are synced with the bundle: only the changed details are written, and the details which aren't in the bundle are
+// removed unless the bundle is truncated.
+func (syncer *complianceDetailsSyncer) RegisterBundleHandlerFunctions(
+	conflationManager *conflator.ConflationManager,
+) {
+	conflationManager.Register(conflator.NewConflationRegistration(
+		conflator.ComplianceDetailsPriority,
+		metadata.CompleteStateMode,
+		bundle.GetBundleType(syncer.detailsBundleFunc()),
+		func(ctx context.Context, bundle bundle.ManagerBundle) error {
+			return syncer.handleComplianceDetailsBundle(ctx, bundle)
+		},
+	))
+}
+
+func (syncer *complianceDetailsSyncer) handleComplianceDetailsBundle(ctx context.Context,
+	bundle bundle.ManagerBundle,
+) error {
+	logBundleHandlingMessage(syncer.log, bundle, startBundleHandlingMessage)
+	leafHubName := bundle.GetLeafHubName()
+
+	// the existing details are read from the primary database rather than GetReadGorm, the diff against the lagging
+	// replica would skip the writes of the details which are changed back
+	db := database.GetGorm()
+	existing, err := getComplianceDetailsFromDB(db, leafHubName)
+	if err != nil {
+		return fmt.Errorf("failed to get the compliance details of the hub %s: %w", leafHubName, err)
+	}
+
+	changed := []models.StatusComplianceDetails{}
+	for _, object := range bundle.GetObjects() {
+		complianceDetails, ok := object.(*grc.ComplianceDetails)
+		if !ok || complianceDetails.PolicyID == "" {
+			continue
+		}
+		templates, err := json.Marshal(complianceDetails.Templates)
+		if err != nil {
+			return err
+		}
+		key := complianceDetailsKey(complianceDetails.PolicyID, complianceDetails.ClusterName)
+		existingDetails, found := existing[key]
+		delete(existing, key)
+		if found && bytes.Equal(existingDetails.templates, templates) {
+			continue
+		}
+		changed = append(changed, models.StatusComplianceDetails{
+			PolicyID:    complianceDetails.PolicyID,
+			ClusterName: complianceDetails.ClusterName,
+			LeafHubName: leafHubName,
+			Templates:   templates,
+		})
+	}
+
+	// the details which aren't in the truncated bundle might still exist on the hub
+	if detailsBundle, ok := bundle.(*grc.ComplianceDetailsBundle); ok && detailsBundle.Truncated {
+		existing = nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, removed := range existing {
+			if err := tx.Where(&models.StatusComplianceDetails{
+				LeafHubName: leafHubName,
+				PolicyID:    removed.policyID,
+				ClusterName: removed.clusterName,
+			}).Delete(&models.StatusComplianceDetails{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(changed) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "leaf_hub_name"}, {Name: "policy_id"}, {Name: "cluster_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"templates", "updated_at"}),
+		}).CreateInBatches(changed, 100).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store the compliance details of the hub %s: %w", leafHubName, err)
+	}
+
+	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
+	return nil
+}
+
+// complianceDetailsRow is the stored details, the templates are normalized to be compared with the reported ones.
+type complianceDetailsRow struct {
+	policyID    string
+	clusterName string
+	templates   []byte
+}
+
+func complianceDetailsKey(policyID, clusterName string) string {
+	return fmt.Sprintf("%s/%s", policyID, clusterName)
+}
+
+func getComplianceDetailsFromDB(db *gorm.DB, leafHubName string) (map[string]complianceDetailsRow, error) {
+	var details []models.StatusComplianceDetails
+	if err := db.Select("policy_id", "cluster_name", "templates").
+		Where(&models.StatusComplianceDetails{LeafHubName: leafHubName}).Find(&details).Error; err != nil {
+		return nil, err
+	}
+	rows := make(map[string]complianceDetailsRow, len(details))
+	for _, detail := range details {
+		// the jsonb column reorders the keys, so the templates are marshaled again in the reported format
+		templates := []*grc.TemplateDetails{}
+		normalized := []byte(nil)
+		if err := json.Unmarshal(detail.Templates, &templates); err == nil {
+			normalized, _ = json.Marshal(templates)
+		}
+		rows[complianceDetailsKey(detail.PolicyID, detail.ClusterName)] = complianceDetailsRow{
+			policyID:    detail.PolicyID,
+			clusterName: detail.ClusterName,
+			templates:   normalized,
+		}
+	}
+	return rows, nil
+}
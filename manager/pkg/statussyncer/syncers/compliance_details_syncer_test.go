@@ -0,0 +1,159 @@
+package dbsyncer_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/grc"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+var _ = Describe("ComplianceDetailsSyncer", Ordered, func() {
+	const (
+		leafHubName = "hub1"
+		messageKey  = constants.ComplianceDetailsMsgKey
+	)
+
+	var detailsBundle *grc.ComplianceDetailsBundle
+	policyID := uuid.New().String()
+
+	BeforeAll(func() {
+		detailsBundle = grc.NewAgentComplianceDetailsBundle(leafHubName)
+	})
+
+	sendBundle := func() {
+		detailsBundle.GetVersion().Incr()
+		payloadBytes, err := json.Marshal(detailsBundle)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		err = producer.Send(ctx, &transport.Message{
+			Key:     fmt.Sprintf("%s.%s", leafHubName, messageKey),
+			MsgType: constants.StatusBundle,
+			Payload: payloadBytes,
+		})
+		Expect(err).Should(Succeed())
+	}
+
+	listDetails := func() ([]models.StatusComplianceDetails, error) {
+		details := []models.StatusComplianceDetails{}
+		err := database.GetGorm().Where("leaf_hub_name = ? AND policy_id = ?", leafHubName, policyID).
+			Order("cluster_name").Find(&details).Error
+		return details, err
+	}
+
+	It("store the compliance details of the global policy", func() {
+		detailsBundle.Objects = []*grc.ComplianceDetails{
+			{
+				PolicyID:    policyID,
+				ClusterName: "cluster1",
+				Templates: []*grc.TemplateDetails{
+					{
+						Name:          "check-namespace",
+						Kind:          "ConfigurationPolicy",
+						Compliance:    "NonCompliant",
+						Message:       "namespaces [test] not found",
+						LastTimestamp: time.Now().UTC().Truncate(time.Second),
+					},
+				},
+			},
+			{
+				PolicyID:    policyID,
+				ClusterName: "cluster2",
+				Templates:   []*grc.TemplateDetails{{Name: "check-namespace", Compliance: "Compliant"}},
+			},
+		}
+		sendBundle()
+
+		Eventually(func() error {
+			details, err := listDetails()
+			if err != nil {
+				return err
+			}
+			if len(details) != 2 {
+				return fmt.Errorf("expect 2 compliance details, but got %d", len(details))
+			}
+			templates := []*grc.TemplateDetails{}
+			if err := json.Unmarshal(details[0].Templates, &templates); err != nil {
+				return err
+			}
+			if len(templates) != 1 || templates[0].Kind != "ConfigurationPolicy" ||
+				templates[0].Message != "namespaces [test] not found" {
+				return fmt.Errorf("unexpected templates %v", string(details[0].Templates))
+			}
+			return nil
+		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	})
+
+	It("keep the unchanged compliance details", func() {
+		details, err := listDetails()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(details).Should(HaveLen(2))
+		updatedAt := details[0].UpdatedAt
+
+		detailsBundle.Objects[1].Templates[0].Compliance = "NonCompliant"
+		sendBundle()
+
+		Eventually(func() error {
+			details, err := listDetails()
+			if err != nil {
+				return err
+			}
+			if len(details) != 2 {
+				return fmt.Errorf("expect 2 compliance details, but got %d", len(details))
+			}
+			if !strings.Contains(string(details[1].Templates), "NonCompliant") {
+				return fmt.Errorf("the details of cluster2 aren't updated: %s", string(details[1].Templates))
+			}
+			if !details[0].UpdatedAt.Equal(updatedAt) {
+				return fmt.Errorf("the unchanged details of cluster1 are rewritten")
+			}
+			return nil
+		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	})
+
+	It("keep the compliance details which aren't in the truncated bundle", func() {
+		objects := detailsBundle.Objects
+		detailsBundle.Objects = objects[1:]
+		detailsBundle.Truncated = true
+		sendBundle()
+		// the next bundle isn't truncated
+		detailsBundle.Objects = objects
+		detailsBundle.Truncated = false
+
+		Consistently(func() error {
+			details, err := listDetails()
+			if err != nil {
+				return err
+			}
+			if len(details) != 2 {
+				return fmt.Errorf("expect 2 compliance details, but got %d", len(details))
+			}
+			return nil
+		}, 6*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	})
+
+	It("remove the compliance details which aren't reported", func() {
+		detailsBundle.Objects = detailsBundle.Objects[1:]
+		sendBundle()
+
+		Eventually(func() error {
+			details, err := listDetails()
+			if err != nil {
+				return err
+			}
+			if len(details) != 1 || details[0].ClusterName != "cluster2" {
+				return fmt.Errorf("expect only the details of cluster2, but got %v", details)
+			}
+			return nil
+		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,38 @@
+package dbsyncer
+
+import (
+	"fmt"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/notification"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+)
+
+// complianceTransitions collects the compliance changes of the existing clusters in a bundle, they are emitted to the
+// notification webhooks once the changes are committed into the database.
+type complianceTransitions struct {
+	leafHubName string
+	events      []*notification.Event
+}
+
+func newComplianceTransitions(leafHubName string) *complianceTransitions {
+	return &complianceTransitions{leafHubName: leafHubName}
+}
+
+func (t *complianceTransitions) add(policyID, clusterName string, compliance database.ComplianceStatus) {
+	t.events = append(t.events, notification.NewEvent(notification.PolicyComplianceChanged,
+		fmt.Sprintf("the policy %s is %s on the cluster %s of the hub %s", policyID, compliance, clusterName,
+			t.leafHubName),
+		map[string]string{
+			notification.DataLeafHubName: t.leafHubName,
+			notification.DataClusterName: clusterName,
+			notification.DataPolicyID:    policyID,
+			notification.DataCompliance:  string(compliance),
+		}))
+}
+
+func (t *complianceTransitions) emit() {
+	for _, event := range t.events {
+		notification.Emit(event)
+	}
+	t.events = nil
+}
@@ -0,0 +1,215 @@
+package dbsyncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/gitops"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/registration"
+)
+
+const (
+	unknownApplicationStatus = "Unknown"
+	// maxRevisionLength is the size of the revision column
+	maxRevisionLength = 254
+)
+
+// GitOpsApplicationsDBSyncer implements argo cd applications db sync business logic.
+type GitOpsApplicationsDBSyncer struct {
+	log              logr.Logger
+	createBundleFunc CreateBundleFunction
+}
+
+// NewGitOpsApplicationsDBSyncer creates a new instance of GitOpsApplicationsDBSyncer.
+func NewGitOpsApplicationsDBSyncer(log logr.Logger) Syncer {
+	return &GitOpsApplicationsDBSyncer{
+		log:              log,
+		createBundleFunc: gitops.NewManagerApplicationBundle,
+	}
+}
+
+// RegisterCreateBundleFunctions registers create bundle functions within the transport instance.
+func (syncer *GitOpsApplicationsDBSyncer) RegisterCreateBundleFunctions(dispatcher BundleRegisterable) {
+	dispatcher.BundleRegister(&registration.BundleRegistration{
+		MsgID:            constants.GitOpsApplicationsMsgKey,
+		CreateBundleFunc: syncer.createBundleFunc,
+		Predicate:        func() bool { return true }, // the agents send the bundles only if it's enabled
+	})
+}
+
+// RegisterBundleHandlerFunctions registers bundle handler functions within the conflation manager.
+// the leaf hub sends all the living applications, so the applications in the db which cannot be found in the bundle
+// are deleted. the health transitions of the applications are appended to the history table.
+func (syncer *GitOpsApplicationsDBSyncer) RegisterBundleHandlerFunctions(
+	conflationManager *conflator.ConflationManager,
+) {
+	conflationManager.Register(conflator.NewConflationRegistration(
+		conflator.GitOpsApplicationsPriority,
+		metadata.CompleteStateMode,
+		bundle.GetBundleType(syncer.createBundleFunc()),
+		func(ctx context.Context, bundle bundle.ManagerBundle) error {
+			return syncer.handleApplicationsBundle(ctx, bundle)
+		},
+	))
+}
+
+func (syncer *GitOpsApplicationsDBSyncer) handleApplicationsBundle(ctx context.Context,
+	bundle bundle.ManagerBundle,
+) error {
+	logBundleHandlingMessage(syncer.log, bundle, startBundleHandlingMessage)
+	leafHubName := bundle.GetLeafHubName()
+
+	db := database.GetGorm()
+	applicationsFromDB, err := getApplicationsFromDB(db, leafHubName)
+	if err != nil {
+		return fmt.Errorf("failed fetching leaf hub argo cd applications from db - %w", err)
+	}
+
+	batchUpsertApplications := []models.GitOpsApplication{}
+	healthTransitions := []models.GitOpsApplicationHealth{}
+	for _, object := range bundle.GetObjects() {
+		application, ok := object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s", application.GetNamespace(), application.GetName())
+		applicationFromDB, exist := applicationsFromDB[key]
+		// remove the handled object from the map
+		delete(applicationsFromDB, key)
+		if exist && application.GetResourceVersion() == applicationFromDB.ResourceVersion {
+			continue // update application in db only if what we got is a different (newer) version of the resource
+		}
+
+		applicationModel, err := convertApplicationToModel(leafHubName, application)
+		if err != nil {
+			return err
+		}
+		batchUpsertApplications = append(batchUpsertApplications, *applicationModel)
+
+		if exist && applicationFromDB.Health == applicationModel.Health {
+			continue
+		}
+		healthTransitions = append(healthTransitions, models.GitOpsApplicationHealth{
+			LeafHubName:    leafHubName,
+			Namespace:      applicationModel.Namespace,
+			Name:           applicationModel.Name,
+			PreviousHealth: applicationFromDB.Health,
+			Health:         applicationModel.Health,
+			SyncStatus:     applicationModel.SyncStatus,
+			Revision:       applicationModel.Revision,
+		})
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if len(batchUpsertApplications) > 0 {
+			e := tx.Clauses(clause.OnConflict{
+				UpdateAll: true,
+			}).CreateInBatches(batchUpsertApplications, 100).Error
+			if e != nil {
+				return e
+			}
+		}
+		if len(healthTransitions) > 0 {
+			if e := tx.CreateInBatches(healthTransitions, 100).Error; e != nil {
+				return e
+			}
+		}
+		// delete objects that in the db but were not sent in the bundle (leaf hub sends only living resources).
+		for _, application := range applicationsFromDB {
+			e := tx.Where(&models.GitOpsApplication{
+				LeafHubName: leafHubName,
+				Namespace:   application.Namespace,
+				Name:        application.Name,
+			}).Delete(&models.GitOpsApplication{}).Error
+			if e != nil {
+				return e
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed syncing argo cd applications - %w", err)
+	}
+
+	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
+	return nil
+}
+
+// convertApplicationToModel flattens the status summary of the application into a row of the gitops_applications
+// table, the application is kept in the payload as well.
+func convertApplicationToModel(leafHubName string, application *unstructured.Unstructured,
+) (*models.GitOpsApplication, error) {
+	payload, err := json.Marshal(application)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GitOpsApplication{
+		LeafHubName:          leafHubName,
+		Namespace:            application.GetNamespace(),
+		Name:                 application.GetName(),
+		Project:              nestedString(application, "spec", "project"),
+		Health:               nestedStatus(application, "status", "health", "status"),
+		SyncStatus:           nestedStatus(application, "status", "sync", "status"),
+		Revision:             applicationRevision(application),
+		DestinationServer:    nestedString(application, "spec", "destination", "server"),
+		DestinationName:      nestedString(application, "spec", "destination", "name"),
+		DestinationNamespace: nestedString(application, "spec", "destination", "namespace"),
+		Payload:              payload,
+		ResourceVersion:      application.GetResourceVersion(),
+	}, nil
+}
+
+// applicationRevision returns the synced revision of the application, the revisions of the multi-source application
+// are joined in the order of its sources.
+func applicationRevision(application *unstructured.Unstructured) string {
+	revision := nestedString(application, "status", "sync", "revision")
+	if revision == "" {
+		revisions, _, _ := unstructured.NestedStringSlice(application.Object, "status", "sync", "revisions")
+		revision = strings.Join(revisions, ",")
+	}
+	if len(revision) > maxRevisionLength {
+		revision = revision[:maxRevisionLength]
+	}
+	return revision
+}
+
+func nestedString(application *unstructured.Unstructured, fields ...string) string {
+	value, _, _ := unstructured.NestedString(application.Object, fields...)
+	return value
+}
+
+func nestedStatus(application *unstructured.Unstructured, fields ...string) string {
+	if value := nestedString(application, fields...); value != "" {
+		return value
+	}
+	return unknownApplicationStatus
+}
+
+func getApplicationsFromDB(db *gorm.DB, leafHubName string) (map[string]models.GitOpsApplication, error) {
+	var applications []models.GitOpsApplication
+	err := db.Select("namespace", "name", "health", "resource_version").
+		Where(&models.GitOpsApplication{LeafHubName: leafHubName}).Find(&applications).Error
+	if err != nil {
+		return nil, err
+	}
+	keyToApplicationMap := make(map[string]models.GitOpsApplication, len(applications))
+	for _, application := range applications {
+		keyToApplicationMap[fmt.Sprintf("%s/%s", application.Namespace, application.Name)] = application
+	}
+	return keyToApplicationMap, nil
+}
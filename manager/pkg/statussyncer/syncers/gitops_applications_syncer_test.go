@@ -0,0 +1,104 @@
+package dbsyncer_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/gitops"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+var _ = Describe("GitOpsApplicationsDbSyncer", Ordered, func() {
+	const (
+		leafHubName = "hub1"
+		messageKey  = constants.GitOpsApplicationsMsgKey
+	)
+
+	var statusBundle *GenericStatusBundle
+	var application *unstructured.Unstructured
+
+	BeforeAll(func() {
+		statusBundle = &GenericStatusBundle{
+			Objects:       make([]Object, 0),
+			LeafHubName:   leafHubName,
+			BundleVersion: metadata.NewBundleVersion(),
+			lock:          sync.Mutex{},
+		}
+		application = gitops.NewApplication()
+		application.SetName("guestbook")
+		application.SetNamespace("openshift-gitops")
+		application.SetUID("2b5e1a3c-0a4f-4e55-9d0c-0c5b1c7e2f11")
+		Expect(unstructured.SetNestedField(application.Object, "default", "spec", "project")).To(Succeed())
+		Expect(unstructured.SetNestedField(application.Object, "https://kubernetes.default.svc",
+			"spec", "destination", "server")).To(Succeed())
+		Expect(unstructured.SetNestedField(application.Object, "OutOfSync", "status", "sync", "status")).To(Succeed())
+	})
+
+	sendApplication := func(resourceVersion, health string) {
+		application.SetResourceVersion(resourceVersion)
+		Expect(unstructured.SetNestedField(application.Object, health, "status", "health", "status")).To(Succeed())
+		statusBundle.Objects = []Object{application}
+		statusBundle.BundleVersion.Incr()
+		payloadBytes, err := json.Marshal(statusBundle)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		err = producer.Send(ctx, &transport.Message{
+			Key:     fmt.Sprintf("%s.%s", leafHubName, messageKey),
+			MsgType: constants.StatusBundle,
+			Payload: payloadBytes,
+		})
+		Expect(err).Should(Succeed())
+	}
+
+	expectHealth := func(health string, transitions int) {
+		Eventually(func() error {
+			applications := []models.GitOpsApplication{}
+			if err := database.GetGorm().Where("leaf_hub_name = ?", leafHubName).Find(&applications).Error; err != nil {
+				return err
+			}
+			if len(applications) != 1 {
+				return fmt.Errorf("expect 1 application, but got %d", len(applications))
+			}
+			if applications[0].Health != health || applications[0].SyncStatus != "OutOfSync" ||
+				applications[0].Project != "default" ||
+				applications[0].DestinationServer != "https://kubernetes.default.svc" {
+				return fmt.Errorf("unexpected application: %+v", applications[0])
+			}
+
+			var count int64
+			err := database.GetGorm().Model(&models.GitOpsApplicationHealth{}).
+				Where("leaf_hub_name = ? AND name = ?", leafHubName, "guestbook").Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count != int64(transitions) {
+				return fmt.Errorf("expect %d health transitions, but got %d", transitions, count)
+			}
+			return nil
+		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	}
+
+	It("sync the application bundle", func() {
+		sendApplication("1", "Healthy")
+		expectHealth("Healthy", 1)
+	})
+
+	It("record the health transition of the application", func() {
+		sendApplication("2", "Degraded")
+		expectHealth("Degraded", 2)
+
+		// the sync status is changed without changing the health
+		sendApplication("3", "Degraded")
+		expectHealth("Degraded", 2)
+	})
+})
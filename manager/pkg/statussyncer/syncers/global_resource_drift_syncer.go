@@ -0,0 +1,130 @@
+package dbsyncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/drift"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/registration"
+)
+
+// globalResourceDriftSyncer records the drifts of the global resources reported by the managed hubs.
+type globalResourceDriftSyncer struct {
+	log             logr.Logger
+	driftBundleFunc CreateBundleFunction
+}
+
+func NewGlobalResourceDriftSyncer(log logr.Logger) Syncer {
+	return &globalResourceDriftSyncer{
+		log:             log,
+		driftBundleFunc: drift.NewManagerDriftBundle,
+	}
+}
+
+// RegisterCreateBundleFunctions registers create bundle functions within the transport instance.
+func (syncer *globalResourceDriftSyncer) RegisterCreateBundleFunctions(transportDispatcher BundleRegisterable) {
+	transportDispatcher.BundleRegister(&registration.BundleRegistration{
+		MsgID:            constants.GlobalResourceDriftMsgKey,
+		CreateBundleFunc: syncer.driftBundleFunc,
+		Predicate:        func() bool { return true }, // always get drift bundles
+	})
+}
+
+// RegisterBundleHandlerFunctions registers bundle handler functions within the conflation manager.
+// the bundle holds the drifts detected in the last round on the managed hub, the drifts which are recorded but
+// not in the bundle any more are resolved.
+func (syncer *globalResourceDriftSyncer) RegisterBundleHandlerFunctions(
+	conflationManager *conflator.ConflationManager,
+) {
+	conflationManager.Register(conflator.NewConflationRegistration(
+		conflator.GlobalResourceDriftPriority,
+		metadata.CompleteStateMode,
+		bundle.GetBundleType(syncer.driftBundleFunc()),
+		func(ctx context.Context, bundle bundle.ManagerBundle) error {
+			return syncer.handleDriftBundle(ctx, bundle)
+		},
+	))
+}
+
+func (syncer *globalResourceDriftSyncer) handleDriftBundle(ctx context.Context, bundle bundle.ManagerBundle) error {
+	logBundleHandlingMessage(syncer.log, bundle, startBundleHandlingMessage)
+	leafHubName := bundle.GetLeafHubName()
+
+	drifts := []models.GlobalResourceDrift{}
+	reported := map[string]bool{}
+	for _, object := range bundle.GetObjects() {
+		objectDrift, ok := object.(*drift.ObjectDrift)
+		if !ok {
+			continue
+		}
+		fieldPaths, err := json.Marshal(objectDrift.FieldPaths)
+		if err != nil {
+			return err
+		}
+		record := models.GlobalResourceDrift{
+			LeafHubName: leafHubName,
+			APIVersion:  objectDrift.APIVersion,
+			Kind:        objectDrift.Kind,
+			Namespace:   objectDrift.Namespace,
+			Name:        objectDrift.Name,
+			FieldPaths:  fieldPaths,
+			Deleted:     objectDrift.Deleted,
+			Action:      objectDrift.Action,
+			DetectedAt:  objectDrift.DetectedAt,
+		}
+		// the reverted drift is resolved once it's detected
+		if objectDrift.Action == drift.DriftReverted {
+			record.ResolvedAt = &record.DetectedAt
+		}
+		drifts = append(drifts, record)
+		reported[driftKey(&record)] = true
+	}
+
+	db := database.GetGorm()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if len(drifts) > 0 {
+			// the drift is reported repeatedly until it's resolved, only record it once
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(drifts, 100).Error; err != nil {
+				return err
+			}
+		}
+
+		unresolved := []models.GlobalResourceDrift{}
+		if err := tx.Where("leaf_hub_name = ? AND resolved_at IS NULL", leafHubName).
+			Find(&unresolved).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for i := range unresolved {
+			if reported[driftKey(&unresolved[i])] {
+				continue
+			}
+			if err := tx.Model(&unresolved[i]).Update("resolved_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record the drifts of the hub %s: %w", leafHubName, err)
+	}
+
+	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
+	return nil
+}
+
+func driftKey(drift *models.GlobalResourceDrift) string {
+	return fmt.Sprintf("%s/%s/%s/%d", drift.Kind, drift.Namespace, drift.Name, drift.DetectedAt.Unix())
+}
@@ -0,0 +1,96 @@
+package dbsyncer_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/drift"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+var _ = Describe("GlobalResourceDriftSyncer", Ordered, func() {
+	const (
+		leafHubName = "hub1"
+		messageKey  = constants.GlobalResourceDriftMsgKey
+	)
+
+	var driftBundle *drift.DriftBundle
+
+	BeforeAll(func() {
+		driftBundle = drift.NewAgentDriftBundle(leafHubName)
+	})
+
+	sendBundle := func() {
+		driftBundle.GetVersion().Incr()
+		payloadBytes, err := json.Marshal(driftBundle)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		err = producer.Send(ctx, &transport.Message{
+			Key:     fmt.Sprintf("%s.%s", leafHubName, messageKey),
+			MsgType: constants.StatusBundle,
+			Payload: payloadBytes,
+		})
+		Expect(err).Should(Succeed())
+	}
+
+	It("record the drift of the global resource", func() {
+		driftBundle.Objects = []*drift.ObjectDrift{
+			{
+				APIVersion: "policy.open-cluster-management.io/v1",
+				Kind:       "Policy",
+				Namespace:  "default",
+				Name:       "policy1",
+				FieldPaths: []string{"spec.remediationAction"},
+				Action:     drift.DriftReported,
+				DetectedAt: time.Now().UTC().Truncate(time.Second),
+			},
+		}
+		sendBundle()
+
+		Eventually(func() error {
+			drifts := []models.GlobalResourceDrift{}
+			if err := database.GetGorm().Where("leaf_hub_name = ? AND name = ?", leafHubName, "policy1").
+				Find(&drifts).Error; err != nil {
+				return err
+			}
+			if len(drifts) != 1 {
+				return fmt.Errorf("expect 1 drift, but got %d", len(drifts))
+			}
+			fieldPaths := []string{}
+			if err := json.Unmarshal(drifts[0].FieldPaths, &fieldPaths); err != nil {
+				return err
+			}
+			if len(fieldPaths) != 1 || fieldPaths[0] != "spec.remediationAction" {
+				return fmt.Errorf("unexpected field paths %v", fieldPaths)
+			}
+			if drifts[0].ResolvedAt != nil {
+				return fmt.Errorf("the drift shouldn't be resolved")
+			}
+			return nil
+		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	})
+
+	It("resolve the drift of the global resource", func() {
+		driftBundle.Objects = []*drift.ObjectDrift{}
+		sendBundle()
+
+		Eventually(func() error {
+			drifts := []models.GlobalResourceDrift{}
+			if err := database.GetGorm().Where("leaf_hub_name = ? AND name = ?", leafHubName, "policy1").
+				Find(&drifts).Error; err != nil {
+				return err
+			}
+			if len(drifts) != 1 || drifts[0].ResolvedAt == nil {
+				return fmt.Errorf("the drift should be resolved: %v", drifts)
+			}
+			return nil
+		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	})
+})
@@ -2,6 +2,7 @@ package dbsyncer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -62,6 +63,15 @@ func handleHeartbeatBundle(ctx context.Context, bundle bundle.ManagerBundle) err
 		LastUpdateAt: time.Now(),
 	}
 
+	// the agent health is only reported by the agent which supports it
+	if heartbeatBundle, ok := bundle.(*cluster.HubClusterHeartbeatBundle); ok && heartbeatBundle.Health != nil {
+		health, err := json.Marshal(heartbeatBundle.Health)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the agent health %v", err)
+		}
+		heartbeat.Health = health
+	}
+
 	err := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&heartbeat).Error
 	if err != nil {
 		return fmt.Errorf("failed to update heartbeat %v", err)
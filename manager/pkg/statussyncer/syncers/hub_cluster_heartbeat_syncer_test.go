@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/cluster"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
@@ -62,4 +63,47 @@ var _ = Describe("HubClusterHeartbeatSyncer", Ordered, func() {
 			return fmt.Errorf("not found heartbeat record on the table")
 		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
 	})
+
+	It("sync the hubClusterHeartbeat bundle with the agent health", func() {
+		By("Create hubClusterHeartbeat bundle with the health")
+		statusBundle := cluster.NewAgentHubClusterHeartbeatBundle(leafHubName)
+		statusBundle.Health = &base.AgentHealth{
+			AgentVersion:  "v1.2.0",
+			StatusSyncers: []string{"hub1.ManagedClusters"},
+			Bundles: map[string]*base.BundleSendStatus{
+				"hub1.ManagedClusters": {ErrorCount: 3, ConsecutiveErrors: 3, LastError: "broker not available"},
+			},
+		}
+		statusBundle.GetVersion().Incr()
+
+		payloadBytes, err := json.Marshal(statusBundle)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		err = producer.Send(ctx, &transport.Message{
+			Key:     fmt.Sprintf("%s.%s", leafHubName, messageKey),
+			MsgType: constants.StatusBundle,
+			Payload: payloadBytes,
+		})
+		Expect(err).Should(Succeed())
+
+		By("Check the health of the leaf hub")
+		Eventually(func() error {
+			heartbeat := models.LeafHubHeartbeat{}
+			err := database.GetGorm().Where("leaf_hub_name = ?", leafHubName).First(&heartbeat).Error
+			if err != nil {
+				return err
+			}
+			health := &base.AgentHealth{}
+			if len(heartbeat.Health) == 0 {
+				return fmt.Errorf("the health of the hub %s isn't reported", leafHubName)
+			}
+			if err := json.Unmarshal(heartbeat.Health, health); err != nil {
+				return err
+			}
+			if health.AgentVersion != "v1.2.0" || len(health.DegradedReasons()) != 1 {
+				return fmt.Errorf("unexpected health of the hub %s: %s", leafHubName, string(heartbeat.Health))
+			}
+			return nil
+		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	})
 })
@@ -24,6 +24,7 @@ func (syncer *CompliancesDBSyncer) handleLocalComplianceBundle(ctx context.Conte
 	if err != nil {
 		return err
 	}
+	transitions := newComplianceTransitions(leafHubName)
 
 	for _, object := range bundle.GetObjects() { // every object is clusters list per policy with full state
 		clustersPerPolicyFromBundle, ok := object.(*base.GenericCompliance)
@@ -42,20 +43,20 @@ func (syncer *CompliancesDBSyncer) handleLocalComplianceBundle(ctx context.Conte
 		allClustersOnDB, batchUpsertLocalCompliances = addClustersForLocalPolicies(leafHubName,
 			clustersPerPolicyFromBundle.PolicyID, clustersPerPolicyFromBundle.CompliantClusters,
 			allClustersOnDB, database.Compliant, policyClusterSetFromDB.GetClusters(database.Compliant),
-			batchUpsertLocalCompliances)
+			batchUpsertLocalCompliances, transitions)
 
 		// handle non compliant clusters of the policy
 		allClustersOnDB, batchUpsertLocalCompliances = addClustersForLocalPolicies(leafHubName,
 			clustersPerPolicyFromBundle.PolicyID, clustersPerPolicyFromBundle.NonCompliantClusters,
 			allClustersOnDB, database.NonCompliant,
 			policyClusterSetFromDB.GetClusters(database.NonCompliant),
-			batchUpsertLocalCompliances)
+			batchUpsertLocalCompliances, transitions)
 
 		// handle unknown compliance clusters of the policy
 		allClustersOnDB, batchUpsertLocalCompliances = addClustersForLocalPolicies(leafHubName,
 			clustersPerPolicyFromBundle.PolicyID, clustersPerPolicyFromBundle.UnknownComplianceClusters,
 			allClustersOnDB, database.Unknown, policyClusterSetFromDB.GetClusters(database.Unknown),
-			batchUpsertLocalCompliances)
+			batchUpsertLocalCompliances, transitions)
 
 		// batch upsert
 		err = db.Clauses(clause.OnConflict{
@@ -105,13 +106,14 @@ func (syncer *CompliancesDBSyncer) handleLocalComplianceBundle(ctx context.Conte
 	if err != nil {
 		return fmt.Errorf("failed to handle clusters per policy bundle - %w", err)
 	}
+	transitions.emit()
 	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
 	return nil
 }
 
 func addClustersForLocalPolicies(leafHub, policyID string, bundleClusters []string,
 	allClusterFromDB set.Set, complianceStatus database.ComplianceStatus, typedClusters set.Set,
-	allCompliances []models.LocalStatusCompliance,
+	allCompliances []models.LocalStatusCompliance, transitions *complianceTransitions,
 ) (set.Set, []models.LocalStatusCompliance) {
 	for _, clusterName := range bundleClusters {
 		if !allClusterFromDB.Contains(clusterName) {
@@ -132,6 +134,7 @@ func addClustersForLocalPolicies(leafHub, policyID string, bundleClusters []stri
 				Error:       database.ErrorNone,
 				Compliance:  complianceStatus,
 			})
+			transitions.add(policyID, clusterName, complianceStatus)
 		}
 		// either way if status was updated or not, remove from allClustersFromDB to mark this cluster as handled
 		allClusterFromDB.Remove(clusterName)
@@ -153,6 +156,7 @@ func (syncer *CompliancesDBSyncer) handleLocalCompleteComplianceBundle(ctx conte
 	if err != nil {
 		return err
 	}
+	transitions := newComplianceTransitions(leafHubName)
 
 	for _, object := range bundle.GetObjects() { // every object in bundle is policy compliance status
 		policyComplianceStatus, ok := object.(*base.GenericCompleteCompliance)
@@ -221,6 +225,9 @@ func (syncer *CompliancesDBSyncer) handleLocalCompleteComplianceBundle(ctx conte
 		if err != nil {
 			return fmt.Errorf("failed upating compliances from local complainces - %w", err)
 		}
+		for _, compliance := range batchUpdateCompliances {
+			transitions.add(compliance.PolicyID, compliance.ClusterName, compliance.Compliance)
+		}
 
 		// for policies that are found in the db but not in the bundle - all clusters are Compliant (implicitly)
 		delete(allPolicyComplianceRowsFromDB, policyComplianceStatus.PolicyID)
@@ -228,18 +235,24 @@ func (syncer *CompliancesDBSyncer) handleLocalCompleteComplianceBundle(ctx conte
 
 	// update policies not in the bundle - all is Compliant
 	err = db.Transaction(func(tx *gorm.DB) error {
-		for policyID := range allPolicyComplianceRowsFromDB {
+		for policyID, nonComplianceClusterSets := range allPolicyComplianceRowsFromDB {
 			err := tx.Model(&models.LocalStatusCompliance{}).Where("policy_id = ? AND leaf_hub_name = ?",
 				policyID, leafHubName).Updates(&models.LocalStatusCompliance{Compliance: database.Compliant}).Error
 			if err != nil {
 				return err
 			}
+			for _, name := range nonComplianceClusterSets.GetAllClusters().ToSlice() {
+				if clusterName, ok := name.(string); ok {
+					transitions.add(policyID, clusterName, database.Compliant)
+				}
+			}
 		}
 		return nil
 	})
 	if err != nil {
 		return fmt.Errorf("failed deleting compliances from local complainces - %w", err)
 	}
+	transitions.emit()
 
 	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
 	return nil
@@ -17,6 +17,7 @@ import (
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport/registration"
 )
 
@@ -96,6 +97,9 @@ func (syncer *localSpecPoliciesSyncer) handleLocalObjectsBundle(ctx context.Cont
 		if err != nil {
 			return err
 		}
+		if payload, err = sensitive.Encrypt(models.LocalSpecPolicy{}.TableName(), payload); err != nil {
+			return err
+		}
 		// if the row doesn't exist in db then add it.
 		if !objInDB {
 			batchUpsertLocalPolicies = append(batchUpsertLocalPolicies, models.LocalSpecPolicy{
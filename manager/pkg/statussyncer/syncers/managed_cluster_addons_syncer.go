@@ -0,0 +1,190 @@
+package dbsyncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/cluster"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/registration"
+)
+
+// ManagedClusterAddOnsDBSyncer implements managed cluster addons db sync business logic.
+type ManagedClusterAddOnsDBSyncer struct {
+	log              logr.Logger
+	createBundleFunc CreateBundleFunction
+}
+
+// NewManagedClusterAddOnsDBSyncer creates a new instance of ManagedClusterAddOnsDBSyncer.
+func NewManagedClusterAddOnsDBSyncer(log logr.Logger) Syncer {
+	return &ManagedClusterAddOnsDBSyncer{
+		log:              log,
+		createBundleFunc: cluster.NewManagerManagedClusterAddOnBundle,
+	}
+}
+
+// RegisterCreateBundleFunctions registers create bundle functions within the transport instance.
+func (syncer *ManagedClusterAddOnsDBSyncer) RegisterCreateBundleFunctions(dispatcher BundleRegisterable) {
+	dispatcher.BundleRegister(&registration.BundleRegistration{
+		MsgID:            constants.ManagedClusterAddOnsMsgKey,
+		CreateBundleFunc: syncer.createBundleFunc,
+		Predicate:        func() bool { return true }, // always get managed cluster addons bundles
+	})
+}
+
+// RegisterBundleHandlerFunctions registers bundle handler functions within the conflation manager.
+// the leaf hub sends all the living addons, so the addons in the db which cannot be found in the bundle are deleted,
+// and the others are updated only when the resourceVersion is changed.
+func (syncer *ManagedClusterAddOnsDBSyncer) RegisterBundleHandlerFunctions(
+	conflationManager *conflator.ConflationManager,
+) {
+	conflationManager.Register(conflator.NewConflationRegistration(
+		conflator.ManagedClusterAddOnsPriority,
+		metadata.CompleteStateMode,
+		bundle.GetBundleType(syncer.createBundleFunc()),
+		func(ctx context.Context, bundle bundle.ManagerBundle) error {
+			return syncer.handleManagedClusterAddOnsBundle(ctx, bundle)
+		},
+	))
+}
+
+func (syncer *ManagedClusterAddOnsDBSyncer) handleManagedClusterAddOnsBundle(ctx context.Context,
+	bundle bundle.ManagerBundle,
+) error {
+	logBundleHandlingMessage(syncer.log, bundle, startBundleHandlingMessage)
+	leafHubName := bundle.GetLeafHubName()
+
+	db := database.GetGorm()
+	addOnIdentitiesFromDB, err := getAddOnIdentities(db, leafHubName)
+	if err != nil {
+		return fmt.Errorf("failed fetching leaf hub managed cluster addons from db - %w", err)
+	}
+
+	batchUpsertAddOns := []models.ManagedClusterAddOn{}
+	for _, object := range bundle.GetObjects() {
+		addOn, ok := object.(*addonv1alpha1.ManagedClusterAddOn)
+		if !ok {
+			continue
+		}
+
+		key := addOnKey(addOn.Namespace, addOn.Name)
+		addOnFromDB, exist := addOnIdentitiesFromDB[key]
+		// remove the handled object from the map
+		delete(addOnIdentitiesFromDB, key)
+		if exist && addOn.GetResourceVersion() == addOnFromDB.version {
+			continue // update addon in db only if what we got is a different (newer) version of the resource
+		}
+
+		addOnModel, err := convertAddOnToModel(leafHubName, addOn)
+		if err != nil {
+			return err
+		}
+		batchUpsertAddOns = append(batchUpsertAddOns, *addOnModel)
+	}
+
+	if len(batchUpsertAddOns) > 0 {
+		err = db.Clauses(clause.OnConflict{
+			UpdateAll: true,
+		}).CreateInBatches(batchUpsertAddOns, 100).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	// delete objects that in the db but were not sent in the bundle (leaf hub sends only living resources).
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, identity := range addOnIdentitiesFromDB {
+			e := tx.Where(&models.ManagedClusterAddOn{
+				LeafHubName: leafHubName,
+				ClusterName: identity.clusterName,
+				AddOnName:   identity.addOnName,
+			}).Delete(&models.ManagedClusterAddOn{}).Error
+			if e != nil {
+				return e
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting managed cluster addons - %w", err)
+	}
+
+	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
+	return nil
+}
+
+// convertAddOnToModel flattens the addon into a row of the managed_cluster_addons table. The addon is in the
+// namespace of the cluster it's installed on.
+func convertAddOnToModel(leafHubName string, addOn *addonv1alpha1.ManagedClusterAddOn,
+) (*models.ManagedClusterAddOn, error) {
+	conditions, err := json.Marshal(addOn.Status.Conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	installNamespace := addOn.Status.Namespace
+	if installNamespace == "" {
+		installNamespace = addOn.Spec.InstallNamespace
+	}
+
+	return &models.ManagedClusterAddOn{
+		LeafHubName:      leafHubName,
+		ClusterName:      addOn.Namespace,
+		AddOnName:        addOn.Name,
+		InstallNamespace: installNamespace,
+		HubVersion:       addOn.GetAnnotations()[constants.ManagedHubVersionAnnotation],
+		Available:        addOnConditionStatus(addOn, addonv1alpha1.ManagedClusterAddOnConditionAvailable),
+		Degraded:         addOnConditionStatus(addOn, addonv1alpha1.ManagedClusterAddOnConditionDegraded),
+		Conditions:       conditions,
+		ResourceVersion:  addOn.GetResourceVersion(),
+	}, nil
+}
+
+func addOnConditionStatus(addOn *addonv1alpha1.ManagedClusterAddOn, conditionType string) string {
+	condition := meta.FindStatusCondition(addOn.Status.Conditions, conditionType)
+	if condition == nil {
+		return string(metav1.ConditionUnknown)
+	}
+	return string(condition.Status)
+}
+
+type addOnIdentity struct {
+	clusterName string
+	addOnName   string
+	version     string
+}
+
+func addOnKey(clusterName, addOnName string) string {
+	return fmt.Sprintf("%s/%s", clusterName, addOnName)
+}
+
+func getAddOnIdentities(db *gorm.DB, leafHubName string) (map[string]addOnIdentity, error) {
+	var addOns []models.ManagedClusterAddOn
+	err := db.Select("cluster_name", "addon_name", "resource_version").
+		Where(&models.ManagedClusterAddOn{LeafHubName: leafHubName}).Find(&addOns).Error
+	if err != nil {
+		return nil, err
+	}
+	keyToIdentityMap := make(map[string]addOnIdentity, len(addOns))
+	for _, addOn := range addOns {
+		keyToIdentityMap[addOnKey(addOn.ClusterName, addOn.AddOnName)] = addOnIdentity{
+			clusterName: addOn.ClusterName,
+			addOnName:   addOn.AddOnName,
+			version:     addOn.ResourceVersion,
+		}
+	}
+	return keyToIdentityMap, nil
+}
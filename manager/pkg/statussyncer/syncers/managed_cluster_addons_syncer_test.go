@@ -0,0 +1,114 @@
+package dbsyncer_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+var _ = Describe("ManagedClusterAddOnsDbSyncer", Ordered, func() {
+	const (
+		leafHubName = "hub1"
+		messageKey  = constants.ManagedClusterAddOnsMsgKey
+	)
+
+	var statusBundle *GenericStatusBundle
+
+	BeforeAll(func() {
+		statusBundle = &GenericStatusBundle{
+			Objects:       make([]Object, 0),
+			LeafHubName:   leafHubName,
+			BundleVersion: metadata.NewBundleVersion(),
+			lock:          sync.Mutex{},
+		}
+	})
+
+	sendBundle := func() {
+		statusBundle.BundleVersion.Incr()
+		payloadBytes, err := json.Marshal(statusBundle)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		err = producer.Send(ctx, &transport.Message{
+			Key:     fmt.Sprintf("%s.%s", leafHubName, messageKey),
+			MsgType: constants.StatusBundle,
+			Payload: payloadBytes,
+		})
+		Expect(err).Should(Succeed())
+	}
+
+	It("sync the ManagedClusterAddOn bundle", func() {
+		By("Create ManagedClusterAddOn bundle")
+		addOn := &addonv1alpha1.ManagedClusterAddOn{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "application-manager",
+				Namespace:       "cluster1",
+				ResourceVersion: "1",
+				Annotations: map[string]string{
+					constants.ManagedHubVersionAnnotation: "2.9.0",
+				},
+			},
+			Spec: addonv1alpha1.ManagedClusterAddOnSpec{
+				InstallNamespace: "open-cluster-management-agent-addon",
+			},
+			Status: addonv1alpha1.ManagedClusterAddOnStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               addonv1alpha1.ManagedClusterAddOnConditionAvailable,
+						Status:             metav1.ConditionTrue,
+						Reason:             "ManagedClusterAddOnLeaseUpdated",
+						LastTransitionTime: metav1.Now(),
+					},
+				},
+			},
+		}
+		statusBundle.Objects = append(statusBundle.Objects, addOn)
+		sendBundle()
+
+		By("Check the managed cluster addon table")
+		Eventually(func() error {
+			addOns := []models.ManagedClusterAddOn{}
+			if err := database.GetGorm().Where("leaf_hub_name = ?", leafHubName).Find(&addOns).Error; err != nil {
+				return err
+			}
+			if len(addOns) != 1 {
+				return fmt.Errorf("expect 1 addon, but got %d", len(addOns))
+			}
+			if addOns[0].ClusterName != "cluster1" || addOns[0].AddOnName != "application-manager" ||
+				addOns[0].InstallNamespace != "open-cluster-management-agent-addon" ||
+				addOns[0].HubVersion != "2.9.0" || addOns[0].Available != "True" || addOns[0].Degraded != "Unknown" {
+				return fmt.Errorf("unexpected addon: %+v", addOns[0])
+			}
+			return nil
+		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	})
+
+	It("delete the ManagedClusterAddOn which is not in the bundle", func() {
+		statusBundle.Objects = make([]Object, 0)
+		sendBundle()
+
+		Eventually(func() error {
+			var count int64
+			err := database.GetGorm().Model(&models.ManagedClusterAddOn{}).
+				Where("leaf_hub_name = ?", leafHubName).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count != 0 {
+				return fmt.Errorf("expect the addons are deleted, but got %d", count)
+			}
+			return nil
+		}, 30*time.Second, 2*time.Second).ShouldNot(HaveOccurred())
+	})
+})
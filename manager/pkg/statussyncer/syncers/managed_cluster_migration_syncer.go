@@ -0,0 +1,85 @@
+package dbsyncer
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/migration"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/registration"
+)
+
+// managedClusterMigrationSyncer applies the results of the migration stages run by the managed hubs to the
+// migrations, the stages are requested by the migration controller. The bootstrap kubeconfigs in the results are
+// forwarded to the hubs by the syncer, so they aren't kept in the database.
+type managedClusterMigrationSyncer struct {
+	log                       logr.Logger
+	producer                  transport.Producer
+	migrationStatusBundleFunc CreateBundleFunction
+}
+
+func NewManagedClusterMigrationSyncer(log logr.Logger, producer transport.Producer) Syncer {
+	return &managedClusterMigrationSyncer{
+		log:                       log,
+		producer:                  producer,
+		migrationStatusBundleFunc: spec.NewManagerMigrationStatusBundle,
+	}
+}
+
+// RegisterCreateBundleFunctions registers create bundle functions within the transport instance.
+func (syncer *managedClusterMigrationSyncer) RegisterCreateBundleFunctions(transportDispatcher BundleRegisterable) {
+	transportDispatcher.BundleRegister(&registration.BundleRegistration{
+		MsgID:            constants.ManagedClusterMigrationStatusMsgKey,
+		CreateBundleFunc: syncer.migrationStatusBundleFunc,
+		Predicate:        func() bool { return true }, // always get migration status bundles
+	})
+}
+
+// RegisterBundleHandlerFunctions registers bundle handler functions within the conflation manager.
+// the bundle holds all the recent results of the managed hub, so only the latest one is handled.
+func (syncer *managedClusterMigrationSyncer) RegisterBundleHandlerFunctions(
+	conflationManager *conflator.ConflationManager,
+) {
+	conflationManager.Register(conflator.NewConflationRegistration(
+		conflator.ManagedClusterMigrationPriority,
+		metadata.CompleteStateMode,
+		bundle.GetBundleType(syncer.migrationStatusBundleFunc()),
+		func(ctx context.Context, bundle bundle.ManagerBundle) error {
+			return syncer.handleMigrationStatusBundle(ctx, bundle)
+		},
+	))
+}
+
+func (syncer *managedClusterMigrationSyncer) handleMigrationStatusBundle(ctx context.Context,
+	bundle bundle.ManagerBundle,
+) error {
+	logBundleHandlingMessage(syncer.log, bundle, startBundleHandlingMessage)
+
+	statuses := []*spec.MigrationStatus{}
+	for _, object := range bundle.GetObjects() {
+		if status, ok := object.(*spec.MigrationStatus); ok {
+			statuses = append(statuses, status)
+		}
+	}
+	forwards, err := migration.HandleStatus(database.GetGorm(), bundle.GetLeafHubName(), statuses)
+	for _, request := range forwards {
+		if err := migration.Send(ctx, syncer.producer, request); err != nil {
+			// the migration controller requests the stage again, then the result is reported and forwarded again
+			syncer.log.Error(err, "failed to forward the migration stage", "id", request.Migration.MigrationID,
+				"hub", request.LeafHubName, "stage", request.Migration.Stage)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
+	return nil
+}
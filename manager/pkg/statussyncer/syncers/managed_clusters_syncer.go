@@ -8,8 +8,11 @@ import (
 	"github.com/go-logr/logr"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/notification"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/cluster"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
@@ -17,6 +20,7 @@ import (
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/sensitive"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport/registration"
 )
 
@@ -71,6 +75,11 @@ func (syncer *ManagedClustersDBSyncer) handleManagedClustersBundle(ctx context.C
 	if err != nil {
 		return fmt.Errorf("failed fetching leaf hub managed clusters from db - %w", err)
 	}
+	clusterIdToAvailableMapFromDB, err := getClusterIdToAvailableMap(db, leafHubName)
+	if err != nil {
+		return fmt.Errorf("failed fetching the availability of the managed clusters from db - %w", err)
+	}
+	unavailableEvents := []*notification.Event{}
 
 	// batch upsert managed clusters
 	batchUpsertClusters := []models.ManagedCluster{}
@@ -96,6 +105,9 @@ func (syncer *ManagedClustersDBSyncer) handleManagedClustersBundle(ctx context.C
 		if err != nil {
 			return err
 		}
+		if payload, err = sensitive.Encrypt(models.ManagedCluster{}.TableName(), payload); err != nil {
+			return err
+		}
 
 		clusterVersionFromDB, exist := clusterIdToVersionMapFromDB[clusterId]
 		if !exist {
@@ -115,6 +127,17 @@ func (syncer *ManagedClustersDBSyncer) handleManagedClustersBundle(ctx context.C
 			continue // update cluster in db only if what we got is a different (newer) version of the resource
 		}
 
+		if clusterIdToAvailableMapFromDB[clusterId] == string(metav1.ConditionTrue) &&
+			!meta.IsStatusConditionTrue(cluster.Status.Conditions, clusterv1.ManagedClusterConditionAvailable) {
+			unavailableEvents = append(unavailableEvents, notification.NewEvent(notification.ClusterUnavailable,
+				fmt.Sprintf("the cluster %s of the hub %s becomes unavailable", cluster.GetName(), leafHubName),
+				map[string]string{
+					notification.DataLeafHubName: leafHubName,
+					notification.DataClusterName: cluster.GetName(),
+					notification.DataClusterID:   clusterId,
+				}))
+		}
+
 		batchUpsertClusters = append(batchUpsertClusters, models.ManagedCluster{
 			ClusterID:   clusterId,
 			LeafHubName: leafHubName,
@@ -128,6 +151,9 @@ func (syncer *ManagedClustersDBSyncer) handleManagedClustersBundle(ctx context.C
 	if err != nil {
 		return err
 	}
+	for _, event := range unavailableEvents {
+		notification.Emit(event)
+	}
 
 	// delete objects that in the db but were not sent in the bundle (leaf hub sends only living resources).
 	// https://gorm.io/docs/delete.html#Soft-Delete
@@ -168,3 +194,24 @@ func getClusterIdToVersionMap(db *gorm.DB, leafHubName string) (map[string]strin
 	}
 	return nameToVersionMap, nil
 }
+
+// getClusterIdToAvailableMap returns the status of the available condition of the managed clusters in the db.
+func getClusterIdToAvailableMap(db *gorm.DB, leafHubName string) (map[string]string, error) {
+	var availableStatuses []struct {
+		ClusterID string `gorm:"column:cluster_id"`
+		Status    string `gorm:"column:status"`
+	}
+
+	err := db.Raw(`SELECT cluster_id, condition->>'status' AS status
+		FROM status.managed_clusters, jsonb_array_elements(payload->'status'->'conditions') AS condition
+		WHERE leaf_hub_name = ? AND deleted_at IS NULL AND condition->>'type' = ?`,
+		leafHubName, clusterv1.ManagedClusterConditionAvailable).Scan(&availableStatuses).Error
+	if err != nil {
+		return nil, err
+	}
+	idToAvailableMap := make(map[string]string)
+	for _, available := range availableStatuses {
+		idToAvailableMap[available.ClusterID] = available.Status
+	}
+	return idToAvailableMap, nil
+}
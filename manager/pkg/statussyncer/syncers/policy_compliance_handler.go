@@ -37,6 +37,7 @@ func (syncer *CompliancesDBSyncer) handleComplianceBundle(ctx context.Context,
 	// policyID: { compliance: (cluster1, cluster2), nonCompliance: (cluster3, cluster4), unknowns: (cluster5) }
 	allPolicyClusterSetsFromDB := convertStatusComplianceToClusterSets(compliancesFromDB)
 
+	transitions := newComplianceTransitions(leafHubName)
 	err = db.Transaction(func(tx *gorm.DB) error {
 		for _, object := range bundle.GetObjects() { // every object is clusters list per policy with full state
 			clustersPerPolicyFromBundle, ok := object.(*base.GenericCompliance)
@@ -54,7 +55,7 @@ func (syncer *CompliancesDBSyncer) handleComplianceBundle(ctx context.Context,
 			// handle compliant clusters of the policy
 			allClustersOnDB, err = handleClustersPerPolicyWithTx(tx, leafHubName, clustersPerPolicyFromBundle.PolicyID,
 				clustersPerPolicyFromBundle.CompliantClusters, allClustersOnDB, database.Compliant,
-				policyClusterSetFromDB.GetClusters(database.Compliant))
+				policyClusterSetFromDB.GetClusters(database.Compliant), transitions)
 			if err != nil {
 				return fmt.Errorf(failedBatchFormat, err)
 			}
@@ -62,7 +63,7 @@ func (syncer *CompliancesDBSyncer) handleComplianceBundle(ctx context.Context,
 			// handle non compliant clusters of the policy
 			allClustersOnDB, err = handleClustersPerPolicyWithTx(tx, leafHubName, clustersPerPolicyFromBundle.PolicyID,
 				clustersPerPolicyFromBundle.NonCompliantClusters, allClustersOnDB, database.NonCompliant,
-				policyClusterSetFromDB.GetClusters(database.NonCompliant))
+				policyClusterSetFromDB.GetClusters(database.NonCompliant), transitions)
 			if err != nil {
 				return fmt.Errorf(failedBatchFormat, err)
 			}
@@ -70,7 +71,16 @@ func (syncer *CompliancesDBSyncer) handleComplianceBundle(ctx context.Context,
 			// handle unknown compliance clusters of the policy
 			allClustersOnDB, err = handleClustersPerPolicyWithTx(tx, leafHubName, clustersPerPolicyFromBundle.PolicyID,
 				clustersPerPolicyFromBundle.UnknownComplianceClusters, allClustersOnDB, database.Unknown,
-				policyClusterSetFromDB.GetClusters(database.Unknown))
+				policyClusterSetFromDB.GetClusters(database.Unknown), transitions)
+			if err != nil {
+				return fmt.Errorf(failedBatchFormat, err)
+			}
+
+			// the rollout only counts the compliance reported for the generation it delivers
+			err = tx.Model(&models.StatusCompliance{}).Where(
+				"leaf_hub_name = ? AND policy_id = ? AND policy_generation <> ?", leafHubName,
+				clustersPerPolicyFromBundle.PolicyID, clustersPerPolicyFromBundle.Generation,
+			).Update("policy_generation", clustersPerPolicyFromBundle.Generation).Error
 			if err != nil {
 				return fmt.Errorf(failedBatchFormat, err)
 			}
@@ -109,6 +119,7 @@ func (syncer *CompliancesDBSyncer) handleComplianceBundle(ctx context.Context,
 	if err != nil {
 		return fmt.Errorf("failed to handle clusters per policy bundle - %w", err)
 	}
+	transitions.emit()
 	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
 	return nil
 }
@@ -127,6 +138,7 @@ func convertStatusComplianceToClusterSets(compliancesFromDB []models.StatusCompl
 
 func handleClustersPerPolicyWithTx(tx *gorm.DB, leafHub, policyID string, bundleClusters []string,
 	allClusterFromDB set.Set, complianceStatus database.ComplianceStatus, typedClusters set.Set,
+	transitions *complianceTransitions,
 ) (set.Set, error) {
 	for _, clusterName := range bundleClusters {
 		if !allClusterFromDB.Contains(clusterName) {
@@ -153,6 +165,7 @@ func handleClustersPerPolicyWithTx(tx *gorm.DB, leafHub, policyID string, bundle
 			if err != nil {
 				return nil, err
 			}
+			transitions.add(policyID, clusterName, complianceStatus)
 		}
 		// either way if status was updated or not, remove from allClustersFromDB to mark this cluster as handled
 		allClusterFromDB.Remove(clusterName)
@@ -180,6 +193,7 @@ func (syncer *CompliancesDBSyncer) handleCompleteComplianceBundle(ctx context.Co
 
 	allPolicyComplianceRowsFromDB := convertStatusComplianceToClusterSets(nonCompliancesFromDB)
 
+	transitions := newComplianceTransitions(leafHubName)
 	err = db.Transaction(func(tx *gorm.DB) error {
 		for _, object := range bundle.GetObjects() { // every object in bundle is policy compliance status
 			policyComplianceStatus, ok := object.(*base.GenericCompleteCompliance)
@@ -202,6 +216,7 @@ func (syncer *CompliancesDBSyncer) handleCompleteComplianceBundle(ctx context.Co
 					if err != nil {
 						return err
 					}
+					transitions.add(policyComplianceStatus.PolicyID, clusterName, database.NonCompliant)
 				} // if different need to update, otherwise no need to do anything.
 				allNonComplianceClusters.Remove(clusterName) // mark cluster as handled
 			}
@@ -214,6 +229,7 @@ func (syncer *CompliancesDBSyncer) handleCompleteComplianceBundle(ctx context.Co
 					if err != nil {
 						return err
 					}
+					transitions.add(policyComplianceStatus.PolicyID, clusterName, database.Unknown)
 				} // if different need to update, otherwise no need to do anything.
 				allNonComplianceClusters.Remove(clusterName) // mark cluster as handled
 			}
@@ -228,6 +244,7 @@ func (syncer *CompliancesDBSyncer) handleCompleteComplianceBundle(ctx context.Co
 				if err != nil {
 					return err
 				}
+				transitions.add(policyComplianceStatus.PolicyID, clusterName, database.Compliant)
 			}
 
 			// for policies that are found in the db but not in the bundle - all clusters are Compliant (implicitly)
@@ -235,13 +252,18 @@ func (syncer *CompliancesDBSyncer) handleCompleteComplianceBundle(ctx context.Co
 		}
 
 		// update policies not in the bundle - all is Compliant
-		for policyID := range allPolicyComplianceRowsFromDB {
+		for policyID, nonComplianceClusterSets := range allPolicyComplianceRowsFromDB {
 			ret := tx.Model(&models.StatusCompliance{}).Where(
 				"policy_id = ? AND leaf_hub_name = ?", policyID, leafHubName).
 				Updates(&models.StatusCompliance{Compliance: database.Compliant})
 			if ret.Error != nil {
 				return ret.Error
 			}
+			for _, name := range nonComplianceClusterSets.GetAllClusters().ToSlice() {
+				if clusterName, ok := name.(string); ok {
+					transitions.add(policyID, clusterName, database.Compliant)
+				}
+			}
 		}
 		// return nil will commit the whole transaction
 		return nil
@@ -250,6 +272,7 @@ func (syncer *CompliancesDBSyncer) handleCompleteComplianceBundle(ctx context.Co
 	if err != nil {
 		return fmt.Errorf("failed to handle complete compliance bundle - %w", err)
 	}
+	transitions.emit()
 
 	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
 	return nil
@@ -273,6 +296,7 @@ func (syncer *CompliancesDBSyncer) handleDeltaComplianceBundle(ctx context.Conte
 	leafHubName := bundle.GetLeafHubName()
 	db := database.GetGorm()
 
+	transitions := newComplianceTransitions(leafHubName)
 	err := db.Transaction(func(tx *gorm.DB) error {
 		for _, object := range bundle.GetObjects() { // every object in bundle is policy generic compliance status
 			policyGenericComplianceStatus, ok := object.(*base.GenericCompliance)
@@ -285,6 +309,7 @@ func (syncer *CompliancesDBSyncer) handleDeltaComplianceBundle(ctx context.Conte
 				if err != nil {
 					return err
 				}
+				transitions.add(policyGenericComplianceStatus.PolicyID, cluster, database.Compliant)
 			}
 
 			for _, cluster := range policyGenericComplianceStatus.NonCompliantClusters {
@@ -293,6 +318,7 @@ func (syncer *CompliancesDBSyncer) handleDeltaComplianceBundle(ctx context.Conte
 				if err != nil {
 					return err
 				}
+				transitions.add(policyGenericComplianceStatus.PolicyID, cluster, database.NonCompliant)
 			}
 
 			for _, cluster := range policyGenericComplianceStatus.UnknownComplianceClusters {
@@ -301,6 +327,7 @@ func (syncer *CompliancesDBSyncer) handleDeltaComplianceBundle(ctx context.Conte
 				if err != nil {
 					return err
 				}
+				transitions.add(policyGenericComplianceStatus.PolicyID, cluster, database.Unknown)
 			}
 		}
 
@@ -310,6 +337,7 @@ func (syncer *CompliancesDBSyncer) handleDeltaComplianceBundle(ctx context.Conte
 	if err != nil {
 		return fmt.Errorf("failed to handle delta compliance bundle - %w", err)
 	}
+	transitions.emit()
 
 	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
 
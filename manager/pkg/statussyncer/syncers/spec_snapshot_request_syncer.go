@@ -0,0 +1,80 @@
+package dbsyncer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/specsyncer/db2transport/snapshot"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/spec"
+	"github.com/stolostron/multicluster-global-hub/pkg/conflator"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/registration"
+)
+
+// specSnapshotRequestSyncer hands over the snapshot requests of the spec bundles from the managed hubs to the db to
+// transport syncers, the managed hub requests the snapshot once it misses a delta bundle.
+type specSnapshotRequestSyncer struct {
+	log                       logr.Logger
+	snapshotRequestBundleFunc CreateBundleFunction
+	requests                  snapshot.Requests
+}
+
+func NewSpecSnapshotRequestSyncer(log logr.Logger) Syncer {
+	return &specSnapshotRequestSyncer{
+		log:                       log,
+		snapshotRequestBundleFunc: spec.NewManagerSnapshotRequestBundle,
+		requests:                  snapshot.NewRequests(),
+	}
+}
+
+// RegisterCreateBundleFunctions registers create bundle functions within the transport instance.
+func (syncer *specSnapshotRequestSyncer) RegisterCreateBundleFunctions(transportDispatcher BundleRegisterable) {
+	transportDispatcher.BundleRegister(&registration.BundleRegistration{
+		MsgID:            constants.SpecSnapshotRequestMsgKey,
+		CreateBundleFunc: syncer.snapshotRequestBundleFunc,
+		Predicate:        func() bool { return true }, // always get snapshot request bundles
+	})
+}
+
+// RegisterBundleHandlerFunctions registers bundle handler functions within the conflation manager.
+// the bundle holds all the spec bundles which the managed hub waits for the snapshots, so only the latest one is
+// handled.
+func (syncer *specSnapshotRequestSyncer) RegisterBundleHandlerFunctions(
+	conflationManager *conflator.ConflationManager,
+) {
+	conflationManager.Register(conflator.NewConflationRegistration(
+		conflator.SpecSnapshotRequestPriority,
+		metadata.CompleteStateMode,
+		bundle.GetBundleType(syncer.snapshotRequestBundleFunc()),
+		func(ctx context.Context, bundle bundle.ManagerBundle) error {
+			return syncer.handleSnapshotRequestBundle(ctx, bundle)
+		},
+	))
+}
+
+func (syncer *specSnapshotRequestSyncer) handleSnapshotRequestBundle(ctx context.Context,
+	bundle bundle.ManagerBundle,
+) error {
+	logBundleHandlingMessage(syncer.log, bundle, startBundleHandlingMessage)
+
+	msgKeys := []string{}
+	for _, object := range bundle.GetObjects() {
+		if msgKey, ok := object.(string); ok {
+			msgKeys = append(msgKeys, msgKey)
+		}
+	}
+	if len(msgKeys) > 0 {
+		syncer.log.Info("the managed hub requests the snapshots", "leafHubName", bundle.GetLeafHubName(),
+			"bundles", msgKeys)
+		if err := syncer.requests.Request(ctx, bundle.GetLeafHubName(), msgKeys...); err != nil {
+			return fmt.Errorf("failed to record the snapshot requests of the hub %s: %w", bundle.GetLeafHubName(), err)
+		}
+	}
+
+	logBundleHandlingMessage(syncer.log, bundle, finishBundleHandlingMessage)
+	return nil
+}
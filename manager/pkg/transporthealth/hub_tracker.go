@@ -0,0 +1,67 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package transporthealth
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+var (
+	hubLock sync.RWMutex
+	hubLags = map[string]*transport.HubLag{}
+)
+
+// RecordReceived records the message received from the managed hub, the delay is the duration between the hub sent
+// the message and the manager received it, it grows when the manager falls behind the transport.
+func RecordReceived(hub string, sentTime, receivedTime time.Time) {
+	var delay time.Duration
+	if !sentTime.IsZero() && receivedTime.After(sentTime) {
+		delay = receivedTime.Sub(sentTime)
+	}
+
+	hubLock.Lock()
+	defer hubLock.Unlock()
+	hubLags[hub] = &transport.HubLag{
+		Name:             hub,
+		LastReceivedTime: receivedTime,
+		Delay:            delay,
+	}
+}
+
+// getHubLags returns the last received message of the hubs sorted by the hub name. the heartbeats are the last
+// received time of the hubs stored by all the manager replicas, so the hubs received by the other replicas or not
+// received since the start are included. the staleness is measured against the latest of them.
+func getHubLags(heartbeats map[string]time.Time, now time.Time) []transport.HubLag {
+	hubLock.RLock()
+	merged := make(map[string]transport.HubLag, len(hubLags))
+	for name, hubLag := range hubLags {
+		merged[name] = *hubLag
+	}
+	hubLock.RUnlock()
+
+	for name, lastReceivedTime := range heartbeats {
+		hubLag, ok := merged[name]
+		if !ok {
+			hubLag = transport.HubLag{Name: name}
+		}
+		if lastReceivedTime.After(hubLag.LastReceivedTime) {
+			hubLag.LastReceivedTime = lastReceivedTime
+		}
+		merged[name] = hubLag
+	}
+
+	hubs := make([]transport.HubLag, 0, len(merged))
+	for _, hubLag := range merged {
+		if now.After(hubLag.LastReceivedTime) {
+			hubLag.Staleness = now.Sub(hubLag.LastReceivedTime)
+		}
+		hubs = append(hubs, hubLag)
+	}
+	sort.Slice(hubs, func(i, j int) bool { return hubs[i].Name < hubs[j].Name })
+	return hubs
+}
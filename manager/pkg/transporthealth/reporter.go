@@ -0,0 +1,239 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package transporthealth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/monitoring"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+	"github.com/stolostron/multicluster-global-hub/pkg/database/models"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/consumer"
+)
+
+const (
+	reportInterval  = 30 * time.Second
+	publishInterval = 5 * time.Minute
+	// the transport is degraded when the manager is behind the messages more than the threshold
+	defaultLagThreshold = 1000
+	// the hub is lagging when its message is received later than the threshold, or it doesn't send any message
+	// within the threshold, the heartbeat interval is 1 minute
+	defaultDelayThreshold = 5 * time.Minute
+	// the hub silent longer than the threshold is considered as detached, and it isn't reported anymore
+	defaultForgetThreshold = 24 * time.Hour
+)
+
+// AddTransportHealthReporter adds the reporter which computes the consumer lag of the transport periodically, exports
+// it as metrics, and publishes it to the configmap for the operator.
+func AddTransportHealthReporter(mgr ctrl.Manager, managerConfig *config.ManagerConfig) error {
+	var querier WatermarkQuerier
+	if managerConfig.TransportConfig.TransportType == string(transport.Kafka) {
+		var err error
+		querier, err = NewKafkaWatermarkQuerier(managerConfig.TransportConfig.KafkaConfig)
+		if err != nil {
+			return err
+		}
+	}
+	return mgr.Add(&reporter{
+		log:            ctrl.Log.WithName("transport-health-reporter"),
+		client:         mgr.GetClient(),
+		namespace:      managerConfig.ManagerNamespace,
+		querier:        querier,
+		lagThreshold:   defaultLagThreshold,
+		delayThreshold: defaultDelayThreshold,
+	})
+}
+
+type reporter struct {
+	log            logr.Logger
+	client         client.Client
+	namespace      string
+	querier        WatermarkQuerier
+	lagThreshold   int64
+	delayThreshold time.Duration
+
+	published       bool
+	lastDegraded    bool
+	lastPublishTime time.Time
+}
+
+func (r *reporter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(reportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("context canceled, exiting transport health reporter...")
+			return nil
+		case <-ticker.C:
+			if err := r.report(ctx); err != nil {
+				r.log.Error(err, "failed to report the transport health")
+			}
+		}
+	}
+}
+
+func (r *reporter) report(ctx context.Context) error {
+	positions, err := committedPositions()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	heartbeats, err := hubHeartbeats(now.Add(-defaultForgetThreshold))
+	if err != nil {
+		return err
+	}
+	health, err := buildHealth(positions, r.querier, getHubLags(heartbeats, now))
+	if err != nil {
+		return err
+	}
+	health.LagThreshold = r.lagThreshold
+	health.DelayThreshold = r.delayThreshold
+
+	for _, partition := range health.Partitions {
+		monitoring.TransportConsumerLagGaugeVec.WithLabelValues(partition.Topic,
+			strconv.Itoa(int(partition.Partition))).Set(float64(partition.Lag))
+	}
+	for _, hub := range health.Hubs {
+		monitoring.TransportHubLastReceivedGaugeVec.WithLabelValues(hub.Name).Set(
+			float64(hub.LastReceivedTime.Unix()))
+		monitoring.TransportHubDelayGaugeVec.WithLabelValues(hub.Name).Set(hub.Delay.Seconds())
+	}
+
+	return r.publish(ctx, health)
+}
+
+// publish updates the configmap when the health is changed between healthy and degraded, otherwise refreshes it
+// every publish interval, to avoid triggering the operator frequently.
+func (r *reporter) publish(ctx context.Context, health *transport.TransportHealth) error {
+	degraded := health.Degraded()
+	if r.published && degraded == r.lastDegraded && time.Since(r.lastPublishTime) < publishInterval {
+		return nil
+	}
+
+	// only the lagging hubs are rolled up into the status
+	published := *health
+	published.Hubs = health.LaggingHubs()
+	payload, err := json.Marshal(published)
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = r.client.Get(ctx, client.ObjectKey{
+		Namespace: r.namespace,
+		Name:      constants.GHTransportHealthConfigMap,
+	}, configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if errors.IsNotFound(err) {
+		err = r.client.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: r.namespace,
+				Name:      constants.GHTransportHealthConfigMap,
+			},
+			Data: map[string]string{constants.GHTransportHealthKey: string(payload)},
+		})
+	} else {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[constants.GHTransportHealthKey] = string(payload)
+		err = r.client.Update(ctx, configMap)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.published = true
+	r.lastDegraded = degraded
+	r.lastPublishTime = time.Now()
+	return nil
+}
+
+// committedPositions returns the positions committed by the conflation committers of the manager replicas.
+func committedPositions() ([]metadata.TransportPosition, error) {
+	positions, err := consumer.CommittedPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the committed positions: %w", err)
+	}
+	return positions, nil
+}
+
+// hubHeartbeats returns the last time the hubs are received by any manager replica since the given time.
+func hubHeartbeats(since time.Time) (map[string]time.Time, error) {
+	var heartbeats []models.LeafHubHeartbeat
+	if err := database.GetGorm().Where("last_timestamp > ?", since).Find(&heartbeats).Error; err != nil {
+		return nil, fmt.Errorf("failed to query the hub heartbeats: %w", err)
+	}
+	lastReceivedTimes := make(map[string]time.Time, len(heartbeats))
+	for _, heartbeat := range heartbeats {
+		lastReceivedTimes[heartbeat.Name] = heartbeat.LastUpdateAt
+	}
+	return lastReceivedTimes, nil
+}
+
+// buildHealth compares the committed positions with the high-water marks of the partitions.
+func buildHealth(positions []metadata.TransportPosition, querier WatermarkQuerier, hubs []transport.HubLag,
+) (*transport.TransportHealth, error) {
+	health := &transport.TransportHealth{
+		Partitions: []transport.PartitionLag{},
+		Hubs:       hubs,
+	}
+	if querier == nil {
+		return health, nil
+	}
+
+	watermarks := map[string]map[int32]int64{}
+	for _, position := range positions {
+		topicWatermarks, ok := watermarks[position.Topic]
+		if !ok {
+			var err error
+			topicWatermarks, err = querier.HighWatermarks(position.Topic)
+			if err != nil {
+				return nil, err
+			}
+			watermarks[position.Topic] = topicWatermarks
+		}
+		highWaterMark, ok := topicWatermarks[position.Partition]
+		if !ok {
+			continue
+		}
+		lag := highWaterMark - position.Offset
+		if lag < 0 {
+			lag = 0
+		}
+		health.Partitions = append(health.Partitions, transport.PartitionLag{
+			Topic:         position.Topic,
+			Partition:     position.Partition,
+			Committed:     position.Offset,
+			HighWaterMark: highWaterMark,
+			Lag:           lag,
+		})
+	}
+	sort.Slice(health.Partitions, func(i, j int) bool {
+		if health.Partitions[i].Topic != health.Partitions[j].Topic {
+			return health.Partitions[i].Topic < health.Partitions[j].Topic
+		}
+		return health.Partitions[i].Partition < health.Partitions[j].Partition
+	})
+	return health, nil
+}
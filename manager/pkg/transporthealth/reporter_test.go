@@ -0,0 +1,131 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package transporthealth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+)
+
+type fakeWatermarkQuerier map[string]map[int32]int64
+
+func (q fakeWatermarkQuerier) HighWatermarks(topic string) (map[int32]int64, error) {
+	return q[topic], nil
+}
+
+func TestBuildHealth(t *testing.T) {
+	now := time.Now()
+	RecordReceived("hub1", now.Add(-10*time.Second), now)
+	RecordReceived("hub2", now.Add(-10*time.Minute), now)
+
+	health, err := buildHealth([]metadata.TransportPosition{
+		{Topic: "status", Partition: 1, Offset: 100},
+		{Topic: "status", Partition: 0, Offset: 500},
+		{Topic: "event", Partition: 0, Offset: 10},
+	}, fakeWatermarkQuerier{
+		"status": {0: 2000, 1: 100},
+		"event":  {0: 5},
+	}, getHubLags(nil, now))
+	assert.Nil(t, err)
+	health.LagThreshold = defaultLagThreshold
+	health.DelayThreshold = defaultDelayThreshold
+
+	assert.Equal(t, []transport.PartitionLag{
+		{Topic: "event", Partition: 0, Committed: 10, HighWaterMark: 5, Lag: 0},
+		{Topic: "status", Partition: 0, Committed: 500, HighWaterMark: 2000, Lag: 1500},
+		{Topic: "status", Partition: 1, Committed: 100, HighWaterMark: 100, Lag: 0},
+	}, health.Partitions)
+	assert.Equal(t, int64(1500), health.TotalLag())
+
+	laggingHubs := health.LaggingHubs()
+	assert.Len(t, laggingHubs, 1)
+	assert.Equal(t, "hub2", laggingHubs[0].Name)
+	assert.True(t, health.Degraded())
+	assert.Equal(t, "the manager is 1500 messages behind the transport, lagging hubs: hub2(10m0s)",
+		health.Message())
+
+	// the hub catches up
+	RecordReceived("hub2", now.Add(time.Minute), now.Add(time.Minute))
+	health, err = buildHealth(nil, fakeWatermarkQuerier{}, getHubLags(nil, now.Add(time.Minute)))
+	assert.Nil(t, err)
+	health.LagThreshold = defaultLagThreshold
+	health.DelayThreshold = defaultDelayThreshold
+	assert.False(t, health.Degraded())
+	assert.Equal(t, "the manager is 0 messages behind the transport", health.Message())
+
+	// the hub1 stops sending, the hub3 is only received by another replica
+	later := now.Add(10 * time.Minute)
+	health, err = buildHealth(nil, fakeWatermarkQuerier{}, getHubLags(map[string]time.Time{
+		"hub2": later.Add(-time.Minute),
+		"hub3": later.Add(-30 * time.Second),
+		"hub4": later.Add(-20 * time.Minute),
+	}, later))
+	assert.Nil(t, err)
+	health.LagThreshold = defaultLagThreshold
+	health.DelayThreshold = defaultDelayThreshold
+	assert.Len(t, health.Hubs, 4)
+	assert.True(t, health.Degraded())
+	assert.Equal(t, "the manager is 0 messages behind the transport, lagging hubs: hub1(silent for 10m0s), "+
+		"hub4(silent for 20m0s)", health.Message())
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	assert.Nil(t, corev1.AddToScheme(scheme))
+	r := &reporter{
+		log:            ctrl.Log.WithName("transport-health-reporter"),
+		client:         fake.NewClientBuilder().WithScheme(scheme).Build(),
+		namespace:      constants.GHDefaultNamespace,
+		lagThreshold:   defaultLagThreshold,
+		delayThreshold: defaultDelayThreshold,
+	}
+
+	getHealth := func() *transport.TransportHealth {
+		configMap := &corev1.ConfigMap{}
+		assert.Nil(t, r.client.Get(ctx, client.ObjectKey{
+			Namespace: constants.GHDefaultNamespace,
+			Name:      constants.GHTransportHealthConfigMap,
+		}, configMap))
+		health := &transport.TransportHealth{}
+		assert.Nil(t, json.Unmarshal([]byte(configMap.Data[constants.GHTransportHealthKey]), health))
+		return health
+	}
+
+	health := &transport.TransportHealth{
+		Partitions:     []transport.PartitionLag{{Topic: "status", Partition: 0, Lag: 10}},
+		Hubs:           []transport.HubLag{{Name: "hub1", LastReceivedTime: time.Now()}},
+		LagThreshold:   defaultLagThreshold,
+		DelayThreshold: defaultDelayThreshold,
+	}
+	assert.Nil(t, r.publish(ctx, health))
+	published := getHealth()
+	assert.False(t, published.Degraded())
+	assert.Len(t, published.Hubs, 0)
+
+	// skip publishing since the health isn't changed between healthy and degraded
+	health.Partitions[0].Lag = 20
+	assert.Nil(t, r.publish(ctx, health))
+	assert.Equal(t, int64(10), getHealth().TotalLag())
+
+	// publish the degraded health immediately
+	health.Hubs[0].Delay = 10 * time.Minute
+	assert.Nil(t, r.publish(ctx, health))
+	published = getHealth()
+	assert.True(t, published.Degraded())
+	assert.Equal(t, "hub1", published.LaggingHubs()[0].Name)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package transporthealth
+
+import (
+	"fmt"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport/config"
+)
+
+const watermarkTimeoutMs = 5000
+
+// WatermarkQuerier queries the high-water mark of each partition of the topic.
+type WatermarkQuerier interface {
+	HighWatermarks(topic string) (map[int32]int64, error)
+}
+
+type kafkaWatermarkQuerier struct {
+	consumer *kafka.Consumer
+}
+
+// NewKafkaWatermarkQuerier creates a kafka client to query the watermarks, it doesn't subscribe to any topic, so it
+// won't join the consumer group of the manager.
+func NewKafkaWatermarkQuerier(kafkaConfig *transport.KafkaConfig) (WatermarkQuerier, error) {
+	configMap, err := config.GetConfluentConfigMap(kafkaConfig, false)
+	if err != nil {
+		return nil, err
+	}
+	_ = configMap.SetKey("client.id", fmt.Sprintf("%s-lag", kafkaConfig.ConsumerConfig.ConsumerID))
+	_ = configMap.SetKey("enable.auto.commit", "false")
+
+	consumer, err := kafka.NewConsumer(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the kafka client to query watermarks: %w", err)
+	}
+	return &kafkaWatermarkQuerier{consumer: consumer}, nil
+}
+
+func (q *kafkaWatermarkQuerier) HighWatermarks(topic string) (map[int32]int64, error) {
+	metadata, err := q.consumer.GetMetadata(&topic, false, watermarkTimeoutMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the metadata of topic %s: %w", topic, err)
+	}
+	topicMetadata, ok := metadata.Topics[topic]
+	if !ok {
+		return nil, fmt.Errorf("topic %s not found", topic)
+	}
+
+	watermarks := map[int32]int64{}
+	for _, partition := range topicMetadata.Partitions {
+		_, high, err := q.consumer.QueryWatermarkOffsets(topic, partition.ID, watermarkTimeoutMs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the watermarks of %s@%d: %w", topic, partition.ID, err)
+		}
+		watermarks[partition.ID] = high
+	}
+	return watermarks, nil
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/audit"
+	"github.com/stolostron/multicluster-global-hub/pkg/database"
+)
+
+// NewAuditHandler records the user of the admitted changes of the global resources. The handler is a validating
+// webhook, so it sees the objects after all the mutations, and the changes are recorded into the audit log with the
+// user by the spec to db controllers once they're persisted. The changes are rejected if the user can't be recorded.
+func NewAuditHandler() admission.Handler {
+	return &auditHandler{}
+}
+
+type auditHandler struct{}
+
+func (a *auditHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if _, found := audit.SpecTables[req.Kind.Kind]; !found || (req.DryRun != nil && *req.DryRun) {
+		return admission.Allowed("")
+	}
+
+	if err := audit.SetActor(database.GetGorm().WithContext(ctx), req.Kind.Kind, req.Namespace, req.Name,
+		req.UserInfo.Username, req.UserInfo.Groups); err != nil {
+		log.Error(err, "failed to record the user of the change", "kind", req.Kind.Kind, "namespace", req.Namespace,
+			"name", req.Name, "user", req.UserInfo.Username)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	log.V(2).Info("user of the change is recorded", "kind", req.Kind.Kind, "namespace", req.Namespace,
+		"name", req.Name, "operation", req.Operation, "user", req.UserInfo.Username)
+	return admission.Allowed("")
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
+	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
+)
+
+// ConvertTo converts this MulticlusterGlobalHub to the hub version (v1beta1). The annotations which are promoted to
+// the spec fields of v1beta1 are moved into the fields, the invalid values are kept in the annotations.
+func (src *MulticlusterGlobalHub) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.MulticlusterGlobalHub)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if err := convertByJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertByJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	annotations := dst.GetAnnotations()
+	if paused, ok := popBoolAnnotation(annotations, operatorconstants.AnnotationMGHPause); ok {
+		dst.Spec.Paused = paused
+	}
+	if repo, ok := popAnnotation(annotations, operatorconstants.AnnotationImageRepo); ok {
+		dst.Spec.ImageRepository = repo
+	}
+	if cm, ok := popAnnotation(annotations, operatorconstants.AnnotationImageOverridesCM); ok {
+		dst.Spec.ImageOverridesConfigMap = cm
+	}
+	if install, ok := popBoolAnnotation(annotations, operatorconstants.AnnotationMGHInstallCrunchyOperator); ok {
+		dst.Spec.InstallCrunchyOperator = install
+	}
+	if gitops, ok := popBoolAnnotation(annotations, operatorconstants.AnnotationMGHEnableGitOpsStatus); ok {
+		dst.Spec.EnableGitOpsStatus = gitops
+	}
+	if interval, ok := popDurationAnnotation(annotations, operatorconstants.AnnotationMetricsScrapeInterval); ok {
+		dst.Spec.MetricsScrapeInterval = interval
+	}
+	if rules, ok := annotations[operatorconstants.AnnotationMGHRedactionRules]; ok {
+		redactionRules := map[string][]string{}
+		if err := json.Unmarshal([]byte(rules), &redactionRules); err == nil && len(redactionRules) > 0 {
+			dst.Spec.RedactionRules = redactionRules
+			delete(annotations, operatorconstants.AnnotationMGHRedactionRules)
+		}
+	}
+
+	manager := &v1beta1.ManagerConfig{}
+	if skipAuth, ok := popBoolAnnotation(annotations, operatorconstants.AnnotationMGHSkipAuth); ok {
+		manager.SkipAuth = skipAuth
+	}
+	if interval, ok := annotations[operatorconstants.AnnotationMGHSchedulerInterval]; ok &&
+		isValidSchedulerInterval(interval) {
+		manager.SchedulerInterval = v1beta1.SchedulerInterval(interval)
+		delete(annotations, operatorconstants.AnnotationMGHSchedulerInterval)
+	}
+	if jobs, ok := annotations[operatorconstants.AnnotationLaunchJobNames]; ok {
+		if launchJobs, valid := parseLaunchJobs(jobs); valid {
+			manager.LaunchJobs = launchJobs
+			delete(annotations, operatorconstants.AnnotationLaunchJobNames)
+		}
+	}
+	if sharding, ok := popBoolAnnotation(annotations, operatorconstants.AnnotationMGHEnableStatusSharding); ok {
+		manager.StatusSharding = sharding
+	}
+	if interval, ok := popDurationAnnotation(annotations, operatorconstants.AnnotationStatisticInterval); ok {
+		manager.StatisticInterval = interval
+	}
+	if secret, ok := popAnnotation(annotations, operatorconstants.AnnotationMGHPayloadEncryptionSecret); ok {
+		manager.PayloadEncryptionSecret = secret
+	}
+	if !reflect.DeepEqual(*manager, v1beta1.ManagerConfig{}) {
+		dst.Spec.Manager = manager
+	}
+	if advanced, ok := annotations[operatorconstants.AnnotationMGHAdvancedConfig]; ok {
+		saved := &v1beta1.AdvancedConfig{}
+		if err := json.Unmarshal([]byte(advanced), saved); err == nil {
+			restoreAdvancedConfig(&dst.Spec, saved)
+			delete(annotations, operatorconstants.AnnotationMGHAdvancedConfig)
+		}
+	}
+	if backup, ok := annotations[operatorconstants.AnnotationMGHPostgresBackup]; ok {
+		saved := &v1beta1.PostgresConfig{}
+		if err := json.Unmarshal([]byte(backup), saved); err == nil {
+			dst.Spec.DataLayer.Postgres.Backup = saved.Backup
+			dst.Spec.DataLayer.Postgres.Restore = saved.Restore
+			delete(annotations, operatorconstants.AnnotationMGHPostgresBackup)
+		}
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	dst.SetAnnotations(annotations)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1beta1) to this version. The spec fields which don't exist in this
+// version are written into the annotations.
+func (dst *MulticlusterGlobalHub) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.MulticlusterGlobalHub)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if err := convertByJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertByJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	annotations := dst.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if src.Spec.Paused {
+		annotations[operatorconstants.AnnotationMGHPause] = "true"
+	}
+	if src.Spec.ImageRepository != "" {
+		annotations[operatorconstants.AnnotationImageRepo] = src.Spec.ImageRepository
+	}
+	if src.Spec.ImageOverridesConfigMap != "" {
+		annotations[operatorconstants.AnnotationImageOverridesCM] = src.Spec.ImageOverridesConfigMap
+	}
+	if src.Spec.InstallCrunchyOperator {
+		annotations[operatorconstants.AnnotationMGHInstallCrunchyOperator] = "true"
+	}
+	if src.Spec.EnableGitOpsStatus {
+		annotations[operatorconstants.AnnotationMGHEnableGitOpsStatus] = "true"
+	}
+	if src.Spec.MetricsScrapeInterval != nil {
+		annotations[operatorconstants.AnnotationMetricsScrapeInterval] = src.Spec.MetricsScrapeInterval.Duration.String()
+	}
+	if len(src.Spec.RedactionRules) > 0 {
+		data, err := json.Marshal(src.Spec.RedactionRules)
+		if err != nil {
+			return err
+		}
+		annotations[operatorconstants.AnnotationMGHRedactionRules] = string(data)
+	}
+	if manager := src.Spec.Manager; manager != nil {
+		if manager.SkipAuth {
+			annotations[operatorconstants.AnnotationMGHSkipAuth] = "true"
+		}
+		if manager.SchedulerInterval != "" {
+			annotations[operatorconstants.AnnotationMGHSchedulerInterval] = string(manager.SchedulerInterval)
+		}
+		if len(manager.LaunchJobs) > 0 {
+			jobs := make([]string, 0, len(manager.LaunchJobs))
+			for _, job := range manager.LaunchJobs {
+				jobs = append(jobs, string(job))
+			}
+			annotations[operatorconstants.AnnotationLaunchJobNames] = strings.Join(jobs, ",")
+		}
+		if manager.StatusSharding {
+			annotations[operatorconstants.AnnotationMGHEnableStatusSharding] = "true"
+		}
+		if manager.StatisticInterval != nil {
+			annotations[operatorconstants.AnnotationStatisticInterval] = manager.StatisticInterval.Duration.String()
+		}
+		if manager.PayloadEncryptionSecret != "" {
+			annotations[operatorconstants.AnnotationMGHPayloadEncryptionSecret] = manager.PayloadEncryptionSecret
+		}
+	}
+	if saved := advancedConfigWithoutResources(src.Spec.AdvancedConfig); saved != nil {
+		data, err := json.Marshal(saved)
+		if err != nil {
+			return err
+		}
+		annotations[operatorconstants.AnnotationMGHAdvancedConfig] = string(data)
+	}
+	if postgres := src.Spec.DataLayer.Postgres; postgres.Backup != nil || postgres.Restore != nil {
+		data, err := json.Marshal(&v1beta1.PostgresConfig{Backup: postgres.Backup, Restore: postgres.Restore})
+		if err != nil {
+			return err
+		}
+		annotations[operatorconstants.AnnotationMGHPostgresBackup] = string(data)
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	dst.SetAnnotations(annotations)
+	return nil
+}
+
+// convertByJSON converts the structs which have the same json schema in both versions
+func convertByJSON(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// componentSpecs returns the references of the component specs in the advanced config
+func componentSpecs(advanced *v1beta1.AdvancedConfig) []**v1beta1.CommonSpec {
+	return []**v1beta1.CommonSpec{
+		&advanced.Grafana, &advanced.Kafka, &advanced.Zookeeper, &advanced.Postgres, &advanced.Manager, &advanced.Agent,
+	}
+}
+
+// advancedConfigWithoutResources returns the fields of the component specs which don't exist in v1alpha4, it returns
+// nil if all of them are empty
+func advancedConfigWithoutResources(advanced *v1beta1.AdvancedConfig) *v1beta1.AdvancedConfig {
+	if advanced == nil {
+		return nil
+	}
+	saved := advanced.DeepCopy()
+	found := false
+	for _, spec := range componentSpecs(saved) {
+		if *spec == nil {
+			continue
+		}
+		(*spec).Resources = nil
+		if reflect.DeepEqual(**spec, v1beta1.CommonSpec{}) {
+			*spec = nil
+			continue
+		}
+		found = true
+	}
+	if !found {
+		return nil
+	}
+	return saved
+}
+
+// restoreAdvancedConfig restores the saved fields of the component specs, the resources are kept since they may be
+// updated with v1alpha4
+func restoreAdvancedConfig(dst *v1beta1.MulticlusterGlobalHubSpec, saved *v1beta1.AdvancedConfig) {
+	if dst.AdvancedConfig == nil {
+		dst.AdvancedConfig = &v1beta1.AdvancedConfig{}
+	}
+	dstSpecs := componentSpecs(dst.AdvancedConfig)
+	for i, spec := range componentSpecs(saved) {
+		if *spec == nil {
+			continue
+		}
+		restored := (*spec).DeepCopy()
+		restored.Resources = nil
+		if *dstSpecs[i] != nil {
+			restored.Resources = (*dstSpecs[i]).Resources
+		}
+		*dstSpecs[i] = restored
+	}
+}
+
+func popAnnotation(annotations map[string]string, key string) (string, bool) {
+	val, ok := annotations[key]
+	if !ok || val == "" {
+		return "", false
+	}
+	delete(annotations, key)
+	return val, true
+}
+
+// popBoolAnnotation only pops the annotation with the "true" or "false" value
+func popBoolAnnotation(annotations map[string]string, key string) (bool, bool) {
+	val, ok := annotations[key]
+	if !ok || (!strings.EqualFold(val, "true") && !strings.EqualFold(val, "false")) {
+		return false, false
+	}
+	delete(annotations, key)
+	return strings.EqualFold(val, "true"), true
+}
+
+// popDurationAnnotation only pops the annotation with a valid duration
+func popDurationAnnotation(annotations map[string]string, key string) (*metav1.Duration, bool) {
+	val, ok := annotations[key]
+	if !ok {
+		return nil, false
+	}
+	duration, err := time.ParseDuration(val)
+	if err != nil {
+		return nil, false
+	}
+	delete(annotations, key)
+	return &metav1.Duration{Duration: duration}, true
+}
+
+func isValidSchedulerInterval(interval string) bool {
+	switch v1beta1.SchedulerInterval(interval) {
+	case v1beta1.EveryMonth, v1beta1.EveryWeek, v1beta1.EveryDay, v1beta1.EveryHour, v1beta1.EveryMinute,
+		v1beta1.EverySecond:
+		return true
+	}
+	return false
+}
+
+func parseLaunchJobs(jobs string) ([]v1beta1.LaunchJob, bool) {
+	launchJobs := []v1beta1.LaunchJob{}
+	for _, job := range strings.Split(jobs, ",") {
+		job = strings.TrimSpace(job)
+		if job == "" {
+			continue
+		}
+		switch v1beta1.LaunchJob(job) {
+		case v1beta1.DataRetentionJob, v1beta1.LocalComplianceHistoryJob:
+			launchJobs = append(launchJobs, v1beta1.LaunchJob(job))
+		default:
+			return nil, false
+		}
+	}
+	return launchJobs, true
+}
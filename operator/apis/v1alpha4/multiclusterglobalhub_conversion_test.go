@@ -0,0 +1,210 @@
+package v1alpha4
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
+	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
+)
+
+func TestConversion(t *testing.T) {
+	src := &MulticlusterGlobalHub{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "multiclusterglobalhub",
+			Namespace: "multicluster-global-hub",
+			Annotations: map[string]string{
+				operatorconstants.AnnotationMGHPause:                   "True",
+				operatorconstants.AnnotationMGHSkipAuth:                "true",
+				operatorconstants.AnnotationMGHSchedulerInterval:       "minute",
+				operatorconstants.AnnotationLaunchJobNames:             "data-retention, local-compliance-history",
+				operatorconstants.AnnotationImageRepo:                  "quay.io/testing",
+				operatorconstants.AnnotationImageOverridesCM:           "image-overrides",
+				operatorconstants.AnnotationMGHInstallCrunchyOperator:  "yes",
+				operatorconstants.AnnotationMGHEnableStatusSharding:    "true",
+				operatorconstants.AnnotationMGHEnableGitOpsStatus:      "true",
+				operatorconstants.AnnotationStatisticInterval:          "30s",
+				operatorconstants.AnnotationMetricsScrapeInterval:      "1m30s",
+				operatorconstants.AnnotationMGHRedactionRules:          `{"Secret":["$.data"]}`,
+				operatorconstants.AnnotationMGHPayloadEncryptionSecret: "payload-encryption",
+				"foo": "bar",
+			},
+		},
+		Spec: MulticlusterGlobalHubSpec{
+			AvailabilityConfig: HABasic,
+			DataLayer: DataLayerConfig{
+				Postgres: PostgresConfig{Retention: "18m"},
+			},
+		},
+	}
+
+	hub := &v1beta1.MulticlusterGlobalHub{}
+	if err := src.ConvertTo(hub); err != nil {
+		t.Fatalf("failed to convert to v1beta1: %v", err)
+	}
+	if !hub.Spec.Paused || hub.Spec.ImageRepository != "quay.io/testing" ||
+		hub.Spec.ImageOverridesConfigMap != "image-overrides" {
+		t.Errorf("unexpected spec: %+v", hub.Spec)
+	}
+	if hub.Spec.Manager == nil || !hub.Spec.Manager.SkipAuth || hub.Spec.Manager.SchedulerInterval != v1beta1.EveryMinute ||
+		!hub.Spec.Manager.StatusSharding {
+		t.Errorf("unexpected manager config: %+v", hub.Spec.Manager)
+	}
+	if hub.Spec.Manager.StatisticInterval == nil || hub.Spec.Manager.StatisticInterval.Duration != 30*time.Second ||
+		hub.Spec.Manager.PayloadEncryptionSecret != "payload-encryption" {
+		t.Errorf("unexpected manager config: %+v", hub.Spec.Manager)
+	}
+	if !hub.Spec.EnableGitOpsStatus || hub.Spec.MetricsScrapeInterval == nil ||
+		hub.Spec.MetricsScrapeInterval.Duration != 90*time.Second ||
+		!reflect.DeepEqual(hub.Spec.RedactionRules, map[string][]string{"Secret": {"$.data"}}) {
+		t.Errorf("unexpected spec: %+v", hub.Spec)
+	}
+	if !reflect.DeepEqual(hub.Spec.Manager.LaunchJobs,
+		[]v1beta1.LaunchJob{v1beta1.DataRetentionJob, v1beta1.LocalComplianceHistoryJob}) {
+		t.Errorf("unexpected launch jobs: %v", hub.Spec.Manager.LaunchJobs)
+	}
+	if hub.Spec.AvailabilityConfig != v1beta1.HABasic || hub.Spec.DataLayer.Postgres.Retention != "18m" {
+		t.Errorf("the common spec isn't converted: %+v", hub.Spec)
+	}
+	// the invalid value is kept in the annotation
+	expectedAnnotations := map[string]string{
+		operatorconstants.AnnotationMGHInstallCrunchyOperator: "yes",
+		"foo": "bar",
+	}
+	if !reflect.DeepEqual(hub.GetAnnotations(), expectedAnnotations) {
+		t.Errorf("unexpected annotations: %v", hub.GetAnnotations())
+	}
+	// the source object isn't changed
+	if len(src.GetAnnotations()) != 14 {
+		t.Errorf("the source object is changed: %v", src.GetAnnotations())
+	}
+
+	dst := &MulticlusterGlobalHub{}
+	if err := dst.ConvertFrom(hub); err != nil {
+		t.Fatalf("failed to convert from v1beta1: %v", err)
+	}
+	expectedAnnotations = map[string]string{
+		operatorconstants.AnnotationMGHPause:                   "true",
+		operatorconstants.AnnotationMGHSkipAuth:                "true",
+		operatorconstants.AnnotationMGHSchedulerInterval:       "minute",
+		operatorconstants.AnnotationLaunchJobNames:             "data-retention,local-compliance-history",
+		operatorconstants.AnnotationImageRepo:                  "quay.io/testing",
+		operatorconstants.AnnotationImageOverridesCM:           "image-overrides",
+		operatorconstants.AnnotationMGHInstallCrunchyOperator:  "yes",
+		operatorconstants.AnnotationMGHEnableStatusSharding:    "true",
+		operatorconstants.AnnotationMGHEnableGitOpsStatus:      "true",
+		operatorconstants.AnnotationStatisticInterval:          "30s",
+		operatorconstants.AnnotationMetricsScrapeInterval:      "1m30s",
+		operatorconstants.AnnotationMGHRedactionRules:          `{"Secret":["$.data"]}`,
+		operatorconstants.AnnotationMGHPayloadEncryptionSecret: "payload-encryption",
+		"foo": "bar",
+	}
+	if !reflect.DeepEqual(dst.GetAnnotations(), expectedAnnotations) {
+		t.Errorf("unexpected annotations: %v", dst.GetAnnotations())
+	}
+	if !reflect.DeepEqual(dst.Spec, src.Spec) {
+		t.Errorf("the spec isn't converted back: %+v", dst.Spec)
+	}
+}
+
+func TestConversionKeepsAdvancedConfig(t *testing.T) {
+	replicas := int32(2)
+	hub := &v1beta1.MulticlusterGlobalHub{
+		ObjectMeta: metav1.ObjectMeta{Name: "multiclusterglobalhub", Namespace: "multicluster-global-hub"},
+		Spec: v1beta1.MulticlusterGlobalHubSpec{
+			AdvancedConfig: &v1beta1.AdvancedConfig{
+				Kafka: &v1beta1.CommonSpec{
+					Replicas:          &replicas,
+					NodeSelector:      map[string]string{"node-role.kubernetes.io/storage": ""},
+					PriorityClassName: "high-priority",
+				},
+				Grafana: &v1beta1.CommonSpec{
+					Resources: &v1beta1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")},
+					},
+				},
+			},
+		},
+	}
+
+	alpha := &MulticlusterGlobalHub{}
+	if err := alpha.ConvertFrom(hub); err != nil {
+		t.Fatalf("failed to convert from v1beta1: %v", err)
+	}
+	if _, ok := alpha.GetAnnotations()[operatorconstants.AnnotationMGHAdvancedConfig]; !ok {
+		t.Fatalf("the advanced config isn't kept in the annotations: %v", alpha.GetAnnotations())
+	}
+	if alpha.Spec.AdvancedConfig.Grafana.Resources == nil {
+		t.Errorf("the resources aren't converted: %+v", alpha.Spec.AdvancedConfig)
+	}
+
+	// the resources are updated with v1alpha4
+	alpha.Spec.AdvancedConfig.Kafka = &CommonSpec{
+		Resources: &ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+		},
+	}
+	converted := &v1beta1.MulticlusterGlobalHub{}
+	if err := alpha.ConvertTo(converted); err != nil {
+		t.Fatalf("failed to convert to v1beta1: %v", err)
+	}
+	if len(converted.GetAnnotations()) != 0 {
+		t.Errorf("unexpected annotations: %v", converted.GetAnnotations())
+	}
+	kafka := converted.Spec.AdvancedConfig.Kafka
+	if kafka == nil || kafka.Replicas == nil || *kafka.Replicas != 2 || kafka.PriorityClassName != "high-priority" ||
+		!reflect.DeepEqual(kafka.NodeSelector, hub.Spec.AdvancedConfig.Kafka.NodeSelector) {
+		t.Errorf("the kafka spec isn't restored: %+v", kafka)
+	}
+	if kafka.Resources == nil || !kafka.Resources.Limits.Memory().Equal(resource.MustParse("4Gi")) {
+		t.Errorf("the kafka resources aren't converted: %+v", kafka.Resources)
+	}
+	if converted.Spec.AdvancedConfig.Grafana.Resources == nil {
+		t.Errorf("the grafana resources aren't converted: %+v", converted.Spec.AdvancedConfig.Grafana)
+	}
+}
+
+func TestConversionKeepsPostgresBackup(t *testing.T) {
+	hub := &v1beta1.MulticlusterGlobalHub{
+		ObjectMeta: metav1.ObjectMeta{Name: "multiclusterglobalhub", Namespace: "multicluster-global-hub"},
+		Spec: v1beta1.MulticlusterGlobalHubSpec{
+			DataLayer: v1beta1.DataLayerConfig{
+				Postgres: v1beta1.PostgresConfig{
+					Retention: "18m",
+					Backup: &v1beta1.PostgresBackupConfig{
+						Schedule:  "0 2 * * *",
+						Retention: 7,
+						Storage: v1beta1.BackupStorage{
+							S3: &v1beta1.BackupS3Storage{Bucket: "globalhub", CredentialSecret: "backup-credential"},
+						},
+					},
+					Restore: &v1beta1.PostgresRestoreConfig{Backup: "globalhub-20240102T020000Z.tar.gz"},
+				},
+			},
+		},
+	}
+
+	alpha := &MulticlusterGlobalHub{}
+	if err := alpha.ConvertFrom(hub); err != nil {
+		t.Fatalf("failed to convert from v1beta1: %v", err)
+	}
+	if _, ok := alpha.GetAnnotations()[operatorconstants.AnnotationMGHPostgresBackup]; !ok {
+		t.Fatalf("the backup config isn't kept in the annotations: %v", alpha.GetAnnotations())
+	}
+
+	converted := &v1beta1.MulticlusterGlobalHub{}
+	if err := alpha.ConvertTo(converted); err != nil {
+		t.Fatalf("failed to convert to v1beta1: %v", err)
+	}
+	if len(converted.GetAnnotations()) != 0 {
+		t.Errorf("unexpected annotations: %v", converted.GetAnnotations())
+	}
+	if !reflect.DeepEqual(converted.Spec.DataLayer.Postgres, hub.Spec.DataLayer.Postgres) {
+		t.Errorf("the postgres config isn't restored: %+v", converted.Spec.DataLayer.Postgres)
+	}
+}
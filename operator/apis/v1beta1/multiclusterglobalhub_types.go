@@ -0,0 +1,414 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DataLayerType specifies the type of data layer that global hub stores and transports the data.
+// +kubebuilder:validation:Enum:="largeScale"
+type DataLayerType string
+
+const (
+	// // Native is a DataLayerType using kubernetes native storage and event subscription
+	// Native DataLayerType = "native"
+	// LargeScale is a DataLayerType using external high performance data storage and transport layer
+	LargeScale DataLayerType = "largeScale"
+)
+
+// TransportFormatType specifies the type of data format based on kafka implementation.
+type TransportFormatType string
+
+const (
+	KafkaMessage TransportFormatType = "message"
+	CloudEvents  TransportFormatType = "cloudEvents"
+)
+
+// AvailabilityType ...
+type AvailabilityType string
+
+const (
+	// HABasic stands up most app subscriptions with a replicaCount of 1
+	HABasic AvailabilityType = "Basic"
+	// HAHigh stands up most app subscriptions with a replicaCount of 2
+	HAHigh AvailabilityType = "High"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:shortName={mgh,mcgh}
+// +operator-sdk:csv:customresourcedefinitions:resources={{Deployment,v1,multicluster-global-hub-operator}}
+// MulticlusterGlobalHub defines the configuration for an instance of the multiCluster global hub
+type MulticlusterGlobalHub struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:default={dataLayer: {postgres: {retention: "18m"}}}
+	Spec   MulticlusterGlobalHubSpec   `json:"spec,omitempty"`
+	Status MulticlusterGlobalHubStatus `json:"status,omitempty"`
+}
+
+// MulticlusterGlobalHubSpec defines the desired state of MulticlusterGlobalHub
+type MulticlusterGlobalHubSpec struct {
+	// Specifies deployment replication for improved availability. Options are: Basic and High (default)
+	// +kubebuilder:default:="High"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AvailabilityConfig AvailabilityType `json:"availabilityConfig,omitempty"`
+	// Pull policy of the multicluster global hub images
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Pull secret of the multicluster global hub images
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	ImagePullSecret string `json:"imagePullSecret,omitempty"`
+	// Spec of NodeSelector
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations causes all components to tolerate any taints.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// DataLayer can be configured to use a different data layer.
+	// +kubebuilder:default={postgres: {retention: "18m"}}
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DataLayer DataLayerConfig `json:"dataLayer"`
+	// Advanced configurations for global hub
+	// +optional
+	AdvancedConfig *AdvancedConfig `json:"advanced,omitempty"`
+	// EnableMetrics enables the metrics for the global hub kafka components
+	// +optional
+	EnableMetrics bool `json:"enableMetrics,omitempty"`
+	// MetricsScrapeInterval is the interval of scraping the metrics of the global hub components, default 1m
+	// +optional
+	MetricsScrapeInterval *metav1.Duration `json:"metricsScrapeInterval,omitempty"`
+	// EnableGitOpsStatus reports the status of the argo cd applications from the managed hubs
+	// +optional
+	EnableGitOpsStatus bool `json:"enableGitOpsStatus,omitempty"`
+	// RedactionRules redacts the sensitive fields of the objects reported by the agents, it maps the kind to the json
+	// paths of the fields, e.g. {"Policy": ["$.spec.policy-templates[*].objectDefinition.data"]}
+	// +optional
+	RedactionRules map[string][]string `json:"redactionRules,omitempty"`
+	// Paused stops reconciling the global hub components, e.g. during the maintenance
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// ImageRepository overrides the repository of all the global hub images, e.g. quay.io/stolostron
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	ImageRepository string `json:"imageRepository,omitempty"`
+	// ImageOverridesConfigMap is the name of the configmap in the global hub namespace, which overrides the images of
+	// the global hub components
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	ImageOverridesConfigMap string `json:"imageOverridesConfigMap,omitempty"`
+	// InstallCrunchyOperator installs the crunchy postgres operator to provide the database
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	InstallCrunchyOperator bool `json:"installCrunchyOperator,omitempty"`
+	// Manager configures the behavior of the global hub manager
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Manager *ManagerConfig `json:"manager,omitempty"`
+}
+
+// SchedulerInterval is the interval of moving the policy compliance to the history
+// +kubebuilder:validation:Enum:=month;week;day;hour;minute;second
+type SchedulerInterval string
+
+const (
+	EveryMonth  SchedulerInterval = "month"
+	EveryWeek   SchedulerInterval = "week"
+	EveryDay    SchedulerInterval = "day"
+	EveryHour   SchedulerInterval = "hour"
+	EveryMinute SchedulerInterval = "minute"
+	EverySecond SchedulerInterval = "second"
+)
+
+// LaunchJob is the job of the manager which can be run once the manager is started
+// +kubebuilder:validation:Enum:=data-retention;local-compliance-history
+type LaunchJob string
+
+const (
+	DataRetentionJob          LaunchJob = "data-retention"
+	LocalComplianceHistoryJob LaunchJob = "local-compliance-history"
+)
+
+// ManagerConfig defines the behavior of the global hub manager
+type ManagerConfig struct {
+	// SkipAuth skips the authentication of the non-k8s api. It's only used for the test
+	// +optional
+	SkipAuth bool `json:"skipAuth,omitempty"`
+	// SchedulerInterval is the interval of moving the policy compliance to the history. Options are: month, week,
+	// day (default), hour, minute and second
+	// +optional
+	SchedulerInterval SchedulerInterval `json:"schedulerInterval,omitempty"`
+	// LaunchJobs are the jobs which run once the manager is started. Options are: data-retention and
+	// local-compliance-history
+	// +optional
+	LaunchJobs []LaunchJob `json:"launchJobs,omitempty"`
+	// StatusSharding processes the status of the managed hubs on all the manager replicas instead of the leader only,
+	// the managed hubs are split between the replicas by the kafka partitions of the status topics
+	// +optional
+	StatusSharding bool `json:"statusSharding,omitempty"`
+	// StatisticInterval is the interval of logging the statistics of the status processing
+	// +optional
+	StatisticInterval *metav1.Duration `json:"statisticInterval,omitempty"`
+	// PayloadEncryptionSecret is the secret in the global hub namespace to encrypt the fields of the stored payloads,
+	// the "key" of the secret is the AES key and the "rules" maps the table to the json paths of the fields
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	PayloadEncryptionSecret string `json:"payloadEncryptionSecret,omitempty"`
+}
+
+type AdvancedConfig struct {
+	// The spec of grafana
+	// +optional
+	Grafana *CommonSpec `json:"grafana,omitempty"`
+
+	// The spec of kafka
+	// +optional
+	Kafka *CommonSpec `json:"kafka,omitempty"`
+
+	// The spec of zookeeper
+	// +optional
+	Zookeeper *CommonSpec `json:"zookeeper,omitempty"`
+
+	// The spec of postgres
+	// +optional
+	Postgres *CommonSpec `json:"postgres,omitempty"`
+
+	// The spec of global hub manager
+	// +optional
+	Manager *CommonSpec `json:"manager,omitempty"`
+
+	// The spec of global hub agent
+	// +optional
+	Agent *CommonSpec `json:"agent,omitempty"`
+}
+
+type CommonSpec struct {
+	// Compute Resources required by this component.
+	// +optional
+	Resources *ResourceRequirements `json:"resources,omitempty"`
+	// Replicas overrides the number of pods derived from the availabilityConfig. It's ignored by postgres, which
+	// always runs a single instance
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// NodeSelector overrides the global nodeSelector for this component
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations overrides the global tolerations for this component
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity is the scheduling constraints of the pods. It replaces the default pod anti-affinity of grafana, and
+	// it's merged with the node affinity converted from the nodeSelector for kafka and zookeeper
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// TopologySpreadConstraints describes how the pods ought to spread across the topology domains
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// PriorityClassName is the priority class of the pods
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// PodAnnotations are the extra annotations added to the pods
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+	// PodLabels are the extra labels added to the pods, the labels used by the selector can't be overridden
+	// +optional
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+	// Env are the extra environment variables added to the main container, the names must be unique and can't
+	// override the variables set by the operator. Kafka and zookeeper only support the variables with a plain value
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// ResourceRequirements copied from corev1.ResourceRequirements
+// We do not need to support ResourceClaim
+type ResourceRequirements struct {
+	// Limits describes the maximum amount of compute resources allowed.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	// +optional
+	Limits corev1.ResourceList `json:"limits,omitempty"`
+	// Requests describes the minimum amount of compute resources required.
+	// If Requests is omitted for a container, it defaults to Limits if that is explicitly specified,
+	// otherwise to an implementation-defined value.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	// +optional
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+}
+
+// DataLayerConfig is a discriminated union of data layer specific configuration.
+type DataLayerConfig struct {
+	// +optional
+	Kafka KafkaConfig `json:"kafka,omitempty"`
+	// +kubebuilder:default={retention: "18m"}
+	Postgres PostgresConfig `json:"postgres,omitempty"`
+	// Specify the storageClass for storage.
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// PostgresConfig defines the desired state of postgres
+type PostgresConfig struct {
+	// Retention is a duration string. Which defines how long to keep the data in the database.
+	// Recommended minimum value is 1 month, default value is 18 months.
+	// A duration string is a possibly signed sequence of decimal numbers, each with optional fraction and a unit suffix,
+	// such as "1y6m". Valid time units are "m" and "y".
+	// +kubebuilder:default:="18m"
+	Retention string `json:"retention,omitempty"`
+
+	// Specify the size for storage.
+	// +optional
+	StorageSize string `json:"storageSize,omitempty"`
+
+	// Backup takes scheduled logical backups of the global hub database, including the transport positions.
+	// +optional
+	Backup *PostgresBackupConfig `json:"backup,omitempty"`
+
+	// Restore restores the database from a backup of the backup storage. The manager is stopped until the restore is
+	// completed, then it resumes consuming from the transport positions in the backup. The restore runs once for each
+	// backup and point in time, remove it after the restore is completed.
+	// +optional
+	Restore *PostgresRestoreConfig `json:"restore,omitempty"`
+}
+
+// PostgresBackupConfig defines the scheduled backups of the database
+type PostgresBackupConfig struct {
+	// Schedule is the cron expression of the backups, it's in the time zone of the kube-controller-manager.
+	// +kubebuilder:default:="0 2 * * *"
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Retention is the number of the backups to keep, the oldest backups are deleted once a new backup is taken.
+	// +kubebuilder:default:=7
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retention int32 `json:"retention,omitempty"`
+
+	// Storage is where the backups are kept, one of pvc and s3 should be specified.
+	Storage BackupStorage `json:"storage"`
+}
+
+// BackupStorage is a discriminated union of the backup storages.
+type BackupStorage struct {
+	// PVC keeps the backups in a persistent volume claim.
+	// +optional
+	PVC *BackupPVCStorage `json:"pvc,omitempty"`
+
+	// S3 keeps the backups in an S3 compatible bucket.
+	// +optional
+	S3 *BackupS3Storage `json:"s3,omitempty"`
+}
+
+// BackupPVCStorage defines the persistent volume claim of the backups
+type BackupPVCStorage struct {
+	// ClaimName is an existing claim to keep the backups. If it's empty, the claim
+	// multicluster-global-hub-postgres-backup is created with the storageSize and storageClass.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// Specify the size for the created claim, the default value is 10Gi.
+	// +optional
+	StorageSize string `json:"storageSize,omitempty"`
+
+	// Specify the storageClass for the created claim, the default value is the storageClass of the data layer.
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// BackupS3Storage defines the S3 compatible bucket of the backups
+type BackupS3Storage struct {
+	// Bucket is the name of the bucket.
+	Bucket string `json:"bucket"`
+
+	// Prefix is the prefix of the backup objects in the bucket.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Endpoint is the URL of the S3 compatible storage, it's empty for AWS S3.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Region is the region of the bucket.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// ForcePathStyle uses the path style addressing, it's required by most S3 compatible storages.
+	// +optional
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
+
+	// CredentialSecret is the secret in the global hub namespace with the aws_access_key_id and
+	// aws_secret_access_key keys.
+	CredentialSecret string `json:"credentialSecret"`
+}
+
+// PostgresRestoreConfig defines the backup to restore, the latest backup is restored if both the backup and the
+// pointInTime are empty
+type PostgresRestoreConfig struct {
+	// Backup is the name of the backup to restore, e.g. globalhub-20240102T020000Z.tar.gz.
+	// +optional
+	Backup string `json:"backup,omitempty"`
+
+	// PointInTime restores the latest backup taken at or before the time, it's ignored if the backup is specified.
+	// +optional
+	PointInTime *metav1.Time `json:"pointInTime,omitempty"`
+}
+
+// KafkaConfig defines the desired state of kafka
+type KafkaConfig struct {
+	// Specify the size for storage.
+	// +optional
+	StorageSize string `json:"storageSize,omitempty"`
+}
+
+// MulticlusterGlobalHubStatus defines the observed state of MulticlusterGlobalHub
+type MulticlusterGlobalHubStatus struct {
+	// MulticlusterGlobalHubStatus defines the observed state of MulticlusterGlobalHub
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// MulticlusterGlobalHubList contains a list of MulticlusterGlobalHub
+type MulticlusterGlobalHubList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MulticlusterGlobalHub `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MulticlusterGlobalHub{}, &MulticlusterGlobalHubList{})
+}
+
+// Hub marks this type as a conversion hub.
+func (*MulticlusterGlobalHub) Hub() {}
+
+func (mgh *MulticlusterGlobalHub) GetConditions() []metav1.Condition {
+	return mgh.Status.Conditions
+}
+
+func (mgh *MulticlusterGlobalHub) SetConditions(conditions []metav1.Condition) {
+	mgh.Status.Conditions = conditions
+}
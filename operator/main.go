@@ -71,10 +71,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 	hubofhubsaddon "github.com/stolostron/multicluster-global-hub/operator/pkg/controllers/addon"
 	backupcontrollers "github.com/stolostron/multicluster-global-hub/operator/pkg/controllers/backup"
 	hubofhubscontrollers "github.com/stolostron/multicluster-global-hub/operator/pkg/controllers/hubofhubs"
+	"github.com/stolostron/multicluster-global-hub/operator/pkg/conversion"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	commonobjects "github.com/stolostron/multicluster-global-hub/pkg/objects"
 	"github.com/stolostron/multicluster-global-hub/pkg/utils"
@@ -107,6 +109,7 @@ func init() {
 	utilruntime.Must(workv1.AddToScheme(scheme))
 	utilruntime.Must(addonv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(globalhubv1alpha4.AddToScheme(scheme))
+	utilruntime.Must(globalhubv1beta1.AddToScheme(scheme))
 	utilruntime.Must(appsubv1.SchemeBuilder.AddToScheme(scheme))
 	utilruntime.Must(appsubV1alpha1.AddToScheme(scheme))
 	utilruntime.Must(subv1alpha1.AddToScheme(scheme))
@@ -236,6 +239,19 @@ func doMain(ctx context.Context, cfg *rest.Config) int {
 		return 1
 	}
 
+	// serve the conversion between the MulticlusterGlobalHub versions
+	webhookEnabled, err := conversion.SetupWebhookWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create the conversion webhook")
+		return 1
+	}
+	setupLog.Info("conversion webhook", "enabled", webhookEnabled)
+
+	if err = conversion.AddStorageVersionMigrator(mgr); err != nil {
+		setupLog.Error(err, "unable to add the storage version migrator to manager")
+		return 1
+	}
+
 	// start crd controller
 	if err = hubofhubscontrollers.StartCRDController(mgr, r); err != nil {
 		setupLog.Error(err, "unable to start crd controller")
@@ -407,6 +423,9 @@ func initCache(config *rest.Config, cacheOpts cache.Options) (cache.Cache, error
 		&admissionregistrationv1.MutatingWebhookConfiguration{}: {
 			Label: labelSelector,
 		},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{}: {
+			Label: labelSelector,
+		},
 		&promv1.ServiceMonitor{}: {
 			Label: labelSelector,
 		},
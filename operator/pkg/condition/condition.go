@@ -19,12 +19,13 @@ package condition
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 )
 
 type ICondition interface {
@@ -90,44 +91,70 @@ const (
 	CONDITION_MESSAGE_BACKUP_DISABLED = "Backup Disabled In RHACM"
 )
 
+// NOTE: the status of TransportDegraded is True when the manager falls behind the transport or any hub is lagging
+const (
+	CONDITION_TYPE_TRANSPORT_DEGRADED   = "TransportDegraded"
+	CONDITION_REASON_TRANSPORT_LAGGING  = "TransportLagging"
+	CONDITION_REASON_TRANSPORT_HEALTHY  = "TransportHealthy"
+	CONDITION_MESSAGE_TRANSPORT_UNKNOWN = "The transport health is not reported by the manager"
+)
+
+// NOTE: the DatabaseRestored condition reports the restore requested by spec.dataLayer.postgres.restore, the manager is
+// stopped while the restore is running
+const (
+	CONDITION_TYPE_DATABASE_RESTORED   = "DatabaseRestored"
+	CONDITION_REASON_RESTORE_RUNNING   = "RestoreRunning"
+	CONDITION_REASON_RESTORE_SUCCEEDED = "RestoreSucceeded"
+	CONDITION_REASON_RESTORE_FAILED    = "RestoreFailed"
+)
+
+// NOTE: the condition type is prefixed with the component, e.g. ManagerResourcesApplied. the status is False if any
+// object of the component failed to be applied, and the message lists the failed objects
+const (
+	CONDITION_TYPE_RESOURCES_APPLIED    = "ResourcesApplied"
+	CONDITION_REASON_RESOURCES_APPLIED  = "ResourcesApplied"
+	CONDITION_REASON_RESOURCES_FAILED   = "ResourcesApplyFailed"
+	CONDITION_MESSAGE_RESOURCES_APPLIED = "All the resources are applied"
+)
+
 // SetConditionFunc is function type that receives the concrete condition method
 type SetConditionFunc func(ctx context.Context, c client.Client,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	status metav1.ConditionStatus) error
 
 func FailToSetConditionError(condition string, err error) error {
 	return fmt.Errorf("failed to set condition(%s): %w", condition, err)
 }
 
-func SetConditionGrafanaAvailable(ctx context.Context, c client.Client, mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func SetConditionGrafanaAvailable(ctx context.Context, c client.Client, mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	status metav1.ConditionStatus,
 ) error {
 	return SetCondition(ctx, c, mgh, CONDITION_TYPE_GRAFANA_AVAILABLE, status, CONDITION_REASON_GRAFANA_AVAILABLE,
 		CONDITION_MESSAGE_GRAFANA_AVAILABLE)
 }
 
-func SetConditionDatabaseInit(ctx context.Context, c client.Client, mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func SetConditionDatabaseInit(ctx context.Context, c client.Client, mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	status metav1.ConditionStatus,
 ) error {
 	return SetCondition(ctx, c, mgh, CONDITION_TYPE_DATABASE_INIT, status,
 		CONDITION_REASON_DATABASE_INIT, CONDITION_MESSAGE_DATABASE_INIT)
 }
 
-func SetConditionDataRetention(ctx context.Context, c client.Client, mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func SetConditionDataRetention(ctx context.Context, c client.Client, mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	status metav1.ConditionStatus, msg string,
 ) error {
 	return SetCondition(ctx, c, mgh, CONDITION_TYPE_RETENTION_PARSED, status,
 		CONDITION_REASON_RETENTION_PARSED, msg)
 }
 
-func SetConditionManagerAvailable(ctx context.Context, c client.Client, mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func SetConditionManagerAvailable(ctx context.Context, c client.Client, mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	status metav1.ConditionStatus,
 ) error {
 	return SetCondition(ctx, c, mgh, CONDITION_TYPE_MANAGER_AVAILABLE, status,
 		CONDITION_REASON_MANAGER_AVAILABLE, CONDITION_MESSAGE_MANAGER_AVAILABLE)
 }
 
-func SetConditionLeafHubDeployed(ctx context.Context, c client.Client, mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func SetConditionLeafHubDeployed(ctx context.Context, c client.Client, mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	clusterName string, status metav1.ConditionStatus,
 ) error {
 	reason := CONDITION_REASON_LEAFHUB_DEPLOY
@@ -140,7 +167,47 @@ func SetConditionLeafHubDeployed(ctx context.Context, c client.Client, mgh *glob
 	return SetCondition(ctx, c, mgh, CONDITION_TYPE_LEAFHUB_DEPLOY, status, reason, message)
 }
 
-func SetCondition(ctx context.Context, c client.Client, mgh *globalhubv1alpha4.MulticlusterGlobalHub, typeName string,
+func SetConditionTransportDegraded(ctx context.Context, c client.Client,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub, degraded bool, msg string,
+) error {
+	if degraded {
+		return SetCondition(ctx, c, mgh, CONDITION_TYPE_TRANSPORT_DEGRADED, CONDITION_STATUS_TRUE,
+			CONDITION_REASON_TRANSPORT_LAGGING, msg)
+	}
+	return SetCondition(ctx, c, mgh, CONDITION_TYPE_TRANSPORT_DEGRADED, CONDITION_STATUS_FALSE,
+		CONDITION_REASON_TRANSPORT_HEALTHY, msg)
+}
+
+func SetConditionDatabaseRestored(ctx context.Context, c client.Client,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub, reason string, msg string,
+) error {
+	status := metav1.ConditionFalse
+	if reason == CONDITION_REASON_RESTORE_SUCCEEDED {
+		status = metav1.ConditionTrue
+	}
+	return SetCondition(ctx, c, mgh, CONDITION_TYPE_DATABASE_RESTORED, status, reason, msg)
+}
+
+// ResourcesAppliedConditionType returns the type of the ResourcesApplied condition of the component
+func ResourcesAppliedConditionType(component string) string {
+	if component == "" {
+		return CONDITION_TYPE_RESOURCES_APPLIED
+	}
+	return strings.ToUpper(component[:1]) + component[1:] + CONDITION_TYPE_RESOURCES_APPLIED
+}
+
+func SetConditionResourcesApplied(ctx context.Context, c client.Client,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub, component string, applyErr error,
+) error {
+	if applyErr != nil {
+		return SetCondition(ctx, c, mgh, ResourcesAppliedConditionType(component), CONDITION_STATUS_FALSE,
+			CONDITION_REASON_RESOURCES_FAILED, applyErr.Error())
+	}
+	return SetCondition(ctx, c, mgh, ResourcesAppliedConditionType(component), CONDITION_STATUS_TRUE,
+		CONDITION_REASON_RESOURCES_APPLIED, CONDITION_MESSAGE_RESOURCES_APPLIED)
+}
+
+func SetCondition(ctx context.Context, c client.Client, mgh *globalhubv1beta1.MulticlusterGlobalHub, typeName string,
 	status metav1.ConditionStatus, reason string, message string,
 ) error {
 	if !ContainsCondition(mgh, typeName) {
@@ -161,7 +228,7 @@ func SetCondition(ctx context.Context, c client.Client, mgh *globalhubv1alpha4.M
 	return nil
 }
 
-func ContainsCondition(mgh *globalhubv1alpha4.MulticlusterGlobalHub, typeName string) bool {
+func ContainsCondition(mgh *globalhubv1beta1.MulticlusterGlobalHub, typeName string) bool {
 	output := false
 	for _, condition := range mgh.Status.Conditions {
 		if condition.Type == typeName {
@@ -171,7 +238,7 @@ func ContainsCondition(mgh *globalhubv1alpha4.MulticlusterGlobalHub, typeName st
 	return output
 }
 
-func ContainConditionStatus(mgh *globalhubv1alpha4.MulticlusterGlobalHub, typeName string,
+func ContainConditionStatus(mgh *globalhubv1beta1.MulticlusterGlobalHub, typeName string,
 	status metav1.ConditionStatus,
 ) bool {
 	output := false
@@ -183,7 +250,7 @@ func ContainConditionStatus(mgh *globalhubv1alpha4.MulticlusterGlobalHub, typeNa
 	return output
 }
 
-func ContainConditionStatusReason(mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func ContainConditionStatusReason(mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	typeName, reason string, status metav1.ConditionStatus,
 ) bool {
 	output := false
@@ -195,7 +262,7 @@ func ContainConditionStatusReason(mgh *globalhubv1alpha4.MulticlusterGlobalHub,
 	return output
 }
 
-func ContainConditionMessage(mgh *globalhubv1alpha4.MulticlusterGlobalHub, typeName string,
+func ContainConditionMessage(mgh *globalhubv1beta1.MulticlusterGlobalHub, typeName string,
 	message string,
 ) bool {
 	output := false
@@ -207,7 +274,7 @@ func ContainConditionMessage(mgh *globalhubv1alpha4.MulticlusterGlobalHub, typeN
 	return output
 }
 
-func GetConditionStatus(mgh *globalhubv1alpha4.MulticlusterGlobalHub, typeName string) metav1.ConditionStatus {
+func GetConditionStatus(mgh *globalhubv1beta1.MulticlusterGlobalHub, typeName string) metav1.ConditionStatus {
 	var output metav1.ConditionStatus = CONDITION_STATUS_UNKNOWN
 	for _, condition := range mgh.Status.Conditions {
 		if condition.Type == typeName {
@@ -217,7 +284,7 @@ func GetConditionStatus(mgh *globalhubv1alpha4.MulticlusterGlobalHub, typeName s
 	return output
 }
 
-func DeleteCondition(ctx context.Context, c client.Client, mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func DeleteCondition(ctx context.Context, c client.Client, mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	typeName string, reason string,
 ) error {
 	newConditions := make([]metav1.Condition, 0)
@@ -234,7 +301,7 @@ func DeleteCondition(ctx context.Context, c client.Client, mgh *globalhubv1alpha
 	return nil
 }
 
-func UpdateCondition(ctx context.Context, c client.Client, mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func UpdateCondition(ctx context.Context, c client.Client, mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	cond metav1.Condition,
 ) error {
 	if ContainConditionStatusReason(mgh, cond.Type, cond.Reason, cond.Status) {
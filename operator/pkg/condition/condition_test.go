@@ -17,7 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 )
 
 var (
@@ -48,7 +48,7 @@ func TestMain(m *testing.M) {
 	}
 
 	scheme := runtime.NewScheme()
-	err = globalhubv1alpha4.AddToScheme(scheme)
+	err = globalhubv1beta1.AddToScheme(scheme)
 	if err != nil {
 		panic(err)
 	}
@@ -70,13 +70,13 @@ func TestMain(m *testing.M) {
 }
 
 func TestCondition(t *testing.T) {
-	mgh := &globalhubv1alpha4.MulticlusterGlobalHub{
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test",
 			Namespace: "default",
 		},
-		Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{
-			DataLayer: globalhubv1alpha4.DataLayerConfig{},
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+			DataLayer: globalhubv1beta1.DataLayerConfig{},
 		},
 	}
 
@@ -117,13 +117,13 @@ func TestCondition(t *testing.T) {
 }
 
 func TestRetentionCondition(t *testing.T) {
-	mgh := &globalhubv1alpha4.MulticlusterGlobalHub{
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-condition",
 			Namespace: "default",
 		},
-		Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{
-			DataLayer: globalhubv1alpha4.DataLayerConfig{},
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+			DataLayer: globalhubv1beta1.DataLayerConfig{},
 		},
 	}
 	err := runtimeClient.Create(ctx, mgh)
@@ -143,3 +143,65 @@ func TestRetentionCondition(t *testing.T) {
 	assert.True(t, ContainConditionMessage(mgh, CONDITION_TYPE_RETENTION_PARSED, "invalid retention 1s"))
 	assert.Equal(t, CONDITION_STATUS_FALSE, string(mgh.Status.Conditions[0].Status))
 }
+
+func TestTransportDegradedCondition(t *testing.T) {
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-transport-condition",
+			Namespace: "default",
+		},
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+			DataLayer: globalhubv1beta1.DataLayerConfig{},
+		},
+	}
+	err := runtimeClient.Create(ctx, mgh)
+	assert.NoError(t, err)
+
+	msg := "the manager is 1500 messages behind the transport, lagging hubs: hub1(10m0s)"
+	err = SetConditionTransportDegraded(ctx, runtimeClient, mgh, true, msg)
+	assert.NoError(t, err)
+	err = runtimeClient.Get(ctx, client.ObjectKeyFromObject(mgh), mgh)
+	assert.NoError(t, err)
+	assert.True(t, ContainConditionMessage(mgh, CONDITION_TYPE_TRANSPORT_DEGRADED, msg))
+	assert.Equal(t, CONDITION_STATUS_TRUE, string(GetConditionStatus(mgh, CONDITION_TYPE_TRANSPORT_DEGRADED)))
+
+	msg = "the manager is 0 messages behind the transport"
+	err = SetConditionTransportDegraded(ctx, runtimeClient, mgh, false, msg)
+	assert.NoError(t, err)
+	err = runtimeClient.Get(ctx, client.ObjectKeyFromObject(mgh), mgh)
+	assert.NoError(t, err)
+	assert.True(t, ContainConditionMessage(mgh, CONDITION_TYPE_TRANSPORT_DEGRADED, msg))
+	assert.Equal(t, CONDITION_STATUS_FALSE, string(GetConditionStatus(mgh, CONDITION_TYPE_TRANSPORT_DEGRADED)))
+}
+
+func TestResourcesAppliedCondition(t *testing.T) {
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-resources-condition",
+			Namespace: "default",
+		},
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+			DataLayer: globalhubv1beta1.DataLayerConfig{},
+		},
+	}
+	err := runtimeClient.Create(ctx, mgh)
+	assert.NoError(t, err)
+
+	conditionType := ResourcesAppliedConditionType("manager")
+	assert.Equal(t, "ManagerResourcesApplied", conditionType)
+
+	applyErr := fmt.Errorf("Deployment/default/multicluster-global-hub-manager: forbidden")
+	err = SetConditionResourcesApplied(ctx, runtimeClient, mgh, "manager", applyErr)
+	assert.NoError(t, err)
+	err = runtimeClient.Get(ctx, client.ObjectKeyFromObject(mgh), mgh)
+	assert.NoError(t, err)
+	assert.True(t, ContainConditionMessage(mgh, conditionType, applyErr.Error()))
+	assert.Equal(t, CONDITION_STATUS_FALSE, string(GetConditionStatus(mgh, conditionType)))
+
+	err = SetConditionResourcesApplied(ctx, runtimeClient, mgh, "manager", nil)
+	assert.NoError(t, err)
+	err = runtimeClient.Get(ctx, client.ObjectKeyFromObject(mgh), mgh)
+	assert.NoError(t, err)
+	assert.True(t, ContainConditionMessage(mgh, conditionType, CONDITION_MESSAGE_RESOURCES_APPLIED))
+	assert.Equal(t, CONDITION_STATUS_TRUE, string(GetConditionStatus(mgh, conditionType)))
+}
@@ -20,17 +20,19 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/prometheus/common/model"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/postgres"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
@@ -103,7 +105,7 @@ func GetOauthSessionSecret() (string, error) {
 }
 
 // getAnnotation returns the annotation value for a given key, or an empty string if not set
-func getAnnotation(mgh *globalhubv1alpha4.MulticlusterGlobalHub, annotationKey string) string {
+func getAnnotation(mgh *globalhubv1beta1.MulticlusterGlobalHub, annotationKey string) string {
 	annotations := mgh.GetAnnotations()
 	if annotations == nil {
 		return ""
@@ -112,51 +114,103 @@ func getAnnotation(mgh *globalhubv1alpha4.MulticlusterGlobalHub, annotationKey s
 	return annotations[annotationKey]
 }
 
-// IsPaused returns true if the MulticlusterGlobalHub instance is annotated as paused, and false otherwise
-func IsPaused(mgh *globalhubv1alpha4.MulticlusterGlobalHub) bool {
-	isPausedVal := getAnnotation(mgh, operatorconstants.AnnotationMGHPause)
-	if isPausedVal != "" && strings.EqualFold(isPausedVal, "true") {
+// IsPaused returns true if the MulticlusterGlobalHub instance is paused, and false otherwise. the mgh-pause annotation
+// is still honored for the instances which aren't converted by the webhook
+func IsPaused(mgh *globalhubv1beta1.MulticlusterGlobalHub) bool {
+	if mgh.Spec.Paused {
 		return true
 	}
-
-	return false
+	return strings.EqualFold(getAnnotation(mgh, operatorconstants.AnnotationMGHPause), "true")
 }
 
 // GetSchedulerInterval returns the scheduler interval for moving policy compliance history
-func GetSchedulerInterval(mgh *globalhubv1alpha4.MulticlusterGlobalHub) string {
+func GetSchedulerInterval(mgh *globalhubv1beta1.MulticlusterGlobalHub) string {
+	if mgh.Spec.Manager != nil && mgh.Spec.Manager.SchedulerInterval != "" {
+		return string(mgh.Spec.Manager.SchedulerInterval)
+	}
 	return getAnnotation(mgh, operatorconstants.AnnotationMGHSchedulerInterval)
 }
 
 // SkipAuth returns true to skip authenticate for non-k8s api
-func SkipAuth(mgh *globalhubv1alpha4.MulticlusterGlobalHub) bool {
-	toSkipAuth := getAnnotation(mgh, operatorconstants.AnnotationMGHSkipAuth)
-	if toSkipAuth != "" && strings.EqualFold(toSkipAuth, "true") {
+func SkipAuth(mgh *globalhubv1beta1.MulticlusterGlobalHub) bool {
+	if mgh.Spec.Manager != nil && mgh.Spec.Manager.SkipAuth {
 		return true
 	}
+	return strings.EqualFold(getAnnotation(mgh, operatorconstants.AnnotationMGHSkipAuth), "true")
+}
 
-	return false
+// EnableGitOpsStatus returns true if the agents report the status of the argo cd applications
+func EnableGitOpsStatus(mgh *globalhubv1beta1.MulticlusterGlobalHub) bool {
+	if mgh.Spec.EnableGitOpsStatus {
+		return true
+	}
+	return strings.EqualFold(getAnnotation(mgh, operatorconstants.AnnotationMGHEnableGitOpsStatus), "true")
 }
 
-func GetInstallCrunchyOperator(mgh *globalhubv1alpha4.MulticlusterGlobalHub) bool {
-	toInstallCrunchyOperator := getAnnotation(mgh, operatorconstants.AnnotationMGHInstallCrunchyOperator)
-	if toInstallCrunchyOperator != "" && strings.EqualFold(toInstallCrunchyOperator, "true") {
+// EnableStatusSharding returns true if the managed hubs are sharded across the manager replicas to process the status
+func EnableStatusSharding(mgh *globalhubv1beta1.MulticlusterGlobalHub) bool {
+	if mgh.Spec.Manager != nil && mgh.Spec.Manager.StatusSharding {
 		return true
 	}
+	return strings.EqualFold(getAnnotation(mgh, operatorconstants.AnnotationMGHEnableStatusSharding), "true")
+}
 
-	return false
+// GetRedactionRules returns the json rules to redact the sensitive fields of the objects reported by the agents
+func GetRedactionRules(mgh *globalhubv1beta1.MulticlusterGlobalHub) (string, error) {
+	if len(mgh.Spec.RedactionRules) > 0 {
+		rules, err := json.Marshal(mgh.Spec.RedactionRules)
+		if err != nil {
+			return "", err
+		}
+		return string(rules), nil
+	}
+	return getAnnotation(mgh, operatorconstants.AnnotationMGHRedactionRules), nil
+}
+
+// GetPayloadEncryptionSecret returns the secret to encrypt the fields of the payloads stored by the manager
+func GetPayloadEncryptionSecret(mgh *globalhubv1beta1.MulticlusterGlobalHub) string {
+	if mgh.Spec.Manager != nil && mgh.Spec.Manager.PayloadEncryptionSecret != "" {
+		return mgh.Spec.Manager.PayloadEncryptionSecret
+	}
+	return getAnnotation(mgh, operatorconstants.AnnotationMGHPayloadEncryptionSecret)
+}
+
+func GetInstallCrunchyOperator(mgh *globalhubv1beta1.MulticlusterGlobalHub) bool {
+	if mgh.Spec.InstallCrunchyOperator {
+		return true
+	}
+	return strings.EqualFold(getAnnotation(mgh, operatorconstants.AnnotationMGHInstallCrunchyOperator), "true")
 }
 
 // GetLaunchJobNames returns the jobs concatenated using "," wchich will run once the constainer is started
-func GetLaunchJobNames(mgh *globalhubv1alpha4.MulticlusterGlobalHub) string {
+func GetLaunchJobNames(mgh *globalhubv1beta1.MulticlusterGlobalHub) string {
+	if mgh.Spec.Manager != nil && len(mgh.Spec.Manager.LaunchJobs) > 0 {
+		jobs := make([]string, 0, len(mgh.Spec.Manager.LaunchJobs))
+		for _, job := range mgh.Spec.Manager.LaunchJobs {
+			jobs = append(jobs, string(job))
+		}
+		return strings.Join(jobs, ",")
+	}
 	return getAnnotation(mgh, operatorconstants.AnnotationLaunchJobNames)
 }
 
-// GetImageOverridesConfigmap returns the images override configmap annotation, or an empty string if not set
-func GetImageOverridesConfigmap(mgh *globalhubv1alpha4.MulticlusterGlobalHub) string {
+// GetImageOverridesConfigmap returns the images override configmap, or an empty string if not set
+func GetImageOverridesConfigmap(mgh *globalhubv1beta1.MulticlusterGlobalHub) string {
+	if mgh.Spec.ImageOverridesConfigMap != "" {
+		return mgh.Spec.ImageOverridesConfigMap
+	}
 	return getAnnotation(mgh, operatorconstants.AnnotationImageOverridesCM)
 }
 
-func SetImageOverrides(mgh *globalhubv1alpha4.MulticlusterGlobalHub) error {
+// getImageRepository returns the repository to override the images, or an empty string if not set
+func getImageRepository(mgh *globalhubv1beta1.MulticlusterGlobalHub) string {
+	if mgh.Spec.ImageRepository != "" {
+		return mgh.Spec.ImageRepository
+	}
+	return getAnnotation(mgh, operatorconstants.AnnotationImageRepo)
+}
+
+func SetImageOverrides(mgh *globalhubv1beta1.MulticlusterGlobalHub) error {
 	// first check for environment variables containing the 'RELATED_IMAGE_' prefix
 	for _, env := range os.Environ() {
 		envKeyVal := strings.SplitN(env, "=", 2)
@@ -168,7 +222,7 @@ func SetImageOverrides(mgh *globalhubv1alpha4.MulticlusterGlobalHub) error {
 	}
 
 	// second override image repo
-	imageRepoOverride := getAnnotation(mgh, operatorconstants.AnnotationImageRepo)
+	imageRepoOverride := getImageRepository(mgh)
 	if imageRepoOverride != "" {
 		for imageKey, imageRef := range imageOverrides {
 			imageIndex := strings.LastIndex(imageRef, "/")
@@ -206,7 +260,11 @@ func GetManagedClusters() []string {
 	return managedClusters
 }
 
-func SetStatisticLogInterval(mgh *globalhubv1alpha4.MulticlusterGlobalHub) error {
+func SetStatisticLogInterval(mgh *globalhubv1beta1.MulticlusterGlobalHub) error {
+	if mgh.Spec.Manager != nil && mgh.Spec.Manager.StatisticInterval != nil {
+		statisticLogInterval = mgh.Spec.Manager.StatisticInterval.Duration.String()
+		return nil
+	}
 	interval := getAnnotation(mgh, operatorconstants.AnnotationStatisticInterval)
 	if interval == "" {
 		return nil
@@ -224,7 +282,11 @@ func GetStatisticLogInterval() string {
 	return statisticLogInterval
 }
 
-func GetMetricsScrapeInterval(mgh *globalhubv1alpha4.MulticlusterGlobalHub) string {
+func GetMetricsScrapeInterval(mgh *globalhubv1beta1.MulticlusterGlobalHub) string {
+	if mgh.Spec.MetricsScrapeInterval != nil {
+		// the prometheus duration doesn't support the fractions, e.g. 1.5s
+		return model.Duration(mgh.Spec.MetricsScrapeInterval.Duration).String()
+	}
 	interval := getAnnotation(mgh, operatorconstants.AnnotationMetricsScrapeInterval)
 	if interval == "" {
 		interval = metricsScrapeInterval
@@ -232,14 +294,23 @@ func GetMetricsScrapeInterval(mgh *globalhubv1alpha4.MulticlusterGlobalHub) stri
 	return interval
 }
 
-func GetPostgresStorageSize(mgh *globalhubv1alpha4.MulticlusterGlobalHub) string {
+func GetPostgresStorageSize(mgh *globalhubv1beta1.MulticlusterGlobalHub) string {
 	if mgh.Spec.DataLayer.Postgres.StorageSize != "" {
 		return mgh.Spec.DataLayer.Postgres.StorageSize
 	}
 	return GHPostgresDefaultStorageSize
 }
 
-func GetKafkaStorageSize(mgh *globalhubv1alpha4.MulticlusterGlobalHub) string {
+func GetPostgresBackupStorageSize(mgh *globalhubv1beta1.MulticlusterGlobalHub) string {
+	defaultBackupStorageSize := "10Gi"
+	backup := mgh.Spec.DataLayer.Postgres.Backup
+	if backup != nil && backup.Storage.PVC != nil && backup.Storage.PVC.StorageSize != "" {
+		return backup.Storage.PVC.StorageSize
+	}
+	return defaultBackupStorageSize
+}
+
+func GetKafkaStorageSize(mgh *globalhubv1beta1.MulticlusterGlobalHub) string {
 	defaultKafkaStorageSize := "10Gi"
 	if mgh.Spec.DataLayer.Kafka.StorageSize != "" {
 		return mgh.Spec.DataLayer.Kafka.StorageSize
@@ -247,7 +318,7 @@ func GetKafkaStorageSize(mgh *globalhubv1alpha4.MulticlusterGlobalHub) string {
 	return defaultKafkaStorageSize
 }
 
-func SetImagePullSecretName(mgh *globalhubv1alpha4.MulticlusterGlobalHub) {
+func SetImagePullSecretName(mgh *globalhubv1beta1.MulticlusterGlobalHub) {
 	if mgh.Spec.ImagePullSecret != imagePullSecretName {
 		imagePullSecretName = mgh.Spec.ImagePullSecret
 	}
@@ -280,7 +351,10 @@ func GetPGConnectionFromGHStorageSecret(ctx context.Context, client client.Clien
 	return &postgres.PostgresConnection{
 		SuperuserDatabaseURI:    string(pgSecret.Data["database_uri"]),
 		ReadonlyUserDatabaseURI: string(pgSecret.Data["database_uri_with_readonlyuser"]),
-		CACert:                  pgSecret.Data["ca.crt"],
+		// the read replica is optional
+		ReplicaDatabaseURI:             string(pgSecret.Data["database_replica_uri"]),
+		ReplicaReadonlyUserDatabaseURI: string(pgSecret.Data["database_replica_uri_with_readonlyuser"]),
+		CACert:                         pgSecret.Data["ca.crt"],
 	}, nil
 }
 
@@ -19,10 +19,11 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/utils"
 )
@@ -33,7 +34,7 @@ func TestSetImageOverrides(t *testing.T) {
 		desc               string
 		initImageManifests map[string]string
 		operandImagesEnv   map[string]string
-		mghInstance        *globalhubv1alpha4.MulticlusterGlobalHub
+		mghInstance        *globalhubv1beta1.MulticlusterGlobalHub
 		wantImageManifests map[string]string
 		wantErr            error
 	}{
@@ -49,12 +50,12 @@ func TestSetImageOverrides(t *testing.T) {
 				"RELATED_IMAGE_MULTICLUSTER_GLOBAL_HUB_AGENT":   "quay.io/stolostron/multicluster-global-hub-agent:v0.6.0",
 				"RELATED_IMAGE_OAUTH_PROXY":                     "quay.io/stolostron/origin-oauth-proxy:4.9",
 			},
-			mghInstance: &globalhubv1alpha4.MulticlusterGlobalHub{
+			mghInstance: &globalhubv1beta1.MulticlusterGlobalHub{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: utils.GetDefaultNamespace(),
 					Name:      mghInstanceName,
 				},
-				Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+				Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 			},
 			wantImageManifests: map[string]string{
 				"multicluster_global_hub_agent":   "quay.io/stolostron/multicluster-global-hub-agent:v0.6.0",
@@ -75,7 +76,7 @@ func TestSetImageOverrides(t *testing.T) {
 				"RELATED_IMAGE_MULTICLUSTER_GLOBAL_HUB_AGENT":   "quay.io/stolostron/multicluster-global-hub-agent:v0.6.0",
 				"RELATED_IMAGE_OAUTH_PROXY":                     "quay.io/stolostron/origin-oauth-proxy:4.9",
 			},
-			mghInstance: &globalhubv1alpha4.MulticlusterGlobalHub{
+			mghInstance: &globalhubv1beta1.MulticlusterGlobalHub{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: utils.GetDefaultNamespace(),
 					Name:      mghInstanceName,
@@ -83,7 +84,7 @@ func TestSetImageOverrides(t *testing.T) {
 						operatorconstants.AnnotationImageRepo: "quay.io/testing",
 					},
 				},
-				Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+				Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 			},
 			wantImageManifests: map[string]string{
 				"multicluster_global_hub_agent":   "quay.io/testing/multicluster-global-hub-agent:v0.6.0",
@@ -124,3 +125,66 @@ func TestGetOauthSessionSecret(t *testing.T) {
 		t.Errorf("oauth session secret is not consistent")
 	}
 }
+
+func TestSpecFieldsOverAnnotations(t *testing.T) {
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: utils.GetDefaultNamespace(),
+			Name:      "multiclusterglobalhub",
+			Annotations: map[string]string{
+				operatorconstants.AnnotationMGHSchedulerInterval: "hour",
+				operatorconstants.AnnotationImageOverridesCM:     "annotation-cm",
+			},
+		},
+	}
+	if IsPaused(mgh) || SkipAuth(mgh) || GetInstallCrunchyOperator(mgh) {
+		t.Errorf("expect the instance isn't paused, doesn't skip auth and doesn't install crunchy operator")
+	}
+	if interval := GetSchedulerInterval(mgh); interval != "hour" {
+		t.Errorf("expect the scheduler interval from the annotation, but got %s", interval)
+	}
+
+	mgh.Spec.Paused = true
+	mgh.Spec.InstallCrunchyOperator = true
+	mgh.Spec.ImageOverridesConfigMap = "spec-cm"
+	mgh.Spec.Manager = &globalhubv1beta1.ManagerConfig{
+		SkipAuth:          true,
+		SchedulerInterval: globalhubv1beta1.EveryMinute,
+		LaunchJobs: []globalhubv1beta1.LaunchJob{
+			globalhubv1beta1.DataRetentionJob, globalhubv1beta1.LocalComplianceHistoryJob,
+		},
+	}
+	if !IsPaused(mgh) || !SkipAuth(mgh) || !GetInstallCrunchyOperator(mgh) {
+		t.Errorf("expect the instance is paused, skips auth and installs crunchy operator")
+	}
+	if interval := GetSchedulerInterval(mgh); interval != "minute" {
+		t.Errorf("expect the scheduler interval from the spec, but got %s", interval)
+	}
+	if cm := GetImageOverridesConfigmap(mgh); cm != "spec-cm" {
+		t.Errorf("expect the image overrides configmap from the spec, but got %s", cm)
+	}
+	if jobs := GetLaunchJobNames(mgh); jobs != "data-retention,local-compliance-history" {
+		t.Errorf("expect the launch jobs from the spec, but got %s", jobs)
+	}
+
+	mgh.Spec.EnableGitOpsStatus = true
+	mgh.Spec.MetricsScrapeInterval = &metav1.Duration{Duration: 90 * time.Second}
+	mgh.Spec.RedactionRules = map[string][]string{"Secret": {"$.data"}}
+	mgh.Spec.Manager.StatisticInterval = &metav1.Duration{Duration: 30 * time.Second}
+	mgh.Spec.Manager.PayloadEncryptionSecret = "payload-encryption"
+	if !EnableGitOpsStatus(mgh) {
+		t.Errorf("expect the gitops status is enabled by the spec")
+	}
+	if interval := GetMetricsScrapeInterval(mgh); interval != "1m30s" {
+		t.Errorf("expect the metrics scrape interval from the spec, but got %s", interval)
+	}
+	if rules, err := GetRedactionRules(mgh); err != nil || rules != `{"Secret":["$.data"]}` {
+		t.Errorf("expect the redaction rules from the spec, but got %s, %v", rules, err)
+	}
+	if secret := GetPayloadEncryptionSecret(mgh); secret != "payload-encryption" {
+		t.Errorf("expect the payload encryption secret from the spec, but got %s", secret)
+	}
+	if err := SetStatisticLogInterval(mgh); err != nil || GetStatisticLogInterval() != "30s" {
+		t.Errorf("expect the statistic interval from the spec, but got %s, %v", GetStatisticLogInterval(), err)
+	}
+}
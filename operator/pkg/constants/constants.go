@@ -48,6 +48,16 @@ const (
 	AnnotationMGHSkipAuth = "mgh-skip-auth"
 	// AnnotationMGHInstallCrunchyOperator installs crunchy operator to provide postgres
 	AnnotationMGHInstallCrunchyOperator = "mgh-install-crunchy-operator"
+	// AnnotationMGHEnableGitOpsStatus reports the status of the argo cd applications from the managed hubs
+	AnnotationMGHEnableGitOpsStatus = "mgh-enable-gitops-status"
+	// AnnotationMGHEnableStatusSharding processes the status on all the manager replicas, which split the managed hubs
+	AnnotationMGHEnableStatusSharding = "mgh-enable-status-sharding"
+	// AnnotationMGHRedactionRules redacts the sensitive fields of the objects reported by the agents, the value maps
+	// the kind to the json paths of the fields, e.g. {"Policy": ["$.spec.policy-templates[*].objectDefinition.data"]}
+	AnnotationMGHRedactionRules = "mgh-redaction-rules"
+	// AnnotationMGHPayloadEncryptionSecret is the secret of the manager to encrypt the fields of the stored payloads,
+	// the "key" of the secret is the AES key and the "rules" maps the table to the json paths of the fields
+	AnnotationMGHPayloadEncryptionSecret = "mgh-payload-encryption-secret"
 	// AnnotationMGHSchedulerInterval sits in MulticlusterGlobalHub annotations
 	// to identify the scheduler interval for moving policy compliance history
 	// valid value can be "month, week, day, hour, minute, second"
@@ -58,6 +68,11 @@ const (
 	AnnotationStatisticInterval = "mgh-statistic-interval"
 	// AnnotationMetricsScrapeInterval to set the scrape interval for metrics
 	AnnotationMetricsScrapeInterval = "mgh-metrics-scrape-interval"
+	// AnnotationMGHAdvancedConfig keeps the advanced config fields which don't exist in v1alpha4, so that they aren't
+	// lost when the instance is updated with v1alpha4
+	AnnotationMGHAdvancedConfig = "mgh-advanced-config"
+	// AnnotationMGHPostgresBackup keeps the backup and restore config of the database which don't exist in v1alpha4
+	AnnotationMGHPostgresBackup = "mgh-postgres-backup"
 	// AnnotationONMulticlusterHub indicates the addons are running on a hub cluster
 	AnnotationONMulticlusterHub = "addon.open-cluster-management.io/on-multicluster-hub"
 	// AnnotationPolicyONMulticlusterHub indicates the policy spec sync is running on a hub cluster
@@ -109,8 +124,20 @@ const (
 
 	// GHAgentInstallACMHubLabelKey is to indicate whether to install ACM hub on the agent
 	GHAgentACMHubInstallLabelKey = "global-hub.open-cluster-management.io/hub-cluster-install"
+
+	// AnnotationStatusFilterPrefix is the prefix of the annotations to filter the resources reported by the agent,
+	// e.g. "status-filter.global-hub.open-cluster-management.io/managedClusters.labelSelector: ci!=true"
+	AnnotationStatusFilterPrefix = "status-filter.global-hub.open-cluster-management.io/"
 )
 
+// StatusFilterKeys are the status filters supported by the agent config
+var StatusFilterKeys = []string{
+	"managedClusters.labelSelector",
+	"managedClusters.clusterSets",
+	"policies.labelSelector",
+	"policies.namespaces",
+}
+
 // AggregationLevel specifies the level of aggregation leaf hubs should do before sending the information
 // Enum=full;minimal
 type AggregationLevel string
@@ -20,7 +20,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 	hubofhubs "github.com/stolostron/multicluster-global-hub/operator/pkg/controllers/hubofhubs"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/utils"
@@ -81,7 +81,7 @@ func NewHoHAddonController(kubeConfig *rest.Config, client client.Client,
 func (a *HoHAddonController) Start(ctx context.Context) error {
 	addonScheme := runtime.NewScheme()
 	utilruntime.Must(mchv1.AddToScheme(addonScheme))
-	utilruntime.Must(globalhubv1alpha4.AddToScheme(addonScheme))
+	utilruntime.Must(globalhubv1beta1.AddToScheme(addonScheme))
 	utilruntime.Must(operatorsv1.AddToScheme(addonScheme))
 	utilruntime.Must(operatorsv1alpha1.AddToScheme(addonScheme))
 
@@ -20,7 +20,7 @@ import (
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 	hubofhubscontroller "github.com/stolostron/multicluster-global-hub/operator/pkg/controllers/hubofhubs"
@@ -66,13 +66,18 @@ type ManifestsConfig struct {
 	Tolerations            []corev1.Toleration
 	AggregationLevel       string
 	EnableLocalPolicies    string
+	StatusFilters          map[string]string
+	RedactionRules         string
 	EnableGlobalResource   bool
+	EnableGitOpsStatus     bool
 	AgentQPS               float32
 	AgentBurst             int
 	LogLevel               string
 	// cannot use *corev1.ResourceRequirements, addonfactory.StructToValues removes the real value
 	Resources              *Resources
 	AgentHeartbeatInterval string
+	Replicas               int32
+	PodSpec                *utils.PodSpecValues
 }
 
 type Resources struct {
@@ -96,8 +101,8 @@ type HohAgentAddon struct {
 	LogLevel             string
 }
 
-func (a *HohAgentAddon) getMulticlusterGlobalHub() (*globalhubv1alpha4.MulticlusterGlobalHub, error) {
-	mghList := &globalhubv1alpha4.MulticlusterGlobalHubList{}
+func (a *HohAgentAddon) getMulticlusterGlobalHub() (*globalhubv1beta1.MulticlusterGlobalHub, error) {
+	mghList := &globalhubv1beta1.MulticlusterGlobalHubList{}
 	err := a.client.List(a.ctx, mghList)
 	if err != nil {
 		return nil, err
@@ -148,6 +153,17 @@ func (a *HohAgentAddon) setInstallHostedMode(cluster *clusterv1.ManagedCluster,
 	manifestsConfig.KlusterletWorkSA = fmt.Sprintf("klusterlet-%s-work-sa", cluster.GetName())
 }
 
+// getStatusFilters returns the status filters of the agent from the annotations of the managed hub
+func getStatusFilters(cluster *clusterv1.ManagedCluster) map[string]string {
+	filters := map[string]string{}
+	for _, key := range operatorconstants.StatusFilterKeys {
+		if value := cluster.GetAnnotations()[operatorconstants.AnnotationStatusFilterPrefix+key]; value != "" {
+			filters[key] = value
+		}
+	}
+	return filters
+}
+
 func (a *HohAgentAddon) setACMPackageConfigs(manifestsConfig *ManifestsConfig) error {
 	pm, err := GetPackageManifestConfig(a.ctx, a.dynamicClient)
 	if err != nil {
@@ -210,6 +226,11 @@ func (a *HohAgentAddon) GetValues(cluster *clusterv1.ManagedCluster,
 		log.Error(err, "failed to unmarshal to agent resources")
 	}
 
+	podSpec, err := utils.GetPodSpecValues(operatorconstants.Agent, mgh.Spec.AdvancedConfig, "name")
+	if err != nil {
+		return nil, err
+	}
+
 	manifestsConfig := ManifestsConfig{
 		HoHAgentImage:          image,
 		ImagePullPolicy:        string(imagePullPolicy),
@@ -229,11 +250,14 @@ func (a *HohAgentAddon) GetValues(cluster *clusterv1.ManagedCluster,
 		KlusterletNamespace:    "open-cluster-management-agent",
 		KlusterletWorkSA:       "klusterlet-work-sa",
 		EnableGlobalResource:   a.EnableGlobalResource,
+		EnableGitOpsStatus:     config.EnableGitOpsStatus(mgh),
 		AgentQPS:               agentQPS,
 		AgentBurst:             agentBurst,
 		LogLevel:               a.LogLevel,
 		Resources:              agentRes,
 		AgentHeartbeatInterval: config.AgentHeartbeatInterval,
+		Replicas:               utils.GetReplicas(operatorconstants.Agent, mgh.Spec.AdvancedConfig, 1),
+		PodSpec:                podSpec,
 	}
 
 	if err := a.setImagePullSecret(mgh, cluster, &manifestsConfig); err != nil {
@@ -244,6 +268,19 @@ func (a *HohAgentAddon) GetValues(cluster *clusterv1.ManagedCluster,
 
 	manifestsConfig.AggregationLevel = config.AggregationLevel
 	manifestsConfig.EnableLocalPolicies = config.EnableLocalPolicies
+	manifestsConfig.StatusFilters = getStatusFilters(cluster)
+	rules, err := config.GetRedactionRules(mgh)
+	if err != nil {
+		return nil, err
+	}
+	if rules != "" {
+		// the json string is a valid yaml scalar, it keeps the multiple lines rules in the configmap
+		quoted, err := json.Marshal(rules)
+		if err != nil {
+			return nil, err
+		}
+		manifestsConfig.RedactionRules = string(quoted)
+	}
 
 	if a.installACMHub(cluster) {
 		manifestsConfig.InstallACMHub = true
@@ -253,8 +290,8 @@ func (a *HohAgentAddon) GetValues(cluster *clusterv1.ManagedCluster,
 		}
 	}
 
-	manifestsConfig.Tolerations = mgh.Spec.Tolerations
-	manifestsConfig.NodeSelector = mgh.Spec.NodeSelector
+	manifestsConfig.Tolerations = utils.GetTolerations(operatorconstants.Agent, mgh)
+	manifestsConfig.NodeSelector = utils.GetNodeSelector(operatorconstants.Agent, mgh)
 
 	a.setInstallHostedMode(cluster, &manifestsConfig)
 
@@ -291,7 +328,7 @@ func (a *HohAgentAddon) getAgentRestConfig(configMap *corev1.ConfigMap) (float32
 }
 
 // GetImagePullSecret returns the image pull secret name and data
-func (a *HohAgentAddon) setImagePullSecret(mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func (a *HohAgentAddon) setImagePullSecret(mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	cluster *clusterv1.ManagedCluster, manifestsConfig *ManifestsConfig,
 ) error {
 	imagePullSecret := &corev1.Secret{}
@@ -322,7 +359,7 @@ func (a *HohAgentAddon) setImagePullSecret(mgh *globalhubv1alpha4.MulticlusterGl
 	return nil
 }
 
-func (a *HohAgentAddon) getOverrideImage(mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func (a *HohAgentAddon) getOverrideImage(mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	cluster *clusterv1.ManagedCluster,
 ) (string, error) {
 	// image registry override by operator environment variable and mgh annotation
@@ -2,10 +2,12 @@ package addon
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
 
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 )
@@ -98,3 +100,40 @@ func TestHohAgentAddon_getAgentRestConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_getStatusFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]string
+	}{
+		{
+			name: "no status filters",
+			want: map[string]string{},
+		},
+		{
+			name: "the supported status filters",
+			annotations: map[string]string{
+				operatorconstants.AnnotationStatusFilterPrefix + "managedClusters.labelSelector": "ci!=true",
+				operatorconstants.AnnotationStatusFilterPrefix + "policies.namespaces":           "default,policies",
+				operatorconstants.AnnotationStatusFilterPrefix + "policies.labelSelector":        "",
+				// the sync interval of the agent config can't be overridden
+				operatorconstants.AnnotationStatusFilterPrefix + "policies": "1s",
+			},
+			want: map[string]string{
+				"managedClusters.labelSelector": "ci!=true",
+				"policies.namespaces":           "default,policies",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &clusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "hub1", Annotations: tt.annotations},
+			}
+			if got := getStatusFilters(cluster); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getStatusFilters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -23,7 +23,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	operatorv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	operatorv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
@@ -89,13 +89,13 @@ func fakeHoHManagementAddon() *v1alpha1.ClusterManagementAddOn {
 	}
 }
 
-func fakeMGH(namespace, name string) *operatorv1alpha4.MulticlusterGlobalHub {
-	return &operatorv1alpha4.MulticlusterGlobalHub{
+func fakeMGH(namespace, name string) *operatorv1beta1.MulticlusterGlobalHub {
+	return &operatorv1beta1.MulticlusterGlobalHub{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
 		},
-		Status: operatorv1alpha4.MulticlusterGlobalHubStatus{
+		Status: operatorv1beta1.MulticlusterGlobalHubStatus{
 			Conditions: []metav1.Condition{
 				{
 					Type:   condition.CONDITION_TYPE_GLOBALHUB_READY,
@@ -128,7 +128,7 @@ func TestHoHAddonReconciler(t *testing.T) {
 	addonTestScheme := scheme.Scheme
 	utilruntime.Must(v1.AddToScheme(addonTestScheme))
 	utilruntime.Must(v1alpha1.AddToScheme(addonTestScheme))
-	utilruntime.Must(operatorv1alpha4.AddToScheme(addonTestScheme))
+	utilruntime.Must(operatorv1beta1.AddToScheme(addonTestScheme))
 	utilruntime.Must(kafkav1beta2.AddToScheme(addonTestScheme))
 
 	namespace := "default"
@@ -142,7 +142,7 @@ func TestHoHAddonReconciler(t *testing.T) {
 		name            string
 		cluster         *v1.ManagedCluster
 		managementAddon *v1alpha1.ClusterManagementAddOn
-		mgh             *operatorv1alpha4.MulticlusterGlobalHub
+		mgh             *operatorv1beta1.MulticlusterGlobalHub
 		addon           *v1alpha1.ManagedClusterAddOn
 		req             reconcile.Request
 		validateFunc    func(t *testing.T, addon *v1alpha1.ManagedClusterAddOn, err error)
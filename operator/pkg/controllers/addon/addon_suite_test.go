@@ -49,7 +49,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
@@ -121,7 +121,7 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	err = placementrulesv1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
-	err = globalhubv1alpha4.AddToScheme(scheme.Scheme)
+	err = globalhubv1beta1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 	err = appsv1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
@@ -204,15 +204,15 @@ const (
 	interval = time.Millisecond * 250
 )
 
-var mgh = &globalhubv1alpha4.MulticlusterGlobalHub{
+var mgh = &globalhubv1beta1.MulticlusterGlobalHub{
 	ObjectMeta: metav1.ObjectMeta{
 		Name: MGHName,
 	},
-	Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{
+	Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
 		ImagePullSecret: "test-pull-secret",
-		DataLayer:       globalhubv1alpha4.DataLayerConfig{},
+		DataLayer:       globalhubv1beta1.DataLayerConfig{},
 	},
-	Status: globalhubv1alpha4.MulticlusterGlobalHubStatus{
+	Status: globalhubv1beta1.MulticlusterGlobalHubStatus{
 		Conditions: []metav1.Condition{
 			{
 				Type:   condition.CONDITION_TYPE_GLOBALHUB_READY,
@@ -246,7 +246,7 @@ func prepareBeforeTest() {
 	// 	After creating this MGH instance, check that the MGH instance's Spec fields are failed with default values.
 	mghLookupKey := types.NamespacedName{Namespace: utils.GetDefaultNamespace(), Name: MGHName}
 	config.SetMGHNamespacedName(mghLookupKey)
-	createdMGH := &globalhubv1alpha4.MulticlusterGlobalHub{}
+	createdMGH := &globalhubv1beta1.MulticlusterGlobalHub{}
 
 	// get this newly created MGH instance, given that creation may not immediately happen.
 	Eventually(func() bool {
@@ -26,7 +26,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	"github.com/stolostron/multicluster-global-hub/pkg/utils"
@@ -59,7 +59,7 @@ var allResourcesBackup = map[string]Backup{
 // In the reconcile, we identy the request kind and get it by request.Name.
 func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// mgh is used to update backup condition
-	mghList := &globalhubv1alpha4.MulticlusterGlobalHubList{}
+	mghList := &globalhubv1beta1.MulticlusterGlobalHubList{}
 	err := r.Client.List(ctx, mghList)
 	if err != nil {
 		klog.Error(err, "Failed to list MulticlusterGlobalHub")
@@ -111,7 +111,7 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 }
 
 func addDisableCondition(ctx context.Context, client client.Client,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub, err error,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub, err error,
 ) (ctrl.Result, error) {
 	msg := condition.CONDITION_MESSAGE_BACKUP_DISABLED
 	if err != nil {
@@ -129,7 +129,7 @@ func addDisableCondition(ctx context.Context, client client.Client,
 }
 
 func addBackupCondition(ctx context.Context, client client.Client,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub, err error,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub, err error,
 ) (ctrl.Result, error) {
 	msg := condition.CONDITION_MESSAGE_BACKUP
 	if err != nil {
@@ -22,7 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/utils"
 )
@@ -46,12 +46,12 @@ func (r *mghBackup) AddLabelToOneObj(ctx context.Context,
 	client client.Client,
 	namespace, name string,
 ) error {
-	obj := &globalhubv1alpha4.MulticlusterGlobalHub{}
+	obj := &globalhubv1beta1.MulticlusterGlobalHub{}
 	return utils.AddLabel(ctx, client, obj, namespace, name, r.labelKey, r.labelValue)
 }
 
 func (r *mghBackup) AddLabelToAllObjs(ctx context.Context, c client.Client, namespace string) error {
-	mghList := &globalhubv1alpha4.MulticlusterGlobalHubList{}
+	mghList := &globalhubv1beta1.MulticlusterGlobalHubList{}
 	err := c.List(ctx, mghList, &client.ListOptions{
 		Namespace: namespace,
 	})
@@ -62,7 +62,7 @@ func (r *mghBackup) AddLabelToAllObjs(ctx context.Context, c client.Client, name
 		if utils.HasLabel(mgh.GetLabels(), r.labelKey, r.labelValue) {
 			continue
 		}
-		obj := &globalhubv1alpha4.MulticlusterGlobalHub{}
+		obj := &globalhubv1beta1.MulticlusterGlobalHub{}
 		err := utils.AddLabel(ctx, c, obj, namespace, mgh.Name, r.labelKey, r.labelValue)
 		if err != nil {
 			return err
@@ -72,7 +72,7 @@ func (r *mghBackup) AddLabelToAllObjs(ctx context.Context, c client.Client, name
 }
 
 func (r *mghBackup) DeleteLabelOfAllObjs(ctx context.Context, c client.Client, namespace string) error {
-	mghList := &globalhubv1alpha4.MulticlusterGlobalHubList{}
+	mghList := &globalhubv1beta1.MulticlusterGlobalHubList{}
 	err := c.List(ctx, mghList, &client.ListOptions{
 		Namespace: namespace,
 	})
@@ -83,7 +83,7 @@ func (r *mghBackup) DeleteLabelOfAllObjs(ctx context.Context, c client.Client, n
 		if !utils.HasLabel(mgh.GetLabels(), r.labelKey, r.labelValue) {
 			continue
 		}
-		obj := &globalhubv1alpha4.MulticlusterGlobalHub{}
+		obj := &globalhubv1beta1.MulticlusterGlobalHub{}
 		err := utils.DeleteLabel(ctx, c, obj, namespace, mgh.Name, r.labelKey)
 		if err != nil {
 			return err
@@ -34,7 +34,7 @@ import (
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
@@ -52,12 +52,12 @@ const (
 	mchNamespace = "open-cluster-management"
 )
 
-var mghObj = &globalhubv1alpha4.MulticlusterGlobalHub{
+var mghObj = &globalhubv1beta1.MulticlusterGlobalHub{
 	ObjectMeta: metav1.ObjectMeta{
 		Name:      mghName,
 		Namespace: mghNamespace,
 	},
-	Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+	Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 }
 
 var mchObj = &mchv1.MultiClusterHub{
@@ -110,7 +110,7 @@ var _ = Describe("Backup controller", Ordered, func() {
 		It("Should create the MGH instance with backup label", func() {
 			Expect(k8sClient.Create(ctx, mghObj)).Should(Succeed())
 			Eventually(func() bool {
-				mgh := &globalhubv1alpha4.MulticlusterGlobalHub{}
+				mgh := &globalhubv1beta1.MulticlusterGlobalHub{}
 				Expect(k8sClient.Get(ctx, types.NamespacedName{
 					Namespace: mghNamespace,
 					Name:      mghName,
@@ -131,7 +131,7 @@ var _ = Describe("Backup controller", Ordered, func() {
 		})
 
 		It("update the mgh label, it should be reconciled", func() {
-			mgh := &globalhubv1alpha4.MulticlusterGlobalHub{}
+			mgh := &globalhubv1beta1.MulticlusterGlobalHub{}
 			Expect(k8sClient.Get(ctx, types.NamespacedName{
 				Namespace: mghNamespace,
 				Name:      mghName,
@@ -152,7 +152,7 @@ var _ = Describe("Backup controller", Ordered, func() {
 			// Disable backup
 			disableBackup()
 			Eventually(func() bool {
-				mgh := &globalhubv1alpha4.MulticlusterGlobalHub{}
+				mgh := &globalhubv1beta1.MulticlusterGlobalHub{}
 				Expect(k8sClient.Get(ctx, types.NamespacedName{
 					Namespace: mghNamespace,
 					Name:      mghName,
@@ -70,6 +70,22 @@ CREATE TABLE IF NOT EXISTS history.subscriptions (
     deleted boolean DEFAULT false NOT NULL
 );
 
+CREATE TABLE IF NOT EXISTS history.global_resource_drifts (
+    leaf_hub_name character varying(254) NOT NULL,
+    api_version character varying(254) NOT NULL,
+    kind character varying(254) NOT NULL,
+    namespace character varying(254) NOT NULL DEFAULT '',
+    name character varying(254) NOT NULL,
+    -- the paths of the fields which are different from the spec of the global hub
+    field_paths jsonb NOT NULL,
+    deleted boolean NOT NULL DEFAULT false,
+    -- reported or reverted
+    action character varying(20) NOT NULL,
+    detected_at timestamp without time zone NOT NULL,
+    resolved_at timestamp without time zone,
+    PRIMARY KEY (leaf_hub_name, kind, namespace, name, detected_at)
+);
+
 CREATE TABLE IF NOT EXISTS local_spec.placementrules (
     id uuid PRIMARY KEY,
     leaf_hub_name character varying(254) NOT NULL,
@@ -160,6 +176,34 @@ CREATE TABLE IF NOT EXISTS spec.policies (
     deleted boolean DEFAULT false NOT NULL
 );
 
+CREATE TABLE IF NOT EXISTS spec.policy_rollouts (
+    policy_id uuid PRIMARY KEY,
+    -- the generation of the stored policy is always 0, it's increased by the rollout once the policy spec is changed
+    generation bigint NOT NULL,
+    payload jsonb NOT NULL,
+    stable_payload jsonb,
+    stable_generation bigint DEFAULT 0 NOT NULL,
+    strategy jsonb NOT NULL,
+    phase character varying(63) NOT NULL,
+    current_wave integer DEFAULT 0 NOT NULL,
+    waves jsonb,
+    message text,
+    created_at timestamp without time zone DEFAULT now() NOT NULL,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS spec.local_policy_replacements (
+    local_policy_id uuid PRIMARY KEY,
+    leaf_hub_name character varying(254) NOT NULL,
+    local_namespace character varying(254) NOT NULL,
+    local_name character varying(254) NOT NULL,
+    policy_namespace character varying(254) NOT NULL,
+    policy_name character varying(254) NOT NULL,
+    replaced boolean DEFAULT false NOT NULL,
+    created_at timestamp without time zone DEFAULT now() NOT NULL,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL
+);
+
 CREATE TABLE IF NOT EXISTS spec.subscriptions (
     id uuid PRIMARY KEY,
     payload jsonb NOT NULL,
@@ -182,7 +226,19 @@ CREATE TABLE IF NOT EXISTS status.compliance (
     leaf_hub_name character varying(254) NOT NULL,
     error status.error_type NOT NULL,
     compliance status.compliance_type NOT NULL,
-    cluster_id uuid
+    cluster_id uuid,
+    -- the generation of the global policy delivered by the rollout, 0 if the policy isn't rolled out
+    policy_generation bigint DEFAULT 0 NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS status.compliance_details (
+    policy_id uuid NOT NULL,
+    cluster_name character varying(254) NOT NULL,
+    leaf_hub_name character varying(254) NOT NULL,
+    -- the latest status of the policy templates: name, kind, compliance, message and lastTimestamp
+    templates jsonb NOT NULL,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (leaf_hub_name, policy_id, cluster_name)
 );
 
 CREATE TABLE IF NOT EXISTS status.placementdecisions (
@@ -223,6 +279,12 @@ CREATE INDEX IF NOT EXISTS compliance_leaf_hub_non_compliant_idx ON status.compl
 
 CREATE UNIQUE INDEX IF NOT EXISTS compliance_leaf_hub_policy_cluster_idx ON status.compliance (leaf_hub_name, policy_id, cluster_name);
 
+CREATE INDEX IF NOT EXISTS compliance_policy_idx ON status.compliance (policy_id);
+
+CREATE INDEX IF NOT EXISTS compliance_details_policy_idx ON status.compliance_details (policy_id);
+
+CREATE INDEX IF NOT EXISTS policies_payload_idx ON spec.policies USING gin (payload jsonb_path_ops);
+
 CREATE UNIQUE INDEX IF NOT EXISTS placementdecisions_leaf_hub_name_and_payload_id_namespace_idx ON status.placementdecisions (leaf_hub_name, id, (((payload -> 'metadata'::text) ->> 'namespace'::text)));
 
 CREATE INDEX IF NOT EXISTS placementdecisions_payload_name_and_namespace_idx ON status.placementdecisions ((((payload -> 'metadata'::text) ->> 'name'::text)), (((payload -> 'metadata'::text) ->> 'namespace'::text)));
@@ -241,4 +303,6 @@ CREATE INDEX IF NOT EXISTS subscription_reports_payload_name_and_namespace_idx O
 
 CREATE UNIQUE INDEX IF NOT EXISTS subscription_statuses_leaf_hub_name_and_payload_id_namespace_idx ON status.subscription_statuses (leaf_hub_name, id, (((payload -> 'metadata'::text) ->> 'namespace'::text)));
 
-CREATE INDEX IF NOT EXISTS subscription_statuses_payload_name_and_namespace_idx ON status.subscription_statuses ((((payload -> 'metadata'::text) ->> 'name'::text)), (((payload -> 'metadata'::text) ->> 'namespace'::text)));
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS subscription_statuses_payload_name_and_namespace_idx ON status.subscription_statuses ((((payload -> 'metadata'::text) ->> 'name'::text)), (((payload -> 'metadata'::text) ->> 'namespace'::text)));
+
+CREATE INDEX IF NOT EXISTS global_resource_drifts_unresolved_idx ON history.global_resource_drifts (leaf_hub_name) WHERE (resolved_at IS NULL);
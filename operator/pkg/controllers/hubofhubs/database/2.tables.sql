@@ -13,6 +13,7 @@ CREATE TABLE IF NOT EXISTS local_spec.policies (
 );
 CREATE INDEX IF NOT EXISTS local_policies_deleted_at_idx ON local_spec.policies (deleted_at);
 CREATE INDEX IF NOT EXISTS local_policies_leafhub_idx ON local_spec.policies (leaf_hub_name);
+CREATE INDEX IF NOT EXISTS local_policies_payload_idx ON local_spec.policies USING gin (payload jsonb_path_ops);
 
 CREATE TABLE IF NOT EXISTS local_status.compliance (
     policy_id uuid NOT NULL,
@@ -27,7 +28,8 @@ CREATE TABLE IF NOT EXISTS local_status.compliance (
 CREATE TABLE IF NOT EXISTS status.leaf_hub_heartbeats (
     leaf_hub_name character varying(254) NOT NULL,
     last_timestamp timestamp without time zone DEFAULT now() NOT NULL,
-    status VARCHAR(10) DEFAULT 'active'
+    status VARCHAR(10) DEFAULT 'active',
+    health jsonb
 );
 CREATE UNIQUE INDEX IF NOT EXISTS leaf_hub_heartbeats_leaf_hub_idx ON status.leaf_hub_heartbeats (leaf_hub_name);
 CREATE INDEX IF NOT EXISTS leaf_hub_heartbeats_leaf_hub_timestamp_idx ON status.leaf_hub_heartbeats(last_timestamp);
@@ -44,6 +46,44 @@ CREATE TABLE IF NOT EXISTS status.managed_clusters (
 );
 CREATE INDEX IF NOT EXISTS cluster_deleted_at_idx ON status.managed_clusters (deleted_at);
 CREATE INDEX IF NOT EXISTS leafhub_cluster_idx ON status.managed_clusters (leaf_hub_name, cluster_name);
+CREATE INDEX IF NOT EXISTS cluster_name_id_idx ON status.managed_clusters (cluster_name, cluster_id);
+CREATE INDEX IF NOT EXISTS cluster_payload_idx ON status.managed_clusters USING gin (payload jsonb_path_ops);
+
+CREATE TABLE IF NOT EXISTS status.managed_cluster_addons (
+    leaf_hub_name character varying(254) NOT NULL,
+    cluster_name character varying(254) NOT NULL,
+    addon_name character varying(254) NOT NULL,
+    install_namespace character varying(254),
+    -- the ACM version of the managed hub which installs the addon
+    hub_version character varying(254),
+    available character varying(16) NOT NULL DEFAULT 'Unknown',
+    degraded character varying(16) NOT NULL DEFAULT 'Unknown',
+    conditions jsonb,
+    resource_version character varying(63),
+    created_at timestamp without time zone DEFAULT now() NOT NULL,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (leaf_hub_name, cluster_name, addon_name)
+);
+CREATE INDEX IF NOT EXISTS managed_cluster_addons_name_idx ON status.managed_cluster_addons (addon_name);
+
+CREATE TABLE IF NOT EXISTS status.gitops_applications (
+    leaf_hub_name character varying(254) NOT NULL,
+    namespace character varying(254) NOT NULL,
+    name character varying(254) NOT NULL,
+    project character varying(254),
+    health character varying(63) NOT NULL DEFAULT 'Unknown',
+    sync_status character varying(63) NOT NULL DEFAULT 'Unknown',
+    revision character varying(254),
+    destination_server text,
+    destination_name character varying(254),
+    destination_namespace character varying(254),
+    payload jsonb NOT NULL,
+    resource_version character varying(63),
+    created_at timestamp without time zone DEFAULT now() NOT NULL,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (leaf_hub_name, namespace, name)
+);
+CREATE INDEX IF NOT EXISTS gitops_applications_health_idx ON status.gitops_applications (health);
 
 CREATE TABLE IF NOT EXISTS status.leaf_hubs (
     leaf_hub_name character varying(254) NOT NULL,
@@ -57,6 +97,9 @@ CREATE TABLE IF NOT EXISTS status.leaf_hubs (
     PRIMARY KEY (cluster_id, leaf_hub_name)
 );
 CREATE INDEX IF NOT EXISTS leafhub_deleted_at_idx ON status.leaf_hubs (deleted_at);
+-- the hub only reports the resources included by the status filters of the agent
+ALTER TABLE status.leaf_hubs ADD COLUMN IF NOT EXISTS partially_reported boolean
+    generated always as (payload ? 'statusFilters') stored;
 
 -- Partition tables
 CREATE TABLE IF NOT EXISTS event.local_policies (
@@ -107,6 +150,51 @@ CREATE TABLE IF NOT EXISTS history.local_compliance (
     CONSTRAINT local_policies_unique_constraint UNIQUE (policy_id, cluster_id, compliance_date)
 ) PARTITION BY RANGE (compliance_date);
 
+CREATE TABLE IF NOT EXISTS history.gitops_application_health (
+    leaf_hub_name character varying(254) NOT NULL,
+    namespace character varying(254) NOT NULL,
+    name character varying(254) NOT NULL,
+    previous_health character varying(63),
+    health character varying(63) NOT NULL,
+    sync_status character varying(63),
+    revision character varying(254),
+    transitioned_at timestamp without time zone DEFAULT now() NOT NULL
+);
+CREATE INDEX IF NOT EXISTS gitops_application_health_idx ON history.gitops_application_health (leaf_hub_name, namespace, name, transitioned_at);
+
+-- the append-only audit log of the mutations made through the global hub, the diff only has the changed fields
+CREATE TABLE IF NOT EXISTS history.audit_log (
+    id uuid NOT NULL,
+    -- the user of the api or the kubernetes request
+    actor character varying(254) NOT NULL,
+    actor_groups jsonb,
+    -- api or kubernetes
+    source character varying(63) NOT NULL,
+    action character varying(63) NOT NULL,
+    target_kind character varying(254) NOT NULL,
+    target_namespace character varying(254),
+    target_name character varying(254) NOT NULL,
+    target_id character varying(254),
+    diff jsonb,
+    created_at timestamp without time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON history.audit_log (created_at, id);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON history.audit_log (actor, created_at);
+CREATE INDEX IF NOT EXISTS audit_log_target_idx ON history.audit_log (target_kind, target_name, created_at);
+
+-- the users of the latest admitted changes of the global resources, the changes are recorded into the audit log with
+-- them once they're persisted by the spec to db controllers on the leader
+CREATE TABLE IF NOT EXISTS status.audit_actors (
+    kind character varying(254) NOT NULL,
+    namespace character varying(254) NOT NULL,
+    name character varying(254) NOT NULL,
+    actor character varying(254) NOT NULL,
+    actor_groups jsonb,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (kind, namespace, name)
+);
+
 CREATE TABLE IF NOT EXISTS history.local_compliance_job_log (
     name varchar(254) NOT NULL,
     start_at timestamp NOT NULL DEFAULT now(),
@@ -123,4 +211,57 @@ CREATE TABLE IF NOT EXISTS status.transport (
     payload jsonb NOT NULL,
     created_at timestamp without time zone DEFAULT now() NOT NULL,
     updated_at timestamp without time zone DEFAULT now() NOT NULL
-);
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS status.resyncs (
+    id uuid PRIMARY KEY,
+    -- the requested hubs and bundle keys, empty means all the active hubs or the default bundle keys
+    leaf_hubs jsonb NOT NULL,
+    bundle_keys jsonb NOT NULL,
+    trigger character varying(63) NOT NULL,
+    timeout_seconds integer NOT NULL,
+    phase character varying(63) NOT NULL,
+    progress jsonb,
+    message text,
+    started_at timestamp without time zone,
+    completed_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT now() NOT NULL,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL
+);
+
+-- the last bundle versions processed by the manager replicas, the resync only waits for the bundles reported by the hub
+CREATE TABLE IF NOT EXISTS status.leaf_hub_bundles (
+    leaf_hub_name character varying(254) NOT NULL,
+    bundle_type character varying(254) NOT NULL,
+    generation bigint NOT NULL,
+    value bigint NOT NULL,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (leaf_hub_name, bundle_type)
+);
+
+-- the managed hubs waiting for the snapshots of the spec bundles, the requests are received by the manager replica owning
+-- the partition of the hub, and served by the spec syncers running on the leader
+CREATE TABLE IF NOT EXISTS status.spec_snapshot_requests (
+    leaf_hub_name character varying(254) NOT NULL,
+    bundle_key character varying(254) NOT NULL,
+    requested_at timestamp without time zone DEFAULT now() NOT NULL,
+    PRIMARY KEY (leaf_hub_name, bundle_key)
+);
+
+CREATE TABLE IF NOT EXISTS status.managed_cluster_migrations (
+    id uuid PRIMARY KEY,
+    source_hub character varying(254) NOT NULL,
+    target_hub character varying(254) NOT NULL,
+    -- the names of the clusters to migrate
+    clusters jsonb NOT NULL,
+    timeout_seconds integer NOT NULL,
+    phase character varying(63) NOT NULL,
+    -- the progress of each cluster, the bootstrap kubeconfigs are forwarded between the hubs and never stored
+    progress jsonb,
+    message text,
+    started_at timestamp without time zone,
+    completed_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT now() NOT NULL,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL
+);
+CREATE INDEX IF NOT EXISTS managed_cluster_migrations_phase_idx ON status.managed_cluster_migrations (phase);
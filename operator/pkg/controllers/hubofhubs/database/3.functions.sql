@@ -20,6 +20,16 @@ BEGIN
 END;
 $$;
 
+-- the audit log is append-only, the expired records are removed by dropping the partitions
+CREATE OR REPLACE FUNCTION public.reject_audit_log_change()
+    RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION 'history.audit_log is append-only';
+END;
+$$;
+
 -- manually exec local compliance cronjob func
 -- insert compliance view records to history.local_compliance: SELECT history.insert_local_compliance_job('2023_07_06');
 CREATE OR REPLACE FUNCTION history.insert_local_compliance_job(
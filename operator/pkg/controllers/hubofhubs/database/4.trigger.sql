@@ -12,12 +12,19 @@ AFTER INSERT OR UPDATE ON status.managed_clusters
 FOR EACH ROW
 EXECUTE FUNCTION public.update_local_compliance_cluster_id();
 
+-- reject the updates and the deletions of the audit log
+DROP TRIGGER IF EXISTS audit_log_append_only ON history.audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON history.audit_log
+FOR EACH STATEMENT EXECUTE FUNCTION public.reject_audit_log_change();
+
 --- create the current month partitioned tables for local_policies and local_root_policies
 SELECT create_monthly_range_partitioned_table('event.local_root_policies', to_char(current_date, 'YYYY-MM-DD'));
 SELECT create_monthly_range_partitioned_table('event.local_policies', to_char(current_date, 'YYYY-MM-DD'));
 SELECT create_monthly_range_partitioned_table('history.local_compliance', to_char(current_date, 'YYYY-MM-DD'));
+SELECT create_monthly_range_partitioned_table('history.audit_log', to_char(current_date, 'YYYY-MM-DD'));
 
 --- create the previous month partitioned tables for receiving the data from the previous month
 SELECT create_monthly_range_partitioned_table('event.local_root_policies', to_char(current_date - interval '1 month', 'YYYY-MM-DD'));
 SELECT create_monthly_range_partitioned_table('event.local_policies', to_char(current_date - interval '1 month', 'YYYY-MM-DD'));
 SELECT create_monthly_range_partitioned_table('history.local_compliance', to_char(current_date - interval '1 month', 'YYYY-MM-DD'));
+SELECT create_monthly_range_partitioned_table('history.audit_log', to_char(current_date - interval '1 month', 'YYYY-MM-DD'));
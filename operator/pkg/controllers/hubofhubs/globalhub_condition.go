@@ -2,10 +2,12 @@ package hubofhubs
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -16,10 +18,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
+	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+	"github.com/stolostron/multicluster-global-hub/pkg/transport"
 )
 
 // this controller is responsible for updating the status of the global hub mgh cr
@@ -31,7 +37,7 @@ type GlobalHubConditionReconciler struct {
 func (r *GlobalHubConditionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	r.Log.V(2).Info("reconciling global hub status condition", "namespace", req.Namespace, "name", req.Name)
 
-	mgh := &globalhubv1alpha4.MulticlusterGlobalHub{}
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{}
 	if err := r.Client.Get(ctx, types.NamespacedName{
 		Name:      req.Name,
 		Namespace: req.Namespace,
@@ -53,11 +59,42 @@ func (r *GlobalHubConditionReconciler) Reconcile(ctx context.Context, req ctrl.R
 		operatorconstants.GHGrafanaDeploymentName); err != nil {
 		return ctrl.Result{}, err
 	}
+
+	// roll up the transport health reported by the manager
+	if err := r.updateTransportStatus(ctx, mgh); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{}, nil
 }
 
+func (r *GlobalHubConditionReconciler) updateTransportStatus(ctx context.Context,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
+) error {
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, types.NamespacedName{
+		Name:      constants.GHTransportHealthConfigMap,
+		Namespace: mgh.Namespace,
+	}, configMap); err != nil && errors.IsNotFound(err) {
+		// the manager hasn't reported the transport health yet, ignore
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	health := &transport.TransportHealth{}
+	if err := json.Unmarshal([]byte(configMap.Data[constants.GHTransportHealthKey]), health); err != nil {
+		r.Log.Error(err, "failed to parse the transport health", "name", configMap.Name)
+		return condition.SetCondition(ctx, r.Client, mgh, condition.CONDITION_TYPE_TRANSPORT_DEGRADED,
+			condition.CONDITION_STATUS_UNKNOWN, condition.CONDITION_REASON_TRANSPORT_HEALTHY,
+			condition.CONDITION_MESSAGE_TRANSPORT_UNKNOWN)
+	}
+
+	r.Log.V(2).Info("updating transport status", "degraded", health.Degraded(), "message", health.Message())
+	return condition.SetConditionTransportDegraded(ctx, r.Client, mgh, health.Degraded(), health.Message())
+}
+
 func (r *GlobalHubConditionReconciler) updateDeploymentStatus(ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub, conditionType string, deployName string,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub, conditionType string, deployName string,
 ) error {
 	desiredCondition := metav1.Condition{
 		Type:               conditionType,
@@ -107,9 +144,18 @@ func (r *GlobalHubConditionReconciler) SetupWithManager(mgr manager.Manager) err
 		},
 	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&globalhubv1alpha4.MulticlusterGlobalHub{}, builder.WithPredicates(mghPred)).
+		For(&globalhubv1beta1.MulticlusterGlobalHub{}, builder.WithPredicates(mghPred)).
 		Watches(&appsv1.Deployment{},
 			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
-				&globalhubv1alpha4.MulticlusterGlobalHub{})).
+				&globalhubv1beta1.MulticlusterGlobalHub{})).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: config.GetMGHNamespacedName()}}
+			}), builder.WithPredicates(transportHealthPred)).
 		Complete(r)
 }
+
+var transportHealthPred = predicate.NewPredicateFuncs(func(obj client.Object) bool {
+	return obj.GetName() == constants.GHTransportHealthConfigMap &&
+		obj.GetNamespace() == config.GetMGHNamespacedName().Namespace
+})
@@ -5,13 +5,13 @@ import (
 
 	"k8s.io/apimachinery/pkg/types"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 )
 
 // reconcileSystemConfig tries to create hoh resources if they don't exist
 func (r *MulticlusterGlobalHubReconciler) reconcileSystemConfig(ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
 ) error {
 	log := r.Log.WithName("config")
 	log.Info("set operand images; service monitor interval; set global hub agent config")
@@ -12,7 +12,7 @@ import (
 
 	"github.com/jackc/pgx/v4"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
 	"github.com/stolostron/multicluster-global-hub/pkg/database"
 )
@@ -29,7 +29,7 @@ var databaseOldFS embed.FS
 var upgradeFS embed.FS
 
 func (r *MulticlusterGlobalHubReconciler) ReconcileDatabase(ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
 ) error {
 	log := r.Log.WithName("database")
 
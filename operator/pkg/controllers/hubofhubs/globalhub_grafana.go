@@ -21,7 +21,7 @@ import (
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/deployer"
@@ -71,7 +71,7 @@ var (
 )
 
 func (r *MulticlusterGlobalHubReconciler) reconcileGrafana(ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
 ) error {
 	log := r.Log.WithName("grafana")
 
@@ -87,12 +87,17 @@ func (r *MulticlusterGlobalHubReconciler) reconcileGrafana(ctx context.Context,
 	}
 
 	replicas := int32(1)
-	if mgh.Spec.AvailabilityConfig == globalhubv1alpha4.HAHigh {
+	if mgh.Spec.AvailabilityConfig == globalhubv1beta1.HAHigh {
 		replicas = 2
 	}
+	podSpec, err := operatorutils.GetPodSpecValues(operatorconstants.Grafana, mgh.Spec.AdvancedConfig, "name")
+	if err != nil {
+		return err
+	}
 
 	// get the grafana objects
-	grafanaRenderer, grafanaDeployer := renderer.NewHoHRenderer(fs), deployer.NewHoHDeployer(r.Client)
+	grafanaRenderer := renderer.NewHoHRenderer(fs)
+	grafanaDeployer := deployer.NewApplyDeployer(r.Client, operatorconstants.Grafana, mgh)
 	grafanaObjects, err := grafanaRenderer.Render("manifests/grafana", "", func(profile string) (interface{}, error) {
 		return struct {
 			Namespace            string
@@ -107,19 +112,21 @@ func (r *MulticlusterGlobalHubReconciler) reconcileGrafana(ctx context.Context,
 			Tolerations          []corev1.Toleration
 			Resources            *corev1.ResourceRequirements
 			EnableMetrics        bool
+			PodSpec              *operatorutils.PodSpecValues
 		}{
 			Namespace:            utils.GetDefaultNamespace(),
-			Replicas:             replicas,
+			Replicas:             operatorutils.GetReplicas(operatorconstants.Grafana, mgh.Spec.AdvancedConfig, replicas),
 			SessionSecret:        proxySessionSecret,
 			ProxyImage:           config.GetImage(config.OauthProxyImageKey),
 			GrafanaImage:         config.GetImage(config.GrafanaImageKey),
 			ImagePullSecret:      mgh.Spec.ImagePullSecret,
 			ImagePullPolicy:      string(imagePullPolicy),
 			DatasourceSecretName: datasourceName,
-			NodeSelector:         mgh.Spec.NodeSelector,
-			Tolerations:          mgh.Spec.Tolerations,
+			NodeSelector:         operatorutils.GetNodeSelector(operatorconstants.Grafana, mgh),
+			Tolerations:          operatorutils.GetTolerations(operatorconstants.Grafana, mgh),
 			EnableMetrics:        mgh.Spec.EnableMetrics,
 			Resources:            operatorutils.GetResources(operatorconstants.Grafana, mgh.Spec.AdvancedConfig),
+			PodSpec:              podSpec,
 		}, nil
 	})
 	if err != nil {
@@ -167,7 +174,7 @@ func (r *MulticlusterGlobalHubReconciler) reconcileGrafana(ctx context.Context,
 // generateGranafaIni append the custom grafana.ini to default grafana.ini
 func (r *MulticlusterGlobalHubReconciler) generateGrafanaIni(
 	ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
 ) (bool, error) {
 	configNamespace := utils.GetDefaultNamespace()
 	defaultGrafanaIniSecret, err := r.KubeClient.CoreV1().
@@ -264,7 +271,7 @@ func (r *MulticlusterGlobalHubReconciler) generateGrafanaIni(
 // if there is the custom configmap, merge the custom configmap and default configmap then apply the merged configmap
 func (r *MulticlusterGlobalHubReconciler) generateAlertConfigMap(
 	ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
 ) (bool, error) {
 	configNamespace := utils.GetDefaultNamespace()
 	defaultAlertConfigMap, err := r.KubeClient.CoreV1().
@@ -428,7 +435,7 @@ func mergeAlertConfigMap(defaultAlertConfigMap, customAlertConfigMap *corev1.Con
 // the GrafanaDatasource points to multicluster-global-hub cr
 func (r *MulticlusterGlobalHubReconciler) GenerateGrafanaDataSourceSecret(
 	ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
 ) (bool, error) {
 	if r.MiddlewareConfig == nil || r.MiddlewareConfig.StorageConn == nil {
 		return false, fmt.Errorf("middleware PgConnection config is null")
@@ -447,8 +454,12 @@ func (r *MulticlusterGlobalHubReconciler) GenerateGrafanaDataSourceSecret(
 		saToken = string(saSecret.Data["token"])
 	}
 
-	datasourceVal, err := GrafanaDataSource(r.MiddlewareConfig.StorageConn.ReadonlyUserDatabaseURI,
-		r.MiddlewareConfig.StorageConn.CACert, saToken)
+	// the dashboards query the read replica if it's provided, so that they don't slow down the status ingestion
+	readonlyUserDatabaseURI := r.MiddlewareConfig.StorageConn.ReadonlyUserDatabaseURI
+	if r.MiddlewareConfig.StorageConn.ReplicaReadonlyUserDatabaseURI != "" {
+		readonlyUserDatabaseURI = r.MiddlewareConfig.StorageConn.ReplicaReadonlyUserDatabaseURI
+	}
+	datasourceVal, err := GrafanaDataSource(readonlyUserDatabaseURI, r.MiddlewareConfig.StorageConn.CACert, saToken)
 	if err != nil {
 		datasourceVal, err = GrafanaDataSource(r.MiddlewareConfig.StorageConn.SuperuserDatabaseURI,
 			r.MiddlewareConfig.StorageConn.CACert, saToken)
@@ -14,7 +14,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	operatorutils "github.com/stolostron/multicluster-global-hub/operator/pkg/utils"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/pkg/utils"
@@ -128,13 +128,13 @@ policies:
 func Test_generateAlertConfigMap(t *testing.T) {
 	configNamespace := utils.GetDefaultNamespace()
 
-	mgh := &globalhubv1alpha4.MulticlusterGlobalHub{
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test",
 			Namespace: "multicluster-global-hub",
 		},
-		Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{
-			DataLayer: globalhubv1alpha4.DataLayerConfig{},
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+			DataLayer: globalhubv1beta1.DataLayerConfig{},
 		},
 	}
 	tests := []struct {
@@ -221,7 +221,7 @@ func Test_generateAlertConfigMap(t *testing.T) {
 						Name:      mergedAlertName,
 						OwnerReferences: []metav1.OwnerReference{
 							{
-								APIVersion:         "operator.open-cluster-management.io/v1alpha4",
+								APIVersion:         "operator.open-cluster-management.io/v1beta1",
 								Kind:               "MulticlusterGlobalHub",
 								Name:               "test",
 								BlockOwnerDeletion: pointer.Bool(true),
@@ -307,7 +307,7 @@ policies:
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := globalhubv1alpha4.AddToScheme(scheme.Scheme)
+			err := globalhubv1beta1.AddToScheme(scheme.Scheme)
 			if err != nil {
 				t.Error("Failed to add scheme")
 			}
@@ -338,13 +338,13 @@ policies:
 
 func Test_generateGranafaIni(t *testing.T) {
 	configNamespace := utils.GetDefaultNamespace()
-	mgh := &globalhubv1alpha4.MulticlusterGlobalHub{
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test",
 			Namespace: "multicluster-global-hub",
 		},
-		Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{
-			DataLayer: globalhubv1alpha4.DataLayerConfig{},
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+			DataLayer: globalhubv1beta1.DataLayerConfig{},
 		},
 	}
 	tests := []struct {
@@ -384,7 +384,7 @@ func Test_generateGranafaIni(t *testing.T) {
 						},
 						OwnerReferences: []metav1.OwnerReference{
 							{
-								APIVersion:         "operator.open-cluster-management.io/v1alpha4",
+								APIVersion:         "operator.open-cluster-management.io/v1beta1",
 								Kind:               "MulticlusterGlobalHub",
 								Name:               "test",
 								BlockOwnerDeletion: pointer.Bool(true),
@@ -485,7 +485,7 @@ func Test_generateGranafaIni(t *testing.T) {
 						},
 						OwnerReferences: []metav1.OwnerReference{
 							{
-								APIVersion:         "operator.open-cluster-management.io/v1alpha4",
+								APIVersion:         "operator.open-cluster-management.io/v1beta1",
 								Kind:               "MulticlusterGlobalHub",
 								Name:               "test",
 								BlockOwnerDeletion: pointer.Bool(true),
@@ -561,7 +561,7 @@ email = example@redhat.com
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := globalhubv1alpha4.AddToScheme(scheme.Scheme)
+			err := globalhubv1beta1.AddToScheme(scheme.Scheme)
 			if err != nil {
 				t.Error("Failed to add scheme")
 			}
@@ -11,12 +11,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/restmapper"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
-	"github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	"github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
@@ -36,7 +37,7 @@ var (
 )
 
 func (r *MulticlusterGlobalHubReconciler) reconcileManager(ctx context.Context,
-	mgh *v1alpha4.MulticlusterGlobalHub,
+	mgh *v1beta1.MulticlusterGlobalHub,
 ) error {
 	log := r.Log.WithName("manager")
 
@@ -46,8 +47,9 @@ func (r *MulticlusterGlobalHubReconciler) reconcileManager(ctx context.Context,
 		return fmt.Errorf("failed to get random session secret for oauth-proxy: %v", err)
 	}
 
-	// create new HoHRenderer and HoHDeployer
-	hohRenderer, hohDeployer := renderer.NewHoHRenderer(fs), deployer.NewHoHDeployer(r.Client)
+	// create new HoHRenderer and ApplyDeployer
+	hohRenderer := renderer.NewHoHRenderer(fs)
+	hohDeployer := deployer.NewApplyDeployer(r.Client, operatorconstants.Manager, mgh)
 
 	// create discovery client
 	dc, err := discovery.NewDiscoveryClientForConfig(r.Manager.GetConfig())
@@ -83,9 +85,22 @@ func (r *MulticlusterGlobalHubReconciler) reconcileManager(ctx context.Context,
 	}
 
 	replicas := int32(1)
-	if mgh.Spec.AvailabilityConfig == v1alpha4.HAHigh {
+	if mgh.Spec.AvailabilityConfig == v1beta1.HAHigh {
 		replicas = 2
 	}
+	podSpec, err := utils.GetPodSpecValues(operatorconstants.Manager, mgh.Spec.AdvancedConfig, "name")
+	if err != nil {
+		return err
+	}
+	replicas = utils.GetReplicas(operatorconstants.Manager, mgh.Spec.AdvancedConfig, replicas)
+	// the manager is stopped while the database is being restored
+	backupValues, restoring, err := r.reconcilePostgresBackup(ctx, mgh)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile the database backup: %v", err)
+	}
+	if restoring {
+		replicas = 0
+	}
 	trans := config.GetTransporter()
 
 	transportTopic := trans.GenerateClusterTopic(transportprotocol.GlobalHubClusterName)
@@ -113,6 +128,8 @@ func (r *MulticlusterGlobalHubReconciler) reconcileManager(ctx context.Context,
 			ProxySessionSecret: proxySessionSecret,
 			DatabaseURL: base64.StdEncoding.EncodeToString(
 				[]byte(r.MiddlewareConfig.StorageConn.SuperuserDatabaseURI)),
+			DatabaseReplicaURL: base64.StdEncoding.EncodeToString(
+				[]byte(r.MiddlewareConfig.StorageConn.ReplicaDatabaseURI)),
 			PostgresCACert:         base64.StdEncoding.EncodeToString(r.MiddlewareConfig.StorageConn.CACert),
 			KafkaCACert:            transportConn.CACert,
 			KafkaClientCert:        transportConn.ClientCert,
@@ -130,13 +147,17 @@ func (r *MulticlusterGlobalHubReconciler) reconcileManager(ctx context.Context,
 			SchedulerInterval:      config.GetSchedulerInterval(mgh),
 			SkipAuth:               config.SkipAuth(mgh),
 			LaunchJobNames:         config.GetLaunchJobNames(mgh),
-			NodeSelector:           mgh.Spec.NodeSelector,
-			Tolerations:            mgh.Spec.Tolerations,
+			NodeSelector:           utils.GetNodeSelector(operatorconstants.Manager, mgh),
+			Tolerations:            utils.GetTolerations(operatorconstants.Manager, mgh),
 			RetentionMonth:         months,
 			StatisticLogInterval:   config.GetStatisticLogInterval(),
 			EnableGlobalResource:   r.EnableGlobalResource,
+			EnableStatusSharding:   config.EnableStatusSharding(mgh),
+			EncryptionSecret:       config.GetPayloadEncryptionSecret(mgh),
 			LogLevel:               r.LogLevel,
 			Resources:              utils.GetResources(operatorconstants.Manager, mgh.Spec.AdvancedConfig),
+			PodSpec:                podSpec,
+			Backup:                 backupValues,
 		}, nil
 	})
 	if err != nil {
@@ -186,42 +207,63 @@ func setMiddlewareCache(curMiddlewareConfig *MiddlewareConfig) {
 	}
 }
 
-func (r *MulticlusterGlobalHubReconciler) manipulateObj(ctx context.Context, hohDeployer deployer.Deployer,
+// manipulateObj applies the rendered objects of the component, the objects failed to be applied are reported in the
+// ResourcesApplied condition of the component. the objects which aren't rendered any more are pruned only if all the
+// rendered objects are applied.
+func (r *MulticlusterGlobalHubReconciler) manipulateObj(ctx context.Context, hohDeployer deployer.ComponentDeployer,
 	mapper *restmapper.DeferredDiscoveryRESTMapper, objs []*unstructured.Unstructured,
-	mgh *v1alpha4.MulticlusterGlobalHub, log logr.Logger,
+	mgh *v1beta1.MulticlusterGlobalHub, log logr.Logger,
 ) error {
+	var applyErrs []error
 	// manipulate the object
 	for _, obj := range objs {
-		mapping, err := mapper.RESTMapping(obj.GroupVersionKind().GroupKind(), obj.GroupVersionKind().Version)
-		if err != nil {
-			log.Error(err, "failed to find mapping for resource", "kind", obj.GetKind(),
+		if err := r.manipulateSingleObj(hohDeployer, mapper, obj, mgh); err != nil {
+			log.Error(err, "failed to apply the object", "kind", obj.GetKind(),
 				"namespace", obj.GetNamespace(), "name", obj.GetName())
-			return err
+			applyErrs = append(applyErrs, fmt.Errorf("%s: %w", deployer.ObjectRef{
+				Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName(),
+			}, err))
 		}
+	}
 
-		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
-			// for namespaced resource, set ownerreference of controller
-			if err := controllerutil.SetControllerReference(mgh, obj, r.Scheme); err != nil {
-				log.Error(err, "failed to set controller reference", "kind", obj.GetKind(),
-					"namespace", obj.GetNamespace(), "name", obj.GetName())
-				return err
-			}
-		}
+	applyErr := utilerrors.NewAggregate(applyErrs)
+	if err := condition.SetConditionResourcesApplied(ctx, r.Client, mgh, hohDeployer.Component(),
+		applyErr); err != nil {
+		return condition.FailToSetConditionError(
+			condition.ResourcesAppliedConditionType(hohDeployer.Component()), err)
+	}
+	if applyErr != nil {
+		return applyErr
+	}
 
-		// set owner labels
-		labels := obj.GetLabels()
-		if labels == nil {
-			labels = make(map[string]string)
-		}
-		labels[constants.GlobalHubOwnerLabelKey] = constants.GHOperatorOwnerLabelVal
-		obj.SetLabels(labels)
+	return hohDeployer.Prune(ctx)
+}
+
+func (r *MulticlusterGlobalHubReconciler) manipulateSingleObj(hohDeployer deployer.Deployer,
+	mapper *restmapper.DeferredDiscoveryRESTMapper, obj *unstructured.Unstructured,
+	mgh *v1beta1.MulticlusterGlobalHub,
+) error {
+	mapping, err := mapper.RESTMapping(obj.GroupVersionKind().GroupKind(), obj.GroupVersionKind().Version)
+	if err != nil {
+		return fmt.Errorf("failed to find mapping for resource: %w", err)
+	}
 
-		if err := hohDeployer.Deploy(obj); err != nil {
-			return err
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		// for namespaced resource, set ownerreference of controller
+		if err := controllerutil.SetControllerReference(mgh, obj, r.Scheme); err != nil {
+			return fmt.Errorf("failed to set controller reference: %w", err)
 		}
 	}
 
-	return nil
+	// set owner labels
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[constants.GlobalHubOwnerLabelKey] = constants.GHOperatorOwnerLabelVal
+	obj.SetLabels(labels)
+
+	return hohDeployer.Deploy(obj)
 }
 
 type ManagerVariables struct {
@@ -232,6 +274,7 @@ type ManagerVariables struct {
 	ImagePullPolicy        string
 	ProxySessionSecret     string
 	DatabaseURL            string
+	DatabaseReplicaURL     string
 	PostgresCACert         string
 	KafkaCACert            string
 	KafkaConsumerTopic     string
@@ -254,6 +297,10 @@ type ManagerVariables struct {
 	RetentionMonth         int
 	StatisticLogInterval   string
 	EnableGlobalResource   bool
+	EnableStatusSharding   bool
+	EncryptionSecret       string
 	LogLevel               string
 	Resources              *corev1.ResourceRequirements
+	PodSpec                *utils.PodSpecValues
+	Backup                 *PostgresBackupValues
 }
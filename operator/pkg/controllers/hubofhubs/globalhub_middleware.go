@@ -22,6 +22,7 @@ import (
 	"sync"
 	"time"
 
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -32,8 +33,9 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	"github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
+	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/deployer"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/postgres"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/renderer"
@@ -48,7 +50,7 @@ import (
 // 1. create the kafka and postgres subscription at the same time
 // 2. then create the kafka and postgres resources at the same time
 // 3. wait for kafka and postgres ready
-func (r *MulticlusterGlobalHubReconciler) ReconcileMiddleware(ctx context.Context, mgh *v1alpha4.MulticlusterGlobalHub,
+func (r *MulticlusterGlobalHubReconciler) ReconcileMiddleware(ctx context.Context, mgh *v1beta1.MulticlusterGlobalHub,
 ) (ctrl.Result, error) {
 	// initialize postgres and kafka at the same time
 	var wg sync.WaitGroup
@@ -98,13 +100,16 @@ func (r *MulticlusterGlobalHubReconciler) ReconcileMiddleware(ctx context.Contex
 	return ctrl.Result{}, nil
 }
 
-// renderKafkaMetricsResources renders the kafka podmonitor and metrics
+// renderKafkaMetricsResources renders the kafka podmonitor and metrics, they're pruned if the metrics are disabled
 func (r *MulticlusterGlobalHubReconciler) renderKafkaMetricsResources(ctx context.Context,
-	mgh *v1alpha4.MulticlusterGlobalHub, transProtocol transport.TransportProtocol) error {
+	mgh *v1beta1.MulticlusterGlobalHub, transProtocol transport.TransportProtocol) error {
 	log := r.Log.WithName("middleware")
+	kafkaDeployer := deployer.NewApplyDeployer(r.Client, operatorconstants.Kafka, mgh,
+		corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+		promv1.SchemeGroupVersion.WithKind(promv1.PodMonitorsKind))
 	if mgh.Spec.EnableMetrics && transProtocol == transport.StrimziTransporter {
 		// render the kafka objects
-		kafkaRenderer, kafkaDeployer := renderer.NewHoHRenderer(fs), deployer.NewHoHDeployer(r.Client)
+		kafkaRenderer := renderer.NewHoHRenderer(fs)
 		kafkaObjects, err := kafkaRenderer.Render("manifests/kafka", "",
 			func(profile string) (interface{}, error) {
 				return struct {
@@ -126,11 +131,15 @@ func (r *MulticlusterGlobalHubReconciler) renderKafkaMetricsResources(ctx contex
 		if err = r.manipulateObj(ctx, kafkaDeployer, mapper, kafkaObjects, mgh, log); err != nil {
 			return fmt.Errorf("failed to create/update kafka objects: %w", err)
 		}
+		return nil
+	}
+	if err := kafkaDeployer.Prune(ctx); err != nil {
+		return fmt.Errorf("failed to prune kafka objects: %w", err)
 	}
 	return nil
 }
 
-func (r *MulticlusterGlobalHubReconciler) ReconcileTransport(ctx context.Context, mgh *v1alpha4.MulticlusterGlobalHub,
+func (r *MulticlusterGlobalHubReconciler) ReconcileTransport(ctx context.Context, mgh *v1beta1.MulticlusterGlobalHub,
 	transProtocol transport.TransportProtocol) (*transport.ConnCredential, error) {
 	// create the transport instance
 	var trans transport.Transporter
@@ -195,7 +204,7 @@ func (r *MulticlusterGlobalHubReconciler) ReconcileTransport(ctx context.Context
 	return conn, err
 }
 
-func (r *MulticlusterGlobalHubReconciler) ReconcileStorage(ctx context.Context, mgh *v1alpha4.MulticlusterGlobalHub,
+func (r *MulticlusterGlobalHubReconciler) ReconcileStorage(ctx context.Context, mgh *v1beta1.MulticlusterGlobalHub,
 ) (*postgres.PostgresConnection, error) {
 	// support BYO postgres
 	pgConnection, err := config.GetPGConnectionFromGHStorageSecret(ctx, r.Client)
@@ -13,7 +13,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	"github.com/stolostron/multicluster-global-hub/pkg/transport"
 	"github.com/stolostron/multicluster-global-hub/pkg/utils"
@@ -27,7 +27,7 @@ type middlewareController struct {
 func (m *middlewareController) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 	log := m.reconciler.Log.WithName("middleware")
 	// get the mcgh cr name and then trigger the globalhub reconciler
-	mgh := &globalhubv1alpha4.MulticlusterGlobalHub{}
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{}
 	err := m.mgr.GetClient().Get(ctx, config.GetMGHNamespacedName(), mgh)
 	if err != nil {
 		log.Error(err, "failed to get MulticlusterGlobalHub")
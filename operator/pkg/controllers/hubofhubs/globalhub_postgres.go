@@ -14,7 +14,7 @@ import (
 	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/restmapper"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/deployer"
@@ -42,7 +42,7 @@ var partialPostgresURI = "@multicluster-global-hub-postgres." +
 
 // EnsureCrunchyPostgresSubscription verifies resources needed for Crunchy Postgres are created
 func (r *MulticlusterGlobalHubReconciler) EnsureCrunchyPostgresSubscription(ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
 ) error {
 	postgresSub, err := operatorutils.GetSubscriptionByName(ctx, r.Client, postgres.SubscriptionName)
 	if err != nil {
@@ -92,7 +92,7 @@ func (r *MulticlusterGlobalHubReconciler) EnsureCrunchyPostgres(ctx context.Cont
 }
 
 func (r *MulticlusterGlobalHubReconciler) InitPostgresByStatefulset(ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub,
 ) (*postgres.PostgresConnection, error) {
 	// install the postgres statefulset only
 	credential, err := getPostgresCredential(ctx, mgh, r)
@@ -104,8 +104,15 @@ func (r *MulticlusterGlobalHubReconciler) InitPostgresByStatefulset(ctx context.
 		imagePullPolicy = mgh.Spec.ImagePullPolicy
 	}
 
+	podSpec, err := operatorutils.GetPodSpecValues(operatorconstants.Postgres, mgh.Spec.AdvancedConfig,
+		"app", "component", "name")
+	if err != nil {
+		return nil, err
+	}
+
 	// get the postgres objects
-	postgresRenderer, postgresDeployer := renderer.NewHoHRenderer(fs), deployer.NewHoHDeployer(r.Client)
+	postgresRenderer := renderer.NewHoHRenderer(fs)
+	postgresDeployer := deployer.NewApplyDeployer(r.Client, operatorconstants.Postgres, mgh)
 	postgresObjects, err := postgresRenderer.Render("manifests/postgres", "",
 		func(profile string) (interface{}, error) {
 			return struct {
@@ -121,13 +128,14 @@ func (r *MulticlusterGlobalHubReconciler) InitPostgresByStatefulset(ctx context.
 				PostgresReadonlyUserPassword string
 				StorageClass                 string
 				Resources                    *corev1.ResourceRequirements
+				PodSpec                      *operatorutils.PodSpecValues
 			}{
 				Namespace:                    mgh.GetNamespace(),
 				PostgresImage:                config.GetImage(config.PostgresImageKey),
 				ImagePullSecret:              mgh.Spec.ImagePullSecret,
 				ImagePullPolicy:              string(imagePullPolicy),
-				NodeSelector:                 mgh.Spec.NodeSelector,
-				Tolerations:                  mgh.Spec.Tolerations,
+				NodeSelector:                 operatorutils.GetNodeSelector(operatorconstants.Postgres, mgh),
+				Tolerations:                  operatorutils.GetTolerations(operatorconstants.Postgres, mgh),
 				StorageSize:                  config.GetPostgresStorageSize(mgh),
 				PostgresAdminUserPassword:    credential.postgresAdminUserPassword,
 				PostgresReadonlyUsername:     credential.postgresReadonlyUsername,
@@ -135,6 +143,7 @@ func (r *MulticlusterGlobalHubReconciler) InitPostgresByStatefulset(ctx context.
 				StorageClass:                 mgh.Spec.DataLayer.StorageClass,
 				Resources: operatorutils.GetResources(operatorconstants.Postgres,
 					mgh.Spec.AdvancedConfig),
+				PodSpec: podSpec,
 			}, nil
 		})
 	if err != nil {
@@ -165,7 +174,7 @@ func (r *MulticlusterGlobalHubReconciler) InitPostgresByStatefulset(ctx context.
 	}, nil
 }
 
-func getPostgresCredential(ctx context.Context, mgh *globalhubv1alpha4.MulticlusterGlobalHub,
+func getPostgresCredential(ctx context.Context, mgh *globalhubv1beta1.MulticlusterGlobalHub,
 	r *MulticlusterGlobalHubReconciler,
 ) (*postgresCredential, error) {
 	postgres := &corev1.Secret{}
@@ -191,7 +200,7 @@ func getPostgresCredential(ctx context.Context, mgh *globalhubv1alpha4.Multiclus
 }
 
 func getPostgresCA(ctx context.Context,
-	mgh *globalhubv1alpha4.MulticlusterGlobalHub, r *MulticlusterGlobalHubReconciler,
+	mgh *globalhubv1beta1.MulticlusterGlobalHub, r *MulticlusterGlobalHubReconciler,
 ) (string, error) {
 	ca := &corev1.ConfigMap{}
 	if err := r.Client.Get(ctx, types.NamespacedName{
@@ -0,0 +1,175 @@
+package hubofhubs
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
+	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
+	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
+	commonutils "github.com/stolostron/multicluster-global-hub/pkg/utils"
+)
+
+const (
+	postgresBackupClaimName  = "multicluster-global-hub-postgres-backup"
+	postgresRestoreJobPrefix = "multicluster-global-hub-postgres-restore-"
+)
+
+// PostgresBackupValues are the values to render the backup cronjob and the restore job of the database
+type PostgresBackupValues struct {
+	Schedule  string
+	Retention int32
+	// Suspend suspends the backups while the database is being restored
+	Suspend   bool
+	ClaimName string
+	// WorkDirSize limits the volume where the tables are staged before they're archived, the staged tables are
+	// bounded by the storage of the database
+	WorkDirSize string
+	S3          *v1beta1.BackupS3Storage
+	// RestoreJobName is set once the restore is requested, the job is kept until the restore is removed from the spec
+	RestoreJobName     string
+	RestoreBackup      string
+	RestorePointInTime string
+}
+
+// reconcilePostgresBackup returns the values to render the backup cronjob and the restore job, and whether the
+// manager should be stopped for the restore. the restore job waits for the manager to release its database sessions
+// before the data is replaced.
+func (r *MulticlusterGlobalHubReconciler) reconcilePostgresBackup(ctx context.Context,
+	mgh *v1beta1.MulticlusterGlobalHub,
+) (*PostgresBackupValues, bool, error) {
+	postgres := mgh.Spec.DataLayer.Postgres
+	if postgres.Backup == nil {
+		if postgres.Restore != nil {
+			return nil, false, condition.SetConditionDatabaseRestored(ctx, r.Client, mgh,
+				condition.CONDITION_REASON_RESTORE_FAILED,
+				"the restore requires the backup storage in spec.dataLayer.postgres.backup")
+		}
+		return nil, false, nil
+	}
+
+	backup := postgres.Backup
+	values := &PostgresBackupValues{
+		Schedule:    backup.Schedule,
+		Retention:   backup.Retention,
+		WorkDirSize: config.GetPostgresStorageSize(mgh),
+	}
+	switch {
+	case backup.Storage.S3 != nil:
+		values.S3 = backup.Storage.S3
+	case backup.Storage.PVC != nil:
+		values.ClaimName = backup.Storage.PVC.ClaimName
+		if values.ClaimName == "" {
+			if err := r.ensurePostgresBackupClaim(ctx, mgh); err != nil {
+				return nil, false, err
+			}
+			values.ClaimName = postgresBackupClaimName
+		}
+	default:
+		return nil, false, fmt.Errorf("neither pvc nor s3 is specified in the storage of the database backup")
+	}
+
+	restore := postgres.Restore
+	if restore == nil {
+		return values, false, nil
+	}
+	values.RestoreBackup = restore.Backup
+	if restore.Backup == "" && restore.PointInTime != nil {
+		values.RestorePointInTime = restore.PointInTime.UTC().Format(time.RFC3339)
+	}
+	values.RestoreJobName = restoreJobName(values.RestoreBackup, values.RestorePointInTime)
+
+	job := &batchv1.Job{}
+	err := r.Client.Get(ctx, types.NamespacedName{
+		Namespace: commonutils.GetDefaultNamespace(),
+		Name:      values.RestoreJobName,
+	}, job)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, false, err
+	}
+
+	reason, msg := condition.CONDITION_REASON_RESTORE_RUNNING,
+		fmt.Sprintf("The database is being restored by the job %s", values.RestoreJobName)
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			reason, msg = condition.CONDITION_REASON_RESTORE_SUCCEEDED,
+				fmt.Sprintf("The database is restored by the job %s", values.RestoreJobName)
+		case batchv1.JobFailed:
+			reason, msg = condition.CONDITION_REASON_RESTORE_FAILED,
+				fmt.Sprintf("The job %s failed to restore the database: %s", values.RestoreJobName, cond.Message)
+		}
+	}
+	if err := condition.SetConditionDatabaseRestored(ctx, r.Client, mgh, reason, msg); err != nil {
+		return nil, false, condition.FailToSetConditionError(condition.CONDITION_TYPE_DATABASE_RESTORED, err)
+	}
+
+	// the database is left untouched if the restore failed, so the manager is started again in both cases
+	running := reason == condition.CONDITION_REASON_RESTORE_RUNNING
+	values.Suspend = running
+	return values, running, nil
+}
+
+// ensurePostgresBackupClaim creates the claim of the backups. it isn't rendered with the other manager objects, so
+// the backups aren't deleted with the claim once the backup is disabled.
+func (r *MulticlusterGlobalHubReconciler) ensurePostgresBackupClaim(ctx context.Context,
+	mgh *v1beta1.MulticlusterGlobalHub,
+) error {
+	namespace := commonutils.GetDefaultNamespace()
+	_, err := r.KubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, postgresBackupClaimName,
+		metav1.GetOptions{})
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+
+	size, err := resource.ParseQuantity(config.GetPostgresBackupStorageSize(mgh))
+	if err != nil {
+		return fmt.Errorf("failed to parse the storage size of the database backup: %w", err)
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      postgresBackupClaimName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"name": postgresBackupClaimName,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	storageClass := mgh.Spec.DataLayer.StorageClass
+	if pvc := mgh.Spec.DataLayer.Postgres.Backup.Storage.PVC; pvc.StorageClass != "" {
+		storageClass = pvc.StorageClass
+	}
+	if storageClass != "" {
+		claim.Spec.StorageClassName = &storageClass
+	}
+	_, err = r.KubeClient.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, claim, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create the claim of the database backup: %w", err)
+	}
+	return nil
+}
+
+// restoreJobName returns a distinct job for each requested restore, since the job can't be updated once created
+func restoreJobName(backup, pointInTime string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(backup + "/" + pointInTime))
+	return fmt.Sprintf("%s%08x", postgresRestoreJobPrefix, h.Sum32())
+}
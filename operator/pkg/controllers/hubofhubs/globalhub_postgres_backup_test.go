@@ -0,0 +1,198 @@
+package hubofhubs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
+	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
+	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
+	"github.com/stolostron/multicluster-global-hub/pkg/utils"
+)
+
+func Test_reconcilePostgresBackup(t *testing.T) {
+	namespace := utils.GetDefaultNamespace()
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = globalhubv1beta1.AddToScheme(testScheme)
+
+	pointInTime := metav1.NewTime(time.Date(2024, 1, 4, 12, 0, 0, 0, time.UTC))
+	restoreJob := func(conditionType batchv1.JobConditionType) *batchv1.Job {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      restoreJobName("", "2024-01-04T12:00:00Z"),
+				Namespace: namespace,
+			},
+		}
+		if conditionType != "" {
+			job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+		}
+		return job
+	}
+
+	tests := []struct {
+		name            string
+		postgres        globalhubv1beta1.PostgresConfig
+		initObjects     []client.Object
+		wantBackup      bool
+		wantClaim       string
+		wantRestoring   bool
+		wantRestoreJob  bool
+		wantRestoreCond string
+	}{
+		{
+			name: "backup disabled",
+		},
+		{
+			name: "restore without backup storage",
+			postgres: globalhubv1beta1.PostgresConfig{
+				Restore: &globalhubv1beta1.PostgresRestoreConfig{},
+			},
+			wantRestoreCond: condition.CONDITION_REASON_RESTORE_FAILED,
+		},
+		{
+			name: "backup to the created claim",
+			postgres: globalhubv1beta1.PostgresConfig{
+				Backup: &globalhubv1beta1.PostgresBackupConfig{
+					Schedule:  "0 2 * * *",
+					Retention: 7,
+					Storage: globalhubv1beta1.BackupStorage{
+						PVC: &globalhubv1beta1.BackupPVCStorage{StorageSize: "5Gi"},
+					},
+				},
+			},
+			wantBackup: true,
+			wantClaim:  postgresBackupClaimName,
+		},
+		{
+			name: "restore is running",
+			postgres: globalhubv1beta1.PostgresConfig{
+				Backup: &globalhubv1beta1.PostgresBackupConfig{
+					Storage: globalhubv1beta1.BackupStorage{
+						S3: &globalhubv1beta1.BackupS3Storage{Bucket: "globalhub", CredentialSecret: "credential"},
+					},
+				},
+				Restore: &globalhubv1beta1.PostgresRestoreConfig{PointInTime: &pointInTime},
+			},
+			wantBackup:      true,
+			wantRestoring:   true,
+			wantRestoreJob:  true,
+			wantRestoreCond: condition.CONDITION_REASON_RESTORE_RUNNING,
+		},
+		{
+			name: "restore is completed",
+			postgres: globalhubv1beta1.PostgresConfig{
+				Backup: &globalhubv1beta1.PostgresBackupConfig{
+					Storage: globalhubv1beta1.BackupStorage{
+						PVC: &globalhubv1beta1.BackupPVCStorage{ClaimName: "backup"},
+					},
+				},
+				Restore: &globalhubv1beta1.PostgresRestoreConfig{PointInTime: &pointInTime},
+			},
+			initObjects:     []client.Object{restoreJob(batchv1.JobComplete)},
+			wantBackup:      true,
+			wantClaim:       "backup",
+			wantRestoreJob:  true,
+			wantRestoreCond: condition.CONDITION_REASON_RESTORE_SUCCEEDED,
+		},
+		{
+			name: "restore is failed",
+			postgres: globalhubv1beta1.PostgresConfig{
+				Backup: &globalhubv1beta1.PostgresBackupConfig{
+					Storage: globalhubv1beta1.BackupStorage{
+						PVC: &globalhubv1beta1.BackupPVCStorage{ClaimName: "backup"},
+					},
+				},
+				Restore: &globalhubv1beta1.PostgresRestoreConfig{PointInTime: &pointInTime},
+			},
+			initObjects:     []client.Object{restoreJob(batchv1.JobFailed)},
+			wantBackup:      true,
+			wantClaim:       "backup",
+			wantRestoreJob:  true,
+			wantRestoreCond: condition.CONDITION_REASON_RESTORE_FAILED,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgh := &globalhubv1beta1.MulticlusterGlobalHub{
+				ObjectMeta: metav1.ObjectMeta{Name: "multiclusterglobalhub", Namespace: namespace},
+				Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+					DataLayer: globalhubv1beta1.DataLayerConfig{Postgres: tt.postgres},
+				},
+			}
+			kubeClient := fakekube.NewSimpleClientset()
+			r := &MulticlusterGlobalHubReconciler{
+				Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(append(tt.initObjects, mgh)...).
+					WithStatusSubresource(mgh).Build(),
+				KubeClient: kubeClient,
+				Scheme:     testScheme,
+			}
+
+			ctx := context.Background()
+			values, restoring, err := r.reconcilePostgresBackup(ctx, mgh)
+			if err != nil {
+				t.Fatalf("failed to reconcile the database backup: %v", err)
+			}
+			if (values != nil) != tt.wantBackup {
+				t.Fatalf("unexpected backup values: %+v", values)
+			}
+			if restoring != tt.wantRestoring {
+				t.Errorf("expected restoring %v, got %v", tt.wantRestoring, restoring)
+			}
+			if values != nil {
+				if values.ClaimName != tt.wantClaim {
+					t.Errorf("expected claim %q, got %q", tt.wantClaim, values.ClaimName)
+				}
+				if values.WorkDirSize != config.GHPostgresDefaultStorageSize {
+					t.Errorf("the work dir should be sized by the database storage: %q", values.WorkDirSize)
+				}
+				if values.Suspend != tt.wantRestoring {
+					t.Errorf("the backups should be suspended only while restoring: %+v", values)
+				}
+				if (values.RestoreJobName != "") != tt.wantRestoreJob {
+					t.Errorf("unexpected restore job: %q", values.RestoreJobName)
+				}
+				if tt.wantRestoreJob && values.RestorePointInTime != "2024-01-04T12:00:00Z" {
+					t.Errorf("unexpected point in time: %q", values.RestorePointInTime)
+				}
+			}
+
+			claims, err := kubeClient.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantClaim == postgresBackupClaimName {
+				if len(claims.Items) != 1 || claims.Items[0].Spec.Resources.Requests.Storage().String() != "5Gi" {
+					t.Errorf("the backup claim isn't created: %+v", claims.Items)
+				}
+			} else if len(claims.Items) != 0 {
+				t.Errorf("unexpected claims: %+v", claims.Items)
+			}
+
+			if tt.wantRestoreCond == "" {
+				if condition.ContainsCondition(mgh, condition.CONDITION_TYPE_DATABASE_RESTORED) {
+					t.Errorf("unexpected restore condition: %+v", mgh.Status.Conditions)
+				}
+				return
+			}
+			for _, cond := range mgh.Status.Conditions {
+				if cond.Type == condition.CONDITION_TYPE_DATABASE_RESTORED && cond.Reason != tt.wantRestoreCond {
+					t.Errorf("expected restore reason %s, got %s", tt.wantRestoreCond, cond.Reason)
+				}
+			}
+			if !condition.ContainsCondition(mgh, condition.CONDITION_TYPE_DATABASE_RESTORED) {
+				t.Errorf("the restore condition isn't set: %+v", mgh.Status.Conditions)
+			}
+		})
+	}
+}
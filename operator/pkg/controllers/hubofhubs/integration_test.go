@@ -48,7 +48,7 @@ import (
 	applicationv1beta1 "sigs.k8s.io/application/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
@@ -137,18 +137,18 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 			ctx := context.Background()
 			// create a testing MGH instance with invalid large scale data layer setting
 			By("By creating a new MGH instance with invalid large scale data layer setting")
-			mgh := &globalhubv1alpha4.MulticlusterGlobalHub{
+			mgh := &globalhubv1beta1.MulticlusterGlobalHub{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      MGHName,
 					Namespace: commonutils.GetDefaultNamespace(),
 				},
-				Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+				Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 			}
 			Expect(k8sClient.Create(ctx, mgh)).Should(Succeed())
 
 			// after creating this MGH instance, check that the MGH instance's Spec fields are failed with default values.
 			mghLookupKey := types.NamespacedName{Namespace: commonutils.GetDefaultNamespace(), Name: MGHName}
-			createdMGH := &globalhubv1alpha4.MulticlusterGlobalHub{}
+			createdMGH := &globalhubv1beta1.MulticlusterGlobalHub{}
 
 			// get this newly created MGH instance, given that creation may not immediately happen.
 			Eventually(func() bool {
@@ -176,7 +176,7 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 			ctx := context.Background()
 			// create a testing MGH instance with reference to nonexisting image override configmap
 			By("By creating a new MGH instance with reference to nonexisting image override configmap")
-			mgh := &globalhubv1alpha4.MulticlusterGlobalHub{
+			mgh := &globalhubv1beta1.MulticlusterGlobalHub{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      MGHName,
 					Namespace: commonutils.GetDefaultNamespace(),
@@ -184,15 +184,15 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 						operatorconstants.AnnotationImageOverridesCM: "noexisting-cm",
 					},
 				},
-				Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{
-					DataLayer: globalhubv1alpha4.DataLayerConfig{},
+				Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+					DataLayer: globalhubv1beta1.DataLayerConfig{},
 				},
 			}
 			Expect(k8sClient.Create(ctx, mgh)).Should(Succeed())
 
 			// after creating this MGH instance, check that the MGH instance's Spec fields are failed with default values.
 			mghLookupKey := types.NamespacedName{Namespace: commonutils.GetDefaultNamespace(), Name: MGHName}
-			createdMGH := &globalhubv1alpha4.MulticlusterGlobalHub{}
+			createdMGH := &globalhubv1beta1.MulticlusterGlobalHub{}
 
 			// get this newly created MGH instance, given that creation may not immediately happen.
 			Eventually(func() bool {
@@ -224,15 +224,15 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 	})
 
 	Context("When create MGH instance with large scale data layer type", func() {
-		mgh := &globalhubv1alpha4.MulticlusterGlobalHub{
+		mgh := &globalhubv1beta1.MulticlusterGlobalHub{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      MGHName,
 				Namespace: commonutils.GetDefaultNamespace(),
 			},
-			Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{
-				DataLayer: globalhubv1alpha4.DataLayerConfig{
-					Kafka: globalhubv1alpha4.KafkaConfig{},
-					Postgres: globalhubv1alpha4.PostgresConfig{
+			Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+				DataLayer: globalhubv1beta1.DataLayerConfig{
+					Kafka: globalhubv1beta1.KafkaConfig{},
+					Postgres: globalhubv1beta1.PostgresConfig{
 						Retention: "1y",
 					},
 				},
@@ -276,14 +276,14 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 			mgh.SetNamespace(commonutils.GetDefaultNamespace())
 			Expect(k8sClient.Create(ctx, mgh)).Should(Succeed())
 
-			createdMGH := &globalhubv1alpha4.MulticlusterGlobalHub{}
+			createdMGH := &globalhubv1beta1.MulticlusterGlobalHub{}
 			// get this newly created MGH instance, given that creation may not immediately happen.
 			Eventually(func() error {
 				return k8sClient.Get(ctx, client.ObjectKeyFromObject(mgh), createdMGH)
 			}, timeout, interval).Should(Succeed())
 
 			// make sure the default values are filled
-			// Expect(createdMGH.Spec.AggregationLevel).Should(Equal(globalhubv1alpha4.Full))
+			// Expect(createdMGH.Spec.AggregationLevel).Should(Equal(globalhubv1beta1.Full))
 			// Expect(createdMGH.Spec.EnableLocalPolicies).Should(Equal(true))
 
 			Eventually(func() error {
@@ -614,7 +614,7 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 			}, timeout, interval).Should(Succeed())
 
 			By("By checking the kafkaBootstrapServer")
-			createdMGH := &globalhubv1alpha4.MulticlusterGlobalHub{}
+			createdMGH := &globalhubv1beta1.MulticlusterGlobalHub{}
 			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(mgh), createdMGH)).Should(Succeed())
 			secretTrasnporter := transportprotocol.NewBYOTransporter(ctx, types.NamespacedName{
 				Name: constants.GHTransportSecretName, Namespace: commonutils.GetDefaultNamespace(),
@@ -913,9 +913,9 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 	})
 
 	Context("Reconcile the Postgres and kafka resources", func() {
-		mcgh := &globalhubv1alpha4.MulticlusterGlobalHub{}
+		mcgh := &globalhubv1beta1.MulticlusterGlobalHub{}
 		It("Should create the MGH instance", func() {
-			mcgh = &globalhubv1alpha4.MulticlusterGlobalHub{
+			mcgh = &globalhubv1beta1.MulticlusterGlobalHub{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      MGHName,
 					Namespace: commonutils.GetDefaultNamespace(),
@@ -923,7 +923,7 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 						operatorconstants.AnnotationMGHInstallCrunchyOperator: "true",
 					},
 				},
-				Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+				Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 			}
 			Expect(k8sClient.Create(ctx, mcgh)).Should(Succeed())
 		})
@@ -986,7 +986,7 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 			Expect(k8sClient.Delete(ctx, mcgh)).Should(Succeed())
 			// ensure the mcgh is deleted
 			Eventually(func() error {
-				err := k8sClient.Get(ctx, namespacedName, &globalhubv1alpha4.MulticlusterGlobalHub{})
+				err := k8sClient.Get(ctx, namespacedName, &globalhubv1beta1.MulticlusterGlobalHub{})
 				if err != nil {
 					if errors.IsNotFound(err) {
 						return nil
@@ -999,14 +999,14 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 	})
 
 	Context("Reconcile the Postgres database", Ordered, func() {
-		mcgh := &globalhubv1alpha4.MulticlusterGlobalHub{}
+		mcgh := &globalhubv1beta1.MulticlusterGlobalHub{}
 		It("Should create the MGH instance", func() {
-			mcgh = &globalhubv1alpha4.MulticlusterGlobalHub{
+			mcgh = &globalhubv1beta1.MulticlusterGlobalHub{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      MGHName,
 					Namespace: "default",
 				},
-				Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+				Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 			}
 			Expect(k8sClient.Create(ctx, mcgh)).Should(Succeed())
 		})
@@ -1049,7 +1049,7 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 			Expect(k8sClient.Delete(ctx, mcgh)).Should(Succeed())
 			// ensure the mcgh is deleted
 			Eventually(func() error {
-				err := k8sClient.Get(ctx, namespacedName, &globalhubv1alpha4.MulticlusterGlobalHub{})
+				err := k8sClient.Get(ctx, namespacedName, &globalhubv1beta1.MulticlusterGlobalHub{})
 				if err != nil {
 					if errors.IsNotFound(err) {
 						return nil
@@ -1066,12 +1066,12 @@ var _ = Describe("MulticlusterGlobalHub controller", Ordered, func() {
 			// create kafkacluster
 			Expect(kafka.UpdateKafkaClusterReady(k8sClient, commonutils.GetDefaultNamespace())).ToNot(HaveOccurred())
 
-			mcgh := &globalhubv1alpha4.MulticlusterGlobalHub{
+			mcgh := &globalhubv1beta1.MulticlusterGlobalHub{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      MGHName,
 					Namespace: commonutils.GetDefaultNamespace(),
 				},
-				Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{
+				Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
 					Tolerations: []corev1.Toleration{
 						{
 							Key:      "dedicated",
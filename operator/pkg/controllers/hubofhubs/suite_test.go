@@ -49,7 +49,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	hubofhubscontroller "github.com/stolostron/multicluster-global-hub/operator/pkg/controllers/hubofhubs"
 	commonobjects "github.com/stolostron/multicluster-global-hub/pkg/objects"
 	"github.com/stolostron/multicluster-global-hub/test/pkg/kafka"
@@ -116,7 +116,7 @@ var _ = BeforeSuite(func() {
 	Expect(appsubV1alpha1.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
 	Expect(chnv1.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
 	Expect(placementrulesv1.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
-	Expect(globalhubv1alpha4.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
+	Expect(globalhubv1beta1.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
 	Expect(applicationv1beta1.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
 	Expect(policyv1.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
 	Expect(mchv1.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
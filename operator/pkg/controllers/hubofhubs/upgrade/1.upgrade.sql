@@ -6,3 +6,16 @@ ALTER TABLE status.leaf_hubs ADD CONSTRAINT leaf_hubs_pkey PRIMARY KEY (cluster_
 
 ALTER TABLE status.leaf_hub_heartbeats ADD COLUMN IF NOT EXISTS status VARCHAR(10) DEFAULT 'active';
 CREATE INDEX IF NOT EXISTS leaf_hub_heartbeats_leaf_hub_status_idx ON status.leaf_hub_heartbeats(status);
+
+ALTER TABLE status.leaf_hub_heartbeats ADD COLUMN IF NOT EXISTS health jsonb;
+
+ALTER TABLE IF EXISTS status.compliance ADD COLUMN IF NOT EXISTS policy_generation bigint DEFAULT 0 NOT NULL;
+ALTER TABLE IF EXISTS spec.policy_rollouts ADD COLUMN IF NOT EXISTS stable_generation bigint DEFAULT 0 NOT NULL;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = 'status'
+        AND table_name = 'managed_cluster_addons' AND column_name = 'version') THEN
+        ALTER TABLE status.managed_cluster_addons RENAME COLUMN version TO hub_version;
+    END IF;
+END $$;
@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package conversion
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
+)
+
+const (
+	// CRDName is the name of the MulticlusterGlobalHub CRD
+	CRDName = "multiclusterglobalhubs.operator.open-cluster-management.io"
+
+	migrationInterval = 10 * time.Second
+)
+
+// CertDir is the directory of the serving certificate of the webhook server, it's mounted by OLM, or from the secret
+// issued by the service-ca operator when the operator is deployed by kustomize
+var CertDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+
+// SetupWebhookWithManager serves the conversion between the MulticlusterGlobalHub versions on the /convert path. the
+// webhook is only enabled when the serving certificate is mounted, otherwise the webhook server can't be started.
+func SetupWebhookWithManager(mgr ctrl.Manager) (bool, error) {
+	if _, err := os.Stat(filepath.Join(CertDir, "tls.crt")); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, ctrl.NewWebhookManagedBy(mgr).For(&globalhubv1beta1.MulticlusterGlobalHub{}).Complete()
+}
+
+// AddStorageVersionMigrator rewrites the existing MulticlusterGlobalHub instances with the storage version, then
+// removes the old versions from the stored versions of the CRD, so that the old versions can be dropped later.
+func AddStorageVersionMigrator(mgr ctrl.Manager) error {
+	return mgr.Add(&storageVersionMigrator{
+		log:    ctrl.Log.WithName("storage-version-migrator"),
+		client: mgr.GetClient(),
+		reader: mgr.GetAPIReader(),
+	})
+}
+
+type storageVersionMigrator struct {
+	log    logr.Logger
+	client client.Client
+	reader client.Reader
+}
+
+func (m *storageVersionMigrator) Start(ctx context.Context) error {
+	return wait.PollUntilContextCancel(ctx, migrationInterval, true, func(ctx context.Context) (bool, error) {
+		if err := m.migrate(ctx); err != nil {
+			m.log.Error(err, "failed to migrate the storage version, retrying")
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+func (m *storageVersionMigrator) migrate(ctx context.Context) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := m.reader.Get(ctx, types.NamespacedName{Name: CRDName}, crd); err != nil {
+		return err
+	}
+
+	storageVersion := ""
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			storageVersion = version.Name
+		}
+	}
+	if storageVersion != globalhubv1beta1.GroupVersion.Version {
+		m.log.Info("skip the migration since the storage version isn't the expected version",
+			"storageVersion", storageVersion, "expectedVersion", globalhubv1beta1.GroupVersion.Version)
+		return nil
+	}
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storageVersion {
+		return nil
+	}
+
+	// an update without any change rewrites the instance with the storage version
+	mghList := &globalhubv1beta1.MulticlusterGlobalHubList{}
+	if err := m.reader.List(ctx, mghList); err != nil {
+		return err
+	}
+	for i := range mghList.Items {
+		mgh := &mghList.Items[i]
+		if err := m.client.Update(ctx, mgh); err != nil {
+			return fmt.Errorf("failed to migrate %s/%s: %w", mgh.Namespace, mgh.Name, err)
+		}
+	}
+
+	m.log.Info("migrated the instances to the storage version", "storedVersions", crd.Status.StoredVersions,
+		"storageVersion", storageVersion, "instances", len(mghList.Items))
+	crd.Status.StoredVersions = []string{storageVersion}
+	return m.client.Status().Update(ctx, crd)
+}
@@ -0,0 +1,277 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+const (
+	// FieldManager is the field manager of the objects applied by the operator
+	FieldManager = "multicluster-global-hub-operator"
+	// InventoryKey is the key of the applied objects in the inventory configmap
+	InventoryKey = "objects"
+	// ComponentLabelKey is the label of the component which the object is deployed for, it's used to rebuild the
+	// inventory of the component once the inventory configmap is missing
+	ComponentLabelKey = "global-hub.open-cluster-management.io/component"
+
+	skipCreationIfExistAnnotation = "skip-creation-if-exist"
+)
+
+// ComponentDeployer is a Deployer which records the objects deployed for a component, and prunes the objects which
+// were deployed before but aren't rendered any more
+type ComponentDeployer interface {
+	Deployer
+	// Component returns the name of the component, e.g. manager, grafana
+	Component() string
+	// Prune deletes the objects which aren't deployed in this round, it should only be called once all the rendered
+	// objects are deployed successfully
+	Prune(ctx context.Context) error
+}
+
+// ObjectRef identifies an object recorded in the inventory
+type ObjectRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+func (r ObjectRef) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s/%s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+func newObjectRef(obj *unstructured.Unstructured) ObjectRef {
+	return ObjectRef{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// ApplyDeployer is an implementation of ComponentDeployer interface with the server side apply. the objects applied
+// for the component are recorded in the inventory configmap in the namespace of the owner
+type ApplyDeployer struct {
+	client    client.Client
+	component string
+	owner     client.Object
+	deployed  map[ObjectRef]struct{}
+	// kinds are the kinds of the objects which might be deployed for the component, they're listed together with the
+	// kinds of the deployed objects to rebuild the missing inventory
+	kinds []schema.GroupVersionKind
+}
+
+// NewApplyDeployer creates a new ApplyDeployer, the inventory configmap is owned by the owner so that it's garbage
+// collected together with the owner. the kinds are required to prune the objects of the component when none of them
+// is deployed, e.g. the component is disabled, and the inventory configmap is missing
+func NewApplyDeployer(client client.Client, component string, owner client.Object,
+	kinds ...schema.GroupVersionKind,
+) ComponentDeployer {
+	return &ApplyDeployer{
+		client:    client,
+		component: component,
+		owner:     owner,
+		deployed:  map[ObjectRef]struct{}{},
+		kinds:     kinds,
+	}
+}
+
+// InventoryName returns the name of the inventory configmap of the component
+func InventoryName(component string) string {
+	return fmt.Sprintf("multicluster-global-hub-%s-inventory", component)
+}
+
+func (d *ApplyDeployer) Component() string {
+	return d.component
+}
+
+func (d *ApplyDeployer) Deploy(unsObj *unstructured.Unstructured) error {
+	// record the object before applying it, so that it isn't pruned even if it fails to be applied
+	d.deployed[newObjectRef(unsObj)] = struct{}{}
+
+	labels := unsObj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ComponentLabelKey] = d.component
+	unsObj.SetLabels(labels)
+
+	// if resource has annotation skip-creation-if-exist: true, then it will not be updated
+	if strings.ToLower(unsObj.GetAnnotations()[skipCreationIfExistAnnotation]) == "true" {
+		foundObj := &unstructured.Unstructured{}
+		foundObj.SetGroupVersionKind(unsObj.GroupVersionKind())
+		err := d.client.Get(context.TODO(),
+			types.NamespacedName{Name: unsObj.GetName(), Namespace: unsObj.GetNamespace()}, foundObj)
+		if err == nil {
+			return nil
+		}
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	unsObj.SetResourceVersion("")
+	unsObj.SetManagedFields(nil)
+	return d.client.Patch(context.TODO(), unsObj, client.Apply, client.FieldOwner(FieldManager),
+		client.ForceOwnership)
+}
+
+// Prune deletes the objects in the inventory which aren't deployed in this round. it prunes all the objects of the
+// component if nothing is deployed, so it's also called when the component is disabled.
+func (d *ApplyDeployer) Prune(ctx context.Context) error {
+	inventory, found, err := d.loadInventory(ctx)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if inventory, err = d.listComponentObjects(ctx); err != nil {
+			return err
+		}
+	}
+
+	// the objects failed to be pruned are kept in the inventory, so that they're pruned in the next round
+	remaining := map[ObjectRef]struct{}{}
+	for ref := range d.deployed {
+		remaining[ref] = struct{}{}
+	}
+	var errs []string
+	for _, ref := range inventory {
+		if _, ok := d.deployed[ref]; ok {
+			continue
+		}
+		if err := d.delete(ctx, ref); err != nil {
+			remaining[ref] = struct{}{}
+			errs = append(errs, fmt.Sprintf("%s: %v", ref, err))
+		}
+	}
+
+	if err := d.saveInventory(ctx, remaining); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to prune the %s objects: %s", d.component, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// delete removes the object unless it's gone, or it isn't owned by the operator any more
+func (d *ApplyDeployer) delete(ctx context.Context, ref ObjectRef) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+	err := d.client.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, obj)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if obj.GetLabels()[constants.GlobalHubOwnerLabelKey] != constants.GHOperatorOwnerLabelVal {
+		return nil
+	}
+	if err := d.client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil &&
+		!errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// loadInventory returns the objects in the inventory configmap, and whether the configmap exists
+func (d *ApplyDeployer) loadInventory(ctx context.Context) ([]ObjectRef, bool, error) {
+	cm := &corev1.ConfigMap{}
+	err := d.client.Get(ctx, types.NamespacedName{
+		Namespace: d.owner.GetNamespace(),
+		Name:      InventoryName(d.component),
+	}, cm)
+	if errors.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	inventory := []ObjectRef{}
+	if err := json.Unmarshal([]byte(cm.Data[InventoryKey]), &inventory); err != nil {
+		return nil, false, fmt.Errorf("failed to parse the inventory of %s: %w", d.component, err)
+	}
+	return inventory, true, nil
+}
+
+// listComponentObjects rebuilds the inventory from the objects labeled with the owner and the component, the kinds of
+// the deployed objects and the given kinds are listed in the namespace of the owner and the cluster scope
+func (d *ApplyDeployer) listComponentObjects(ctx context.Context) ([]ObjectRef, error) {
+	kinds := map[schema.GroupVersionKind]struct{}{}
+	for _, gvk := range d.kinds {
+		kinds[gvk] = struct{}{}
+	}
+	for ref := range d.deployed {
+		kinds[schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)] = struct{}{}
+	}
+
+	inventory := []ObjectRef{}
+	for gvk := range kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err := d.client.List(ctx, list, client.InNamespace(d.owner.GetNamespace()), client.MatchingLabels{
+			constants.GlobalHubOwnerLabelKey: constants.GHOperatorOwnerLabelVal,
+			ComponentLabelKey:                d.component,
+		})
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the %s objects of %s: %w", gvk.Kind, d.component, err)
+		}
+		for i := range list.Items {
+			inventory = append(inventory, newObjectRef(&list.Items[i]))
+		}
+	}
+	return inventory, nil
+}
+
+func (d *ApplyDeployer) saveInventory(ctx context.Context, refs map[ObjectRef]struct{}) error {
+	inventory := make([]ObjectRef, 0, len(refs))
+	for ref := range refs {
+		inventory = append(inventory, ref)
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		return inventory[i].String() < inventory[j].String() ||
+			(inventory[i].String() == inventory[j].String() && inventory[i].APIVersion < inventory[j].APIVersion)
+	})
+	data, err := json.Marshal(inventory)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      InventoryName(d.component),
+			Namespace: d.owner.GetNamespace(),
+			Labels: map[string]string{
+				constants.GlobalHubOwnerLabelKey: constants.GHOperatorOwnerLabelVal,
+			},
+		},
+		Data: map[string]string{InventoryKey: string(data)},
+	}
+	if err := controllerutil.SetControllerReference(d.owner, cm, d.client.Scheme()); err != nil {
+		return err
+	}
+	return d.client.Patch(ctx, cm, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+}
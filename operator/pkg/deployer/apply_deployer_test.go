@@ -0,0 +1,149 @@
+package deployer_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/stolostron/multicluster-global-hub/operator/pkg/deployer"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+func newOwnedConfigMap(name string, data map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+			"labels": map[string]interface{}{
+				constants.GlobalHubOwnerLabelKey: constants.GHOperatorOwnerLabelVal,
+			},
+		},
+		"data": data,
+	}}
+	return obj
+}
+
+var _ = Describe("ApplyDeployer", Ordered, func() {
+	var (
+		ctx   context.Context
+		owner *corev1.ConfigMap
+	)
+
+	BeforeAll(func() {
+		ctx = context.Background()
+		owner = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "apply-deployer-owner", Namespace: "default"},
+		}
+		Expect(k8sClient.Create(ctx, owner)).To(Succeed())
+	})
+
+	It("should apply the objects with the field manager and record the inventory", func() {
+		d := deployer.NewApplyDeployer(k8sClient, "test", owner)
+		Expect(d.Deploy(newOwnedConfigMap("apply-cm-1", map[string]interface{}{"key": "v1"}))).To(Succeed())
+		Expect(d.Deploy(newOwnedConfigMap("apply-cm-2", map[string]interface{}{"key": "v1"}))).To(Succeed())
+		Expect(d.Prune(ctx)).To(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "apply-cm-1"}, cm)).To(Succeed())
+		Expect(cm.Data["key"]).To(Equal("v1"))
+		managers := []string{}
+		for _, field := range cm.GetManagedFields() {
+			managers = append(managers, field.Manager)
+		}
+		Expect(managers).To(ContainElement(deployer.FieldManager))
+
+		inventory := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{
+			Namespace: "default", Name: deployer.InventoryName("test"),
+		}, inventory)).To(Succeed())
+		refs := []deployer.ObjectRef{}
+		Expect(json.Unmarshal([]byte(inventory.Data[deployer.InventoryKey]), &refs)).To(Succeed())
+		Expect(refs).To(HaveLen(2))
+		Expect(inventory.GetOwnerReferences()).To(HaveLen(1))
+	})
+
+	It("should prune the objects which aren't rendered any more", func() {
+		// the object is modified by others, it should be reverted by the apply
+		cm := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "apply-cm-1"}, cm)).To(Succeed())
+		cm.Data["key"] = "changed"
+		Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+
+		d := deployer.NewApplyDeployer(k8sClient, "test", owner)
+		Expect(d.Deploy(newOwnedConfigMap("apply-cm-1", map[string]interface{}{"key": "v1"}))).To(Succeed())
+		Expect(d.Prune(ctx)).To(Succeed())
+
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "apply-cm-1"}, cm)).To(Succeed())
+		Expect(cm.Data["key"]).To(Equal("v1"))
+
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "apply-cm-2"}, cm)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not prune the objects which aren't owned by the operator", func() {
+		d := deployer.NewApplyDeployer(k8sClient, "test", owner)
+		obj := newOwnedConfigMap("apply-cm-3", nil)
+		Expect(d.Deploy(obj)).To(Succeed())
+		Expect(d.Prune(ctx)).To(Succeed())
+
+		// the owner label is removed, e.g. the object is taken over by the user
+		cm := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "apply-cm-3"}, cm)).To(Succeed())
+		delete(cm.Labels, constants.GlobalHubOwnerLabelKey)
+		Expect(k8sClient.Update(ctx, cm)).To(Succeed())
+
+		d = deployer.NewApplyDeployer(k8sClient, "test", owner)
+		Expect(d.Prune(ctx)).To(Succeed())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "apply-cm-3"}, cm)).To(Succeed())
+	})
+
+	It("should prune all the objects of the disabled component with the missing inventory", func() {
+		d := deployer.NewApplyDeployer(k8sClient, "disabled", owner)
+		Expect(d.Deploy(newOwnedConfigMap("apply-cm-disabled", nil))).To(Succeed())
+		Expect(d.Prune(ctx)).To(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{
+			Namespace: "default", Name: "apply-cm-disabled",
+		}, cm)).To(Succeed())
+		Expect(cm.Labels).To(HaveKeyWithValue(deployer.ComponentLabelKey, "disabled"))
+
+		// the inventory is lost, it's rebuilt from the objects with the component label
+		Expect(k8sClient.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: deployer.InventoryName("disabled"),
+		}})).To(Succeed())
+
+		// nothing is deployed since the component is disabled
+		d = deployer.NewApplyDeployer(k8sClient, "disabled", owner, corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+		Expect(d.Prune(ctx)).To(Succeed())
+
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "apply-cm-disabled"}, cm)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		// the objects of the other components aren't pruned
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "apply-cm-1"}, cm)).To(Succeed())
+	})
+
+	It("should not update the object with the skip-creation-if-exist annotation", func() {
+		d := deployer.NewApplyDeployer(k8sClient, "skip", owner)
+		obj := newOwnedConfigMap("apply-cm-skip", map[string]interface{}{"key": "v1"})
+		obj.SetAnnotations(map[string]string{"skip-creation-if-exist": "true"})
+		Expect(d.Deploy(obj)).To(Succeed())
+
+		obj = newOwnedConfigMap("apply-cm-skip", map[string]interface{}{"key": "v2"})
+		obj.SetAnnotations(map[string]string{"skip-creation-if-exist": "true"})
+		Expect(d.Deploy(obj)).To(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "apply-cm-skip"}, cm)).To(Succeed())
+		Expect(cm.Data["key"]).To(Equal("v1"))
+	})
+})
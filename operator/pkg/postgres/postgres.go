@@ -10,7 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 )
 
@@ -51,12 +51,16 @@ type PostgresConnection struct {
 	// readonly user connection
 	// it is used for read the database by the grafana
 	ReadonlyUserDatabaseURI string
+	// the optional read replica connections, the replica serves the API of the manager and the grafana, so that
+	// the dashboards don't slow down the status ingestion in the primary database
+	ReplicaDatabaseURI             string
+	ReplicaReadonlyUserDatabaseURI string
 	// ca certificate
 	CACert []byte
 }
 
 // NewSubscription returns an CrunchyPostgres subscription with desired default values
-func NewSubscription(m *globalhubv1alpha4.MulticlusterGlobalHub, c *subv1alpha1.SubscriptionConfig,
+func NewSubscription(m *globalhubv1beta1.MulticlusterGlobalHub, c *subv1alpha1.SubscriptionConfig,
 	community bool,
 ) *subv1alpha1.Subscription {
 	chName, pkgName, catSourceName := channel, packageName, catalogSourceName
@@ -6,40 +6,40 @@ import (
 	subv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 )
 
 func TestNewSubscription(t *testing.T) {
-	sub := NewSubscription(&globalhubv1alpha4.MulticlusterGlobalHub{
+	sub := NewSubscription(&globalhubv1beta1.MulticlusterGlobalHub{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "default",
 			Name:      "globalhub",
 		},
-		Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 	}, &subv1alpha1.SubscriptionConfig{}, true)
 
 	if sub.Spec.Package != communityPackageName {
 		t.Errorf("Expected package name %s, got %s", communityPackageName, sub.Spec.Package)
 	}
 
-	sub = NewSubscription(&globalhubv1alpha4.MulticlusterGlobalHub{
+	sub = NewSubscription(&globalhubv1beta1.MulticlusterGlobalHub{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "default",
 			Name:      "globalhub",
 		},
-		Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 	}, &subv1alpha1.SubscriptionConfig{}, false)
 
 	if sub.Spec.Package != packageName {
 		t.Errorf("Expected package name %s, got %s", packageName, sub.Spec.Package)
 	}
 
-	sub = NewSubscription(&globalhubv1alpha4.MulticlusterGlobalHub{
+	sub = NewSubscription(&globalhubv1beta1.MulticlusterGlobalHub{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "default",
 			Name:      "globalhub",
 		},
-		Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 	}, &subv1alpha1.SubscriptionConfig{
 		NodeSelector: map[string]string{
 			"foo": "bar",
@@ -21,7 +21,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	operatorv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	operatorv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	operatorconstants "github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/utils"
@@ -91,7 +91,7 @@ type strimziTransporter struct {
 	subPackageName       string
 
 	// global hub config
-	mgh           *operatorv1alpha4.MulticlusterGlobalHub
+	mgh           *operatorv1beta1.MulticlusterGlobalHub
 	runtimeClient client.Client
 
 	// wait until kafka cluster status is ready when initialize
@@ -103,7 +103,7 @@ type strimziTransporter struct {
 
 type KafkaOption func(*strimziTransporter)
 
-func NewStrimziTransporter(c client.Client, mgh *operatorv1alpha4.MulticlusterGlobalHub,
+func NewStrimziTransporter(c client.Client, mgh *operatorv1beta1.MulticlusterGlobalHub,
 	opts ...KafkaOption,
 ) (*strimziTransporter, error) {
 	k := &strimziTransporter{
@@ -137,7 +137,7 @@ func NewStrimziTransporter(c client.Client, mgh *operatorv1alpha4.MulticlusterGl
 		k.subCatalogSourceName = CommunityCatalogSourceName
 	}
 
-	if mgh.Spec.AvailabilityConfig == operatorv1alpha4.HABasic {
+	if mgh.Spec.AvailabilityConfig == operatorv1beta1.HABasic {
 		k.topicPartitionReplicas = 1
 	}
 
@@ -177,7 +177,7 @@ func WithWaitReady(wait bool) KafkaOption {
 }
 
 // initialize the kafka cluster, return nil if the instance is launched successfully!
-func (k *strimziTransporter) initialize(mgh *operatorv1alpha4.MulticlusterGlobalHub) error {
+func (k *strimziTransporter) initialize(mgh *operatorv1beta1.MulticlusterGlobalHub) error {
 	k.log.Info("reconcile global hub kafka subscription")
 	k.namespace = mgh.Namespace
 	err := k.ensureSubscription(mgh)
@@ -581,7 +581,7 @@ func (k *strimziTransporter) kafkaClusterReady() error {
 	return err
 }
 
-func (k *strimziTransporter) createUpdateKafkaCluster(mgh *operatorv1alpha4.MulticlusterGlobalHub) (error, bool) {
+func (k *strimziTransporter) createUpdateKafkaCluster(mgh *operatorv1beta1.MulticlusterGlobalHub) (error, bool) {
 	existingKafka := &kafkav1beta2.Kafka{}
 	err := k.runtimeClient.Get(k.ctx, types.NamespacedName{
 		Name:      k.name,
@@ -622,7 +622,7 @@ func (k *strimziTransporter) createUpdateKafkaCluster(mgh *operatorv1alpha4.Mult
 }
 
 func (k *strimziTransporter) getKafkaResources(
-	mgh *operatorv1alpha4.MulticlusterGlobalHub,
+	mgh *operatorv1beta1.MulticlusterGlobalHub,
 ) *kafkav1beta2.KafkaSpecKafkaResources {
 	kafkaRes := utils.GetResources(operatorconstants.Kafka, mgh.Spec.AdvancedConfig)
 	kafkaSpecRes := &kafkav1beta2.KafkaSpecKafkaResources{}
@@ -639,7 +639,7 @@ func (k *strimziTransporter) getKafkaResources(
 }
 
 func (k *strimziTransporter) getZookeeperResources(
-	mgh *operatorv1alpha4.MulticlusterGlobalHub,
+	mgh *operatorv1beta1.MulticlusterGlobalHub,
 ) *kafkav1beta2.KafkaSpecZookeeperResources {
 	zookeeperRes := utils.GetResources(operatorconstants.Zookeeper, mgh.Spec.AdvancedConfig)
 
@@ -655,7 +655,7 @@ func (k *strimziTransporter) getZookeeperResources(
 	return zookeeperSpecRes
 }
 
-func (k *strimziTransporter) newKafkaCluster(mgh *operatorv1alpha4.MulticlusterGlobalHub) *kafkav1beta2.Kafka {
+func (k *strimziTransporter) newKafkaCluster(mgh *operatorv1beta1.MulticlusterGlobalHub) *kafkav1beta2.Kafka {
 	storageSize := config.GetKafkaStorageSize(mgh)
 
 	kafkaSpecKafkaStorageVolumesElem := kafkav1beta2.KafkaSpecKafkaStorageVolumesElem{
@@ -714,7 +714,7 @@ func (k *strimziTransporter) newKafkaCluster(mgh *operatorv1alpha4.MulticlusterG
 				Authorization: &kafkav1beta2.KafkaSpecKafkaAuthorization{
 					Type: kafkav1beta2.KafkaSpecKafkaAuthorizationTypeSimple,
 				},
-				Replicas: 3,
+				Replicas: utils.GetReplicas(operatorconstants.Kafka, mgh.Spec.AdvancedConfig, 3),
 				Storage: kafkav1beta2.KafkaSpecKafkaStorage{
 					Type: kafkav1beta2.KafkaSpecKafkaStorageTypeJbod,
 					Volumes: []kafkav1beta2.KafkaSpecKafkaStorageVolumesElem{
@@ -724,7 +724,7 @@ func (k *strimziTransporter) newKafkaCluster(mgh *operatorv1alpha4.MulticlusterG
 				Version: &KafkaVersion,
 			},
 			Zookeeper: kafkav1beta2.KafkaSpecZookeeper{
-				Replicas:  3,
+				Replicas:  utils.GetReplicas(operatorconstants.Zookeeper, mgh.Spec.AdvancedConfig, 3),
 				Storage:   kafkaSpecZookeeperStorage,
 				Resources: k.getZookeeperResources(mgh),
 			},
@@ -737,13 +737,14 @@ func (k *strimziTransporter) newKafkaCluster(mgh *operatorv1alpha4.MulticlusterG
 
 	k.setAffinity(mgh, kafkaCluster)
 	k.setTolerations(mgh, kafkaCluster)
+	k.setPodTemplates(mgh, kafkaCluster)
 	k.setMetricsConfig(mgh, kafkaCluster)
 
 	return kafkaCluster
 }
 
 // set metricsConfig for kafka cluster based on the mgh enableMetrics
-func (k *strimziTransporter) setMetricsConfig(mgh *operatorv1alpha4.MulticlusterGlobalHub,
+func (k *strimziTransporter) setMetricsConfig(mgh *operatorv1beta1.MulticlusterGlobalHub,
 	kafkaCluster *kafkav1beta2.Kafka) {
 	kafkaMetricsConfig := &kafkav1beta2.KafkaSpecKafkaMetricsConfig{}
 	zookeeperMetricsConfig := &kafkav1beta2.KafkaSpecZookeeperMetricsConfig{}
@@ -771,143 +772,231 @@ func (k *strimziTransporter) setMetricsConfig(mgh *operatorv1alpha4.Multicluster
 	}
 }
 
-// set affinity for kafka cluster based on the mgh nodeSelector
-func (k *strimziTransporter) setAffinity(mgh *operatorv1alpha4.MulticlusterGlobalHub,
+// set affinity for kafka cluster based on the nodeSelector of the components
+func (k *strimziTransporter) setAffinity(mgh *operatorv1beta1.MulticlusterGlobalHub,
 	kafkaCluster *kafkav1beta2.Kafka) {
-	kafkaPodAffinity := &kafkav1beta2.KafkaSpecKafkaTemplatePodAffinity{}
-	zookeeperPodAffinity := &kafkav1beta2.KafkaSpecZookeeperTemplatePodAffinity{}
-	entityOperatorPodAffinity := &kafkav1beta2.KafkaSpecEntityOperatorTemplatePodAffinity{}
-
-	if mgh.Spec.NodeSelector != nil {
-		nodeSelectorReqs := []corev1.NodeSelectorRequirement{}
-
-		for key, value := range mgh.Spec.NodeSelector {
-			req := corev1.NodeSelectorRequirement{
-				Key:      key,
-				Operator: corev1.NodeSelectorOpIn,
-				Values:   []string{value},
-			}
-			nodeSelectorReqs = append(nodeSelectorReqs, req)
-		}
-		nodeSelectorTerms := []corev1.NodeSelectorTerm{
-			{
-				MatchExpressions: nodeSelectorReqs,
+	if nodeSelector := utils.GetNodeSelector(operatorconstants.Kafka, mgh); nodeSelector != nil {
+		kafkaNodeSelectorTermsElem := make([]kafkav1beta2.KafkaSpecKafkaTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecutionNodeSelectorTermsElem, 0) // #nosec S103
+		k.unmarshalNodeSelectorTerms(nodeSelector, &kafkaNodeSelectorTermsElem)
+		kafkaPodTemplate(kafkaCluster).Affinity = &kafkav1beta2.KafkaSpecKafkaTemplatePodAffinity{
+			NodeAffinity: &kafkav1beta2.KafkaSpecKafkaTemplatePodAffinityNodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &kafkav1beta2.KafkaSpecKafkaTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecution{ // #nosec S103
+					NodeSelectorTerms: kafkaNodeSelectorTermsElem,
+				},
 			},
 		}
+	}
 
-		jsonData, err := json.Marshal(nodeSelectorTerms)
-		if err != nil {
-			k.log.Error(err, "failed to marshall nodeSelector terms")
+	if nodeSelector := utils.GetNodeSelector(operatorconstants.Zookeeper, mgh); nodeSelector != nil {
+		zookeeperNodeSelectorTermsElem := make([]kafkav1beta2.KafkaSpecZookeeperTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecutionNodeSelectorTermsElem, 0) // #nosec S103
+		k.unmarshalNodeSelectorTerms(nodeSelector, &zookeeperNodeSelectorTermsElem)
+		zookeeperPodTemplate(kafkaCluster).Affinity = &kafkav1beta2.KafkaSpecZookeeperTemplatePodAffinity{
+			NodeAffinity: &kafkav1beta2.KafkaSpecZookeeperTemplatePodAffinityNodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &kafkav1beta2.KafkaSpecZookeeperTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecution{ // #nosec S103
+					NodeSelectorTerms: zookeeperNodeSelectorTermsElem,
+				},
+			},
 		}
+	}
 
-		kafkaNodeSelectorTermsElem := make([]kafkav1beta2.KafkaSpecKafkaTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecutionNodeSelectorTermsElem, 0)                   // #nosec S103
-		zookeeperNodeSelectorTermsElem := make([]kafkav1beta2.KafkaSpecZookeeperTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecutionNodeSelectorTermsElem, 0)           // #nosec S103
+	if mgh.Spec.NodeSelector != nil {
 		entityOperatorNodeSelectorTermsElem := make([]kafkav1beta2.KafkaSpecEntityOperatorTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecutionNodeSelectorTermsElem, 0) // #nosec S103
-
-		err = json.Unmarshal(jsonData, &kafkaNodeSelectorTermsElem)
-		if err != nil {
-			k.log.Error(err, "failed to unmarshal to kafkaNodeSelectorTermsElem")
-		}
-		err = json.Unmarshal(jsonData, &zookeeperNodeSelectorTermsElem)
-		if err != nil {
-			k.log.Error(err, "failed to unmarshal to zookeeperNodeSelectorTermsElem")
-		}
-		err = json.Unmarshal(jsonData, &entityOperatorNodeSelectorTermsElem)
-		if err != nil {
-			k.log.Error(err, "failed to unmarshal to entityOperatorNodeSelectorTermsElem")
-		}
-
-		zookeeperPodAffinity.NodeAffinity = &kafkav1beta2.KafkaSpecZookeeperTemplatePodAffinityNodeAffinity{
-			RequiredDuringSchedulingIgnoredDuringExecution: &kafkav1beta2.KafkaSpecZookeeperTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecution{ // #nosec S103
-				NodeSelectorTerms: zookeeperNodeSelectorTermsElem,
-			},
-		}
-		kafkaPodAffinity.NodeAffinity = &kafkav1beta2.KafkaSpecKafkaTemplatePodAffinityNodeAffinity{
-			RequiredDuringSchedulingIgnoredDuringExecution: &kafkav1beta2.KafkaSpecKafkaTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecution{ // #nosec S103
-				NodeSelectorTerms: kafkaNodeSelectorTermsElem,
-			},
-		}
-		entityOperatorPodAffinity.NodeAffinity = &kafkav1beta2.KafkaSpecEntityOperatorTemplatePodAffinityNodeAffinity{
-			RequiredDuringSchedulingIgnoredDuringExecution: &kafkav1beta2.KafkaSpecEntityOperatorTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecution{ // #nosec S103
-				NodeSelectorTerms: entityOperatorNodeSelectorTermsElem,
+		k.unmarshalNodeSelectorTerms(mgh.Spec.NodeSelector, &entityOperatorNodeSelectorTermsElem)
+		entityOperatorPodTemplate(kafkaCluster).Affinity = &kafkav1beta2.KafkaSpecEntityOperatorTemplatePodAffinity{
+			NodeAffinity: &kafkav1beta2.KafkaSpecEntityOperatorTemplatePodAffinityNodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &kafkav1beta2.KafkaSpecEntityOperatorTemplatePodAffinityNodeAffinityRequiredDuringSchedulingIgnoredDuringExecution{ // #nosec S103
+					NodeSelectorTerms: entityOperatorNodeSelectorTermsElem,
+				},
 			},
 		}
+	}
+}
 
-		if kafkaCluster.Spec.Kafka.Template == nil {
-			kafkaCluster.Spec.Kafka.Template = &kafkav1beta2.KafkaSpecKafkaTemplate{
-				Pod: &kafkav1beta2.KafkaSpecKafkaTemplatePod{
-					Affinity: kafkaPodAffinity,
-				},
-			}
-			kafkaCluster.Spec.Zookeeper.Template = &kafkav1beta2.KafkaSpecZookeeperTemplate{
-				Pod: &kafkav1beta2.KafkaSpecZookeeperTemplatePod{
-					Affinity: zookeeperPodAffinity,
-				},
-			}
-			kafkaCluster.Spec.EntityOperator.Template = &kafkav1beta2.KafkaSpecEntityOperatorTemplate{
-				Pod: &kafkav1beta2.KafkaSpecEntityOperatorTemplatePod{
-					Affinity: entityOperatorPodAffinity,
-				},
-			}
-		} else {
-			kafkaCluster.Spec.Kafka.Template.Pod.Affinity = kafkaPodAffinity
-			kafkaCluster.Spec.Zookeeper.Template.Pod.Affinity = zookeeperPodAffinity
-			kafkaCluster.Spec.EntityOperator.Template.Pod.Affinity = entityOperatorPodAffinity
+// unmarshalNodeSelectorTerms converts the nodeSelector into the node selector terms of the strimzi pod template
+func (k *strimziTransporter) unmarshalNodeSelectorTerms(nodeSelector map[string]string, terms interface{}) {
+	nodeSelectorReqs := []corev1.NodeSelectorRequirement{}
+	for key, value := range nodeSelector {
+		req := corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{value},
 		}
+		nodeSelectorReqs = append(nodeSelectorReqs, req)
+	}
+	nodeSelectorTerms := []corev1.NodeSelectorTerm{
+		{
+			MatchExpressions: nodeSelectorReqs,
+		},
+	}
+
+	jsonData, err := json.Marshal(nodeSelectorTerms)
+	if err != nil {
+		k.log.Error(err, "failed to marshall nodeSelector terms")
+	}
+	if err = json.Unmarshal(jsonData, terms); err != nil {
+		k.log.Error(err, "failed to unmarshal to the nodeSelector terms", "type", fmt.Sprintf("%T", terms))
 	}
 }
 
-// setTolerations sets the kafka tolerations based on the mgh tolerations
-func (k *strimziTransporter) setTolerations(mgh *operatorv1alpha4.MulticlusterGlobalHub,
+// setTolerations sets the kafka tolerations based on the tolerations of the components
+func (k *strimziTransporter) setTolerations(mgh *operatorv1beta1.MulticlusterGlobalHub,
 	kafkaCluster *kafkav1beta2.Kafka) {
-	kafkaTolerationsElem := make([]kafkav1beta2.KafkaSpecKafkaTemplatePodTolerationsElem, 0)
-	zookeeperTolerationsElem := make([]kafkav1beta2.KafkaSpecZookeeperTemplatePodTolerationsElem, 0)
-	entityOperatorTolerationsElem := make([]kafkav1beta2.KafkaSpecEntityOperatorTemplatePodTolerationsElem, 0)
+	if tolerations := utils.GetTolerations(operatorconstants.Kafka, mgh); tolerations != nil {
+		kafkaTolerationsElem := make([]kafkav1beta2.KafkaSpecKafkaTemplatePodTolerationsElem, 0)
+		k.unmarshalTolerations(tolerations, &kafkaTolerationsElem)
+		kafkaPodTemplate(kafkaCluster).Tolerations = kafkaTolerationsElem
+	}
+
+	if tolerations := utils.GetTolerations(operatorconstants.Zookeeper, mgh); tolerations != nil {
+		zookeeperTolerationsElem := make([]kafkav1beta2.KafkaSpecZookeeperTemplatePodTolerationsElem, 0)
+		k.unmarshalTolerations(tolerations, &zookeeperTolerationsElem)
+		zookeeperPodTemplate(kafkaCluster).Tolerations = zookeeperTolerationsElem
+	}
 
 	if mgh.Spec.Tolerations != nil {
-		jsonData, err := json.Marshal(mgh.Spec.Tolerations)
-		if err != nil {
-			k.log.Error(err, "failed to marshal tolerations")
+		entityOperatorTolerationsElem := make([]kafkav1beta2.KafkaSpecEntityOperatorTemplatePodTolerationsElem, 0)
+		k.unmarshalTolerations(mgh.Spec.Tolerations, &entityOperatorTolerationsElem)
+		entityOperatorPodTemplate(kafkaCluster).Tolerations = entityOperatorTolerationsElem
+	}
+}
+
+func (k *strimziTransporter) unmarshalTolerations(tolerations []corev1.Toleration, elems interface{}) {
+	jsonData, err := json.Marshal(tolerations)
+	if err != nil {
+		k.log.Error(err, "failed to marshal tolerations")
+	}
+	if err = json.Unmarshal(jsonData, elems); err != nil {
+		k.log.Error(err, "failed to unmarshal to the tolerations", "type", fmt.Sprintf("%T", elems))
+	}
+}
+
+// setPodTemplates applies the pod settings in the kafka and zookeeper specs of the advanced config to the templates
+func (k *strimziTransporter) setPodTemplates(mgh *operatorv1beta1.MulticlusterGlobalHub,
+	kafkaCluster *kafkav1beta2.Kafka) {
+	if spec := utils.GetCommonSpec(operatorconstants.Kafka, mgh.Spec.AdvancedConfig); spec != nil {
+		existing := kafkaCluster.Spec.Kafka.Template
+		if existing == nil {
+			existing = &kafkav1beta2.KafkaSpecKafkaTemplate{}
 		}
-		err = json.Unmarshal(jsonData, &kafkaTolerationsElem)
-		if err != nil {
-			k.log.Error(err, "failed to unmarshal to KafkaSpecruntimeKafkaTemplatePodTolerationsElem")
+		template := &kafkav1beta2.KafkaSpecKafkaTemplate{}
+		if k.patchPodTemplate(spec, "kafkaContainer", existing, template) {
+			kafkaCluster.Spec.Kafka.Template = template
 		}
-		err = json.Unmarshal(jsonData, &zookeeperTolerationsElem)
-		if err != nil {
-			k.log.Error(err, "failed to unmarshal to KafkaSpecZookeeperTemplatePodTolerationsElem")
+	}
+
+	if spec := utils.GetCommonSpec(operatorconstants.Zookeeper, mgh.Spec.AdvancedConfig); spec != nil {
+		existing := kafkaCluster.Spec.Zookeeper.Template
+		if existing == nil {
+			existing = &kafkav1beta2.KafkaSpecZookeeperTemplate{}
 		}
-		err = json.Unmarshal(jsonData, &entityOperatorTolerationsElem)
-		if err != nil {
-			k.log.Error(err, "failed to unmarshal to KafkaSpecEntityOperatorTemplatePodTolerationsElem")
+		template := &kafkav1beta2.KafkaSpecZookeeperTemplate{}
+		if k.patchPodTemplate(spec, "zookeeperContainer", existing, template) {
+			kafkaCluster.Spec.Zookeeper.Template = template
 		}
+	}
+}
 
-		if kafkaCluster.Spec.Kafka.Template == nil {
-			kafkaCluster.Spec.Kafka.Template = &kafkav1beta2.KafkaSpecKafkaTemplate{
-				Pod: &kafkav1beta2.KafkaSpecKafkaTemplatePod{
-					Tolerations: kafkaTolerationsElem,
-				},
-			}
-			kafkaCluster.Spec.Zookeeper.Template = &kafkav1beta2.KafkaSpecZookeeperTemplate{
-				Pod: &kafkav1beta2.KafkaSpecZookeeperTemplatePod{
-					Tolerations: zookeeperTolerationsElem,
-				},
-			}
-			kafkaCluster.Spec.EntityOperator.Template = &kafkav1beta2.KafkaSpecEntityOperatorTemplate{
-				Pod: &kafkav1beta2.KafkaSpecEntityOperatorTemplatePod{
-					Tolerations: entityOperatorTolerationsElem,
-				},
-			}
-		} else {
-			kafkaCluster.Spec.Kafka.Template.Pod.Tolerations = kafkaTolerationsElem
-			kafkaCluster.Spec.Zookeeper.Template.Pod.Tolerations = zookeeperTolerationsElem
-			kafkaCluster.Spec.EntityOperator.Template.Pod.Tolerations = entityOperatorTolerationsElem
+// patchPodTemplate merges the pod settings of the spec into the existing strimzi template, and unmarshal the result
+// into the patched template. The affinity is merged with the node affinity generated from the nodeSelector. It
+// returns false if the template isn't patched.
+func (k *strimziTransporter) patchPodTemplate(spec *operatorv1beta1.CommonSpec, containerName string,
+	existing, patched interface{},
+) bool {
+	pod := map[string]interface{}{}
+	if spec.Affinity != nil {
+		pod["affinity"] = spec.Affinity
+	}
+	if len(spec.TopologySpreadConstraints) > 0 {
+		pod["topologySpreadConstraints"] = spec.TopologySpreadConstraints
+	}
+	if spec.PriorityClassName != "" {
+		pod["priorityClassName"] = spec.PriorityClassName
+	}
+	metadata := map[string]interface{}{}
+	if len(spec.PodLabels) > 0 {
+		metadata["labels"] = spec.PodLabels
+	}
+	if len(spec.PodAnnotations) > 0 {
+		metadata["annotations"] = spec.PodAnnotations
+	}
+	if len(metadata) > 0 {
+		pod["metadata"] = metadata
+	}
+
+	patch := map[string]interface{}{}
+	if len(pod) > 0 {
+		patch["pod"] = pod
+	}
+	// the strimzi container template only supports the env with a plain value
+	env := []map[string]string{}
+	for _, e := range spec.Env {
+		if e.ValueFrom != nil {
+			k.log.Info("skip the env with the valueFrom", "container", containerName, "env", e.Name)
+			continue
 		}
+		env = append(env, map[string]string{"name": e.Name, "value": e.Value})
+	}
+	if len(env) > 0 {
+		patch[containerName] = map[string]interface{}{"env": env}
+	}
+	if len(patch) == 0 {
+		return false
+	}
+
+	patchData, err := json.Marshal(patch)
+	if err != nil {
+		k.log.Error(err, "failed to marshal the pod template patch", "container", containerName)
+		return false
+	}
+	existingData, err := json.Marshal(existing)
+	if err != nil {
+		k.log.Error(err, "failed to marshal the pod template", "container", containerName)
+		return false
+	}
+	patchedData, err := jsonpatch.MergePatch(existingData, patchData)
+	if err != nil {
+		k.log.Error(err, "failed to patch the pod template", "container", containerName)
+		return false
+	}
+	if err = json.Unmarshal(patchedData, patched); err != nil {
+		k.log.Error(err, "failed to unmarshal the patched pod template", "container", containerName)
+		return false
+	}
+	return true
+}
+
+func kafkaPodTemplate(kafkaCluster *kafkav1beta2.Kafka) *kafkav1beta2.KafkaSpecKafkaTemplatePod {
+	if kafkaCluster.Spec.Kafka.Template == nil {
+		kafkaCluster.Spec.Kafka.Template = &kafkav1beta2.KafkaSpecKafkaTemplate{}
+	}
+	if kafkaCluster.Spec.Kafka.Template.Pod == nil {
+		kafkaCluster.Spec.Kafka.Template.Pod = &kafkav1beta2.KafkaSpecKafkaTemplatePod{}
+	}
+	return kafkaCluster.Spec.Kafka.Template.Pod
+}
+
+func zookeeperPodTemplate(kafkaCluster *kafkav1beta2.Kafka) *kafkav1beta2.KafkaSpecZookeeperTemplatePod {
+	if kafkaCluster.Spec.Zookeeper.Template == nil {
+		kafkaCluster.Spec.Zookeeper.Template = &kafkav1beta2.KafkaSpecZookeeperTemplate{}
+	}
+	if kafkaCluster.Spec.Zookeeper.Template.Pod == nil {
+		kafkaCluster.Spec.Zookeeper.Template.Pod = &kafkav1beta2.KafkaSpecZookeeperTemplatePod{}
+	}
+	return kafkaCluster.Spec.Zookeeper.Template.Pod
+}
+
+func entityOperatorPodTemplate(kafkaCluster *kafkav1beta2.Kafka) *kafkav1beta2.KafkaSpecEntityOperatorTemplatePod {
+	if kafkaCluster.Spec.EntityOperator.Template == nil {
+		kafkaCluster.Spec.EntityOperator.Template = &kafkav1beta2.KafkaSpecEntityOperatorTemplate{}
+	}
+	if kafkaCluster.Spec.EntityOperator.Template.Pod == nil {
+		kafkaCluster.Spec.EntityOperator.Template.Pod = &kafkav1beta2.KafkaSpecEntityOperatorTemplatePod{}
 	}
+	return kafkaCluster.Spec.EntityOperator.Template.Pod
 }
 
 // create/ update the kafka subscription
-func (k *strimziTransporter) ensureSubscription(mgh *operatorv1alpha4.MulticlusterGlobalHub) error {
+func (k *strimziTransporter) ensureSubscription(mgh *operatorv1beta1.MulticlusterGlobalHub) error {
 	// get subscription
 	existingSub := &subv1alpha1.Subscription{}
 	err := k.runtimeClient.Get(k.ctx, types.NamespacedName{
@@ -936,7 +1025,7 @@ func (k *strimziTransporter) ensureSubscription(mgh *operatorv1alpha4.Multiclust
 }
 
 // newSubscription returns an CrunchyPostgres subscription with desired default values
-func (k *strimziTransporter) newSubscription(mgh *operatorv1alpha4.MulticlusterGlobalHub) *subv1alpha1.Subscription {
+func (k *strimziTransporter) newSubscription(mgh *operatorv1beta1.MulticlusterGlobalHub) *subv1alpha1.Subscription {
 	labels := map[string]string{
 		"installer.name":                 mgh.Name,
 		"installer.namespace":            mgh.Namespace,
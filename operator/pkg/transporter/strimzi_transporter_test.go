@@ -23,10 +23,11 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	chnv1 "open-cluster-management.io/multicloud-operators-channel/pkg/apis/apps/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
-	"github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	"github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/test/pkg/kafka"
 )
 
@@ -85,13 +86,13 @@ func TestMain(m *testing.M) {
 }
 
 func TestStrimziTransporter(t *testing.T) {
-	mgh := &v1alpha4.MulticlusterGlobalHub{
+	mgh := &v1beta1.MulticlusterGlobalHub{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-mgh",
 			Namespace: "default",
 		},
-		Spec: v1alpha4.MulticlusterGlobalHubSpec{
-			DataLayer: v1alpha4.DataLayerConfig{},
+		Spec: v1beta1.MulticlusterGlobalHubSpec{
+			DataLayer: v1beta1.DataLayerConfig{},
 		},
 	}
 	trans, err := NewStrimziTransporter(
@@ -133,9 +134,9 @@ func TestStrimziTransporter(t *testing.T) {
 	customMemoryRequest := "1Mi"
 	customMemoryLimit := "2Mi"
 
-	mgh.Spec.AdvancedConfig = &v1alpha4.AdvancedConfig{
-		Kafka: &v1alpha4.CommonSpec{
-			Resources: &v1alpha4.ResourceRequirements{
+	mgh.Spec.AdvancedConfig = &v1beta1.AdvancedConfig{
+		Kafka: &v1beta1.CommonSpec{
+			Resources: &v1beta1.ResourceRequirements{
 				Limits: corev1.ResourceList{
 					corev1.ResourceName(corev1.ResourceCPU):    resource.MustParse(customCPULimit),
 					corev1.ResourceName(corev1.ResourceMemory): resource.MustParse(customMemoryLimit),
@@ -146,8 +147,8 @@ func TestStrimziTransporter(t *testing.T) {
 				},
 			},
 		},
-		Zookeeper: &v1alpha4.CommonSpec{
-			Resources: &v1alpha4.ResourceRequirements{
+		Zookeeper: &v1beta1.CommonSpec{
+			Resources: &v1beta1.ResourceRequirements{
 				Limits: corev1.ResourceList{
 					corev1.ResourceName(corev1.ResourceCPU):    resource.MustParse(customCPULimit),
 					corev1.ResourceName(corev1.ResourceMemory): resource.MustParse(customMemoryLimit),
@@ -309,3 +310,73 @@ func TestStrimziTransporter(t *testing.T) {
 	_, err = NewStrimziTransporter(runtimeClient, mgh, WithWaitReady(true))
 	assert.Nil(t, err)
 }
+
+func TestNewKafkaClusterWithPodSettings(t *testing.T) {
+	kafkaReplicas := int32(5)
+	mgh := &v1beta1.MulticlusterGlobalHub{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mgh",
+			Namespace: "default",
+		},
+		Spec: v1beta1.MulticlusterGlobalHubSpec{
+			NodeSelector: map[string]string{"node-role.kubernetes.io/worker": ""},
+			AdvancedConfig: &v1beta1.AdvancedConfig{
+				Kafka: &v1beta1.CommonSpec{
+					Replicas:     &kafkaReplicas,
+					NodeSelector: map[string]string{"node-role.kubernetes.io/storage": ""},
+					Tolerations: []corev1.Toleration{{
+						Key:      "node-role.kubernetes.io/storage",
+						Operator: corev1.TolerationOpExists,
+						Effect:   corev1.TaintEffectNoSchedule,
+					}},
+					Affinity: &corev1.Affinity{
+						PodAntiAffinity: &corev1.PodAntiAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+								TopologyKey: "kubernetes.io/hostname",
+							}},
+						},
+					},
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.DoNotSchedule,
+					}},
+					PriorityClassName: "high-priority",
+					PodLabels:         map[string]string{"team": "global-hub"},
+					Env: []corev1.EnvVar{
+						{Name: "KAFKA_HEAP_OPTS", Value: "-Xmx2g"},
+						{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{
+							FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+						}},
+					},
+				},
+			},
+		},
+	}
+	trans := &strimziTransporter{log: ctrl.Log.WithName("test"), name: KafkaClusterName, namespace: "default"}
+	kafka := trans.newKafkaCluster(mgh)
+
+	assert.Equal(t, int32(5), kafka.Spec.Kafka.Replicas)
+	assert.Equal(t, int32(3), kafka.Spec.Zookeeper.Replicas)
+
+	kafkaPod := kafka.Spec.Kafka.Template.Pod
+	// the node affinity is converted from the kafka nodeSelector and merged with the affinity
+	terms := kafkaPod.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Equal(t, "node-role.kubernetes.io/storage", *terms[0].MatchExpressions[0].Key)
+	assert.Len(t, kafkaPod.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, 1)
+	assert.Len(t, kafkaPod.Tolerations, 1)
+	assert.Len(t, kafkaPod.TopologySpreadConstraints, 1)
+	assert.Equal(t, "high-priority", *kafkaPod.PriorityClassName)
+	assert.JSONEq(t, `{"team":"global-hub"}`, string(kafkaPod.Metadata.Labels.Raw))
+	// only the env with a plain value is supported by strimzi
+	assert.Len(t, kafka.Spec.Kafka.Template.KafkaContainer.Env, 1)
+	assert.Equal(t, "KAFKA_HEAP_OPTS", *kafka.Spec.Kafka.Template.KafkaContainer.Env[0].Name)
+
+	// the zookeeper uses the global nodeSelector
+	zookeeperPod := kafka.Spec.Zookeeper.Template.Pod
+	for _, term := range zookeeperPod.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		assert.Equal(t, "node-role.kubernetes.io/worker", *term.MatchExpressions[0].Key)
+	}
+	assert.Empty(t, zookeeperPod.Tolerations)
+	assert.Nil(t, zookeeperPod.PriorityClassName)
+}
@@ -18,9 +18,11 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -38,7 +40,7 @@ import (
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/config"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
@@ -264,12 +266,12 @@ func CopyMap(newMap, originalMap map[string]string) {
 func WaitGlobalHubReady(ctx context.Context,
 	client client.Client,
 	interval time.Duration,
-) (*globalhubv1alpha4.MulticlusterGlobalHub, error) {
-	mghInstance := &globalhubv1alpha4.MulticlusterGlobalHub{}
+) (*globalhubv1beta1.MulticlusterGlobalHub, error) {
+	mghInstance := &globalhubv1beta1.MulticlusterGlobalHub{}
 	klog.Info("Wait MulticlusterGlobalHub ready")
 
 	if err := wait.PollImmediate(interval, 10*time.Minute, func() (bool, error) {
-		mghList := &globalhubv1alpha4.MulticlusterGlobalHubList{}
+		mghList := &globalhubv1beta1.MulticlusterGlobalHubList{}
 		err := client.List(ctx, mghList)
 		if err != nil {
 			klog.Error(err, "Failed to list MulticlusterGlobalHub")
@@ -297,7 +299,7 @@ func WaitGlobalHubReady(ctx context.Context,
 	return mghInstance, nil
 }
 
-func GetResources(component string, advanced *globalhubv1alpha4.AdvancedConfig) *corev1.ResourceRequirements {
+func GetResources(component string, advanced *globalhubv1beta1.AdvancedConfig) *corev1.ResourceRequirements {
 	resourceReq := corev1.ResourceRequirements{}
 	requests := corev1.ResourceList{}
 	limits := corev1.ResourceList{}
@@ -356,7 +358,159 @@ func GetResources(component string, advanced *globalhubv1alpha4.AdvancedConfig)
 	return &resourceReq
 }
 
-func setResourcesFromCR(res *globalhubv1alpha4.ResourceRequirements, requests, limits corev1.ResourceList) {
+// GetCommonSpec returns the spec of the component in the advanced config, it's nil if the spec isn't specified
+func GetCommonSpec(component string, advanced *globalhubv1beta1.AdvancedConfig) *globalhubv1beta1.CommonSpec {
+	if advanced == nil {
+		return nil
+	}
+	switch component {
+	case constants.Grafana:
+		return advanced.Grafana
+	case constants.Postgres:
+		return advanced.Postgres
+	case constants.Manager:
+		return advanced.Manager
+	case constants.Agent:
+		return advanced.Agent
+	case constants.Kafka:
+		return advanced.Kafka
+	case constants.Zookeeper:
+		return advanced.Zookeeper
+	}
+	return nil
+}
+
+// GetReplicas returns the replicas of the component, the defaultReplicas is used if it isn't specified
+func GetReplicas(component string, advanced *globalhubv1beta1.AdvancedConfig, defaultReplicas int32) int32 {
+	if spec := GetCommonSpec(component, advanced); spec != nil && spec.Replicas != nil {
+		return *spec.Replicas
+	}
+	return defaultReplicas
+}
+
+// GetNodeSelector returns the node selector of the component, which overrides the global node selector
+func GetNodeSelector(component string, mgh *globalhubv1beta1.MulticlusterGlobalHub) map[string]string {
+	if spec := GetCommonSpec(component, mgh.Spec.AdvancedConfig); spec != nil && spec.NodeSelector != nil {
+		return spec.NodeSelector
+	}
+	return mgh.Spec.NodeSelector
+}
+
+// GetTolerations returns the tolerations of the component, which overrides the global tolerations
+func GetTolerations(component string, mgh *globalhubv1beta1.MulticlusterGlobalHub) []corev1.Toleration {
+	if spec := GetCommonSpec(component, mgh.Spec.AdvancedConfig); spec != nil && spec.Tolerations != nil {
+		return spec.Tolerations
+	}
+	return mgh.Spec.Tolerations
+}
+
+// PodSpecValues are the pod settings of the component to render the manifest templates. The structured fields are
+// serialized into JSON, which is valid YAML, so that they can be inlined in the templates.
+type PodSpecValues struct {
+	Affinity                  string
+	TopologySpreadConstraints string
+	PriorityClassName         string
+	// PodAnnotations and PodLabels are the serialized `"key": "value"` entries ordered by the keys
+	PodAnnotations []string
+	PodLabels      []string
+	// Env is the list of the serialized environment variables
+	Env []string
+}
+
+// componentEnvNames are the environment variables defined by the manifests of the component, the extra ones can't
+// override them since the duplicated names are rejected by the server side apply.
+var componentEnvNames = map[string][]string{
+	constants.Manager:  {"POD_NAMESPACE", "DATABASE_URL", "DATABASE_REPLICA_URL", "WATCH_NAMESPACE", "LAUNCH_JOB_NAMES"},
+	constants.Grafana:  {"POD_IP"},
+	constants.Postgres: {"POSTGRESQL_ADMIN_PASSWORD", "POSTGRESQL_SHARED_BUFFERS", "POSTGRESQL_EFFECTIVE_CACHE_SIZE", "WORK_MEM"},
+	constants.Agent:    {"POD_NAMESPACE", "WATCH_NAMESPACE"},
+}
+
+// GetPodSpecValues returns the pod settings of the component, the pod labels with the selectorLabelKeys are dropped
+// since the selector of the workload can't be changed.
+func GetPodSpecValues(component string, advanced *globalhubv1beta1.AdvancedConfig,
+	selectorLabelKeys ...string,
+) (*PodSpecValues, error) {
+	values := &PodSpecValues{}
+	spec := GetCommonSpec(component, advanced)
+	if spec == nil {
+		return values, nil
+	}
+
+	values.PriorityClassName = spec.PriorityClassName
+	annotations, err := serializeEntries(spec.PodAnnotations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the podAnnotations of %s: %w", component, err)
+	}
+	values.PodAnnotations = annotations
+	if spec.Affinity != nil {
+		data, err := json.Marshal(spec.Affinity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal the affinity of %s: %w", component, err)
+		}
+		values.Affinity = string(data)
+	}
+	if len(spec.TopologySpreadConstraints) > 0 {
+		data, err := json.Marshal(spec.TopologySpreadConstraints)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal the topologySpreadConstraints of %s: %w", component, err)
+		}
+		values.TopologySpreadConstraints = string(data)
+	}
+	envNames := map[string]bool{}
+	for _, name := range componentEnvNames[component] {
+		envNames[name] = true
+	}
+	for _, env := range spec.Env {
+		if envNames[env.Name] {
+			return nil, fmt.Errorf("the env %s of %s is duplicated or defined by the operator", env.Name, component)
+		}
+		envNames[env.Name] = true
+		data, err := json.Marshal(env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal the env %s of %s: %w", env.Name, component, err)
+		}
+		values.Env = append(values.Env, string(data))
+	}
+	if len(spec.PodLabels) > 0 {
+		labels := map[string]string{}
+		for key, val := range spec.PodLabels {
+			labels[key] = val
+		}
+		for _, key := range selectorLabelKeys {
+			delete(labels, key)
+		}
+		values.PodLabels, err = serializeEntries(labels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal the podLabels of %s: %w", component, err)
+		}
+	}
+	return values, nil
+}
+
+// serializeEntries quotes the keys and the values as the json strings, so that any of them is a valid yaml scalar.
+func serializeEntries(entries map[string]string) ([]string, error) {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	serialized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		quotedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		quotedValue, err := json.Marshal(entries[key])
+		if err != nil {
+			return nil, err
+		}
+		serialized = append(serialized, fmt.Sprintf("%s: %s", quotedKey, quotedValue))
+	}
+	return serialized, nil
+}
+
+func setResourcesFromCR(res *globalhubv1beta1.ResourceRequirements, requests, limits corev1.ResourceList) {
 	if res != nil {
 		if res.Requests.Memory().String() != "0" {
 			requests[corev1.ResourceName(corev1.ResourceMemory)] = resource.MustParse(res.Requests.Memory().String())
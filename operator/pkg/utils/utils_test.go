@@ -18,6 +18,8 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
 	"testing"
 	"time"
 
@@ -26,8 +28,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
 
-	globalhubv1alpha4 "github.com/stolostron/multicluster-global-hub/operator/apis/v1alpha4"
+	globalhubv1beta1 "github.com/stolostron/multicluster-global-hub/operator/apis/v1beta1"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/condition"
 	"github.com/stolostron/multicluster-global-hub/operator/pkg/constants"
 )
@@ -277,12 +280,12 @@ func TestWaitGlobalHubReady(t *testing.T) {
 		{
 			name: "no mgh status",
 			mgh: []runtime.Object{
-				&globalhubv1alpha4.MulticlusterGlobalHub{
+				&globalhubv1beta1.MulticlusterGlobalHub{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test",
 						Namespace: "default",
 					},
-					Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+					Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 				},
 			},
 			returned: false,
@@ -296,7 +299,7 @@ func TestWaitGlobalHubReady(t *testing.T) {
 		{
 			name: "mgh is deleting",
 			mgh: []runtime.Object{
-				&globalhubv1alpha4.MulticlusterGlobalHub{
+				&globalhubv1beta1.MulticlusterGlobalHub{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:              "test",
 						Namespace:         "default",
@@ -305,7 +308,7 @@ func TestWaitGlobalHubReady(t *testing.T) {
 							"test",
 						},
 					},
-					Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
+					Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
 				},
 			},
 			returned: false,
@@ -313,13 +316,13 @@ func TestWaitGlobalHubReady(t *testing.T) {
 		{
 			name: "ready mgh",
 			mgh: []runtime.Object{
-				&globalhubv1alpha4.MulticlusterGlobalHub{
+				&globalhubv1beta1.MulticlusterGlobalHub{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "test",
 						Namespace: "default",
 					},
-					Spec: globalhubv1alpha4.MulticlusterGlobalHubSpec{},
-					Status: globalhubv1alpha4.MulticlusterGlobalHubStatus{
+					Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{},
+					Status: globalhubv1beta1.MulticlusterGlobalHubStatus{
 						Conditions: []metav1.Condition{
 							{
 								Type:   condition.CONDITION_TYPE_GLOBALHUB_READY,
@@ -335,7 +338,7 @@ func TestWaitGlobalHubReady(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := runtime.NewScheme()
-			globalhubv1alpha4.AddToScheme(s)
+			globalhubv1beta1.AddToScheme(s)
 
 			runtimeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(tt.mgh...).Build()
 			returned := false
@@ -360,7 +363,7 @@ func Test_GetResources(t *testing.T) {
 	tests := []struct {
 		name          string
 		component     string
-		advanced      func(resReq *globalhubv1alpha4.ResourceRequirements) *globalhubv1alpha4.AdvancedConfig
+		advanced      func(resReq *globalhubv1beta1.ResourceRequirements) *globalhubv1beta1.AdvancedConfig
 		cpuRequest    string
 		cpuLimit      string
 		memoryRequest string
@@ -378,9 +381,9 @@ func Test_GetResources(t *testing.T) {
 		{
 			name:      "Test Grafana with customized values",
 			component: constants.Grafana,
-			advanced: func(resReq *globalhubv1alpha4.ResourceRequirements) *globalhubv1alpha4.AdvancedConfig {
-				return &globalhubv1alpha4.AdvancedConfig{
-					Grafana: &globalhubv1alpha4.CommonSpec{
+			advanced: func(resReq *globalhubv1beta1.ResourceRequirements) *globalhubv1beta1.AdvancedConfig {
+				return &globalhubv1beta1.AdvancedConfig{
+					Grafana: &globalhubv1beta1.CommonSpec{
 						Resources: resReq,
 					},
 				}
@@ -398,9 +401,9 @@ func Test_GetResources(t *testing.T) {
 		{
 			name:      "Test Postgres with customized values",
 			component: constants.Postgres,
-			advanced: func(resReq *globalhubv1alpha4.ResourceRequirements) *globalhubv1alpha4.AdvancedConfig {
-				return &globalhubv1alpha4.AdvancedConfig{
-					Postgres: &globalhubv1alpha4.CommonSpec{
+			advanced: func(resReq *globalhubv1beta1.ResourceRequirements) *globalhubv1beta1.AdvancedConfig {
+				return &globalhubv1beta1.AdvancedConfig{
+					Postgres: &globalhubv1beta1.CommonSpec{
 						Resources: resReq,
 					},
 				}
@@ -418,9 +421,9 @@ func Test_GetResources(t *testing.T) {
 		{
 			name:      "Test Agent with customized values",
 			component: constants.Agent,
-			advanced: func(resReq *globalhubv1alpha4.ResourceRequirements) *globalhubv1alpha4.AdvancedConfig {
-				return &globalhubv1alpha4.AdvancedConfig{
-					Agent: &globalhubv1alpha4.CommonSpec{
+			advanced: func(resReq *globalhubv1beta1.ResourceRequirements) *globalhubv1beta1.AdvancedConfig {
+				return &globalhubv1beta1.AdvancedConfig{
+					Agent: &globalhubv1beta1.CommonSpec{
 						Resources: resReq,
 					},
 				}
@@ -438,9 +441,9 @@ func Test_GetResources(t *testing.T) {
 		{
 			name:      "Test Manager with customized values",
 			component: constants.Manager,
-			advanced: func(resReq *globalhubv1alpha4.ResourceRequirements) *globalhubv1alpha4.AdvancedConfig {
-				return &globalhubv1alpha4.AdvancedConfig{
-					Manager: &globalhubv1alpha4.CommonSpec{
+			advanced: func(resReq *globalhubv1beta1.ResourceRequirements) *globalhubv1beta1.AdvancedConfig {
+				return &globalhubv1beta1.AdvancedConfig{
+					Manager: &globalhubv1beta1.CommonSpec{
 						Resources: resReq,
 					},
 				}
@@ -458,9 +461,9 @@ func Test_GetResources(t *testing.T) {
 		{
 			name:      "Test Kafka with customized values",
 			component: constants.Kafka,
-			advanced: func(resReq *globalhubv1alpha4.ResourceRequirements) *globalhubv1alpha4.AdvancedConfig {
-				return &globalhubv1alpha4.AdvancedConfig{
-					Kafka: &globalhubv1alpha4.CommonSpec{
+			advanced: func(resReq *globalhubv1beta1.ResourceRequirements) *globalhubv1beta1.AdvancedConfig {
+				return &globalhubv1beta1.AdvancedConfig{
+					Kafka: &globalhubv1beta1.CommonSpec{
 						Resources: resReq,
 					},
 				}
@@ -478,9 +481,9 @@ func Test_GetResources(t *testing.T) {
 		{
 			name:      "Test Zookeeper with customized values",
 			component: constants.Zookeeper,
-			advanced: func(resReq *globalhubv1alpha4.ResourceRequirements) *globalhubv1alpha4.AdvancedConfig {
-				return &globalhubv1alpha4.AdvancedConfig{
-					Zookeeper: &globalhubv1alpha4.CommonSpec{
+			advanced: func(resReq *globalhubv1beta1.ResourceRequirements) *globalhubv1beta1.AdvancedConfig {
+				return &globalhubv1beta1.AdvancedConfig{
+					Zookeeper: &globalhubv1beta1.CommonSpec{
 						Resources: resReq,
 					},
 				}
@@ -489,7 +492,7 @@ func Test_GetResources(t *testing.T) {
 		},
 	}
 
-	resReq := &globalhubv1alpha4.ResourceRequirements{
+	resReq := &globalhubv1beta1.ResourceRequirements{
 		Limits: corev1.ResourceList{
 			corev1.ResourceName(corev1.ResourceCPU):    resource.MustParse(customCPULimit),
 			corev1.ResourceName(corev1.ResourceMemory): resource.MustParse(customMemoryLimit),
@@ -502,7 +505,7 @@ func Test_GetResources(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var advanced *globalhubv1alpha4.AdvancedConfig
+			var advanced *globalhubv1beta1.AdvancedConfig
 			if tt.custom {
 				advanced = tt.advanced(resReq)
 				tt.cpuRequest = customCPURequest
@@ -526,3 +529,107 @@ func Test_GetResources(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetPodSpecValues(t *testing.T) {
+	replicas := int32(3)
+	mgh := &globalhubv1beta1.MulticlusterGlobalHub{
+		Spec: globalhubv1beta1.MulticlusterGlobalHubSpec{
+			NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+			Tolerations:  []corev1.Toleration{{Key: "node-role.kubernetes.io/infra", Operator: "Exists"}},
+			AdvancedConfig: &globalhubv1beta1.AdvancedConfig{
+				Manager: &globalhubv1beta1.CommonSpec{
+					Replicas:     &replicas,
+					NodeSelector: map[string]string{"pool": "manager"},
+					Affinity: &corev1.Affinity{
+						PodAntiAffinity: &corev1.PodAntiAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+								TopologyKey: "kubernetes.io/hostname",
+								LabelSelector: &metav1.LabelSelector{
+									MatchLabels: map[string]string{"name": "multicluster-global-hub-manager"},
+								},
+							}},
+						},
+					},
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.ScheduleAnyway,
+					}},
+					PriorityClassName: "system-cluster-critical",
+					PodLabels:         map[string]string{"name": "overridden", "team": "global-hub"},
+					PodAnnotations:    map[string]string{"owner": "global-hub"},
+					Env:               []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "4"}},
+				},
+			},
+		},
+	}
+
+	if got := GetReplicas(constants.Manager, mgh.Spec.AdvancedConfig, 2); got != 3 {
+		t.Errorf("unexpected manager replicas: %d", got)
+	}
+	if got := GetReplicas(constants.Grafana, mgh.Spec.AdvancedConfig, 2); got != 2 {
+		t.Errorf("unexpected grafana replicas: %d", got)
+	}
+	if got := GetNodeSelector(constants.Manager, mgh); got["pool"] != "manager" || len(got) != 1 {
+		t.Errorf("unexpected manager nodeSelector: %v", got)
+	}
+	if got := GetNodeSelector(constants.Grafana, mgh); len(got) != 1 {
+		t.Errorf("the grafana should use the global nodeSelector: %v", got)
+	}
+	if got := GetTolerations(constants.Manager, mgh); len(got) != 1 {
+		t.Errorf("the manager should use the global tolerations: %v", got)
+	}
+
+	values, err := GetPodSpecValues(constants.Manager, mgh.Spec.AdvancedConfig, "name")
+	if err != nil {
+		t.Fatalf("failed to get the pod spec values: %v", err)
+	}
+	if values.PriorityClassName != "system-cluster-critical" ||
+		!reflect.DeepEqual(values.PodAnnotations, []string{`"owner": "global-hub"`}) {
+		t.Errorf("unexpected pod spec values: %+v", values)
+	}
+	if !reflect.DeepEqual(values.PodLabels, []string{`"team": "global-hub"`}) {
+		t.Errorf("the selector label should be dropped: %v", values.PodLabels)
+	}
+	// the serialized values are valid yaml, they can be inlined in the templates
+	podSpec := &corev1.PodSpec{}
+	if err := yaml.Unmarshal([]byte("affinity: "+values.Affinity), podSpec); err != nil ||
+		!reflect.DeepEqual(podSpec.Affinity, mgh.Spec.AdvancedConfig.Manager.Affinity) {
+		t.Errorf("unexpected affinity: %s, %v", values.Affinity, err)
+	}
+	constraints := []corev1.TopologySpreadConstraint{}
+	if err := json.Unmarshal([]byte(values.TopologySpreadConstraints), &constraints); err != nil ||
+		len(constraints) != 1 || constraints[0].TopologyKey != "topology.kubernetes.io/zone" {
+		t.Errorf("unexpected topologySpreadConstraints: %s", values.TopologySpreadConstraints)
+	}
+	if len(values.Env) != 1 || values.Env[0] != `{"name":"GOMAXPROCS","value":"4"}` {
+		t.Errorf("unexpected env: %v", values.Env)
+	}
+
+	values, err = GetPodSpecValues(constants.Grafana, mgh.Spec.AdvancedConfig, "name")
+	if err != nil || values.Affinity != "" || len(values.Env) != 0 {
+		t.Errorf("unexpected grafana pod spec values: %+v, %v", values, err)
+	}
+
+	// the special characters are quoted, so that the entries are still valid yaml
+	mgh.Spec.AdvancedConfig.Manager.PodAnnotations = map[string]string{"note": `say "hi": #1`}
+	values, err = GetPodSpecValues(constants.Manager, mgh.Spec.AdvancedConfig, "name")
+	if err != nil {
+		t.Fatalf("failed to get the pod spec values: %v", err)
+	}
+	annotations := map[string]string{}
+	if err := yaml.Unmarshal([]byte(values.PodAnnotations[0]), &annotations); err != nil ||
+		annotations["note"] != `say "hi": #1` {
+		t.Errorf("unexpected annotations: %v, %v", values.PodAnnotations, err)
+	}
+
+	// the env duplicating the one set by the operator or another extra env is rejected
+	mgh.Spec.AdvancedConfig.Manager.Env = []corev1.EnvVar{{Name: "DATABASE_URL", Value: "postgres://"}}
+	if _, err := GetPodSpecValues(constants.Manager, mgh.Spec.AdvancedConfig, "name"); err == nil {
+		t.Errorf("the env overriding the operator one should be rejected")
+	}
+	mgh.Spec.AdvancedConfig.Manager.Env = []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "4"}, {Name: "GOMAXPROCS", Value: "2"}}
+	if _, err := GetPodSpecValues(constants.Manager, mgh.Spec.AdvancedConfig, "name"); err == nil {
+		t.Errorf("the duplicated env should be rejected")
+	}
+}
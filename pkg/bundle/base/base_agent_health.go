@@ -0,0 +1,90 @@
+package base
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// staleSyncIntervals is the number of the sync intervals without running the syncer to consider it stuck
+	staleSyncIntervals = 3
+	// minStaleDuration avoids flagging the syncers with the short intervals on a slow round
+	minStaleDuration = time.Minute
+)
+
+// AgentHealth is the self-reported health of the agent, it's carried by the heartbeat bundle.
+type AgentHealth struct {
+	AgentVersion string `json:"agentVersion,omitempty"`
+	// ReportTime is the time of the agent when the health is reported, the syncers are evaluated against it, so
+	// that the clock skew between the agent and the manager doesn't matter
+	ReportTime *time.Time `json:"reportTime,omitempty"`
+	// StatusSyncers are the transport bundle keys of the running status syncers, the same keys as the Bundles
+	StatusSyncers  []string                     `json:"statusSyncers,omitempty"`
+	SpecSyncers    []string                     `json:"specSyncers,omitempty"`
+	Bundles        map[string]*BundleSendStatus `json:"bundles,omitempty"`
+	SpecWorkerPool *WorkerPoolStatus            `json:"specWorkerPool,omitempty"`
+}
+
+// BundleSendStatus records the transport sending result of a status bundle.
+type BundleSendStatus struct {
+	LastSuccessTime   *time.Time `json:"lastSuccessTime,omitempty"`
+	ErrorCount        int64      `json:"errorCount"`
+	ConsecutiveErrors int64      `json:"consecutiveErrors"`
+	LastError         string     `json:"lastError,omitempty"`
+	// LastSyncTime is the last round of the syncer, whether the bundle is sent or not. it starts from the time the
+	// syncer is registered, so that the syncer which never runs is also flagged.
+	LastSyncTime *time.Time    `json:"lastSyncTime,omitempty"`
+	SyncInterval time.Duration `json:"syncInterval,omitempty"`
+}
+
+// staleAfter returns the duration after which the syncer without any round is considered stuck
+func (s *BundleSendStatus) staleAfter() time.Duration {
+	if stale := staleSyncIntervals * s.SyncInterval; stale > minStaleDuration {
+		return stale
+	}
+	return minStaleDuration
+}
+
+// WorkerPoolStatus is the saturation of the spec worker pool.
+type WorkerPoolStatus struct {
+	Size    int `json:"size"`
+	Pending int `json:"pending"`
+}
+
+// Saturated returns true if the pending jobs fill up the queue of the pool.
+func (s *WorkerPoolStatus) Saturated() bool {
+	return s.Size > 0 && s.Pending >= s.Size
+}
+
+// DegradedReasons returns the reasons why the agent isn't healthy, it's empty if the agent works well.
+func (h *AgentHealth) DegradedReasons() []string {
+	reasons := []string{}
+	if h == nil {
+		return reasons
+	}
+	now := time.Now()
+	if h.ReportTime != nil {
+		now = *h.ReportTime
+	}
+	for key, status := range h.Bundles {
+		if status.ConsecutiveErrors > 0 {
+			since := "the start"
+			if status.LastSuccessTime != nil {
+				since = status.LastSuccessTime.Format(time.RFC3339)
+			}
+			reasons = append(reasons, fmt.Sprintf("failed to send bundle %s %d times since %s: %s", key,
+				status.ConsecutiveErrors, since, status.LastError))
+		}
+		if status.LastSyncTime != nil && now.Sub(*status.LastSyncTime) > status.staleAfter() {
+			reasons = append(reasons, fmt.Sprintf("syncer of bundle %s hasn't run since %s", key,
+				status.LastSyncTime.Format(time.RFC3339)))
+		}
+	}
+	sort.Strings(reasons)
+	if h.SpecWorkerPool != nil && h.SpecWorkerPool.Saturated() {
+		reasons = append(reasons, fmt.Sprintf("spec worker pool is saturated: %d pending jobs with size %d",
+			h.SpecWorkerPool.Pending, h.SpecWorkerPool.Size))
+	}
+	return reasons
+}
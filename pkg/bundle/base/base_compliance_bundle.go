@@ -12,6 +12,8 @@ type GenericCompliance struct {
 	CompliantClusters         []string `json:"compliantClusters"`
 	NonCompliantClusters      []string `json:"nonCompliantClusters"`
 	UnknownComplianceClusters []string `json:"unknownComplianceClusters"`
+	// Generation is the generation of the global policy delivered by the rollout, zero if it isn't rolled out
+	Generation int64 `json:"generation,omitempty"`
 }
 
 // BaseComplianceBundle the base struct for clusters per policy bundle and contains the full state.
@@ -7,6 +7,8 @@ type HubClusterInfo struct {
 	ConsoleURL string `json:"consoleURL"`
 	GrafanaURL string `json:"grafanaURL"`
 	ClusterId  string `json:"clusterId"`
+	// StatusFilters are the filters of the reported resources, the hub is partially reported if it isn't empty
+	StatusFilters map[string]string `json:"statusFilters,omitempty"`
 }
 
 // BaseHubClusterInfoBundle the bundle for the hub cluster info.
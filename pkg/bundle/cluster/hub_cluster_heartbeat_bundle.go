@@ -9,9 +9,10 @@ import (
 var _ bundle.ManagerBundle = (*HubClusterHeartbeatBundle)(nil)
 var _ bundle.BaseAgentBundle = (*HubClusterHeartbeatBundle)(nil)
 
-// LocalPolicyBundle abstracts management of local policies spec bundle.
+// HubClusterHeartbeatBundle is the heartbeat of the leaf hub, it also carries the self-reported agent health.
 type HubClusterHeartbeatBundle struct {
 	base.BaseManagerBundle
+	Health *base.AgentHealth `json:"health,omitempty"`
 }
 
 // NewManagerHubClusterHeartbeatBundle creates a new instance of HubClusterHeartbeatBundle.
@@ -22,7 +23,7 @@ func NewManagerHubClusterHeartbeatBundle() bundle.ManagerBundle {
 // NewAgentHubClusterHeartbeatBundle creates a new instance of HubClusterHeartbeatBundle.
 func NewAgentHubClusterHeartbeatBundle(leafHubName string) *HubClusterHeartbeatBundle {
 	return &HubClusterHeartbeatBundle{
-		base.BaseManagerBundle{
+		BaseManagerBundle: base.BaseManagerBundle{
 			LeafHubName:   leafHubName,
 			BundleVersion: metadata.NewBundleVersion(),
 		},
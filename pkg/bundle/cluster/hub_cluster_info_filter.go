@@ -0,0 +1,69 @@
+package cluster
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
+)
+
+var _ bundle.SharedBundleObject = (*hubClusterFilterObject)(nil)
+
+// hubClusterFilterObject reports the status filters configured in the agent config, so that the global hub knows
+// the hub is partially reported.
+type hubClusterFilterObject struct {
+	filterKeys []string
+}
+
+func NewHubClusterInfoFilterObject(filterKeys []string) *hubClusterFilterObject {
+	return &hubClusterFilterObject{filterKeys: filterKeys}
+}
+
+func (h *hubClusterFilterObject) Predicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == constants.GHAgentNamespace &&
+			object.GetName() == constants.GHAgentConfigCMName
+	})
+}
+
+func (h *hubClusterFilterObject) CreateObject() bundle.Object {
+	return &corev1.ConfigMap{}
+}
+
+func (h *hubClusterFilterObject) BundleUpdate(obj bundle.Object, b bundle.BaseAgentBundle) {
+	hubClusterBundle, ok1 := ensureBundle(b)
+	configMap, ok2 := obj.(*corev1.ConfigMap)
+	if !ok1 || !ok2 {
+		return
+	}
+
+	var filters map[string]string
+	for _, key := range h.filterKeys {
+		if value := configMap.Data[key]; value != "" {
+			if filters == nil {
+				filters = map[string]string{}
+			}
+			filters[key] = value
+		}
+	}
+	if !reflect.DeepEqual(hubClusterBundle.Objects[0].StatusFilters, filters) {
+		hubClusterBundle.Objects[0].StatusFilters = filters
+		hubClusterBundle.GetVersion().Incr()
+	}
+}
+
+func (h *hubClusterFilterObject) BundleDelete(obj bundle.Object, b bundle.BaseAgentBundle) {
+	hubClusterBundle, ok := ensureBundle(b)
+	if !ok {
+		return
+	}
+
+	if hubClusterBundle.Objects[0].StatusFilters != nil {
+		hubClusterBundle.Objects[0].StatusFilters = nil
+		hubClusterBundle.GetVersion().Incr()
+	}
+}
@@ -0,0 +1,31 @@
+package cluster
+
+import (
+	addonv1alpha1 "open-cluster-management.io/api/addon/v1alpha1"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
+)
+
+var _ bundle.ManagerBundle = (*ManagedClusterAddOnBundle)(nil)
+
+// ManagedClusterAddOnBundle abstracts management of managed cluster addons bundle.
+type ManagedClusterAddOnBundle struct {
+	Objects []*addonv1alpha1.ManagedClusterAddOn `json:"objects"`
+	base.BaseManagerBundle
+}
+
+// NewManagerManagedClusterAddOnBundle creates a new instance of ManagedClusterAddOnBundle.
+func NewManagerManagedClusterAddOnBundle() bundle.ManagerBundle {
+	return &ManagedClusterAddOnBundle{}
+}
+
+// GetObjects returns the objects in the bundle.
+func (bundle *ManagedClusterAddOnBundle) GetObjects() []interface{} {
+	result := make([]interface{}, len(bundle.Objects))
+	for i, obj := range bundle.Objects {
+		result[i] = obj
+	}
+
+	return result
+}
@@ -0,0 +1,66 @@
+package drift
+
+import (
+	"time"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+)
+
+var (
+	_ bundle.ManagerBundle   = (*DriftBundle)(nil)
+	_ bundle.BaseAgentBundle = (*DriftBundle)(nil)
+)
+
+const (
+	// DriftReported means the drift is only reported, the object on the managed hub isn't changed
+	DriftReported = "reported"
+	// DriftReverted means the object on the managed hub is reverted to the spec from the global hub
+	DriftReverted = "reverted"
+)
+
+// ObjectDrift is the drift of a global resource on the managed hub.
+type ObjectDrift struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// FieldPaths are the fields of the object which are different from the last received bundle
+	FieldPaths []string `json:"fieldPaths,omitempty"`
+	// Deleted means the object is removed from the managed hub
+	Deleted    bool      `json:"deleted,omitempty"`
+	Action     string    `json:"action"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// DriftBundle holds the drifts of the global resources detected in the last round.
+type DriftBundle struct {
+	base.BaseManagerBundle
+	Objects []*ObjectDrift `json:"objects"`
+}
+
+// NewManagerDriftBundle creates a new instance of DriftBundle.
+func NewManagerDriftBundle() bundle.ManagerBundle {
+	return &DriftBundle{}
+}
+
+// NewAgentDriftBundle creates a new instance of DriftBundle.
+func NewAgentDriftBundle(leafHubName string) *DriftBundle {
+	return &DriftBundle{
+		BaseManagerBundle: base.BaseManagerBundle{
+			LeafHubName:   leafHubName,
+			BundleVersion: metadata.NewBundleVersion(),
+		},
+		Objects: make([]*ObjectDrift, 0),
+	}
+}
+
+// GetObjects returns the objects in the bundle.
+func (bundle *DriftBundle) GetObjects() []interface{} {
+	result := make([]interface{}, len(bundle.Objects))
+	for i, obj := range bundle.Objects {
+		result[i] = obj
+	}
+	return result
+}
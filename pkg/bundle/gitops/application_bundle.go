@@ -0,0 +1,47 @@
+package gitops
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
+)
+
+// ApplicationGVK is the group version kind of the argo cd application, the api isn't imported to avoid depending
+// on argo cd, so the applications are handled as unstructured objects
+var ApplicationGVK = schema.GroupVersionKind{
+	Group:   "argoproj.io",
+	Version: "v1alpha1",
+	Kind:    "Application",
+}
+
+var _ bundle.ManagerBundle = (*ApplicationBundle)(nil)
+
+// ApplicationBundle abstracts management of argo cd applications bundle.
+type ApplicationBundle struct {
+	Objects []*unstructured.Unstructured `json:"objects"`
+	base.BaseManagerBundle
+}
+
+// NewManagerApplicationBundle creates a new instance of ApplicationBundle.
+func NewManagerApplicationBundle() bundle.ManagerBundle {
+	return &ApplicationBundle{}
+}
+
+// GetObjects returns the objects in the bundle.
+func (bundle *ApplicationBundle) GetObjects() []interface{} {
+	result := make([]interface{}, len(bundle.Objects))
+	for i, obj := range bundle.Objects {
+		result[i] = obj
+	}
+
+	return result
+}
+
+// NewApplication creates an empty unstructured argo cd application.
+func NewApplication() *unstructured.Unstructured {
+	application := &unstructured.Unstructured{}
+	application.SetGroupVersionKind(ApplicationGVK)
+	return application
+}
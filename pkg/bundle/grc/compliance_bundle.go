@@ -3,6 +3,7 @@ package grc
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 
 	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
@@ -10,6 +11,7 @@ import (
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/constants"
 	utils "github.com/stolostron/multicluster-global-hub/pkg/utils"
 )
 
@@ -174,6 +176,7 @@ func (b *ComplianceBundle) getNewCompliance(originPolicyID string, policy *polic
 		CompliantClusters:         compliantClusters,
 		NonCompliantClusters:      nonCompliantClusters,
 		UnknownComplianceClusters: unknownComplianceClusters,
+		Generation:                rolloutGeneration(policy),
 	}
 }
 
@@ -191,6 +194,11 @@ func (b *ComplianceBundle) updateObjectIfChanged(objectIndex int, policy *polici
 		!b.containClusters(allClusters, oldPolicyStatus.UnknownComplianceClusters) {
 		clusterListChanged = true // at least one cluster was added/removed
 	}
+	// the compliance of the new rolled out version is reported with the generation
+	if generation := rolloutGeneration(policy); generation != oldPolicyStatus.Generation {
+		oldPolicyStatus.Generation = generation
+		clusterListChanged = true
+	}
 
 	// in any case we want to update the internal bundle in case statuses changed
 	oldPolicyStatus.CompliantClusters = newCompliantClusters
@@ -200,6 +208,15 @@ func (b *ComplianceBundle) updateObjectIfChanged(objectIndex int, policy *polici
 	return clusterListChanged
 }
 
+// rolloutGeneration returns the generation of the global policy delivered by the rollout.
+func rolloutGeneration(policy *policiesv1.Policy) int64 {
+	generation, err := strconv.ParseInt(policy.GetAnnotations()[constants.PolicyRolloutGenerationAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return generation
+}
+
 func (b *ComplianceBundle) containClusters(allClusters []string, subsetClusters []string) bool {
 	for _, clusterName := range subsetClusters {
 		if !utils.ContainsString(allClusters, clusterName) {
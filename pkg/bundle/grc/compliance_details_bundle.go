@@ -0,0 +1,74 @@
+package grc
+
+import (
+	"time"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+)
+
+var (
+	_ bundle.ManagerBundle   = (*ComplianceDetailsBundle)(nil)
+	_ bundle.BaseAgentBundle = (*ComplianceDetailsBundle)(nil)
+)
+
+const (
+	// MaxComplianceDetailsMessageLength is the max length of the message reported for a policy template
+	MaxComplianceDetailsMessageLength = 512
+	// MaxComplianceDetailsTemplates is the max number of the templates reported for a replicated policy
+	MaxComplianceDetailsTemplates = 20
+	// MaxComplianceDetailsBundleSize is the max size of the details in a bundle, the details of the compliant
+	// policies are dropped first once it's exceeded
+	MaxComplianceDetailsBundleSize = 2 * 1024 * 1024
+)
+
+// TemplateDetails is the latest status of a policy template on the managed cluster.
+type TemplateDetails struct {
+	Name          string    `json:"name"`
+	Kind          string    `json:"kind"`
+	Compliance    string    `json:"compliance"`
+	Message       string    `json:"message,omitempty"`
+	LastTimestamp time.Time `json:"lastTimestamp,omitempty"`
+}
+
+// ComplianceDetails is the per-template status of a global policy on the managed cluster.
+type ComplianceDetails struct {
+	PolicyID    string             `json:"policyId"`
+	ClusterName string             `json:"clusterName"`
+	Templates   []*TemplateDetails `json:"templates"`
+}
+
+// ComplianceDetailsBundle holds the per-template status details of the global policies on the managed hub.
+type ComplianceDetailsBundle struct {
+	base.BaseManagerBundle
+	Objects []*ComplianceDetails `json:"objects"`
+	// Truncated means some of the details are dropped to bound the bundle size, so the details which aren't in the
+	// bundle are kept by the manager rather than removed
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// NewManagerComplianceDetailsBundle creates a new instance of ComplianceDetailsBundle.
+func NewManagerComplianceDetailsBundle() bundle.ManagerBundle {
+	return &ComplianceDetailsBundle{}
+}
+
+// NewAgentComplianceDetailsBundle creates a new instance of ComplianceDetailsBundle.
+func NewAgentComplianceDetailsBundle(leafHubName string) *ComplianceDetailsBundle {
+	return &ComplianceDetailsBundle{
+		BaseManagerBundle: base.BaseManagerBundle{
+			LeafHubName:   leafHubName,
+			BundleVersion: metadata.NewBundleVersion(),
+		},
+		Objects: make([]*ComplianceDetails, 0),
+	}
+}
+
+// GetObjects returns the objects in the bundle.
+func (bundle *ComplianceDetailsBundle) GetObjects() []interface{} {
+	result := make([]interface{}, len(bundle.Objects))
+	for i, obj := range bundle.Objects {
+		result[i] = obj
+	}
+	return result
+}
@@ -38,9 +38,16 @@ func TestComplianceBundle(t *testing.T) {
 	version = b.GetVersion()
 	assert.Equal(t, "0.3", version.String())
 
-	b.DeleteObject(policy) // remove obj by uid from bundle
+	// the new generation delivered by the rollout is reported even if the clusters aren't changed
+	policy.Annotations = map[string]string{constants.PolicyRolloutGenerationAnnotation: "2"}
+	b.UpdateObject(policy)
 	version = b.GetVersion()
 	assert.Equal(t, "0.4", version.String())
+	assert.Equal(t, int64(2), b.(*ComplianceBundle).Objects[0].Generation)
+
+	b.DeleteObject(policy) // remove obj by uid from bundle
+	version = b.GetVersion()
+	assert.Equal(t, "0.5", version.String())
 }
 
 func TestCompleteComplianceBundle(t *testing.T) {
@@ -2,8 +2,20 @@ package spec
 
 import "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
+// BundleGeneration locates the spec bundle in the stream of the bundles sent for a message key. The delta bundle
+// only holds the objects changed since the base generation, the snapshot bundle holds all the objects. The bundle
+// without generation is sent by the previous versions of the manager, it holds all the objects.
+type BundleGeneration struct {
+	// Stream is changed once the manager restarts the stream with a snapshot, e.g. the manager is restarted
+	Stream         string `json:"stream,omitempty"`
+	Generation     int64  `json:"generation,omitempty"`
+	BaseGeneration int64  `json:"baseGeneration,omitempty"`
+	Snapshot       bool   `json:"snapshot,omitempty"`
+}
+
 // Manger to Agent: GenericSpecBundle bundle received from transport containing Objects/DeletedObjects.
 type GenericSpecBundle struct {
+	BundleGeneration
 	Objects        []*unstructured.Unstructured `json:"objects"`
 	DeletedObjects []*unstructured.Unstructured `json:"deletedObjects"`
 }
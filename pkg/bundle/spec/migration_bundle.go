@@ -0,0 +1,99 @@
+package spec
+
+import (
+	"time"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+)
+
+// the stages of the managed cluster migration, each of them is run by the agent of the source or the target hub.
+const (
+	// MigrationStageExport exports the namespaces which bind the cluster sets of the clusters from the source hub.
+	MigrationStageExport = "Export"
+	// MigrationStagePrepare creates the clusters and the cluster set bindings on the target hub, and exports the
+	// bootstrap kubeconfigs of the clusters.
+	MigrationStagePrepare = "Prepare"
+	// MigrationStageDeploy deploys the bootstrap kubeconfigs of the target hub to the clusters from the source hub.
+	MigrationStageDeploy = "Deploy"
+	// MigrationStageRegister waits for the clusters to join the target hub with the deployed bootstrap kubeconfigs.
+	MigrationStageRegister = "Register"
+	// MigrationStageDetach removes the clusters from the source hub once they join the target hub.
+	MigrationStageDetach = "Detach"
+	// MigrationStageRollback restores the clusters on the source hub and exports its bootstrap kubeconfigs, the target
+	// hub deploys them to the clusters which have joined it, and removes the clusters.
+	MigrationStageRollback = "Rollback"
+)
+
+var (
+	_ bundle.ManagerBundle   = (*MigrationStatusBundle)(nil)
+	_ bundle.BaseAgentBundle = (*MigrationStatusBundle)(nil)
+)
+
+// Manager to Agent: MigrationSpec requests the agent to run the stage of the migration for the clusters.
+type MigrationSpec struct {
+	MigrationID string                  `json:"migrationID"`
+	Stage       string                  `json:"stage"`
+	SourceHub   string                  `json:"sourceHub"`
+	TargetHub   string                  `json:"targetHub"`
+	Clusters    []*MigrationClusterSpec `json:"clusters"`
+}
+
+// MigrationClusterSpec is the cluster to migrate, the labels and the cluster set bindings are set in the prepare
+// stage, and the bootstrap kubeconfig is set in the deploy and the rollback stages.
+type MigrationClusterSpec struct {
+	Name                string            `json:"name"`
+	Labels              map[string]string `json:"labels,omitempty"`
+	ClusterSetBindings  []string          `json:"clusterSetBindings,omitempty"`
+	BootstrapKubeconfig []byte            `json:"bootstrapKubeconfig,omitempty"`
+}
+
+// Agent to Manager: MigrationStatusBundle holds the results of the migration stages run by the agent, the results
+// are kept until they expire, so that the bundle always holds all the recent results.
+type MigrationStatusBundle struct {
+	base.BaseManagerBundle
+	Objects []*MigrationStatus `json:"objects"`
+}
+
+// MigrationStatus is the result of the stage of the migration.
+type MigrationStatus struct {
+	MigrationID string                    `json:"migrationID"`
+	Stage       string                    `json:"stage"`
+	Clusters    []*MigrationClusterStatus `json:"clusters"`
+	CompletedAt time.Time                 `json:"completedAt"`
+}
+
+// MigrationClusterStatus is the result of the cluster, the cluster set bindings are exported in the export stage,
+// and the bootstrap kubeconfig is exported in the prepare and the rollback stages.
+type MigrationClusterStatus struct {
+	Name                string   `json:"name"`
+	Error               string   `json:"error,omitempty"`
+	ClusterSetBindings  []string `json:"clusterSetBindings,omitempty"`
+	BootstrapKubeconfig []byte   `json:"bootstrapKubeconfig,omitempty"`
+}
+
+// NewManagerMigrationStatusBundle creates a new instance of MigrationStatusBundle.
+func NewManagerMigrationStatusBundle() bundle.ManagerBundle {
+	return &MigrationStatusBundle{}
+}
+
+// NewAgentMigrationStatusBundle creates a new instance of MigrationStatusBundle.
+func NewAgentMigrationStatusBundle(leafHubName string) *MigrationStatusBundle {
+	return &MigrationStatusBundle{
+		BaseManagerBundle: base.BaseManagerBundle{
+			LeafHubName:   leafHubName,
+			BundleVersion: metadata.NewBundleVersion(),
+		},
+		Objects: make([]*MigrationStatus, 0),
+	}
+}
+
+// GetObjects returns the objects in the bundle.
+func (bundle *MigrationStatusBundle) GetObjects() []interface{} {
+	result := make([]interface{}, len(bundle.Objects))
+	for i, obj := range bundle.Objects {
+		result[i] = obj
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package spec
+
+import (
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/base"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+)
+
+var (
+	_ bundle.ManagerBundle   = (*SnapshotRequestBundle)(nil)
+	_ bundle.BaseAgentBundle = (*SnapshotRequestBundle)(nil)
+)
+
+// Agent to Manager: SnapshotRequestBundle holds the message keys of the spec bundles which the managed hub
+// requests the snapshots of, since a gap is detected in the received delta bundles.
+type SnapshotRequestBundle struct {
+	base.BaseManagerBundle
+	Objects []string `json:"objects"`
+}
+
+// NewManagerSnapshotRequestBundle creates a new instance of SnapshotRequestBundle.
+func NewManagerSnapshotRequestBundle() bundle.ManagerBundle {
+	return &SnapshotRequestBundle{}
+}
+
+// NewAgentSnapshotRequestBundle creates a new instance of SnapshotRequestBundle.
+func NewAgentSnapshotRequestBundle(leafHubName string) *SnapshotRequestBundle {
+	return &SnapshotRequestBundle{
+		BaseManagerBundle: base.BaseManagerBundle{
+			LeafHubName:   leafHubName,
+			BundleVersion: metadata.NewBundleVersion(),
+		},
+		Objects: make([]string, 0),
+	}
+}
+
+// GetObjects returns the objects in the bundle.
+func (bundle *SnapshotRequestBundle) GetObjects() []interface{} {
+	result := make([]interface{}, len(bundle.Objects))
+	for i, obj := range bundle.Objects {
+		result[i] = obj
+	}
+	return result
+}
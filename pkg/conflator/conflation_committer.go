@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -25,6 +26,8 @@ type ConflationCommitter struct {
 	log                    logr.Logger
 	transportMetadatasFunc metadata.GetBundleStatusesFunc
 	committedPositions     map[string]int64
+	// lock serializes the periodic commit and the commit before the partitions are revoked
+	lock sync.Mutex
 }
 
 func NewKafkaConflationCommitter(getTransportMetadatasFunc metadata.GetBundleStatusesFunc) *ConflationCommitter {
@@ -43,7 +46,7 @@ func (k *ConflationCommitter) Start(ctx context.Context) error {
 		for {
 			select {
 			case <-ticker.C: // wait for next time interval
-				err := k.commit()
+				err := k.Commit()
 				if err != nil {
 					k.log.Info("failed to commit offset", "error", err)
 				}
@@ -57,7 +60,12 @@ func (k *ConflationCommitter) Start(ctx context.Context) error {
 	return nil
 }
 
-func (k *ConflationCommitter) commit() error {
+// Commit persists the positions of the bundles in the conflation units into the database, each partition has its own
+// position named with topic@partition, so that the manager replicas only commit the partitions owned by them.
+func (k *ConflationCommitter) Commit() error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
 	// get metadata (both pending and processed)
 	transportMetadatas := k.transportMetadatasFunc()
 
@@ -81,7 +89,7 @@ func (k *ConflationCommitter) commit() error {
 			return err
 		}
 		databaseTransports = append(databaseTransports, models.Transport{
-			Name:    transPosition.Topic,
+			Name:    key,
 			Payload: payload,
 		})
 		k.committedPositions[key] = int64(transPosition.Offset)
@@ -2,6 +2,7 @@ package conflator
 
 import (
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -22,9 +23,13 @@ func NewConflationManager(conflationUnitsReadyQueue *ConflationReadyQueue, stati
 		readyQueue:    conflationUnitsReadyQueue,
 		lock:          sync.Mutex{}, // lock to be used to find/create conflation units
 		statistics:    statistics,
+		hubPartitions: make(map[string]string),
 	}
 }
 
+// the bundles in process are waited for at most the timeout before the partitions are revoked
+const suspendTimeout = 30 * time.Second
+
 // ConflationManager implements conflation units management.
 type ConflationManager struct {
 	log             logr.Logger
@@ -34,6 +39,10 @@ type ConflationManager struct {
 	readyQueue    *ConflationReadyQueue
 	lock          sync.Mutex
 	statistics    *statistics.Statistics
+	// partitions(topic@partition) assigned to the manager replica, nil means the manager owns all the partitions
+	partitions map[string]bool
+	// hubPartitions maps the leaf hub to the partition which the bundles of the hub are received from
+	hubPartitions map[string]string
 }
 
 // Register registers bundle type with priority and handler function within the conflation manager.
@@ -43,25 +52,151 @@ func (cm *ConflationManager) Register(registration *ConflationRegistration) {
 
 // Insert function inserts the bundle to the appropriate conflation unit.
 func (cm *ConflationManager) Insert(managerBundle bundle.ManagerBundle, bundleStatus metadata.BundleStatus) {
-	cm.getConflationUnit(managerBundle.GetLeafHubName()).insert(managerBundle, bundleStatus)
+	conflationUnit := cm.getConflationUnit(managerBundle.GetLeafHubName(), bundleStatus)
+	if conflationUnit == nil {
+		cm.log.V(2).Info("skip the bundle from the partition which isn't owned", "managedHub",
+			managerBundle.GetLeafHubName(), "bundleType", bundle.GetBundleType(managerBundle))
+		return
+	}
+	conflationUnit.insert(managerBundle, bundleStatus)
 }
 
-// GetTransportMetadatas provides collections of the CU's bundle transport-metadata.
+// GetTransportMetadatas provides collections of the CU's bundle transport-metadata. The bundles of the partitions
+// which aren't owned by the manager are excluded, so that they aren't committed.
 func (cm *ConflationManager) GetTransportMetadatas() []metadata.BundleStatus {
-	metadata := make([]metadata.BundleStatus, 0)
+	cm.lock.Lock()
+	conflationUnits := make([]*ConflationUnit, 0, len(cm.conflationUnits))
+	for leafHubName, cu := range cm.conflationUnits {
+		if partition, found := cm.hubPartitions[leafHubName]; found && !cm.owns(partition) {
+			continue
+		}
+		conflationUnits = append(conflationUnits, cu)
+	}
+	cm.lock.Unlock()
 
-	for _, cu := range cm.conflationUnits {
+	metadata := make([]metadata.BundleStatus, 0)
+	for _, cu := range conflationUnits {
 		metadata = append(metadata, cu.getBundleStatues()...)
 	}
 
 	return metadata
 }
 
-// if conflation unit doesn't exist for leaf hub, creates it.
-func (cm *ConflationManager) getConflationUnit(leafHubName string) *ConflationUnit {
+// AssignPartitions records the partitions assigned to the manager. The suspended conflation units of the assigned
+// partitions are resumed, and the others which belong to the partitions of the other managers are deleted.
+func (cm *ConflationManager) AssignPartitions(partitions []metadata.TransportPosition) {
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+
+	cm.partitions = make(map[string]bool)
+	for _, partition := range partitions {
+		cm.partitions[positionKey(partition.Topic, partition.Partition)] = true
+	}
+	for leafHubName, partition := range cm.hubPartitions {
+		if cm.partitions[partition] {
+			cm.conflationUnits[leafHubName].resume()
+			continue
+		}
+		cm.log.Info("delete the conflation unit of the partition owned by the other manager",
+			"managedHub", leafHubName, "topic@partition", partition)
+		delete(cm.conflationUnits, leafHubName)
+		delete(cm.hubPartitions, leafHubName)
+	}
+}
+
+// SuspendPartitions stops dispatching the bundles of the partitions, and waits for the bundles in process, so that
+// the bundles of a hub aren't handled by the current and the next owner of the partition at the same time.
+func (cm *ConflationManager) SuspendPartitions(partitions []metadata.TransportPosition) {
+	suspended := map[string]bool{}
+	for _, partition := range partitions {
+		suspended[positionKey(partition.Topic, partition.Partition)] = true
+	}
+
+	cm.lock.Lock()
+	conflationUnits := []*ConflationUnit{}
+	for leafHubName, partition := range cm.hubPartitions {
+		if suspended[partition] {
+			conflationUnits = append(conflationUnits, cm.conflationUnits[leafHubName])
+		}
+	}
+	cm.lock.Unlock()
+
+	deadline := time.Now().Add(suspendTimeout)
+	for _, cu := range conflationUnits {
+		for cu.suspend() {
+			if time.Now().After(deadline) {
+				cm.log.Info("timeout to wait for the bundles in process", "partitions", partitions)
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// RevokePartitions stops inserting the bundles of the partitions, the bundles are received by the next owner.
+func (cm *ConflationManager) RevokePartitions(partitions []metadata.TransportPosition) {
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+
+	if cm.partitions == nil {
+		cm.partitions = make(map[string]bool)
+	}
+	for _, partition := range partitions {
+		delete(cm.partitions, positionKey(partition.Topic, partition.Partition))
+	}
+}
+
+// GetProcessedVersion returns the version of the last bundle with the type processed for the leaf hub, or nil if no
+// bundle is received from the leaf hub.
+func (cm *ConflationManager) GetProcessedVersion(leafHubName, bundleType string) *metadata.BundleVersion {
+	cm.lock.Lock()
+	conflationUnit, found := cm.conflationUnits[leafHubName]
+	cm.lock.Unlock()
+	if !found {
+		return nil
+	}
+	return conflationUnit.getProcessedVersion(bundleType)
+}
+
+// GetProcessedVersions returns the versions of the bundles processed for the leaf hubs of the partitions owned by the
+// manager, it maps the leaf hub to the bundle types. the bundle types which aren't received are excluded.
+func (cm *ConflationManager) GetProcessedVersions() map[string]map[string]metadata.BundleVersion {
+	cm.lock.Lock()
+	conflationUnits := make(map[string]*ConflationUnit, len(cm.conflationUnits))
+	for leafHubName, cu := range cm.conflationUnits {
+		if partition, found := cm.hubPartitions[leafHubName]; found && !cm.owns(partition) {
+			continue
+		}
+		conflationUnits[leafHubName] = cu
+	}
+	cm.lock.Unlock()
+
+	versions := make(map[string]map[string]metadata.BundleVersion, len(conflationUnits))
+	for leafHubName, cu := range conflationUnits {
+		if processed := cu.getProcessedVersions(); len(processed) > 0 {
+			versions[leafHubName] = processed
+		}
+	}
+	return versions
+}
+
+// if conflation unit doesn't exist for leaf hub, creates it. returns nil if the bundle is from the partition which
+// isn't owned by the manager.
+func (cm *ConflationManager) getConflationUnit(leafHubName string, bundleStatus metadata.BundleStatus,
+) *ConflationUnit {
 	cm.lock.Lock() // use lock to find/create conflation units
 	defer cm.lock.Unlock()
 
+	if transportMetadata, ok := bundleStatus.(metadata.TransportMetadata); ok &&
+		transportMetadata.GetTransportMetadata() != nil {
+		position := transportMetadata.GetTransportMetadata()
+		partition := positionKey(position.Topic, position.Partition)
+		if !cm.owns(partition) {
+			return nil
+		}
+		cm.hubPartitions[leafHubName] = partition
+	}
+
 	if conflationUnit, found := cm.conflationUnits[leafHubName]; found {
 		return conflationUnit
 	}
@@ -72,3 +207,8 @@ func (cm *ConflationManager) getConflationUnit(leafHubName string) *ConflationUn
 
 	return conflationUnit
 }
+
+// owns returns whether the partition is assigned to the manager, must be called with the lock.
+func (cm *ConflationManager) owns(partition string) bool {
+	return cm.partitions == nil || cm.partitions[partition]
+}
@@ -0,0 +1,105 @@
+package conflator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/cluster"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
+	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata/status"
+	"github.com/stolostron/multicluster-global-hub/pkg/statistics"
+)
+
+func TestConflationManagerPartitions(t *testing.T) {
+	heartbeatType := bundle.GetBundleType(&cluster.HubClusterHeartbeatBundle{})
+	stats := statistics.NewStatistics(&statistics.StatisticsConfig{}, []string{heartbeatType})
+	readyQueue := NewConflationReadyQueue(stats)
+	conflationManager := NewConflationManager(readyQueue, stats)
+	conflationManager.Register(NewConflationRegistration(HubClusterHeartbeatPriority, metadata.CompleteStateMode,
+		heartbeatType, func(ctx context.Context, b bundle.ManagerBundle) error { return nil }))
+
+	insert := func(leafHubName string, partition int32, offset int64) {
+		heartbeat := cluster.NewAgentHubClusterHeartbeatBundle(leafHubName)
+		heartbeat.GetVersion().Next()
+		for i := int64(0); i < offset; i++ {
+			heartbeat.GetVersion().Incr()
+		}
+		conflationManager.Insert(heartbeat, status.NewThresholdBundleStatusFromPosition(3,
+			&metadata.TransportPosition{Topic: "status", Partition: partition, Offset: offset}))
+	}
+	committed := func() map[string]int64 {
+		offsets := map[string]int64{}
+		for key, position := range metadataToCommit(conflationManager.GetTransportMetadatas()) {
+			offsets[key] = position.Offset
+		}
+		return offsets
+	}
+
+	// the manager owns all the partitions before the partitions are assigned
+	insert("hub1", 0, 3)
+	assert.Equal(t, map[string]int64{"status@0": 3}, committed())
+
+	conflationManager.AssignPartitions([]metadata.TransportPosition{
+		{Topic: "status", Partition: 0},
+		{Topic: "status", Partition: 1},
+	})
+	insert("hub2", 1, 5)
+	insert("hub3", 2, 4)
+	assert.Equal(t, map[string]int64{"status@0": 3, "status@1": 5}, committed())
+
+	// the partition 1 is revoked, the bundles of it aren't committed or inserted any more
+	conflationManager.SuspendPartitions([]metadata.TransportPosition{{Topic: "status", Partition: 1}})
+	conflationManager.RevokePartitions([]metadata.TransportPosition{{Topic: "status", Partition: 1}})
+	insert("hub5", 1, 7)
+	assert.Equal(t, map[string]int64{"status@0": 3}, committed())
+	assert.Nil(t, conflationManager.GetProcessedVersion("hub5", heartbeatType))
+
+	// the conflation unit of the partition 1 is deleted once the other partitions are assigned
+	conflationManager.AssignPartitions([]metadata.TransportPosition{
+		{Topic: "status", Partition: 0},
+		{Topic: "status", Partition: 2},
+	})
+	assert.Nil(t, conflationManager.GetProcessedVersion("hub2", heartbeatType))
+	assert.NotNil(t, conflationManager.GetProcessedVersion("hub1", heartbeatType))
+	insert("hub4", 2, 2)
+	assert.Equal(t, map[string]int64{"status@0": 3, "status@2": 2}, committed())
+}
+
+func TestConflationUnitSuspend(t *testing.T) {
+	heartbeatType := bundle.GetBundleType(&cluster.HubClusterHeartbeatBundle{})
+	stats := statistics.NewStatistics(&statistics.StatisticsConfig{}, []string{heartbeatType})
+	readyQueue := NewConflationReadyQueue(stats)
+	conflationManager := NewConflationManager(readyQueue, stats)
+	conflationManager.Register(NewConflationRegistration(HubClusterHeartbeatPriority, metadata.CompleteStateMode,
+		heartbeatType, func(ctx context.Context, b bundle.ManagerBundle) error { return nil }))
+
+	heartbeat := cluster.NewAgentHubClusterHeartbeatBundle("hub1")
+	heartbeat.GetVersion().Next()
+	heartbeat.GetVersion().Incr()
+	position := &metadata.TransportPosition{Topic: "status", Partition: 0, Offset: 1}
+	conflationManager.Insert(heartbeat, status.NewThresholdBundleStatusFromPosition(3, position))
+
+	conflationUnit := readyQueue.BlockingDequeue()
+	_, bundleMetadata, _, err := conflationUnit.GetNext()
+	assert.Nil(t, err)
+	assert.True(t, conflationUnit.suspend(), "the bundle is in process")
+
+	// the bundle inserted into the suspended unit isn't dispatched until the unit is resumed
+	conflationUnit.ReportResult(bundleMetadata, nil)
+	assert.False(t, conflationUnit.suspend())
+	next := cluster.NewAgentHubClusterHeartbeatBundle("hub1")
+	next.GetVersion().Next()
+	next.GetVersion().Incr()
+	next.GetVersion().Incr()
+	conflationManager.Insert(next, status.NewThresholdBundleStatusFromPosition(3, position))
+	assert.True(t, readyQueue.isEmpty())
+
+	conflationManager.AssignPartitions([]metadata.TransportPosition{*position})
+	assert.False(t, readyQueue.isEmpty())
+	resumed, _, _, err := readyQueue.BlockingDequeue().GetNext()
+	assert.Nil(t, err)
+	assert.Equal(t, "1.2", resumed.GetVersion().String())
+}
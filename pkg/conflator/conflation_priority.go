@@ -16,6 +16,9 @@ const (
 	LocalCompliancePriority            ConflationPriority = iota
 	LocalCompleteCompliancePriority    ConflationPriority = iota
 	LocalReplicatedPolicyEventPriority ConflationPriority = iota
+	ManagedClusterAddOnsPriority       ConflationPriority = iota
+	GitOpsApplicationsPriority         ConflationPriority = iota
+	ManagedClusterMigrationPriority    ConflationPriority = iota
 
 	// enable global resource
 	PlacementRulePriority           ConflationPriority = iota
@@ -24,4 +27,7 @@ const (
 	SubscriptionStatusPriority      ConflationPriority = iota
 	SubscriptionReportPriority      ConflationPriority = iota
 	LocalPlacementRulesSpecPriority ConflationPriority = iota
+	GlobalResourceDriftPriority     ConflationPriority = iota
+	ComplianceDetailsPriority       ConflationPriority = iota
+	SpecSnapshotRequestPriority     ConflationPriority = iota
 )
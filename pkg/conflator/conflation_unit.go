@@ -18,6 +18,7 @@ const (
 
 var (
 	errNoReadyBundle               = errors.New("no bundle is ready to be processed")
+	errConflationUnitSuspended     = errors.New("the conflation unit is suspended")
 	errDependencyCannotBeEvaluated = errors.New("bundles declares dependency in registration but doesn't " +
 		"implement DependantBundle interface")
 )
@@ -40,8 +41,10 @@ type ConflationUnit struct {
 	readyQueue           *ConflationReadyQueue
 	// requireInitialDependencyChecks bool
 	isInReadyQueue bool
-	lock           sync.Mutex
-	statistics     *statistics.Statistics
+	// the suspended unit doesn't dispatch the bundles, e.g. the partition of the hub is revoked from the replica
+	suspended  bool
+	lock       sync.Mutex
+	statistics *statistics.Statistics
 }
 
 func newConflationUnit(log logr.Logger, readyQueue *ConflationReadyQueue,
@@ -143,6 +146,11 @@ func (cu *ConflationUnit) GetNext() (bundle.ManagerBundle, *ConflationBundleMeta
 	cu.lock.Lock()
 	defer cu.lock.Unlock()
 
+	if cu.suspended { // the unit is dispatched again once it's resumed
+		cu.isInReadyQueue = false
+		return nil, nil, nil, errConflationUnitSuspended
+	}
+
 	nextBundleToProcessPriority := cu.getNextReadyBundlePriority()
 	if nextBundleToProcessPriority == invalidPriority { // CU adds itself to RQ only when it has ready to process bundle
 		return nil, nil, nil, errNoReadyBundle // therefore this shouldn't happen
@@ -189,6 +197,55 @@ func (cu *ConflationUnit) ReportResult(metadata *ConflationBundleMetadata, err e
 	}
 }
 
+// getProcessedVersion returns a copy of the last processed version of the bundle type.
+func (cu *ConflationUnit) getProcessedVersion(bundleType string) *metadata.BundleVersion {
+	cu.lock.Lock()
+	defer cu.lock.Unlock()
+
+	priority, found := cu.bundleTypeToPriority[bundleType]
+	if !found {
+		return nil
+	}
+	version := *cu.priorityQueue[priority].lastProcessedVersion
+	return &version
+}
+
+// getProcessedVersions returns the copies of the last processed versions of the received bundle types.
+func (cu *ConflationUnit) getProcessedVersions() map[string]metadata.BundleVersion {
+	cu.lock.Lock()
+	defer cu.lock.Unlock()
+
+	versions := map[string]metadata.BundleVersion{}
+	for bundleType, priority := range cu.bundleTypeToPriority {
+		version := *cu.priorityQueue[priority].lastProcessedVersion
+		if !version.Equals(metadata.NewBundleVersion()) {
+			versions[bundleType] = version
+		}
+	}
+	return versions
+}
+
+// suspend stops dispatching the bundles of the conflation unit, and returns whether a bundle is still in process.
+func (cu *ConflationUnit) suspend() bool {
+	cu.lock.Lock()
+	defer cu.lock.Unlock()
+
+	cu.suspended = true
+	return cu.isInProcess()
+}
+
+// resume continues dispatching the bundles of the suspended conflation unit.
+func (cu *ConflationUnit) resume() {
+	cu.lock.Lock()
+	defer cu.lock.Unlock()
+
+	if !cu.suspended {
+		return
+	}
+	cu.suspended = false
+	cu.addCUToReadyQueueIfNeeded()
+}
+
 func (cu *ConflationUnit) isInProcess() bool {
 	for _, conflationElement := range cu.priorityQueue {
 		if conflationElement.isInProcess {
@@ -199,7 +256,7 @@ func (cu *ConflationUnit) isInProcess() bool {
 }
 
 func (cu *ConflationUnit) addCUToReadyQueueIfNeeded() {
-	if cu.isInReadyQueue || cu.isInProcess() {
+	if cu.suspended || cu.isInReadyQueue || cu.isInProcess() {
 		return // allow CU to appear only once in RQ/processing
 	}
 	// if we reached here, CU is not in RQ nor during processing
@@ -77,3 +77,8 @@ func (pool *DBWorkerPool) Acquire() (*Worker, error) {
 	}
 	return nil, fmt.Errorf("timeout to get the DBWorker")
 }
+
+// Release returns the acquired worker to the pool without running a job on it.
+func (pool *DBWorkerPool) Release(worker *Worker) {
+	pool.workers <- worker
+}
@@ -53,6 +53,12 @@ const (
 	KafkaCertSecretName        = "kafka-certs-secret"                // #nosec G101
 	GHDefaultStorageRetention  = "18m"                               // 18 months
 	PostgresCAConfigMap        = "multicluster-global-hub-postgres-ca"
+	// GHTransportHealthConfigMap is published by the manager with the consumer lag of the transport
+	GHTransportHealthConfigMap = "multicluster-global-hub-transport-health"
+	GHTransportHealthKey       = "health"
+	// GHNotificationSecretName holds the webhook endpoints which the manager delivers the events to
+	GHNotificationSecretName = "multicluster-global-hub-notification" // #nosec G101
+	GHNotificationConfigKey  = "config.yaml"
 )
 
 // global hub console secret/configmap names
@@ -80,6 +86,15 @@ const (
 	ManagedClusterManagedByAnnotation = "global-hub.open-cluster-management.io/managed-by"
 	// identify the resource is from the global hub cluster
 	OriginOwnerReferenceAnnotation = "global-hub.open-cluster-management.io/origin-ownerreference-uid"
+	// the rollout strategy of the global policy, the policy is delivered to the managed hubs in the ordered waves
+	PolicyRolloutStrategyAnnotation = "global-hub.open-cluster-management.io/rollout-strategy"
+	// the generation of the global policy delivered by the rollout, the managed hub reports it with the compliance so
+	// that the rollout only counts the status of the delivered version
+	PolicyRolloutGenerationAnnotation = "global-hub.open-cluster-management.io/rollout-generation"
+	// the ACM version of the managed hub which installs the addon, the addon itself doesn't report its version
+	ManagedHubVersionAnnotation = "global-hub.open-cluster-management.io/hub-version"
+	// the id of the migration which creates the managed cluster on the target hub
+	ManagedClusterMigrationAnnotation = "global-hub.open-cluster-management.io/migration"
 )
 
 // store all the finalizers
@@ -122,11 +137,20 @@ const (
 	HubClusterHeartbeatMsgKey = "HubClusterHeartbeat"
 
 	ResyncMsgKey = "Resync"
+	// PolicyRolloutMsgKey - the policies delivered to the specific managed hub by the rollout.
+	PolicyRolloutMsgKey = "PolicyRollout"
+	// LocalPolicyReplacementMsgKey - the local policies deleted on the specific managed hub once they're replaced by
+	// the adopted global policy.
+	LocalPolicyReplacementMsgKey = "LocalPolicyReplacement"
 
 	// ManagedClustersMsgKey - managed clusters message key.
 	ManagedClustersMsgKey = "ManagedClusters"
 	// ManagedClustersLabelsMsgKey - managed clusters labels message key.
 	ManagedClustersLabelsMsgKey = "ManagedClustersLabels"
+	// ManagedClusterAddOnsMsgKey - managed cluster addons message key.
+	ManagedClusterAddOnsMsgKey = "ManagedClusterAddOns"
+	// GitOpsApplicationsMsgKey - argo cd applications message key.
+	GitOpsApplicationsMsgKey = "GitOpsApplications"
 
 	// ComplianceMsgKey - clusters per policy message key.
 	ComplianceMsgKey = "Compliance"
@@ -159,6 +183,17 @@ const (
 	PlacementMsgKey = "Placement"
 	// PlacementDecisionMsgKey - placement-decision message key.
 	PlacementDecisionMsgKey = "PlacementDecision"
+
+	// GlobalResourceDriftMsgKey - the drift of the global resources message key.
+	GlobalResourceDriftMsgKey = "GlobalResourceDrift"
+	// ComplianceDetailsMsgKey - the per-template status details of the global policies message key.
+	ComplianceDetailsMsgKey = "ComplianceDetails"
+	// SpecSnapshotRequestMsgKey - the spec bundles which the managed hub requests the snapshots of message key.
+	SpecSnapshotRequestMsgKey = "SpecSnapshotRequest"
+	// ManagedClusterMigrationMsgKey - the stage of the managed cluster migration message key.
+	ManagedClusterMigrationMsgKey = "ManagedClusterMigration"
+	// ManagedClusterMigrationStatusMsgKey - the results of the managed cluster migration stages message key.
+	ManagedClusterMigrationStatusMsgKey = "ManagedClusterMigrationStatus"
 )
 
 // event exporter reference object label keys
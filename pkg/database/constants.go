@@ -16,9 +16,15 @@ const (
 const (
 	// ManagedClustersTableName table name of managed clusters.
 	ManagedClustersTableName = "managed_clusters"
+	// ManagedClusterAddOnsTableName table name of managed cluster addons.
+	ManagedClusterAddOnsTableName = "managed_cluster_addons"
+	// GitOpsApplicationsTableName table name of argo cd applications.
+	GitOpsApplicationsTableName = "gitops_applications"
 
 	// ComplianceTableName table name of policy compliance status.
 	ComplianceTableName = "compliance"
+	// ComplianceDetailsTableName table name of the per-template status details of the policies.
+	ComplianceDetailsTableName = "compliance_details"
 	// MinimalComplianceTable table name of minimal policy compliance status.
 	MinimalComplianceTable = "aggregated_compliance"
 	// LocalPolicySpecTableName table name of local policy spec.
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"sync"
+	"time"
 
 	_ "github.com/lib/pq"
 	"gorm.io/driver/postgres"
@@ -32,6 +33,14 @@ type DatabaseConfig struct {
 	Dialect    string
 	CaCertPath string
 	PoolSize   int
+	// JobPoolSize and APIPoolSize are the sizes of the separate pools for the background jobs and the API reads,
+	// so that the expensive queries can't starve the status ingestion. zero shares the ingestion pool.
+	JobPoolSize int
+	APIPoolSize int
+	// ReplicaURL is the optional read replica to serve the API reads, the reads fall back to the primary once the
+	// replica is unreachable or it lags behind the primary more than MaxReplicaLag.
+	ReplicaURL    string
+	MaxReplicaLag time.Duration
 }
 
 func InitGormInstance(config *DatabaseConfig) error {
@@ -44,34 +53,44 @@ func InitGormInstance(config *DatabaseConfig) error {
 		return err
 	}
 	gormOnce.Do(func() {
-		sqlDB, err = sql.Open(config.Dialect, urlObj.String())
+		gormDB, sqlDB, err = openGorm(config.Dialect, urlObj.String(), config.PoolSize)
 		if err != nil {
-			log.Error(err, "failed to open database connection")
 			return
 		}
-		// sqlDB.SetMaxOpenConns(config.PoolSize)
-		gormDB, err = gorm.Open(postgres.New(postgres.Config{
-			Conn:                 sqlDB,
-			PreferSimpleProtocol: true,
-		}), &gorm.Config{
-			PrepareStmt:          false,
-			FullSaveAssociations: false,
-		})
-		if err != nil {
-			log.Error(err, "failed to open gorm connection")
-			return
-		}
-		sqlDB, err = gormDB.DB()
-		if err != nil {
-			log.Error(err, "failed to open gorm connection")
-			return
-		}
-		fmt.Println("set max connection==============:", config.PoolSize)
-		sqlDB.SetMaxOpenConns(config.PoolSize)
+		log.Info("set max connection", "ingest", config.PoolSize, "job", config.JobPoolSize,
+			"api", config.APIPoolSize)
+		err = initPools(config, urlObj.String())
 	})
 	return err
 }
 
+func openGorm(dialect, url string, poolSize int) (*gorm.DB, *sql.DB, error) {
+	conn, err := sql.Open(dialect, url)
+	if err != nil {
+		log.Error(err, "failed to open database connection")
+		return nil, nil, err
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		Conn:                 conn,
+		PreferSimpleProtocol: true,
+	}), &gorm.Config{
+		PrepareStmt:          false,
+		FullSaveAssociations: false,
+	})
+	if err != nil {
+		log.Error(err, "failed to open gorm connection")
+		return nil, nil, err
+	}
+	conn, err = db.DB()
+	if err != nil {
+		log.Error(err, "failed to open gorm connection")
+		return nil, nil, err
+	}
+	conn.SetMaxOpenConns(poolSize)
+	return db, conn, nil
+}
+
+// GetGorm returns the connections of the primary database for the status ingestion and the other writes.
 func GetGorm() *gorm.DB {
 	if gormDB == nil {
 		log.Error(nil, "gorm connection is not initialized")
@@ -82,6 +101,7 @@ func GetGorm() *gorm.DB {
 
 // Close the sql.DB connection
 func CloseGorm() {
+	closePools()
 	if sqlDB != nil {
 		err := sqlDB.Close()
 		if err != nil {
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
 
 type LocalComplianceJobLog struct {
 	Name     string    `gorm:"column:name"`
@@ -15,3 +19,54 @@ type LocalComplianceJobLog struct {
 func (LocalComplianceJobLog) TableName() string {
 	return "history.local_compliance_job_log"
 }
+
+type GlobalResourceDrift struct {
+	LeafHubName string         `gorm:"column:leaf_hub_name;primaryKey"`
+	APIVersion  string         `gorm:"column:api_version;not null"`
+	Kind        string         `gorm:"column:kind;primaryKey"`
+	Namespace   string         `gorm:"column:namespace;primaryKey"`
+	Name        string         `gorm:"column:name;primaryKey"`
+	FieldPaths  datatypes.JSON `gorm:"column:field_paths;type:jsonb"`
+	Deleted     bool           `gorm:"column:deleted"`
+	Action      string         `gorm:"column:action;not null"`
+	DetectedAt  time.Time      `gorm:"column:detected_at;primaryKey"`
+	ResolvedAt  *time.Time     `gorm:"column:resolved_at"`
+}
+
+func (GlobalResourceDrift) TableName() string {
+	return "history.global_resource_drifts"
+}
+
+type GitOpsApplicationHealth struct {
+	LeafHubName    string    `gorm:"column:leaf_hub_name;not null"`
+	Namespace      string    `gorm:"column:namespace;not null"`
+	Name           string    `gorm:"column:name;not null"`
+	PreviousHealth string    `gorm:"column:previous_health"`
+	Health         string    `gorm:"column:health;not null"`
+	SyncStatus     string    `gorm:"column:sync_status"`
+	Revision       string    `gorm:"column:revision"`
+	TransitionedAt time.Time `gorm:"column:transitioned_at;default:current_timestamp"`
+}
+
+func (GitOpsApplicationHealth) TableName() string {
+	return "history.gitops_application_health"
+}
+
+// AuditLog is a mutation made through the global hub, the diff has the changed fields before and after the mutation
+type AuditLog struct {
+	ID              string         `gorm:"column:id;primaryKey"`
+	Actor           string         `gorm:"column:actor;not null"`
+	ActorGroups     datatypes.JSON `gorm:"column:actor_groups;type:jsonb"`
+	Source          string         `gorm:"column:source;not null"`
+	Action          string         `gorm:"column:action;not null"`
+	TargetKind      string         `gorm:"column:target_kind;not null"`
+	TargetNamespace string         `gorm:"column:target_namespace"`
+	TargetName      string         `gorm:"column:target_name;not null"`
+	TargetID        string         `gorm:"column:target_id"`
+	Diff            datatypes.JSON `gorm:"column:diff;type:jsonb"`
+	CreatedAt       time.Time      `gorm:"column:created_at;primaryKey;default:now()"`
+}
+
+func (AuditLog) TableName() string {
+	return "history.audit_log"
+}
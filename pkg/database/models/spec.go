@@ -65,3 +65,42 @@ type SpecPlacementBinding struct {
 func (SpecPlacementBinding) TableName() string {
 	return "spec.placementbindings"
 }
+
+// PolicyRollout is the rollout state of the global policy version, the stable payload is the previous version which
+// has been rolled out to all the hubs.
+type PolicyRollout struct {
+	PolicyID         string         `gorm:"column:policy_id;primaryKey"`
+	Generation       int64          `gorm:"column:generation;not null"`
+	Payload          datatypes.JSON `gorm:"column:payload;type:jsonb"`
+	StablePayload    datatypes.JSON `gorm:"column:stable_payload;type:jsonb"`
+	StableGeneration int64          `gorm:"column:stable_generation;not null"`
+	Strategy         datatypes.JSON `gorm:"column:strategy;type:jsonb"`
+	Phase            string         `gorm:"column:phase;not null"`
+	CurrentWave      int            `gorm:"column:current_wave;not null"`
+	Waves            datatypes.JSON `gorm:"column:waves;type:jsonb"`
+	Message          string         `gorm:"column:message"`
+	CreatedAt        time.Time      `gorm:"column:created_at;autoCreateTime:true"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at;autoUpdateTime:true"`
+}
+
+func (PolicyRollout) TableName() string {
+	return "spec.policy_rollouts"
+}
+
+// LocalPolicyReplacement is the local policy replaced by the adopted global policy, it's deleted on the hub once the
+// global policy lands on the hub.
+type LocalPolicyReplacement struct {
+	LocalPolicyID   string    `gorm:"column:local_policy_id;primaryKey"`
+	LeafHubName     string    `gorm:"column:leaf_hub_name;not null"`
+	LocalNamespace  string    `gorm:"column:local_namespace;not null"`
+	LocalName       string    `gorm:"column:local_name;not null"`
+	PolicyNamespace string    `gorm:"column:policy_namespace;not null"`
+	PolicyName      string    `gorm:"column:policy_name;not null"`
+	Replaced        bool      `gorm:"column:replaced;not null"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime:true"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;autoUpdateTime:true"`
+}
+
+func (LocalPolicyReplacement) TableName() string {
+	return "spec.local_policy_replacements"
+}
@@ -24,6 +24,45 @@ func (ManagedCluster) TableName() string {
 	return "status.managed_clusters"
 }
 
+type ManagedClusterAddOn struct {
+	LeafHubName      string         `gorm:"column:leaf_hub_name;primaryKey"`
+	ClusterName      string         `gorm:"column:cluster_name;primaryKey"`
+	AddOnName        string         `gorm:"column:addon_name;primaryKey"`
+	InstallNamespace string         `gorm:"column:install_namespace"`
+	HubVersion       string         `gorm:"column:hub_version"`
+	Available        string         `gorm:"column:available;not null"`
+	Degraded         string         `gorm:"column:degraded;not null"`
+	Conditions       datatypes.JSON `gorm:"column:conditions;type:jsonb"`
+	ResourceVersion  string         `gorm:"column:resource_version"`
+	CreatedAt        time.Time      `gorm:"column:created_at;autoCreateTime:true"`
+	UpdatedAt        time.Time      `gorm:"column:updated_at;autoUpdateTime:true"`
+}
+
+func (ManagedClusterAddOn) TableName() string {
+	return "status.managed_cluster_addons"
+}
+
+type GitOpsApplication struct {
+	LeafHubName          string         `gorm:"column:leaf_hub_name;primaryKey"`
+	Namespace            string         `gorm:"column:namespace;primaryKey"`
+	Name                 string         `gorm:"column:name;primaryKey"`
+	Project              string         `gorm:"column:project"`
+	Health               string         `gorm:"column:health;not null"`
+	SyncStatus           string         `gorm:"column:sync_status;not null"`
+	Revision             string         `gorm:"column:revision"`
+	DestinationServer    string         `gorm:"column:destination_server"`
+	DestinationName      string         `gorm:"column:destination_name"`
+	DestinationNamespace string         `gorm:"column:destination_namespace"`
+	Payload              datatypes.JSON `gorm:"column:payload;type:jsonb"`
+	ResourceVersion      string         `gorm:"column:resource_version"`
+	CreatedAt            time.Time      `gorm:"column:created_at;autoCreateTime:true"`
+	UpdatedAt            time.Time      `gorm:"column:updated_at;autoUpdateTime:true"`
+}
+
+func (GitOpsApplication) TableName() string {
+	return "status.gitops_applications"
+}
+
 type LeafHub struct {
 	LeafHubName string         `gorm:"column:leaf_hub_name;not null"`
 	ClusterID   string         `gorm:"column:cluster_id;primaryKey"`
@@ -43,6 +82,8 @@ type StatusCompliance struct {
 	LeafHubName string                    `gorm:"column:leaf_hub_name;not null"`
 	Error       string                    `gorm:"column:error;not null"`
 	Compliance  database.ComplianceStatus `gorm:"column:compliance;not null"`
+	// PolicyGeneration is the generation of the global policy delivered by the rollout which reports the compliance
+	PolicyGeneration int64 `gorm:"column:policy_generation;not null"`
 	// ClusterID   string                    `gorm:"column:cluster_id;default:(-)"`
 }
 
@@ -50,6 +91,18 @@ func (StatusCompliance) TableName() string {
 	return "status.compliance"
 }
 
+type StatusComplianceDetails struct {
+	PolicyID    string         `gorm:"column:policy_id;primaryKey"`
+	ClusterName string         `gorm:"column:cluster_name;primaryKey"`
+	LeafHubName string         `gorm:"column:leaf_hub_name;primaryKey"`
+	Templates   datatypes.JSON `gorm:"column:templates;type:jsonb"`
+	UpdatedAt   time.Time      `gorm:"column:updated_at;autoUpdateTime:true"`
+}
+
+func (StatusComplianceDetails) TableName() string {
+	return "status.compliance_details"
+}
+
 type AggregatedCompliance struct {
 	PolicyID             string `gorm:"column:policy_id;not null"`
 	LeafHubName          string `gorm:"column:leaf_hub_name;not null"`
@@ -73,9 +126,10 @@ func (Transport) TableName() string {
 }
 
 type LeafHubHeartbeat struct {
-	Name         string    `gorm:"column:leaf_hub_name;primaryKey"`
-	Status       string    `gorm:"column:status;default:(-)"`
-	LastUpdateAt time.Time `gorm:"column:last_timestamp;autoUpdateTime:false"`
+	Name         string         `gorm:"column:leaf_hub_name;primaryKey"`
+	Status       string         `gorm:"column:status;default:(-)"`
+	LastUpdateAt time.Time      `gorm:"column:last_timestamp;autoUpdateTime:false"`
+	Health       datatypes.JSON `gorm:"column:health;type:jsonb"` // AgentHealth
 }
 
 func (LeafHubHeartbeat) TableName() string {
@@ -87,3 +141,85 @@ func (h LeafHubHeartbeat) UpInsertHeartBeat(db *gorm.DB) error {
 		VALUES ($1, $2, $3) ON CONFLICT (leaf_hub_name) DO UPDATE SET last_timestamp = $3;`
 	return db.Exec(tmp, h.Name, h.Status, h.LastUpdateAt).Error
 }
+
+// Resync is the request to resync the status bundles from the managed hubs, the progress of each hub is tracked by
+// the manager.
+type Resync struct {
+	ID             string         `gorm:"column:id;primaryKey"`
+	LeafHubs       datatypes.JSON `gorm:"column:leaf_hubs;type:jsonb"`
+	BundleKeys     datatypes.JSON `gorm:"column:bundle_keys;type:jsonb"`
+	Trigger        string         `gorm:"column:trigger;not null"`
+	TimeoutSeconds int            `gorm:"column:timeout_seconds;not null"`
+	Phase          string         `gorm:"column:phase;not null"`
+	Progress       datatypes.JSON `gorm:"column:progress;type:jsonb"`
+	Message        string         `gorm:"column:message"`
+	StartedAt      *time.Time     `gorm:"column:started_at"`
+	CompletedAt    *time.Time     `gorm:"column:completed_at"`
+	CreatedAt      time.Time      `gorm:"column:created_at;autoCreateTime:true"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at;autoUpdateTime:true"`
+}
+
+func (Resync) TableName() string {
+	return "status.resyncs"
+}
+
+// LeafHubBundle is the last version of the bundle type processed for the leaf hub.
+type LeafHubBundle struct {
+	LeafHubName string    `gorm:"column:leaf_hub_name;primaryKey"`
+	BundleType  string    `gorm:"column:bundle_type;primaryKey"`
+	Generation  int64     `gorm:"column:generation;not null"`
+	Value       int64     `gorm:"column:value;not null"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime:true"`
+}
+
+func (LeafHubBundle) TableName() string {
+	return "status.leaf_hub_bundles"
+}
+
+// SpecSnapshotRequest is the request of the leaf hub for the snapshot of the spec bundle, it's renewed once the hub
+// requests the snapshot again.
+type SpecSnapshotRequest struct {
+	LeafHubName string    `gorm:"column:leaf_hub_name;primaryKey"`
+	BundleKey   string    `gorm:"column:bundle_key;primaryKey"`
+	RequestedAt time.Time `gorm:"column:requested_at;default:now()"`
+}
+
+func (SpecSnapshotRequest) TableName() string {
+	return "status.spec_snapshot_requests"
+}
+
+// AuditActor is the user of the latest admitted change of the global resource, the namespace is empty for the
+// cluster scoped resources.
+type AuditActor struct {
+	Kind        string         `gorm:"column:kind;primaryKey"`
+	Namespace   string         `gorm:"column:namespace;primaryKey"`
+	Name        string         `gorm:"column:name;primaryKey"`
+	Actor       string         `gorm:"column:actor;not null"`
+	ActorGroups datatypes.JSON `gorm:"column:actor_groups;type:jsonb"`
+	UpdatedAt   time.Time      `gorm:"column:updated_at;autoUpdateTime:true"`
+}
+
+func (AuditActor) TableName() string {
+	return "status.audit_actors"
+}
+
+// ManagedClusterMigration is the request to migrate the managed clusters from the source hub to the target hub, the
+// progress of each cluster is tracked by the manager.
+type ManagedClusterMigration struct {
+	ID             string         `gorm:"column:id;primaryKey"`
+	SourceHub      string         `gorm:"column:source_hub;not null"`
+	TargetHub      string         `gorm:"column:target_hub;not null"`
+	Clusters       datatypes.JSON `gorm:"column:clusters;type:jsonb"`
+	TimeoutSeconds int            `gorm:"column:timeout_seconds;not null"`
+	Phase          string         `gorm:"column:phase;not null"`
+	Progress       datatypes.JSON `gorm:"column:progress;type:jsonb"`
+	Message        string         `gorm:"column:message"`
+	StartedAt      *time.Time     `gorm:"column:started_at"`
+	CompletedAt    *time.Time     `gorm:"column:completed_at"`
+	CreatedAt      time.Time      `gorm:"column:created_at;autoCreateTime:true"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at;autoUpdateTime:true"`
+}
+
+func (ManagedClusterMigration) TableName() string {
+	return "status.managed_cluster_migrations"
+}
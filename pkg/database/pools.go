@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	replicaCheckInterval = 10 * time.Second
+	replicaCheckTimeout  = 5 * time.Second
+)
+
+// replicaLagQuery returns the seconds the replica lags behind the primary, the argument is the current wal location
+// of the primary. the replica which has replayed the wal up to the location doesn't lag even if the primary is idle,
+// and the primary itself returns zero. the replica disconnected from the primary doesn't replay the wal, so it lags
+// once the primary is written. it returns -1 if the replica hasn't replayed any transaction.
+const replicaLagQuery = `SELECT CASE WHEN NOT pg_is_in_recovery() OR pg_last_wal_replay_lsn() >= ?::pg_lsn THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), -1) END`
+
+var (
+	jobDB     *gorm.DB
+	apiDB     *gorm.DB
+	replica   *replicaRouter
+	poolConns []*sql.DB
+)
+
+// replicaRouter serves the reads from the replica while it's healthy, otherwise from the fallback connections of
+// the primary database.
+type replicaRouter struct {
+	db       *gorm.DB
+	fallback *gorm.DB
+	maxLag   time.Duration
+	lagFunc  func(ctx context.Context) (time.Duration, error)
+	healthy  atomic.Bool
+	stopCh   chan struct{}
+}
+
+// initPools opens the separate pools of the primary database for the jobs and the API, and the replica pool.
+func initPools(config *DatabaseConfig, primaryURL string) error {
+	var err error
+	if jobDB, err = openPool(config.Dialect, primaryURL, config.JobPoolSize); err != nil {
+		return err
+	}
+	if apiDB, err = openPool(config.Dialect, primaryURL, config.APIPoolSize); err != nil {
+		return err
+	}
+	if config.ReplicaURL == "" {
+		return nil
+	}
+
+	replicaURL, err := completePostgres(config.ReplicaURL, config.CaCertPath)
+	if err != nil {
+		return err
+	}
+	poolSize := config.APIPoolSize
+	if poolSize <= 0 {
+		poolSize = config.PoolSize
+	}
+	replicaDB, replicaConn, err := openGorm(config.Dialect, replicaURL.String(), poolSize)
+	if err != nil {
+		return err
+	}
+	poolConns = append(poolConns, replicaConn)
+
+	fallback := apiDB
+	if fallback == nil {
+		fallback = gormDB
+	}
+	lagFunc := func(ctx context.Context) (time.Duration, error) {
+		// the location of the primary is queried first, so the replica which is caught up has replayed it
+		var primaryLSN string
+		if err := fallback.WithContext(ctx).Raw("SELECT pg_current_wal_lsn()::text").Row().Scan(&primaryLSN); err != nil {
+			return 0, fmt.Errorf("failed to query the wal location of the primary database: %w", err)
+		}
+		var seconds float64
+		if err := replicaDB.WithContext(ctx).Raw(replicaLagQuery, primaryLSN).Row().Scan(&seconds); err != nil {
+			return 0, err
+		}
+		if seconds < 0 {
+			return 0, errors.New("the replica database hasn't replayed the wal of the primary database")
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	replica = newReplicaRouter(replicaDB, fallback, config.MaxReplicaLag, lagFunc)
+	if replica.check(); !replica.healthy.Load() {
+		log.Info("the replica database isn't ready, reading from the primary database")
+	}
+	go replica.run(replicaCheckInterval)
+	return nil
+}
+
+// openPool opens the separate pool of the primary database, it returns nil if the pool size isn't set.
+func openPool(dialect, url string, poolSize int) (*gorm.DB, error) {
+	if poolSize <= 0 {
+		return nil, nil
+	}
+	db, conn, err := openGorm(dialect, url, poolSize)
+	if err != nil {
+		return nil, err
+	}
+	poolConns = append(poolConns, conn)
+	return db, nil
+}
+
+func closePools() {
+	if replica != nil {
+		close(replica.stopCh)
+		replica = nil
+	}
+	for _, conn := range poolConns {
+		if err := conn.Close(); err != nil {
+			log.Error(err, "failed to close database connection")
+		}
+	}
+	poolConns = nil
+	jobDB, apiDB = nil, nil
+}
+
+// GetJobGorm returns the connections for the background jobs, e.g. the data retention and the compliance history.
+func GetJobGorm() *gorm.DB {
+	if jobDB != nil {
+		return jobDB
+	}
+	return GetGorm()
+}
+
+// GetReadGorm returns the connections for the API reads, it's the replica if it's configured and healthy. the reads
+// may not see the latest writes of the primary, so the read-after-write queries should use GetGorm instead.
+func GetReadGorm() *gorm.DB {
+	if replica != nil {
+		return replica.get()
+	}
+	if apiDB != nil {
+		return apiDB
+	}
+	return GetGorm()
+}
+
+func newReplicaRouter(db, fallback *gorm.DB, maxLag time.Duration,
+	lagFunc func(ctx context.Context) (time.Duration, error),
+) *replicaRouter {
+	return &replicaRouter{
+		db:       db,
+		fallback: fallback,
+		maxLag:   maxLag,
+		lagFunc:  lagFunc,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+func (r *replicaRouter) get() *gorm.DB {
+	if r.healthy.Load() {
+		return r.db
+	}
+	return r.fallback
+}
+
+// check marks the replica as unhealthy if it can't be queried or it lags behind the primary more than the max lag.
+func (r *replicaRouter) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaCheckTimeout)
+	defer cancel()
+
+	lag, err := r.lagFunc(ctx)
+	healthy := err == nil && (r.maxLag <= 0 || lag <= r.maxLag)
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		log.Info("reading from the replica database", "lag", lag.String())
+	} else if err != nil {
+		log.Error(err, "failed to query the replica database, reading from the primary database")
+	} else {
+		log.Info("the replica database lags behind, reading from the primary database", "lag", lag.String(),
+			"maxLag", r.maxLag.String())
+	}
+}
+
+func (r *replicaRouter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.check()
+		}
+	}
+}
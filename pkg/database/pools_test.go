@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestReplicaRouter(t *testing.T) {
+	replicaDB, primaryDB := &gorm.DB{}, &gorm.DB{}
+	var lag time.Duration
+	var lagErr error
+	router := newReplicaRouter(replicaDB, primaryDB, time.Minute, func(ctx context.Context) (time.Duration, error) {
+		return lag, lagErr
+	})
+
+	// the replica isn't used before it's checked
+	assert.Same(t, primaryDB, router.get())
+
+	router.check()
+	assert.Same(t, replicaDB, router.get())
+
+	// the replica lags behind
+	lag = 2 * time.Minute
+	router.check()
+	assert.Same(t, primaryDB, router.get())
+
+	// the replica catches up
+	lag = time.Second
+	router.check()
+	assert.Same(t, replicaDB, router.get())
+
+	// the replica is unreachable
+	lagErr = errors.New("connection refused")
+	router.check()
+	assert.Same(t, primaryDB, router.get())
+
+	// the lag is ignored without the max lag
+	router.maxLag, lag, lagErr = 0, time.Hour, nil
+	router.check()
+	assert.Same(t, replicaDB, router.get())
+}
@@ -0,0 +1,176 @@
+package sensitive
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// encryptedPrefix marks the encrypted value, the version allows to rotate the encryption scheme.
+const encryptedPrefix = "enc:v1:"
+
+// FieldCipher encrypts the fields of the payloads with AES-GCM before they're stored, the field is replaced by the
+// string "enc:v1:<base64 of the nonce and the sealed json value>". the nil cipher keeps the payloads as they are.
+type FieldCipher struct {
+	aead  cipher.AEAD
+	rules Rules
+}
+
+// NewFieldCipher creates the cipher with the 16, 24 or 32 bytes key, the rules are keyed by the table name.
+func NewFieldCipher(key []byte, rules Rules) (*FieldCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &FieldCipher{aead: aead, rules: rules}, nil
+}
+
+// LoadKey reads the encryption key from the file, the key is either raw bytes or base64 encoded.
+func LoadKey(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the encryption key: %w", err)
+	}
+	raw := strings.TrimSpace(string(content))
+	if key, err := base64.StdEncoding.DecodeString(raw); err == nil && validKeySize(len(key)) {
+		return key, nil
+	}
+	if !validKeySize(len(raw)) {
+		return nil, fmt.Errorf("the encryption key must be 16, 24 or 32 bytes, got %d", len(raw))
+	}
+	return []byte(raw), nil
+}
+
+func validKeySize(size int) bool {
+	return size == 16 || size == 24 || size == 32
+}
+
+// Encrypt encrypts the fields located by the rules of the table, the encrypted fields aren't encrypted again.
+func (c *FieldCipher) Encrypt(table string, payload []byte) ([]byte, error) {
+	if c == nil || len(c.rules[table]) == 0 {
+		return payload, nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return nil, err
+	}
+	for _, path := range c.rules[table] {
+		var err error
+		if doc, _, err = path.Apply(doc, c.encryptValue); err != nil {
+			return nil, fmt.Errorf("failed to encrypt %s of %s: %w", path, table, err)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// Decrypt restores all the encrypted fields of the payload, it doesn't depend on the rules, so that the payloads
+// are still readable once the rules are changed.
+func (c *FieldCipher) Decrypt(payload []byte) ([]byte, error) {
+	if c == nil || !bytes.Contains(payload, []byte(encryptedPrefix)) {
+		return payload, nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return nil, err
+	}
+	doc, err := c.decryptValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+func (c *FieldCipher) encryptValue(value interface{}) (interface{}, error) {
+	if str, ok := value.(string); ok && strings.HasPrefix(str, encryptedPrefix) {
+		return value, nil
+	}
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *FieldCipher) decryptValue(value interface{}) (interface{}, error) {
+	switch typed := value.(type) {
+	case string:
+		if !strings.HasPrefix(typed, encryptedPrefix) {
+			return value, nil
+		}
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(typed, encryptedPrefix))
+		if err != nil || len(sealed) < c.aead.NonceSize() {
+			return nil, fmt.Errorf("invalid encrypted value")
+		}
+		nonceSize := c.aead.NonceSize()
+		plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt the value: %w", err)
+		}
+		var decrypted interface{}
+		if err := json.Unmarshal(plaintext, &decrypted); err != nil {
+			return nil, err
+		}
+		return decrypted, nil
+	case map[string]interface{}:
+		for key, child := range typed {
+			decrypted, err := c.decryptValue(child)
+			if err != nil {
+				return nil, err
+			}
+			typed[key] = decrypted
+		}
+	case []interface{}:
+		for i, child := range typed {
+			decrypted, err := c.decryptValue(child)
+			if err != nil {
+				return nil, err
+			}
+			typed[i] = decrypted
+		}
+	}
+	return value, nil
+}
+
+var defaultCipher struct {
+	sync.RWMutex
+	cipher *FieldCipher
+}
+
+// SetFieldCipher sets the cipher used by Encrypt and Decrypt, the nil cipher disables the encryption.
+func SetFieldCipher(c *FieldCipher) {
+	defaultCipher.Lock()
+	defer defaultCipher.Unlock()
+	defaultCipher.cipher = c
+}
+
+// Encrypt encrypts the payload stored into the table with the cipher set by SetFieldCipher.
+func Encrypt(table string, payload []byte) ([]byte, error) {
+	defaultCipher.RLock()
+	defer defaultCipher.RUnlock()
+	return defaultCipher.cipher.Encrypt(table, payload)
+}
+
+// Decrypt decrypts the payload with the cipher set by SetFieldCipher. it should only be called by the components
+// which deliver the payload to the authorized consumers, e.g. the managed hubs and the authenticated API.
+func Decrypt(payload []byte) ([]byte, error) {
+	defaultCipher.RLock()
+	defer defaultCipher.RUnlock()
+	return defaultCipher.cipher.Decrypt(payload)
+}
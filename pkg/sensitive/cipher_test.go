@@ -0,0 +1,80 @@
+package sensitive
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldCipher(t *testing.T) {
+	rules, err := ParseRules(`
+status.managed_clusters:
+- $.spec.managedClusterClientConfigs[*].caBundle
+- $.metadata.annotations
+`)
+	require.NoError(t, err)
+	c, err := NewFieldCipher([]byte(strings.Repeat("k", 32)), rules)
+	require.NoError(t, err)
+
+	payload := []byte(`{"metadata":{"name":"cluster1","annotations":{"token":"abc"}},` +
+		`"spec":{"managedClusterClientConfigs":[{"url":"https://cluster1","caBundle":"Y2E="}]}}`)
+	encrypted, err := c.Encrypt("status.managed_clusters", payload)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "Y2E=")
+	assert.NotContains(t, string(encrypted), "abc")
+	assert.Contains(t, string(encrypted), `"name":"cluster1"`)
+	assert.Contains(t, string(encrypted), `"url":"https://cluster1"`)
+
+	// the encrypted fields aren't encrypted again
+	again, err := c.Encrypt("status.managed_clusters", encrypted)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(encrypted), string(again))
+
+	decrypted, err := c.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(payload), string(decrypted))
+
+	// the payload of the table without rules isn't changed
+	unchanged, err := c.Encrypt("spec.policies", payload)
+	require.NoError(t, err)
+	assert.Equal(t, payload, unchanged)
+
+	// the payload can't be decrypted with another key
+	other, err := NewFieldCipher([]byte(strings.Repeat("o", 32)), rules)
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+	assert.Error(t, err)
+
+	// the nil cipher keeps the payload
+	var disabled *FieldCipher
+	unchanged, err = disabled.Encrypt("status.managed_clusters", payload)
+	require.NoError(t, err)
+	assert.Equal(t, payload, unchanged)
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	rawKey := strings.Repeat("r", 16)
+	key, err := LoadKey(write("raw", rawKey+"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte(rawKey), key)
+
+	encodedKey := []byte(strings.Repeat("e", 32))
+	key, err = LoadKey(write("base64", base64.StdEncoding.EncodeToString(encodedKey)))
+	require.NoError(t, err)
+	assert.Equal(t, encodedKey, key)
+
+	_, err = LoadKey(write("short", "short"))
+	assert.Error(t, err)
+}
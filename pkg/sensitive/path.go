@@ -0,0 +1,132 @@
+package sensitive
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is the subset of the json path to locate the fields of a payload, e.g.
+// "$.spec.policy-templates[*].objectDefinition.data" or "$.metadata.annotations['example.com/token']".
+// the supported segments are the field name, the quoted field name, the index and the wildcard.
+type Path struct {
+	raw      string
+	segments []segment
+}
+
+type segment struct {
+	name     string
+	index    int
+	wildcard bool
+}
+
+// TransformFunc returns the new value of the field located by the path.
+type TransformFunc func(value interface{}) (interface{}, error)
+
+// ParsePath parses the json path, the leading "$" is optional.
+func ParsePath(raw string) (*Path, error) {
+	expr := strings.TrimPrefix(strings.TrimSpace(raw), "$")
+	path := &Path{raw: raw}
+	for len(expr) > 0 {
+		switch expr[0] {
+		case '.':
+			end := strings.IndexAny(expr[1:], ".[")
+			if end < 0 {
+				end = len(expr) - 1
+			}
+			name := expr[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("invalid path %q: empty field name", raw)
+			}
+			path.segments = append(path.segments, segment{name: name, index: -1, wildcard: name == "*"})
+			expr = expr[end+1:]
+		case '[':
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ']'", raw)
+			}
+			seg, err := parseBracket(expr[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", raw, err)
+			}
+			path.segments = append(path.segments, seg)
+			expr = expr[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", raw, expr[0])
+		}
+	}
+	if len(path.segments) == 0 {
+		return nil, fmt.Errorf("invalid path %q: the root can't be selected", raw)
+	}
+	return path, nil
+}
+
+func parseBracket(expr string) (segment, error) {
+	if expr == "*" {
+		return segment{index: -1, wildcard: true}, nil
+	}
+	if len(expr) >= 2 && (expr[0] == '\'' || expr[0] == '"') && expr[len(expr)-1] == expr[0] {
+		return segment{name: expr[1 : len(expr)-1], index: -1}, nil
+	}
+	index, err := strconv.Atoi(expr)
+	if err != nil || index < 0 {
+		return segment{}, fmt.Errorf("invalid index %q", expr)
+	}
+	return segment{index: index}, nil
+}
+
+// String returns the raw path.
+func (p *Path) String() string {
+	return p.raw
+}
+
+// Apply replaces the fields located by the path in the decoded json document with the result of the function, it
+// returns the updated document and the number of the located fields. the missing fields are skipped.
+func (p *Path) Apply(doc interface{}, transform TransformFunc) (interface{}, int, error) {
+	return apply(doc, p.segments, transform)
+}
+
+func apply(value interface{}, segments []segment, transform TransformFunc) (interface{}, int, error) {
+	if len(segments) == 0 {
+		updated, err := transform(value)
+		if err != nil {
+			return value, 0, err
+		}
+		return updated, 1, nil
+	}
+
+	seg, located := segments[0], 0
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if seg.index >= 0 {
+			return value, 0, nil
+		}
+		for key, child := range typed {
+			if !seg.wildcard && key != seg.name {
+				continue
+			}
+			updated, n, err := apply(child, segments[1:], transform)
+			if err != nil {
+				return value, located, err
+			}
+			typed[key] = updated
+			located += n
+		}
+	case []interface{}:
+		if !seg.wildcard && seg.index < 0 {
+			return value, 0, nil
+		}
+		for i, child := range typed {
+			if !seg.wildcard && i != seg.index {
+				continue
+			}
+			updated, n, err := apply(child, segments[1:], transform)
+			if err != nil {
+				return value, located, err
+			}
+			typed[i] = updated
+			located += n
+		}
+	}
+	return value, located, nil
+}
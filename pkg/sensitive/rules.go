@@ -0,0 +1,88 @@
+package sensitive
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// RedactedValue replaces the string values of the redacted fields.
+const RedactedValue = "REDACTED"
+
+// Rules maps the resource to the json paths of its sensitive fields. the agent keys the rules by the kind of the
+// reported object, and the manager keys them by the table which stores the payload, e.g.
+//
+//	Policy:
+//	- $.spec.policy-templates[*].objectDefinition.spec.object-templates[*].objectDefinition.data
+type Rules map[string][]*Path
+
+// ParseRules parses the rules from the yaml or json document, the empty document has no rules.
+func ParseRules(raw string) (Rules, error) {
+	rawRules := map[string][]string{}
+	if err := yaml.Unmarshal([]byte(raw), &rawRules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	rules := Rules{}
+	for key, rawPaths := range rawRules {
+		for _, rawPath := range rawPaths {
+			path, err := ParsePath(rawPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rules of %s: %w", key, err)
+			}
+			rules[key] = append(rules[key], path)
+		}
+	}
+	return rules, nil
+}
+
+// Redact replaces the string values under the fields located by the rules of the resource with the RedactedValue.
+// the other values are kept, so that the redacted document can still be decoded into the typed object. it returns
+// the updated document and the number of the located fields.
+func (r Rules) Redact(key string, doc interface{}) (interface{}, int, error) {
+	located := 0
+	for _, path := range r[key] {
+		var n int
+		var err error
+		if doc, n, err = path.Apply(doc, redactValue); err != nil {
+			return doc, located, err
+		}
+		located += n
+	}
+	return doc, located, nil
+}
+
+// RedactObject redacts the typed object in place by the rules of the resource, it returns true if any field is
+// located by the rules.
+func (r Rules) RedactObject(key string, object runtime.Object) (bool, error) {
+	if len(r[key]) == 0 {
+		return false, nil
+	}
+	doc, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return false, err
+	}
+	if _, located, err := r.Redact(key, doc); err != nil || located == 0 {
+		return false, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(doc, object); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func redactValue(value interface{}) (interface{}, error) {
+	switch typed := value.(type) {
+	case string:
+		return RedactedValue, nil
+	case map[string]interface{}:
+		for key, child := range typed {
+			typed[key], _ = redactValue(child)
+		}
+	case []interface{}:
+		for i, child := range typed {
+			typed[i], _ = redactValue(child)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,97 @@
+package sensitive
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	policyv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+func TestParsePath(t *testing.T) {
+	for _, invalid := range []string{"", "$", "$.", "$.a[", "$.a[-1]", "$.a[b]", "spec"} {
+		_, err := ParsePath(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	path, err := ParsePath("$.metadata.annotations['example.com/token']")
+	require.NoError(t, err)
+	assert.Equal(t, []segment{
+		{name: "metadata", index: -1},
+		{name: "annotations", index: -1},
+		{name: "example.com/token", index: -1},
+	}, path.segments)
+
+	path, err = ParsePath(".items[1].*[*]")
+	require.NoError(t, err)
+	assert.Equal(t, []segment{
+		{name: "items", index: -1},
+		{index: 1},
+		{name: "*", index: -1, wildcard: true},
+		{index: -1, wildcard: true},
+	}, path.segments)
+}
+
+func TestRedact(t *testing.T) {
+	rules, err := ParseRules(`
+ConfigMap:
+- $.data
+- $.metadata.annotations['example.com/token']
+- $.spec.items[*].secrets[1]
+`)
+	require.NoError(t, err)
+
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"metadata": {"name": "cm", "annotations": {"example.com/token": "abc", "owner": "team"}},
+		"data": {"password": "secret", "replicas": 3},
+		"spec": {"items": [{"secrets": ["a", "b"]}, {"secrets": ["c"]}]}
+	}`), &doc))
+
+	doc, located, err := rules.Redact("ConfigMap", doc)
+	require.NoError(t, err)
+	assert.Equal(t, 3, located)
+	redacted, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"metadata": {"name": "cm", "annotations": {"example.com/token": "REDACTED", "owner": "team"}},
+		"data": {"password": "REDACTED", "replicas": 3},
+		"spec": {"items": [{"secrets": ["a", "REDACTED"]}, {"secrets": ["c"]}]}
+	}`, string(redacted))
+
+	_, located, err = rules.Redact("Secret", doc)
+	require.NoError(t, err)
+	assert.Zero(t, located)
+
+	_, err = ParseRules("Policy: $.spec")
+	assert.Error(t, err)
+}
+
+func TestRedactObject(t *testing.T) {
+	rules, err := ParseRules(`{"Policy": ["$.spec.policy-templates[*].objectDefinition.spec.object-templates` +
+		`[*].objectDefinition.data"]}`)
+	require.NoError(t, err)
+
+	policy := &policyv1.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy1", Namespace: "default"},
+		Spec: policyv1.PolicySpec{
+			PolicyTemplates: []*policyv1.PolicyTemplate{{
+				ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"kind":"ConfigurationPolicy","spec":{` +
+					`"object-templates":[{"objectDefinition":{"kind":"Secret","data":{"token":"dG9rZW4="}}}]}}`)},
+			}},
+		},
+	}
+	redacted, err := rules.RedactObject("Policy", policy)
+	require.NoError(t, err)
+	assert.True(t, redacted)
+	assert.Equal(t, "policy1", policy.Name)
+	assert.JSONEq(t, `{"kind":"ConfigurationPolicy","spec":{"object-templates":[{"objectDefinition":`+
+		`{"kind":"Secret","data":{"token":"REDACTED"}}}]}}`, string(policy.Spec.PolicyTemplates[0].ObjectDefinition.Raw))
+
+	redacted, err = rules.RedactObject("PlacementBinding", policy)
+	require.NoError(t, err)
+	assert.False(t, redacted)
+}
@@ -65,3 +65,19 @@ func (tm *genericMetrics) toStringUnsafe() string {
 	metrics.WriteString(fmt.Sprintf("successes=%d, avg=%.0f ms, max=%d ms", tm.successes, average, tm.maxDuration))
 	return metrics.String()
 }
+
+// snapshot returns a consistent copy of the metrics.
+func (tm *genericMetrics) snapshot() MetricsSnapshot {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	snapshot := MetricsSnapshot{
+		Successes: tm.successes,
+		Failures:  tm.failures,
+		Max:       time.Duration(tm.maxDuration) * time.Millisecond,
+	}
+	if tm.successes != 0 {
+		snapshot.Avg = time.Duration(tm.totalDuration/tm.successes) * time.Millisecond
+	}
+	return snapshot
+}
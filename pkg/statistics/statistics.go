@@ -156,3 +156,48 @@ func (s *Statistics) run(ctx context.Context, duration time.Duration) {
 		}
 	}
 }
+
+// MetricsSnapshot is the point-in-time view of the time measurements of a stage.
+type MetricsSnapshot struct {
+	Successes int64
+	Failures  int64
+	Avg       time.Duration
+	Max       time.Duration
+}
+
+// BundleSnapshot is the point-in-time view of the metrics of a bundle type.
+type BundleSnapshot struct {
+	Received   int64
+	Conflation MetricsSnapshot
+	Database   MetricsSnapshot
+}
+
+// Snapshot is the point-in-time view of the statistics, it's used by the callers which report the statistics on
+// their own instead of the periodic log, e.g. the fleet simulator.
+type Snapshot struct {
+	ConflationUnits          int
+	ConflationReadyQueueSize int
+	AvailableDBWorkers       int
+	Bundles                  map[string]BundleSnapshot
+}
+
+// Snapshot returns the current statistics.
+func (s *Statistics) Snapshot() *Snapshot {
+	s.mutex.Lock()
+	snapshot := &Snapshot{
+		ConflationUnits:          s.numOfConflationUnits,
+		ConflationReadyQueueSize: s.conflationReadyQueueSize,
+		AvailableDBWorkers:       s.numOfAvailableDBWorkers,
+		Bundles:                  make(map[string]BundleSnapshot, len(s.bundleMetrics)),
+	}
+	s.mutex.Unlock()
+
+	for bundleType, bundleMetrics := range s.bundleMetrics {
+		snapshot.Bundles[bundleType] = BundleSnapshot{
+			Received:   bundleMetrics.totalReceived,
+			Conflation: bundleMetrics.conflationUnit.snapshot(),
+			Database:   bundleMetrics.database.snapshot(),
+		}
+	}
+	return snapshot
+}
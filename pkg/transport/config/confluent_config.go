@@ -29,6 +29,9 @@ func GetConfluentConfigMap(kafkaConfig *transport.KafkaConfig, producer bool) (*
 		_ = kafkaConfigMap.SetKey("auto.offset.reset", "earliest")
 		_ = kafkaConfigMap.SetKey("group.id", kafkaConfig.ConsumerConfig.ConsumerID)
 		_ = kafkaConfigMap.SetKey("client.id", kafkaConfig.ConsumerConfig.ConsumerID)
+		// spread the partitions of all the subscribed topics across the consumers of the group, the range strategy
+		// assigns the only partition of every status.<hub> topic to the same consumer
+		_ = kafkaConfigMap.SetKey("partition.assignment.strategy", "roundrobin")
 	}
 
 	_, validCA := utils.Validate(kafkaConfig.CaCertPath)
@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/client"
@@ -25,16 +26,30 @@ import (
 	"github.com/stolostron/multicluster-global-hub/pkg/transport/kafka_confluent"
 )
 
+// positionDelimiter separates the topic and the partition in the name of the position committed in the database
+const positionDelimiter = "@"
+
 type GenericConsumer struct {
 	log                  logr.Logger
 	client               cloudevents.Client
 	assembler            *messageAssembler
 	messageChan          chan *transport.Message
 	withDatabasePosition bool
+	partitionListener    PartitionListener
+}
+
+// PartitionListener is notified when the partitions are assigned to or revoked from the consumer in the rebalance of
+// the consumer group, e.g. a consumer joins or leaves the group.
+type PartitionListener interface {
+	// PartitionsAssigned is invoked before the messages of the assigned partitions are received
+	PartitionsAssigned(partitions []metadata.TransportPosition)
+	// PartitionsRevoked is invoked before the partitions are assigned to the other consumers
+	PartitionsRevoked(partitions []metadata.TransportPosition)
 }
 
 type GenericConsumeOption func(*GenericConsumer) error
 
+// WithDatabasePosition starts consuming the assigned partitions from the positions committed in the database
 func WithDatabasePosition(fromPosition bool) GenericConsumeOption {
 	return func(c *GenericConsumer) error {
 		c.withDatabasePosition = fromPosition
@@ -42,19 +57,35 @@ func WithDatabasePosition(fromPosition bool) GenericConsumeOption {
 	}
 }
 
+func WithPartitionListener(listener PartitionListener) GenericConsumeOption {
+	return func(c *GenericConsumer) error {
+		c.partitionListener = listener
+		return nil
+	}
+}
+
 func NewGenericConsumer(tranConfig *transport.TransportConfig, opts ...GenericConsumeOption) (*GenericConsumer, error) {
-	log := ctrl.Log.WithName(fmt.Sprintf("%s-consumer", tranConfig.TransportType))
+	c := &GenericConsumer{
+		log:                  ctrl.Log.WithName(fmt.Sprintf("%s-consumer", tranConfig.TransportType)),
+		messageChan:          make(chan *transport.Message),
+		assembler:            newMessageAssembler(),
+		withDatabasePosition: false,
+	}
+	if err := c.applyOptions(opts...); err != nil {
+		return nil, err
+	}
+
 	var receiver interface{}
 	var err error
 	switch tranConfig.TransportType {
 	case string(transport.Kafka):
-		log.Info("transport consumer with cloudevents-kafka receiver")
-		receiver, err = getConfluentReceiverProtocol(tranConfig)
+		c.log.Info("transport consumer with cloudevents-kafka receiver")
+		receiver, err = getConfluentReceiverProtocol(tranConfig, c.rebalance)
 		if err != nil {
 			return nil, err
 		}
 	case string(transport.Chan):
-		log.Info("transport consumer with go chan receiver")
+		c.log.Info("transport consumer with go chan receiver")
 		if tranConfig.Extends == nil {
 			tranConfig.Extends = make(map[string]interface{})
 		}
@@ -66,21 +97,10 @@ func NewGenericConsumer(tranConfig *transport.TransportConfig, opts ...GenericCo
 		return nil, fmt.Errorf("transport-type - %s is not a valid option", tranConfig.TransportType)
 	}
 
-	client, err := cloudevents.NewClient(receiver, client.WithPollGoroutines(1))
+	c.client, err = cloudevents.NewClient(receiver, client.WithPollGoroutines(1))
 	if err != nil {
 		return nil, err
 	}
-
-	c := &GenericConsumer{
-		log:                  log,
-		client:               client,
-		messageChan:          make(chan *transport.Message),
-		assembler:            newMessageAssembler(),
-		withDatabasePosition: false,
-	}
-	if err := c.applyOptions(opts...); err != nil {
-		return nil, err
-	}
 	return c, nil
 }
 
@@ -94,25 +114,14 @@ func (c *GenericConsumer) applyOptions(opts ...GenericConsumeOption) error {
 }
 
 func (c *GenericConsumer) Start(ctx context.Context) error {
-	receiveContext := ctx
-	if c.withDatabasePosition {
-		offsets, err := getInitOffset()
-		if err != nil {
-			return err
-		}
-		c.log.Info("init consumer", "offsets", offsets)
-		if len(offsets) > 0 {
-			receiveContext = kafka_confluent.CommitOffsetCtx(ctx, offsets)
-		}
-	}
-
-	err := c.client.StartReceiver(receiveContext, func(ctx context.Context, event cloudevents.Event) ceprotocol.Result {
+	err := c.client.StartReceiver(ctx, func(ctx context.Context, event cloudevents.Event) ceprotocol.Result {
 		c.log.V(2).Info("received message and forward to bundle channel", "event.ID", event.ID())
 
 		transportMessage := &transport.Message{}
 		transportMessage.Key = event.ID()
 		transportMessage.MsgType = event.Type()
 		transportMessage.Destination = event.Source()
+		transportMessage.Time = event.Time()
 		transportMessage.BundleStatus = status.NewThresholdBundleStatus(3, event)
 
 		chunk, isChunk := c.assembler.messageChunk(event)
@@ -137,27 +146,96 @@ func (c *GenericConsumer) MessageChan() chan *transport.Message {
 	return c.messageChan
 }
 
-func getInitOffset() ([]kafka.TopicPartition, error) {
-	db := database.GetGorm()
-	var positions []models.Transport
-	err := db.Where("name ~ ?", "^status*").Find(&positions).Error
+// rebalance assigns the partitions from the positions committed in the database, and notifies the partition listener
+// of the assigned and revoked partitions.
+func (c *GenericConsumer) rebalance(consumer *kafka.Consumer, event kafka.Event) error {
+	switch e := event.(type) {
+	case kafka.AssignedPartitions:
+		partitions := e.Partitions
+		if c.withDatabasePosition {
+			positions, err := CommittedPositions()
+			if err != nil {
+				// the partitions are consumed from the offsets committed to the consumer group
+				c.log.Error(err, "failed to get the committed positions from database")
+			} else {
+				partitions = startPartitions(partitions, positions)
+			}
+		}
+		c.log.Info("partitions assigned", "partitions", partitions)
+		if c.partitionListener != nil {
+			c.partitionListener.PartitionsAssigned(toPositions(partitions))
+		}
+		return consumer.Assign(partitions)
+	case kafka.RevokedPartitions:
+		c.log.Info("partitions revoked", "partitions", e.Partitions)
+		if c.partitionListener != nil {
+			c.partitionListener.PartitionsRevoked(toPositions(e.Partitions))
+		}
+		return consumer.Unassign()
+	}
+	return nil
+}
+
+// CommittedPositions returns the positions of the status topics committed in the database. The positions are named
+// with topic@partition, and the previous versions named the position with the topic, which is used only if the
+// partition has no topic@partition position.
+func CommittedPositions() ([]metadata.TransportPosition, error) {
+	var transports []models.Transport
+	err := database.GetGorm().Where("name ~ ?", "^status*").Order("name").Find(&transports).Error
 	if err != nil {
 		return nil, err
 	}
-	offsetToStart := []kafka.TopicPartition{}
-	for i, pos := range positions {
-		var kafkaPosition metadata.TransportPosition
-		err := json.Unmarshal(pos.Payload, &kafkaPosition)
-		if err != nil {
+	positions := []metadata.TransportPosition{}
+	indexes := map[string]int{}
+	for _, pos := range transports {
+		var position metadata.TransportPosition
+		if err := json.Unmarshal(pos.Payload, &position); err != nil {
 			return nil, err
 		}
-		offsetToStart = append(offsetToStart, kafka.TopicPartition{
-			Topic:     &positions[i].Name,
-			Partition: kafkaPosition.Partition,
-			Offset:    kafka.Offset(kafkaPosition.Offset),
-		})
+		topic, _, partitioned := strings.Cut(pos.Name, positionDelimiter)
+		position.Topic = topic
+
+		key := fmt.Sprintf("%s%s%d", topic, positionDelimiter, position.Partition)
+		if i, found := indexes[key]; found {
+			if partitioned {
+				positions[i] = position
+			}
+			continue
+		}
+		indexes[key] = len(positions)
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+// startPartitions sets the offsets of the assigned partitions to the committed positions, the partitions without the
+// position are consumed from the offsets committed to the consumer group.
+func startPartitions(partitions []kafka.TopicPartition, positions []metadata.TransportPosition,
+) []kafka.TopicPartition {
+	started := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, partition := range partitions {
+		for _, position := range positions {
+			if partition.Topic != nil && *partition.Topic == position.Topic &&
+				partition.Partition == position.Partition {
+				partition.Offset = kafka.Offset(position.Offset)
+				break
+			}
+		}
+		started = append(started, partition)
+	}
+	return started
+}
+
+func toPositions(partitions []kafka.TopicPartition) []metadata.TransportPosition {
+	positions := make([]metadata.TransportPosition, 0, len(partitions))
+	for _, partition := range partitions {
+		position := metadata.TransportPosition{Partition: partition.Partition, Offset: int64(partition.Offset)}
+		if partition.Topic != nil {
+			position.Topic = *partition.Topic
+		}
+		positions = append(positions, position)
 	}
-	return offsetToStart, nil
+	return positions
 }
 
 // func getSaramaReceiverProtocol(transportConfig *transport.TransportConfig) (interface{}, error) {
@@ -174,12 +252,14 @@ func getInitOffset() ([]kafka.TopicPartition, error) {
 // 		transportConfig.KafkaConfig.ConsumerConfig.ConsumerTopic)
 // }
 
-func getConfluentReceiverProtocol(transportConfig *transport.TransportConfig) (interface{}, error) {
+func getConfluentReceiverProtocol(transportConfig *transport.TransportConfig, rebalanceCb kafka.RebalanceCb,
+) (interface{}, error) {
 	configMap, err := config.GetConfluentConfigMap(transportConfig.KafkaConfig, false)
 	if err != nil {
 		return nil, err
 	}
 
 	return kafka_confluent.New(kafka_confluent.WithConfigMap(configMap),
-		kafka_confluent.WithReceiverTopics([]string{transportConfig.KafkaConfig.ConsumerConfig.ConsumerTopic}))
+		kafka_confluent.WithReceiverTopics([]string{transportConfig.KafkaConfig.ConsumerConfig.ConsumerTopic}),
+		kafka_confluent.WithRebalanceCallBack(rebalanceCb))
 }
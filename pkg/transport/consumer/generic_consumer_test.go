@@ -2,6 +2,7 @@ package consumer
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -38,7 +39,7 @@ func TestGenerateConsumer(t *testing.T) {
 	}
 }
 
-func TestGetInitOffset(t *testing.T) {
+func TestCommittedPositions(t *testing.T) {
 	testPostgres, err := testpostgres.NewTestPostgres()
 	assert.Nil(t, err)
 	err = testpostgres.InitDatabase(testPostgres.URI)
@@ -46,37 +47,53 @@ func TestGetInitOffset(t *testing.T) {
 
 	databaseTransports := []models.Transport{}
 
-	databaseTransports = append(databaseTransports, generateTransport("status.hub1", 12))
-	databaseTransports = append(databaseTransports, generateTransport("status.hub2", 11))
-	databaseTransports = append(databaseTransports, generateTransport("status", 9))
-	databaseTransports = append(databaseTransports, generateTransport("spec", 9))
+	databaseTransports = append(databaseTransports, generateTransport("status.hub1", "status.hub1", 0, 12))
+	databaseTransports = append(databaseTransports, generateTransport("status.hub2", "status.hub2", 0, 11))
+	databaseTransports = append(databaseTransports, generateTransport("status", "status", 0, 9))
+	databaseTransports = append(databaseTransports, generateTransport("spec", "spec", 0, 9))
+	// the position of the partition overrides the position named with the topic
+	databaseTransports = append(databaseTransports, generateTransport("status@0", "status", 0, 15))
+	databaseTransports = append(databaseTransports, generateTransport("status@1", "status", 1, 3))
 
 	db := database.GetGorm()
 	err = db.Clauses(clause.OnConflict{
 		UpdateAll: true,
 	}).CreateInBatches(databaseTransports, 100).Error
 	assert.Nil(t, err)
-	offsets, err := getInitOffset()
+	positions, err := CommittedPositions()
 	assert.Nil(t, err)
 
-	count := 0
-	for _, offset := range offsets {
-		if *offset.Topic == "spec" {
+	offsets := map[string]int64{}
+	for _, position := range positions {
+		if position.Topic == "spec" {
 			t.Fatalf("the topic %s shouldn't be selected", "spec")
 		}
-		count++
+		offsets[fmt.Sprintf("%s@%d", position.Topic, position.Partition)] = position.Offset
 	}
-	assert.Equal(t, 3, count)
+	assert.Equal(t, map[string]int64{
+		"status.hub1@0": 12,
+		"status.hub2@0": 11,
+		"status@0":      15,
+		"status@1":      3,
+	}, offsets)
+
+	topic := "status"
+	partitions := startPartitions([]kafka.TopicPartition{
+		{Topic: &topic, Partition: 1, Offset: kafka.OffsetStored},
+		{Topic: &topic, Partition: 2, Offset: kafka.OffsetStored},
+	}, positions)
+	assert.Equal(t, kafka.Offset(3), partitions[0].Offset)
+	assert.Equal(t, kafka.OffsetStored, partitions[1].Offset)
 }
 
-func generateTransport(topic string, offset int64) models.Transport {
+func generateTransport(name, topic string, partition int32, offset int64) models.Transport {
 	payload, _ := json.Marshal(metadata.TransportPosition{
 		Topic:     topic,
-		Partition: 0,
+		Partition: partition,
 		Offset:    int64(offset),
 	})
 	return models.Transport{
-		Name:    topic,
+		Name:    name,
 		Payload: payload,
 	}
 }
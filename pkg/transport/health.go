@@ -0,0 +1,86 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package transport
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TransportHealth is the consumer lag of the global hub manager, it's published by the manager and rolled up into
+// the status of the MulticlusterGlobalHub by the operator.
+type TransportHealth struct {
+	// Partitions is the lag between the committed position and the high-water mark of each partition
+	Partitions []PartitionLag `json:"partitions,omitempty"`
+	// Hubs is the last received message of each managed hub
+	Hubs []HubLag `json:"hubs,omitempty"`
+	// LagThreshold is the total lag of the partitions to mark the transport as degraded
+	LagThreshold int64 `json:"lagThreshold"`
+	// DelayThreshold is the delay of the hub message to mark the hub as lagging
+	DelayThreshold time.Duration `json:"delayThreshold"`
+}
+
+type PartitionLag struct {
+	Topic         string `json:"topic"`
+	Partition     int32  `json:"partition"`
+	Committed     int64  `json:"committed"`
+	HighWaterMark int64  `json:"highWaterMark"`
+	Lag           int64  `json:"lag"`
+}
+
+type HubLag struct {
+	Name string `json:"name"`
+	// LastReceivedTime is the time the manager received the last message from the hub
+	LastReceivedTime time.Time `json:"lastReceivedTime"`
+	// Delay is the duration between the hub sent the last received message and the manager received it
+	Delay time.Duration `json:"delay"`
+	// Staleness is the duration since the last received message when the health is reported, it grows when the hub
+	// stops sending the messages
+	Staleness time.Duration `json:"staleness"`
+}
+
+// TotalLag returns the sum of the partition lags.
+func (h *TransportHealth) TotalLag() int64 {
+	var total int64
+	for _, partition := range h.Partitions {
+		total += partition.Lag
+	}
+	return total
+}
+
+// LaggingHubs returns the hubs whose messages are received later than the delay threshold, or which haven't sent any
+// message within the delay threshold.
+func (h *TransportHealth) LaggingHubs() []HubLag {
+	hubs := []HubLag{}
+	for _, hub := range h.Hubs {
+		if hub.Delay > h.DelayThreshold || hub.Staleness > h.DelayThreshold {
+			hubs = append(hubs, hub)
+		}
+	}
+	return hubs
+}
+
+// Degraded returns true if the manager falls behind the transport or any hub is lagging.
+func (h *TransportHealth) Degraded() bool {
+	return h.TotalLag() > h.LagThreshold || len(h.LaggingHubs()) > 0
+}
+
+// Message summarizes how far the manager is behind the transport and which hubs are affected.
+func (h *TransportHealth) Message() string {
+	message := fmt.Sprintf("the manager is %d messages behind the transport", h.TotalLag())
+	laggingHubs := h.LaggingHubs()
+	if len(laggingHubs) == 0 {
+		return message
+	}
+	hubs := make([]string, 0, len(laggingHubs))
+	for _, hub := range laggingHubs {
+		if hub.Staleness > h.DelayThreshold {
+			hubs = append(hubs, fmt.Sprintf("%s(silent for %s)", hub.Name, hub.Staleness.Round(time.Second)))
+			continue
+		}
+		hubs = append(hubs, fmt.Sprintf("%s(%s)", hub.Name, hub.Delay.Round(time.Second)))
+	}
+	return fmt.Sprintf("%s, lagging hubs: %s", message, strings.Join(hubs, ", "))
+}
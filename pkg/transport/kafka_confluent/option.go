@@ -165,3 +165,45 @@ func MessageKeyFrom(ctx context.Context) string {
 	}
 	return ""
 }
+
+// Opaque key type used to store partition key
+type partitionKeyType struct{}
+
+var keyForPartitionKey = partitionKeyType{}
+
+// WithPartitionKey returns back a new context with the given partitionKey. The messages with the same partition key
+// are sent to the same partition of the topic, while the message key is still used by the log compaction.
+func WithPartitionKey(ctx context.Context, partitionKey string) context.Context {
+	return context.WithValue(ctx, keyForPartitionKey, partitionKey)
+}
+
+// PartitionKeyFrom looks in the given context and returns `partitionKey` as a string if found and valid, otherwise "".
+func PartitionKeyFrom(ctx context.Context) string {
+	c := ctx.Value(keyForPartitionKey)
+	if c != nil {
+		if s, ok := c.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// PartitionStore keeps the partitions chosen for the partition keys, so that the partition of a key survives the
+// restart of the producer.
+type PartitionStore interface {
+	// Load returns the stored partition of the partition key, false if it isn't stored
+	Load(ctx context.Context, topic, partitionKey string) (int32, bool, error)
+	Store(ctx context.Context, topic, partitionKey string, partition int32) error
+}
+
+// WithPartitionStore sets the store of the partitions chosen for the partition keys. This option is not required, the
+// partitions are only pinned in memory without it.
+func WithPartitionStore(store PartitionStore) Option {
+	return func(p *Protocol) error {
+		if store == nil {
+			return fmt.Errorf("the partition store option must not be nil")
+		}
+		p.producerPartitionStore = store
+		return nil
+	}
+}
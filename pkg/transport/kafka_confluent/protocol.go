@@ -3,6 +3,7 @@ package kafka_confluent
 import (
 	"context"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"sync"
 
@@ -33,6 +34,12 @@ type Protocol struct {
 	producerDeliveryChan     chan kafka.Event // optional
 	producerDefaultTopic     string           // optional
 	producerDefaultPartition int32            // optional
+	// producerPartitions pins the partitions of the partition keys, the partition of a key isn't changed once it's
+	// chosen, so the messages of the key stay in order when the partitions are added to the topic
+	producerPartitions     map[string]int32
+	producerPartitionStore PartitionStore // optional
+	producerPartitionCount func(topic string) (int, error)
+	producerPartitionsMux  sync.Mutex
 
 	// receiver
 	incoming chan *kafka.Message
@@ -41,9 +48,11 @@ type Protocol struct {
 func New(opts ...Option) (*Protocol, error) {
 	p := &Protocol{
 		producerDefaultPartition: kafka.PartitionAny,
+		producerPartitions:       map[string]int32{},
 		consumerPollTimeout:      100,
 		incoming:                 make(chan *kafka.Message),
 	}
+	p.producerPartitionCount = p.partitionCount
 	if err := p.applyOptions(opts...); err != nil {
 		return nil, err
 	}
@@ -108,6 +117,11 @@ func (p *Protocol) Send(ctx context.Context, in binding.Message, transformers ..
 
 	if partition := TopicPartitionFrom(ctx); partition != -1 {
 		kafkaMsg.TopicPartition.Partition = partition
+	} else if partitionKey := PartitionKeyFrom(ctx); partitionKey != "" {
+		kafkaMsg.TopicPartition.Partition, err = p.partitionOf(ctx, *kafkaMsg.TopicPartition.Topic, partitionKey)
+		if err != nil {
+			return err
+		}
 	}
 
 	if messageKey := MessageKeyFrom(ctx); messageKey != "" {
@@ -131,6 +145,57 @@ func (p *Protocol) Send(ctx context.Context, in binding.Message, transformers ..
 	return nil
 }
 
+// partitionOf returns the partition of the partition key. The key is hashed to a partition of the topic the first time
+// it's sent, then the partition is pinned in memory and in the partition store, so the key isn't moved to the other
+// partition once the partitions are added to the topic, the added partitions are used by the new keys.
+func (p *Protocol) partitionOf(ctx context.Context, topic, partitionKey string) (int32, error) {
+	p.producerPartitionsMux.Lock()
+	defer p.producerPartitionsMux.Unlock()
+
+	pinnedKey := topic + "/" + partitionKey
+	if partition, ok := p.producerPartitions[pinnedKey]; ok {
+		return partition, nil
+	}
+
+	count, err := p.producerPartitionCount(topic)
+	if err != nil {
+		return kafka.PartitionAny, err
+	}
+	if count == 0 {
+		// the topic isn't created yet, let the producer choose the partition
+		return kafka.PartitionAny, nil
+	}
+
+	if p.producerPartitionStore != nil {
+		partition, found, err := p.producerPartitionStore.Load(ctx, topic, partitionKey)
+		if err != nil {
+			return kafka.PartitionAny, fmt.Errorf("failed to load the partition of %s: %w", partitionKey, err)
+		}
+		// the stored partition is out of range only if the topic is recreated with less partitions
+		if found && partition >= 0 && int(partition) < count {
+			p.producerPartitions[pinnedKey] = partition
+			return partition, nil
+		}
+	}
+
+	partition := int32(crc32.ChecksumIEEE([]byte(partitionKey)) % uint32(count))
+	if p.producerPartitionStore != nil {
+		if err := p.producerPartitionStore.Store(ctx, topic, partitionKey, partition); err != nil {
+			return kafka.PartitionAny, fmt.Errorf("failed to store the partition of %s: %w", partitionKey, err)
+		}
+	}
+	p.producerPartitions[pinnedKey] = partition
+	return partition, nil
+}
+
+func (p *Protocol) partitionCount(topic string) (int, error) {
+	metadata, err := p.producer.GetMetadata(&topic, false, 10000)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the metadata of the topic %s: %w", topic, err)
+	}
+	return len(metadata.Topics[topic].Partitions), nil
+}
+
 func (p *Protocol) OpenInbound(ctx context.Context) error {
 	if p.consumer == nil {
 		return fmt.Errorf("the consumer client must not be nil")
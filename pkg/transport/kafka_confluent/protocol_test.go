@@ -0,0 +1,71 @@
+package kafka_confluent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryPartitionStore map[string]int32
+
+func (s memoryPartitionStore) Load(ctx context.Context, topic, partitionKey string) (int32, bool, error) {
+	partition, found := s[topic+"/"+partitionKey]
+	return partition, found, nil
+}
+
+func (s memoryPartitionStore) Store(ctx context.Context, topic, partitionKey string, partition int32) error {
+	s[topic+"/"+partitionKey] = partition
+	return nil
+}
+
+func TestPartitionOf(t *testing.T) {
+	ctx := context.Background()
+	count := 0
+	store := memoryPartitionStore{}
+	newProtocol := func() *Protocol {
+		return &Protocol{
+			producerPartitions:     map[string]int32{},
+			producerPartitionStore: store,
+			producerPartitionCount: func(topic string) (int, error) { return count, nil },
+		}
+	}
+	p := newProtocol()
+
+	// the topic isn't created yet
+	partition, err := p.partitionOf(ctx, "status", "hub1")
+	require.NoError(t, err)
+	assert.Equal(t, kafka.PartitionAny, partition)
+	assert.Empty(t, store)
+
+	count = 2
+	hub1Partition, err := p.partitionOf(ctx, "status", "hub1")
+	require.NoError(t, err)
+	assert.Less(t, hub1Partition, int32(2))
+	assert.Equal(t, hub1Partition, store["status/hub1"])
+
+	// the partitions are added, the hub stays in its partition, even after the producer restarts
+	count = 100
+	partition, err = p.partitionOf(ctx, "status", "hub1")
+	require.NoError(t, err)
+	assert.Equal(t, hub1Partition, partition)
+
+	p = newProtocol()
+	partition, err = p.partitionOf(ctx, "status", "hub1")
+	require.NoError(t, err)
+	assert.Equal(t, hub1Partition, partition)
+
+	// the new hub is hashed to all the partitions
+	_, err = p.partitionOf(ctx, "status", "hub2")
+	require.NoError(t, err)
+	assert.Contains(t, store, "status/hub2")
+
+	// the stored partition out of range is chosen again, e.g. the topic is recreated with less partitions
+	store["status/hub3"] = 200
+	partition, err = p.partitionOf(ctx, "status", "hub3")
+	require.NoError(t, err)
+	assert.Less(t, partition, int32(100))
+	assert.Equal(t, partition, store["status/hub3"])
+}
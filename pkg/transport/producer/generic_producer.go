@@ -79,6 +79,13 @@ func (p *GenericProducer) Send(ctx context.Context, msg *transport.Message) erro
 		event.SetSource(transport.Broadcast)
 	}
 
+	// the messages of a managed hub are sent to the same partition, so that the hub is owned by one of the manager
+	// replicas when the status processing is sharded by the partitions
+	sendCtx := kafka_confluent.WithMessageKey(ctx, msg.Key)
+	if msg.Destination != "" && msg.Destination != transport.Broadcast {
+		sendCtx = kafka_confluent.WithPartitionKey(sendCtx, msg.Destination)
+	}
+
 	messageBytes := msg.Payload
 	chunks := p.splitPayloadIntoChunks(messageBytes)
 	for index, chunk := range chunks {
@@ -87,7 +94,7 @@ func (p *GenericProducer) Send(ctx context.Context, msg *transport.Message) erro
 		if err := event.SetData(cloudevents.ApplicationJSON, chunk); err != nil {
 			return fmt.Errorf("failed to set cloudevents data: %v", msg)
 		}
-		if result := p.client.Send(kafka_confluent.WithMessageKey(ctx, msg.Key), event); cloudevents.IsUndelivered(result) {
+		if result := p.client.Send(sendCtx, event); cloudevents.IsUndelivered(result) {
 			return fmt.Errorf("failed to send generic message to transport: %s", result.Error())
 		}
 
@@ -130,6 +137,12 @@ func getConfluentSenderProtocol(transportConfig *transport.TransportConfig) (int
 	if err != nil {
 		return nil, err
 	}
-	return kafka_confluent.New(kafka_confluent.WithConfigMap(configMap),
-		kafka_confluent.WithSenderTopic(transportConfig.KafkaConfig.ProducerConfig.ProducerTopic))
+	options := []kafka_confluent.Option{
+		kafka_confluent.WithConfigMap(configMap),
+		kafka_confluent.WithSenderTopic(transportConfig.KafkaConfig.ProducerConfig.ProducerTopic),
+	}
+	if store := transportConfig.KafkaConfig.ProducerConfig.PartitionStore; store != nil {
+		options = append(options, kafka_confluent.WithPartitionStore(store))
+	}
+	return kafka_confluent.New(options...)
 }
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"context"
 	"time"
 
 	"github.com/stolostron/multicluster-global-hub/pkg/bundle/metadata"
@@ -54,6 +55,16 @@ type KafkaProducerConfig struct {
 	ProducerID         string
 	ProducerTopic      string
 	MessageSizeLimitKB int
+	// PartitionStore keeps the partitions of the managed hubs chosen by the producer, it's optional
+	PartitionStore PartitionStore
+}
+
+// PartitionStore keeps the partition chosen for the partition key of the topic, so that the messages of a managed hub
+// stay in the same partition once the partitions are added to the topic.
+type PartitionStore interface {
+	// Load returns the stored partition of the partition key, false if it isn't stored
+	Load(ctx context.Context, topic, partitionKey string) (int32, bool, error)
+	Store(ctx context.Context, topic, partitionKey string, partition int32) error
 }
 
 type KafkaConsumerConfig struct {
@@ -85,6 +96,7 @@ type Message struct {
 	Destination  string                `json:"destination"`
 	MsgType      string                `json:"msgType"`
 	Payload      []byte                `json:"payload"`
+	Time         time.Time             `json:"time"`
 	BundleStatus metadata.BundleStatus // the manager to mark the processing status of the bundle
 }
 
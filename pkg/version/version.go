@@ -0,0 +1,32 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package version
+
+import "runtime/debug"
+
+// Version is the release version of the binary, it's overridden at build time with
+//
+//	-ldflags "-X github.com/stolostron/multicluster-global-hub/pkg/version.Version=<version>"
+var Version = ""
+
+// Get returns the version of the running binary. if it isn't set at build time, fall back to the vcs revision
+// recorded by the go toolchain.
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	if info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "unknown"
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package testapiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/stolostron/multicluster-global-hub/manager/pkg/nonk8sapi"
+	"github.com/stolostron/multicluster-global-hub/test/pkg/testpostgres"
+)
+
+// Token is the only bearer token authenticated by the test API server.
+const Token = "test-token"
+
+// TestAPIServer serves the global hub API backed by a test postgres, the requests are authenticated by a fake
+// openshift user API.
+type TestAPIServer struct {
+	postgres   *testpostgres.TestPostgres
+	authServer *httptest.Server
+	apiServer  *httptest.Server
+	URL        string
+}
+
+func NewTestAPIServer() (*TestAPIServer, error) {
+	pg, err := testpostgres.NewTestPostgres()
+	if err != nil {
+		return nil, err
+	}
+	if err := testpostgres.InitDatabase(pg.URI); err != nil {
+		_ = pg.Stop()
+		return nil, err
+	}
+
+	// only the requests with the test token are authenticated
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"kind": "User",
+			"apiVersion": "user.openshift.io/v1",
+			"metadata": {
+			  "name": "kube:admin"
+			},
+			"groups": [
+			  "system:authenticated",
+			  "system:cluster-admins"
+			]
+		  }`))
+	}))
+
+	router, err := nonk8sapi.SetupRouter(&nonk8sapi.NonK8sAPIServerConfig{
+		ServerBasePath: "/global-hub-api/v1",
+		ClusterAPIURL:  authServer.URL,
+	}, nil)
+	if err != nil {
+		authServer.Close()
+		_ = pg.Stop()
+		return nil, err
+	}
+	apiServer := httptest.NewServer(router)
+
+	return &TestAPIServer{
+		postgres:   pg,
+		authServer: authServer,
+		apiServer:  apiServer,
+		URL:        apiServer.URL,
+	}, nil
+}
+
+// Stop stops the servers and the postgres, it does nothing if the server isn't started, e.g. the setup of the suite
+// fails, so the setup error isn't hidden.
+func (s *TestAPIServer) Stop() error {
+	if s == nil {
+		return nil
+	}
+	s.apiServer.Close()
+	s.authServer.Close()
+	return s.postgres.Stop()
+}